@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+type client struct {
+	base   string
+	apiKey string
+	http   *http.Client
+}
+
+// apiError is a 4xx or 5xx response; body holds the API's error text
+type apiError struct {
+	status int
+	body   []byte
+}
+
+func (e *apiError) Error() string {
+	msg := strings.TrimSpace(string(e.body))
+	var resp struct {
+		Error string `json:"error"`
+	}
+	if json.Unmarshal(e.body, &resp) == nil && resp.Error != "" {
+		msg = resp.Error
+	}
+	return fmt.Sprintf("%d %s: %s", e.status, http.StatusText(e.status), msg)
+}
+
+func (c *client) sendJSON(ctx context.Context, method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	return c.send(ctx, method, path, "application/json", reader, out)
+}
+
+func (c *client) send(ctx context.Context, method, path, contentType string, body io.Reader, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, method, c.base+path, body)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if c.apiKey != "" {
+		req.Header.Set("X-API-Key-ID", c.apiKey)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return &apiError{status: resp.StatusCode, body: data}
+	}
+	if out != nil {
+		return json.NewDecoder(resp.Body).Decode(out)
+	}
+	_, err = io.Copy(io.Discard, resp.Body)
+	return err
+}
@@ -0,0 +1,229 @@
+// Command calibreimport copies a Calibre library into the catalog, for small
+// libraries moving off a Calibre-based catalog:
+//
+//	go run ./cmd/calibreimport -library ~/Calibre\ Library -url http://localhost:8080/api/v1
+//	go run ./cmd/calibreimport -library ./export -format paperback -dry-run
+//
+// It reads the metadata.opf Calibre keeps in every book folder of a library
+// (next to metadata.db) and writes into "Save to disk" exports, so it works
+// on either. Each book is created with its title, authors, description,
+// publication year, ISBN/ISSN/OCLC identifiers and series; its cover is
+// uploaded as an image asset. Books whose identifier is already in the
+// catalog are skipped, so an interrupted import can simply be run again.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"public_library/internal/book"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type summary struct {
+	Created    int
+	Duplicates int
+	Failed     int
+	Covers     int
+}
+
+func main() {
+	var (
+		library  = flag.String("library", "", "Calibre library folder or \"Save to disk\" export (required)")
+		baseURL  = flag.String("url", "http://localhost:8080/api/v1", "API base URL")
+		format   = flag.String("format", "ebook", "catalog format given to the imported books; empty leaves it unset")
+		audience = flag.String("audience", "", "audience given to the imported books (children, teen or adult)")
+		covers   = flag.Bool("covers", true, "upload each book's cover image")
+		apiKey   = flag.String("api-key", "", "value of the X-API-Key-ID header, if the instance rate limits by key")
+		dryRun   = flag.Bool("dry-run", false, "read and check the library without writing to the catalog")
+	)
+	flag.Parse()
+	if *library == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	entries, problems, err := readLibrary(*library, *format, *audience)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, p := range problems {
+		log.Print(p)
+	}
+	log.Printf("read %d books from %s", len(entries), *library)
+	if *dryRun {
+		if len(problems) > 0 {
+			os.Exit(1)
+		}
+		return
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	imp := &importer{
+		client: &client{base: strings.TrimRight(*baseURL, "/"), apiKey: *apiKey, http: &http.Client{Timeout: 2 * time.Minute}},
+		covers: *covers,
+	}
+	if err := imp.loadSeries(ctx); err != nil {
+		log.Fatalf("listing series: %v", err)
+	}
+	sum := summary{Failed: len(problems)}
+	for _, e := range entries {
+		if ctx.Err() != nil {
+			break
+		}
+		imp.importEntry(ctx, e, &sum)
+	}
+	log.Printf("created %d books with %d covers; %d already in the catalog; %d failed",
+		sum.Created, sum.Covers, sum.Duplicates, sum.Failed)
+	if sum.Failed > 0 || ctx.Err() != nil {
+		os.Exit(1)
+	}
+}
+
+// readLibrary parses every metadata.opf under dir, in path order. Books that
+// cannot be read are reported as problems rather than stopping the import.
+func readLibrary(dir, format, audience string) ([]entry, []string, error) {
+	var paths []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		// .caltrash and .calnotes hold deleted books and notes
+		if d.IsDir() && path != dir && strings.HasPrefix(d.Name(), ".") {
+			return filepath.SkipDir
+		}
+		if !d.IsDir() && d.Name() == "metadata.opf" {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(paths) == 0 {
+		if _, err := os.Stat(filepath.Join(dir, "metadata.db")); err == nil {
+			return nil, nil, errors.New("no metadata.opf files in the library; write them with `calibredb backup_metadata --all` and run again")
+		}
+		return nil, nil, fmt.Errorf("no metadata.opf files found under %s", dir)
+	}
+	sort.Strings(paths)
+
+	var entries []entry
+	var problems []string
+	for _, path := range paths {
+		e, err := readOPF(path, format)
+		if err == nil {
+			e.Book.Audience = audience
+			err = e.Book.Validate()
+		}
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", path, err))
+			continue
+		}
+		entries = append(entries, e)
+	}
+	return entries, problems, nil
+}
+
+type importer struct {
+	client *client
+	covers bool
+	// series maps lower-cased series titles to their catalog IDs
+	series map[string]int
+}
+
+type seriesRecord struct {
+	ID    int    `json:"id"`
+	Title string `json:"title"`
+}
+
+func (imp *importer) loadSeries(ctx context.Context) error {
+	var all []seriesRecord
+	if err := imp.client.sendJSON(ctx, http.MethodGet, "/series", nil, &all); err != nil {
+		return err
+	}
+	imp.series = make(map[string]int, len(all))
+	for _, s := range all {
+		imp.series[strings.ToLower(s.Title)] = s.ID
+	}
+	return nil
+}
+
+// seriesID returns the catalog series titled title, creating it if needed
+func (imp *importer) seriesID(ctx context.Context, title string) (int, error) {
+	if id, ok := imp.series[strings.ToLower(title)]; ok {
+		return id, nil
+	}
+	var created seriesRecord
+	if err := imp.client.sendJSON(ctx, http.MethodPost, "/series", seriesRecord{Title: title}, &created); err != nil {
+		return 0, err
+	}
+	imp.series[strings.ToLower(title)] = created.ID
+	return created.ID, nil
+}
+
+func (imp *importer) importEntry(ctx context.Context, e entry, sum *summary) {
+	b := e.Book
+	if e.SeriesTitle != "" {
+		id, err := imp.seriesID(ctx, e.SeriesTitle)
+		if err != nil {
+			log.Printf("%s: series %q: %v", e.label(), e.SeriesTitle, err)
+			sum.Failed++
+			return
+		}
+		b.Series = &book.SeriesRef{ID: id, Position: e.SeriesIndex}
+	}
+
+	var created book.Book
+	err := imp.client.sendJSON(ctx, http.MethodPost, "/books/create", b, &created)
+	var apiErr *apiError
+	if errors.As(err, &apiErr) && apiErr.status == http.StatusConflict {
+		log.Printf("%s: skipped, %v", e.label(), err)
+		sum.Duplicates++
+		return
+	}
+	if err != nil {
+		log.Printf("%s: %v", e.label(), err)
+		sum.Failed++
+		return
+	}
+	sum.Created++
+
+	if !imp.covers || e.CoverPath == "" {
+		return
+	}
+	if err := imp.uploadCover(ctx, created.ID, e.CoverPath); err != nil {
+		log.Printf("%s: cover: %v", e.label(), err)
+		sum.Failed++
+		return
+	}
+	sum.Covers++
+}
+
+func (imp *importer) uploadCover(ctx context.Context, bookID int, path string) error {
+	contentType := map[string]string{
+		".jpg": "image/jpeg", ".jpeg": "image/jpeg", ".png": "image/png", ".gif": "image/gif", ".webp": "image/webp",
+	}[strings.ToLower(filepath.Ext(path))]
+	if contentType == "" {
+		return fmt.Errorf("%s is not a JPEG, PNG, GIF or WebP image", filepath.Base(path))
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	target := "/books/" + strconv.Itoa(bookID) + "/assets?filename=" + url.QueryEscape(filepath.Base(path))
+	return imp.client.send(ctx, http.MethodPost, target, contentType, f, nil)
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"public_library/internal/book"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+// opfPackage is the part of a Calibre OPF file the importer reads. Calibre
+// writes OPF 2 by default and OPF 3 when asked to; both are handled. Field
+// tags carry no namespace so dc: and opf: names match by local name.
+type opfPackage struct {
+	Metadata struct {
+		Titles      []string        `xml:"title"`
+		Creators    []opfCreator    `xml:"creator"`
+		Identifiers []opfIdentifier `xml:"identifier"`
+		Dates       []string        `xml:"date"`
+		Description string          `xml:"description"`
+		Languages   []string        `xml:"language"`
+		Publisher   string          `xml:"publisher"`
+		Meta        []opfMeta       `xml:"meta"`
+	} `xml:"metadata"`
+	Guide []struct {
+		Type string `xml:"type,attr"`
+		Href string `xml:"href,attr"`
+	} `xml:"guide>reference"`
+}
+
+type opfCreator struct {
+	ID   string `xml:"id,attr"`
+	Role string `xml:"role,attr"`
+	Name string `xml:",chardata"`
+}
+
+type opfIdentifier struct {
+	Scheme string `xml:"scheme,attr"`
+	Value  string `xml:",chardata"`
+}
+
+// opfMeta is an OPF 2 <meta name content> or an OPF 3 <meta property refines>
+type opfMeta struct {
+	Name     string `xml:"name,attr"`
+	Content  string `xml:"content,attr"`
+	ID       string `xml:"id,attr"`
+	Property string `xml:"property,attr"`
+	Refines  string `xml:"refines,attr"`
+	Value    string `xml:",chardata"`
+}
+
+// entry is one book of the library, ready to be created
+type entry struct {
+	Dir         string
+	Book        book.Book
+	SeriesTitle string
+	SeriesIndex *float64
+	CoverPath   string
+}
+
+func (e entry) label() string {
+	return fmt.Sprintf("%q by %s", e.Book.Title, e.Book.Author)
+}
+
+// identifierSchemes maps the identifier schemes Calibre writes to the
+// catalog's identifier types; other schemes (uuid, calibre, goodreads,
+// amazon, ...) have no counterpart and are dropped.
+var identifierSchemes = map[string]string{
+	"isbn": book.IdentifierISBN13, // typed by length below
+	"issn": book.IdentifierISSN,
+	"oclc": book.IdentifierOCLC,
+}
+
+var (
+	htmlTag   = regexp.MustCompile(`(?s)<[^>]*>`)
+	htmlBreak = regexp.MustCompile(`(?i)</p>|<br\s*/?>`)
+)
+
+// readOPF builds the entry described by the metadata.opf at path. format is
+// the catalog format given to every imported book.
+func readOPF(path, format string) (entry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return entry{}, err
+	}
+	var pkg opfPackage
+	if err := xml.Unmarshal(data, &pkg); err != nil {
+		return entry{}, fmt.Errorf("parsing OPF: %w", err)
+	}
+	md := pkg.Metadata
+	e := entry{Dir: filepath.Dir(path)}
+
+	if len(md.Titles) > 0 {
+		e.Book.Title = strings.TrimSpace(md.Titles[0])
+	}
+	var authors []string
+	for _, c := range md.Creators {
+		// OPF 2 gives the role as an attribute, OPF 3 in a refining <meta>
+		role := c.Role
+		for _, m := range md.Meta {
+			if m.Refines == "#"+c.ID && c.ID != "" && m.Property == "role" {
+				role = strings.TrimSpace(m.Value)
+			}
+		}
+		if name := strings.TrimSpace(c.Name); name != "" && (role == "" || role == "aut") {
+			authors = append(authors, name)
+		}
+	}
+	e.Book.Author = strings.Join(authors, " & ")
+	if e.Book.Title == "" || e.Book.Author == "" {
+		return entry{}, fmt.Errorf("OPF has no title or author")
+	}
+
+	e.Book.Description = plainText(md.Description)
+	e.Book.Format = format
+	// Calibre writes ISO 639-2 codes such as "eng", which parse to "en"
+	if len(md.Languages) > 0 {
+		if tag, err := language.Parse(strings.TrimSpace(md.Languages[0])); err == nil && tag != language.Und {
+			e.Book.Language = tag.String()
+		}
+	}
+	if len(md.Dates) > 0 {
+		// Calibre writes 0101-01-01 for an unknown date
+		date, _, _ := strings.Cut(strings.TrimSpace(md.Dates[0]), "-")
+		if year, err := strconv.Atoi(date); err == nil && year > 101 {
+			e.Book.PublicationYear = &year
+		}
+	}
+
+	seen := make(map[book.Identifier]bool)
+	for _, id := range md.Identifiers {
+		scheme, value := strings.ToLower(strings.TrimSpace(id.Scheme)), strings.TrimSpace(id.Value)
+		if scheme == "" {
+			// OPF 3: "isbn:9780…" or "urn:isbn:9780…"
+			var ok bool
+			if scheme, value, ok = strings.Cut(strings.TrimPrefix(value, "urn:"), ":"); !ok {
+				continue
+			}
+			scheme = strings.ToLower(scheme)
+		}
+		idType, ok := identifierSchemes[scheme]
+		if !ok {
+			continue
+		}
+		if scheme == "isbn" && len(strings.NewReplacer("-", "", " ", "").Replace(value)) == 10 {
+			idType = book.IdentifierISBN10
+		}
+		normalized, err := book.NormalizeIdentifier(idType, value)
+		if err != nil || seen[normalized] {
+			continue // Calibre does not check identifiers; a bad one is not worth losing the book over
+		}
+		seen[normalized] = true
+		e.Book.Identifiers = append(e.Book.Identifiers, normalized)
+	}
+
+	e.SeriesTitle, e.SeriesIndex = series(md.Meta)
+
+	for _, ref := range pkg.Guide {
+		if ref.Type == "cover" && ref.Href != "" {
+			e.CoverPath = filepath.Join(e.Dir, filepath.FromSlash(ref.Href))
+		}
+	}
+	if e.CoverPath == "" {
+		e.CoverPath = filepath.Join(e.Dir, "cover.jpg")
+	}
+	if _, err := os.Stat(e.CoverPath); err != nil {
+		e.CoverPath = ""
+	}
+	return e, nil
+}
+
+// series reads the series title and position from calibre:series metadata
+// (OPF 2) or the first belongs-to-collection (OPF 3)
+func series(meta []opfMeta) (string, *float64) {
+	var title, index string
+	for _, m := range meta {
+		switch m.Name {
+		case "calibre:series":
+			title = m.Content
+		case "calibre:series_index":
+			index = m.Content
+		}
+	}
+	for _, m := range meta {
+		if m.Property != "belongs-to-collection" || title != "" {
+			continue
+		}
+		title = m.Value
+		for _, r := range meta {
+			if r.Refines == "#"+m.ID && m.ID != "" && r.Property == "group-position" {
+				index = r.Value
+			}
+		}
+	}
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return "", nil
+	}
+	if pos, err := strconv.ParseFloat(strings.TrimSpace(index), 64); err == nil && pos > 0 {
+		return title, &pos
+	}
+	return title, nil
+}
+
+// plainText turns Calibre's HTML comments into the catalog's plain-text
+// description, keeping paragraph breaks
+func plainText(s string) string {
+	s = htmlBreak.ReplaceAllString(s, "\n")
+	s = html.UnescapeString(htmlTag.ReplaceAllString(s, ""))
+	lines := strings.Split(s, "\n")
+	kept := lines[:0]
+	for _, l := range lines {
+		if l = strings.Join(strings.Fields(l), " "); l != "" {
+			kept = append(kept, l)
+		}
+	}
+	return strings.Join(kept, "\n\n")
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"public_library/internal/book"
+)
+
+// client issues the API requests of one run and reports each status code
+type client struct {
+	base   string
+	apiKey string
+	http   *http.Client
+}
+
+func (c *client) send(ctx context.Context, method, path string, body interface{}, out interface{}) (int, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return 0, err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.base+path, reader)
+	if err != nil {
+		return 0, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.apiKey != "" {
+		req.Header.Set("X-API-Key-ID", c.apiKey)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		io.Copy(io.Discard, resp.Body)
+		return resp.StatusCode, fmt.Errorf("%s %s: %s", method, path, resp.Status)
+	}
+	if out != nil {
+		return resp.StatusCode, json.NewDecoder(resp.Body).Decode(out)
+	}
+	_, err = io.Copy(io.Discard, resp.Body)
+	return resp.StatusCode, err
+}
+
+func (c *client) get(ctx context.Context, id int) (int, error) {
+	return c.send(ctx, http.MethodGet, fmt.Sprintf("/books/%d", id), nil, nil)
+}
+
+func (c *client) list(ctx context.Context, req map[string]interface{}) (int, error) {
+	return c.send(ctx, http.MethodPost, "/books/list", req, nil)
+}
+
+func (c *client) create(ctx context.Context, b book.Book) (int, int, error) {
+	var created book.Book
+	status, err := c.send(ctx, http.MethodPost, "/books/create", b, &created)
+	return status, created.ID, err
+}
+
+func (c *client) delete(ctx context.Context, id int) error {
+	_, err := c.send(ctx, http.MethodDelete, fmt.Sprintf("/books/%d", id), nil, nil)
+	return err
+}
+
+// discoverIDs reads the first pages of the catalog so get requests hit
+// existing books
+func (c *client) discoverIDs(ctx context.Context) ([]int, error) {
+	var ids []int
+	for page := 1; page <= 5; page++ {
+		var resp struct {
+			PageCount int64               `json:"page_count"`
+			Data      []book.BookResponse `json:"data"`
+		}
+		if _, err := c.send(ctx, http.MethodPost, "/books/list", map[string]interface{}{"page": page, "page_size": 100}, &resp); err != nil {
+			return nil, err
+		}
+		for _, b := range resp.Data {
+			ids = append(ids, b.ID)
+		}
+		if int64(page) >= resp.PageCount {
+			break
+		}
+	}
+	return ids, nil
+}
@@ -0,0 +1,286 @@
+// Command loadgen drives a configurable mix of catalog traffic against a
+// running instance and reports throughput and latency percentiles per
+// operation, so performance changes can be measured and compared:
+//
+//	go run ./cmd/loadgen -url http://localhost:8080/api/v1 -mix read-heavy -duration 1m -concurrency 16
+//	go run ./cmd/loadgen -mix list=50,search=40,create=10 -rps 200 -json > after.json
+//
+// Books created by the run are deleted at the end unless -cleanup=false.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"math/rand"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"public_library/pkg/fixtures"
+)
+
+// presets are the named traffic mixes; -mix also accepts op=weight lists
+var presets = map[string]string{
+	"read-heavy":  "get=50,list=30,search=15,create=5",
+	"search":      "search=70,list=20,get=10",
+	"write-heavy": "create=50,get=30,list=20",
+	"browse":      "list=70,get=30",
+}
+
+func main() {
+	var (
+		baseURL     = flag.String("url", "http://localhost:8080/api/v1", "API base URL")
+		mixFlag     = flag.String("mix", "read-heavy", "traffic mix: a preset (read-heavy, search, write-heavy, browse) or op=weight,... with ops get, list, search, create")
+		duration    = flag.Duration("duration", 30*time.Second, "how long to send traffic")
+		concurrency = flag.Int("concurrency", 8, "number of concurrent clients")
+		rps         = flag.Int("rps", 0, "target requests per second across all clients; 0 sends as fast as responses allow")
+		apiKey      = flag.String("api-key", "", "value of the X-API-Key-ID header, if the instance rate limits by key")
+		seed        = flag.Int64("seed", time.Now().UnixNano(), "seed for generated books and request choices")
+		cleanup     = flag.Bool("cleanup", true, "delete the books created by the run when it ends")
+		asJSON      = flag.Bool("json", false, "print the report as JSON")
+	)
+	flag.Parse()
+
+	mix, err := parseMix(*mixFlag)
+	if err != nil {
+		log.Fatalf("invalid -mix: %v", err)
+	}
+	if *concurrency < 1 {
+		log.Fatal("-concurrency must be at least 1")
+	}
+
+	c := &client{
+		base:   strings.TrimRight(*baseURL, "/"),
+		apiKey: *apiKey,
+		http: &http.Client{
+			Timeout: 30 * time.Second,
+			Transport: &http.Transport{
+				MaxIdleConnsPerHost: *concurrency,
+			},
+		},
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	ids, err := c.discoverIDs(ctx)
+	if err != nil {
+		log.Fatalf("cannot reach %s: %v", c.base, err)
+	}
+	run := &runState{client: c, ids: ids}
+
+	runCtx, cancel := context.WithTimeout(ctx, *duration)
+	defer cancel()
+	start := time.Now()
+	recorder := newRecorder()
+	run.drive(runCtx, mix, *concurrency, *rps, *seed, recorder)
+	elapsed := time.Since(start)
+
+	if *cleanup {
+		run.deleteCreated(context.Background())
+	}
+
+	report := recorder.report(elapsed)
+	report.Mix = mix.String()
+	report.Concurrency = *concurrency
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(report)
+		return
+	}
+	report.print(os.Stdout)
+}
+
+// mix is a weighted choice of operations
+type mix []struct {
+	op     string
+	weight int
+}
+
+var ops = map[string]bool{"get": true, "list": true, "search": true, "create": true}
+
+func parseMix(s string) (mix, error) {
+	if preset, ok := presets[s]; ok {
+		s = preset
+	}
+	var m mix
+	for _, part := range strings.Split(s, ",") {
+		op, w, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok || !ops[op] {
+			return nil, fmt.Errorf("%q is not op=weight with a known op", part)
+		}
+		weight, err := strconv.Atoi(w)
+		if err != nil || weight < 0 {
+			return nil, fmt.Errorf("weight of %s must be a non-negative integer", op)
+		}
+		if weight > 0 {
+			m = append(m, struct {
+				op     string
+				weight int
+			}{op, weight})
+		}
+	}
+	if len(m) == 0 {
+		return nil, fmt.Errorf("no operation has a positive weight")
+	}
+	return m, nil
+}
+
+func (m mix) pick(rnd *rand.Rand) string {
+	total := 0
+	for _, e := range m {
+		total += e.weight
+	}
+	n := rnd.Intn(total)
+	for _, e := range m {
+		if n < e.weight {
+			return e.op
+		}
+		n -= e.weight
+	}
+	return m[len(m)-1].op
+}
+
+func (m mix) String() string {
+	parts := make([]string, len(m))
+	for i, e := range m {
+		parts[i] = fmt.Sprintf("%s=%d", e.op, e.weight)
+	}
+	return strings.Join(parts, ",")
+}
+
+// runState is shared by the clients: the known book IDs for get requests
+// and the books created so far
+type runState struct {
+	client *client
+
+	mu      sync.Mutex
+	ids     []int
+	created []int
+}
+
+func (s *runState) randomID(rnd *rand.Rand) (int, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.ids) == 0 {
+		return 0, false
+	}
+	return s.ids[rnd.Intn(len(s.ids))], true
+}
+
+func (s *runState) addCreated(id int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ids = append(s.ids, id)
+	s.created = append(s.created, id)
+}
+
+// drive runs concurrency clients until ctx is done. With rps > 0 requests
+// are released by a shared ticker; otherwise each client sends its next
+// request as soon as the previous one completes.
+func (s *runState) drive(ctx context.Context, m mix, concurrency, rps int, seed int64, rec *recorder) {
+	var tokens <-chan time.Time
+	if rps > 0 {
+		ticker := time.NewTicker(time.Second / time.Duration(rps))
+		defer ticker.Stop()
+		tokens = ticker.C
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			rnd := rand.New(rand.NewSource(seed + int64(worker)))
+			gen := fixtures.New(seed+int64(worker), time.Now())
+			for {
+				if tokens != nil {
+					select {
+					case <-ctx.Done():
+						return
+					case <-tokens:
+					}
+				} else if ctx.Err() != nil {
+					return
+				}
+
+				op := m.pick(rnd)
+				begin := time.Now()
+				status, err := s.do(ctx, op, rnd, gen)
+				if ctx.Err() != nil && err != nil {
+					return // cut off by the end of the run, not a failure
+				}
+				rec.record(op, time.Since(begin), status, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+var searchTerms = []string{
+	"harbor", "history", "garden", "mystery", "dragon", "ocean", "kingdom", "tolkien",
+	"austen", "sourdough", "9780547928227", "lighthouse", "murder", "science",
+}
+
+func (s *runState) do(ctx context.Context, op string, rnd *rand.Rand, gen *fixtures.Generator) (int, error) {
+	switch op {
+	case "get":
+		id, ok := s.randomID(rnd)
+		if !ok {
+			return s.client.list(ctx, map[string]interface{}{"page": 1})
+		}
+		return s.client.get(ctx, id)
+	case "list":
+		return s.client.list(ctx, map[string]interface{}{"page": 1 + rnd.Intn(5), "page_size": 20})
+	case "search":
+		return s.client.list(ctx, map[string]interface{}{"search": searchTerms[rnd.Intn(len(searchTerms))], "page_size": 20})
+	default:
+		status, id, err := s.client.create(ctx, gen.Book())
+		if err == nil && id > 0 {
+			s.addCreated(id)
+		}
+		return status, err
+	}
+}
+
+func (s *runState) deleteCreated(ctx context.Context) {
+	failed := 0
+	for _, id := range s.created {
+		if err := s.client.delete(ctx, id); err != nil {
+			failed++
+		}
+	}
+	if failed > 0 {
+		log.Printf("failed to delete %d of %d created books", failed, len(s.created))
+	}
+}
+
+// percentiles reported for every operation
+var percentiles = []float64{50, 90, 95, 99}
+
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	i := int(float64(len(sorted))*p/100+0.5) - 1
+	if i < 0 {
+		i = 0
+	}
+	if i >= len(sorted) {
+		i = len(sorted) - 1
+	}
+	return sorted[i]
+}
+
+func sortDurations(d []time.Duration) {
+	sort.Slice(d, func(i, j int) bool { return d[i] < d[j] })
+}
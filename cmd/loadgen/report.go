@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+type opSamples struct {
+	latencies []time.Duration
+	errors    int
+	statuses  map[int]int
+}
+
+// recorder collects the outcome of every request
+type recorder struct {
+	mu  sync.Mutex
+	ops map[string]*opSamples
+}
+
+func newRecorder() *recorder {
+	return &recorder{ops: make(map[string]*opSamples)}
+}
+
+func (r *recorder) record(op string, d time.Duration, status int, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s, ok := r.ops[op]
+	if !ok {
+		s = &opSamples{statuses: make(map[int]int)}
+		r.ops[op] = s
+	}
+	s.latencies = append(s.latencies, d)
+	s.statuses[status]++
+	if err != nil {
+		s.errors++
+	}
+}
+
+// OpReport summarizes one operation. Latencies are in milliseconds.
+type OpReport struct {
+	Op          string             `json:"op"`
+	Requests    int                `json:"requests"`
+	Errors      int                `json:"errors"`
+	PerSecond   float64            `json:"per_second"`
+	Percentiles map[string]float64 `json:"latency_ms"`
+	Max         float64            `json:"max_ms"`
+	// Statuses counts responses by HTTP status; 0 is a transport error
+	Statuses map[int]int `json:"statuses"`
+}
+
+// Report is the result of a run
+type Report struct {
+	Mix         string     `json:"mix"`
+	Concurrency int        `json:"concurrency"`
+	Seconds     float64    `json:"seconds"`
+	Total       OpReport   `json:"total"`
+	Ops         []OpReport `json:"ops"`
+}
+
+func (r *recorder) report(elapsed time.Duration) Report {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rep := Report{Seconds: elapsed.Seconds()}
+	all := &opSamples{statuses: make(map[int]int)}
+	names := make([]string, 0, len(r.ops))
+	for op, s := range r.ops {
+		names = append(names, op)
+		all.latencies = append(all.latencies, s.latencies...)
+		all.errors += s.errors
+		for code, n := range s.statuses {
+			all.statuses[code] += n
+		}
+	}
+	sort.Strings(names)
+	for _, op := range names {
+		rep.Ops = append(rep.Ops, summarize(op, r.ops[op], elapsed))
+	}
+	rep.Total = summarize("total", all, elapsed)
+	return rep
+}
+
+func summarize(op string, s *opSamples, elapsed time.Duration) OpReport {
+	sortDurations(s.latencies)
+	rep := OpReport{
+		Op:          op,
+		Requests:    len(s.latencies),
+		Errors:      s.errors,
+		PerSecond:   float64(len(s.latencies)) / elapsed.Seconds(),
+		Percentiles: make(map[string]float64, len(percentiles)),
+		Statuses:    s.statuses,
+	}
+	for _, p := range percentiles {
+		rep.Percentiles[fmt.Sprintf("p%g", p)] = ms(percentile(s.latencies, p))
+	}
+	if n := len(s.latencies); n > 0 {
+		rep.Max = ms(s.latencies[n-1])
+	}
+	return rep
+}
+
+func ms(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+func (r Report) print(w io.Writer) {
+	fmt.Fprintf(w, "mix %s, %d clients, %.1fs\n\n", r.Mix, r.Concurrency, r.Seconds)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "op\trequests\terrors\treq/s\tp50 ms\tp90 ms\tp95 ms\tp99 ms\tmax ms\t")
+	for _, op := range append(r.Ops, r.Total) {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%.1f\t%.2f\t%.2f\t%.2f\t%.2f\t%.2f\t\n",
+			op.Op, op.Requests, op.Errors, op.PerSecond,
+			op.Percentiles["p50"], op.Percentiles["p90"], op.Percentiles["p95"], op.Percentiles["p99"], op.Max)
+	}
+	tw.Flush()
+}
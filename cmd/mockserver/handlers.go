@@ -0,0 +1,936 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"public_library/internal/admin"
+	"public_library/internal/book"
+	"public_library/internal/config"
+	"public_library/internal/health"
+	"public_library/internal/media"
+	"public_library/internal/middleware"
+	"public_library/internal/ratelimit"
+	"public_library/internal/readinglist"
+	"public_library/internal/series"
+	"public_library/internal/stats"
+	"public_library/internal/usage"
+	"public_library/utils"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+// mockAPI answers the routes of pkg/server from a store. Response types
+// come from the API's own packages so their JSON shape stays in step.
+type mockAPI struct {
+	store       *store
+	cfg         config.AppConfig
+	formats     *book.FormatCatalog
+	signer      *media.TokenSigner
+	maintenance *admin.Maintenance
+	admin       *admin.Handler
+	prefix      string
+}
+
+func newRouter(st *store, prefix string) *mux.Router {
+	cfg := config.Default()
+	formats, err := book.NewFormatCatalog(cfg.Formats.LoanDays)
+	if err != nil {
+		panic(err)
+	}
+	signer, err := media.NewTokenSigner("mockserver-stream-token-secret", cfg.Media.TokenTTL)
+	if err != nil {
+		panic(err)
+	}
+	maintenance := admin.NewMaintenance(cfg.Maintenance)
+	api := &mockAPI{
+		store:       st,
+		cfg:         cfg,
+		formats:     formats,
+		signer:      signer,
+		maintenance: maintenance,
+		admin:       admin.NewHandler(maintenance, zap.NewNop()),
+		prefix:      prefix,
+	}
+
+	// Maintenance mode behaves as in the real API, so clients can test their
+	// read-only banner with PUT /admin/maintenance
+	change := middleware.Maintenance(maintenance)
+
+	router := mux.NewRouter()
+	v1 := router.PathPrefix(prefix).Subrouter()
+	v1.HandleFunc("/health", api.health).Methods("GET")
+	v1.HandleFunc("/formats", api.listFormats).Methods("GET")
+	v1.HandleFunc("/books/list", api.listBooks).Methods("POST")
+	v1.Handle("/books/create", change(http.HandlerFunc(api.createBook))).Methods("POST")
+	v1.Handle("/books/import", change(http.HandlerFunc(api.importBooks))).Methods("POST")
+	v1.Handle("/books/bulk", change(http.HandlerFunc(api.bulkCreateBooks))).Methods("POST")
+	v1.Handle("/books/bulk-delete", change(http.HandlerFunc(api.bulkDeleteBooks))).Methods("POST")
+	v1.HandleFunc("/books/export", api.exportBooks).Methods("GET")
+	v1.HandleFunc("/books/shelf-report", api.shelfReport).Methods("GET")
+	v1.HandleFunc("/books/identifiers/{type}/{value}", api.getBookByIdentifier).Methods("GET")
+	v1.HandleFunc("/books/{id}", api.getBook).Methods("GET")
+	v1.Handle("/books/{id}", change(http.HandlerFunc(api.updateBook))).Methods("PUT")
+	v1.Handle("/books/{id}", change(http.HandlerFunc(api.patchBook))).Methods("PATCH")
+	v1.Handle("/books/{id}", change(http.HandlerFunc(api.deleteBook))).Methods("DELETE")
+	v1.Handle("/books/{id}/assets", change(http.HandlerFunc(api.uploadAsset))).Methods("POST")
+	v1.HandleFunc("/books/{id}/assets", api.listAssets).Methods("GET")
+	v1.HandleFunc("/media/assets/{id}/stream-token", api.issueStreamToken).Methods("POST")
+	v1.HandleFunc("/media/stream/{token}", api.streamAsset).Methods("GET")
+	v1.HandleFunc("/series", api.listSeries).Methods("GET")
+	v1.Handle("/series", change(http.HandlerFunc(api.createSeries))).Methods("POST")
+	v1.HandleFunc("/series/{id}", api.getSeries).Methods("GET")
+	v1.Handle("/series/{id}", change(http.HandlerFunc(api.updateSeries))).Methods("PUT")
+	v1.Handle("/series/{id}", change(http.HandlerFunc(api.deleteSeries))).Methods("DELETE")
+	v1.HandleFunc("/stats/books", api.bookStats).Methods("GET")
+
+	// Any bearer token is accepted; requests without one get 401 as they would
+	// from a real instance
+	adminRoutes := v1.PathPrefix("/admin").Subrouter()
+	adminRoutes.Use(requireBearer)
+	adminRoutes.HandleFunc("/maintenance", api.admin.GetMaintenance).Methods("GET")
+	adminRoutes.HandleFunc("/maintenance", api.admin.SetMaintenance).Methods("PUT")
+	adminRoutes.HandleFunc("/rate-limits", api.listRateLimits).Methods("GET")
+	adminRoutes.HandleFunc("/rate-limits/{key}", api.getRateLimit).Methods("GET")
+	adminRoutes.HandleFunc("/rate-limits/{key}", api.putRateLimit).Methods("PUT")
+	adminRoutes.HandleFunc("/rate-limits/{key}", api.deleteRateLimit).Methods("DELETE")
+	adminRoutes.HandleFunc("/api-keys/{id}/usage", api.keyUsage).Methods("GET")
+	return router
+}
+
+func requireBearer(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "Bearer ") {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+			http.Error(w, "admin token required", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeBookError answers like book.Handler.writeError
+func writeBookError(w http.ResponseWriter, err error) {
+	var dup *book.DuplicateError
+	switch {
+	case errors.As(err, &dup):
+		writeJSON(w, http.StatusConflict, book.ConflictResponse{Error: dup.Error(), ExistingID: dup.BookID})
+	case errors.Is(err, book.ErrNotFound):
+		http.Error(w, "book not found", http.StatusNotFound)
+	case errors.Is(err, book.ErrValidation), errors.Is(err, book.ErrInvalidSort), errors.Is(err, book.ErrInvalidImport):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, "internal server error", http.StatusInternalServerError)
+	}
+}
+
+func pathID(w http.ResponseWriter, r *http.Request, what string) (int, bool) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "invalid "+what+" ID", http.StatusBadRequest)
+		return 0, false
+	}
+	return id, true
+}
+
+func (a *mockAPI) health(w http.ResponseWriter, r *http.Request) {
+	now := time.Now().UTC().Format(time.RFC3339)
+	writeJSON(w, http.StatusOK, book.StatusResponse{
+		Status:    utils.StatusOK,
+		Version:   "v1.0.0-mock",
+		Timestamp: now,
+		Message:   utils.StatusOK,
+		Checks:    []health.Result{{Name: "database", Status: health.StatusUp, CheckedAt: now}},
+	})
+}
+
+func (a *mockAPI) listFormats(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, a.formats.All())
+}
+
+// visible applies the X-Catalog-Audience limit the gateway would send
+func visible(r *http.Request) (func(book.Book) bool, error) {
+	limit := strings.ToLower(strings.TrimSpace(r.Header.Get(middleware.AudienceHeader)))
+	if limit == "" {
+		return func(book.Book) bool { return true }, nil
+	}
+	if _, err := book.WithAudienceLimit(r.Context(), limit); err != nil {
+		return nil, err
+	}
+	order := []string{book.AudienceChildren, book.AudienceTeen, book.AudienceAdult}
+	for i, a := range order {
+		if a == limit {
+			allowed := order[:i+1]
+			return func(b book.Book) bool {
+				for _, a := range allowed {
+					if b.Audience == a {
+						return true
+					}
+				}
+				return false
+			}, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: unknown audience %q", book.ErrValidation, limit)
+}
+
+func (a *mockAPI) listBooks(w http.ResponseWriter, r *http.Request) {
+	var req book.PaginationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"error": "invalid request"}`, http.StatusBadRequest)
+		return
+	}
+	allowed, err := visible(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	terms := strings.Fields(strings.ToLower(req.Search))
+	list := a.store.sortedBooks(func(b book.Book) bool {
+		return allowed(b) && matches(b, req, terms)
+	})
+	if err := sortBooks(list, req.Sort); err != nil {
+		writeBookError(w, err)
+		return
+	}
+
+	page, size := req.Page, req.PageSize
+	if page < 1 {
+		page = 1
+	}
+	if size < 1 {
+		size = a.cfg.Catalog.DefaultPageSize
+	}
+	if size > a.cfg.Catalog.MaxPageSize {
+		size = a.cfg.Catalog.MaxPageSize
+	}
+	total := int64(len(list))
+	data := []book.BookResponse{}
+	for i := (page - 1) * size; i < len(list) && i < page*size; i++ {
+		data = append(data, a.listEntry(list[i]))
+	}
+	writeJSON(w, http.StatusOK, book.PaginationResponse{
+		TotalCount: total,
+		PageCount:  (total + int64(size) - 1) / int64(size),
+		Data:       data,
+	})
+}
+
+// matches applies the filters of a list request; every search term must
+// appear in the title, author, ISBN or description, or in their romanized
+// form
+func matches(b book.Book, req book.PaginationRequest, terms []string) bool {
+	if author := strings.TrimSpace(req.Author); author != "" && !strings.Contains(strings.ToLower(b.Author), strings.ToLower(author)) {
+		return false
+	}
+	if title := strings.TrimSpace(req.Title); title != "" && !strings.HasPrefix(strings.ToLower(b.Title), strings.ToLower(title)) {
+		return false
+	}
+	if isbn := strings.TrimSpace(req.ISBN); isbn != "" && !hasISBN(b, isbn) {
+		return false
+	}
+	if req.YearFrom != nil && (b.PublicationYear == nil || *b.PublicationYear < *req.YearFrom) {
+		return false
+	}
+	if req.YearTo != nil && (b.PublicationYear == nil || *b.PublicationYear > *req.YearTo) {
+		return false
+	}
+	if len(req.Formats) > 0 && !contains(req.Formats, b.Format) {
+		return false
+	}
+	if len(req.Audiences) > 0 && !contains(req.Audiences, b.Audience) {
+		return false
+	}
+	for _, f := range req.AccessibilityFeatures {
+		if !contains(b.AccessibilityFeatures, strings.TrimSpace(f)) {
+			return false
+		}
+	}
+	text := strings.ToLower(strings.Join([]string{b.Title, b.Author, b.ISBN, b.Description}, " "))
+	latin := book.Romanize(b.Title + " " + b.Author + " " + b.Romanized)
+	for _, t := range terms {
+		if !strings.Contains(text, t) && !strings.Contains(latin, book.Romanize(t)) {
+			return false
+		}
+	}
+	return true
+}
+
+// hasISBN reports whether the book carries isbn, ignoring hyphens and
+// spaces; unlike the API it does not match ISBN-10s to their ISBN-13 form
+func hasISBN(b book.Book, isbn string) bool {
+	strip := strings.NewReplacer("-", "", " ", "")
+	isbn = strings.ToUpper(strip.Replace(isbn))
+	if strings.ToUpper(strip.Replace(b.ISBN)) == isbn {
+		return true
+	}
+	for _, id := range b.Identifiers {
+		if (id.Type == book.IdentifierISBN10 || id.Type == book.IdentifierISBN13) && id.Value == isbn {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(list []string, v string) bool {
+	for _, s := range list {
+		if strings.EqualFold(s, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func sortBooks(list []book.Book, s *book.Sort) error {
+	if s == nil {
+		return nil
+	}
+	var less func(a, b book.Book) bool
+	switch strings.ToLower(s.Field) {
+	case "id":
+		less = func(a, b book.Book) bool { return a.ID < b.ID }
+	case "call_number":
+		less = func(a, b book.Book) bool {
+			return book.CallNumberSortKey(a.CallNumber) < book.CallNumberSortKey(b.CallNumber)
+		}
+	case "publication_year":
+		year := func(b book.Book) int {
+			if b.PublicationYear == nil {
+				return 0
+			}
+			return *b.PublicationYear
+		}
+		less = func(a, b book.Book) bool { return year(a) < year(b) }
+	default:
+		return fmt.Errorf("%w: cannot sort by %q", book.ErrInvalidSort, s.Field)
+	}
+	switch strings.ToLower(s.Order) {
+	case "", utils.ASC:
+		sort.SliceStable(list, func(i, j int) bool { return less(list[i], list[j]) })
+	case utils.DESC:
+		sort.SliceStable(list, func(i, j int) bool { return less(list[j], list[i]) })
+	default:
+		return fmt.Errorf("%w: order must be %q or %q", book.ErrInvalidSort, utils.ASC, utils.DESC)
+	}
+	return nil
+}
+
+// listEntry is the list view of a book, with its description shortened
+func (a *mockAPI) listEntry(b book.Book) book.BookResponse {
+	description := b.Description
+	if limit := a.cfg.Catalog.DescriptionPreview; limit > 0 && utf8.RuneCountInString(description) > limit {
+		cut := string([]rune(description)[:limit])
+		if i := strings.LastIndexAny(cut, " \t\n"); i > 0 {
+			cut = cut[:i]
+		}
+		description = strings.TrimRight(cut, " \t\n.,;:") + "…"
+	}
+	return book.BookResponse{
+		ID: b.ID, Title: b.Title, Author: b.Author, Description: description,
+		ISBN: b.ISBN, Identifiers: b.Identifiers, CallNumber: b.CallNumber,
+		ShelfLocation: b.ShelfLocation, Collection: b.Collection,
+		PublicationYear: b.PublicationYear, Edition: b.Edition, PageCount: b.PageCount,
+		Format: b.Format, Series: b.Series, Audience: b.Audience,
+		AccessibilityFeatures: b.AccessibilityFeatures, Language: b.Language,
+		Romanized: b.Romanized,
+	}
+}
+
+func (a *mockAPI) getBook(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "book")
+	if !ok {
+		return
+	}
+	allowed, err := visible(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	b, found := a.store.book(id)
+	if !found || !allowed(b) {
+		http.Error(w, "book not found", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, b)
+}
+
+func (a *mockAPI) getBookByIdentifier(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := book.NormalizeIdentifier(vars["type"], vars["value"])
+	if err != nil {
+		writeBookError(w, err)
+		return
+	}
+	allowed, err := visible(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	b, found := a.store.bookByIdentifier(id)
+	if !found || !allowed(b) {
+		http.Error(w, "book not found", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, b)
+}
+
+func (a *mockAPI) createBook(w http.ResponseWriter, r *http.Request) {
+	var b book.Book
+	if err := json.NewDecoder(r.Body).Decode(&b); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if err := a.store.createBook(&b); err != nil {
+		writeBookError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, b)
+}
+
+// bulkCreateBooks creates each valid book of the array and reports the
+// others by index, like the real bulk create; books repeating an identifier
+// of an earlier one are reported as duplicates of the book it created
+func (a *mockAPI) bulkCreateBooks(w http.ResponseWriter, r *http.Request) {
+	var books []book.Book
+	if err := json.NewDecoder(r.Body).Decode(&books); err != nil {
+		http.Error(w, "invalid JSON: expected an array of books", http.StatusBadRequest)
+		return
+	}
+	if len(books) == 0 || len(books) > 1000 {
+		http.Error(w, "validation failed: between 1 and 1000 books are required", http.StatusBadRequest)
+		return
+	}
+	out := book.BulkCreateResponse{Created: []book.BulkCreated{}, Errors: []book.BulkError{}}
+	for i := range books {
+		if err := a.store.createBook(&books[i]); err != nil {
+			e := book.BulkError{Index: i, Error: err.Error()}
+			var dup *book.DuplicateError
+			if errors.As(err, &dup) {
+				e.ExistingID = dup.BookID
+			}
+			out.Errors = append(out.Errors, e)
+			continue
+		}
+		out.Created = append(out.Created, book.BulkCreated{Index: i, ID: books[i].ID})
+	}
+	status := http.StatusCreated
+	if len(out.Errors) > 0 {
+		status = http.StatusOK
+	}
+	writeJSON(w, status, out)
+}
+
+func (a *mockAPI) bulkDeleteBooks(w http.ResponseWriter, r *http.Request) {
+	var ids []int
+	if err := json.NewDecoder(r.Body).Decode(&ids); err != nil {
+		http.Error(w, "invalid JSON: expected an array of book IDs", http.StatusBadRequest)
+		return
+	}
+	if len(ids) == 0 || len(ids) > 1000 {
+		http.Error(w, "validation failed: between 1 and 1000 ids are required", http.StatusBadRequest)
+		return
+	}
+	out := book.BulkDeleteResponse{Deleted: []int{}, NotFound: []int{}, Conflict: []int{}}
+	seen := make(map[int]bool)
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		if err := a.store.deleteBook(id); err != nil {
+			out.NotFound = append(out.NotFound, id)
+		} else {
+			out.Deleted = append(out.Deleted, id)
+		}
+	}
+	sort.Ints(out.Deleted)
+	sort.Ints(out.NotFound)
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (a *mockAPI) updateBook(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	var b book.Book
+	if err := json.NewDecoder(r.Body).Decode(&b); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	b.ID = id
+	if err := a.store.updateBook(&b); err != nil {
+		writeBookError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, b)
+}
+
+func (a *mockAPI) patchBook(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "book")
+	if !ok {
+		return
+	}
+	var p book.Patch
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if err := p.Validate(); err != nil {
+		writeBookError(w, err)
+		return
+	}
+	b, err := a.store.patchBook(id, p)
+	if err != nil {
+		writeBookError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, b)
+}
+
+func (a *mockAPI) deleteBook(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	if err := a.store.deleteBook(id); err != nil {
+		writeBookError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// importBooks accepts the CSV layout of the real import: a header naming at
+// least title, author and isbn, then one book per row. Like the real import
+// it is all or nothing.
+func (a *mockAPI) importBooks(w http.ResponseWriter, r *http.Request) {
+	rows, err := csv.NewReader(r.Body).ReadAll()
+	if err != nil || len(rows) == 0 {
+		http.Error(w, "invalid import file: cannot read CSV", http.StatusBadRequest)
+		return
+	}
+	col := make(map[string]int)
+	for i, name := range rows[0] {
+		col[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"title", "author", "isbn"} {
+		if _, ok := col[required]; !ok {
+			http.Error(w, "invalid import file: missing column "+required, http.StatusBadRequest)
+			return
+		}
+	}
+	field := func(row []string, name string) string {
+		if i, ok := col[name]; ok && i < len(row) {
+			return strings.TrimSpace(row[i])
+		}
+		return ""
+	}
+	optionalInt := func(s string) *int {
+		if n, err := strconv.Atoi(s); err == nil {
+			return &n
+		}
+		return nil
+	}
+
+	books := make([]book.Book, 0, len(rows)-1)
+	for _, row := range rows[1:] {
+		books = append(books, book.Book{
+			Title: field(row, "title"), Author: field(row, "author"), ISBN: field(row, "isbn"),
+			Description: field(row, "description"), CallNumber: field(row, "call_number"),
+			ShelfLocation: field(row, "shelf_location"), Collection: field(row, "collection"),
+			PublicationYear: optionalInt(field(row, "publication_year")), Edition: field(row, "edition"),
+			PageCount: optionalInt(field(row, "page_count")), Format: field(row, "format"),
+			Audience:              field(row, "audience"),
+			Language:              field(row, "language"),
+			Romanized:             field(row, "romanized"),
+			AccessibilityFeatures: strings.FieldsFunc(field(row, "accessibility_features"), func(r rune) bool { return r == ';' }),
+		})
+	}
+	if err := a.store.importBooks(books); err != nil {
+		writeBookError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, book.ImportResponse{Imported: int64(len(books))})
+}
+
+func (a *mockAPI) exportBooks(w http.ResponseWriter, r *http.Request) {
+	afterID := 0
+	if v := r.URL.Query().Get("after_id"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil || id < 0 {
+			http.Error(w, "invalid after_id", http.StatusBadRequest)
+			return
+		}
+		afterID = id
+	}
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", `attachment; filename="books.csv"`)
+	cw := csv.NewWriter(w)
+	if afterID == 0 {
+		cw.Write([]string{
+			"id", "title", "author", "description", "isbn",
+			"call_number", "shelf_location", "collection",
+			"publication_year", "edition", "page_count", "format",
+			"identifiers", "series_id", "series_position", "audience",
+			"accessibility_features", "language", "romanized",
+		})
+	}
+	optionalInt := func(n *int) string {
+		if n == nil {
+			return ""
+		}
+		return strconv.Itoa(*n)
+	}
+	for _, b := range a.store.sortedBooks(func(b book.Book) bool { return b.ID > afterID }) {
+		ids := make([]string, len(b.Identifiers))
+		for i, id := range b.Identifiers {
+			ids[i] = id.Type + ":" + id.Value
+		}
+		seriesID, position := "", ""
+		if b.Series != nil {
+			seriesID = strconv.Itoa(b.Series.ID)
+			if b.Series.Position != nil {
+				position = strconv.FormatFloat(*b.Series.Position, 'f', -1, 64)
+			}
+		}
+		cw.Write([]string{
+			strconv.Itoa(b.ID), b.Title, b.Author, b.Description, b.ISBN,
+			b.CallNumber, b.ShelfLocation, b.Collection,
+			optionalInt(b.PublicationYear), b.Edition, optionalInt(b.PageCount), b.Format,
+			strings.Join(ids, ";"), seriesID, position, b.Audience,
+			strings.Join(b.AccessibilityFeatures, ";"), b.Language, b.Romanized,
+		})
+	}
+	cw.Flush()
+}
+
+func (a *mockAPI) shelfReport(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	from, to, collection := strings.TrimSpace(q.Get("from")), strings.TrimSpace(q.Get("to")), q.Get("collection")
+	if from == "" || to == "" {
+		http.Error(w, "validation failed: from and to are required", http.StatusBadRequest)
+		return
+	}
+	lo, hi := book.CallNumberSortKey(from), book.CallNumberSortKey(to)
+	list := a.store.sortedBooks(func(b book.Book) bool {
+		key := book.CallNumberSortKey(b.CallNumber)
+		return b.CallNumber != "" && key >= lo && (key <= hi || strings.HasPrefix(key, hi)) &&
+			(collection == "" || b.Collection == collection)
+	})
+	sort.SliceStable(list, func(i, j int) bool {
+		return book.CallNumberSortKey(list[i].CallNumber) < book.CallNumberSortKey(list[j].CallNumber)
+	})
+
+	report := book.ShelfReport{From: from, To: to, Shelves: []book.ShelfGroup{}}
+	shelfIndex := make(map[string]int)
+	for _, b := range list {
+		i, ok := shelfIndex[b.ShelfLocation]
+		if !ok {
+			i = len(report.Shelves)
+			shelfIndex[b.ShelfLocation] = i
+			report.Shelves = append(report.Shelves, book.ShelfGroup{ShelfLocation: b.ShelfLocation})
+		}
+		report.Shelves[i].Items = append(report.Shelves[i].Items, book.ShelfItem{
+			ID: b.ID, Title: b.Title, Author: b.Author, CallNumber: b.CallNumber, Collection: b.Collection,
+		})
+		report.TotalItems++
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+func (a *mockAPI) uploadAsset(w http.ResponseWriter, r *http.Request) {
+	bookID, ok := pathID(w, r, "book")
+	if !ok {
+		return
+	}
+	filename := strings.TrimSpace(r.URL.Query().Get("filename"))
+	if filename == "" {
+		http.Error(w, "filename is required", http.StatusBadRequest)
+		return
+	}
+	contentType, ok := media.UploadContentType(r.Header.Get("Content-Type"))
+	if !ok {
+		http.Error(w, media.ErrContentType.Error(), http.StatusBadRequest)
+		return
+	}
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, a.cfg.Media.MaxUploadBytes))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, "file too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "upload failed", http.StatusInternalServerError)
+		return
+	}
+	asset, err := a.store.addAsset(storedAsset{
+		Asset: media.Asset{BookID: bookID, Filename: filename, ContentType: contentType, SizeBytes: int64(len(data))},
+		data:  data,
+	})
+	if err != nil {
+		http.Error(w, "book not found", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, http.StatusCreated, asset)
+}
+
+func (a *mockAPI) listAssets(w http.ResponseWriter, r *http.Request) {
+	bookID, ok := pathID(w, r, "book")
+	if !ok {
+		return
+	}
+	if _, found := a.store.book(bookID); !found {
+		http.Error(w, "book not found", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, a.store.bookAssets(bookID))
+}
+
+// issueStreamToken issues a token for the loan_id of the X-Member-ID
+// member; there are no loans here, so any loan is taken to be the member's
+func (a *mockAPI) issueStreamToken(w http.ResponseWriter, r *http.Request) {
+	assetID, ok := pathID(w, r, "asset")
+	if !ok {
+		return
+	}
+	member := strings.TrimSpace(r.Header.Get(readinglist.MemberHeader))
+	if member == "" {
+		http.Error(w, readinglist.MemberHeader+" header is required", http.StatusUnauthorized)
+		return
+	}
+	var req media.StreamTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if req.LoanID <= 0 {
+		http.Error(w, "loan_id is required", http.StatusBadRequest)
+		return
+	}
+	if _, found := a.store.asset(assetID); !found {
+		http.Error(w, "asset not found", http.StatusNotFound)
+		return
+	}
+	token, expiresAt := a.signer.Issue(media.Grant{AssetID: assetID, LoanID: req.LoanID, MemberID: member}, time.Now())
+	writeJSON(w, http.StatusOK, media.StreamTokenResponse{
+		Token:     token,
+		URL:       a.prefix + "/media/stream/" + token,
+		ExpiresAt: expiresAt,
+	})
+}
+
+func (a *mockAPI) streamAsset(w http.ResponseWriter, r *http.Request) {
+	grant, err := a.signer.Verify(mux.Vars(r)["token"], time.Now())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	asset, found := a.store.asset(grant.AssetID)
+	if !found {
+		http.Error(w, "asset not found", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", asset.ContentType)
+	w.Header().Set("Cache-Control", "private, no-store")
+	http.ServeContent(w, r, asset.Filename, asset.CreatedAt, bytes.NewReader(asset.data))
+}
+
+func (a *mockAPI) listSeries(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, a.store.listSeries())
+}
+
+func (a *mockAPI) getSeries(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "series")
+	if !ok {
+		return
+	}
+	sr, found := a.store.seriesWithVolumes(id)
+	if !found {
+		http.Error(w, "series not found", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, sr)
+}
+
+func decodeSeries(w http.ResponseWriter, r *http.Request, s *series.Series) bool {
+	if err := json.NewDecoder(r.Body).Decode(s); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return false
+	}
+	s.Title = strings.TrimSpace(s.Title)
+	if s.Title == "" {
+		http.Error(w, "title is required", http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+func (a *mockAPI) createSeries(w http.ResponseWriter, r *http.Request) {
+	var s series.Series
+	if !decodeSeries(w, r, &s) {
+		return
+	}
+	a.store.createSeries(&s)
+	writeJSON(w, http.StatusCreated, s)
+}
+
+func (a *mockAPI) updateSeries(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "series")
+	if !ok {
+		return
+	}
+	var s series.Series
+	if !decodeSeries(w, r, &s) {
+		return
+	}
+	s.ID = id
+	if err := a.store.updateSeries(s); err != nil {
+		http.Error(w, "series not found", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, http.StatusOK, s)
+}
+
+func (a *mockAPI) deleteSeries(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "series")
+	if !ok {
+		return
+	}
+	if err := a.store.deleteSeries(id); err != nil {
+		http.Error(w, "series not found", http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// bookStats is computed on every request; the real API serves a snapshot
+// refreshed every stats.refresh_interval
+func (a *mockAPI) bookStats(w http.ResponseWriter, r *http.Request) {
+	counts := make(map[string]int64)
+	list := a.store.sortedBooks(nil)
+	for _, b := range list {
+		counts[b.Author]++
+	}
+	top := make([]stats.AuthorCount, 0, len(counts))
+	for author, n := range counts {
+		top = append(top, stats.AuthorCount{Author: author, BookCount: n})
+	}
+	sort.Slice(top, func(i, j int) bool {
+		if top[i].BookCount != top[j].BookCount {
+			return top[i].BookCount > top[j].BookCount
+		}
+		return top[i].Author < top[j].Author
+	})
+	if len(top) > 10 {
+		top = top[:10]
+	}
+	writeJSON(w, http.StatusOK, stats.BookStatsResponse{
+		TotalBooks:   int64(len(list)),
+		TotalAuthors: int64(len(counts)),
+		TopAuthors:   top,
+		RefreshedAt:  time.Now().UTC(),
+	})
+}
+
+func (a *mockAPI) defaultLimit() ratelimit.Limit {
+	c := a.cfg.RateLimit
+	return ratelimit.Limit{RequestsPerMinute: c.RequestsPerMinute, Burst: c.Burst, DailyQuota: c.DailyQuota}
+}
+
+func (a *mockAPI) keyStatus(key string) ratelimit.KeyStatus {
+	a.store.mu.RLock()
+	defer a.store.mu.RUnlock()
+	if kl, ok := a.store.limits[key]; ok {
+		updated := kl.UpdatedAt
+		return ratelimit.KeyStatus{APIKeyID: key, Limit: kl.Limit, Override: true, UpdatedAt: &updated}
+	}
+	return ratelimit.KeyStatus{APIKeyID: key, Limit: a.defaultLimit()}
+}
+
+func (a *mockAPI) listRateLimits(w http.ResponseWriter, r *http.Request) {
+	a.store.mu.RLock()
+	keys := make([]string, 0, len(a.store.limits))
+	for k := range a.store.limits {
+		keys = append(keys, k)
+	}
+	a.store.mu.RUnlock()
+	sort.Strings(keys)
+	overrides := make([]ratelimit.KeyStatus, len(keys))
+	for i, k := range keys {
+		overrides[i] = a.keyStatus(k)
+	}
+	writeJSON(w, http.StatusOK, ratelimit.LimitsResponse{Default: a.defaultLimit(), Overrides: overrides})
+}
+
+func (a *mockAPI) getRateLimit(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, a.keyStatus(mux.Vars(r)["key"]))
+}
+
+func (a *mockAPI) putRateLimit(w http.ResponseWriter, r *http.Request) {
+	var l ratelimit.Limit
+	if err := json.NewDecoder(r.Body).Decode(&l); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if l.RequestsPerMinute < 0 || l.Burst < 0 || l.DailyQuota < 0 {
+		http.Error(w, "limits must not be negative", http.StatusBadRequest)
+		return
+	}
+	key := mux.Vars(r)["key"]
+	a.store.mu.Lock()
+	a.store.limits[key] = ratelimit.KeyLimit{APIKeyID: key, Limit: l, UpdatedAt: time.Now().UTC()}
+	a.store.mu.Unlock()
+	writeJSON(w, http.StatusOK, a.keyStatus(key))
+}
+
+func (a *mockAPI) deleteRateLimit(w http.ResponseWriter, r *http.Request) {
+	key := mux.Vars(r)["key"]
+	a.store.mu.Lock()
+	_, ok := a.store.limits[key]
+	delete(a.store.limits, key)
+	a.store.mu.Unlock()
+	if !ok {
+		http.Error(w, "no rate limit override for this key", http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// keyUsage reports no traffic; the mock does not count requests
+func (a *mockAPI) keyUsage(w http.ResponseWriter, r *http.Request) {
+	to := time.Now().UTC()
+	from := to.AddDate(0, 0, -29)
+	if v := r.URL.Query().Get("from"); v != "" {
+		t, err := time.Parse(time.DateOnly, v)
+		if err != nil {
+			http.Error(w, "from must be a date (YYYY-MM-DD)", http.StatusBadRequest)
+			return
+		}
+		from = t
+	}
+	if v := r.URL.Query().Get("to"); v != "" {
+		t, err := time.Parse(time.DateOnly, v)
+		if err != nil {
+			http.Error(w, "to must be a date (YYYY-MM-DD)", http.StatusBadRequest)
+			return
+		}
+		to = t
+	}
+	writeJSON(w, http.StatusOK, usage.Report{
+		APIKeyID:     mux.Vars(r)["id"],
+		From:         from.Format(time.DateOnly),
+		To:           to.Format(time.DateOnly),
+		TopEndpoints: []usage.EndpointUsage{},
+		Daily:        []usage.DailyUsage{},
+	})
+}
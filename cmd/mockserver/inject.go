@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Headers a client sends to shape the response to one request
+const (
+	// mockStatusHeader answers the request with this status and an error body
+	mockStatusHeader = "X-Mock-Status"
+	// mockLatencyHeader delays the request by this duration ("1.5s") instead
+	// of the configured latency
+	mockLatencyHeader = "X-Mock-Latency"
+)
+
+// injector delays responses and replaces some of them with errors
+type injector struct {
+	latency     time.Duration
+	jitter      time.Duration
+	errorRate   float64
+	errorStatus int
+	seed        int64
+
+	mu  sync.Mutex
+	rng *rand.Rand
+}
+
+func (in *injector) wrap(next http.Handler) http.Handler {
+	in.rng = rand.New(rand.NewSource(in.seed))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		delay, fail := in.draw()
+		if v := r.Header.Get(mockLatencyHeader); v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil || d < 0 {
+				http.Error(w, "invalid "+mockLatencyHeader, http.StatusBadRequest)
+				return
+			}
+			delay = d
+		}
+		status := 0
+		if fail {
+			status = in.errorStatus
+		}
+		if v := r.Header.Get(mockStatusHeader); v != "" {
+			code, err := strconv.Atoi(v)
+			if err != nil || code < 100 || code > 599 {
+				http.Error(w, "invalid "+mockStatusHeader, http.StatusBadRequest)
+				return
+			}
+			status = code
+		}
+
+		if delay > 0 {
+			select {
+			case <-time.After(delay):
+			case <-r.Context().Done():
+				return
+			}
+		}
+		if status != 0 {
+			writeInjected(w, status)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// draw picks the delay of one request and whether it fails
+func (in *injector) draw() (time.Duration, bool) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	delay := in.latency
+	if in.jitter > 0 {
+		delay += time.Duration(in.rng.Int63n(int64(in.jitter) + 1))
+	}
+	return delay, in.errorRate > 0 && in.rng.Float64() < in.errorRate
+}
+
+// writeInjected answers like the real API does for the same status
+func writeInjected(w http.ResponseWriter, status int) {
+	switch status {
+	case http.StatusTooManyRequests:
+		w.Header().Set("Retry-After", "30")
+		w.Header().Set("X-Quota-Remaining", "0")
+		http.Error(w, "rate limit exceeded", status)
+	case http.StatusServiceUnavailable:
+		w.Header().Set("Retry-After", "60")
+		http.Error(w, "service temporarily unavailable", status)
+	default:
+		if status < 400 {
+			w.WriteHeader(status)
+			return
+		}
+		http.Error(w, fmt.Sprintf("injected error: %s", http.StatusText(status)), status)
+	}
+}
@@ -0,0 +1,83 @@
+// Command mockserver serves the library API from in-memory data, so frontend
+// and mobile clients can be developed without Postgres or a running
+// instance:
+//
+//	go run ./cmd/mockserver -addr :8081 -books 500 -latency 80ms -jitter 40ms -error-rate 0.02
+//
+// The catalog is generated with pkg/fixtures from -seed and lives until the
+// process exits; writes change it like they would the real API. Every
+// response can be delayed and a share of them replaced by errors, and a
+// single request can ask for a status or delay with the X-Mock-Status and
+// X-Mock-Latency headers.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+)
+
+func main() {
+	var (
+		addr        = flag.String("addr", ":8081", "listen address")
+		prefix      = flag.String("prefix", "/api/v1", "path prefix of the API routes")
+		books       = flag.Int("books", 200, "number of generated books")
+		seed        = flag.Int64("seed", 1, "seed for the generated catalog")
+		latency     = flag.Duration("latency", 0, "delay added to every response")
+		jitter      = flag.Duration("jitter", 0, "random extra delay of up to this much per response")
+		errorRate   = flag.Float64("error-rate", 0, "share of requests (0-1) answered with -error-status instead")
+		errorStatus = flag.Int("error-status", http.StatusServiceUnavailable, "status of injected errors")
+	)
+	flag.Parse()
+
+	if *errorRate < 0 || *errorRate > 1 {
+		log.Fatal("-error-rate must be between 0 and 1")
+	}
+	if *errorStatus < 400 || *errorStatus > 599 {
+		log.Fatal("-error-status must be a 4xx or 5xx status")
+	}
+
+	st := newStore(*seed, *books)
+	inj := &injector{
+		latency:     *latency,
+		jitter:      *jitter,
+		errorRate:   *errorRate,
+		errorStatus: *errorStatus,
+		seed:        *seed,
+	}
+	srv := &http.Server{Addr: *addr, Handler: cors(inj.wrap(newRouter(st, *prefix)))}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	}()
+
+	log.Printf("Mock API with %d books on %s%s", *books, *addr, *prefix)
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Fatal(err)
+	}
+}
+
+// cors lets browser apps served from another origin call the mock
+func cors(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type, X-API-Key-ID, X-Catalog-Audience, X-Mock-Status, X-Mock-Latency")
+		w.Header().Set("Access-Control-Expose-Headers", "Retry-After, X-Quota-Remaining")
+		if r.Method == http.MethodOptions {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,369 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"public_library/internal/book"
+	"public_library/internal/media"
+	"public_library/internal/ratelimit"
+	"public_library/internal/sandbox"
+	"public_library/internal/series"
+	"public_library/pkg/fixtures"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var errNotFound = errors.New("not found")
+
+// storedAsset is an uploaded file kept in memory
+type storedAsset struct {
+	media.Asset
+	data []byte
+}
+
+// store is the mock's catalog. It applies the same validation as the real
+// API (book.Validate, identifier normalization, duplicate identifiers) but
+// keeps everything in maps.
+type store struct {
+	mu     sync.RWMutex
+	books  map[int]book.Book
+	series map[int]series.Series
+	assets map[int]storedAsset
+	limits map[string]ratelimit.KeyLimit
+
+	nextBook, nextSeries, nextAsset int
+}
+
+// newStore fills the catalog with the sandbox's demo books and series plus
+// n books generated from seed
+func newStore(seed int64, n int) *store {
+	s := &store{
+		books:  make(map[int]book.Book),
+		series: make(map[int]series.Series),
+		assets: make(map[int]storedAsset),
+		limits: make(map[string]ratelimit.KeyLimit),
+	}
+	ids := make([]int, len(sandbox.DemoSeries))
+	for i, sr := range sandbox.DemoSeries {
+		s.createSeries(&sr)
+		ids[i] = sr.ID
+	}
+	for _, b := range sandbox.DemoBooks {
+		if b.Series != nil {
+			ref := *b.Series
+			ref.ID = ids[ref.ID-1]
+			b.Series = &ref
+		}
+		if err := s.createBook(&b); err != nil {
+			panic(fmt.Sprintf("demo book %q: %v", b.Title, err))
+		}
+	}
+	// Generated ISBNs may collide with a demo book; those are skipped
+	for _, b := range fixtures.New(seed, time.Now()).Books(n) {
+		s.createBook(&b)
+	}
+	return s
+}
+
+// checkBook validates b and fills its normalized identifiers and primary
+// ISBN, as the real service does before writing
+func (s *store) checkBook(b *book.Book) error {
+	if err := b.Validate(); err != nil {
+		return err
+	}
+	var ids []book.Identifier
+	seen := make(map[book.Identifier]bool)
+	add := func(idType, value string) error {
+		id, err := book.NormalizeIdentifier(idType, value)
+		if err != nil {
+			return err
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+		return nil
+	}
+	if isbn := strings.NewReplacer("-", "", " ", "").Replace(b.ISBN); isbn != "" {
+		idType := book.IdentifierISBN13
+		if len(isbn) == 10 {
+			idType = book.IdentifierISBN10
+		}
+		if err := add(idType, isbn); err != nil {
+			return err
+		}
+	}
+	for _, id := range b.Identifiers {
+		if err := add(id.Type, id.Value); err != nil {
+			return err
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if ids[i].Type != ids[j].Type {
+			return ids[i].Type < ids[j].Type
+		}
+		return ids[i].Value < ids[j].Value
+	})
+	b.Identifiers = ids
+	b.ISBN = ""
+	for _, id := range ids {
+		if id.Type == book.IdentifierISBN13 || (id.Type == book.IdentifierISBN10 && b.ISBN == "") {
+			b.ISBN = id.Value
+		}
+	}
+
+	for _, other := range s.books {
+		if other.ID == b.ID {
+			continue
+		}
+		for _, have := range other.Identifiers {
+			if seen[have] {
+				return &book.DuplicateError{BookID: other.ID, Identifier: have}
+			}
+		}
+	}
+	if b.Series != nil {
+		sr, ok := s.series[b.Series.ID]
+		if !ok {
+			return fmt.Errorf("%w: series %d does not exist", book.ErrValidation, b.Series.ID)
+		}
+		b.Series = seriesRef(sr, b.Series.Position)
+	}
+	return nil
+}
+
+func seriesRef(sr series.Series, position *float64) *book.SeriesRef {
+	ref := &book.SeriesRef{ID: sr.ID, Title: sr.Title, Position: position, Label: sr.Title}
+	if position != nil {
+		ref.Label = sr.Title + " #" + strconv.FormatFloat(*position, 'f', -1, 64)
+	}
+	return ref
+}
+
+func (s *store) createBook(b *book.Book) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b.ID = 0
+	if err := s.checkBook(b); err != nil {
+		return err
+	}
+	s.nextBook++
+	b.ID = s.nextBook
+	s.books[b.ID] = *b
+	return nil
+}
+
+// importBooks adds all of books or, if one fails validation, none of them
+func (s *store) importBooks(books []book.Book) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	first := s.nextBook
+	for i := range books {
+		b := &books[i]
+		b.ID = 0
+		if err := s.checkBook(b); err != nil {
+			for id := first + 1; id <= s.nextBook; id++ {
+				delete(s.books, id)
+			}
+			s.nextBook = first
+			return fmt.Errorf("%w: row %d: %v", book.ErrInvalidImport, i+2, err)
+		}
+		s.nextBook++
+		b.ID = s.nextBook
+		s.books[b.ID] = *b
+	}
+	return nil
+}
+
+func (s *store) updateBook(b *book.Book) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.books[b.ID]; !ok {
+		return book.ErrNotFound
+	}
+	if err := s.checkBook(b); err != nil {
+		return err
+	}
+	s.books[b.ID] = *b
+	return nil
+}
+
+// patchBook applies p to the book with id under one lock, like the row lock
+// of the real patch
+func (s *store) patchBook(id int, p book.Patch) (*book.Book, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	current, ok := s.books[id]
+	if !ok {
+		return nil, book.ErrNotFound
+	}
+	b, err := p.Apply(current)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkBook(b); err != nil {
+		return nil, err
+	}
+	s.books[id] = *b
+	return b, nil
+}
+
+func (s *store) deleteBook(id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.books[id]; !ok {
+		return book.ErrNotFound
+	}
+	delete(s.books, id)
+	for aid, a := range s.assets {
+		if a.BookID == id {
+			delete(s.assets, aid)
+		}
+	}
+	return nil
+}
+
+func (s *store) book(id int) (book.Book, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	b, ok := s.books[id]
+	return b, ok
+}
+
+// sortedBooks returns the books passing keep, in id order
+func (s *store) sortedBooks(keep func(book.Book) bool) []book.Book {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var list []book.Book
+	for _, b := range s.books {
+		if keep == nil || keep(b) {
+			list = append(list, b)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list
+}
+
+func (s *store) bookByIdentifier(id book.Identifier) (book.Book, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, b := range s.books {
+		for _, have := range b.Identifiers {
+			if have == id {
+				return b, true
+			}
+		}
+	}
+	return book.Book{}, false
+}
+
+func (s *store) listSeries() []series.Series {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	list := make([]series.Series, 0, len(s.series))
+	for _, sr := range s.series {
+		list = append(list, sr)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Title < list[j].Title })
+	return list
+}
+
+// seriesWithVolumes returns a series and its books in reading order
+func (s *store) seriesWithVolumes(id int) (series.SeriesResponse, bool) {
+	s.mu.RLock()
+	sr, ok := s.series[id]
+	s.mu.RUnlock()
+	if !ok {
+		return series.SeriesResponse{}, false
+	}
+	volumes := s.sortedBooks(func(b book.Book) bool { return b.Series != nil && b.Series.ID == id })
+	sort.SliceStable(volumes, func(i, j int) bool {
+		pi, pj := volumes[i].Series.Position, volumes[j].Series.Position
+		if pi == nil || pj == nil {
+			return pj == nil && pi != nil
+		}
+		return *pi < *pj
+	})
+	if volumes == nil {
+		volumes = []book.Book{}
+	}
+	return series.SeriesResponse{Series: sr, Volumes: volumes}, true
+}
+
+func (s *store) createSeries(sr *series.Series) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextSeries++
+	sr.ID = s.nextSeries
+	s.series[sr.ID] = *sr
+}
+
+// updateSeries renames a series, and the series info shown on its books
+func (s *store) updateSeries(sr series.Series) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.series[sr.ID]; !ok {
+		return errNotFound
+	}
+	s.series[sr.ID] = sr
+	for id, b := range s.books {
+		if b.Series != nil && b.Series.ID == sr.ID {
+			b.Series = seriesRef(sr, b.Series.Position)
+			s.books[id] = b
+		}
+	}
+	return nil
+}
+
+// deleteSeries removes a series; its books stay without one
+func (s *store) deleteSeries(id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.series[id]; !ok {
+		return errNotFound
+	}
+	delete(s.series, id)
+	for bid, b := range s.books {
+		if b.Series != nil && b.Series.ID == id {
+			b.Series = nil
+			s.books[bid] = b
+		}
+	}
+	return nil
+}
+
+func (s *store) addAsset(a storedAsset) (media.Asset, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.books[a.BookID]; !ok {
+		return media.Asset{}, book.ErrNotFound
+	}
+	s.nextAsset++
+	a.ID = s.nextAsset
+	a.CreatedAt = time.Now().UTC()
+	s.assets[a.ID] = a
+	return a.Asset, nil
+}
+
+func (s *store) bookAssets(bookID int) []media.Asset {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	list := []media.Asset{}
+	for _, a := range s.assets {
+		if a.BookID == bookID {
+			list = append(list, a.Asset)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list
+}
+
+func (s *store) asset(id int) (storedAsset, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	a, ok := s.assets[id]
+	return a, ok
+}
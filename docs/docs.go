@@ -15,35 +15,92 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/books/create": {
-            "post": {
-                "description": "Add a book to the library",
-                "consumes": [
-                    "application/json"
-                ],
+        "/acquisitions/report": {
+            "get": {
+                "description": "Totals the copies bought in a period by funding source. Amounts are summed per currency and converted to the report currency with the configured exchange rates; currencies without a rate are listed in missing_rates and left out of the converted totals.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "books"
+                    "acquisitions"
                 ],
-                "summary": "Create a new book",
+                "summary": "Report acquisition spending by funding source",
                 "parameters": [
                     {
-                        "description": "Book to create",
-                        "name": "book",
-                        "in": "body",
-                        "required": true,
+                        "type": "string",
+                        "description": "First purchase date, YYYY-MM-DD",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last purchase date, YYYY-MM-DD",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Report currency; defaults to acquisitions.currency",
+                        "name": "currency",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/book.Book"
+                            "$ref": "#/definitions/acquisition.Report"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     }
+                }
+            }
+        },
+        "/acquisitions/valuation": {
+            "get": {
+                "description": "Totals the replacement cost of every acquired copy, or its price where no replacement cost is recorded, by branch and by format within each branch, for the insurance renewal. Amounts are converted to the report currency like the acquisition report. format=csv or format=pdf downloads the report as a file.",
+                "produces": [
+                    "application/json",
+                    "text/csv",
+                    "application/pdf"
+                ],
+                "tags": [
+                    "acquisitions"
+                ],
+                "summary": "Report the replacement value of the collection",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Report currency; defaults to acquisitions.currency",
+                        "name": "currency",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "csv",
+                            "pdf"
+                        ],
+                        "type": "string",
+                        "default": "json",
+                        "description": "Output format",
+                        "name": "format",
+                        "in": "query"
+                    }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/book.Book"
+                            "$ref": "#/definitions/acquisition.Valuation"
                         }
                     },
                     "400": {
@@ -58,9 +115,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/books/list": {
-            "post": {
-                "description": "Get a paginated list of all books",
+        "/acquisitions/{id}": {
+            "put": {
+                "description": "Replaces the price, currency, funding source, purchase date, branch, barcode and replacement cost of a copy; the book stays the same.",
                 "consumes": [
                     "application/json"
                 ],
@@ -68,17 +125,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "books"
+                    "acquisitions"
                 ],
-                "summary": "List all books",
+                "summary": "Update an acquisition record",
                 "parameters": [
                     {
-                        "description": "Pagination and filter request",
-                        "name": "requestBody",
+                        "type": "integer",
+                        "description": "Acquisition ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Acquisition fields",
+                        "name": "acquisition",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/book.PaginationRequest"
+                            "$ref": "#/definitions/acquisition.Acquisition"
                         }
                     }
                 ],
@@ -86,32 +150,90 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/book.PaginationResponse"
+                            "$ref": "#/definitions/acquisition.Acquisition"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/book.ErrorResponse"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/book.ErrorResponse"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "409": {
+                        "description": "Conflict",
                         "schema": {
-                            "$ref": "#/definitions/book.ErrorResponse"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "A copy that has been lent can't be deleted; withdraw it instead.",
+                "tags": [
+                    "acquisitions"
+                ],
+                "summary": "Delete an acquisition record",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Acquisition ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     }
                 }
             }
         },
-        "/books/{id}": {
-            "get": {
+        "/acquisitions/{id}/checkouts": {
+            "post": {
+                "description": "Adds one to the copy's checkout count and updates its last checkout date. Withdrawn copies and copies out for repair don't circulate.",
                 "consumes": [
                     "application/json"
                 ],
@@ -119,23 +241,40 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "books"
+                    "acquisitions"
                 ],
-                "summary": "Get book by ID",
+                "summary": "Count a checkout of a copy",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Book ID",
+                        "description": "Acquisition ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Checkout date",
+                        "name": "checkout",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/acquisition.CheckoutRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/book.Book"
+                            "$ref": "#/definitions/acquisition.Acquisition"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     },
                     "404": {
@@ -146,43 +285,92 @@ const docTemplate = `{
                                 "type": "string"
                             }
                         }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
                     }
                 }
-            },
-            "put": {
-                "consumes": [
-                    "application/json"
-                ],
+            }
+        },
+        "/acquisitions/{id}/history": {
+            "get": {
+                "description": "Returns everything recorded about a physical copy, oldest first: its acquisition, checkouts and returns, the holds it was trapped and shelved for, repairs, moves to another home branch, transits after check-in and its withdrawal. Useful for investigating damage disputes. Dates without a time of day, such as repair dates, are given as the start of the day.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "books"
+                    "circulation"
                 ],
-                "summary": "Update a book",
+                "summary": "Get the history of a copy",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Book ID",
+                        "description": "Copy (acquisition) ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/circulation.CopyHistory"
+                        }
                     },
-                    {
-                        "description": "Updated book",
-                        "name": "book",
-                        "in": "body",
-                        "required": true,
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/book.Book"
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     }
+                }
+            }
+        },
+        "/acquisitions/{id}/repairs": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "repairs"
+                ],
+                "summary": "List the repairs of a copy",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Acquisition ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/book.Book"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/repairs.Repair"
+                            }
                         }
                     },
                     "400": {
@@ -205,7 +393,8 @@ const docTemplate = `{
                     }
                 }
             },
-            "delete": {
+            "post": {
+                "description": "Records the copy as out with the vendor until it is returned; it can't be checked out meanwhile. kind defaults to repair and sent_on to today. cost, with its currency, is the vendor's quote.",
                 "consumes": [
                     "application/json"
                 ],
@@ -213,21 +402,42 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "books"
+                    "repairs"
                 ],
-                "summary": "Delete a book",
+                "summary": "Send a damaged copy out for repair or binding",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Book ID",
+                        "description": "Acquisition ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Vendor, damage, cost and expected return",
+                        "name": "repair",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/repairs.Repair"
+                        }
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/repairs.Repair"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
                     },
                     "404": {
                         "description": "Not Found",
@@ -237,128 +447,15901 @@ const docTemplate = `{
                                 "type": "string"
                             }
                         }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
                     }
                 }
             }
         },
-        "/health": {
+        "/acquisitions/{id}/rfid-tags": {
             "get": {
-                "description": "Returns server health status",
-                "consumes": [
-                    "*/*"
-                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Health"
+                    "rfid"
+                ],
+                "summary": "List the RFID tags of a copy",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Acquisition ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Health check",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/book.StatusResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/rfid.Tag"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Associates the tag UID with the copy. The UID may be grouped with colons, dashes or spaces. Assigning a tag the copy already carries changes nothing; a tag of another copy must be removed from it first.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rfid"
+                ],
+                "summary": "Associate an RFID tag with a copy",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Acquisition ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Tag to associate",
+                        "name": "tag",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/rfid.TagRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/rfid.Tag"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/api-keys/{id}/usage": {
+            "get": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Returns request counts, error rates and the busiest endpoints of the key between from and to (UTC days, inclusive). Counts are flushed periodically, so the last minute or so may be missing.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Usage of an API key",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "API key ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "First day (YYYY-MM-DD); defaults to 29 days before to",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last day (YYYY-MM-DD); defaults to today",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Number of top endpoints to return",
+                        "name": "top",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/usage.Report"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/carousel": {
+            "get": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Lists the features scheduled on the day given, or every feature, by start date and position. Unlike the public carousel, lists that aren't published are included.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List the carousel schedule",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Day, YYYY-MM-DD",
+                        "name": "on",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/picks.Feature"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Schedules a staff picks list or a title, exactly one of list_id and book_id, on the homepage carousel from starts_on through ends_on. A list only shows while it is published.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Schedule a feature on the carousel",
+                "parameters": [
+                    {
+                        "description": "Feature to schedule",
+                        "name": "feature",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/picks.FeatureRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/picks.Feature"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/carousel/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Get a carousel feature",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Feature ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/picks.Feature"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     }
                 }
+            },
+            "put": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Reschedule a carousel feature",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Feature ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Feature",
+                        "name": "feature",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/picks.FeatureRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/picks.Feature"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Remove a feature from the carousel schedule",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Feature ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/devices": {
+            "get": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Lists the registered devices with their status, last heartbeat and last error, and counts how many are online and silent. The counts cover the branch and kind asked for, whatever the status filter.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Get the status of every device",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Branch",
+                        "name": "branch",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "kiosk",
+                            "gate"
+                        ],
+                        "type": "string",
+                        "description": "Kind",
+                        "name": "kind",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "online",
+                            "silent"
+                        ],
+                        "type": "string",
+                        "description": "Status",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/devices.Dashboard"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/devices/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Get a device",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Device ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/devices.Device"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Removes a decommissioned device and its errors. A device that registers again is added back.",
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Remove a device from the registry",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Device ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/devices/{id}/errors": {
+            "get": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Returns the last 100 errors the device reported, most recent first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List a device's errors",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Device ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/devices.DeviceError"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/embeddings": {
+            "get": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Counts the books of the catalog and those whose embedding is up to date with their title, description and the configured model.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Get the state of the embedding index",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/embeddings.IndexStatus"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Embeddings are disabled",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/experiments": {
+            "get": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List experiments",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/experiments.Experiment"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/experiments/{key}": {
+            "get": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Get an experiment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Experiment key",
+                        "name": "key",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/experiments.Experiment"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Stores the experiment, which takes effect on this instance at once and on the others at their next reload. Units are bucketed by weight, by a hash of the unit and the key, so they stay in their variant while the variants don't change. Setting running to false stops the experiment and keeps its results.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Create or replace an experiment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Experiment key",
+                        "name": "key",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Experiment",
+                        "name": "experiment",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/experiments.ExperimentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Experiment replaced",
+                        "schema": {
+                            "$ref": "#/definitions/experiments.Experiment"
+                        }
+                    },
+                    "201": {
+                        "description": "Experiment created",
+                        "schema": {
+                            "$ref": "#/definitions/experiments.Experiment"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Removes the experiment with its results. Stop it instead to keep them.",
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Delete an experiment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Experiment key",
+                        "name": "key",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/experiments/{key}/results": {
+            "get": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Returns the units exposed to each variant and, per metric, how many of them converted after their exposure, with the conversion rate. A unit counts in the variant it was first exposed to. Counts are flushed periodically, so the last minute or so may be missing.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Compare the variants of an experiment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Experiment key",
+                        "name": "key",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/experiments.Results"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/ils-sync": {
+            "get": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Returns the configured Koha or Sierra sync, whether a run is in progress, where the next incremental run starts and the report of the last run.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Get the ILS sync status",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/ilssync.Status"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/ils-sync/run": {
+            "post": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Starts a sync in the background and returns at once; follow it with GET /admin/ils-sync. A run fetches the records changed since the last successful run, or every record with full=true, e.g. to pick up item changes that did not touch their bib record.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Start an ILS sync run",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Fetch every record",
+                        "name": "full",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/ilssync.Status"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/maintenance": {
+            "get": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Get maintenance mode",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/admin.MaintenanceState"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "While enabled, writes return 503 with the message and reads continue. The setting applies to this instance until it restarts.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Turn maintenance mode on or off",
+                "parameters": [
+                    {
+                        "description": "Maintenance mode",
+                        "name": "maintenance",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/admin.MaintenanceRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/admin.MaintenanceState"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/picks": {
+            "get": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Lists the staff picks lists, drafts included, without their books, most recently updated first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List every staff picks list",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/picks.List"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Creates a list without books. The slug, which names it in its public URL, defaults to one made from the title. A list is only public once published.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Create a staff picks list",
+                "parameters": [
+                    {
+                        "description": "List to create",
+                        "name": "list",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/picks.ListRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/picks.List"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/picks/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Returns the list, published or not, with all its books in order.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Get a staff picks list",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "List ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/picks.List"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Changes the list's slug, title, description and whether it is published, keeping its books.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Change a staff picks list",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "List ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "List details",
+                        "name": "list",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/picks.ListRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/picks.List"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Delete a staff picks list",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "List ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/picks/{id}/items": {
+            "put": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Replaces the books on the list with the ones given, in that order, each with an optional staff note. A book can be on a list once.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Set the books on a staff picks list",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "List ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Books in order",
+                        "name": "items",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/picks.ItemsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/picks.List"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/rate-limits": {
+            "get": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Returns the default limit and every per-key override with its usage today on this instance",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List rate limits",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/ratelimit.LimitsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/rate-limits/{key}": {
+            "get": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Get the rate limit of an API key",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "API key ID",
+                        "name": "key",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/ratelimit.KeyStatus"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Stores an override that takes effect on this instance at once and on the others at their next reload",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Set the rate limit of an API key",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "API key ID",
+                        "name": "key",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Rate limit; 0 means unlimited",
+                        "name": "limit",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ratelimit.Limit"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/ratelimit.KeyStatus"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "The key returns to the default limit",
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Remove the rate limit override of an API key",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "API key ID",
+                        "name": "key",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/zero-results": {
+            "get": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Lists the queries patrons searched without results between from and to (UTC days, inclusive), most searched first, for collection managers to see what is looked for but not held. Queries are lowercased with their whitespace collapsed, and counted like GET /stats/usage, but rare queries are listed too. catalog_matches shows how many books each query finds now. format=csv downloads the list as a file.",
+                "produces": [
+                    "application/json",
+                    "text/csv"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Searches that found nothing",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "First day (YYYY-MM-DD); defaults to 29 days before to",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last day (YYYY-MM-DD); defaults to today",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Leave out queries searched fewer times",
+                        "name": "min_searches",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 100,
+                        "description": "Queries to return, at most 1000",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "csv"
+                        ],
+                        "type": "string",
+                        "default": "json",
+                        "description": "Output format",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/analytics.ZeroResultLog"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/books": {
+            "get": {
+                "description": "Get a paginated list of all books, filtered by query parameters that mirror the POST /books/list body. List parameters take comma-separated values or repeat. A search that finds nothing returns suggestions: titles and authors close to the search, to offer as corrections.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "books"
+                ],
+                "summary": "List all books",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "1-based page",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size, capped by the server configuration",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Title, author, ISBN or full text",
+                        "name": "search",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Authors containing it, ignoring case",
+                        "name": "author",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Titles starting with it, ignoring case",
+                        "name": "title",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ISBN-10 or ISBN-13, matching either form",
+                        "name": "isbn",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "csv",
+                        "description": "Sort fields, applied in order: id, title, author, call_number or publication_year",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "csv",
+                        "description": "Sort order of each sort field, asc or desc; missing ones are asc",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "First publication year, inclusive",
+                        "name": "year_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Last publication year, inclusive",
+                        "name": "year_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "csv",
+                        "description": "Formats",
+                        "name": "formats",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "csv",
+                        "description": "Audiences",
+                        "name": "audiences",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "csv",
+                        "description": "Accessibility features, all required",
+                        "name": "accessibility_features",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/book.PaginationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/book.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/book.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/books/availability": {
+            "post": {
+                "description": "Returns, for each book asked for, how many copies are on the shelf, on loan and waited for by holds, when the first copy on loan is due back and the branches with a copy available, in one call for a whole reading list or wishlist. Books are answered in the order asked, once each; IDs of books that don't exist or are outside the caller's audience limit are listed in not_found. At most 200 books per call.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "circulation"
+                ],
+                "summary": "Check the availability of several books at once",
+                "parameters": [
+                    {
+                        "description": "Books to check",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/circulation.AvailabilityRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/circulation.AvailabilityReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/books/bulk": {
+            "post": {
+                "description": "Creates up to 1000 books from an array, in one transaction. Each book is validated and checked for duplicate identifiers, in the catalog and earlier in the array, on its own: those that pass are created and listed in created with their new id, the others in errors with the reason, both by their index in the array. Returns 201 when every book was created and 200 when some were not. Needs features.bulk_import.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "books"
+                ],
+                "summary": "Create many books at once",
+                "parameters": [
+                    {
+                        "description": "Books to create",
+                        "name": "books",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/book.Book"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Some books were not created",
+                        "schema": {
+                            "$ref": "#/definitions/book.BulkCreateResponse"
+                        }
+                    },
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/book.BulkCreateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Bulk import is disabled",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/books/bulk-delete": {
+            "post": {
+                "description": "Deletes the books whose ids are in the array, up to 1000, in one transaction, and returns the ids deleted, those of books that did not exist and, as conflict, those of books kept because a copy of theirs has loans. A delete hook vetoing any of the books deletes none of them.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "books"
+                ],
+                "summary": "Delete many books at once",
+                "parameters": [
+                    {
+                        "description": "IDs of the books to delete",
+                        "name": "ids",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "integer"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/book.BulkDeleteResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/books/create": {
+            "post": {
+                "description": "Add a book to the library",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "books"
+                ],
+                "summary": "Create a new book",
+                "parameters": [
+                    {
+                        "description": "Book to create",
+                        "name": "book",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/book.Book"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/book.Book"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/book.ConflictResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/books/export": {
+            "get": {
+                "description": "Streams every book as CSV (one column per book field, id first) in id order. An interrupted download can be resumed by passing the id of the last complete row as after_id; resumed downloads omit the header so they can be appended to the partial file.",
+                "produces": [
+                    "text/csv"
+                ],
+                "tags": [
+                    "books"
+                ],
+                "summary": "Export the catalog as CSV",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Resume after this book ID",
+                        "name": "after_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "CSV stream",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/books/identifiers/{type}/{value}": {
+            "get": {
+                "description": "Finds the book carrying the given ISBN-10, ISBN-13, ISSN or OCLC number. Hyphens and spaces in the value are ignored.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "books"
+                ],
+                "summary": "Look up a book by identifier",
+                "parameters": [
+                    {
+                        "enum": [
+                            "isbn10",
+                            "isbn13",
+                            "issn",
+                            "oclc"
+                        ],
+                        "type": "string",
+                        "description": "Identifier type",
+                        "name": "type",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Identifier value",
+                        "name": "value",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/book.Book"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/books/import": {
+            "post": {
+                "description": "Streams a CSV file into the catalog using COPY. The header must name title, author and isbn columns; other book fields such as call_number or publication_year are optional. The import is all-or-nothing.",
+                "consumes": [
+                    "text/csv"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "books"
+                ],
+                "summary": "Bulk import books from CSV",
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/book.ImportResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/book.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/book.ConflictResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/book.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/books/list": {
+            "post": {
+                "description": "Get a paginated list of all books. A search that finds nothing returns suggestions: titles and authors close to the search, to offer as corrections. Kept for existing clients; GET /books takes the same request as query parameters.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "books"
+                ],
+                "summary": "List all books",
+                "parameters": [
+                    {
+                        "description": "Pagination and filter request",
+                        "name": "requestBody",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/book.PaginationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/book.PaginationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/book.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/book.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/book.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/books/search/natural": {
+            "post": {
+                "description": "Finds the books a free-text query such as \"mystery novels set in Venice\" describes. With embeddings.enabled the books are ranked by how close their title and description are in meaning to the query, most similar first; when embeddings are disabled, the provider fails or nothing is embedded yet, the query runs as a keyword search of the catalog, and method says which one answered. Books outside the caller's audience limit are left out.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "books"
+                ],
+                "summary": "Search books by a natural-language description",
+                "parameters": [
+                    {
+                        "description": "Query",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/embeddings.NaturalSearchRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/embeddings.NaturalSearchResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/books/shelf-report": {
+            "get": {
+                "description": "Lists the books whose call numbers fall between from and to (inclusive, natural call number order), grouped by shelf location",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "books"
+                ],
+                "summary": "Items by shelf range",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "800",
+                        "description": "First call number in the range",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "899",
+                        "description": "Last call number in the range; call numbers starting with it are included",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only include this collection",
+                        "name": "collection",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/book.ShelfReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/books/{id}": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "books"
+                ],
+                "summary": "Get book by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/book.Book"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "books"
+                ],
+                "summary": "Update a book",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Updated book",
+                        "name": "book",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/book.Book"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/book.Book"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/book.ConflictResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Deletes the book with its copies. A book with copies that have been lent can't be deleted; withdraw its copies instead.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "books"
+                ],
+                "summary": "Delete a book",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Applies a JSON merge patch to the book: the fields in the body replace the book's, null clears them, and fields left out keep their values, so clients need not resend the whole book. At least one field other than id is required. An isbn sent without identifiers replaces the primary ISBN. The patch applies to the book as catalogued, whatever the Accept-Language.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "books"
+                ],
+                "summary": "Update some fields of a book",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Fields to change",
+                        "name": "patch",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/book.Book"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/book.Book"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/book.ConflictResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/books/{id}/acquisitions": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "acquisitions"
+                ],
+                "summary": "List the purchased copies of a book",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/acquisition.Acquisition"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Stores the price, currency, funding source, holding branch and item barcode of one purchased copy of the book. acquired_on defaults to today; replacement_cost, used by the insurance valuation, defaults to the price. A barcode already on another copy is refused with 409.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "acquisitions"
+                ],
+                "summary": "Record the purchase of a copy",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Price, currency and funding source",
+                        "name": "acquisition",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/acquisition.Acquisition"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/acquisition.Acquisition"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/books/{id}/assets": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "media"
+                ],
+                "summary": "List a book's audio and e-book assets",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/media.Asset"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Stores the raw request body as an asset of the book. The Content-Type must be an audio type, application/epub+zip, application/vnd.comicbook+zip or, for covers, image/jpeg, image/png, image/gif or image/webp.",
+                "consumes": [
+                    "audio/mpeg",
+                    "application/epub+zip",
+                    "application/vnd.comicbook+zip",
+                    "image/jpeg"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "media"
+                ],
+                "summary": "Upload an audiobook, e-book or cover image",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Original file name",
+                        "name": "filename",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/media.Asset"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/books/{id}/citation": {
+            "get": {
+                "description": "Returns the book as a BibTeX entry or RIS record, for import into Zotero, EndNote, Mendeley or a LaTeX bibliography.",
+                "produces": [
+                    "application/x-bibtex",
+                    "application/x-research-info-systems"
+                ],
+                "tags": [
+                    "books"
+                ],
+                "summary": "Export a book's citation",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "bibtex",
+                            "ris"
+                        ],
+                        "type": "string",
+                        "default": "bibtex",
+                        "description": "Citation format",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Citation",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/books/{id}/cite": {
+            "get": {
+                "description": "Returns the book's reference formatted in APA (7th edition), MLA (9th edition) or Chicago (17th edition, bibliography) style, as plain text and as HTML with the title in italics. The catalog has no publishers, so they are left out.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "books"
+                ],
+                "summary": "Cite a book",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "apa",
+                            "mla",
+                            "chicago"
+                        ],
+                        "type": "string",
+                        "default": "apa",
+                        "description": "Citation style",
+                        "name": "style",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/book.FormattedCitation"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/books/{id}/location": {
+            "get": {
+                "description": "Locates the book's call number in its collection on the shelf map. Books without a call number have no location.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "shelf-map"
+                ],
+                "summary": "Find where a book is shelved",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Branch",
+                        "name": "branch",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/shelfmap.Location"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/books/{id}/recommendations": {
+            "get": {
+                "description": "Returns books for readers of the book, best first, from the configured strategy or the caller's variant in the recommendations experiment: co_borrowing (what the book's borrowers also borrowed), content (same author, series or collection) or random. Places the strategy leaves empty are filled with random books. Books outside the caller's audience limit are left out.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "books"
+                ],
+                "summary": "Recommend books to the readers of a book",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Books to return, at most recommendations.max_limit",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/recommendations.Recommendations"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/books/{id}/similar": {
+            "get": {
+                "description": "Returns the books whose title and description are closest in meaning to the book's, by the cosine similarity of their embeddings, most similar first. Books outside the caller's audience limit are left out. Needs embeddings.enabled; a book added or edited is compared once the indexer has embedded it, within embeddings.interval.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "books"
+                ],
+                "summary": "Find books similar in meaning to a book",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Books to return, at most 50",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/embeddings.Matches"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Book not found, or embeddings are disabled",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Book not embedded yet",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/books/{id}/translations": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "books"
+                ],
+                "summary": "List a book's translations",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/book.Translation"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/books/{id}/translations/{language}": {
+            "put": {
+                "description": "Stores the book's title and description in a language, given as a BCP 47 tag such as \"fr\" or \"pt-BR\". Reads with an Accept-Language header return the best matching translation, or the book as catalogued when none matches better.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "books"
+                ],
+                "summary": "Add or replace a translation",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "BCP 47 language tag",
+                        "name": "language",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Translated title and description",
+                        "name": "translation",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/book.Translation"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/book.Translation"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "tags": [
+                    "books"
+                ],
+                "summary": "Delete a translation",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "BCP 47 language tag",
+                        "name": "language",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/carousel": {
+            "get": {
+                "description": "Returns the staff picks lists and titles scheduled on the carousel today, by position. Lists that aren't published and titles outside the caller's audience limit are left out.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "picks"
+                ],
+                "summary": "Get today's homepage carousel",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/picks.Carousel"
+                        }
+                    }
+                }
+            }
+        },
+        "/challenges/{year}/leaderboard": {
+            "get": {
+                "description": "Ranks the members with a challenge for the year by the books logged toward it; ties share a rank. Members who set hide_from_leaderboard are left out, and the others are shown by first name and last initial only.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "challenges"
+                ],
+                "summary": "Get a reading challenge leaderboard",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Year",
+                        "name": "year",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Standings to return, at most 100",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/challenges.Leaderboard"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/circulation/checkin/batch": {
+            "post": {
+                "description": "Checks in each barcode at the branch, in order: returns the copy's loan if it is out, fining it as POST /loans/{id}/return does, then routes the copy. A copy of a book with waiting holds is set aside for the oldest hold and goes on the hold shelf if the hold is picked up at the branch, or in transit to its pickup branch; a copy trapped before keeps its hold. Other copies are reshelved, or in transit to their home branch unless their collection floats under circulation.floating: then they are reshelved and the branch becomes their home branch. Holds whose copy reaches the hold shelf are published on the event bus as hold.ready. Barcodes that can't be checked in carry an error without failing the batch.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "circulation"
+                ],
+                "summary": "Check in the copies a returns sorter read",
+                "parameters": [
+                    {
+                        "description": "Branch and scanned barcodes, at most 500",
+                        "name": "checkin",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/circulation.BatchCheckinRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/circulation.BatchCheckin"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/closures": {
+            "get": {
+                "description": "Lists the closures overlapping a period, earliest first. With a branch, lists that branch's closures and those of every branch.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "calendar"
+                ],
+                "summary": "List library closures",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Branch",
+                        "name": "branch",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "First day, YYYY-MM-DD",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last day, YYYY-MM-DD",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/calendar.Closure"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Closes a branch, or with no branch every branch, from starts_on to ends_on (default starts_on). Loans don't fall due on closed days and aren't fined for them.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "calendar"
+                ],
+                "summary": "Add a closure to the calendar",
+                "parameters": [
+                    {
+                        "description": "Closure to add",
+                        "name": "closure",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/calendar.Closure"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/calendar.Closure"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/closures/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "calendar"
+                ],
+                "summary": "Get a closure",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Closure ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/calendar.Closure"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Loans already out keep their due dates.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "calendar"
+                ],
+                "summary": "Update a closure",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Closure ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Updated closure",
+                        "name": "closure",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/calendar.Closure"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/calendar.Closure"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "tags": [
+                    "calendar"
+                ],
+                "summary": "Remove a closure from the calendar",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Closure ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/consent-documents": {
+            "get": {
+                "description": "Lists every published version by kind, newest first, or with current=true only the current version of each kind.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "consent"
+                ],
+                "summary": "List the documents members accept",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Only the current versions",
+                        "name": "current",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/consent.Document"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Makes the document the current version of its kind. If it is required, members must accept it before they can use the API again, even if they accepted an earlier version.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "consent"
+                ],
+                "summary": "Publish a document version",
+                "parameters": [
+                    {
+                        "description": "Document version to publish",
+                        "name": "document",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/consent.Document"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/consent.Document"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/copies/{id}/history": {
+            "get": {
+                "description": "Returns everything recorded about a physical copy, oldest first: its acquisition, checkouts and returns, the holds it was trapped and shelved for, repairs, moves to another home branch, transits after check-in and its withdrawal. Useful for investigating damage disputes. Dates without a time of day, such as repair dates, are given as the start of the day.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "circulation"
+                ],
+                "summary": "Get the history of a copy",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Copy (acquisition) ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/circulation.CopyHistory"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/courses": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "courses"
+                ],
+                "summary": "List courses",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Only courses of this term",
+                        "name": "term",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/courses.Course"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "courses"
+                ],
+                "summary": "Create a course",
+                "parameters": [
+                    {
+                        "description": "Course to create",
+                        "name": "course",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/courses.Course"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/courses.Course"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/courses/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "courses"
+                ],
+                "summary": "Get a course with its reserves",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Course ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/courses.CourseResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Changing the dates changes when the course's reserves lend on reserve terms.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "courses"
+                ],
+                "summary": "Update a course",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Course ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Updated course",
+                        "name": "course",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/courses.Course"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/courses.Course"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Deletes the course and takes its copies off reserve",
+                "tags": [
+                    "courses"
+                ],
+                "summary": "Delete a course",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Course ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/courses/{id}/reserves": {
+            "get": {
+                "description": "The reserve list students see: each copy on reserve with its book, branch, loan terms and whether it is available.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "courses"
+                ],
+                "summary": "List the reserves of a course",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Course ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/courses.Reserve"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "While the course runs, the copy lends for loan_hours (default 3) and renews at most max_renewals times (default 0). A copy can be on reserve for one course at a time.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "courses"
+                ],
+                "summary": "Put a copy on reserve for a course",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Course ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Copy and loan terms",
+                        "name": "reserve",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/courses.Reserve"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/courses.Reserve"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/courses/{id}/reserves/{reserve_id}": {
+            "put": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "courses"
+                ],
+                "summary": "Change the loan terms of a reserve",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Course ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Reserve ID",
+                        "name": "reserve_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Loan terms and note",
+                        "name": "reserve",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/courses.Reserve"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/courses.Reserve"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "tags": [
+                    "courses"
+                ],
+                "summary": "Take a copy off reserve",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Course ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Reserve ID",
+                        "name": "reserve_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/desk/checkouts": {
+            "post": {
+                "description": "Checks out the copy with the item barcode to the member with the library card, on the same terms and checks as POST /loans, in one call. The card and the member's standing and loan limits are checked together with the copy's availability as part of the checkout. A denied checkout returns 409 with every reason it was denied for, so the desk can resolve them at once.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "circulation"
+                ],
+                "summary": "Check out a scanned copy to a scanned card",
+                "parameters": [
+                    {
+                        "description": "Scanned card number and item barcode",
+                        "name": "checkout",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/circulation.DeskCheckoutRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Checked out",
+                        "schema": {
+                            "$ref": "#/definitions/circulation.DeskCheckout"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Denied",
+                        "schema": {
+                            "$ref": "#/definitions/circulation.DeskCheckout"
+                        }
+                    }
+                }
+            }
+        },
+        "/devices": {
+            "post": {
+                "description": "Registers the device by its serial, or updates the registration of a device already registered with it, keeping its id. Registering counts as a heartbeat.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "devices"
+                ],
+                "summary": "Register a kiosk or gate reader",
+                "parameters": [
+                    {
+                        "description": "Device to register",
+                        "name": "device",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/devices.Registration"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Registration updated",
+                        "schema": {
+                            "$ref": "#/definitions/devices.Device"
+                        }
+                    },
+                    "201": {
+                        "description": "Device registered",
+                        "schema": {
+                            "$ref": "#/definitions/devices.Device"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/devices/{id}/errors": {
+            "post": {
+                "description": "Records an error of the device, such as a printer jam or a failed reader, for the admin dashboard. Reporting an error counts as a heartbeat.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "devices"
+                ],
+                "summary": "Report a device error",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Device ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Error to report",
+                        "name": "error",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/devices.ErrorReport"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/devices.DeviceError"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/devices/{id}/heartbeat": {
+            "post": {
+                "description": "Records that the device is alive. A device without a heartbeat for devices.silent_after shows as silent and is alerted as device.silent.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "devices"
+                ],
+                "summary": "Send a device heartbeat",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Device ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Heartbeat",
+                        "name": "heartbeat",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/devices.Heartbeat"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/drawers": {
+            "get": {
+                "description": "Lists drawer sessions with their totals, most recently opened first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payments"
+                ],
+                "summary": "List cash drawer sessions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Only sessions at this branch",
+                        "name": "branch",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only sessions of this staff member",
+                        "name": "staff",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only sessions not closed yet",
+                        "name": "open",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/payments.Session"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Opens a staff member's drawer at a branch with its opening float, which defaults to 0. Payments taken at the desk are recorded against the session until it is closed. A staff member has one drawer open at a branch at a time.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payments"
+                ],
+                "summary": "Open a cash drawer session",
+                "parameters": [
+                    {
+                        "description": "Branch, staff member and opening float",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/payments.OpenRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/payments.Session"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/drawers/{id}": {
+            "get": {
+                "description": "Returns the session with the cash and card payments taken, the cash the drawer should hold and, once it is closed, the cash counted and how far over or short it is, followed by its ledger of payments.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payments"
+                ],
+                "summary": "Get a cash drawer session's reconciliation report",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Drawer session ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/payments.Session"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/drawers/{id}/close": {
+            "post": {
+                "description": "Closes the session with the cash counted in the drawer, float included, and returns its reconciliation report. No more payments can be taken into it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payments"
+                ],
+                "summary": "Close a cash drawer session",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Drawer session ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Cash counted",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/payments.CloseRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/payments.Session"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/drawers/{id}/payments": {
+            "post": {
+                "description": "Records a cash or card payment from a member into an open drawer session, in the circulation currency, towards one of their loans (loan_id) or what they owe in general. A payment can't be more than the member, or the loan, has left to pay; fines of loans claimed returned aren't owed until the claim is resolved.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payments"
+                ],
+                "summary": "Take a payment at the desk",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Drawer session ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Member, method and amount",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/payments.PaymentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/payments.Payment"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/edi/messages": {
+            "post": {
+                "description": "Reads an EDIFACT interchange of QUOTES and INVOIC messages and applies each to the orders it references. Quotes set the quoted price of order lines; invoices add to the invoiced quantity and record every invoiced copy as an acquisition paid from the order's funding source. Lines are matched by their RFF+LI reference, then by ISBN. A message already applied to an order is reported and skipped, so an interchange can be posted again safely.",
+                "consumes": [
+                    "application/EDIFACT"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "purchasing"
+                ],
+                "summary": "Ingest vendor quotes and invoices",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/purchasing.MessageResult"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/experiments/assignments": {
+            "get": {
+                "description": "Returns the variant of the caller in every running experiment, for clients to adapt their own behavior, and counts them as exposures. The caller is the member in X-Member-ID or, without one, the API key in X-API-Key-ID; callers with neither are in no experiment. The variants are also listed in the X-Experiment-Variants response header.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "experiments"
+                ],
+                "summary": "Get the caller's experiment variants",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Member ID",
+                        "name": "X-Member-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/experiments.Assignment"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/experiments/{key}/conversions": {
+            "post": {
+                "description": "Counts that the caller did what the experiment tries to bring about on the metric, such as following a recommendation. Results only count conversions after the caller was exposed to the experiment. Checkouts and holds are recorded by the API as the checkout and hold metrics of every running experiment.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "experiments"
+                ],
+                "summary": "Record a conversion",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Experiment key",
+                        "name": "key",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Member ID",
+                        "name": "X-Member-ID",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Conversion",
+                        "name": "conversion",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/experiments.ConversionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/formats": {
+            "get": {
+                "description": "Returns the controlled format taxonomy with each format's loan period",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "books"
+                ],
+                "summary": "List media formats",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/book.Format"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/health": {
+            "get": {
+                "description": "Returns server health status with the result of each dependency check (database and configured integrations)",
+                "consumes": [
+                    "*/*"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Health"
+                ],
+                "summary": "Health check",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/book.StatusResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/hold-shelf": {
+            "get": {
+                "description": "Lists the copies on the branch's hold shelf by member name, with the date each hold must be picked up by. Holds past it are marked expired until POST /hold-shelf/clear-expired clears them.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "circulation"
+                ],
+                "summary": "List the copies awaiting pickup at a branch",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Pickup branch",
+                        "name": "branch",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only copies shelved since (RFC 3339)",
+                        "name": "since",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/circulation.ShelfItem"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/hold-shelf/clear-expired": {
+            "post": {
+                "description": "Expires the holds not picked up within circulation.hold_shelf_days of their copy reaching the hold shelf, publishing each on the event bus as hold.expired. Each copy goes back into circulation as if checked in at the branch: set aside for the next hold on its book, or reshelved or sent home.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "circulation"
+                ],
+                "summary": "Clear the expired holds from a branch's hold shelf",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Pickup branch",
+                        "name": "branch",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/circulation.ClearedShelf"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/hold-shelf/slips": {
+            "get": {
+                "description": "Returns a printable slip for each copy on the branch's hold shelf, by member name, separated by form feeds. Slips show the member's name, the last four digits of their card, the copy and the date to pick it up by. since limits them to the copies shelved since the last print run.",
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "circulation"
+                ],
+                "summary": "Print the hold-shelf slips of a branch",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Pickup branch",
+                        "name": "branch",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only copies shelved since (RFC 3339)",
+                        "name": "since",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/holds/{id}": {
+            "delete": {
+                "tags": [
+                    "circulation"
+                ],
+                "summary": "Cancel a waiting hold",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Hold ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/loan-policy": {
+            "get": {
+                "description": "Shows what a copy of the format lends on to a member of the group under the configured loan rules: loan period, loan limits, renewals and fines. Copies on course reserve lend on the reserve's terms instead.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "circulation"
+                ],
+                "summary": "Evaluate the loan terms for a patron group and format",
+                "parameters": [
+                    {
+                        "enum": [
+                            "child",
+                            "adult",
+                            "senior",
+                            "student",
+                            "staff"
+                        ],
+                        "type": "string",
+                        "description": "Patron group",
+                        "name": "patron_group",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Format code",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/policy.Terms"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/loans": {
+            "post": {
+                "description": "Lends the copy on the terms the loan policy sets for the member's patron group and the copy's format: loan period, renewals and loan limits. Copies on course reserve lend on the reserve's terms. Loans don't fall due on days the copy's branch is closed. Withdrawn copies, copies out for repair and copies already out can't be checked out, and suspended members can't check out.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "circulation"
+                ],
+                "summary": "Check a copy out to a member",
+                "parameters": [
+                    {
+                        "description": "Member and copy",
+                        "name": "checkout",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/circulation.CheckoutRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/circulation.Loan"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/loans/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "circulation"
+                ],
+                "summary": "Get a loan",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Loan ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/circulation.Loan"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/loans/{id}/claim-returned": {
+            "post": {
+                "description": "For a copy the member says they returned but the library still has out. The loan's fine stops at what returning the copy now would cost and isn't counted in the member's fines while the claim is open, the loan can't be renewed, and the copy's branch gets a shelf check at GET /shelf-checks. Resolve the claim with POST /loans/{id}/claim-returned/resolve; a shelf check finding the copy, or the copy being checked in, resolves it as found.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "circulation"
+                ],
+                "summary": "Record a member's claim to have returned a loan",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Loan ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/circulation.Loan"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/loans/{id}/claim-returned/resolve": {
+            "post": {
+                "description": "found returns the loan as of the claim, charging the fine it had then, and puts the copy back into circulation at its branch. lost withdraws the copy and adds a replacement charge to what the member owes: charge, or the copy's replacement cost (or price) when that is in the circulation currency.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "circulation"
+                ],
+                "summary": "Resolve a claim that a loan was returned",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Loan ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Outcome of the claim",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/circulation.ResolveClaimRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/circulation.Loan"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/loans/{id}/renew": {
+            "post": {
+                "description": "Sets the loan due a new loan period from now. Loans with no renewals left under the loan policy in force, of a book other members hold or of a suspended member can't be renewed.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "circulation"
+                ],
+                "summary": "Renew a loan",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Loan ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/circulation.Loan"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/loans/{id}/return": {
+            "post": {
+                "description": "Records the copy back. Copies returned late are fined at the rate of the member's patron group for each day or part of a day the branch was open, up to the loan policy's cap. Copies back within the grace period aren't fined.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "circulation"
+                ],
+                "summary": "Check a loan in",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Loan ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/circulation.Loan"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/me/feed": {
+            "get": {
+                "description": "Returns a single ranked feed for the app's home screen. Holds ready for pickup come first, soonest to expire first, then loans due within feed.due_soon_days or overdue, soonest due first. New arrivals in the member's favorite collections (those they borrow most from) follow, alternating with the books recommended to them as by /me/recommendations. New arrivals are books whose first copy was acquired in the last feed.new_arrival_days that the member hasn't borrowed; members with no loans on record get new arrivals from every collection. The member is identified by the X-Member-ID header set by the gateway.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "members"
+                ],
+                "summary": "Get the calling member's home feed",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Member ID",
+                        "name": "X-Member-ID",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Items to return, at most feed.max_limit",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/feed.Feed"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/me/recommendations": {
+            "get": {
+                "description": "Returns books the member hasn't borrowed, best first, starting from the books they borrowed most recently. Strategies and the random fill are those of /books/{id}/recommendations; members with no loans, or whose loans are anonymized, get random books. The member is identified by the X-Member-ID header set by the gateway.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "members"
+                ],
+                "summary": "Recommend books to the calling member",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Member ID",
+                        "name": "X-Member-ID",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Books to return, at most recommendations.max_limit",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/recommendations.Recommendations"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/media/assets/{id}/stream-token": {
+            "post": {
+                "description": "Returns a signed URL that streams the asset until it expires, for the calling member's loan of the asset's book. The loan must be out, not claimed returned and not past due. The token expires at the loan's due date at the latest, and stops working once the loan is returned or claimed returned. EPUB and CBZ assets also get an OPDS-PSE feed URL for page streaming.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "media"
+                ],
+                "summary": "Issue a streaming token",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Asset ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Member ID",
+                        "name": "X-Member-ID",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "The loan the token is for",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/media.StreamTokenRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/media.StreamTokenResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/media/stream/{token}": {
+            "get": {
+                "description": "Serves the asset behind a stream token. Supports Range requests so players can seek without downloading the whole file.",
+                "produces": [
+                    "audio/mpeg"
+                ],
+                "tags": [
+                    "media"
+                ],
+                "summary": "Stream an audio asset",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Stream token",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Byte range, e.g. bytes=0-1048575",
+                        "name": "Range",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "206": {
+                        "description": "Partial Content",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "416": {
+                        "description": "Requested Range Not Satisfiable",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/media/stream/{token}/opds": {
+            "get": {
+                "description": "Returns an OPDS acquisition feed whose entry links to the full file and, with the OPDS Page Streaming Extension, to each page image of an EPUB or CBZ. Works until the stream token expires.",
+                "produces": [
+                    "application/atom+xml"
+                ],
+                "tags": [
+                    "media"
+                ],
+                "summary": "OPDS-PSE feed of a lent e-book",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Stream token",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OPDS feed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/media/stream/{token}/pages/{page}": {
+            "get": {
+                "description": "Serves page {page} (zero-based) of an EPUB or CBZ as an image, read from the archive without sending the rest of it. With width, pages wider than that are scaled down. Works until the stream token expires.",
+                "produces": [
+                    "image/jpeg",
+                    "image/png"
+                ],
+                "tags": [
+                    "media"
+                ],
+                "summary": "Stream one page of a lent e-book",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Stream token",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number, from 0",
+                        "name": "page",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum width in pixels",
+                        "name": "width",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/members": {
+            "post": {
+                "description": "id is the member's ID in the identity system; patron_group defaults to adult.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "circulation"
+                ],
+                "summary": "Register a member",
+                "parameters": [
+                    {
+                        "description": "Member to register",
+                        "name": "member",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/circulation.Member"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/circulation.Member"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/members/guests": {
+            "post": {
+                "description": "Registers a visitor without a full registration in the guest patron group, under a generated id. The account expires after days, circulation.guest_days by default, and an expired guest can't check out, renew or place holds. The guest group's limits apply, by default two copies for use in the library only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "circulation"
+                ],
+                "summary": "Register a guest account",
+                "parameters": [
+                    {
+                        "description": "Guest to register",
+                        "name": "guest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/circulation.GuestRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/circulation.Member"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/members/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "circulation"
+                ],
+                "summary": "Get a member",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Member ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/circulation.Member"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Moving a member to another patron group applies its limits to new checkouts and holds.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "circulation"
+                ],
+                "summary": "Update a member",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Member ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Updated member",
+                        "name": "member",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/circulation.Member"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/circulation.Member"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/members/{id}/challenges": {
+            "get": {
+                "description": "Lists the member's challenges with how many books are logged toward each, most recent year first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "challenges"
+                ],
+                "summary": "List a member's reading challenges",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Member ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/challenges.Challenge"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/members/{id}/challenges/{year}": {
+            "get": {
+                "description": "Returns the challenge with the books logged toward it in the order they were finished and the badges they earned.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "challenges"
+                ],
+                "summary": "Get a member's progress in a reading challenge",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Member ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Year",
+                        "name": "year",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/challenges.Progress"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Joins the member to the year's challenge with the goal, or changes their goal. From then on, every book they return that year is logged toward it. hide_from_leaderboard keeps them off the leaderboard.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "challenges"
+                ],
+                "summary": "Set a member's reading goal for a year",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Member ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Year",
+                        "name": "year",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Goal",
+                        "name": "goal",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/challenges.GoalRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Goal changed",
+                        "schema": {
+                            "$ref": "#/definitions/challenges.Progress"
+                        }
+                    },
+                    "201": {
+                        "description": "Challenge joined",
+                        "schema": {
+                            "$ref": "#/definitions/challenges.Progress"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Removes the member's challenge for the year and the books logged toward it.",
+                "tags": [
+                    "challenges"
+                ],
+                "summary": "Leave a reading challenge",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Member ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Year",
+                        "name": "year",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/members/{id}/challenges/{year}/books": {
+            "post": {
+                "description": "Logs a catalog book the member finished, such as one read from their own shelves, toward their challenge. Books returned that year are logged by themselves. A book counts once per challenge.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "challenges"
+                ],
+                "summary": "Log a book read toward a challenge",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Member ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Year",
+                        "name": "year",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Book finished",
+                        "name": "book",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/challenges.LogRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/challenges.Progress"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/members/{id}/challenges/{year}/books/{book_id}": {
+            "delete": {
+                "tags": [
+                    "challenges"
+                ],
+                "summary": "Remove a book from a challenge",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Member ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Year",
+                        "name": "year",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "book_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/members/{id}/consents": {
+            "get": {
+                "description": "Lists the document versions the member has accepted and the current versions of required documents they still have to accept.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "consent"
+                ],
+                "summary": "Get a member's consents",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Member ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/consent.MemberConsents"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Only the current version of a document can be accepted. Accepting it again keeps the time it was first accepted.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "consent"
+                ],
+                "summary": "Record a member accepting a document",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Member ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Document accepted",
+                        "name": "acceptance",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/consent.AcceptRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/consent.Acceptance"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/members/{id}/family/{child_id}/holds": {
+            "get": {
+                "description": "Lists the child's holds to the guardian if the link has view_holds.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "family"
+                ],
+                "summary": "List a linked child's holds",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Guardian member ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Child member ID",
+                        "name": "child_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/circulation.Hold"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Places a hold for the child as POST /members/{id}/holds does, within the child's hold limit, if the link has manage.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "family"
+                ],
+                "summary": "Place a hold for a linked child",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Guardian member ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Child member ID",
+                        "name": "child_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Book to hold",
+                        "name": "hold",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/circulation.HoldRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/circulation.Hold"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/members/{id}/family/{child_id}/holds/{hold_id}": {
+            "delete": {
+                "description": "Cancels the child's hold if the link has manage.",
+                "tags": [
+                    "family"
+                ],
+                "summary": "Cancel a linked child's hold",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Guardian member ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Child member ID",
+                        "name": "child_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Hold ID",
+                        "name": "hold_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/members/{id}/family/{child_id}/loans": {
+            "get": {
+                "description": "Lists the child's loans to the guardian, as GET /members/{id}/loans does, if the link has view_loans. Returned loans are only listed with view_history.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "family"
+                ],
+                "summary": "List a linked child's loans",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Guardian member ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Child member ID",
+                        "name": "child_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "out",
+                            "returned"
+                        ],
+                        "type": "string",
+                        "description": "Only loans out or returned",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/circulation.Loan"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/members/{id}/family/{child_id}/loans/{loan_id}/renew": {
+            "post": {
+                "description": "Renews the child's loan as POST /loans/{id}/renew does, if the link has manage.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "family"
+                ],
+                "summary": "Renew a linked child's loan",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Guardian member ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Child member ID",
+                        "name": "child_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Loan ID",
+                        "name": "loan_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/circulation.Loan"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/members/{id}/holds": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "circulation"
+                ],
+                "summary": "List a member's holds",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Member ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/circulation.Hold"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Queues the member for the next copy of the book, within the hold limit of their patron group. The first copy of the book checked in is set aside for the oldest hold and sent to its pickup_branch. Checking a copy of the book out to the member fulfills the hold. Suspended members can't place holds.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "circulation"
+                ],
+                "summary": "Place a hold on a book",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Member ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Book to hold",
+                        "name": "hold",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/circulation.HoldRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/circulation.Hold"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/members/{id}/links": {
+            "get": {
+                "description": "Lists the links of the member to their children, then to their guardians, with what each lets the guardian see and do.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "family"
+                ],
+                "summary": "List a member's family links",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Member ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/circulation.Link"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Links a member of the child patron group to the guardian member. Each permission is off unless given: view_loans shows the child's loans out, view_history their returned loans too, view_holds their holds, and manage lets the guardian renew loans and place and cancel holds for the child.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "family"
+                ],
+                "summary": "Link a child to a guardian",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Guardian member ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Child and permissions",
+                        "name": "link",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/circulation.LinkRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/circulation.Link"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/members/{id}/links/{child_id}": {
+            "put": {
+                "description": "Replaces the permissions of the link, e.g. when the child withdraws consent for their guardian to see their loan history.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "family"
+                ],
+                "summary": "Change what a family link allows",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Guardian member ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Child member ID",
+                        "name": "child_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Permissions",
+                        "name": "permissions",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/circulation.LinkPermissions"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/circulation.Link"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "tags": [
+                    "family"
+                ],
+                "summary": "Unlink a child from a guardian",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Guardian member ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Child member ID",
+                        "name": "child_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/members/{id}/loans": {
+            "get": {
+                "description": "Loans still out come first, soonest due first, then returned loans, most recent first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "circulation"
+                ],
+                "summary": "List a member's loans",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Member ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "out",
+                            "returned"
+                        ],
+                        "type": "string",
+                        "description": "Only loans out or returned",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/circulation.Loan"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/members/{id}/loans/anonymize": {
+            "post": {
+                "description": "Unlinks the member's returned loans from them, keeping the loans for circulation counts. Loans with a fine or a replacement charge stay linked. Members with anonymize_loans set, or every member when the library's policy is to anonymize, have loans unlinked as they are returned.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "circulation"
+                ],
+                "summary": "Anonymize a member's loan history",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Member ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/circulation.Anonymized"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/members/{id}/payments": {
+            "get": {
+                "description": "Lists the payments a member made at the desk, most recent first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payments"
+                ],
+                "summary": "List a member's payments",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Member ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/payments.Payment"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/members/{id}/saved-searches": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "saved-searches"
+                ],
+                "summary": "List a member's saved searches",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Member ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/savedsearch.SavedSearch"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Saves the search and filters of a book list request. Books added to the catalog from now on that match it are published as a saved_search.matched alert for the member. The name defaults to the search.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "saved-searches"
+                ],
+                "summary": "Save a search for a member",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Member ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Search to save; name and query are read",
+                        "name": "search",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/savedsearch.SavedSearch"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/savedsearch.SavedSearch"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/members/{id}/saved-searches/{search_id}": {
+            "delete": {
+                "tags": [
+                    "saved-searches"
+                ],
+                "summary": "Delete a member's saved search",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Member ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Saved search ID",
+                        "name": "search_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/members/{id}/status": {
+            "get": {
+                "description": "Counts the member's loans out, overdue loans and waiting holds against the limits of their patron group, totals their fines and shows any suspension in force, so self-service kiosks and SIP2 gateways can tell whether the member may check out or place holds.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "circulation"
+                ],
+                "summary": "Get a member's circulation status",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Member ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/circulation.Status"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/members/{id}/suggestions": {
+            "get": {
+                "description": "Lists the member's suggestions, most recent first, with whether they were accepted or, with the reason, rejected.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "suggestions"
+                ],
+                "summary": "List a member's suggestions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Member ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/suggestions.Suggestion"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Records the member's suggestion of a title the library doesn't have, for staff to triage. A member can have 10 suggestions awaiting triage. A title the catalog already has, by ISBN or a similar title by the same author, is matched to it instead: the suggestion is recorded as matched with the book, the open purchase order it is on if any, and a hold placed for the member at pickup_branch.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "suggestions"
+                ],
+                "summary": "Suggest a title for the library to buy",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Member ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Title to suggest",
+                        "name": "suggestion",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/suggestions.SuggestionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/suggestions.Suggestion"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/members/{id}/suspension": {
+            "put": {
+                "description": "Blocks the member's checkouts, renewals and holds until the suspension expires or is lifted; returns are still accepted. Replaces any suspension in force. suspended_at is set by the server.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "circulation"
+                ],
+                "summary": "Suspend a member",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Member ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Reason and optional expiry",
+                        "name": "suspension",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/circulation.Suspension"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/circulation.Member"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "circulation"
+                ],
+                "summary": "Lift a member's suspension",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Member ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/circulation.Member"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/patron-groups": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "circulation"
+                ],
+                "summary": "List the patron groups and their lending policies",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/circulation.PatronGroup"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/picks": {
+            "get": {
+                "description": "Lists the published staff picks lists without their books, most recently updated first. item_count counts the books within the caller's audience limit.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "picks"
+                ],
+                "summary": "List the published staff picks",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/picks.List"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/picks/{slug}": {
+            "get": {
+                "description": "Returns the list with its books in order, leaving out those outside the caller's audience limit.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "picks"
+                ],
+                "summary": "Get a published staff picks list",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "List slug",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/picks.List"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/picks/{slug}/feed": {
+            "get": {
+                "description": "Returns the list as an Atom feed with an entry per book, in order, for embedding on other sites. Entries link to the book.",
+                "produces": [
+                    "application/atom+xml"
+                ],
+                "tags": [
+                    "picks"
+                ],
+                "summary": "Atom feed of a published staff picks list",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "List slug",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Atom feed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/purchase-orders": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "purchasing"
+                ],
+                "summary": "List purchase orders",
+                "parameters": [
+                    {
+                        "enum": [
+                            "open",
+                            "sent",
+                            "quoted",
+                            "partially_invoiced",
+                            "invoiced"
+                        ],
+                        "type": "string",
+                        "description": "Only orders with this status",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/purchasing.PurchaseOrder"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Orders quantities of catalog books from a vendor identified by its GLN or SAN. Each line gives book_id, quantity and optionally the expected unit price; the book's ISBN, title and author are copied onto the line.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "purchasing"
+                ],
+                "summary": "Create a purchase order",
+                "parameters": [
+                    {
+                        "description": "Vendor, currency, funding source and lines",
+                        "name": "order",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/purchasing.PurchaseOrder"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/purchasing.PurchaseOrder"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/purchase-orders/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "purchasing"
+                ],
+                "summary": "Get a purchase order",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Purchase order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/purchasing.PurchaseOrder"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/purchase-orders/{id}/edifact": {
+            "post": {
+                "description": "Renders the order as an EDIFACT ORDERS interchange (D96A, EDItEUR profile) from edi.sender_id to the vendor, for upload to the vendor's EDI service, and marks an open order sent. Each line carries an RFF+LI reference that vendors echo in their quotes and invoices.",
+                "produces": [
+                    "application/EDIFACT"
+                ],
+                "tags": [
+                    "purchasing"
+                ],
+                "summary": "Generate the EDIFACT ORDERS message of a purchase order",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Purchase order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "EDIFACT interchange",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/reading-list": {
+            "get": {
+                "description": "Returns the catalog titles on the calling member's shelves, most recently changed first. The member is identified by the X-Member-ID header set by the gateway.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reading-list"
+                ],
+                "summary": "Get the member's reading list",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Member ID",
+                        "name": "X-Member-ID",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "read",
+                            "currently-reading",
+                            "to-read",
+                            "did-not-finish"
+                        ],
+                        "type": "string",
+                        "description": "Only this shelf",
+                        "name": "shelf",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/readinglist.Entry"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/reading-list/import": {
+            "post": {
+                "description": "Reads the CSV library export of Goodreads (My Books \u003e Import and export) or StoryGraph (Manage Account \u003e Export) and puts each title found in the catalog on the member's shelf with its rating and read date. Rows are matched by ISBN, then by title and author surname; rows that match nothing are listed in the report. Titles already on the list are updated.",
+                "consumes": [
+                    "text/csv"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reading-list"
+                ],
+                "summary": "Import a Goodreads or StoryGraph export",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Member ID",
+                        "name": "X-Member-ID",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/readinglist.ImportReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/repairs": {
+            "get": {
+                "description": "Lists repairs, copies still out first by expected return date. overdue=true lists only copies out past their expected return date.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "repairs"
+                ],
+                "summary": "List the repair queue",
+                "parameters": [
+                    {
+                        "enum": [
+                            "out",
+                            "returned"
+                        ],
+                        "type": "string",
+                        "description": "Only repairs with this status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only copies overdue from the vendor",
+                        "name": "overdue",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/repairs.Repair"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/repairs/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "repairs"
+                ],
+                "summary": "Get a repair",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Repair ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/repairs.Repair"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/repairs/{id}/return": {
+            "post": {
+                "description": "Puts the copy back in circulation. returned_on defaults to today; cost, if given, replaces the quote with the invoiced amount.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "repairs"
+                ],
+                "summary": "Record a copy back from repair",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Repair ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Return date and final cost",
+                        "name": "return",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/repairs.Return"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/repairs.Repair"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/rfid-tags/encode": {
+            "post": {
+                "description": "Associates up to 1000 tags with their copies, as a processing station encodes them. Either every tag is associated or, if any belongs to another copy, none is.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rfid"
+                ],
+                "summary": "Encode a batch of RFID tags",
+                "parameters": [
+                    {
+                        "description": "Tags to associate",
+                        "name": "batch",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/rfid.EncodeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/rfid.Encoded"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/rfid-tags/resolve": {
+            "post": {
+                "description": "Resolves up to 500 tags a reader sees at once, such as a self-check pad or a security gate. Reads that are not a UID, and tags of no copy, are listed as unknown.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rfid"
+                ],
+                "summary": "Resolve many RFID tag reads at once",
+                "parameters": [
+                    {
+                        "description": "Tag UIDs read",
+                        "name": "reads",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/rfid.ResolveRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/rfid.Resolved"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/rfid-tags/{uid}": {
+            "get": {
+                "description": "Returns the copy carrying the tag, whether it is checked out, and whether a security gate should alarm for it.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rfid"
+                ],
+                "summary": "Resolve an RFID tag read to its copy",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "E004015012345678",
+                        "description": "Tag UID",
+                        "name": "uid",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/rfid.Item"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Unassigns the tag, e.g. when a damaged tag is replaced, so it can be encoded for another copy.",
+                "tags": [
+                    "rfid"
+                ],
+                "summary": "Remove an RFID tag from its copy",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "E004015012345678",
+                        "description": "Tag UID",
+                        "name": "uid",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/serials": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "serials"
+                ],
+                "summary": "List serials",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/serials.Serial"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "serials"
+                ],
+                "summary": "Create a serial",
+                "parameters": [
+                    {
+                        "description": "Serial to create",
+                        "name": "serial",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/serials.Serial"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/serials.Serial"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/serials/claims": {
+            "get": {
+                "description": "Lists issues not received claim_after_days after their expected date, and claimed issues whose last claim was at least claim_after_days ago, with the vendor to claim from. Most overdue first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "serials"
+                ],
+                "summary": "List the issues due for a claim",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/serials.Claim"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/serials/issues/{id}/check-in": {
+            "post": {
+                "description": "Marks the issue received, on received_on (default today) with copies (default the subscription's copies). Claimed issues can be checked in too.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "serials"
+                ],
+                "summary": "Check in an expected issue",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Issue ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Receipt",
+                        "name": "checkin",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/serials.CheckIn"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/serials.Issue"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/serials/issues/{id}/claim": {
+            "post": {
+                "description": "Marks an overdue issue claimed from its vendor and counts the claim. The issue is due for another claim after claim_after_days.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "serials"
+                ],
+                "summary": "Record a claim of a missing issue",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Issue ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/serials.Claim"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/serials/renewals": {
+            "get": {
+                "description": "Lists subscriptions ending within days (default the longest of serials.renewal_notice_days) that no later subscription of their serial follows, soonest first, with the latest reminder sent for each.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "serials"
+                ],
+                "summary": "List the subscriptions due for renewal",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Days ahead to look",
+                        "name": "days",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/serials.Renewal"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/serials/subscriptions/{id}/issues": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "serials"
+                ],
+                "summary": "List the issues of a subscription",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscription ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "expected",
+                            "received",
+                            "claimed"
+                        ],
+                        "type": "string",
+                        "description": "Only issues with this status",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/serials.Issue"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Records a special issue, supplement or other issue outside the predicted run as received.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "serials"
+                ],
+                "summary": "Check in an issue that was not predicted",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscription ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Volume, number and receipt",
+                        "name": "issue",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/serials.UnexpectedIssue"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/serials.Issue"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/serials/subscriptions/{id}/predict": {
+            "post": {
+                "description": "Adds the issues expected from the first issue through the given date, or the end of the subscription, dated by the serial's frequency and numbered by volume. Issues predicted before are kept, so predicting again only extends the list.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "serials"
+                ],
+                "summary": "Predict the expected issues of a subscription",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscription ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last expected date, YYYY-MM-DD",
+                        "name": "through",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/serials.PredictResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/serials/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "serials"
+                ],
+                "summary": "Get a serial with its subscriptions",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Serial ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/serials.SerialResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Changing the frequency affects issues predicted from now on, not those already predicted.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "serials"
+                ],
+                "summary": "Update a serial",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Serial ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Updated serial",
+                        "name": "serial",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/serials.Serial"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/serials.Serial"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Deletes the serial with its subscriptions and issues",
+                "tags": [
+                    "serials"
+                ],
+                "summary": "Delete a serial",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Serial ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/serials/{id}/costs": {
+            "get": {
+                "description": "Lists what each subscription period cost, oldest first, with the change from the period before when both are in the same currency.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "serials"
+                ],
+                "summary": "Get the cost history of a serial",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Serial ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/serials.CostHistory"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/serials/{id}/subscriptions": {
+            "post": {
+                "description": "Records a subscription for a period. The volume, number and expected date of its first issue, with the serial's frequency and issues_per_volume, are used to predict the issues that follow. copies defaults to 1 and claim_after_days to 30. cost, with its currency, is what the period cost. Recording the subscription for the next period stops the renewal reminders of the current one.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "serials"
+                ],
+                "summary": "Subscribe to a serial",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Serial ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Subscription",
+                        "name": "subscription",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/serials.Subscription"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/serials.Subscription"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/series": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "series"
+                ],
+                "summary": "List series",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/series.Series"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "series"
+                ],
+                "summary": "Create a series",
+                "parameters": [
+                    {
+                        "description": "Series to create",
+                        "name": "series",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/series.Series"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/series.Series"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/series/{id}": {
+            "get": {
+                "description": "Returns the series and its books in reading order",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "series"
+                ],
+                "summary": "Get a series with its volumes",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Series ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/series.SeriesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "series"
+                ],
+                "summary": "Update a series",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Series ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Updated series",
+                        "name": "series",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/series.Series"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/series.Series"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Deletes the series; its books remain in the catalog without a series",
+                "tags": [
+                    "series"
+                ],
+                "summary": "Delete a series",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Series ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/shelf-checks": {
+            "get": {
+                "description": "Lists the copies members claim to have returned that the branch should look for, in call number order, until each check is recorded or its claim resolved.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "circulation"
+                ],
+                "summary": "List the shelf checks a branch has to do",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Branch",
+                        "name": "branch",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/circulation.ShelfCheck"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/shelf-checks/{id}/result": {
+            "post": {
+                "description": "A copy found resolves the claim on its loan as found. One not found leaves the claim open for staff to resolve, usually as lost.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "circulation"
+                ],
+                "summary": "Record what a shelf check found",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Shelf check ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "What the check found",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/circulation.ShelfCheckResult"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/circulation.ShelfCheck"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/shelf-map/locate": {
+            "get": {
+                "description": "Returns the floor, aisle and side a call number is shelved on at each branch with a shelf range for it, or at the given branch only. A range of the given collection is preferred over one shelving every collection.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "shelf-map"
+                ],
+                "summary": "Find where a call number is shelved",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "813.52 FIT",
+                        "description": "Call number",
+                        "name": "call_number",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Branch",
+                        "name": "branch",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Collection of the item",
+                        "name": "collection",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/shelfmap.Location"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/shelf-ranges": {
+            "get": {
+                "description": "Lists the shelf ranges of a branch, or of every branch, in call number order.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "shelf-map"
+                ],
+                "summary": "List shelf ranges",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Branch",
+                        "name": "branch",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/shelfmap.Range"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Records where a branch shelves the call numbers from first_call_number to last_call_number, optionally of one collection only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "shelf-map"
+                ],
+                "summary": "Add a shelf range to the shelf map",
+                "parameters": [
+                    {
+                        "description": "Shelf range to add",
+                        "name": "range",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/shelfmap.Range"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/shelfmap.Range"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/shelf-ranges/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "shelf-map"
+                ],
+                "summary": "Get a shelf range",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Shelf range ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/shelfmap.Range"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "shelf-map"
+                ],
+                "summary": "Update a shelf range",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Shelf range ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Updated shelf range",
+                        "name": "range",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/shelfmap.Range"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/shelfmap.Range"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "tags": [
+                    "shelf-map"
+                ],
+                "summary": "Remove a shelf range from the shelf map",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Shelf range ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/stats/books": {
+            "get": {
+                "description": "Returns catalog totals and the most represented authors. Figures come from materialized views and may be up to one refresh interval old.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stats"
+                ],
+                "summary": "Catalog statistics",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Number of top authors to return",
+                        "name": "top",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/stats.BookStatsResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/stats/usage": {
+            "get": {
+                "description": "Returns how many searches were made and found nothing, the queries most often searched without results and the most viewed books between from and to (UTC days, inclusive), to guide collection development. Counts are kept per day with no member or client behind them, and leave out requests sent with DNT or Sec-GPC and members who set analytics_opt_out. Queries searched fewer than analytics.min_query_count times are not reported. Counts are flushed periodically, so the last minute or so may be missing.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stats"
+                ],
+                "summary": "Anonymous catalog usage",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "First day (YYYY-MM-DD); defaults to 29 days before to",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Last day (YYYY-MM-DD); defaults to today",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Number of top queries and books to return",
+                        "name": "top",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/analytics.Report"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/suggestions": {
+            "get": {
+                "description": "Lists the members' suggestions, oldest first, optionally only those with a status.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "suggestions"
+                ],
+                "summary": "List purchase suggestions for triage",
+                "parameters": [
+                    {
+                        "enum": [
+                            "pending",
+                            "accepted",
+                            "rejected",
+                            "matched"
+                        ],
+                        "type": "string",
+                        "description": "Status",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/suggestions.Suggestion"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/suggestions/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "suggestions"
+                ],
+                "summary": "Get a purchase suggestion",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Suggestion ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/suggestions.Suggestion"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/suggestions/{id}/accept": {
+            "post": {
+                "description": "Places a purchase order for the suggested title with the vendor and marks the suggestion accepted. The title is added to the catalog from the suggestion, with the ISBN given if the suggestion has none, unless book_id names the catalog record to order. The member is notified through suggestion.decided.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "suggestions"
+                ],
+                "summary": "Accept a suggestion by ordering the title",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Suggestion ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Order to place",
+                        "name": "order",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/suggestions.AcceptRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/suggestions.Suggestion"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/suggestions/{id}/reject": {
+            "post": {
+                "description": "Marks the suggestion rejected with the reason, which the member sees. The member is notified through suggestion.decided.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "suggestions"
+                ],
+                "summary": "Reject a suggestion",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Suggestion ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Reason",
+                        "name": "rejection",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/suggestions.RejectRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/suggestions.Suggestion"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/weeding/proposals": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "weeding"
+                ],
+                "summary": "List weeding proposals",
+                "parameters": [
+                    {
+                        "enum": [
+                            "proposed",
+                            "approved",
+                            "rejected"
+                        ],
+                        "type": "string",
+                        "description": "Only proposals with this status",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/weeding.Proposal"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Selects the copies in the collection meeting every given criterion: at most max_checkouts checkouts, no checkout in idle_days days (counting from acquisition for copies never checked out), acquired at least min_age_years ago, and optionally one branch and format. idle_days or min_age_years is required. All matches start marked for withdrawal; copies on another open proposal are left out.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "weeding"
+                ],
+                "summary": "Propose copies for withdrawal",
+                "parameters": [
+                    {
+                        "description": "Criteria and note",
+                        "name": "proposal",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/weeding.Proposal"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/weeding.Proposal"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/weeding/proposals/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "weeding"
+                ],
+                "summary": "Get a weeding proposal with its candidates",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Proposal ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/weeding.Proposal"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/weeding/proposals/{id}/approve": {
+            "post": {
+                "description": "Withdraws every candidate still marked for withdrawal and records how the copies are disposed of. Withdrawn copies no longer count in the insurance valuation or circulate.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "weeding"
+                ],
+                "summary": "Approve a weeding proposal",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Proposal ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Disposal method",
+                        "name": "decision",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/weeding.Decision"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/weeding.Proposal"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/weeding/proposals/{id}/candidates/{acquisition_id}": {
+            "put": {
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "weeding"
+                ],
+                "summary": "Decide whether a candidate copy is withdrawn or kept",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Proposal ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Acquisition ID of the copy",
+                        "name": "acquisition_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Decision and note",
+                        "name": "review",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/weeding.Review"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/weeding/proposals/{id}/disposal-report": {
+            "get": {
+                "description": "Lists the copies the proposal withdrew by branch, with their circulation and purchase details. format=csv or format=pdf downloads the report as a file.",
+                "produces": [
+                    "application/json",
+                    "text/csv",
+                    "application/pdf"
+                ],
+                "tags": [
+                    "weeding"
+                ],
+                "summary": "Get the disposal report of an approved proposal",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Proposal ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "csv",
+                            "pdf"
+                        ],
+                        "type": "string",
+                        "default": "json",
+                        "description": "Output format",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/weeding.DisposalReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/weeding/proposals/{id}/reject": {
+            "post": {
+                "description": "Closes the proposal without withdrawing any copy.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "weeding"
+                ],
+                "summary": "Reject a weeding proposal",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Proposal ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/weeding.Proposal"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "acquisition.Acquisition": {
+            "type": "object",
+            "properties": {
+                "acquired_on": {
+                    "description": "AcquiredOn is the purchase date; it defaults to today",
+                    "type": "string",
+                    "example": "2026-03-14"
+                },
+                "barcode": {
+                    "description": "Barcode is the item barcode on the copy, unique across copies",
+                    "type": "string",
+                    "example": "31234000123456"
+                },
+                "book_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "branch": {
+                    "description": "Branch is where the copy is held",
+                    "type": "string",
+                    "example": "Central"
+                },
+                "checkouts": {
+                    "description": "Checkouts counts the copy's loans; LastCheckoutOn is the latest",
+                    "type": "integer",
+                    "example": 12
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "currency": {
+                    "description": "ISO 4217",
+                    "type": "string",
+                    "example": "USD"
+                },
+                "funding_source": {
+                    "description": "FundingSource is the budget line or grant that paid for the copy",
+                    "type": "string",
+                    "example": "Friends of the Library"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "in_repair": {
+                    "description": "InRepair is set while the copy is out for repair or binding",
+                    "type": "boolean",
+                    "example": false
+                },
+                "last_checkout_on": {
+                    "type": "string",
+                    "example": "2026-09-30"
+                },
+                "price": {
+                    "description": "Price is a decimal amount in Currency, with up to four decimals",
+                    "type": "string",
+                    "example": "18.99"
+                },
+                "replacement_cost": {
+                    "description": "ReplacementCost is what replacing the copy would cost today, in\nCurrency; insurance valuations use Price when it is not set",
+                    "type": "string",
+                    "example": "24.99"
+                },
+                "withdrawn_at": {
+                    "description": "WithdrawnAt is set when the copy is weeded from the collection",
+                    "type": "string"
+                }
+            }
+        },
+        "acquisition.Amount": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "string",
+                    "example": "240.50"
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "EUR"
+                }
+            }
+        },
+        "acquisition.BranchTotal": {
+            "type": "object",
+            "properties": {
+                "amounts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/acquisition.Amount"
+                    }
+                },
+                "branch": {
+                    "type": "string",
+                    "example": "Central"
+                },
+                "converted": {
+                    "description": "Converted is empty when no amount could be converted",
+                    "type": "string",
+                    "example": "262.87"
+                },
+                "copies": {
+                    "type": "integer",
+                    "example": 14
+                }
+            }
+        },
+        "acquisition.CheckoutRequest": {
+            "type": "object",
+            "properties": {
+                "checked_out_on": {
+                    "description": "CheckedOutOn defaults to today",
+                    "type": "string",
+                    "example": "2026-09-30"
+                }
+            }
+        },
+        "acquisition.FundTotal": {
+            "type": "object",
+            "properties": {
+                "amounts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/acquisition.Amount"
+                    }
+                },
+                "converted": {
+                    "description": "Converted is empty when no amount could be converted",
+                    "type": "string",
+                    "example": "262.87"
+                },
+                "copies": {
+                    "type": "integer",
+                    "example": 14
+                },
+                "funding_source": {
+                    "type": "string",
+                    "example": "Friends of the Library"
+                }
+            }
+        },
+        "acquisition.Report": {
+            "type": "object",
+            "properties": {
+                "currency": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "from": {
+                    "type": "string",
+                    "example": "2026-01-01"
+                },
+                "funds": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/acquisition.FundTotal"
+                    }
+                },
+                "missing_rates": {
+                    "description": "MissingRates lists currencies with no exchange rate, whose amounts\nare left out of the converted totals",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "CHF"
+                    ]
+                },
+                "rates_as_of": {
+                    "description": "RatesAsOf is when the exchange rates used were published or loaded",
+                    "type": "string"
+                },
+                "to": {
+                    "type": "string",
+                    "example": "2026-12-31"
+                },
+                "total": {
+                    "$ref": "#/definitions/acquisition.Total"
+                }
+            }
+        },
+        "acquisition.Total": {
+            "type": "object",
+            "properties": {
+                "amounts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/acquisition.Amount"
+                    }
+                },
+                "converted": {
+                    "description": "Converted is empty when no amount could be converted",
+                    "type": "string",
+                    "example": "262.87"
+                },
+                "copies": {
+                    "type": "integer",
+                    "example": 14
+                }
+            }
+        },
+        "acquisition.Valuation": {
+            "type": "object",
+            "properties": {
+                "branches": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/acquisition.BranchTotal"
+                    }
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "generated_at": {
+                    "type": "string"
+                },
+                "groups": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/acquisition.ValuationGroup"
+                    }
+                },
+                "missing_rates": {
+                    "description": "MissingRates lists currencies with no exchange rate, whose amounts\nare left out of the converted totals",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "CHF"
+                    ]
+                },
+                "rates_as_of": {
+                    "description": "RatesAsOf is when the exchange rates used were published or loaded",
+                    "type": "string"
+                },
+                "total": {
+                    "$ref": "#/definitions/acquisition.Total"
+                }
+            }
+        },
+        "acquisition.ValuationGroup": {
+            "type": "object",
+            "properties": {
+                "amounts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/acquisition.Amount"
+                    }
+                },
+                "branch": {
+                    "type": "string",
+                    "example": "Central"
+                },
+                "converted": {
+                    "description": "Converted is empty when no amount could be converted",
+                    "type": "string",
+                    "example": "262.87"
+                },
+                "copies": {
+                    "type": "integer",
+                    "example": 14
+                },
+                "format": {
+                    "type": "string",
+                    "example": "hardcover"
+                }
+            }
+        },
+        "admin.MaintenanceRequest": {
+            "type": "object",
+            "properties": {
+                "enabled": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "message": {
+                    "description": "Message replaces the default text returned to rejected writes",
+                    "type": "string",
+                    "example": "Catalog migration in progress, back at 02:00 UTC"
+                },
+                "retry_after_seconds": {
+                    "type": "integer",
+                    "example": 600
+                }
+            }
+        },
+        "admin.MaintenanceState": {
+            "type": "object",
+            "properties": {
+                "enabled": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Catalog migration in progress, back at 02:00 UTC"
+                },
+                "retry_after_seconds": {
+                    "description": "RetryAfterSeconds is sent as Retry-After on rejected writes; 0 omits it",
+                    "type": "integer",
+                    "example": 600
+                },
+                "since": {
+                    "type": "string"
+                }
+            }
+        },
+        "analytics.BookViews": {
+            "type": "object",
+            "properties": {
+                "author": {
+                    "type": "string",
+                    "example": "Octavia E. Butler"
+                },
+                "book_id": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Kindred"
+                },
+                "views": {
+                    "type": "integer",
+                    "example": 96
+                }
+            }
+        },
+        "analytics.DailyUsage": {
+            "type": "object",
+            "properties": {
+                "day": {
+                    "type": "string",
+                    "example": "2024-05-01"
+                },
+                "searches": {
+                    "type": "integer",
+                    "example": 4210
+                },
+                "views": {
+                    "type": "integer",
+                    "example": 9875
+                },
+                "zero_result_rate": {
+                    "description": "ZeroResultRate is zero_result_searches / searches",
+                    "type": "number",
+                    "example": 0.0922
+                },
+                "zero_result_searches": {
+                    "description": "ZeroResultSearches are the searches that found no book",
+                    "type": "integer",
+                    "example": 388
+                }
+            }
+        },
+        "analytics.QueryCount": {
+            "type": "object",
+            "properties": {
+                "query": {
+                    "type": "string",
+                    "example": "octavia butler kindred"
+                },
+                "searches": {
+                    "type": "integer",
+                    "example": 17
+                }
+            }
+        },
+        "analytics.Report": {
+            "type": "object",
+            "properties": {
+                "daily": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/analytics.DailyUsage"
+                    }
+                },
+                "from": {
+                    "type": "string",
+                    "example": "2024-04-02"
+                },
+                "min_query_count": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "to": {
+                    "type": "string",
+                    "example": "2024-05-01"
+                },
+                "top_viewed_books": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/analytics.BookViews"
+                    }
+                },
+                "top_zero_result_queries": {
+                    "description": "TopZeroResultQueries leaves out the queries searched fewer than\nmin_query_count times, which could single out a patron",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/analytics.QueryCount"
+                    }
+                },
+                "totals": {
+                    "$ref": "#/definitions/analytics.Totals"
+                }
+            }
+        },
+        "analytics.Totals": {
+            "type": "object",
+            "properties": {
+                "searches": {
+                    "type": "integer",
+                    "example": 4210
+                },
+                "views": {
+                    "type": "integer",
+                    "example": 9875
+                },
+                "zero_result_rate": {
+                    "description": "ZeroResultRate is zero_result_searches / searches",
+                    "type": "number",
+                    "example": 0.0922
+                },
+                "zero_result_searches": {
+                    "description": "ZeroResultSearches are the searches that found no book",
+                    "type": "integer",
+                    "example": 388
+                }
+            }
+        },
+        "analytics.ZeroResultLog": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "type": "string",
+                    "example": "2024-04-02"
+                },
+                "min_searches": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "queries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/analytics.ZeroResultQuery"
+                    }
+                },
+                "to": {
+                    "type": "string",
+                    "example": "2024-05-01"
+                },
+                "total": {
+                    "description": "Total counts the queries searched at least min_searches times,\nincluding those past the limit",
+                    "type": "integer",
+                    "example": 212
+                }
+            }
+        },
+        "analytics.ZeroResultQuery": {
+            "type": "object",
+            "properties": {
+                "catalog_matches": {
+                    "description": "CatalogMatches is how many books the query finds now; queries with\nmatches were searched before the catalog had them",
+                    "type": "integer",
+                    "example": 0
+                },
+                "days": {
+                    "description": "Days counts the days it was searched on",
+                    "type": "integer",
+                    "example": 9
+                },
+                "first_searched_on": {
+                    "type": "string",
+                    "example": "2024-04-03"
+                },
+                "last_searched_on": {
+                    "type": "string",
+                    "example": "2024-04-29"
+                },
+                "query": {
+                    "type": "string",
+                    "example": "octavia butler kindred"
+                },
+                "searches": {
+                    "type": "integer",
+                    "example": 17
+                }
+            }
+        },
+        "book.Book": {
+            "type": "object",
+            "properties": {
+                "accessibility_features": {
+                    "description": "AccessibilityFeatures uses the schema.org accessibilityFeature terms",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "largePrint",
+                        "dyslexiaFriendly"
+                    ]
+                },
+                "audience": {
+                    "type": "string",
+                    "enum": [
+                        "children",
+                        "teen",
+                        "adult"
+                    ],
+                    "example": "adult"
+                },
+                "author": {
+                    "type": "string",
+                    "example": "F. Scott Fitzgerald"
+                },
+                "call_number": {
+                    "type": "string",
+                    "example": "813.52 FIT"
+                },
+                "collection": {
+                    "type": "string",
+                    "example": "Adult Fiction"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "A portrait of the Jazz Age on Long Island."
+                },
+                "edition": {
+                    "type": "string",
+                    "example": "1st"
+                },
+                "format": {
+                    "type": "string",
+                    "enum": [
+                        "hardcover",
+                        "paperback",
+                        "large_print",
+                        "audiobook_cd",
+                        "audiobook",
+                        "ebook",
+                        "dvd",
+                        "bluray",
+                        "magazine"
+                    ],
+                    "example": "hardcover"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "identifiers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/book.Identifier"
+                    }
+                },
+                "isbn": {
+                    "description": "ISBN is the primary ISBN (ISBN-13 preferred). On write it is added to\nIdentifiers, typed by its length.",
+                    "type": "string",
+                    "example": "9780743273565"
+                },
+                "language": {
+                    "description": "Language is the BCP 47 tag of Title and Description. On read it names\nthe translation chosen by Accept-Language, if any.",
+                    "type": "string",
+                    "example": "en"
+                },
+                "page_count": {
+                    "type": "integer",
+                    "example": 180
+                },
+                "publication_year": {
+                    "type": "integer",
+                    "example": 1925
+                },
+                "romanized": {
+                    "description": "Romanized is the title and author in Latin script, such as the pinyin\nof a Chinese title, for searches typed without the original script",
+                    "type": "string",
+                    "example": "Hong lou meng Cao Xueqin"
+                },
+                "series": {
+                    "$ref": "#/definitions/book.SeriesRef"
+                },
+                "shelf_location": {
+                    "type": "string",
+                    "example": "2F-A12"
+                },
+                "title": {
+                    "type": "string",
+                    "example": "The Great Gatsby"
+                }
+            }
+        },
+        "book.BulkCreateResponse": {
+            "type": "object",
+            "properties": {
+                "created": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/book.BulkCreated"
+                    }
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/book.BulkError"
+                    }
+                }
+            }
+        },
+        "book.BulkCreated": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer",
+                    "example": 1043
+                },
+                "index": {
+                    "type": "integer",
+                    "example": 0
+                }
+            }
+        },
+        "book.BulkDeleteResponse": {
+            "type": "object",
+            "properties": {
+                "conflict": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        7
+                    ]
+                },
+                "deleted": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        12,
+                        31
+                    ]
+                },
+                "not_found": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        99
+                    ]
+                }
+            }
+        },
+        "book.BulkError": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "validation failed: page_count must be between 1 and 100000"
+                },
+                "existing_id": {
+                    "description": "ExistingID is the book that already holds a duplicate identifier",
+                    "type": "integer",
+                    "example": 42
+                },
+                "index": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "book.ConflictResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "isbn13 9780743273565 already belongs to book 42"
+                },
+                "existing_id": {
+                    "type": "integer",
+                    "example": 42
+                }
+            }
+        },
+        "book.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                }
+            }
+        },
+        "book.Format": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "dvd"
+                },
+                "digital": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "loan_days": {
+                    "type": "integer",
+                    "example": 7
+                },
+                "name": {
+                    "type": "string",
+                    "example": "DVD"
+                }
+            }
+        },
+        "book.FormattedCitation": {
+            "type": "object",
+            "properties": {
+                "html": {
+                    "description": "HTML is the citation with the title in \u003ci\u003e, ready to show or copy",
+                    "type": "string",
+                    "example": "Fitzgerald, F. S. (1925). \u003ci\u003eThe Great Gatsby\u003c/i\u003e."
+                },
+                "style": {
+                    "type": "string",
+                    "example": "apa"
+                },
+                "text": {
+                    "description": "Text is the citation as plain text",
+                    "type": "string",
+                    "example": "Fitzgerald, F. S. (1925). The Great Gatsby."
+                }
+            }
+        },
+        "book.Identifier": {
+            "type": "object",
+            "properties": {
+                "type": {
+                    "description": "isbn10, isbn13, issn or oclc",
+                    "type": "string",
+                    "example": "isbn13"
+                },
+                "value": {
+                    "type": "string",
+                    "example": "9780743273565"
+                }
+            }
+        },
+        "book.ImportResponse": {
+            "type": "object",
+            "properties": {
+                "imported": {
+                    "type": "integer",
+                    "example": 500000
+                }
+            }
+        },
+        "book.PaginationRequest": {
+            "type": "object",
+            "properties": {
+                "accessibility_features": {
+                    "description": "AccessibilityFeatures matches books that have every listed feature",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "braille"
+                    ]
+                },
+                "audiences": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "children",
+                        "teen"
+                    ]
+                },
+                "author": {
+                    "description": "Author, Title and ISBN filter on one field each, unlike Search:\nauthors containing Author, titles starting with Title (both ignoring\ncase) and books carrying ISBN, in its ISBN-10 or ISBN-13 form",
+                    "type": "string",
+                    "example": "tolkien"
+                },
+                "formats": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "dvd",
+                        "bluray"
+                    ]
+                },
+                "isbn": {
+                    "type": "string",
+                    "example": "9780618640157"
+                },
+                "page": {
+                    "description": "1-based; defaults to 1",
+                    "type": "integer",
+                    "example": 1
+                },
+                "page_size": {
+                    "description": "defaults to and is capped by the server configuration",
+                    "type": "integer",
+                    "example": 10
+                },
+                "search": {
+                    "description": "title, author, ISBN or full text",
+                    "type": "string",
+                    "example": "gatsby jazz age"
+                },
+                "sort": {
+                    "description": "Sort is a single sort key; SortBy adds more, applied after it in order",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/book.Sort"
+                        }
+                    ]
+                },
+                "sort_by": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/book.Sort"
+                    }
+                },
+                "title": {
+                    "type": "string",
+                    "example": "the lord"
+                },
+                "year_from": {
+                    "description": "inclusive",
+                    "type": "integer",
+                    "example": 1900
+                },
+                "year_to": {
+                    "description": "inclusive",
+                    "type": "integer",
+                    "example": 1950
+                }
+            }
+        },
+        "book.PaginationResponse": {
+            "type": "object",
+            "properties": {
+                "data": {},
+                "page_count": {
+                    "type": "integer"
+                },
+                "suggestions": {
+                    "description": "Suggestions are titles and authors close to a search that found\nnothing, most similar first, to offer as corrections",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "The Great Gatsby",
+                        "F. Scott Fitzgerald"
+                    ]
+                },
+                "total_count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "book.SeriesRef": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "label": {
+                    "type": "string",
+                    "example": "The Lord of the Rings #2"
+                },
+                "position": {
+                    "type": "number",
+                    "example": 2
+                },
+                "title": {
+                    "type": "string",
+                    "example": "The Lord of the Rings"
+                }
+            }
+        },
+        "book.ShelfGroup": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/book.ShelfItem"
+                    }
+                },
+                "shelf_location": {
+                    "type": "string",
+                    "example": "2F-A12"
+                }
+            }
+        },
+        "book.ShelfItem": {
+            "type": "object",
+            "properties": {
+                "author": {
+                    "type": "string",
+                    "example": "F. Scott Fitzgerald"
+                },
+                "call_number": {
+                    "type": "string",
+                    "example": "813.52 FIT"
+                },
+                "collection": {
+                    "type": "string",
+                    "example": "Adult Fiction"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "title": {
+                    "type": "string",
+                    "example": "The Great Gatsby"
+                }
+            }
+        },
+        "book.ShelfReport": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "type": "string",
+                    "example": "800"
+                },
+                "shelves": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/book.ShelfGroup"
+                    }
+                },
+                "to": {
+                    "type": "string",
+                    "example": "899"
+                },
+                "total_items": {
+                    "type": "integer",
+                    "example": 312
+                }
+            }
+        },
+        "book.Sort": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "description": "\"id\", \"title\", \"author\", \"call_number\" or \"publication_year\"",
+                    "type": "string",
+                    "example": "call_number"
+                },
+                "order": {
+                    "description": "\"asc\" or \"desc\"",
+                    "type": "string",
+                    "example": "asc"
+                }
+            }
+        },
+        "book.StatusResponse": {
+            "type": "object",
+            "properties": {
+                "checks": {
+                    "description": "Checks lists each dependency check; failures make Status \"degraded\"",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/health.Result"
+                    }
+                },
+                "message": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "timestamp": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "string"
+                }
+            }
+        },
+        "book.Translation": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "example": "Un portrait de l'ère du jazz à Long Island."
+                },
+                "language": {
+                    "description": "BCP 47 tag, from the path on write",
+                    "type": "string",
+                    "example": "fr"
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Gatsby le Magnifique"
+                }
+            }
+        },
+        "calendar.Closure": {
+            "type": "object",
+            "properties": {
+                "branch": {
+                    "description": "Branch is empty for closures of every branch",
+                    "type": "string",
+                    "example": "Central"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "ends_on": {
+                    "type": "string",
+                    "example": "2026-12-26"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "Christmas"
+                },
+                "starts_on": {
+                    "type": "string",
+                    "example": "2026-12-24"
+                }
+            }
+        },
+        "challenges.Badge": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "ten_books"
+                },
+                "earned_on": {
+                    "description": "EarnedOn is the day the book that earned it was finished",
+                    "type": "string",
+                    "example": "2026-06-02"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Ten books"
+                }
+            }
+        },
+        "challenges.Book": {
+            "type": "object",
+            "properties": {
+                "author": {
+                    "type": "string",
+                    "example": "F. Scott Fitzgerald"
+                },
+                "book_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "completed_on": {
+                    "description": "CompletedOn is the day the book was finished, YYYY-MM-DD",
+                    "type": "string",
+                    "example": "2026-03-17"
+                },
+                "source": {
+                    "type": "string",
+                    "enum": [
+                        "return",
+                        "manual"
+                    ],
+                    "example": "return"
+                },
+                "title": {
+                    "type": "string",
+                    "example": "The Great Gatsby"
+                }
+            }
+        },
+        "challenges.Challenge": {
+            "type": "object",
+            "properties": {
+                "completed": {
+                    "description": "Completed counts the books logged toward the goal",
+                    "type": "integer",
+                    "example": 9
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "goal": {
+                    "type": "integer",
+                    "example": 24
+                },
+                "hide_from_leaderboard": {
+                    "description": "HideFromLeaderboard opts the member out of the year's leaderboard",
+                    "type": "boolean",
+                    "example": false
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 4
+                },
+                "member_id": {
+                    "type": "string",
+                    "example": "m-1001"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "year": {
+                    "type": "integer",
+                    "example": 2026
+                }
+            }
+        },
+        "challenges.GoalRequest": {
+            "type": "object",
+            "properties": {
+                "goal": {
+                    "type": "integer",
+                    "example": 24
+                },
+                "hide_from_leaderboard": {
+                    "type": "boolean",
+                    "example": false
+                }
+            }
+        },
+        "challenges.Leaderboard": {
+            "type": "object",
+            "properties": {
+                "standings": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/challenges.Standing"
+                    }
+                },
+                "year": {
+                    "type": "integer",
+                    "example": 2026
+                }
+            }
+        },
+        "challenges.LogRequest": {
+            "type": "object",
+            "properties": {
+                "book_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "completed_on": {
+                    "description": "CompletedOn defaults to today and must fall in the challenge's year",
+                    "type": "string",
+                    "example": "2026-03-17"
+                }
+            }
+        },
+        "challenges.Progress": {
+            "type": "object",
+            "properties": {
+                "badges": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/challenges.Badge"
+                    }
+                },
+                "books": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/challenges.Book"
+                    }
+                },
+                "challenge": {
+                    "$ref": "#/definitions/challenges.Challenge"
+                },
+                "percent": {
+                    "description": "Percent of the goal reached, capped at 100",
+                    "type": "integer",
+                    "example": 37
+                },
+                "remaining": {
+                    "type": "integer",
+                    "example": 15
+                }
+            }
+        },
+        "challenges.Standing": {
+            "type": "object",
+            "properties": {
+                "completed": {
+                    "type": "integer",
+                    "example": 31
+                },
+                "goal": {
+                    "type": "integer",
+                    "example": 24
+                },
+                "name": {
+                    "description": "Name shows the member's first name and last initial only",
+                    "type": "string",
+                    "example": "Ada L."
+                },
+                "percent": {
+                    "type": "integer",
+                    "example": 100
+                },
+                "rank": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "circulation.Anonymized": {
+            "type": "object",
+            "properties": {
+                "loans": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "member_id": {
+                    "type": "string",
+                    "example": "m-1001"
+                }
+            }
+        },
+        "circulation.Availability": {
+            "type": "object",
+            "properties": {
+                "available": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "book_id": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "branches": {
+                    "description": "Branches are where copies are available, by name",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "Central"
+                    ]
+                },
+                "copies": {
+                    "description": "Copies counts the copies not withdrawn, and Available those on the\nshelf: not on loan, in repair or set aside for a hold",
+                    "type": "integer",
+                    "example": 3
+                },
+                "holds_waiting": {
+                    "description": "HoldsWaiting counts the holds still waiting for a copy",
+                    "type": "integer",
+                    "example": 0
+                },
+                "next_due_at": {
+                    "description": "NextDueAt is when the first copy on loan is due back",
+                    "type": "string"
+                },
+                "on_loan": {
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "circulation.AvailabilityReport": {
+            "type": "object",
+            "properties": {
+                "books": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/circulation.Availability"
+                    }
+                },
+                "not_found": {
+                    "description": "NotFound lists the IDs of books that don't exist or are outside the\ncaller's audience limit",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        99
+                    ]
+                }
+            }
+        },
+        "circulation.AvailabilityRequest": {
+            "type": "object",
+            "properties": {
+                "book_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        1,
+                        12,
+                        31
+                    ]
+                }
+            }
+        },
+        "circulation.BatchCheckin": {
+            "type": "object",
+            "properties": {
+                "branch": {
+                    "type": "string",
+                    "example": "Central"
+                },
+                "failed": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/circulation.CheckinItem"
+                    }
+                },
+                "returned": {
+                    "description": "Returned counts the loans returned; Failed the copies not checked in",
+                    "type": "integer",
+                    "example": 38
+                }
+            }
+        },
+        "circulation.BatchCheckinRequest": {
+            "type": "object",
+            "properties": {
+                "barcodes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "31234000123456",
+                        "31234000654321"
+                    ]
+                },
+                "branch": {
+                    "type": "string",
+                    "example": "Central"
+                }
+            }
+        },
+        "circulation.CheckinItem": {
+            "type": "object",
+            "properties": {
+                "acquisition_id": {
+                    "type": "integer",
+                    "example": 31
+                },
+                "barcode": {
+                    "type": "string",
+                    "example": "31234000123456"
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "destination": {
+                    "description": "Destination is the branch a copy in transit goes to",
+                    "type": "string",
+                    "example": "Eastside"
+                },
+                "disposition": {
+                    "type": "string",
+                    "enum": [
+                        "reshelve",
+                        "hold_shelf",
+                        "transit"
+                    ],
+                    "example": "transit"
+                },
+                "error": {
+                    "description": "Error is why the copy could not be checked in, such as an unknown\nbarcode; the rest of the batch is checked in regardless",
+                    "type": "string",
+                    "example": ""
+                },
+                "fine": {
+                    "description": "Fine is charged on the returned loan, in Currency",
+                    "type": "string",
+                    "example": "0.75"
+                },
+                "floated": {
+                    "description": "Floated is set when the copy, of a floating collection, stays at the\nbranch and it became its home branch",
+                    "type": "boolean",
+                    "example": false
+                },
+                "hold_id": {
+                    "description": "HoldID is the hold the copy was trapped for",
+                    "type": "integer",
+                    "example": 7
+                },
+                "loan_id": {
+                    "description": "LoanID is the loan the check-in returned, if the copy was out",
+                    "type": "integer",
+                    "example": 12
+                },
+                "title": {
+                    "type": "string",
+                    "example": "The Left Hand of Darkness"
+                }
+            }
+        },
+        "circulation.CheckoutRequest": {
+            "type": "object",
+            "properties": {
+                "acquisition_id": {
+                    "type": "integer",
+                    "example": 31
+                },
+                "member_id": {
+                    "type": "string",
+                    "example": "m-1001"
+                }
+            }
+        },
+        "circulation.ClearedHold": {
+            "type": "object",
+            "properties": {
+                "acquisition_id": {
+                    "type": "integer",
+                    "example": 31
+                },
+                "barcode": {
+                    "type": "string",
+                    "example": "31234000123456"
+                },
+                "destination": {
+                    "type": "string",
+                    "example": "Eastside"
+                },
+                "disposition": {
+                    "type": "string",
+                    "enum": [
+                        "reshelve",
+                        "hold_shelf",
+                        "transit"
+                    ],
+                    "example": "hold_shelf"
+                },
+                "floated": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "hold_id": {
+                    "type": "integer",
+                    "example": 7
+                },
+                "member_id": {
+                    "type": "string",
+                    "example": "m-1001"
+                },
+                "next_hold_id": {
+                    "description": "NextHoldID is the hold the copy was set aside for next",
+                    "type": "integer",
+                    "example": 9
+                },
+                "title": {
+                    "type": "string",
+                    "example": "The Dispossessed"
+                }
+            }
+        },
+        "circulation.ClearedShelf": {
+            "type": "object",
+            "properties": {
+                "branch": {
+                    "type": "string",
+                    "example": "Central"
+                },
+                "holds": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/circulation.ClearedHold"
+                    }
+                }
+            }
+        },
+        "circulation.CopyEvent": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "description": "Amount is the fine of a return or the cost of a repair, in Currency",
+                    "type": "string"
+                },
+                "at": {
+                    "type": "string"
+                },
+                "branch": {
+                    "type": "string",
+                    "example": "Central"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "damage": {
+                    "type": "string",
+                    "example": "water damage to the last pages"
+                },
+                "due_at": {
+                    "description": "DueAt and Renewals are those of a checkout's loan",
+                    "type": "string"
+                },
+                "from_branch": {
+                    "description": "FromBranch and Branch are where a copy moved or was sent from and to,\nBranch the pickup branch of a hold or where the copy was acquired",
+                    "type": "string"
+                },
+                "hold_id": {
+                    "type": "integer"
+                },
+                "loan_id": {
+                    "description": "LoanID, HoldID and RepairID are the loan, hold or repair the event\nbelongs to",
+                    "type": "integer",
+                    "example": 7
+                },
+                "member_id": {
+                    "description": "MemberID is the borrower or the member the copy was trapped for;\nanonymized loans have none",
+                    "type": "string",
+                    "example": "m-1001"
+                },
+                "renewals": {
+                    "type": "integer"
+                },
+                "repair_id": {
+                    "type": "integer"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "checked_out"
+                },
+                "vendor": {
+                    "description": "Vendor and Damage are those of a repair",
+                    "type": "string"
+                }
+            }
+        },
+        "circulation.CopyHistory": {
+            "type": "object",
+            "properties": {
+                "acquisition_id": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "barcode": {
+                    "type": "string",
+                    "example": "31234000123456"
+                },
+                "book_id": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "branch": {
+                    "type": "string",
+                    "example": "Central"
+                },
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/circulation.CopyEvent"
+                    }
+                },
+                "title": {
+                    "type": "string",
+                    "example": "The Left Hand of Darkness"
+                },
+                "withdrawn_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "circulation.Denial": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "enum": [
+                        "card_not_found",
+                        "member_suspended",
+                        "member_expired",
+                        "loan_limit",
+                        "format_limit",
+                        "item_not_found",
+                        "item_withdrawn",
+                        "item_in_repair",
+                        "item_on_loan",
+                        "item_on_hold"
+                    ],
+                    "example": "loan_limit"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "member has reached the loan limit"
+                }
+            }
+        },
+        "circulation.DeskCheckout": {
+            "type": "object",
+            "properties": {
+                "denials": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/circulation.Denial"
+                    }
+                },
+                "loan": {
+                    "$ref": "#/definitions/circulation.Loan"
+                },
+                "member": {
+                    "description": "Member is the card's member, left out for unknown cards",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/circulation.Member"
+                        }
+                    ]
+                }
+            }
+        },
+        "circulation.DeskCheckoutRequest": {
+            "type": "object",
+            "properties": {
+                "barcode": {
+                    "type": "string",
+                    "example": "31234000123456"
+                },
+                "card_number": {
+                    "type": "string",
+                    "example": "21234567890128"
+                }
+            }
+        },
+        "circulation.GuestRequest": {
+            "type": "object",
+            "properties": {
+                "card_number": {
+                    "type": "string",
+                    "example": "29990000000017"
+                },
+                "days": {
+                    "description": "Days is how long the account lasts, circulation.guest_days by default\nand at most circulation.max_guest_days",
+                    "type": "integer",
+                    "example": 3
+                },
+                "email": {
+                    "type": "string",
+                    "example": "grace@example.org"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Grace Hopper"
+                }
+            }
+        },
+        "circulation.Hold": {
+            "type": "object",
+            "properties": {
+                "acquisition_id": {
+                    "description": "AcquisitionID is the copy set aside for the hold at check-in",
+                    "type": "integer",
+                    "example": 31
+                },
+                "book_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "closed_at": {
+                    "description": "ClosedAt is when the hold was fulfilled by a checkout, cancelled or\nexpired on the hold shelf",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "member_id": {
+                    "type": "string",
+                    "example": "m-1001"
+                },
+                "pickup_branch": {
+                    "description": "PickupBranch is where the member picks the copy up; without one it is\nthe branch the copy is checked in at",
+                    "type": "string",
+                    "example": "Central"
+                },
+                "placed_at": {
+                    "type": "string"
+                },
+                "shelved_at": {
+                    "description": "ShelvedAt is when the copy reached the pickup branch's hold shelf",
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "waiting",
+                        "fulfilled",
+                        "cancelled",
+                        "expired"
+                    ],
+                    "example": "waiting"
+                },
+                "title": {
+                    "type": "string",
+                    "example": "The Dispossessed"
+                }
+            }
+        },
+        "circulation.HoldRequest": {
+            "type": "object",
+            "properties": {
+                "book_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "pickup_branch": {
+                    "description": "PickupBranch defaults to the branch the copy is checked in at",
+                    "type": "string",
+                    "example": "Central"
+                }
+            }
+        },
+        "circulation.Link": {
+            "type": "object",
+            "properties": {
+                "child_id": {
+                    "type": "string",
+                    "example": "m-1002"
+                },
+                "child_name": {
+                    "type": "string",
+                    "example": "Byron Lovelace"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "guardian_id": {
+                    "type": "string",
+                    "example": "m-1001"
+                },
+                "guardian_name": {
+                    "type": "string",
+                    "example": "Ada Lovelace"
+                },
+                "manage": {
+                    "description": "Manage lets the guardian renew the child's loans and place and cancel\ntheir holds",
+                    "type": "boolean",
+                    "example": false
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "view_history": {
+                    "description": "ViewHistory also shows the child's returned loans; it needs\nViewLoans",
+                    "type": "boolean",
+                    "example": false
+                },
+                "view_holds": {
+                    "description": "ViewHolds shows the child's holds to the guardian",
+                    "type": "boolean",
+                    "example": true
+                },
+                "view_loans": {
+                    "description": "ViewLoans shows the child's loans out to the guardian",
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "circulation.LinkPermissions": {
+            "type": "object",
+            "properties": {
+                "manage": {
+                    "description": "Manage lets the guardian renew the child's loans and place and cancel\ntheir holds",
+                    "type": "boolean",
+                    "example": false
+                },
+                "view_history": {
+                    "description": "ViewHistory also shows the child's returned loans; it needs\nViewLoans",
+                    "type": "boolean",
+                    "example": false
+                },
+                "view_holds": {
+                    "description": "ViewHolds shows the child's holds to the guardian",
+                    "type": "boolean",
+                    "example": true
+                },
+                "view_loans": {
+                    "description": "ViewLoans shows the child's loans out to the guardian",
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "circulation.LinkRequest": {
+            "type": "object",
+            "properties": {
+                "child_id": {
+                    "type": "string",
+                    "example": "m-1002"
+                },
+                "manage": {
+                    "description": "Manage lets the guardian renew the child's loans and place and cancel\ntheir holds",
+                    "type": "boolean",
+                    "example": false
+                },
+                "view_history": {
+                    "description": "ViewHistory also shows the child's returned loans; it needs\nViewLoans",
+                    "type": "boolean",
+                    "example": false
+                },
+                "view_holds": {
+                    "description": "ViewHolds shows the child's holds to the guardian",
+                    "type": "boolean",
+                    "example": true
+                },
+                "view_loans": {
+                    "description": "ViewLoans shows the child's loans out to the guardian",
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "circulation.Loan": {
+            "type": "object",
+            "properties": {
+                "acquisition_id": {
+                    "type": "integer",
+                    "example": 31
+                },
+                "book_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "checked_out_at": {
+                    "type": "string"
+                },
+                "claim_outcome": {
+                    "type": "string",
+                    "enum": [
+                        "found",
+                        "lost"
+                    ],
+                    "example": "lost"
+                },
+                "claimed_returned_at": {
+                    "description": "ClaimedReturnedAt is set when the member claims to have returned the\ncopy; the fine stops accruing until the claim is resolved, as\nClaimOutcome found or lost",
+                    "type": "string"
+                },
+                "course_reserve_id": {
+                    "description": "CourseReserveID is set for loans of copies on course reserve, which\nlend on the reserve's terms",
+                    "type": "integer",
+                    "example": 4
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "due_at": {
+                    "type": "string"
+                },
+                "fine": {
+                    "description": "Fine is what the member was charged for returning the copy late, in\nCurrency; on loans out past due, what has accrued so far",
+                    "type": "string",
+                    "example": "0.75"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "max_renewals": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "member_id": {
+                    "description": "MemberID is empty once the loan has been anonymized",
+                    "type": "string",
+                    "example": "m-1001"
+                },
+                "overdue": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "renewals": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "replacement_charge": {
+                    "description": "ReplacementCharge is what the member was charged for a copy lost, in\nCurrency",
+                    "type": "string",
+                    "example": "24.99"
+                },
+                "returned_at": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string",
+                    "example": "The Left Hand of Darkness"
+                }
+            }
+        },
+        "circulation.Member": {
+            "type": "object",
+            "properties": {
+                "analytics_opt_out": {
+                    "description": "AnalyticsOptOut leaves the member's searches and views out of the\nanonymous usage counts",
+                    "type": "boolean",
+                    "example": false
+                },
+                "anonymize_loans": {
+                    "description": "AnonymizeLoans unlinks the member's loans from them once they are\nreturned without a fine",
+                    "type": "boolean",
+                    "example": false
+                },
+                "auto_renew": {
+                    "description": "AutoRenew opts the member in to having loans renewed shortly before\nthey are due, when no one else is waiting and renewals are left",
+                    "type": "boolean",
+                    "example": false
+                },
+                "card_number": {
+                    "type": "string",
+                    "example": "21234567890128"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string",
+                    "example": "ada@example.org"
+                },
+                "expires_at": {
+                    "description": "ExpiresAt is when a guest account expires; expired members can't\ncheck out, renew or place holds",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "m-1001"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Ada Lovelace"
+                },
+                "patron_group": {
+                    "description": "PatronGroup decides the member's loan and hold limits, loan periods\nand fine rate",
+                    "type": "string",
+                    "enum": [
+                        "child",
+                        "adult",
+                        "senior",
+                        "student",
+                        "staff",
+                        "guest"
+                    ],
+                    "example": "adult"
+                },
+                "suspension": {
+                    "description": "Suspension is set while the member is suspended, through\nPUT /members/{id}/suspension",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/circulation.Suspension"
+                        }
+                    ]
+                }
+            }
+        },
+        "circulation.PatronGroup": {
+            "type": "object",
+            "properties": {
+                "currency": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "fine_per_day": {
+                    "type": "string",
+                    "example": "0.25"
+                },
+                "hold_limit": {
+                    "type": "integer",
+                    "example": 15
+                },
+                "in_library": {
+                    "description": "InLibrary groups borrow for use in the library only",
+                    "type": "boolean",
+                    "example": false
+                },
+                "loan_days": {
+                    "description": "LoanDays is the loan period; 0 uses the loan period of each format",
+                    "type": "integer",
+                    "example": 0
+                },
+                "loan_limit": {
+                    "type": "integer",
+                    "example": 30
+                },
+                "name": {
+                    "type": "string",
+                    "example": "adult"
+                }
+            }
+        },
+        "circulation.ResolveClaimRequest": {
+            "type": "object",
+            "properties": {
+                "charge": {
+                    "description": "Charge is what a lost copy costs the member, in the circulation\ncurrency; it defaults to the copy's replacement cost, or the price\npaid, when that is in the circulation currency",
+                    "type": "string",
+                    "example": "24.99"
+                },
+                "outcome": {
+                    "type": "string",
+                    "enum": [
+                        "found",
+                        "lost"
+                    ],
+                    "example": "lost"
+                }
+            }
+        },
+        "circulation.ShelfCheck": {
+            "type": "object",
+            "properties": {
+                "acquisition_id": {
+                    "type": "integer",
+                    "example": 31
+                },
+                "barcode": {
+                    "type": "string",
+                    "example": "31234000123456"
+                },
+                "branch": {
+                    "type": "string",
+                    "example": "Central"
+                },
+                "call_number": {
+                    "type": "string",
+                    "example": "813.54 LEG"
+                },
+                "checked_at": {
+                    "description": "CheckedAt, Found and Note are set once the shelf check is done",
+                    "type": "string"
+                },
+                "claimed_returned_at": {
+                    "type": "string"
+                },
+                "found": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "loan_id": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "member_id": {
+                    "type": "string",
+                    "example": "m-1001"
+                },
+                "note": {
+                    "type": "string",
+                    "example": "Not on the shelf or the returns trolley"
+                },
+                "requested_at": {
+                    "type": "string"
+                },
+                "shelf_location": {
+                    "type": "string",
+                    "example": "Stacks, level 2"
+                },
+                "title": {
+                    "type": "string",
+                    "example": "The Dispossessed"
+                }
+            }
+        },
+        "circulation.ShelfCheckResult": {
+            "type": "object",
+            "properties": {
+                "found": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "note": {
+                    "type": "string",
+                    "example": "Not on the shelf or the returns trolley"
+                }
+            }
+        },
+        "circulation.ShelfItem": {
+            "type": "object",
+            "properties": {
+                "acquisition_id": {
+                    "type": "integer",
+                    "example": 31
+                },
+                "barcode": {
+                    "type": "string",
+                    "example": "31234000123456"
+                },
+                "book_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "call_number": {
+                    "type": "string",
+                    "example": "813.54 LEG"
+                },
+                "card_number": {
+                    "type": "string",
+                    "example": "21234567890128"
+                },
+                "expired": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "hold_id": {
+                    "type": "integer",
+                    "example": 7
+                },
+                "member_id": {
+                    "type": "string",
+                    "example": "m-1001"
+                },
+                "member_name": {
+                    "type": "string",
+                    "example": "Ada Lovelace"
+                },
+                "pickup_branch": {
+                    "type": "string",
+                    "example": "Central"
+                },
+                "pickup_by": {
+                    "description": "PickupBy is when the hold expires, circulation.hold_shelf_days after\nthe copy was shelved",
+                    "type": "string"
+                },
+                "shelved_at": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string",
+                    "example": "The Dispossessed"
+                }
+            }
+        },
+        "circulation.Status": {
+            "type": "object",
+            "properties": {
+                "balance": {
+                    "type": "string",
+                    "example": "0.75"
+                },
+                "can_checkout": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "can_hold": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "claims_returned": {
+                    "description": "ClaimsReturned counts the loans the member claims to have returned\nwhose claim is not resolved yet; they are not counted as overdue",
+                    "type": "integer",
+                    "example": 0
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "expired": {
+                    "description": "Expired guest accounts can't check out, renew or place holds either",
+                    "type": "boolean",
+                    "example": false
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "fines": {
+                    "description": "Fines is the total of the fines and replacement charges on returned\nloans and the fines accrued so far on loans out past due, leaving out\nthose of loans claimed returned until the claim is resolved",
+                    "type": "string",
+                    "example": "1.75"
+                },
+                "hold_limit": {
+                    "type": "integer",
+                    "example": 15
+                },
+                "holds_waiting": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "loan_limit": {
+                    "type": "integer",
+                    "example": 30
+                },
+                "loans_out": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "member_id": {
+                    "type": "string",
+                    "example": "m-1001"
+                },
+                "overdue": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "paid": {
+                    "description": "Paid is the total of the payments the member made at the desk, and\nBalance what is left of Fines after them",
+                    "type": "string",
+                    "example": "1.00"
+                },
+                "patron_group": {
+                    "type": "string",
+                    "example": "adult"
+                },
+                "suspended": {
+                    "description": "Suspended members can't check out, renew or place holds",
+                    "type": "boolean",
+                    "example": false
+                },
+                "suspension": {
+                    "$ref": "#/definitions/circulation.Suspension"
+                }
+            }
+        },
+        "circulation.Suspension": {
+            "type": "object",
+            "properties": {
+                "reason": {
+                    "type": "string",
+                    "example": "Repeated damage to items"
+                },
+                "suspended_at": {
+                    "type": "string"
+                },
+                "until": {
+                    "description": "Until is when the suspension expires; without it the member stays\nsuspended until it is lifted",
+                    "type": "string"
+                }
+            }
+        },
+        "consent.AcceptRequest": {
+            "type": "object",
+            "properties": {
+                "document_id": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "consent.Acceptance": {
+            "type": "object",
+            "properties": {
+                "accepted_at": {
+                    "type": "string"
+                },
+                "document_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "kind": {
+                    "type": "string",
+                    "example": "terms"
+                },
+                "member_id": {
+                    "type": "string",
+                    "example": "m-1001"
+                },
+                "version": {
+                    "type": "string",
+                    "example": "2026-10"
+                }
+            }
+        },
+        "consent.Document": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "kind": {
+                    "type": "string",
+                    "example": "terms"
+                },
+                "published_at": {
+                    "type": "string"
+                },
+                "required": {
+                    "description": "Required documents must be accepted in their current version before the\nmember can use the API",
+                    "type": "boolean",
+                    "example": true
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Terms of use"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://library.example.org/terms/2026-10"
+                },
+                "version": {
+                    "type": "string",
+                    "example": "2026-10"
+                }
+            }
+        },
+        "consent.MemberConsents": {
+            "type": "object",
+            "properties": {
+                "accepted": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/consent.Acceptance"
+                    }
+                },
+                "member_id": {
+                    "type": "string",
+                    "example": "m-1001"
+                },
+                "outstanding": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/consent.Document"
+                    }
+                }
+            }
+        },
+        "courses.Course": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "Code is the course's catalog code, unique within a term",
+                    "type": "string",
+                    "example": "HIST 210"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "ends_on": {
+                    "type": "string",
+                    "example": "2026-12-18"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "instructor": {
+                    "type": "string",
+                    "example": "Dr. Amara Okafor"
+                },
+                "starts_on": {
+                    "description": "StartsOn and EndsOn bound when the course's reserves apply",
+                    "type": "string",
+                    "example": "2026-09-01"
+                },
+                "term": {
+                    "type": "string",
+                    "example": "Fall 2026"
+                },
+                "title": {
+                    "type": "string",
+                    "example": "The Atlantic World, 1500-1800"
+                }
+            }
+        },
+        "courses.CourseResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "Code is the course's catalog code, unique within a term",
+                    "type": "string",
+                    "example": "HIST 210"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "ends_on": {
+                    "type": "string",
+                    "example": "2026-12-18"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "instructor": {
+                    "type": "string",
+                    "example": "Dr. Amara Okafor"
+                },
+                "reserves": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/courses.Reserve"
+                    }
+                },
+                "starts_on": {
+                    "description": "StartsOn and EndsOn bound when the course's reserves apply",
+                    "type": "string",
+                    "example": "2026-09-01"
+                },
+                "term": {
+                    "type": "string",
+                    "example": "Fall 2026"
+                },
+                "title": {
+                    "type": "string",
+                    "example": "The Atlantic World, 1500-1800"
+                }
+            }
+        },
+        "courses.Reserve": {
+            "type": "object",
+            "properties": {
+                "acquisition_id": {
+                    "type": "integer",
+                    "example": 31
+                },
+                "author": {
+                    "type": "string",
+                    "example": "Suzanne Collins"
+                },
+                "available": {
+                    "description": "Available is false while the copy is withdrawn, out for repair or on\nloan",
+                    "type": "boolean",
+                    "example": true
+                },
+                "book_id": {
+                    "description": "The copy and its book, filled in on reads",
+                    "type": "integer",
+                    "example": 1
+                },
+                "branch": {
+                    "type": "string",
+                    "example": "Central"
+                },
+                "call_number": {
+                    "type": "string",
+                    "example": "813.6 COL"
+                },
+                "course_id": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 40
+                },
+                "loan_hours": {
+                    "description": "LoanHours is the reserve loan period; it defaults to 3",
+                    "type": "integer",
+                    "example": 3
+                },
+                "max_renewals": {
+                    "description": "MaxRenewals defaults to 0, no renewals",
+                    "type": "integer",
+                    "example": 0
+                },
+                "note": {
+                    "type": "string",
+                    "example": "Chapters 3-5 required for week 2"
+                },
+                "title": {
+                    "type": "string",
+                    "example": "The Hunger Games"
+                }
+            }
+        },
+        "devices.Dashboard": {
+            "type": "object",
+            "properties": {
+                "devices": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/devices.Device"
+                    }
+                },
+                "online": {
+                    "type": "integer",
+                    "example": 14
+                },
+                "silent": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "devices.Device": {
+            "type": "object",
+            "properties": {
+                "branch": {
+                    "type": "string",
+                    "example": "Central"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "kind": {
+                    "type": "string",
+                    "enum": [
+                        "kiosk",
+                        "gate"
+                    ],
+                    "example": "kiosk"
+                },
+                "last_error": {
+                    "$ref": "#/definitions/devices.DeviceError"
+                },
+                "last_seen_at": {
+                    "type": "string"
+                },
+                "location": {
+                    "type": "string",
+                    "example": "Ground floor, by the entrance"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Central self-check 1"
+                },
+                "recent_errors": {
+                    "description": "RecentErrors counts the errors reported in the last 24 hours",
+                    "type": "integer",
+                    "example": 0
+                },
+                "registered_at": {
+                    "type": "string"
+                },
+                "serial": {
+                    "description": "Serial identifies the device across registrations",
+                    "type": "string",
+                    "example": "SC-2041-0193"
+                },
+                "silent_alerted_at": {
+                    "description": "SilentAlertedAt is when the device was alerted as silent; it is\ncleared by the next heartbeat",
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "online",
+                        "silent"
+                    ],
+                    "example": "online"
+                },
+                "version": {
+                    "type": "string",
+                    "example": "4.2.1"
+                }
+            }
+        },
+        "devices.DeviceError": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "printer_jam"
+                },
+                "device_id": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 812
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Receipt printer paper jam"
+                },
+                "reported_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "devices.ErrorReport": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "printer_jam"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Receipt printer paper jam"
+                }
+            }
+        },
+        "devices.Heartbeat": {
+            "type": "object",
+            "properties": {
+                "version": {
+                    "description": "Version replaces the software version registered, when given",
+                    "type": "string",
+                    "example": "4.2.1"
+                }
+            }
+        },
+        "devices.Registration": {
+            "type": "object",
+            "properties": {
+                "branch": {
+                    "type": "string",
+                    "example": "Central"
+                },
+                "kind": {
+                    "type": "string",
+                    "enum": [
+                        "kiosk",
+                        "gate"
+                    ],
+                    "example": "kiosk"
+                },
+                "location": {
+                    "type": "string",
+                    "example": "Ground floor, by the entrance"
+                },
+                "name": {
+                    "description": "Name defaults to the serial",
+                    "type": "string",
+                    "example": "Central self-check 1"
+                },
+                "serial": {
+                    "type": "string",
+                    "example": "SC-2041-0193"
+                },
+                "version": {
+                    "type": "string",
+                    "example": "4.2.1"
+                }
+            }
+        },
+        "embeddings.IndexStatus": {
+            "type": "object",
+            "properties": {
+                "books": {
+                    "description": "Books counts the books of the catalog, and Embedded those whose\nembedding is up to date with their title, description and the model",
+                    "type": "integer",
+                    "example": 5120
+                },
+                "embedded": {
+                    "type": "integer",
+                    "example": 5096
+                },
+                "model": {
+                    "type": "string",
+                    "example": "text-embedding-3-small"
+                }
+            }
+        },
+        "embeddings.Match": {
+            "type": "object",
+            "properties": {
+                "author": {
+                    "type": "string",
+                    "example": "Ursula K. Le Guin"
+                },
+                "book_id": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "similarity": {
+                    "description": "Similarity is the cosine similarity of the embeddings, 1 for the same\nmeaning; keyword matches have none",
+                    "type": "number",
+                    "example": 0.83
+                },
+                "title": {
+                    "type": "string",
+                    "example": "The Dispossessed"
+                }
+            }
+        },
+        "embeddings.Matches": {
+            "type": "object",
+            "properties": {
+                "books": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/embeddings.Match"
+                    }
+                },
+                "model": {
+                    "description": "Model is the embedding model the books were compared with",
+                    "type": "string",
+                    "example": "text-embedding-3-small"
+                }
+            }
+        },
+        "embeddings.NaturalSearchRequest": {
+            "type": "object",
+            "properties": {
+                "limit": {
+                    "description": "Limit defaults to 10, at most 50",
+                    "type": "integer",
+                    "example": 10
+                },
+                "query": {
+                    "type": "string",
+                    "example": "mystery novels set in Venice"
+                }
+            }
+        },
+        "embeddings.NaturalSearchResult": {
+            "type": "object",
+            "properties": {
+                "books": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/embeddings.Match"
+                    }
+                },
+                "method": {
+                    "description": "Method is semantic when the books were ranked by the embedding index,\nor keyword when it was disabled, unavailable or found nothing and the\nquery ran as a catalog search instead",
+                    "type": "string",
+                    "enum": [
+                        "semantic",
+                        "keyword"
+                    ],
+                    "example": "semantic"
+                },
+                "model": {
+                    "description": "Model is the embedding model of a semantic search",
+                    "type": "string",
+                    "example": "text-embedding-3-small"
+                },
+                "query": {
+                    "type": "string",
+                    "example": "mystery novels set in Venice"
+                }
+            }
+        },
+        "experiments.Assignment": {
+            "type": "object",
+            "properties": {
+                "experiment": {
+                    "type": "string",
+                    "example": "recommendations"
+                },
+                "variant": {
+                    "type": "string",
+                    "example": "co_borrowing"
+                }
+            }
+        },
+        "experiments.ConversionRequest": {
+            "type": "object",
+            "properties": {
+                "metric": {
+                    "type": "string",
+                    "example": "click"
+                }
+            }
+        },
+        "experiments.Experiment": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Co-borrowing against content-based recommendations"
+                },
+                "key": {
+                    "type": "string",
+                    "example": "recommendations"
+                },
+                "running": {
+                    "description": "Running experiments assign variants; stopped ones keep their results\nand leave every request on the default behavior",
+                    "type": "boolean",
+                    "example": true
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "variants": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/experiments.Variant"
+                    }
+                }
+            }
+        },
+        "experiments.ExperimentRequest": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "example": "Co-borrowing against content-based recommendations"
+                },
+                "running": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "variants": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/experiments.Variant"
+                    }
+                }
+            }
+        },
+        "experiments.MetricResult": {
+            "type": "object",
+            "properties": {
+                "events": {
+                    "type": "integer",
+                    "example": 63
+                },
+                "metric": {
+                    "type": "string",
+                    "example": "checkout"
+                },
+                "rate": {
+                    "description": "Rate is units / the units exposed to the variant",
+                    "type": "number",
+                    "example": 0.082
+                },
+                "units": {
+                    "type": "integer",
+                    "example": 41
+                }
+            }
+        },
+        "experiments.Results": {
+            "type": "object",
+            "properties": {
+                "experiment": {
+                    "type": "string",
+                    "example": "recommendations"
+                },
+                "running": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "variants": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/experiments.VariantResult"
+                    }
+                }
+            }
+        },
+        "experiments.Variant": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "co_borrowing"
+                },
+                "weight": {
+                    "type": "integer",
+                    "example": 50
+                }
+            }
+        },
+        "experiments.VariantResult": {
+            "type": "object",
+            "properties": {
+                "metrics": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/experiments.MetricResult"
+                    }
+                },
+                "units": {
+                    "type": "integer",
+                    "example": 500
+                },
+                "variant": {
+                    "type": "string",
+                    "example": "co_borrowing"
+                },
+                "weight": {
+                    "type": "integer",
+                    "example": 50
+                }
+            }
+        },
+        "feed.Feed": {
+            "type": "object",
+            "properties": {
+                "favorite_collections": {
+                    "description": "FavoriteCollections are the collections the member borrows most from,\nwhich new arrivals are picked from; empty when they have no loans on\nrecord, and new arrivals then come from every collection",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "Adult Fiction",
+                        "Science Fiction"
+                    ]
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/feed.Item"
+                    }
+                },
+                "member_id": {
+                    "type": "string",
+                    "example": "m-1001"
+                }
+            }
+        },
+        "feed.Item": {
+            "type": "object",
+            "properties": {
+                "acquired_on": {
+                    "description": "AcquiredOn is when the library received the first copy of a new\narrival",
+                    "type": "string"
+                },
+                "author": {
+                    "type": "string",
+                    "example": "Ursula K. Le Guin"
+                },
+                "book_id": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "collection": {
+                    "type": "string",
+                    "example": "Adult Fiction"
+                },
+                "due_at": {
+                    "type": "string"
+                },
+                "hold_id": {
+                    "type": "integer",
+                    "example": 7
+                },
+                "kind": {
+                    "type": "string",
+                    "enum": [
+                        "hold_ready",
+                        "due_soon",
+                        "new_arrival",
+                        "recommended"
+                    ],
+                    "example": "hold_ready"
+                },
+                "loan_id": {
+                    "type": "integer",
+                    "example": 31
+                },
+                "overdue": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "pickup_branch": {
+                    "type": "string",
+                    "example": "Central"
+                },
+                "pickup_by": {
+                    "description": "PickupBy is when the ready hold expires",
+                    "type": "string"
+                },
+                "strategy": {
+                    "description": "Strategy is the recommender that picked a recommended book",
+                    "type": "string",
+                    "enum": [
+                        "co_borrowing",
+                        "content",
+                        "random"
+                    ],
+                    "example": "co_borrowing"
+                },
+                "title": {
+                    "type": "string",
+                    "example": "The Dispossessed"
+                }
+            }
+        },
+        "health.Result": {
+            "type": "object",
+            "properties": {
+                "cached": {
+                    "description": "Cached is true when the result was served from the cache rather than\nprobed for this request",
+                    "type": "boolean"
+                },
+                "checked_at": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "latency_ms": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "ilssync.Problem": {
+            "type": "object",
+            "properties": {
+                "outcome": {
+                    "type": "string",
+                    "example": "conflict"
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "isbn13 9780261102354 and oclc 12345 belong to different books (7, 19)"
+                },
+                "record_id": {
+                    "type": "string",
+                    "example": "1042"
+                }
+            }
+        },
+        "ilssync.Report": {
+            "type": "object",
+            "properties": {
+                "conflicts": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "created": {
+                    "type": "integer",
+                    "example": 40
+                },
+                "error": {
+                    "description": "Error is set when the run stopped early; records before it were applied",
+                    "type": "string"
+                },
+                "fetched": {
+                    "type": "integer",
+                    "example": 1200
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "full": {
+                    "type": "boolean"
+                },
+                "problems": {
+                    "description": "Problems lists skipped and conflicting records, the first 100 of them",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/ilssync.Problem"
+                    }
+                },
+                "provider": {
+                    "type": "string",
+                    "example": "koha"
+                },
+                "skipped": {
+                    "type": "integer",
+                    "example": 6
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "unchanged": {
+                    "type": "integer",
+                    "example": 1140
+                },
+                "updated": {
+                    "type": "integer",
+                    "example": 12
+                }
+            }
+        },
+        "ilssync.Status": {
+            "type": "object",
+            "properties": {
+                "enabled": {
+                    "type": "boolean"
+                },
+                "interval": {
+                    "description": "Interval is the time between scheduled runs; empty when runs are only\nstarted by hand",
+                    "type": "string",
+                    "example": "1h0m0s"
+                },
+                "last_run": {
+                    "$ref": "#/definitions/ilssync.Report"
+                },
+                "provider": {
+                    "type": "string",
+                    "example": "koha"
+                },
+                "running": {
+                    "type": "boolean"
+                },
+                "synced_through": {
+                    "description": "SyncedThrough is where the next incremental run picks up; records\nchanged in the ILS after it are fetched",
+                    "type": "string"
+                }
+            }
+        },
+        "media.Asset": {
+            "type": "object",
+            "properties": {
+                "book_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "content_type": {
+                    "type": "string",
+                    "example": "audio/mpeg"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "filename": {
+                    "type": "string",
+                    "example": "chapter-01.mp3"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 7
+                },
+                "size_bytes": {
+                    "type": "integer",
+                    "example": 48213311
+                }
+            }
+        },
+        "media.StreamTokenRequest": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "description": "ExpiresAt ends access earlier; it defaults to media.token_ttl from now\nand may be at most media.max_loan_period ahead. Either way the token\nexpires at the loan's due date at the latest.",
+                    "type": "string",
+                    "example": "2024-06-01T00:00:00Z"
+                },
+                "loan_id": {
+                    "description": "LoanID is the member's loan of the asset's book; it must be out, not\nclaimed returned and not past due",
+                    "type": "integer",
+                    "example": 42
+                }
+            }
+        },
+        "media.StreamTokenResponse": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "type": "string"
+                },
+                "opds_url": {
+                    "description": "OPDSURL is the OPDS-PSE feed for readers that stream pages; it is only\nset for EPUB and CBZ assets",
+                    "type": "string",
+                    "example": "/api/v1/media/stream/eyJ.../opds"
+                },
+                "token": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "/api/v1/media/stream/eyJ..."
+                }
+            }
+        },
+        "payments.CloseRequest": {
+            "type": "object",
+            "properties": {
+                "counted_cash": {
+                    "type": "string",
+                    "example": "112.50"
+                },
+                "note": {
+                    "type": "string",
+                    "example": "Short a quarter"
+                }
+            }
+        },
+        "payments.OpenRequest": {
+            "type": "object",
+            "properties": {
+                "branch": {
+                    "type": "string",
+                    "example": "Central"
+                },
+                "opening_float": {
+                    "type": "string",
+                    "example": "100.00"
+                },
+                "staff": {
+                    "type": "string",
+                    "example": "jdoe"
+                }
+            }
+        },
+        "payments.Payment": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "string",
+                    "example": "2.50"
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 17
+                },
+                "loan_id": {
+                    "description": "LoanID is the loan whose fine or replacement charge the payment is\nfor, if it is for one",
+                    "type": "integer",
+                    "example": 12
+                },
+                "member_id": {
+                    "type": "string",
+                    "example": "m-1001"
+                },
+                "method": {
+                    "type": "string",
+                    "enum": [
+                        "cash",
+                        "card"
+                    ],
+                    "example": "cash"
+                },
+                "reference": {
+                    "type": "string",
+                    "example": "Card terminal receipt 004512"
+                },
+                "session_id": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "taken_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "payments.PaymentRequest": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "string",
+                    "example": "2.50"
+                },
+                "loan_id": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "member_id": {
+                    "type": "string",
+                    "example": "m-1001"
+                },
+                "method": {
+                    "type": "string",
+                    "enum": [
+                        "cash",
+                        "card"
+                    ],
+                    "example": "cash"
+                },
+                "reference": {
+                    "type": "string",
+                    "example": ""
+                }
+            }
+        },
+        "payments.Session": {
+            "type": "object",
+            "properties": {
+                "branch": {
+                    "type": "string",
+                    "example": "Central"
+                },
+                "card_taken": {
+                    "type": "string",
+                    "example": "24.99"
+                },
+                "cash_taken": {
+                    "type": "string",
+                    "example": "12.75"
+                },
+                "closed_at": {
+                    "type": "string"
+                },
+                "counted_cash": {
+                    "description": "CountedCash is what was in the drawer when it was closed, and\nDifference how far that is over (positive) or short (negative) of\nExpectedCash",
+                    "type": "string",
+                    "example": "112.50"
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "difference": {
+                    "type": "string",
+                    "example": "-0.25"
+                },
+                "expected_cash": {
+                    "description": "ExpectedCash is what the drawer should hold: the float and the cash\ntaken",
+                    "type": "string",
+                    "example": "112.75"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "ledger": {
+                    "description": "Ledger lists the session's payments, in the order taken; it is only\nincluded when a single session is requested",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/payments.Payment"
+                    }
+                },
+                "note": {
+                    "type": "string",
+                    "example": "Short a quarter"
+                },
+                "opened_at": {
+                    "type": "string"
+                },
+                "opening_float": {
+                    "description": "OpeningFloat is the cash the drawer started with",
+                    "type": "string",
+                    "example": "100.00"
+                },
+                "payments": {
+                    "description": "Payments counts the payments taken; CashTaken and CardTaken total\nthem by method",
+                    "type": "integer",
+                    "example": 4
+                },
+                "staff": {
+                    "type": "string",
+                    "example": "jdoe"
+                }
+            }
+        },
+        "picks.Carousel": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "type": "string",
+                    "example": "2025-07-14"
+                },
+                "features": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/picks.Feature"
+                    }
+                }
+            }
+        },
+        "picks.Feature": {
+            "type": "object",
+            "properties": {
+                "author": {
+                    "type": "string",
+                    "example": "Ursula K. Le Guin"
+                },
+                "book_id": {
+                    "type": "integer",
+                    "example": 7
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "ends_on": {
+                    "type": "string",
+                    "example": "2025-08-31"
+                },
+                "headline": {
+                    "description": "Headline is the caption on the carousel, defaulting to the title",
+                    "type": "string",
+                    "example": "Books for the beach"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 9
+                },
+                "kind": {
+                    "type": "string",
+                    "enum": [
+                        "list",
+                        "book"
+                    ],
+                    "example": "list"
+                },
+                "list_id": {
+                    "description": "ListID and Slug are set for featured lists, BookID and Author for\nfeatured titles",
+                    "type": "integer",
+                    "example": 4
+                },
+                "position": {
+                    "description": "Features are shown by position, then in the order they were scheduled",
+                    "type": "integer",
+                    "example": 1
+                },
+                "slug": {
+                    "type": "string",
+                    "example": "summer-reads-2025"
+                },
+                "starts_on": {
+                    "type": "string",
+                    "example": "2025-06-01"
+                },
+                "title": {
+                    "description": "Title is the featured list's or book's title",
+                    "type": "string",
+                    "example": "Summer Reads 2025"
+                }
+            }
+        },
+        "picks.FeatureRequest": {
+            "type": "object",
+            "properties": {
+                "book_id": {
+                    "type": "integer"
+                },
+                "ends_on": {
+                    "description": "EndsOn defaults to starts_on",
+                    "type": "string",
+                    "example": "2025-08-31"
+                },
+                "headline": {
+                    "type": "string",
+                    "example": "Books for the beach"
+                },
+                "list_id": {
+                    "type": "integer",
+                    "example": 4
+                },
+                "position": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "starts_on": {
+                    "type": "string",
+                    "example": "2025-06-01"
+                }
+            }
+        },
+        "picks.Item": {
+            "type": "object",
+            "properties": {
+                "author": {
+                    "type": "string",
+                    "example": "Ursula K. Le Guin"
+                },
+                "book_id": {
+                    "type": "integer",
+                    "example": 7
+                },
+                "note": {
+                    "description": "Note is the staff blurb shown with the book",
+                    "type": "string",
+                    "example": "A winter planet for a hot afternoon."
+                },
+                "position": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "title": {
+                    "type": "string",
+                    "example": "The Left Hand of Darkness"
+                }
+            }
+        },
+        "picks.ItemRequest": {
+            "type": "object",
+            "properties": {
+                "book_id": {
+                    "type": "integer",
+                    "example": 7
+                },
+                "note": {
+                    "type": "string",
+                    "example": "A winter planet for a hot afternoon."
+                }
+            }
+        },
+        "picks.ItemsRequest": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/picks.ItemRequest"
+                    }
+                }
+            }
+        },
+        "picks.List": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Our librarians' favourites for the long days."
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 4
+                },
+                "item_count": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "items": {
+                    "description": "Items are only included when a single list is requested",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/picks.Item"
+                    }
+                },
+                "published": {
+                    "description": "Published lists are shown on the public endpoints and feed; drafts\nonly to staff",
+                    "type": "boolean",
+                    "example": true
+                },
+                "slug": {
+                    "description": "Slug names the list in its public URL and feed",
+                    "type": "string",
+                    "example": "summer-reads-2025"
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Summer Reads 2025"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "picks.ListRequest": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "example": "Our librarians' favourites for the long days."
+                },
+                "published": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "slug": {
+                    "description": "Slug defaults to one made from the title",
+                    "type": "string",
+                    "example": "summer-reads-2025"
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Summer Reads 2025"
+                }
+            }
+        },
+        "policy.FormatLimit": {
+            "type": "object",
+            "properties": {
+                "formats": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "dvd",
+                        "bluray"
+                    ]
+                },
+                "limit": {
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "policy.Terms": {
+            "type": "object",
+            "properties": {
+                "currency": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "fine_per_day": {
+                    "type": "string",
+                    "example": "0.25"
+                },
+                "format": {
+                    "type": "string",
+                    "example": "dvd"
+                },
+                "format_limit": {
+                    "description": "FormatLimit caps the member's loans of some formats, this one among\nthem",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/policy.FormatLimit"
+                        }
+                    ]
+                },
+                "grace_days": {
+                    "description": "GraceDays is how many open days past due a copy may be returned\nwithout a fine",
+                    "type": "integer",
+                    "example": 2
+                },
+                "in_library": {
+                    "description": "InLibrary lends for use in the library only, due by the end of the\nday without renewals",
+                    "type": "boolean",
+                    "example": false
+                },
+                "loan_days": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "loan_limit": {
+                    "description": "LoanLimit caps the member's loans of every format",
+                    "type": "integer",
+                    "example": 10
+                },
+                "max_fine": {
+                    "description": "MaxFine caps the fine per copy; empty means no cap",
+                    "type": "string",
+                    "example": "10.00"
+                },
+                "max_renewals": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "patron_group": {
+                    "type": "string",
+                    "example": "child"
+                }
+            }
+        },
+        "purchasing.MessageResult": {
+            "type": "object",
+            "properties": {
+                "acquisitions_recorded": {
+                    "description": "AcquisitionsRecorded counts the invoiced copies recorded as acquisitions",
+                    "type": "integer",
+                    "example": 6
+                },
+                "error": {
+                    "description": "Error explains why the message was not applied",
+                    "type": "string",
+                    "example": "already processed"
+                },
+                "lines_matched": {
+                    "description": "LinesMatched counts the message lines applied to order lines",
+                    "type": "integer",
+                    "example": 3
+                },
+                "order_id": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "reference": {
+                    "description": "Reference is the vendor's document number from BGM",
+                    "type": "string",
+                    "example": "INV-20417"
+                },
+                "type": {
+                    "description": "Type is the EDIFACT message type, INVOIC or QUOTES",
+                    "type": "string",
+                    "example": "INVOIC"
+                },
+                "unmatched": {
+                    "description": "Unmatched describes the message lines that matched no order line",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "purchasing.OrderLine": {
+            "type": "object",
+            "properties": {
+                "author": {
+                    "type": "string",
+                    "example": "F. Scott Fitzgerald"
+                },
+                "book_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "invoiced_price": {
+                    "type": "string",
+                    "example": "9.49"
+                },
+                "invoiced_quantity": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "isbn": {
+                    "type": "string",
+                    "example": "9780743273565"
+                },
+                "line_number": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "price": {
+                    "description": "Price is the expected unit price, sent to the vendor if given",
+                    "type": "string",
+                    "example": "9.99"
+                },
+                "quantity": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "quoted_price": {
+                    "description": "QuotedPrice is the unit price of the vendor's latest quote",
+                    "type": "string",
+                    "example": "9.49"
+                },
+                "title": {
+                    "type": "string",
+                    "example": "The Great Gatsby"
+                }
+            }
+        },
+        "purchasing.PurchaseOrder": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "GBP"
+                },
+                "funding_source": {
+                    "description": "FundingSource is recorded on the acquisitions of invoiced copies",
+                    "type": "string",
+                    "example": "Book fund 2026"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "lines": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/purchasing.OrderLine"
+                    }
+                },
+                "sent_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "open",
+                        "sent",
+                        "quoted",
+                        "partially_invoiced",
+                        "invoiced"
+                    ],
+                    "example": "open"
+                },
+                "vendor": {
+                    "type": "string",
+                    "example": "Gardners Books"
+                },
+                "vendor_id": {
+                    "description": "VendorID identifies the vendor in EDI: a 13-digit GLN or a 7-digit SAN",
+                    "type": "string",
+                    "example": "5013546000008"
+                }
+            }
+        },
+        "ratelimit.KeyStatus": {
+            "type": "object",
+            "properties": {
+                "api_key_id": {
+                    "type": "string",
+                    "example": "catalog-kiosk"
+                },
+                "burst": {
+                    "description": "Burst is how many requests may arrive at once; 0 uses RequestsPerMinute",
+                    "type": "integer",
+                    "example": 60
+                },
+                "daily_quota": {
+                    "type": "integer",
+                    "example": 50000
+                },
+                "override": {
+                    "description": "Override is false when the key runs on the default limit",
+                    "type": "boolean"
+                },
+                "requests_per_minute": {
+                    "type": "integer",
+                    "example": 600
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "used_today": {
+                    "description": "UsedToday counts the requests this instance allowed since midnight UTC",
+                    "type": "integer",
+                    "example": 1234
+                }
+            }
+        },
+        "ratelimit.Limit": {
+            "type": "object",
+            "properties": {
+                "burst": {
+                    "description": "Burst is how many requests may arrive at once; 0 uses RequestsPerMinute",
+                    "type": "integer",
+                    "example": 60
+                },
+                "daily_quota": {
+                    "type": "integer",
+                    "example": 50000
+                },
+                "requests_per_minute": {
+                    "type": "integer",
+                    "example": 600
+                }
+            }
+        },
+        "ratelimit.LimitsResponse": {
+            "type": "object",
+            "properties": {
+                "default": {
+                    "$ref": "#/definitions/ratelimit.Limit"
+                },
+                "overrides": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/ratelimit.KeyStatus"
+                    }
+                }
+            }
+        },
+        "readinglist.Entry": {
+            "type": "object",
+            "properties": {
+                "author": {
+                    "type": "string",
+                    "example": "F. Scott Fitzgerald"
+                },
+                "book_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "date_read": {
+                    "type": "string",
+                    "example": "2024-03-17"
+                },
+                "rating": {
+                    "description": "Rating is the member's star rating, 0.25 to 5",
+                    "type": "number",
+                    "example": 4.5
+                },
+                "shelf": {
+                    "type": "string",
+                    "enum": [
+                        "read",
+                        "currently-reading",
+                        "to-read",
+                        "did-not-finish"
+                    ],
+                    "example": "read"
+                },
+                "title": {
+                    "type": "string",
+                    "example": "The Great Gatsby"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "readinglist.ImportReport": {
+            "type": "object",
+            "properties": {
+                "imported": {
+                    "type": "integer",
+                    "example": 187
+                },
+                "rows": {
+                    "type": "integer",
+                    "example": 212
+                },
+                "source": {
+                    "description": "Source is the detected export format, \"goodreads\" or \"storygraph\"",
+                    "type": "string",
+                    "example": "goodreads"
+                },
+                "unmatched": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/readinglist.UnmatchedRow"
+                    }
+                }
+            }
+        },
+        "readinglist.UnmatchedRow": {
+            "type": "object",
+            "properties": {
+                "author": {
+                    "type": "string",
+                    "example": "Suzanne Collins"
+                },
+                "isbn": {
+                    "type": "string",
+                    "example": "9780439023481"
+                },
+                "line": {
+                    "description": "Line is the row's line in the CSV file; the header is line 1",
+                    "type": "integer",
+                    "example": 14
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "no catalog title matches"
+                },
+                "title": {
+                    "type": "string",
+                    "example": "The Hunger Games (The Hunger Games, #1)"
+                }
+            }
+        },
+        "recommendations.Recommendation": {
+            "type": "object",
+            "properties": {
+                "audience": {
+                    "type": "string",
+                    "enum": [
+                        "children",
+                        "teen",
+                        "adult"
+                    ],
+                    "example": "adult"
+                },
+                "author": {
+                    "type": "string",
+                    "example": "Ursula K. Le Guin"
+                },
+                "book_id": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "collection": {
+                    "type": "string",
+                    "example": "Adult Fiction"
+                },
+                "score": {
+                    "description": "Score ranks the books of a strategy; it is 0 for random picks",
+                    "type": "number",
+                    "example": 7
+                },
+                "strategy": {
+                    "description": "Strategy is the recommender that picked the book; random for books\nfilled in by the fallback",
+                    "type": "string",
+                    "enum": [
+                        "co_borrowing",
+                        "content",
+                        "random"
+                    ],
+                    "example": "co_borrowing"
+                },
+                "title": {
+                    "type": "string",
+                    "example": "The Dispossessed"
+                }
+            }
+        },
+        "recommendations.Recommendations": {
+            "type": "object",
+            "properties": {
+                "books": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/recommendations.Recommendation"
+                    }
+                },
+                "strategy": {
+                    "description": "Strategy is the recommender asked first, from recommendations.strategy\nor the caller's variant in the recommendations experiment",
+                    "type": "string",
+                    "enum": [
+                        "co_borrowing",
+                        "content",
+                        "random"
+                    ],
+                    "example": "co_borrowing"
+                }
+            }
+        },
+        "repairs.Repair": {
+            "type": "object",
+            "properties": {
+                "acquisition_id": {
+                    "type": "integer",
+                    "example": 31
+                },
+                "book_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "branch": {
+                    "type": "string",
+                    "example": "Central"
+                },
+                "cost": {
+                    "description": "Cost is the quoted cost while the copy is out and the final cost once\nit is returned, a decimal amount in Currency",
+                    "type": "string",
+                    "example": "12.50"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "currency": {
+                    "description": "ISO 4217",
+                    "type": "string",
+                    "example": "USD"
+                },
+                "damage": {
+                    "description": "Damage describes what is wrong with the copy",
+                    "type": "string",
+                    "example": "Spine split, loose pages"
+                },
+                "expected_on": {
+                    "type": "string",
+                    "example": "2026-04-15"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "kind": {
+                    "type": "string",
+                    "enum": [
+                        "repair",
+                        "binding"
+                    ],
+                    "example": "binding"
+                },
+                "note": {
+                    "type": "string",
+                    "example": "Rebind in library buckram"
+                },
+                "overdue": {
+                    "description": "Overdue is set for copies still out after their expected return date",
+                    "type": "boolean",
+                    "example": false
+                },
+                "returned_on": {
+                    "type": "string",
+                    "example": "2026-04-10"
+                },
+                "sent_on": {
+                    "description": "SentOn defaults to today",
+                    "type": "string",
+                    "example": "2026-03-02"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "out",
+                        "returned"
+                    ],
+                    "example": "out"
+                },
+                "title": {
+                    "type": "string",
+                    "example": "The Hunger Games"
+                },
+                "vendor": {
+                    "type": "string",
+                    "example": "Hertzberg Bindery"
+                }
+            }
+        },
+        "repairs.Return": {
+            "type": "object",
+            "properties": {
+                "cost": {
+                    "description": "Cost replaces the quoted cost with the invoiced one when given, in\nCurrency or the repair's currency",
+                    "type": "string",
+                    "example": "14.00"
+                },
+                "currency": {
+                    "type": "string",
+                    "example": ""
+                },
+                "note": {
+                    "type": "string",
+                    "example": ""
+                },
+                "returned_on": {
+                    "description": "ReturnedOn defaults to today",
+                    "type": "string",
+                    "example": "2026-04-10"
+                }
+            }
+        },
+        "rfid.EncodeRequest": {
+            "type": "object",
+            "properties": {
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/rfid.EncodeTag"
+                    }
+                }
+            }
+        },
+        "rfid.EncodeTag": {
+            "type": "object",
+            "properties": {
+                "acquisition_id": {
+                    "type": "integer",
+                    "example": 31
+                },
+                "uid": {
+                    "type": "string",
+                    "example": "E004015012345678"
+                }
+            }
+        },
+        "rfid.Encoded": {
+            "type": "object",
+            "properties": {
+                "encoded": {
+                    "type": "integer",
+                    "example": 40
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/rfid.Tag"
+                    }
+                }
+            }
+        },
+        "rfid.Item": {
+            "type": "object",
+            "properties": {
+                "acquisition_id": {
+                    "type": "integer",
+                    "example": 31
+                },
+                "alarm": {
+                    "description": "Alarm is set for copies that are not checked out, which a security\ngate should not let leave",
+                    "type": "boolean",
+                    "example": true
+                },
+                "book_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "branch": {
+                    "type": "string",
+                    "example": "Central"
+                },
+                "checked_out": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "title": {
+                    "type": "string",
+                    "example": "The Left Hand of Darkness"
+                },
+                "uid": {
+                    "type": "string",
+                    "example": "E004015012345678"
+                },
+                "withdrawn": {
+                    "type": "boolean",
+                    "example": false
+                }
+            }
+        },
+        "rfid.ResolveRequest": {
+            "type": "object",
+            "properties": {
+                "uids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "E004015012345678",
+                        "E004015087654321"
+                    ]
+                }
+            }
+        },
+        "rfid.Resolved": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/rfid.Item"
+                    }
+                },
+                "unknown": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "E0040150DEADBEEF"
+                    ]
+                }
+            }
+        },
+        "rfid.Tag": {
+            "type": "object",
+            "properties": {
+                "acquisition_id": {
+                    "type": "integer",
+                    "example": 31
+                },
+                "encoded_at": {
+                    "type": "string"
+                },
+                "uid": {
+                    "description": "UID is the tag's unique ID in upper-case hex, as readers report it",
+                    "type": "string",
+                    "example": "E004015012345678"
+                }
+            }
+        },
+        "rfid.TagRequest": {
+            "type": "object",
+            "properties": {
+                "uid": {
+                    "type": "string",
+                    "example": "E0:04:01:50:12:34:56:78"
+                }
+            }
+        },
+        "savedsearch.Query": {
+            "type": "object",
+            "properties": {
+                "accessibility_features": {
+                    "description": "AccessibilityFeatures matches books that have every listed feature",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "audiences": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "adult"
+                    ]
+                },
+                "formats": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "hardcover",
+                        "ebook"
+                    ]
+                },
+                "search": {
+                    "description": "title, author, ISBN or full text",
+                    "type": "string",
+                    "example": "brandon sanderson"
+                },
+                "year_from": {
+                    "description": "inclusive",
+                    "type": "integer",
+                    "example": 2020
+                },
+                "year_to": {
+                    "description": "inclusive",
+                    "type": "integer"
+                }
+            }
+        },
+        "savedsearch.SavedSearch": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "last_alerted_at": {
+                    "type": "string"
+                },
+                "last_book_id": {
+                    "description": "LastBookID is the last book added to the catalog when the search was\nsaved or last checked; only books added after it alert the member",
+                    "type": "integer",
+                    "example": 1042
+                },
+                "member_id": {
+                    "type": "string",
+                    "example": "m-1001"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "New books by Brandon Sanderson"
+                },
+                "query": {
+                    "$ref": "#/definitions/savedsearch.Query"
+                }
+            }
+        },
+        "serials.CheckIn": {
+            "type": "object",
+            "properties": {
+                "copies": {
+                    "description": "Copies defaults to the subscription's copies",
+                    "type": "integer",
+                    "example": 1
+                },
+                "note": {
+                    "type": "string",
+                    "example": ""
+                },
+                "received_on": {
+                    "description": "ReceivedOn defaults to today",
+                    "type": "string",
+                    "example": "2026-01-07"
+                }
+            }
+        },
+        "serials.Claim": {
+            "type": "object",
+            "properties": {
+                "claim_count": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "copies_received": {
+                    "description": "CopiesReceived counts the copies checked in",
+                    "type": "integer",
+                    "example": 1
+                },
+                "days_overdue": {
+                    "description": "DaysOverdue counts from the expected date",
+                    "type": "integer",
+                    "example": 41
+                },
+                "expected_on": {
+                    "type": "string",
+                    "example": "2026-01-05"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 120
+                },
+                "issn": {
+                    "type": "string",
+                    "example": "00279358"
+                },
+                "last_claimed_on": {
+                    "type": "string",
+                    "example": "2026-02-10"
+                },
+                "note": {
+                    "type": "string",
+                    "example": "Special issue"
+                },
+                "number": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "received_on": {
+                    "type": "string",
+                    "example": "2026-01-07"
+                },
+                "sequence": {
+                    "description": "Sequence is the issue's place in the prediction, from 0; it is\nempty for issues checked in without being predicted",
+                    "type": "integer",
+                    "example": 0
+                },
+                "serial_id": {
+                    "type": "integer",
+                    "example": 4
+                },
+                "serial_title": {
+                    "type": "string",
+                    "example": "National Geographic"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "expected",
+                        "received",
+                        "claimed"
+                    ],
+                    "example": "expected"
+                },
+                "subscription_id": {
+                    "type": "integer",
+                    "example": 7
+                },
+                "vendor": {
+                    "type": "string",
+                    "example": "EBSCO"
+                },
+                "volume": {
+                    "type": "integer",
+                    "example": 249
+                }
+            }
+        },
+        "serials.CostHistory": {
+            "type": "object",
+            "properties": {
+                "periods": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/serials.CostPeriod"
+                    }
+                },
+                "serial_id": {
+                    "type": "integer",
+                    "example": 4
+                },
+                "title": {
+                    "type": "string",
+                    "example": "National Geographic"
+                }
+            }
+        },
+        "serials.CostPeriod": {
+            "type": "object",
+            "properties": {
+                "change": {
+                    "description": "Change and ChangePercent compare the cost with the previous period's;\nthey are empty when either cost is missing or the currencies differ",
+                    "type": "string",
+                    "example": "3.00"
+                },
+                "change_percent": {
+                    "type": "string",
+                    "example": "8.3"
+                },
+                "cost": {
+                    "type": "string",
+                    "example": "39.00"
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "ends_on": {
+                    "type": "string",
+                    "example": "2026-12-31"
+                },
+                "starts_on": {
+                    "type": "string",
+                    "example": "2026-01-01"
+                },
+                "subscription_id": {
+                    "type": "integer",
+                    "example": 7
+                },
+                "vendor": {
+                    "type": "string",
+                    "example": "EBSCO"
+                }
+            }
+        },
+        "serials.Issue": {
+            "type": "object",
+            "properties": {
+                "claim_count": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "copies_received": {
+                    "description": "CopiesReceived counts the copies checked in",
+                    "type": "integer",
+                    "example": 1
+                },
+                "expected_on": {
+                    "type": "string",
+                    "example": "2026-01-05"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 120
+                },
+                "last_claimed_on": {
+                    "type": "string",
+                    "example": "2026-02-10"
+                },
+                "note": {
+                    "type": "string",
+                    "example": "Special issue"
+                },
+                "number": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "received_on": {
+                    "type": "string",
+                    "example": "2026-01-07"
+                },
+                "sequence": {
+                    "description": "Sequence is the issue's place in the prediction, from 0; it is\nempty for issues checked in without being predicted",
+                    "type": "integer",
+                    "example": 0
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "expected",
+                        "received",
+                        "claimed"
+                    ],
+                    "example": "expected"
+                },
+                "subscription_id": {
+                    "type": "integer",
+                    "example": 7
+                },
+                "volume": {
+                    "type": "integer",
+                    "example": 249
+                }
+            }
+        },
+        "serials.PredictResponse": {
+            "type": "object",
+            "properties": {
+                "added": {
+                    "description": "Added counts the issues the prediction added",
+                    "type": "integer",
+                    "example": 12
+                },
+                "issues": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/serials.Issue"
+                    }
+                }
+            }
+        },
+        "serials.Renewal": {
+            "type": "object",
+            "properties": {
+                "claim_after_days": {
+                    "description": "ClaimAfterDays is how long after its expected date an issue may be\nclaimed, and how long to wait between claims",
+                    "type": "integer",
+                    "example": 30
+                },
+                "copies": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "cost": {
+                    "description": "Cost is what the period cost, a decimal amount in Currency",
+                    "type": "string",
+                    "example": "39.00"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "currency": {
+                    "description": "ISO 4217",
+                    "type": "string",
+                    "example": "USD"
+                },
+                "days_left": {
+                    "description": "DaysLeft counts the days until the subscription ends",
+                    "type": "integer",
+                    "example": 28
+                },
+                "ends_on": {
+                    "type": "string",
+                    "example": "2026-12-31"
+                },
+                "first_issue_on": {
+                    "description": "FirstIssueOn is when the first issue is expected",
+                    "type": "string",
+                    "example": "2026-01-05"
+                },
+                "first_number": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "first_volume": {
+                    "description": "FirstVolume and FirstNumber number the first issue of the period",
+                    "type": "integer",
+                    "example": 249
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 7
+                },
+                "issn": {
+                    "type": "string",
+                    "example": "00279358"
+                },
+                "issues_per_volume": {
+                    "description": "IssuesPerVolume restarts numbering at 1 in a new volume after this many\nissues; 0 numbers issues straight through one volume",
+                    "type": "integer",
+                    "example": 12
+                },
+                "reminded_at": {
+                    "type": "string"
+                },
+                "reminded_days_before": {
+                    "description": "RemindedDaysBefore is the notice period of the latest reminder sent,\nand RemindedAt when it was sent; both are empty before the first",
+                    "type": "integer",
+                    "example": 30
+                },
+                "serial_id": {
+                    "type": "integer",
+                    "example": 4
+                },
+                "serial_title": {
+                    "type": "string",
+                    "example": "National Geographic"
+                },
+                "starts_on": {
+                    "type": "string",
+                    "example": "2026-01-01"
+                },
+                "vendor": {
+                    "type": "string",
+                    "example": "EBSCO"
+                }
+            }
+        },
+        "serials.Serial": {
+            "type": "object",
+            "properties": {
+                "frequency": {
+                    "description": "Frequency is how often issues are published, which drives prediction",
+                    "type": "string",
+                    "enum": [
+                        "weekly",
+                        "biweekly",
+                        "semimonthly",
+                        "monthly",
+                        "bimonthly",
+                        "quarterly",
+                        "semiannual",
+                        "annual"
+                    ],
+                    "example": "monthly"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 4
+                },
+                "issn": {
+                    "type": "string",
+                    "example": "00279358"
+                },
+                "publisher": {
+                    "type": "string",
+                    "example": "National Geographic Society"
+                },
+                "title": {
+                    "type": "string",
+                    "example": "National Geographic"
+                }
+            }
+        },
+        "serials.SerialResponse": {
+            "type": "object",
+            "properties": {
+                "frequency": {
+                    "description": "Frequency is how often issues are published, which drives prediction",
+                    "type": "string",
+                    "enum": [
+                        "weekly",
+                        "biweekly",
+                        "semimonthly",
+                        "monthly",
+                        "bimonthly",
+                        "quarterly",
+                        "semiannual",
+                        "annual"
+                    ],
+                    "example": "monthly"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 4
+                },
+                "issn": {
+                    "type": "string",
+                    "example": "00279358"
+                },
+                "publisher": {
+                    "type": "string",
+                    "example": "National Geographic Society"
+                },
+                "subscriptions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/serials.Subscription"
+                    }
+                },
+                "title": {
+                    "type": "string",
+                    "example": "National Geographic"
+                }
+            }
+        },
+        "serials.Subscription": {
+            "type": "object",
+            "properties": {
+                "claim_after_days": {
+                    "description": "ClaimAfterDays is how long after its expected date an issue may be\nclaimed, and how long to wait between claims",
+                    "type": "integer",
+                    "example": 30
+                },
+                "copies": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "cost": {
+                    "description": "Cost is what the period cost, a decimal amount in Currency",
+                    "type": "string",
+                    "example": "39.00"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "currency": {
+                    "description": "ISO 4217",
+                    "type": "string",
+                    "example": "USD"
+                },
+                "ends_on": {
+                    "type": "string",
+                    "example": "2026-12-31"
+                },
+                "first_issue_on": {
+                    "description": "FirstIssueOn is when the first issue is expected",
+                    "type": "string",
+                    "example": "2026-01-05"
+                },
+                "first_number": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "first_volume": {
+                    "description": "FirstVolume and FirstNumber number the first issue of the period",
+                    "type": "integer",
+                    "example": 249
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 7
+                },
+                "issues_per_volume": {
+                    "description": "IssuesPerVolume restarts numbering at 1 in a new volume after this many\nissues; 0 numbers issues straight through one volume",
+                    "type": "integer",
+                    "example": 12
+                },
+                "serial_id": {
+                    "type": "integer",
+                    "example": 4
+                },
+                "starts_on": {
+                    "type": "string",
+                    "example": "2026-01-01"
+                },
+                "vendor": {
+                    "type": "string",
+                    "example": "EBSCO"
+                }
+            }
+        },
+        "serials.UnexpectedIssue": {
+            "type": "object",
+            "properties": {
+                "copies": {
+                    "description": "Copies defaults to the subscription's copies",
+                    "type": "integer",
+                    "example": 1
+                },
+                "note": {
+                    "type": "string",
+                    "example": ""
+                },
+                "number": {
+                    "type": "integer",
+                    "example": 13
+                },
+                "received_on": {
+                    "description": "ReceivedOn defaults to today",
+                    "type": "string",
+                    "example": "2026-01-07"
+                },
+                "volume": {
+                    "type": "integer",
+                    "example": 249
+                }
+            }
+        },
+        "series.Series": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "example": "Tolkien's epic in three volumes"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "title": {
+                    "type": "string",
+                    "example": "The Lord of the Rings"
+                }
+            }
+        },
+        "series.SeriesResponse": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "example": "Tolkien's epic in three volumes"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "title": {
+                    "type": "string",
+                    "example": "The Lord of the Rings"
+                },
+                "volumes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/book.Book"
+                    }
+                }
+            }
+        },
+        "shelfmap.Location": {
+            "type": "object",
+            "properties": {
+                "aisle": {
+                    "type": "string",
+                    "example": "12"
+                },
+                "branch": {
+                    "type": "string",
+                    "example": "Central"
+                },
+                "call_number": {
+                    "type": "string",
+                    "example": "813.52 FIT"
+                },
+                "collection": {
+                    "type": "string",
+                    "example": "Adult Fiction"
+                },
+                "floor": {
+                    "type": "string",
+                    "example": "2"
+                },
+                "range_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "side": {
+                    "type": "string",
+                    "enum": [
+                        "left",
+                        "right"
+                    ],
+                    "example": "left"
+                },
+                "unit": {
+                    "type": "string",
+                    "example": "2F-A12"
+                }
+            }
+        },
+        "shelfmap.Range": {
+            "type": "object",
+            "properties": {
+                "aisle": {
+                    "type": "string",
+                    "example": "12"
+                },
+                "branch": {
+                    "type": "string",
+                    "example": "Central"
+                },
+                "collection": {
+                    "description": "Collection is empty for ranges that shelve every collection",
+                    "type": "string",
+                    "example": "Adult Fiction"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "first_call_number": {
+                    "type": "string",
+                    "example": "800"
+                },
+                "floor": {
+                    "type": "string",
+                    "example": "2"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "last_call_number": {
+                    "description": "LastCallNumber is inclusive, and call numbers starting with it are in\nthe range, so \"813\" covers \"813.52 FIT\"",
+                    "type": "string",
+                    "example": "813"
+                },
+                "side": {
+                    "type": "string",
+                    "enum": [
+                        "left",
+                        "right"
+                    ],
+                    "example": "left"
+                },
+                "unit": {
+                    "description": "Unit is the label on the shelving unit",
+                    "type": "string",
+                    "example": "2F-A12"
+                }
+            }
+        },
+        "stats.AuthorCount": {
+            "type": "object",
+            "properties": {
+                "author": {
+                    "type": "string",
+                    "example": "Terry Pratchett"
+                },
+                "book_count": {
+                    "type": "integer",
+                    "example": 41
+                }
+            }
+        },
+        "stats.BookStatsResponse": {
+            "type": "object",
+            "properties": {
+                "refreshed_at": {
+                    "type": "string"
+                },
+                "top_authors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/stats.AuthorCount"
+                    }
+                },
+                "total_authors": {
+                    "type": "integer",
+                    "example": 5120
+                },
+                "total_books": {
+                    "type": "integer",
+                    "example": 12034
+                }
             }
-        }
-    },
-    "definitions": {
-        "book.Book": {
+        },
+        "suggestions.AcceptRequest": {
+            "type": "object",
+            "properties": {
+                "book_id": {
+                    "type": "integer"
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "GBP"
+                },
+                "funding_source": {
+                    "type": "string",
+                    "example": "Book fund 2026"
+                },
+                "isbn": {
+                    "description": "ISBN is ordered by; it is required when the suggestion has none",
+                    "type": "string",
+                    "example": "9780593321201"
+                },
+                "price": {
+                    "type": "string",
+                    "example": "18.99"
+                },
+                "quantity": {
+                    "description": "Quantity defaults to 1",
+                    "type": "integer",
+                    "example": 2
+                },
+                "vendor": {
+                    "type": "string",
+                    "example": "Gardners Books"
+                },
+                "vendor_id": {
+                    "type": "string",
+                    "example": "5013546000008"
+                }
+            }
+        },
+        "suggestions.RejectRequest": {
+            "type": "object",
+            "properties": {
+                "reason": {
+                    "type": "string",
+                    "example": "Out of print"
+                }
+            }
+        },
+        "suggestions.Suggestion": {
             "type": "object",
             "properties": {
                 "author": {
                     "type": "string",
-                    "example": "F. Scott Fitzgerald"
+                    "example": "Gabrielle Zevin"
+                },
+                "book_id": {
+                    "description": "BookID and OrderID are the catalog record and the purchase order of\nan accepted suggestion. A matched suggestion has the catalog record it\nmatched, the open order the record is on if any, and HoldID, the hold\nplaced for the member on it unless they couldn't have one.",
+                    "type": "integer",
+                    "example": 230
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "decided_at": {
+                    "type": "string"
+                },
+                "hold_id": {
+                    "type": "integer",
+                    "example": 88
                 },
                 "id": {
                     "type": "integer",
-                    "example": 1
+                    "example": 15
                 },
                 "isbn": {
                     "type": "string",
-                    "example": "9780743273565"
+                    "example": "9780593321201"
+                },
+                "member_id": {
+                    "type": "string",
+                    "example": "m-1001"
+                },
+                "note": {
+                    "description": "Note is the member's reason for suggesting the title",
+                    "type": "string",
+                    "example": "My book club is reading it in March."
+                },
+                "order_id": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "reason": {
+                    "description": "Reason explains a rejection to the member",
+                    "type": "string",
+                    "example": "Out of print"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "pending",
+                        "accepted",
+                        "rejected",
+                        "matched"
+                    ],
+                    "example": "pending"
                 },
                 "title": {
                     "type": "string",
-                    "example": "The Great Gatsby"
+                    "example": "Tomorrow, and Tomorrow, and Tomorrow"
                 }
             }
         },
-        "book.ErrorResponse": {
+        "suggestions.SuggestionRequest": {
             "type": "object",
             "properties": {
-                "error": {
-                    "type": "string"
+                "author": {
+                    "type": "string",
+                    "example": "Gabrielle Zevin"
+                },
+                "isbn": {
+                    "type": "string",
+                    "example": "9780593321201"
+                },
+                "note": {
+                    "type": "string",
+                    "example": "My book club is reading it in March."
+                },
+                "pickup_branch": {
+                    "description": "PickupBranch is where to pick up the hold placed if the title is\nalready in the catalog",
+                    "type": "string",
+                    "example": "Central"
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Tomorrow, and Tomorrow, and Tomorrow"
                 }
             }
         },
-        "book.PaginationRequest": {
+        "usage.DailyUsage": {
             "type": "object",
             "properties": {
-                "end_date": {
-                    "type": "string"
+                "client_errors": {
+                    "type": "integer",
+                    "example": 312
                 },
-                "page": {
-                    "type": "integer"
+                "day": {
+                    "type": "string",
+                    "example": "2024-05-01"
                 },
-                "page_size": {
-                    "type": "integer"
+                "error_rate": {
+                    "description": "ErrorRate is (client_errors + server_errors) / requests",
+                    "type": "number",
+                    "example": 0.0207
                 },
-                "search": {
-                    "type": "string"
+                "requests": {
+                    "type": "integer",
+                    "example": 15230
                 },
-                "sort": {
-                    "$ref": "#/definitions/book.Sort"
+                "server_errors": {
+                    "type": "integer",
+                    "example": 4
+                }
+            }
+        },
+        "usage.EndpointUsage": {
+            "type": "object",
+            "properties": {
+                "client_errors": {
+                    "type": "integer",
+                    "example": 312
                 },
-                "start_date": {
-                    "type": "string"
+                "endpoint": {
+                    "type": "string",
+                    "example": "POST /api/v1/books/list"
+                },
+                "error_rate": {
+                    "description": "ErrorRate is (client_errors + server_errors) / requests",
+                    "type": "number",
+                    "example": 0.0207
+                },
+                "requests": {
+                    "type": "integer",
+                    "example": 15230
+                },
+                "server_errors": {
+                    "type": "integer",
+                    "example": 4
                 }
             }
         },
-        "book.PaginationResponse": {
+        "usage.Report": {
             "type": "object",
             "properties": {
-                "data": {},
-                "page_count": {
-                    "type": "integer"
+                "api_key_id": {
+                    "type": "string",
+                    "example": "catalog-kiosk"
                 },
-                "total_count": {
-                    "type": "integer"
+                "daily": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/usage.DailyUsage"
+                    }
+                },
+                "from": {
+                    "type": "string",
+                    "example": "2024-04-02"
+                },
+                "to": {
+                    "type": "string",
+                    "example": "2024-05-01"
+                },
+                "top_endpoints": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/usage.EndpointUsage"
+                    }
+                },
+                "totals": {
+                    "$ref": "#/definitions/usage.Totals"
                 }
             }
         },
-        "book.Sort": {
+        "usage.Totals": {
             "type": "object",
             "properties": {
-                "field": {
-                    "type": "string"
+                "client_errors": {
+                    "type": "integer",
+                    "example": 312
                 },
-                "order": {
-                    "description": "\"asc\" or \"desc\"",
-                    "type": "string"
+                "error_rate": {
+                    "description": "ErrorRate is (client_errors + server_errors) / requests",
+                    "type": "number",
+                    "example": 0.0207
+                },
+                "requests": {
+                    "type": "integer",
+                    "example": 15230
+                },
+                "server_errors": {
+                    "type": "integer",
+                    "example": 4
                 }
             }
         },
-        "book.StatusResponse": {
+        "weeding.Candidate": {
             "type": "object",
             "properties": {
-                "message": {
-                    "type": "string"
+                "acquired_on": {
+                    "type": "string",
+                    "example": "2012-05-02"
                 },
-                "status": {
+                "acquisition_id": {
+                    "type": "integer",
+                    "example": 41
+                },
+                "author": {
+                    "type": "string",
+                    "example": "F. Scott Fitzgerald"
+                },
+                "book_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "branch": {
+                    "type": "string",
+                    "example": "Central"
+                },
+                "checkouts": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "decision": {
+                    "type": "string",
+                    "enum": [
+                        "withdraw",
+                        "keep"
+                    ],
+                    "example": "withdraw"
+                },
+                "format": {
+                    "type": "string",
+                    "example": "paperback"
+                },
+                "isbn": {
+                    "type": "string",
+                    "example": "9780743273565"
+                },
+                "last_checkout_on": {
+                    "type": "string",
+                    "example": "2019-11-20"
+                },
+                "note": {
+                    "type": "string",
+                    "example": "Only copy of a local author"
+                },
+                "price": {
+                    "type": "string",
+                    "example": "12.99"
+                },
+                "title": {
+                    "type": "string",
+                    "example": "The Great Gatsby"
+                }
+            }
+        },
+        "weeding.Criteria": {
+            "type": "object",
+            "properties": {
+                "branch": {
+                    "type": "string",
+                    "example": "Central"
+                },
+                "format": {
+                    "type": "string",
+                    "example": "paperback"
+                },
+                "idle_days": {
+                    "description": "IdleDays selects copies not checked out (or, if never, not acquired)\nin this many days",
+                    "type": "integer",
+                    "example": 730
+                },
+                "max_checkouts": {
+                    "description": "MaxCheckouts selects copies checked out at most this many times",
+                    "type": "integer",
+                    "example": 2
+                },
+                "min_age_years": {
+                    "description": "MinAgeYears selects copies acquired at least this many years ago",
+                    "type": "integer",
+                    "example": 10
+                }
+            }
+        },
+        "weeding.Decision": {
+            "type": "object",
+            "properties": {
+                "disposal": {
+                    "description": "Disposal is required to approve",
+                    "type": "string",
+                    "example": "Friends book sale"
+                }
+            }
+        },
+        "weeding.DisposalReport": {
+            "type": "object",
+            "properties": {
+                "approved_at": {
                     "type": "string"
                 },
-                "timestamp": {
+                "copies": {
+                    "type": "integer",
+                    "example": 57
+                },
+                "disposal": {
+                    "type": "string",
+                    "example": "Friends book sale"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/weeding.Candidate"
+                    }
+                },
+                "proposal_id": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "weeding.Proposal": {
+            "type": "object",
+            "properties": {
+                "candidate_count": {
+                    "type": "integer",
+                    "example": 64
+                },
+                "candidates": {
+                    "description": "Candidates are left out of proposal lists",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/weeding.Candidate"
+                    }
+                },
+                "created_at": {
                     "type": "string"
                 },
-                "version": {
+                "criteria": {
+                    "$ref": "#/definitions/weeding.Criteria"
+                },
+                "decided_at": {
                     "type": "string"
+                },
+                "disposal": {
+                    "description": "Disposal says what becomes of withdrawn copies, e.g. \"Friends book sale\"",
+                    "type": "string",
+                    "example": "Friends book sale"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "note": {
+                    "type": "string",
+                    "example": "Annual fiction weed"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "proposed",
+                        "approved",
+                        "rejected"
+                    ],
+                    "example": "proposed"
+                }
+            }
+        },
+        "weeding.Review": {
+            "type": "object",
+            "properties": {
+                "decision": {
+                    "type": "string",
+                    "enum": [
+                        "withdraw",
+                        "keep"
+                    ],
+                    "example": "keep"
+                },
+                "note": {
+                    "type": "string",
+                    "example": "Only copy of a local author"
                 }
             }
         }
+    },
+    "securityDefinitions": {
+        "AdminToken": {
+            "description": "Bearer token from admin.token in the configuration, as \"Bearer \u003ctoken\u003e\"",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
+        }
     }
 }`
 
@@ -0,0 +1,272 @@
+package acquisition
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"public_library/internal/book"
+	"public_library/internal/httperr"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	svc    *Service
+	logger *zap.Logger
+}
+
+func NewHandler(svc *Service, l *zap.Logger) *Handler {
+	return &Handler{svc: svc, logger: l}
+}
+
+// POST /books/{id}/acquisitions
+
+// RecordAcquisition godoc
+// @Summary Record the purchase of a copy
+// @Description Stores the price, currency, funding source, holding branch and item barcode of one purchased copy of the book. acquired_on defaults to today; replacement_cost, used by the insurance valuation, defaults to the price. A barcode already on another copy is refused with 409.
+// @Tags acquisitions
+// @Accept json
+// @Produce json
+// @Param id path int true "Book ID"
+// @Param acquisition body Acquisition true "Price, currency and funding source"
+// @Success 201 {object} Acquisition
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /books/{id}/acquisitions [post]
+func (h *Handler) RecordAcquisition(w http.ResponseWriter, r *http.Request) {
+	bookID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "invalid book ID", http.StatusBadRequest)
+		return
+	}
+	var a Acquisition
+	if err := json.NewDecoder(r.Body).Decode(&a); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	a.BookID = bookID
+	if err := h.svc.Record(r.Context(), &a); err != nil {
+		h.writeError(w, "failed to record acquisition", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(a)
+}
+
+// GET /books/{id}/acquisitions
+
+// ListAcquisitions godoc
+// @Summary List the purchased copies of a book
+// @Tags acquisitions
+// @Produce json
+// @Param id path int true "Book ID"
+// @Success 200 {array} Acquisition
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /books/{id}/acquisitions [get]
+func (h *Handler) ListAcquisitions(w http.ResponseWriter, r *http.Request) {
+	bookID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "invalid book ID", http.StatusBadRequest)
+		return
+	}
+	acquisitions, err := h.svc.ListByBook(r.Context(), bookID)
+	if err != nil {
+		h.writeError(w, "failed to list acquisitions", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(acquisitions)
+}
+
+// PUT /acquisitions/{id}
+
+// UpdateAcquisition godoc
+// @Summary Update an acquisition record
+// @Description Replaces the price, currency, funding source, purchase date, branch, barcode and replacement cost of a copy; the book stays the same.
+// @Tags acquisitions
+// @Accept json
+// @Produce json
+// @Param id path int true "Acquisition ID"
+// @Param acquisition body Acquisition true "Acquisition fields"
+// @Success 200 {object} Acquisition
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /acquisitions/{id} [put]
+func (h *Handler) UpdateAcquisition(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "invalid acquisition ID", http.StatusBadRequest)
+		return
+	}
+	var a Acquisition
+	if err := json.NewDecoder(r.Body).Decode(&a); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	a.ID = id
+	if err := h.svc.Update(r.Context(), &a); err != nil {
+		h.writeError(w, "failed to update acquisition", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(a)
+}
+
+// POST /acquisitions/{id}/checkouts
+
+// CheckoutRequest is the optional body of RecordCheckout
+type CheckoutRequest struct {
+	// CheckedOutOn defaults to today
+	CheckedOutOn string `json:"checked_out_on" example:"2026-09-30"`
+}
+
+// RecordCheckout godoc
+// @Summary Count a checkout of a copy
+// @Description Adds one to the copy's checkout count and updates its last checkout date. Withdrawn copies and copies out for repair don't circulate.
+// @Tags acquisitions
+// @Accept json
+// @Produce json
+// @Param id path int true "Acquisition ID"
+// @Param checkout body CheckoutRequest false "Checkout date"
+// @Success 200 {object} Acquisition
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /acquisitions/{id}/checkouts [post]
+func (h *Handler) RecordCheckout(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "invalid acquisition ID", http.StatusBadRequest)
+		return
+	}
+	var req CheckoutRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	a, err := h.svc.RecordCheckout(r.Context(), id, req.CheckedOutOn)
+	if err != nil {
+		h.writeError(w, "failed to record checkout", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(a)
+}
+
+// DELETE /acquisitions/{id}
+
+// DeleteAcquisition godoc
+// @Summary Delete an acquisition record
+// @Description A copy that has been lent can't be deleted; withdraw it instead.
+// @Tags acquisitions
+// @Param id path int true "Acquisition ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /acquisitions/{id} [delete]
+func (h *Handler) DeleteAcquisition(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "invalid acquisition ID", http.StatusBadRequest)
+		return
+	}
+	if err := h.svc.Delete(r.Context(), id); err != nil {
+		h.writeError(w, "failed to delete acquisition", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GET /acquisitions/report?from=2026-01-01&to=2026-12-31
+
+// GetAcquisitionReport godoc
+// @Summary Report acquisition spending by funding source
+// @Description Totals the copies bought in a period by funding source. Amounts are summed per currency and converted to the report currency with the configured exchange rates; currencies without a rate are listed in missing_rates and left out of the converted totals.
+// @Tags acquisitions
+// @Produce json
+// @Param from query string false "First purchase date, YYYY-MM-DD"
+// @Param to query string false "Last purchase date, YYYY-MM-DD"
+// @Param currency query string false "Report currency; defaults to acquisitions.currency"
+// @Success 200 {object} Report
+// @Failure 400 {object} map[string]string
+// @Router /acquisitions/report [get]
+func (h *Handler) GetAcquisitionReport(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	report, err := h.svc.Report(r.Context(), ReportRequest{From: q.Get("from"), To: q.Get("to"), Currency: q.Get("currency")})
+	if err != nil {
+		h.writeError(w, "failed to build acquisition report", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// GET /acquisitions/valuation?format=pdf
+
+// GetValuation godoc
+// @Summary Report the replacement value of the collection
+// @Description Totals the replacement cost of every acquired copy, or its price where no replacement cost is recorded, by branch and by format within each branch, for the insurance renewal. Amounts are converted to the report currency like the acquisition report. format=csv or format=pdf downloads the report as a file.
+// @Tags acquisitions
+// @Produce json
+// @Produce text/csv
+// @Produce application/pdf
+// @Param currency query string false "Report currency; defaults to acquisitions.currency"
+// @Param format query string false "Output format" Enums(json, csv, pdf) default(json)
+// @Success 200 {object} Valuation
+// @Failure 400 {object} map[string]string
+// @Router /acquisitions/valuation [get]
+func (h *Handler) GetValuation(w http.ResponseWriter, r *http.Request) {
+	format := strings.ToLower(r.URL.Query().Get("format"))
+	if format != "" && format != "json" && format != "csv" && format != "pdf" {
+		http.Error(w, "format must be json, csv or pdf", http.StatusBadRequest)
+		return
+	}
+	valuation, err := h.svc.Valuation(r.Context(), r.URL.Query().Get("currency"))
+	if err != nil {
+		h.writeError(w, "failed to build valuation", err)
+		return
+	}
+
+	filename := "valuation-" + valuation.GeneratedAt.Format(time.DateOnly)
+	switch format {
+	case "csv":
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.csv"`, filename))
+		if err := writeValuationCSV(w, valuation); err != nil {
+			h.logger.Warn("valuation CSV interrupted", zap.Error(err))
+		}
+	case "pdf":
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.pdf"`, filename))
+		w.Write(valuationPDF(valuation))
+	default:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(valuation)
+	}
+}
+
+func (h *Handler) writeError(w http.ResponseWriter, msg string, err error) {
+	switch {
+	case errors.Is(err, ErrInvalid):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, ErrNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, ErrWithdrawn), errors.Is(err, ErrInRepair), errors.Is(err, ErrHasLoans):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, book.ErrNotFound):
+		http.Error(w, "book not found", http.StatusNotFound)
+	default:
+		httperr.Write(w, h.logger, msg, err)
+	}
+}
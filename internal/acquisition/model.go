@@ -0,0 +1,120 @@
+package acquisition
+
+import "time"
+
+// Acquisition records the purchase of one copy of a book
+type Acquisition struct {
+	ID     int `json:"id" example:"1"`
+	BookID int `json:"book_id" example:"1"`
+	// Price is a decimal amount in Currency, with up to four decimals
+	Price    string `json:"price" example:"18.99"`
+	Currency string `json:"currency" example:"USD"` // ISO 4217
+	// FundingSource is the budget line or grant that paid for the copy
+	FundingSource string `json:"funding_source" example:"Friends of the Library"`
+	// AcquiredOn is the purchase date; it defaults to today
+	AcquiredOn string `json:"acquired_on" example:"2026-03-14"`
+	// Branch is where the copy is held
+	Branch string `json:"branch" example:"Central"`
+	// Barcode is the item barcode on the copy, unique across copies
+	Barcode string `json:"barcode,omitempty" example:"31234000123456"`
+	// ReplacementCost is what replacing the copy would cost today, in
+	// Currency; insurance valuations use Price when it is not set
+	ReplacementCost string `json:"replacement_cost,omitempty" example:"24.99"`
+	// Checkouts counts the copy's loans; LastCheckoutOn is the latest
+	Checkouts      int    `json:"checkouts" example:"12"`
+	LastCheckoutOn string `json:"last_checkout_on,omitempty" example:"2026-09-30"`
+	// WithdrawnAt is set when the copy is weeded from the collection
+	WithdrawnAt *time.Time `json:"withdrawn_at,omitempty"`
+	// InRepair is set while the copy is out for repair or binding
+	InRepair  bool      `json:"in_repair" example:"false"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Amount is a sum of money in one currency
+type Amount struct {
+	Currency string `json:"currency" example:"EUR"`
+	Amount   string `json:"amount" example:"240.50"`
+}
+
+// Total sums the acquisitions of a group. Amounts are kept per currency;
+// Converted adds them up in the report currency.
+type Total struct {
+	Copies  int      `json:"copies" example:"14"`
+	Amounts []Amount `json:"amounts"`
+	// Converted is empty when no amount could be converted
+	Converted string `json:"converted,omitempty" example:"262.87"`
+}
+
+// FundTotal is the total spent from one funding source
+type FundTotal struct {
+	FundingSource string `json:"funding_source" example:"Friends of the Library"`
+	Total
+}
+
+// Report totals acquisitions by funding source
+type Report struct {
+	From     string      `json:"from,omitempty" example:"2026-01-01"`
+	To       string      `json:"to,omitempty" example:"2026-12-31"`
+	Currency string      `json:"currency" example:"USD"`
+	Funds    []FundTotal `json:"funds"`
+	Total    Total       `json:"total"`
+	// RatesAsOf is when the exchange rates used were published or loaded
+	RatesAsOf *time.Time `json:"rates_as_of,omitempty"`
+	// MissingRates lists currencies with no exchange rate, whose amounts
+	// are left out of the converted totals
+	MissingRates []string `json:"missing_rates,omitempty" example:"CHF"`
+}
+
+// ReportRequest selects the acquisitions to report on, by purchase date
+type ReportRequest struct {
+	From string // inclusive, YYYY-MM-DD
+	To   string // inclusive, YYYY-MM-DD
+	// Currency overrides the configured report currency
+	Currency string
+}
+
+// Valuation totals the replacement cost of every acquired copy by branch
+// and format, for insurance
+type Valuation struct {
+	Currency string           `json:"currency" example:"USD"`
+	Branches []BranchTotal    `json:"branches"`
+	Groups   []ValuationGroup `json:"groups"`
+	Total    Total            `json:"total"`
+	// RatesAsOf is when the exchange rates used were published or loaded
+	RatesAsOf *time.Time `json:"rates_as_of,omitempty"`
+	// MissingRates lists currencies with no exchange rate, whose amounts
+	// are left out of the converted totals
+	MissingRates []string  `json:"missing_rates,omitempty" example:"CHF"`
+	GeneratedAt  time.Time `json:"generated_at"`
+}
+
+// BranchTotal is the replacement cost of the copies held at one branch
+type BranchTotal struct {
+	Branch string `json:"branch" example:"Central"`
+	Total
+}
+
+// ValuationGroup is the replacement cost of one format at one branch
+type ValuationGroup struct {
+	Branch string `json:"branch" example:"Central"`
+	Format string `json:"format" example:"hardcover"`
+	Total
+}
+
+// totalRow is one funding source and currency total read from the database
+type totalRow struct {
+	FundingSource string
+	Currency      string
+	Copies        int
+	Amount        string
+}
+
+// valuationRow is one branch, format and currency total read from the
+// database
+type valuationRow struct {
+	Branch   string
+	Format   string
+	Currency string
+	Copies   int
+	Amount   string
+}
@@ -0,0 +1,46 @@
+package acquisition
+
+import (
+	"math/big"
+	"regexp"
+	"strings"
+)
+
+var (
+	pricePattern    = regexp.MustCompile(`^\d{1,10}(\.\d{1,4})?$`)
+	currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+)
+
+// minorUnits are the decimal places of the currencies that don't use two
+var minorUnits = map[string]int{
+	"JPY": 0, "KRW": 0, "ISK": 0, "CLP": 0, "VND": 0, "UGX": 0,
+	"BHD": 3, "KWD": 3, "JOD": 3, "OMR": 3, "TND": 3, "LYD": 3, "IQD": 3,
+}
+
+// NormalizeCurrency upper-cases an ISO 4217 code and reports whether it is
+// well-formed
+func NormalizeCurrency(currency string) (string, bool) {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	return currency, currencyPattern.MatchString(currency)
+}
+
+// ValidPrice reports whether price is a non-negative decimal amount with at
+// most four decimals, as acquisitions store
+func ValidPrice(price string) bool {
+	return pricePattern.MatchString(price)
+}
+
+// parseAmount parses a decimal amount as stored by the database
+func parseAmount(s string) (*big.Rat, bool) {
+	return new(big.Rat).SetString(s)
+}
+
+// FormatAmount renders r with the decimal places of currency, rounding
+// halves away from zero
+func FormatAmount(r *big.Rat, currency string) string {
+	digits, ok := minorUnits[currency]
+	if !ok {
+		digits = 2
+	}
+	return r.FloatString(digits)
+}
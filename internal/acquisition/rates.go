@@ -0,0 +1,144 @@
+package acquisition
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"public_library/internal/config"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Rates are units of each currency per unit of Currency
+type Rates struct {
+	Currency string
+	Rates    map[string]float64
+	AsOf     time.Time
+}
+
+// rate returns the units of currency per unit of r.Currency
+func (r Rates) rate(currency string) (*big.Rat, bool) {
+	if currency == r.Currency {
+		return big.NewRat(1, 1), true
+	}
+	rate, ok := r.Rates[currency]
+	if !ok || rate <= 0 {
+		return nil, false
+	}
+	return new(big.Rat).SetFloat64(rate), true
+}
+
+// convert converts amount from one currency to another through r.Currency
+func (r Rates) convert(amount *big.Rat, from, to string) (*big.Rat, bool) {
+	if from == to {
+		return amount, true
+	}
+	fromRate, ok := r.rate(from)
+	if !ok {
+		return nil, false
+	}
+	toRate, ok := r.rate(to)
+	if !ok {
+		return nil, false
+	}
+	converted := new(big.Rat).Quo(amount, fromRate)
+	return converted.Mul(converted, toRate), true
+}
+
+// RateSource supplies the exchange rates acquisition reports convert with
+type RateSource interface {
+	Rates(ctx context.Context) (Rates, error)
+}
+
+// NewRateSource returns the rate source named by cfg.Rates.Source
+func NewRateSource(cfg config.AcquisitionsConfig, logger *zap.Logger) RateSource {
+	if cfg.Rates.Source == config.RatesHTTP {
+		return &httpRates{
+			url:      cfg.Rates.URL,
+			currency: cfg.Currency,
+			refresh:  cfg.Rates.RefreshInterval,
+			client:   &http.Client{Timeout: cfg.Rates.Timeout},
+			logger:   logger,
+		}
+	}
+	return staticRates{rates: Rates{Currency: cfg.Currency, Rates: cfg.Rates.Static, AsOf: time.Now()}}
+}
+
+// staticRates are the rates listed in the configuration
+type staticRates struct {
+	rates Rates
+}
+
+func (s staticRates) Rates(context.Context) (Rates, error) {
+	return s.rates, nil
+}
+
+// httpRates fetches rates from a Frankfurter-style JSON endpoint and keeps
+// them for the refresh interval. If a refetch fails the previous rates are
+// used until one succeeds.
+type httpRates struct {
+	url      string
+	currency string
+	refresh  time.Duration
+	client   *http.Client
+	logger   *zap.Logger
+
+	mu        sync.Mutex
+	current   Rates
+	fetchedAt time.Time
+}
+
+func (h *httpRates) Rates(ctx context.Context) (Rates, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !h.fetchedAt.IsZero() && time.Since(h.fetchedAt) < h.refresh {
+		return h.current, nil
+	}
+	rates, err := h.fetch(ctx)
+	if err != nil {
+		if h.fetchedAt.IsZero() {
+			return Rates{}, err
+		}
+		h.logger.Warn("exchange rate refresh failed; using previous rates", zap.Error(err))
+		return h.current, nil
+	}
+	h.current, h.fetchedAt = rates, time.Now()
+	return rates, nil
+}
+
+func (h *httpRates) fetch(ctx context.Context) (Rates, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, h.url, nil)
+	if err != nil {
+		return Rates{}, err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return Rates{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return Rates{}, fmt.Errorf("exchange rate source answered %s", resp.Status)
+	}
+
+	var body struct {
+		Base  string             `json:"base"`
+		Date  string             `json:"date"`
+		Rates map[string]float64 `json:"rates"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return Rates{}, fmt.Errorf("decoding exchange rates: %w", err)
+	}
+	if body.Base != "" && body.Base != h.currency {
+		return Rates{}, fmt.Errorf("exchange rates are for %s, not %s", body.Base, h.currency)
+	}
+	asOf := time.Now()
+	if date, err := time.Parse(time.DateOnly, body.Date); err == nil {
+		asOf = date
+	}
+	return Rates{Currency: h.currency, Rates: body.Rates, AsOf: asOf}, nil
+}
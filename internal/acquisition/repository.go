@@ -0,0 +1,250 @@
+package acquisition
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"public_library/internal/db"
+	"public_library/utils"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrNotFound is returned when no acquisition has the requested id
+	ErrNotFound = errors.New("acquisition not found")
+	// ErrWithdrawn is returned for circulation of a withdrawn copy
+	ErrWithdrawn = errors.New("copy has been withdrawn")
+	// ErrInRepair is returned for circulation of a copy out for repair
+	ErrInRepair = errors.New("copy is out for repair")
+	// ErrHasLoans is returned for deleting a copy that has been lent; its
+	// loans are circulation history
+	ErrHasLoans = errors.New("copy has loans; withdraw it instead")
+)
+
+type Repository struct {
+	db *db.DB
+}
+
+func NewRepository(conn *db.DB) *Repository {
+	return &Repository{db: conn}
+}
+
+// Create stores a, filling in its id and creation time
+func (r *Repository) Create(ctx context.Context, a *Acquisition) error {
+	log.Println("<--------Create acquisition starts-------->")
+	defer log.Println("<--------Create acquisition ends-------->")
+
+	query := fmt.Sprintf(`
+		INSERT INTO %s (book_id, price, currency, funding_source, acquired_on, branch, replacement_cost, barcode)
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, '')::numeric, NULLIF($8, ''))
+		RETURNING id, created_at
+	`, utils.AcquisitionsTable)
+	err := r.db.QueryRowContext(ctx, query, a.BookID, a.Price, a.Currency, a.FundingSource, a.AcquiredOn, a.Branch, a.ReplacementCost,
+		a.Barcode).Scan(&a.ID, &a.CreatedAt)
+	if err != nil {
+		log.Printf("Failed to create acquisition of book id=%d: %v", a.BookID, err)
+		return err
+	}
+	return nil
+}
+
+// inRepairSQL is true for acquisitions out for repair
+var inRepairSQL = fmt.Sprintf(`EXISTS (
+	SELECT 1 FROM %[1]s r WHERE r.acquisition_id = %[2]s.id AND r.returned_on IS NULL
+)`, utils.RepairsTable, utils.AcquisitionsTable)
+
+// acquisitionColumns are the columns scanAcquisition reads
+var acquisitionColumns = `id, book_id, price::text, currency, funding_source, acquired_on, branch,
+	COALESCE(barcode, ''), COALESCE(replacement_cost::text, ''), checkouts, last_checkout_on, withdrawn_at, ` + inRepairSQL + `, created_at`
+
+func scanAcquisition(row interface{ Scan(...interface{}) error }) (Acquisition, error) {
+	var a Acquisition
+	var acquiredOn time.Time
+	var lastCheckout sql.NullTime
+	err := row.Scan(&a.ID, &a.BookID, &a.Price, &a.Currency, &a.FundingSource, &acquiredOn,
+		&a.Branch, &a.Barcode, &a.ReplacementCost, &a.Checkouts, &lastCheckout, &a.WithdrawnAt, &a.InRepair, &a.CreatedAt)
+	if err != nil {
+		return a, err
+	}
+	a.AcquiredOn = acquiredOn.Format(time.DateOnly)
+	if lastCheckout.Valid {
+		a.LastCheckoutOn = lastCheckout.Time.Format(time.DateOnly)
+	}
+	return a, nil
+}
+
+// Update replaces the recorded fields of the acquisition with a.ID and
+// reloads the rest; circulation and withdrawal are left as they are
+func (r *Repository) Update(ctx context.Context, a *Acquisition) error {
+	log.Println("<--------Update acquisition starts-------->")
+	defer log.Println("<--------Update acquisition ends-------->")
+
+	query := fmt.Sprintf(`
+		UPDATE %s
+		SET price = $2, currency = $3, funding_source = $4, acquired_on = $5, branch = $6,
+			replacement_cost = NULLIF($7, '')::numeric, barcode = NULLIF($8, '')
+		WHERE id = $1
+		RETURNING %s
+	`, utils.AcquisitionsTable, acquisitionColumns)
+	updated, err := scanAcquisition(r.db.QueryRowContext(ctx, query, a.ID, a.Price, a.Currency, a.FundingSource,
+		a.AcquiredOn, a.Branch, a.ReplacementCost, a.Barcode))
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	if err != nil {
+		log.Printf("Failed to update acquisition id=%d: %v", a.ID, err)
+		return err
+	}
+	*a = updated
+	return nil
+}
+
+// RecordCheckout counts a checkout of the copy on the given date. Copies
+// withdrawn or out for repair don't circulate.
+func (r *Repository) RecordCheckout(ctx context.Context, id int, on string) (*Acquisition, error) {
+	query := fmt.Sprintf(`
+		UPDATE %s
+		SET checkouts = checkouts + 1, last_checkout_on = GREATEST(last_checkout_on, $2::date)
+		WHERE id = $1 AND withdrawn_at IS NULL AND NOT %s
+		RETURNING %s
+	`, utils.AcquisitionsTable, inRepairSQL, acquisitionColumns)
+	a, err := scanAcquisition(r.db.QueryRowContext(ctx, query, id, on))
+	if errors.Is(err, sql.ErrNoRows) {
+		current, err := scanAcquisition(r.db.QueryRowContext(ctx,
+			fmt.Sprintf(`SELECT %s FROM %s WHERE id = $1`, acquisitionColumns, utils.AcquisitionsTable), id))
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrNotFound
+		case err != nil:
+			return nil, err
+		case current.WithdrawnAt != nil:
+			return nil, ErrWithdrawn
+		}
+		return nil, ErrInRepair
+	}
+	if err != nil {
+		log.Printf("Failed to record a checkout of acquisition id=%d: %v", id, err)
+		return nil, err
+	}
+	return &a, nil
+}
+
+// ListByBook returns the acquisitions of a book, most recent first
+func (r *Repository) ListByBook(ctx context.Context, bookID int) ([]Acquisition, error) {
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM %s
+		WHERE book_id = $1
+		ORDER BY acquired_on DESC, id DESC
+	`, acquisitionColumns, utils.AcquisitionsTable)
+	rows, err := r.db.QueryContext(ctx, query, bookID)
+	if err != nil {
+		log.Printf("Failed to list acquisitions of book id=%d: %v", bookID, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	acquisitions := []Acquisition{}
+	for rows.Next() {
+		a, err := scanAcquisition(rows)
+		if err != nil {
+			return nil, err
+		}
+		acquisitions = append(acquisitions, a)
+	}
+	return acquisitions, rows.Err()
+}
+
+func (r *Repository) Delete(ctx context.Context, id int) error {
+	log.Println("<--------Delete acquisition starts-------->")
+	defer log.Println("<--------Delete acquisition ends-------->")
+
+	result, err := r.db.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE id = $1`, utils.AcquisitionsTable), id)
+	if err != nil {
+		if db.IsStillReferenced(err, "loans_acquisition_id_fkey") {
+			return ErrHasLoans
+		}
+		log.Printf("Failed to delete acquisition id=%d: %v", id, err)
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// totals sums acquisitions bought between from and to (either may be empty)
+// by funding source and currency. Amounts are summed in SQL, exactly.
+func (r *Repository) totals(ctx context.Context, from, to string) ([]totalRow, error) {
+	where := []string{"1=1"}
+	var args []interface{}
+	if from != "" {
+		args = append(args, from)
+		where = append(where, fmt.Sprintf("acquired_on >= $%d", len(args)))
+	}
+	if to != "" {
+		args = append(args, to)
+		where = append(where, fmt.Sprintf("acquired_on <= $%d", len(args)))
+	}
+	query := fmt.Sprintf(`
+		SELECT funding_source, currency, COUNT(*), SUM(price)::text
+		FROM %s
+		WHERE %s
+		GROUP BY funding_source, currency
+		ORDER BY funding_source, currency
+	`, utils.AcquisitionsTable, strings.Join(where, " AND "))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.Printf("Failed to total acquisitions: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var totals []totalRow
+	for rows.Next() {
+		var t totalRow
+		if err := rows.Scan(&t.FundingSource, &t.Currency, &t.Copies, &t.Amount); err != nil {
+			return nil, err
+		}
+		totals = append(totals, t)
+	}
+	return totals, rows.Err()
+}
+
+// valuation sums the replacement cost of every copy still held, or its price when no
+// replacement cost is recorded, by branch, book format and currency
+func (r *Repository) valuation(ctx context.Context) ([]valuationRow, error) {
+	query := fmt.Sprintf(`
+		SELECT a.branch, b.format, a.currency, COUNT(*), SUM(COALESCE(a.replacement_cost, a.price))::text
+		FROM %s a
+		JOIN %s b ON b.id = a.book_id
+		WHERE a.withdrawn_at IS NULL
+		GROUP BY a.branch, b.format, a.currency
+		ORDER BY a.branch, b.format, a.currency
+	`, utils.AcquisitionsTable, utils.BooksTable)
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		log.Printf("Failed to total acquisition replacement costs: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var totals []valuationRow
+	for rows.Next() {
+		var v valuationRow
+		if err := rows.Scan(&v.Branch, &v.Format, &v.Currency, &v.Copies, &v.Amount); err != nil {
+			return nil, err
+		}
+		totals = append(totals, v)
+	}
+	return totals, rows.Err()
+}
@@ -0,0 +1,306 @@
+// Package acquisition records what each copy of a book cost and who paid
+// for it, and totals the spending by funding source. Prices keep their
+// own currency; reports convert them with the configured rate source.
+package acquisition
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"public_library/internal/book"
+	"public_library/internal/config"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+)
+
+// ErrInvalid is wrapped by errors about the content of a request
+var ErrInvalid = errors.New("invalid acquisition")
+
+const (
+	maxFundingSourceLength = 200
+	maxBranchLength        = 200
+	maxBarcodeLength       = 64
+)
+
+type Service struct {
+	repo     *Repository
+	books    *book.Service
+	rates    RateSource
+	currency string
+	logger   *zap.Logger
+}
+
+func NewService(repo *Repository, books *book.Service, rates RateSource, cfg config.AcquisitionsConfig, l *zap.Logger) *Service {
+	return &Service{repo: repo, books: books, rates: rates, currency: cfg.Currency, logger: l}
+}
+
+// Record validates a and stores it as a purchased copy of its book
+func (s *Service) Record(ctx context.Context, a *Acquisition) error {
+	if err := a.validate(); err != nil {
+		return err
+	}
+	if _, err := s.books.Get(ctx, a.BookID); err != nil {
+		return err
+	}
+	return s.repo.Create(ctx, a)
+}
+
+// ListByBook returns the acquisitions of a book, most recent first
+func (s *Service) ListByBook(ctx context.Context, bookID int) ([]Acquisition, error) {
+	if _, err := s.books.Get(ctx, bookID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListByBook(ctx, bookID)
+}
+
+// Update validates a and replaces the acquisition with a.ID, e.g. to move a
+// copy to another branch or revise its replacement cost
+func (s *Service) Update(ctx context.Context, a *Acquisition) error {
+	if err := a.validate(); err != nil {
+		return err
+	}
+	return s.repo.Update(ctx, a)
+}
+
+// RecordCheckout counts a loan of the copy on the date on, or today. The
+// counts feed weeding proposals.
+func (s *Service) RecordCheckout(ctx context.Context, id int, on string) (*Acquisition, error) {
+	if on == "" {
+		on = time.Now().Format(time.DateOnly)
+	}
+	if _, err := time.Parse(time.DateOnly, on); err != nil {
+		return nil, fmt.Errorf("%w: checked_out_on must be a date (YYYY-MM-DD)", ErrInvalid)
+	}
+	return s.repo.RecordCheckout(ctx, id, on)
+}
+
+func (s *Service) Delete(ctx context.Context, id int) error {
+	return s.repo.Delete(ctx, id)
+}
+
+func (a *Acquisition) validate() error {
+	a.Price = strings.TrimSpace(a.Price)
+	if !ValidPrice(a.Price) {
+		return fmt.Errorf("%w: price must be a non-negative decimal amount with at most four decimals", ErrInvalid)
+	}
+	currency, ok := NormalizeCurrency(a.Currency)
+	if !ok {
+		return fmt.Errorf("%w: currency must be an ISO 4217 code such as USD", ErrInvalid)
+	}
+	a.Currency = currency
+	a.FundingSource = strings.TrimSpace(a.FundingSource)
+	if utf8.RuneCountInString(a.FundingSource) > maxFundingSourceLength {
+		return fmt.Errorf("%w: funding_source must be at most %d characters", ErrInvalid, maxFundingSourceLength)
+	}
+	a.Branch = strings.TrimSpace(a.Branch)
+	if utf8.RuneCountInString(a.Branch) > maxBranchLength {
+		return fmt.Errorf("%w: branch must be at most %d characters", ErrInvalid, maxBranchLength)
+	}
+	a.Barcode = strings.TrimSpace(a.Barcode)
+	if utf8.RuneCountInString(a.Barcode) > maxBarcodeLength {
+		return fmt.Errorf("%w: barcode must be at most %d characters", ErrInvalid, maxBarcodeLength)
+	}
+	a.ReplacementCost = strings.TrimSpace(a.ReplacementCost)
+	if a.ReplacementCost != "" && !ValidPrice(a.ReplacementCost) {
+		return fmt.Errorf("%w: replacement_cost must be a non-negative decimal amount with at most four decimals", ErrInvalid)
+	}
+	if a.AcquiredOn == "" {
+		a.AcquiredOn = time.Now().Format(time.DateOnly)
+	}
+	if _, err := time.Parse(time.DateOnly, a.AcquiredOn); err != nil {
+		return fmt.Errorf("%w: acquired_on must be a date (YYYY-MM-DD)", ErrInvalid)
+	}
+	return nil
+}
+
+// Report totals the acquisitions bought in the requested period by funding
+// source. Amounts are summed per currency and never added across currencies
+// except through an exchange rate; currencies without one are reported in
+// MissingRates. If no rates are available at all the report still lists the
+// per-currency amounts, without converted totals.
+func (s *Service) Report(ctx context.Context, req ReportRequest) (*Report, error) {
+	for name, date := range map[string]string{"from": req.From, "to": req.To} {
+		if _, err := time.Parse(time.DateOnly, date); date != "" && err != nil {
+			return nil, fmt.Errorf("%w: %s must be a date (YYYY-MM-DD)", ErrInvalid, name)
+		}
+	}
+	currency, err := s.reportCurrency(req.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.repo.totals(ctx, req.From, req.To)
+	if err != nil {
+		return nil, err
+	}
+	rates := s.loadRates(ctx)
+
+	report := &Report{From: req.From, To: req.To, Currency: currency, Funds: []FundTotal{}}
+	if rates.Currency != "" {
+		report.RatesAsOf = &rates.AsOf
+	}
+	missing := make(map[string]bool)
+	overall := newTally()
+	var fund *FundTotal
+	var fundTally *tally
+	for _, row := range rows {
+		if fund == nil || fund.FundingSource != row.FundingSource {
+			if fund != nil {
+				fund.Total = fundTally.total(currency)
+			}
+			report.Funds = append(report.Funds, FundTotal{FundingSource: row.FundingSource})
+			fund, fundTally = &report.Funds[len(report.Funds)-1], newTally()
+		}
+		amount, ok := parseAmount(row.Amount)
+		if !ok {
+			return nil, fmt.Errorf("unexpected amount %q in acquisitions of %s", row.Amount, row.Currency)
+		}
+		converted, ok := rates.convert(amount, row.Currency, currency)
+		if !ok {
+			missing[row.Currency] = true
+		}
+		fundTally.add(row.Currency, row.Copies, amount, converted)
+		overall.add(row.Currency, row.Copies, amount, converted)
+	}
+	if fund != nil {
+		fund.Total = fundTally.total(currency)
+	}
+	report.Total = overall.total(currency)
+	report.MissingRates = sortedKeys(missing)
+	return report, nil
+}
+
+// Valuation totals the replacement cost of every acquired copy by branch
+// and by format within each branch, converted to currency (or the
+// configured report currency) like Report
+func (s *Service) Valuation(ctx context.Context, currency string) (*Valuation, error) {
+	currency, err := s.reportCurrency(currency)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.repo.valuation(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rates := s.loadRates(ctx)
+
+	valuation := &Valuation{Currency: currency, Branches: []BranchTotal{}, Groups: []ValuationGroup{}, GeneratedAt: time.Now()}
+	if rates.Currency != "" {
+		valuation.RatesAsOf = &rates.AsOf
+	}
+	missing := make(map[string]bool)
+	overall := newTally()
+	var branchTally, groupTally *tally
+	// rows are ordered by branch then format, so each group and branch is
+	// finished when the next one starts
+	for i, row := range rows {
+		newBranch := i == 0 || row.Branch != rows[i-1].Branch
+		if newBranch || row.Format != rows[i-1].Format {
+			if groupTally != nil {
+				valuation.Groups[len(valuation.Groups)-1].Total = groupTally.total(currency)
+			}
+			valuation.Groups = append(valuation.Groups, ValuationGroup{Branch: row.Branch, Format: row.Format})
+			groupTally = newTally()
+		}
+		if newBranch {
+			if branchTally != nil {
+				valuation.Branches[len(valuation.Branches)-1].Total = branchTally.total(currency)
+			}
+			valuation.Branches = append(valuation.Branches, BranchTotal{Branch: row.Branch})
+			branchTally = newTally()
+		}
+		amount, ok := parseAmount(row.Amount)
+		if !ok {
+			return nil, fmt.Errorf("unexpected amount %q in replacement costs in %s", row.Amount, row.Currency)
+		}
+		converted, ok := rates.convert(amount, row.Currency, currency)
+		if !ok {
+			missing[row.Currency] = true
+		}
+		for _, t := range []*tally{groupTally, branchTally, overall} {
+			t.add(row.Currency, row.Copies, amount, converted)
+		}
+	}
+	if groupTally != nil {
+		valuation.Groups[len(valuation.Groups)-1].Total = groupTally.total(currency)
+		valuation.Branches[len(valuation.Branches)-1].Total = branchTally.total(currency)
+	}
+	valuation.Total = overall.total(currency)
+	valuation.MissingRates = sortedKeys(missing)
+	return valuation, nil
+}
+
+// reportCurrency validates a requested report currency, defaulting to the
+// configured one
+func (s *Service) reportCurrency(requested string) (string, error) {
+	if requested == "" {
+		return s.currency, nil
+	}
+	currency, ok := NormalizeCurrency(requested)
+	if !ok {
+		return "", fmt.Errorf("%w: currency must be an ISO 4217 code such as USD", ErrInvalid)
+	}
+	return currency, nil
+}
+
+// loadRates returns the current exchange rates, or no rates if the source
+// fails, in which case reports go out unconverted
+func (s *Service) loadRates(ctx context.Context) Rates {
+	rates, err := s.rates.Rates(ctx)
+	if err != nil {
+		s.logger.Warn("exchange rates unavailable; reporting unconverted totals", zap.Error(err))
+	}
+	return rates
+}
+
+func sortedKeys(set map[string]bool) []string {
+	var keys []string
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// tally accumulates a Total
+type tally struct {
+	copies     int
+	amounts    map[string]*big.Rat
+	converted  *big.Rat
+	conversion bool
+}
+
+func newTally() *tally {
+	return &tally{amounts: make(map[string]*big.Rat), converted: new(big.Rat)}
+}
+
+// add counts copies costing amount in currency; converted is nil when the
+// currency has no rate
+func (t *tally) add(currency string, copies int, amount, converted *big.Rat) {
+	t.copies += copies
+	if t.amounts[currency] == nil {
+		t.amounts[currency] = new(big.Rat)
+	}
+	t.amounts[currency].Add(t.amounts[currency], amount)
+	if converted != nil {
+		t.converted.Add(t.converted, converted)
+		t.conversion = true
+	}
+}
+
+func (t *tally) total(currency string) Total {
+	total := Total{Copies: t.copies, Amounts: []Amount{}}
+	for c, amount := range t.amounts {
+		total.Amounts = append(total.Amounts, Amount{Currency: c, Amount: FormatAmount(amount, c)})
+	}
+	sort.Slice(total.Amounts, func(i, j int) bool { return total.Amounts[i].Currency < total.Amounts[j].Currency })
+	if t.conversion {
+		total.Converted = FormatAmount(t.converted, currency)
+	}
+	return total
+}
@@ -0,0 +1,98 @@
+package acquisition
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"public_library/internal/pdf"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var valuationColumns = []string{"branch", "format", "copies", "replacement_cost", "currency", "amounts"}
+
+// writeValuationCSV writes one row per branch and format, a subtotal row
+// per branch with an empty format, and a total row with both empty
+func writeValuationCSV(w io.Writer, v *Valuation) error {
+	cw := csv.NewWriter(w)
+	cw.Write(valuationColumns)
+	record := func(branch, format string, t Total) {
+		cw.Write([]string{branch, format, strconv.Itoa(t.Copies), t.Converted, v.Currency, amountList(t.Amounts)})
+	}
+	for _, b := range v.Branches {
+		for _, g := range v.Groups {
+			if g.Branch == b.Branch {
+				record(displayBranch(g.Branch), displayFormat(g.Format), g.Total)
+			}
+		}
+		record(displayBranch(b.Branch), "", b.Total)
+	}
+	record("", "", v.Total)
+	cw.Flush()
+	return cw.Error()
+}
+
+// valuationPDF lays the valuation out as a printable table, one section per
+// branch
+func valuationPDF(v *Valuation) []byte {
+	doc := pdf.New("Collection valuation")
+	row := func(label string, t Total) string {
+		value := t.Converted
+		if value == "" {
+			value = "-"
+		}
+		return fmt.Sprintf("  %-38.38s %8d %18s  %s", label, t.Copies, value, amountList(t.Amounts))
+	}
+
+	doc.Line("COLLECTION REPLACEMENT VALUATION")
+	doc.Line("Generated " + v.GeneratedAt.Format("2 January 2006 15:04 MST"))
+	doc.Line("Values in " + v.Currency)
+	if v.RatesAsOf != nil {
+		doc.Line("Exchange rates as of " + v.RatesAsOf.Format(time.DateOnly))
+	}
+	doc.Line("")
+	doc.Line(fmt.Sprintf("  %-38s %8s %18s  %s", "Format", "Copies", "Replacement cost", "Paid in"))
+	doc.Line("  " + strings.Repeat("-", pdf.LineWidth-2))
+	for _, b := range v.Branches {
+		doc.Line(displayBranch(b.Branch))
+		for _, g := range v.Groups {
+			if g.Branch == b.Branch {
+				doc.Line(row(displayFormat(g.Format), g.Total))
+			}
+		}
+		doc.Line(row("Branch total", b.Total))
+		doc.Line("")
+	}
+	doc.Line("  " + strings.Repeat("=", pdf.LineWidth-2))
+	doc.Line(row("Collection total", v.Total))
+	if len(v.MissingRates) > 0 {
+		doc.Line("")
+		doc.Line("Amounts in " + strings.Join(v.MissingRates, ", ") + " have no exchange rate and are not included in")
+		doc.Line("the replacement costs; they are listed under \"Paid in\".")
+	}
+	return doc.Bytes()
+}
+
+// amountList renders per-currency amounts as "EUR 12.00; GBP 3.50"
+func amountList(amounts []Amount) string {
+	parts := make([]string, len(amounts))
+	for i, a := range amounts {
+		parts[i] = a.Currency + " " + a.Amount
+	}
+	return strings.Join(parts, "; ")
+}
+
+func displayBranch(branch string) string {
+	if branch == "" {
+		return "(no branch)"
+	}
+	return branch
+}
+
+func displayFormat(format string) string {
+	if format == "" {
+		return "(no format)"
+	}
+	return format
+}
@@ -0,0 +1,76 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	maintenance *Maintenance
+	logger      *zap.Logger
+}
+
+func NewHandler(m *Maintenance, l *zap.Logger) *Handler {
+	return &Handler{maintenance: m, logger: l}
+}
+
+// MaintenanceRequest switches maintenance mode on or off
+type MaintenanceRequest struct {
+	Enabled bool `json:"enabled" example:"true"`
+	// Message replaces the default text returned to rejected writes
+	Message           string `json:"message" example:"Catalog migration in progress, back at 02:00 UTC"`
+	RetryAfterSeconds int    `json:"retry_after_seconds" example:"600"`
+}
+
+// GET /admin/maintenance
+
+// GetMaintenance godoc
+// @Summary Get maintenance mode
+// @Tags admin
+// @Produce json
+// @Security AdminToken
+// @Success 200 {object} MaintenanceState
+// @Failure 401 {object} map[string]string
+// @Router /admin/maintenance [get]
+func (h *Handler) GetMaintenance(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.maintenance.State())
+}
+
+// PUT /admin/maintenance
+
+// SetMaintenance godoc
+// @Summary Turn maintenance mode on or off
+// @Description While enabled, writes return 503 with the message and reads continue. The setting applies to this instance until it restarts.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security AdminToken
+// @Param maintenance body MaintenanceRequest true "Maintenance mode"
+// @Success 200 {object} MaintenanceState
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /admin/maintenance [put]
+func (h *Handler) SetMaintenance(w http.ResponseWriter, r *http.Request) {
+	var req MaintenanceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if req.RetryAfterSeconds < 0 {
+		http.Error(w, "retry_after_seconds must not be negative", http.StatusBadRequest)
+		return
+	}
+
+	state := h.maintenance.Set(MaintenanceState{
+		Enabled:           req.Enabled,
+		Message:           req.Message,
+		RetryAfterSeconds: req.RetryAfterSeconds,
+	})
+	h.logger.Warn("Maintenance mode changed", zap.Bool("enabled", state.Enabled), zap.String("message", state.Message))
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(state)
+}
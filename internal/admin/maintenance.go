@@ -0,0 +1,75 @@
+// Package admin holds the operator-facing state of the API and the /admin
+// endpoints that change it at runtime.
+package admin
+
+import (
+	"sync"
+	"time"
+
+	"public_library/internal/config"
+)
+
+// DefaultMaintenanceMessage is shown to rejected writes when no message is set
+const DefaultMaintenanceMessage = "The library catalog is undergoing maintenance; changes are temporarily disabled. Please try again later."
+
+// MaintenanceState is the current maintenance mode as reported by
+// GET /admin/maintenance
+type MaintenanceState struct {
+	Enabled bool   `json:"enabled" example:"true"`
+	Message string `json:"message" example:"Catalog migration in progress, back at 02:00 UTC"`
+	// RetryAfterSeconds is sent as Retry-After on rejected writes; 0 omits it
+	RetryAfterSeconds int        `json:"retry_after_seconds" example:"600"`
+	Since             *time.Time `json:"since,omitempty"`
+}
+
+// Maintenance is the process-wide maintenance switch. While it is enabled
+// writes are rejected with 503 and reads keep working. It starts from the
+// maintenance section of the configuration and is changed through
+// PUT /admin/maintenance; the change is not persisted and applies to this
+// instance only.
+type Maintenance struct {
+	mu    sync.RWMutex
+	state MaintenanceState
+}
+
+func NewMaintenance(cfg config.MaintenanceConfig) *Maintenance {
+	m := &Maintenance{}
+	m.Set(MaintenanceState{
+		Enabled:           cfg.Enabled,
+		Message:           cfg.Message,
+		RetryAfterSeconds: int(cfg.RetryAfter / time.Second),
+	})
+	return m
+}
+
+// State returns the current maintenance mode
+func (m *Maintenance) State() MaintenanceState {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.state
+}
+
+// Set replaces the maintenance mode. Since is kept while the mode stays
+// enabled and cleared when it is turned off.
+func (m *Maintenance) Set(s MaintenanceState) MaintenanceState {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if s.Message == "" {
+		s.Message = DefaultMaintenanceMessage
+	}
+	if s.RetryAfterSeconds < 0 {
+		s.RetryAfterSeconds = 0
+	}
+	switch {
+	case !s.Enabled:
+		s.Since = nil
+	case m.state.Enabled:
+		s.Since = m.state.Since
+	default:
+		now := time.Now().UTC()
+		s.Since = &now
+	}
+	m.state = s
+	return s
+}
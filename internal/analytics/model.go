@@ -0,0 +1,75 @@
+package analytics
+
+// Totals are the searches and views counted over a period
+type Totals struct {
+	Searches int64 `json:"searches" example:"4210"`
+	// ZeroResultSearches are the searches that found no book
+	ZeroResultSearches int64 `json:"zero_result_searches" example:"388"`
+	// ZeroResultRate is zero_result_searches / searches
+	ZeroResultRate float64 `json:"zero_result_rate" example:"0.0922"`
+	Views          int64   `json:"views" example:"9875"`
+}
+
+// QueryCount is how often a query was searched without results
+type QueryCount struct {
+	Query    string `json:"query" example:"octavia butler kindred"`
+	Searches int64  `json:"searches" example:"17"`
+}
+
+// ZeroResultQuery is a query searched without results, over a period
+type ZeroResultQuery struct {
+	Query    string `json:"query" example:"octavia butler kindred"`
+	Searches int64  `json:"searches" example:"17"`
+	// Days counts the days it was searched on
+	Days            int    `json:"days" example:"9"`
+	FirstSearchedOn string `json:"first_searched_on" example:"2024-04-03"`
+	LastSearchedOn  string `json:"last_searched_on" example:"2024-04-29"`
+	// CatalogMatches is how many books the query finds now; queries with
+	// matches were searched before the catalog had them
+	CatalogMatches int64 `json:"catalog_matches" example:"0"`
+}
+
+// ZeroResultLog is the queries searched without results between From and
+// To, inclusive, most searched first
+type ZeroResultLog struct {
+	From string `json:"from" example:"2024-04-02"`
+	To   string `json:"to" example:"2024-05-01"`
+	// Total counts the queries searched at least min_searches times,
+	// including those past the limit
+	Total       int               `json:"total" example:"212"`
+	MinSearches int               `json:"min_searches" example:"1"`
+	Queries     []ZeroResultQuery `json:"queries"`
+}
+
+// BookViews is how often a book's record was viewed
+type BookViews struct {
+	BookID int    `json:"book_id" example:"42"`
+	Title  string `json:"title" example:"Kindred"`
+	Author string `json:"author" example:"Octavia E. Butler"`
+	Views  int64  `json:"views" example:"96"`
+}
+
+// DailyUsage is the searches and views of one day (UTC)
+type DailyUsage struct {
+	Day string `json:"day" example:"2024-05-01"`
+	Totals
+}
+
+// Report is the catalog usage between From and To, inclusive
+type Report struct {
+	From   string `json:"from" example:"2024-04-02"`
+	To     string `json:"to" example:"2024-05-01"`
+	Totals Totals `json:"totals"`
+	// TopZeroResultQueries leaves out the queries searched fewer than
+	// min_query_count times, which could single out a patron
+	TopZeroResultQueries []QueryCount `json:"top_zero_result_queries"`
+	MinQueryCount        int          `json:"min_query_count" example:"3"`
+	TopViewedBooks       []BookViews  `json:"top_viewed_books"`
+	Daily                []DailyUsage `json:"daily"`
+}
+
+func (t *Totals) computeRate() {
+	if t.Searches > 0 {
+		t.ZeroResultRate = float64(t.ZeroResultSearches) / float64(t.Searches)
+	}
+}
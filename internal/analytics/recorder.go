@@ -0,0 +1,214 @@
+// Package analytics counts the searches made, the searches that found
+// nothing and the views of catalog records, to guide collection
+// development. Counts are aggregated per day and query or book, never per
+// member, API key or client, and are added to the database by a periodic
+// flush. Requests sent with DNT: 1 or Sec-GPC: 1, and those of members who
+// set analytics_opt_out, are not counted at all.
+package analytics
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+)
+
+// maxQueryLength caps the stored queries; longer ones are cut
+const maxQueryLength = 100
+
+type queryKey struct {
+	day   string
+	query string
+}
+
+type viewKey struct {
+	day    string
+	bookID int
+}
+
+type dayCounts struct {
+	searches, zeroResults, views int64
+}
+
+// batch holds the counts of one flush
+type batch struct {
+	days    map[string]*dayCounts
+	queries map[queryKey]int64
+	views   map[viewKey]int64
+}
+
+func newBatch() batch {
+	return batch{days: make(map[string]*dayCounts), queries: make(map[queryKey]int64), views: make(map[viewKey]int64)}
+}
+
+func (b batch) day(day string) *dayCounts {
+	c, ok := b.days[day]
+	if !ok {
+		c = &dayCounts{}
+		b.days[day] = c
+	}
+	return c
+}
+
+func (b batch) empty() bool {
+	return len(b.days) == 0
+}
+
+// Recorder aggregates the counts until the next flush
+type Recorder struct {
+	repo   *Repository
+	logger *zap.Logger
+
+	mu       sync.Mutex
+	pending  batch
+	optedOut map[string]bool
+}
+
+func NewRecorder(repo *Repository, logger *zap.Logger) *Recorder {
+	return &Recorder{repo: repo, logger: logger, pending: newBatch(), optedOut: map[string]bool{}}
+}
+
+// Search counts a search for query that found results books. Searches
+// without a query are only listings and are not counted.
+func (rec *Recorder) Search(query string, results int64, at time.Time) {
+	query = normalizeQuery(query)
+	if query == "" {
+		return
+	}
+	day := at.UTC().Format(time.DateOnly)
+
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	c := rec.pending.day(day)
+	c.searches++
+	if results == 0 {
+		c.zeroResults++
+		rec.pending.queries[queryKey{day: day, query: query}]++
+	}
+}
+
+// View counts a view of the book's record
+func (rec *Recorder) View(bookID int, at time.Time) {
+	day := at.UTC().Format(time.DateOnly)
+
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	rec.pending.day(day).views++
+	rec.pending.views[viewKey{day: day, bookID: bookID}]++
+}
+
+// OptedOut reports whether the member opted out of analytics, as of the
+// last flush
+func (rec *Recorder) OptedOut(memberID string) bool {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	return rec.optedOut[memberID]
+}
+
+// Flush adds the pending counts to the database and reloads the members
+// who opted out. If adding fails the counts are merged back and retried on
+// the next flush.
+func (rec *Recorder) Flush(ctx context.Context) error {
+	rec.mu.Lock()
+	b := rec.pending
+	rec.pending = newBatch()
+	rec.mu.Unlock()
+
+	if !b.empty() {
+		if err := rec.repo.Add(ctx, b); err != nil {
+			rec.mu.Lock()
+			for day, c := range b.days {
+				p := rec.pending.day(day)
+				p.searches += c.searches
+				p.zeroResults += c.zeroResults
+				p.views += c.views
+			}
+			for k, n := range b.queries {
+				rec.pending.queries[k] += n
+			}
+			for k, n := range b.views {
+				rec.pending.views[k] += n
+			}
+			rec.mu.Unlock()
+			return err
+		}
+	}
+	return rec.loadOptOuts(ctx)
+}
+
+func (rec *Recorder) loadOptOuts(ctx context.Context) error {
+	ids, err := rec.repo.OptedOutMembers(ctx)
+	if err != nil {
+		return err
+	}
+	optedOut := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		optedOut[id] = true
+	}
+	rec.mu.Lock()
+	rec.optedOut = optedOut
+	rec.mu.Unlock()
+	return nil
+}
+
+// StartFlusher loads the members who opted out, then flushes every
+// interval until ctx is cancelled. Whoever cancels ctx should Flush once
+// more to keep the last counts.
+func (rec *Recorder) StartFlusher(ctx context.Context, interval time.Duration) {
+	if err := rec.loadOptOuts(ctx); err != nil {
+		rec.logger.Error("Loading analytics opt-outs failed", zap.Error(err))
+	}
+	if interval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := rec.Flush(ctx); err != nil {
+					rec.logger.Error("Analytics flush failed", zap.Error(err))
+				}
+			}
+		}
+	}()
+}
+
+// normalizeQuery lowercases the query and collapses its whitespace, so
+// the same search typed differently is counted once
+func normalizeQuery(query string) string {
+	query = strings.Join(strings.Fields(strings.ToLower(query)), " ")
+	if utf8.RuneCountInString(query) > maxQueryLength {
+		query = strings.TrimSpace(string([]rune(query)[:maxQueryLength]))
+	}
+	return query
+}
+
+type recorderKey struct{}
+
+// WithRecorder returns a copy of ctx whose searches and views are counted
+// by rec. Requests that opted out are left without one.
+func WithRecorder(ctx context.Context, rec *Recorder) context.Context {
+	return context.WithValue(ctx, recorderKey{}, rec)
+}
+
+// RecordSearch counts a search for query that found results books, if
+// the request is counted
+func RecordSearch(ctx context.Context, query string, results int64) {
+	if rec, ok := ctx.Value(recorderKey{}).(*Recorder); ok {
+		rec.Search(query, results, time.Now())
+	}
+}
+
+// RecordView counts a view of the book's record, if the request is counted
+func RecordView(ctx context.Context, bookID int) {
+	if rec, ok := ctx.Value(recorderKey{}).(*Recorder); ok {
+		rec.View(bookID, time.Now())
+	}
+}
@@ -0,0 +1,238 @@
+package analytics
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"public_library/internal/db"
+	"public_library/utils"
+	"time"
+)
+
+type Repository struct {
+	db *db.DB
+}
+
+func NewRepository(conn *db.DB) *Repository {
+	return &Repository{db: conn}
+}
+
+// Add adds a batch of counts to the stored totals in one transaction
+func (r *Repository) Add(ctx context.Context, b batch) error {
+	log.Println("<--------Add analytics starts-------->")
+	defer log.Println("<--------Add analytics ends-------->")
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var days []string
+	var searches, zeroResults, views []int64
+	for day, c := range b.days {
+		days = append(days, day)
+		searches, zeroResults, views = append(searches, c.searches), append(zeroResults, c.zeroResults), append(views, c.views)
+	}
+	daysQuery := fmt.Sprintf(`INSERT INTO %[1]s (day, searches, zero_result_searches, views)
+		SELECT * FROM unnest($1::date[], $2::bigint[], $3::bigint[], $4::bigint[])
+		ON CONFLICT (day) DO UPDATE SET
+			searches = %[1]s.searches + EXCLUDED.searches,
+			zero_result_searches = %[1]s.zero_result_searches + EXCLUDED.zero_result_searches,
+			views = %[1]s.views + EXCLUDED.views`, utils.AnalyticsDailyTable)
+	if _, err := tx.ExecContext(ctx, daysQuery, days, searches, zeroResults, views); err != nil {
+		log.Printf("Failed to add analytics for %d days: %v", len(days), err)
+		return err
+	}
+
+	if len(b.queries) > 0 {
+		var queryDays, queries []string
+		var counts []int64
+		for k, n := range b.queries {
+			queryDays, queries, counts = append(queryDays, k.day), append(queries, k.query), append(counts, n)
+		}
+		queriesQuery := fmt.Sprintf(`INSERT INTO %[1]s (day, query, searches)
+			SELECT * FROM unnest($1::date[], $2::text[], $3::bigint[])
+			ON CONFLICT (day, query) DO UPDATE SET searches = %[1]s.searches + EXCLUDED.searches`,
+			utils.AnalyticsZeroResultsTable)
+		if _, err := tx.ExecContext(ctx, queriesQuery, queryDays, queries, counts); err != nil {
+			log.Printf("Failed to add %d zero-result queries: %v", len(queries), err)
+			return err
+		}
+	}
+
+	if len(b.views) > 0 {
+		var viewDays []string
+		var bookIDs []int
+		var counts []int64
+		for k, n := range b.views {
+			viewDays, bookIDs, counts = append(viewDays, k.day), append(bookIDs, k.bookID), append(counts, n)
+		}
+		viewsQuery := fmt.Sprintf(`INSERT INTO %[1]s (day, book_id, views)
+			SELECT * FROM unnest($1::date[], $2::int[], $3::bigint[])
+			ON CONFLICT (day, book_id) DO UPDATE SET views = %[1]s.views + EXCLUDED.views`,
+			utils.AnalyticsViewsTable)
+		if _, err := tx.ExecContext(ctx, viewsQuery, viewDays, bookIDs, counts); err != nil {
+			log.Printf("Failed to add views of %d books: %v", len(bookIDs), err)
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// OptedOutMembers returns the IDs of the members who opted out of analytics
+func (r *Repository) OptedOutMembers(ctx context.Context) ([]string, error) {
+	query := fmt.Sprintf(`SELECT id FROM %s WHERE analytics_opt_out`, utils.MembersTable)
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		log.Printf("Failed to list members opted out of analytics: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// ZeroResults returns the queries searched without results from..to
+// (inclusive days) at least minSearches times, most searched first, with how
+// many there are in all
+func (r *Repository) ZeroResults(ctx context.Context, from, to time.Time, minSearches, limit int) (*ZeroResultLog, error) {
+	log.Println("<--------Zero-result log starts-------->")
+	defer log.Println("<--------Zero-result log ends-------->")
+
+	zl := &ZeroResultLog{From: from.Format(time.DateOnly), To: to.Format(time.DateOnly), MinSearches: minSearches,
+		Queries: []ZeroResultQuery{}}
+	query := fmt.Sprintf(`
+		SELECT query, SUM(searches), count(*), to_char(min(day), 'YYYY-MM-DD'), to_char(max(day), 'YYYY-MM-DD'),
+			count(*) OVER ()
+		FROM %s
+		WHERE day BETWEEN $1 AND $2
+		GROUP BY query
+		HAVING SUM(searches) >= $3
+		ORDER BY SUM(searches) DESC, max(day) DESC, query
+		LIMIT $4
+	`, utils.AnalyticsZeroResultsTable)
+	rows, err := r.db.QueryContext(ctx, query, zl.From, zl.To, minSearches, limit)
+	if err != nil {
+		log.Printf("Failed to read the zero-result log: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var q ZeroResultQuery
+		if err := rows.Scan(&q.Query, &q.Searches, &q.Days, &q.FirstSearchedOn, &q.LastSearchedOn, &zl.Total); err != nil {
+			log.Printf("Failed to scan zero-result query: %v", err)
+			return nil, err
+		}
+		zl.Queries = append(zl.Queries, q)
+	}
+	return zl, rows.Err()
+}
+
+// Report sums the counts from..to (inclusive days) with the topN
+// zero-result queries searched at least minQueryCount times and the topN
+// most viewed books. Audiences limits the books to those classifications;
+// nil allows every book.
+func (r *Repository) Report(ctx context.Context, from, to time.Time, topN, minQueryCount int, audiences []string) (*Report, error) {
+	log.Println("<--------Analytics report starts-------->")
+	defer log.Println("<--------Analytics report ends-------->")
+
+	report := &Report{
+		From:                 from.Format(time.DateOnly),
+		To:                   to.Format(time.DateOnly),
+		MinQueryCount:        minQueryCount,
+		TopZeroResultQueries: []QueryCount{},
+		TopViewedBooks:       []BookViews{},
+		Daily:                []DailyUsage{},
+	}
+
+	dailyQuery := fmt.Sprintf(`
+		SELECT to_char(day, 'YYYY-MM-DD'), searches, zero_result_searches, views
+		FROM %s
+		WHERE day BETWEEN $1 AND $2
+		ORDER BY day
+	`, utils.AnalyticsDailyTable)
+	rows, err := r.db.QueryContext(ctx, dailyQuery, report.From, report.To)
+	if err != nil {
+		log.Printf("Failed to read daily analytics: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var d DailyUsage
+		if err := rows.Scan(&d.Day, &d.Searches, &d.ZeroResultSearches, &d.Views); err != nil {
+			log.Printf("Failed to scan daily analytics: %v", err)
+			return nil, err
+		}
+		report.Totals.Searches += d.Searches
+		report.Totals.ZeroResultSearches += d.ZeroResultSearches
+		report.Totals.Views += d.Views
+		d.computeRate()
+		report.Daily = append(report.Daily, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	report.Totals.computeRate()
+
+	queriesQuery := fmt.Sprintf(`
+		SELECT query, SUM(searches)
+		FROM %s
+		WHERE day BETWEEN $1 AND $2
+		GROUP BY query
+		HAVING SUM(searches) >= $3
+		ORDER BY SUM(searches) DESC, query
+		LIMIT $4
+	`, utils.AnalyticsZeroResultsTable)
+	queryRows, err := r.db.QueryContext(ctx, queriesQuery, report.From, report.To, minQueryCount, topN)
+	if err != nil {
+		log.Printf("Failed to read zero-result queries: %v", err)
+		return nil, err
+	}
+	defer queryRows.Close()
+	for queryRows.Next() {
+		var q QueryCount
+		if err := queryRows.Scan(&q.Query, &q.Searches); err != nil {
+			log.Printf("Failed to scan zero-result query: %v", err)
+			return nil, err
+		}
+		report.TopZeroResultQueries = append(report.TopZeroResultQueries, q)
+	}
+	if err := queryRows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Views of books deleted since are left out with the books
+	viewsQuery := fmt.Sprintf(`
+		SELECT b.id, b.title, b.author, SUM(v.views)
+		FROM %s v
+		JOIN %s b ON b.id = v.book_id
+		WHERE v.day BETWEEN $1 AND $2 AND ($3::text[] IS NULL OR b.audience = ANY($3))
+		GROUP BY b.id
+		ORDER BY SUM(v.views) DESC, b.id
+		LIMIT $4
+	`, utils.AnalyticsViewsTable, utils.BooksTable)
+	viewRows, err := r.db.QueryContext(ctx, viewsQuery, report.From, report.To, audiences, topN)
+	if err != nil {
+		log.Printf("Failed to read book views: %v", err)
+		return nil, err
+	}
+	defer viewRows.Close()
+	for viewRows.Next() {
+		var v BookViews
+		if err := viewRows.Scan(&v.BookID, &v.Title, &v.Author, &v.Views); err != nil {
+			log.Printf("Failed to scan book views: %v", err)
+			return nil, err
+		}
+		report.TopViewedBooks = append(report.TopViewedBooks, v)
+	}
+	return report, viewRows.Err()
+}
@@ -0,0 +1,88 @@
+package book
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Accessibility features, named after the schema.org accessibilityFeature
+// vocabulary. dyslexiaFriendly has no schema.org term and covers dyslexic
+// fonts, tinted paper and similar layouts.
+const (
+	AccessibilityLargePrint          = "largePrint"
+	AccessibilityBraille             = "braille"
+	AccessibilityDyslexiaFriendly    = "dyslexiaFriendly"
+	AccessibilityAudioDescription    = "audioDescription"
+	AccessibilityCaptions            = "captions"
+	AccessibilitySignLanguage        = "signLanguage"
+	AccessibilityTactileGraphic      = "tactileGraphic"
+	AccessibilityHighContrastDisplay = "highContrastDisplay"
+)
+
+var accessibilityFeatures = []string{
+	AccessibilityLargePrint, AccessibilityBraille, AccessibilityDyslexiaFriendly,
+	AccessibilityAudioDescription, AccessibilityCaptions, AccessibilitySignLanguage,
+	AccessibilityTactileGraphic, AccessibilityHighContrastDisplay,
+}
+
+// lookupAccessibilityFeature matches a feature case-insensitively and returns
+// its canonical spelling
+func lookupAccessibilityFeature(feature string) (string, bool) {
+	feature = strings.TrimSpace(feature)
+	for _, f := range accessibilityFeatures {
+		if strings.EqualFold(f, feature) {
+			return f, true
+		}
+	}
+	return "", false
+}
+
+// normalizeAccessibilityFeatures checks every feature is known and returns
+// them canonically spelled, without duplicates, in vocabulary order
+func normalizeAccessibilityFeatures(features []string) ([]string, error) {
+	normalized := []string{}
+	for _, feature := range features {
+		f, ok := lookupAccessibilityFeature(feature)
+		if !ok {
+			return nil, fmt.Errorf("%w: accessibility feature %q must be one of %s",
+				ErrValidation, feature, strings.Join(accessibilityFeatures, ", "))
+		}
+		if !slices.Contains(normalized, f) {
+			normalized = append(normalized, f)
+		}
+	}
+	slices.SortFunc(normalized, func(a, b string) int {
+		return slices.Index(accessibilityFeatures, a) - slices.Index(accessibilityFeatures, b)
+	})
+	return normalized, nil
+}
+
+// parseAccessibilityFeatures splits the "largePrint;braille" form used in CSV
+func parseAccessibilityFeatures(s string) []string {
+	var features []string
+	for _, f := range strings.Split(s, ";") {
+		if f = strings.TrimSpace(f); f != "" {
+			features = append(features, f)
+		}
+	}
+	return features
+}
+
+// featureList scans the JSON array of features selected by selectBooksSQL
+type featureList []string
+
+func (l *featureList) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*l = featureList{}
+		return nil
+	case []byte:
+		return json.Unmarshal(v, l)
+	case string:
+		return json.Unmarshal([]byte(v), l)
+	default:
+		return fmt.Errorf("cannot scan %T into accessibility features", src)
+	}
+}
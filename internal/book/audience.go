@@ -0,0 +1,72 @@
+package book
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Audience classifications, from most to least restricted
+const (
+	AudienceChildren = "children"
+	AudienceTeen     = "teen"
+	AudienceAdult    = "adult"
+)
+
+var audienceOrder = []string{AudienceChildren, AudienceTeen, AudienceAdult}
+
+type audienceCtxKey struct{}
+
+// WithAudienceLimit restricts every catalog read made with ctx to materials
+// suitable for audience, e.g. a children's account only sees "children".
+func WithAudienceLimit(ctx context.Context, audience string) (context.Context, error) {
+	audience = strings.ToLower(strings.TrimSpace(audience))
+	if allowedAudiences(audience) == nil {
+		return ctx, fmt.Errorf("%w: audience must be one of %s", ErrValidation, strings.Join(audienceOrder, ", "))
+	}
+	return context.WithValue(ctx, audienceCtxKey{}, audience), nil
+}
+
+func audienceLimit(ctx context.Context) string {
+	audience, _ := ctx.Value(audienceCtxKey{}).(string)
+	return audience
+}
+
+// AllowedAudiences lists the classifications visible to reads made with
+// ctx, or nil when they are not restricted
+func AllowedAudiences(ctx context.Context) []string {
+	return allowedAudiences(audienceLimit(ctx))
+}
+
+// allowedAudiences lists the classifications visible under limit
+func allowedAudiences(limit string) []string {
+	for i, a := range audienceOrder {
+		if a == limit {
+			return audienceOrder[:i+1]
+		}
+	}
+	return nil
+}
+
+// restrictAudience adds the context's audience limit, if any, to a books
+// WHERE clause and its arguments
+func restrictAudience(ctx context.Context, whereSQL string, args []interface{}) (string, []interface{}) {
+	allowed := allowedAudiences(audienceLimit(ctx))
+	if allowed == nil {
+		return whereSQL, args
+	}
+	args = append(args, allowed)
+	return fmt.Sprintf("(%s) AND b.audience = ANY($%d)", whereSQL, len(args)), args
+}
+
+// normalizeAudience defaults an empty audience to adult and checks it is known
+func normalizeAudience(audience string) (string, error) {
+	audience = strings.ToLower(strings.TrimSpace(audience))
+	if audience == "" {
+		return AudienceAdult, nil
+	}
+	if allowedAudiences(audience) == nil {
+		return "", fmt.Errorf("%w: audience must be one of %s", ErrValidation, strings.Join(audienceOrder, ", "))
+	}
+	return audience, nil
+}
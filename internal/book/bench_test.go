@@ -0,0 +1,67 @@
+package book
+
+import (
+	"context"
+	"public_library/internal/config"
+	"testing"
+)
+
+// Benchmarks of the SQL built for a list request, run with
+//
+//	go test ./internal/book -run '^$' -bench . -benchmem
+//
+// BenchmarkList in list_test.go measures the queries themselves.
+
+var benchListRequest = func() PaginationRequest {
+	from, to := 1900, 1950
+	return PaginationRequest{
+		Page: 3, PageSize: 20, Search: "gatsby jazz age", Author: "fitzgerald", Title: "the",
+		YearFrom: &from, YearTo: &to, Formats: []string{"paperback", "hardcover"},
+		SortBy: []Sort{{Field: "author"}, {Field: "publication_year", Order: "desc"}},
+	}
+}()
+
+func BenchmarkListWhere(b *testing.B) {
+	ctx, err := WithAudienceLimit(context.Background(), AudienceTeen)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, _, err := listWhere(ctx, benchListRequest); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSearchClause(b *testing.B) {
+	for _, term := range []string{"gatsby", "Достоевский", "978-0-618-64015-7"} {
+		b.Run(term, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				searchClause(term, nil)
+			}
+		})
+	}
+}
+
+func BenchmarkOrderBySQL(b *testing.B) {
+	keys := benchListRequest.sortKeys()
+	collation := SortCollation("en")
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := orderBySQL(keys, collation); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkPageBounds(b *testing.B) {
+	r := &Repository{catalog: config.CatalogConfig{DefaultPageSize: 10, MaxPageSize: 100}}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, _, err := r.pageBounds(benchListRequest); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
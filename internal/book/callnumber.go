@@ -0,0 +1,48 @@
+package book
+
+import (
+	"strings"
+	"unicode"
+)
+
+// callNumberDigits is the width integer runs are padded to in sort keys
+const callNumberDigits = 10
+
+// CallNumberSortKey returns a key that sorts Dewey and LC call numbers in
+// shelf order with a plain string comparison. Integer runs are zero-padded so
+// "QA76" sorts before "QA100", while digits after a '.' are decimal fractions
+// (Dewey decimals, LC cutters such as ".G63") and are left as-is so ".9"
+// sorts before ".914" and ".G63" before ".G7".
+func CallNumberSortKey(callNumber string) string {
+	s := strings.ToUpper(strings.Join(strings.Fields(callNumber), " "))
+
+	var key strings.Builder
+	decimal := false // inside a ".123" decimal or ".A123" cutter
+	for i := 0; i < len(s); {
+		c := s[i]
+		if !unicode.IsDigit(rune(c)) {
+			switch {
+			case c == '.':
+				decimal = true
+			case !unicode.IsLetter(rune(c)):
+				decimal = false
+			}
+			key.WriteByte(c)
+			i++
+			continue
+		}
+		j := i
+		for j < len(s) && unicode.IsDigit(rune(s[j])) {
+			j++
+		}
+		run := s[i:j]
+		if !decimal {
+			if pad := callNumberDigits - len(run); pad > 0 {
+				key.WriteString(strings.Repeat("0", pad))
+			}
+		}
+		key.WriteString(run)
+		i = j
+	}
+	return key.String()
+}
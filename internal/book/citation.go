@@ -0,0 +1,312 @@
+package book
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Citation export formats
+const (
+	CitationBibTeX = "bibtex"
+	CitationRIS    = "ris"
+)
+
+// ErrInvalidCitationFormat is returned for a citation format other than the
+// ones above
+var ErrInvalidCitationFormat = fmt.Errorf("%w: format must be bibtex or ris", ErrValidation)
+
+// personName is one author split into family and given names
+type personName struct {
+	Family string
+	Given  string
+	Suffix string // Jr., III, ...
+}
+
+// inverted is the name as "Family, Given, Suffix", the form RIS expects
+func (n personName) inverted() string {
+	parts := []string{n.Family}
+	for _, p := range []string{n.Given, n.Suffix} {
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// bibTeX is the name as BibTeX parses it: "Family, Suffix, Given"
+func (n personName) bibTeX() string {
+	if n.Suffix == "" {
+		return n.inverted()
+	}
+	return n.Family + ", " + n.Suffix + ", " + n.Given
+}
+
+// authorSeparator splits the author field of books with several authors,
+// as in "Terry Pratchett & Neil Gaiman" or "Strunk, William; White, E. B."
+var authorSeparator = regexp.MustCompile(`\s*(?:;|&|\band\b)\s*`)
+
+// nameSuffixes are the generational suffixes recognized after a name
+var nameSuffixes = map[string]bool{"jr": true, "jr.": true, "sr": true, "sr.": true, "ii": true, "iii": true, "iv": true}
+
+// authorNames splits a book's author field into names. A name already
+// holding a comma ("Fitzgerald, F. Scott") is taken as inverted; otherwise
+// the last word is the family name.
+func authorNames(author string) []personName {
+	var names []personName
+	for _, part := range authorSeparator.Split(author, -1) {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		if family, given, ok := strings.Cut(part, ","); ok {
+			n := personName{Family: strings.TrimSpace(family), Given: strings.TrimSpace(given)}
+			if given, suffix, ok := strings.Cut(n.Given, ","); ok {
+				n.Given, n.Suffix = strings.TrimSpace(given), strings.TrimSpace(suffix)
+			}
+			names = append(names, n)
+			continue
+		}
+		words := strings.Fields(part)
+		suffix := ""
+		if len(words) > 2 && nameSuffixes[strings.ToLower(words[len(words)-1])] {
+			suffix, words = words[len(words)-1], words[:len(words)-1]
+		}
+		if len(words) == 1 {
+			names = append(names, personName{Family: words[0]})
+			continue
+		}
+		names = append(names, personName{
+			Family: words[len(words)-1],
+			Given:  strings.Join(words[:len(words)-1], " "),
+			Suffix: suffix,
+		})
+	}
+	return names
+}
+
+// Citation renders b in the given export format
+func Citation(b *Book, format string) (string, error) {
+	switch strings.ToLower(format) {
+	case CitationBibTeX:
+		return bibTeX(b), nil
+	case CitationRIS:
+		return ris(b), nil
+	default:
+		return "", ErrInvalidCitationFormat
+	}
+}
+
+// preferredISBN is the book's ISBN field or, failing that, its first ISBN, ISBN-13 over ISBN-10
+func (b *Book) preferredISBN() string {
+	if b.ISBN != "" {
+		return b.ISBN
+	}
+	for _, idType := range []string{IdentifierISBN13, IdentifierISBN10} {
+		for _, id := range b.Identifiers {
+			if id.Type == idType {
+				return id.Value
+			}
+		}
+	}
+	return ""
+}
+
+func (b *Book) identifier(idType string) string {
+	for _, id := range b.Identifiers {
+		if id.Type == idType {
+			return id.Value
+		}
+	}
+	return ""
+}
+
+// seriesPosition formats a series position, without a fraction when whole
+func seriesPosition(s *SeriesRef) string {
+	if s == nil || s.Position == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*s.Position, 'f', -1, 64)
+}
+
+// bibTeXSpecial are the characters BibTeX and LaTeX give a meaning to
+var bibTeXSpecial = strings.NewReplacer(
+	`\`, `\textbackslash{}`, `{`, `\{`, `}`, `\}`, `&`, `\&`, `%`, `\%`,
+	`$`, `\$`, `#`, `\#`, `_`, `\_`, `~`, `\textasciitilde{}`, `^`, `\textasciicircum{}`,
+)
+
+// bibTeXKey builds a citation key such as fitzgerald1925great: the first
+// author's family name, the year and the first word of the title that is
+// not an article
+func bibTeXKey(b *Book, names []personName) string {
+	keyPart := func(s string) string {
+		var out strings.Builder
+		for _, r := range strings.ToLower(s) {
+			if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+				out.WriteRune(r)
+			}
+		}
+		return out.String()
+	}
+
+	key := "book"
+	if len(names) > 0 {
+		if family := keyPart(names[0].Family); family != "" {
+			key = family
+		}
+	}
+	if b.PublicationYear != nil {
+		key += strconv.Itoa(*b.PublicationYear)
+	}
+	for _, word := range strings.Fields(b.Title) {
+		switch w := keyPart(word); w {
+		case "", "a", "an", "the":
+			continue
+		default:
+			return key + w
+		}
+	}
+	return key + strconv.Itoa(b.ID)
+}
+
+// bibTeXType is the entry type for the book's format; recordings and video
+// are @misc with the format as howpublished, which Zotero and BibLaTeX read
+func bibTeXType(format string) string {
+	switch format {
+	case "dvd", "bluray", "audiobook", "audiobook_cd":
+		return "misc"
+	default:
+		return "book"
+	}
+}
+
+func bibTeX(b *Book) string {
+	names := authorNames(b.Author)
+	authors := make([]string, len(names))
+	for i, n := range names {
+		authors[i] = bibTeXSpecial.Replace(n.bibTeX())
+	}
+
+	type field struct{ name, value string }
+	fields := []field{
+		{"author", strings.Join(authors, " and ")},
+		// The extra braces keep BibTeX styles from lower-casing the title
+		{"title", "{" + bibTeXSpecial.Replace(b.Title) + "}"},
+	}
+	if b.PublicationYear != nil {
+		fields = append(fields, field{"year", strconv.Itoa(*b.PublicationYear)})
+	}
+	if b.Edition != "" {
+		fields = append(fields, field{"edition", bibTeXSpecial.Replace(b.Edition)})
+	}
+	if b.Series != nil && b.Series.Title != "" {
+		fields = append(fields, field{"series", bibTeXSpecial.Replace(b.Series.Title)})
+		if pos := seriesPosition(b.Series); pos != "" {
+			fields = append(fields, field{"number", pos})
+		}
+	}
+	if bibTeXType(b.Format) == "misc" {
+		if f, ok := lookupFormatName(b.Format); ok {
+			fields = append(fields, field{"howpublished", f})
+		}
+	}
+	if isbn := b.preferredISBN(); isbn != "" {
+		fields = append(fields, field{"isbn", isbn})
+	}
+	if issn := b.identifier(IdentifierISSN); issn != "" {
+		fields = append(fields, field{"issn", issn[:4] + "-" + issn[4:]})
+	}
+	if b.PageCount != nil {
+		fields = append(fields, field{"pagetotal", strconv.Itoa(*b.PageCount)})
+	}
+	if b.Description != "" {
+		fields = append(fields, field{"abstract", bibTeXSpecial.Replace(b.Description)})
+	}
+	if b.CallNumber != "" {
+		fields = append(fields, field{"note", "Call number: " + bibTeXSpecial.Replace(b.CallNumber)})
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "@%s{%s,\n", bibTeXType(b.Format), bibTeXKey(b, names))
+	for i, f := range fields {
+		fmt.Fprintf(&out, "  %s = {%s}", f.name, f.value)
+		if i < len(fields)-1 {
+			out.WriteString(",")
+		}
+		out.WriteString("\n")
+	}
+	out.WriteString("}\n")
+	return out.String()
+}
+
+// risType is the RIS reference type for the book's format
+func risType(format string) string {
+	switch format {
+	case "ebook":
+		return "EBOOK"
+	case "dvd", "bluray":
+		return "VIDEO"
+	case "audiobook", "audiobook_cd":
+		return "SOUND"
+	case "magazine":
+		return "MGZN"
+	default:
+		return "BOOK"
+	}
+}
+
+// risText keeps a value on its RIS line; RIS has no escaping, so line
+// breaks in a description become spaces
+func risText(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func ris(b *Book) string {
+	var out strings.Builder
+	tag := func(name, value string) {
+		if value != "" {
+			fmt.Fprintf(&out, "%s  - %s\r\n", name, risText(value))
+		}
+	}
+
+	tag("TY", risType(b.Format))
+	for _, n := range authorNames(b.Author) {
+		tag("AU", n.inverted())
+	}
+	tag("TI", b.Title)
+	if b.PublicationYear != nil {
+		tag("PY", strconv.Itoa(*b.PublicationYear))
+	}
+	tag("ET", b.Edition)
+	if b.Series != nil {
+		tag("T2", b.Series.Title)
+		tag("VL", seriesPosition(b.Series))
+	}
+	if isbn := b.preferredISBN(); isbn != "" {
+		tag("SN", isbn)
+	} else if issn := b.identifier(IdentifierISSN); issn != "" {
+		tag("SN", issn[:4]+"-"+issn[4:])
+	}
+	if b.PageCount != nil {
+		tag("SP", strconv.Itoa(*b.PageCount))
+	}
+	tag("AB", b.Description)
+	tag("CN", b.CallNumber)
+	if f, ok := lookupFormatName(b.Format); ok {
+		tag("M3", f)
+	}
+	out.WriteString("ER  - \r\n")
+	return out.String()
+}
+
+// lookupFormatName returns the display name of a format code
+func lookupFormatName(code string) (string, bool) {
+	for _, f := range defaultFormats {
+		if f.Code == code {
+			return f.Name, true
+		}
+	}
+	return "", false
+}
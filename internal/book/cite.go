@@ -0,0 +1,288 @@
+package book
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Citation styles of GET /books/{id}/cite
+const (
+	StyleAPA     = "apa"     // APA 7th edition reference list entry
+	StyleMLA     = "mla"     // MLA 9th edition works cited entry
+	StyleChicago = "chicago" // Chicago 17th edition bibliography entry
+)
+
+// ErrInvalidCitationStyle is returned for a style other than the ones above
+var ErrInvalidCitationStyle = fmt.Errorf("%w: style must be apa, mla or chicago", ErrValidation)
+
+// FormattedCitation is a book's reference in one citation style
+type FormattedCitation struct {
+	Style string `json:"style" example:"apa"`
+	// Text is the citation as plain text
+	Text string `json:"text" example:"Fitzgerald, F. S. (1925). The Great Gatsby."`
+	// HTML is the citation with the title in <i>, ready to show or copy
+	HTML string `json:"html" example:"Fitzgerald, F. S. (1925). <i>The Great Gatsby</i>."`
+}
+
+// citationText is a citation built from runs of plain and italic text
+type citationText []citationRun
+
+type citationRun struct {
+	text   string
+	italic bool
+}
+
+func (c *citationText) add(text string, italic bool) {
+	*c = append(*c, citationRun{text: text, italic: italic})
+}
+
+// period ends the citation so far with a period, unless it already ends
+// with a period, question mark or exclamation mark, as after an initial,
+// "ed." or a title such as "Who Moved My Cheese?"
+func (c *citationText) period() {
+	if len(*c) == 0 {
+		return
+	}
+	last := (*c)[len(*c)-1].text
+	if !strings.HasSuffix(last, ".") && !strings.HasSuffix(last, "?") && !strings.HasSuffix(last, "!") {
+		c.add(".", false)
+	}
+}
+
+func (c citationText) render() (string, string) {
+	var text, markup strings.Builder
+	for _, run := range c {
+		text.WriteString(run.text)
+		if run.italic {
+			markup.WriteString("<i>" + html.EscapeString(run.text) + "</i>")
+		} else {
+			markup.WriteString(html.EscapeString(run.text))
+		}
+	}
+	return text.String(), markup.String()
+}
+
+// FormatCitation renders b in the given citation style. The catalog does not
+// record publishers, so the citations leave the publisher out.
+func FormatCitation(b *Book, style string) (*FormattedCitation, error) {
+	style = strings.ToLower(style)
+	names := authorNames(b.Author)
+	var c citationText
+	switch style {
+	case StyleAPA:
+		c = apa(b, names)
+	case StyleMLA:
+		c = mla(b, names)
+	case StyleChicago:
+		c = chicago(b, names)
+	default:
+		return nil, ErrInvalidCitationStyle
+	}
+	text, markup := c.render()
+	return &FormattedCitation{Style: style, Text: text, HTML: markup}, nil
+}
+
+// joinNames lists names as "A, B, and C", or "A and B" for two. APA also
+// puts the comma before & with two names.
+func joinNames(names []string, conjunction string, commaForTwo bool) string {
+	switch len(names) {
+	case 0:
+		return ""
+	case 1:
+		return names[0]
+	case 2:
+		if commaForTwo {
+			return names[0] + ", " + conjunction + " " + names[1]
+		}
+		return names[0] + " " + conjunction + " " + names[1]
+	default:
+		return strings.Join(names[:len(names)-1], ", ") + ", " + conjunction + " " + names[len(names)-1]
+	}
+}
+
+// initials abbreviates given names as "F. S." and "J.-P."
+func initials(given string) string {
+	var out []string
+	for _, name := range strings.Fields(given) {
+		var parts []string
+		for _, part := range strings.Split(name, "-") {
+			if r, _ := utf8.DecodeRuneInString(part); r != utf8.RuneError && unicode.IsLetter(r) {
+				parts = append(parts, string(r)+".")
+			}
+		}
+		if len(parts) > 0 {
+			out = append(out, strings.Join(parts, "-"))
+		}
+	}
+	return strings.Join(out, " ")
+}
+
+// editionLabel turns the edition field into "2nd ed."; first editions are
+// not mentioned in citations
+func editionLabel(edition string) string {
+	e := strings.TrimSpace(edition)
+	lower := strings.ToLower(e)
+	switch lower {
+	case "", "1", "1st", "first", "1st ed.", "first edition":
+		return ""
+	}
+	if n, err := strconv.Atoi(e); err == nil {
+		return ordinal(n) + " ed."
+	}
+	if strings.HasSuffix(lower, " edition") {
+		e = e[:len(e)-len(" edition")]
+	}
+	if strings.HasSuffix(lower, "ed.") {
+		return e
+	}
+	return e + " ed."
+}
+
+func ordinal(n int) string {
+	suffix := "th"
+	if n%100 < 11 || n%100 > 13 {
+		switch n % 10 {
+		case 1:
+			suffix = "st"
+		case 2:
+			suffix = "nd"
+		case 3:
+			suffix = "rd"
+		}
+	}
+	return strconv.Itoa(n) + suffix
+}
+
+// apa: Fitzgerald, F. S., & Perkins, M. (1925). The great Gatsby (2nd ed.).
+// Titles are left in the catalog's capitalization rather than guessed into
+// sentence case.
+func apa(b *Book, names []personName) citationText {
+	var c citationText
+	authors := make([]string, len(names))
+	for i, n := range names {
+		authors[i] = n.Family
+		if given := initials(n.Given); given != "" {
+			authors[i] += ", " + given
+		}
+		if n.Suffix != "" {
+			authors[i] += ", " + n.Suffix
+		}
+	}
+	if len(authors) > 20 {
+		authors = append(authors[:19], "... "+authors[len(authors)-1])
+		c.add(strings.Join(authors, ", "), false)
+	} else {
+		c.add(joinNames(authors, "&", true), false)
+	}
+	c.period()
+
+	year := "n.d."
+	if b.PublicationYear != nil {
+		year = strconv.Itoa(*b.PublicationYear)
+	}
+	c.add(" ("+year+"). ", false)
+	c.add(b.Title, true)
+	if ed := editionLabel(b.Edition); ed != "" {
+		c.add(" ("+ed+")", false)
+	}
+	c.period()
+	return c
+}
+
+// mlaNames lists the first author inverted and a second in natural order;
+// three or more authors are shortened to "et al."
+func mlaNames(names []personName) string {
+	if len(names) == 0 {
+		return ""
+	}
+	first := names[0].inverted()
+	switch len(names) {
+	case 1:
+		return first
+	case 2:
+		return first + ", and " + names[1].natural()
+	default:
+		return first + ", et al"
+	}
+}
+
+// natural is the name in reading order, "Given Family, Suffix"
+func (n personName) natural() string {
+	s := strings.TrimSpace(n.Given + " " + n.Family)
+	if n.Suffix != "" {
+		s += ", " + n.Suffix
+	}
+	return s
+}
+
+// mla: Fitzgerald, F. Scott. The Great Gatsby. 2nd ed., 1925. Jazz Age 2.
+func mla(b *Book, names []personName) citationText {
+	var c citationText
+	if authors := mlaNames(names); authors != "" {
+		c.add(authors, false)
+		c.period()
+		c.add(" ", false)
+	}
+	c.add(b.Title, true)
+	c.period()
+
+	var facts []string
+	if ed := editionLabel(b.Edition); ed != "" {
+		facts = append(facts, ed)
+	}
+	if b.PublicationYear != nil {
+		facts = append(facts, strconv.Itoa(*b.PublicationYear))
+	}
+	if len(facts) > 0 {
+		c.add(" "+strings.Join(facts, ", "), false)
+		c.period()
+	}
+	if b.Series != nil && b.Series.Title != "" {
+		c.add(" "+strings.TrimSpace(b.Series.Title+" "+seriesPosition(b.Series)), false)
+		c.period()
+	}
+	return c
+}
+
+// chicago: Fitzgerald, F. Scott, and Maxwell Perkins. The Great Gatsby. 2nd
+// ed. Jazz Age 2. 1925. Up to ten authors are listed; longer lists give
+// seven followed by "et al."
+func chicago(b *Book, names []personName) citationText {
+	var c citationText
+	if len(names) > 0 {
+		authors := []string{names[0].inverted()}
+		rest := names[1:]
+		if len(names) > 10 {
+			rest = names[1:7]
+		}
+		for _, n := range rest {
+			authors = append(authors, n.natural())
+		}
+		if len(names) > 10 {
+			c.add(strings.Join(authors, ", ")+", et al", false)
+		} else {
+			c.add(joinNames(authors, "and", len(authors) > 1), false)
+		}
+		c.period()
+		c.add(" ", false)
+	}
+	c.add(b.Title, true)
+	c.period()
+	if ed := editionLabel(b.Edition); ed != "" {
+		c.add(" "+ed, false)
+		c.period()
+	}
+	if b.Series != nil && b.Series.Title != "" {
+		c.add(" "+strings.TrimSpace(b.Series.Title+" "+seriesPosition(b.Series)), false)
+		c.period()
+	}
+	if b.PublicationYear != nil {
+		c.add(" "+strconv.Itoa(*b.PublicationYear), false)
+		c.period()
+	}
+	return c
+}
@@ -0,0 +1,24 @@
+package book
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// maxDescriptionLength bounds the stored description, in characters
+const maxDescriptionLength = 20000
+
+// previewDescription shortens a description to at most limit characters for
+// list views, cutting at the last word boundary. A limit of 0 or less keeps
+// the full text.
+func previewDescription(description string, limit int) string {
+	if limit <= 0 || utf8.RuneCountInString(description) <= limit {
+		return description
+	}
+	runes := []rune(description)
+	cut := string(runes[:limit])
+	if i := strings.LastIndexAny(cut, " \t\n"); i > 0 {
+		cut = cut[:i]
+	}
+	return strings.TrimRight(cut, " \t\n.,;:") + "…"
+}
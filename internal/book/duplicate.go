@@ -0,0 +1,113 @@
+package book
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"public_library/utils"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// ErrDuplicate is matched by every DuplicateError
+var ErrDuplicate = errors.New("duplicate identifier")
+
+// DuplicateError reports that an identifier is already held by another book
+type DuplicateError struct {
+	BookID     int
+	Identifier Identifier
+}
+
+func (e *DuplicateError) Error() string {
+	return fmt.Sprintf("%s %s already belongs to book %d", e.Identifier.Type, e.Identifier.Value, e.BookID)
+}
+
+func (e *DuplicateError) Is(target error) bool {
+	return target == ErrDuplicate
+}
+
+// isbnAs13 mirrors the isbn_as_13 SQL function: the ISBN-13 form of an ISBN
+// identifier, or "" for other identifier types
+func isbnAs13(id Identifier) string {
+	switch id.Type {
+	case IdentifierISBN13:
+		return id.Value
+	case IdentifierISBN10:
+		digits := "978" + id.Value[:9]
+		sum := 0
+		for i, c := range digits {
+			weight := 1
+			if i%2 == 1 {
+				weight = 3
+			}
+			sum += int(c-'0') * weight
+		}
+		return fmt.Sprintf("%s%d", digits, (10-sum%10)%10)
+	}
+	return ""
+}
+
+// isIdentifierConflict reports whether err is a violation of one of the
+// identifier uniqueness constraints
+func isIdentifierConflict(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	return pgErr.Code == "23505" || pgErr.Code == "23P01" // unique_violation, exclusion_violation
+}
+
+// findDuplicate returns a DuplicateError naming the book, other than bookID,
+// that already holds one of ids, or nil if there is none
+func (r *Repository) findDuplicate(ctx context.Context, bookID int, ids []Identifier) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	var types, values, isbns []string
+	for _, id := range ids {
+		types = append(types, id.Type)
+		values = append(values, id.Value)
+		if isbn := isbnAs13(id); isbn != "" {
+			isbns = append(isbns, isbn)
+		}
+	}
+
+	query := fmt.Sprintf(`
+		SELECT i.book_id, i.type, i.value
+		FROM %s i
+		WHERE i.book_id <> $1
+		  AND (
+			(i.type, i.value) IN (SELECT * FROM unnest($2::text[], $3::text[]))
+			OR i.normalized_isbn = ANY($4)
+		  )
+		ORDER BY i.book_id
+		LIMIT 1
+	`, utils.BookIdentifiersTable)
+
+	var dup DuplicateError
+	err := r.db.QueryRowContext(ctx, query, bookID, types, values, isbns).
+		Scan(&dup.BookID, &dup.Identifier.Type, &dup.Identifier.Value)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		log.Printf("Failed to look up duplicate identifiers: %v", err)
+		return err
+	}
+	return &dup
+}
+
+// duplicateOr resolves a constraint violation on ids into a DuplicateError
+// naming the existing book, and returns any other error unchanged
+func (r *Repository) duplicateOr(ctx context.Context, err error, bookID int, ids []Identifier) error {
+	if !isIdentifierConflict(err) {
+		return err
+	}
+	var dup *DuplicateError
+	if errors.As(r.findDuplicate(ctx, bookID, ids), &dup) {
+		return dup
+	}
+	return err
+}
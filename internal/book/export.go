@@ -0,0 +1,96 @@
+package book
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"strings"
+)
+
+// exportColumns is the CSV header of an export; exportRecord must match it
+var exportColumns = []string{
+	"id", "title", "author", "description", "isbn",
+	"call_number", "shelf_location", "collection",
+	"publication_year", "edition", "page_count", "format",
+	"identifiers", "series_id", "series_position", "audience",
+	"accessibility_features", "language", "romanized",
+}
+
+func exportRecord(b Book) []string {
+	optionalInt := func(n *int) string {
+		if n == nil {
+			return ""
+		}
+		return strconv.Itoa(*n)
+	}
+	seriesID, seriesPosition := "", ""
+	if b.Series != nil {
+		seriesID = strconv.Itoa(b.Series.ID)
+		if b.Series.Position != nil {
+			seriesPosition = strconv.FormatFloat(*b.Series.Position, 'f', -1, 64)
+		}
+	}
+	return []string{
+		strconv.Itoa(b.ID), b.Title, b.Author, b.Description, b.ISBN,
+		b.CallNumber, b.ShelfLocation, b.Collection,
+		optionalInt(b.PublicationYear), b.Edition, optionalInt(b.PageCount), b.Format,
+		formatIdentifiers(b.Identifiers), seriesID, seriesPosition, b.Audience,
+		strings.Join(b.AccessibilityFeatures, ";"), b.Language, b.Romanized,
+	}
+}
+
+const exportChunkSize = 1000
+
+// ExportBooks walks the catalog in id order starting after afterID and calls
+// fn with each chunk. Chunks are fetched with keyset pagination in separate
+// short queries, so a long export never holds a transaction open and can be
+// resumed from the last id the client received.
+func (r *Repository) ExportBooks(ctx context.Context, afterID int, fn func([]Book) error) error {
+	log.Println("<--------ExportBooks starts-------->")
+	defer log.Println("<--------ExportBooks ends-------->")
+
+	query := selectBooksSQL("b.id > $1", "b.id", "LIMIT $2")
+	cursor := afterID
+	for {
+		chunk := make([]Book, 0, exportChunkSize)
+		err := r.retrier.Do(ctx, "ExportBooks", func() error {
+			chunk = chunk[:0]
+			rows, err := r.db.QueryContext(ctx, query, cursor, exportChunkSize)
+			if err != nil {
+				return err
+			}
+			defer rows.Close()
+			for rows.Next() {
+				b, err := scanBook(rows)
+				if err != nil {
+					return err
+				}
+				chunk = append(chunk, b)
+			}
+			return rows.Err()
+		})
+		if err != nil {
+			log.Printf("Failed to export books after id=%d: %v", cursor, err)
+			return err
+		}
+		if len(chunk) == 0 {
+			return nil
+		}
+		if err := fn(chunk); err != nil {
+			return err
+		}
+		cursor = chunk[len(chunk)-1].ID
+		if len(chunk) < exportChunkSize {
+			return nil
+		}
+	}
+}
+
+// formatIdentifiers renders identifiers as "type:value;type:value"
+func formatIdentifiers(ids []Identifier) string {
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = id.Type + ":" + id.Value
+	}
+	return strings.Join(parts, ";")
+}
@@ -1,33 +1,46 @@
 package book
 
 import (
+	"encoding/csv"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"log"
 	"net/http"
-	"public_library/internal/db"
+	"net/url"
+	"public_library/internal/analytics"
+	"public_library/internal/config"
+	"public_library/internal/health"
+	"public_library/internal/hooks"
+	"public_library/internal/httperr"
 	"public_library/utils"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gorilla/mux"
 	"go.uber.org/zap"
 )
 
+// maxBulkBytes bounds the body of a bulk create
+const maxBulkBytes = 32 << 20
+
 type Handler struct {
-	repo   *Repository
-	logger *zap.Logger
-	config db.AppConfig
+	svc     *Service
+	checker *health.Checker
+	logger  *zap.Logger
+	config  config.AppConfig
 }
 
-func NewHandler(r *Repository, l *zap.Logger) *Handler {
-	return &Handler{repo: r, logger: l}
+func NewHandler(svc *Service, checker *health.Checker, cfg config.AppConfig, l *zap.Logger) *Handler {
+	return &Handler{svc: svc, checker: checker, config: cfg, logger: l}
 }
 
 // HealthCheck handles GET /health
 // Always returns 200 OK. Status can be "ok" or "degraded"
 // @Summary     Health check
-// @Description Returns server health status
+// @Description Returns server health status with the result of each dependency check (database and configured integrations)
 // @Tags        Health
 // @Accept      */*
 // @Produce     json
@@ -45,10 +58,16 @@ func (h *Handler) HealthCheck(w http.ResponseWriter, r *http.Request) {
 		Message:   utils.StatusOK,
 	}
 
-	// Check the database connection
-	if err := h.repo.db.PingContext(ctx); err != nil {
-		// Log DB ping failure and return degraded status
-		h.logger.Error("Health check: DB ping failed", zap.Error(err))
+	// Check the database and external integrations
+	checks, healthy := h.checker.Run(ctx)
+	response.Checks = checks
+	if !healthy {
+		// Log the failing checks and return degraded status
+		for _, c := range checks {
+			if c.Status != health.StatusUp {
+				h.logger.Error("Health check failed", zap.String("check", c.Name), zap.String("error", c.Error))
+			}
+		}
 		response.Status = utils.StatusDegraded
 		response.Message = utils.StatusError
 		w.WriteHeader(http.StatusOK) // Return 200 OK for degraded status
@@ -66,11 +85,58 @@ func (h *Handler) HealthCheck(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// GET /books?page=1&limit=10
+// GET /formats
+
+// ListFormats godoc
+// @Summary List media formats
+// @Description Returns the controlled format taxonomy with each format's loan period
+// @Tags books
+// @Produce json
+// @Success 200 {array} Format
+// @Router /formats [get]
+func (h *Handler) ListFormats(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.svc.Formats())
+}
+
+// GET /books?page=1&page_size=10&search=gatsby&formats=dvd,bluray&sort=author,title&order=asc,desc
+
+// ListBooks godoc
+// @Summary List all books
+// @Description Get a paginated list of all books, filtered by query parameters that mirror the POST /books/list body. List parameters take comma-separated values or repeat. A search that finds nothing returns suggestions: titles and authors close to the search, to offer as corrections.
+// @Tags books
+// @Produce      json
+// @Param        page                    query     int       false  "1-based page"
+// @Param        page_size               query     int       false  "Page size, capped by the server configuration"
+// @Param        search                  query     string    false  "Title, author, ISBN or full text"
+// @Param        author                  query     string    false  "Authors containing it, ignoring case"
+// @Param        title                   query     string    false  "Titles starting with it, ignoring case"
+// @Param        isbn                    query     string    false  "ISBN-10 or ISBN-13, matching either form"
+// @Param        sort                    query     []string  false  "Sort fields, applied in order: id, title, author, call_number or publication_year" collectionFormat(csv)
+// @Param        order                   query     []string  false  "Sort order of each sort field, asc or desc; missing ones are asc" collectionFormat(csv)
+// @Param        year_from               query     int       false  "First publication year, inclusive"
+// @Param        year_to                 query     int       false  "Last publication year, inclusive"
+// @Param        formats                 query     []string  false  "Formats" collectionFormat(csv)
+// @Param        audiences               query     []string  false  "Audiences" collectionFormat(csv)
+// @Param        accessibility_features  query     []string  false  "Accessibility features, all required" collectionFormat(csv)
+// @Success      200      {object}  PaginationResponse
+// @Failure      400      {object}  ErrorResponse
+// @Failure      500      {object}  ErrorResponse
+// @Router /books [get]
+func (h *Handler) ListBooks(w http.ResponseWriter, r *http.Request) {
+	req, err := listRequestFromQuery(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	h.listBooks(w, r, req)
+}
+
+// POST /books/list
 
 // GetBooks godoc
 // @Summary List all books
-// @Description Get a paginated list of all books
+// @Description Get a paginated list of all books. A search that finds nothing returns suggestions: titles and authors close to the search, to offer as corrections. Kept for existing clients; GET /books takes the same request as query parameters.
 // @Tags books
 // @Accept       json
 // @Produce      json
@@ -87,20 +153,90 @@ func (h *Handler) GetBooks(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, `{"error": "invalid request"}`, http.StatusBadRequest)
 		return
 	}
-	books, pageCount, totalCount, err := h.repo.ListAllBooks(r.Context(), req)
+	h.listBooks(w, r, req)
+}
+
+func (h *Handler) listBooks(w http.ResponseWriter, r *http.Request, req PaginationRequest) {
+	books, pageCount, totalCount, err := h.svc.List(r.Context(), req)
 	if err != nil {
-		h.logger.Error("failed to get books", zap.Error(err))
-		http.Error(w, "internal error", http.StatusInternalServerError)
+		h.writeError(w, "failed to get books", err)
 		return
 	}
 	var booksResponse PaginationResponse
 	booksResponse.TotalCount = totalCount
 	booksResponse.PageCount = pageCount
 	booksResponse.Data = books
+	analytics.RecordSearch(r.Context(), req.Search, totalCount)
+	if totalCount == 0 && strings.TrimSpace(req.Search) != "" {
+		// suggestions are a nicety; an empty result still goes out without them
+		if booksResponse.Suggestions, err = h.svc.Suggest(r.Context(), req.Search); err != nil {
+			h.logger.Warn("failed to suggest corrections", zap.String("search", req.Search), zap.Error(err))
+		}
+	}
+	w.Header().Add("Vary", "Accept-Language")
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(booksResponse)
 }
 
+// listRequestFromQuery reads the list request of GET /books from its query
+// parameters. Lists take comma-separated values, repeated parameters or both.
+func listRequestFromQuery(q url.Values) (PaginationRequest, error) {
+	var req PaginationRequest
+	for _, p := range []struct {
+		name string
+		dst  *int
+	}{{"page", &req.Page}, {"page_size", &req.PageSize}} {
+		if v := q.Get(p.name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return req, fmt.Errorf("%s must be a number", p.name)
+			}
+			*p.dst = n
+		}
+	}
+	for _, p := range []struct {
+		name string
+		dst  **int
+	}{{"year_from", &req.YearFrom}, {"year_to", &req.YearTo}} {
+		if v := q.Get(p.name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return req, fmt.Errorf("%s must be a year", p.name)
+			}
+			*p.dst = &n
+		}
+	}
+	req.Search = q.Get("search")
+	req.Author, req.Title, req.ISBN = q.Get("author"), q.Get("title"), q.Get("isbn")
+	fields, orders := queryList(q, "sort"), queryList(q, "order")
+	if len(orders) > len(fields) {
+		return req, errors.New("order takes one value per sort field")
+	}
+	for i, field := range fields {
+		key := Sort{Field: field}
+		if i < len(orders) {
+			key.Order = orders[i]
+		}
+		req.SortBy = append(req.SortBy, key)
+	}
+	req.Formats = queryList(q, "formats")
+	req.Audiences = queryList(q, "audiences")
+	req.AccessibilityFeatures = queryList(q, "accessibility_features")
+	return req, nil
+}
+
+func queryList(q url.Values, name string) []string {
+	var list []string
+	for _, v := range q[name] {
+		for _, s := range strings.Split(v, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				list = append(list, s)
+			}
+		}
+	}
+	return list
+}
+
 // GET /books/{id}
 
 // GetBookByID godoc
@@ -124,17 +260,125 @@ func (h *Handler) GetBookByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	book, err := h.repo.GetByID(r.Context(), id)
+	book, err := h.svc.Get(r.Context(), id)
 	if err != nil {
-		if errors.Is(err, ErrNotFound) {
-			http.Error(w, "book not found", http.StatusNotFound)
-		} else {
-			h.logger.Error("error retrieving book", zap.Error(err))
-			http.Error(w, "internal server error", http.StatusInternalServerError)
-		}
+		h.writeError(w, "error retrieving book", err)
+		return
+	}
+	analytics.RecordView(r.Context(), book.ID)
+
+	setContentLanguage(w, book.Language)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(book)
+}
+
+// GET /books/{id}/citation?format=bibtex
+
+// GetBookCitation godoc
+// @Summary Export a book's citation
+// @Description Returns the book as a BibTeX entry or RIS record, for import into Zotero, EndNote, Mendeley or a LaTeX bibliography.
+// @Tags books
+// @Produce application/x-bibtex
+// @Produce application/x-research-info-systems
+// @Param id path int true "Book ID"
+// @Param format query string false "Citation format" Enums(bibtex, ris) default(bibtex)
+// @Success 200 {string} string "Citation"
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /books/{id}/citation [get]
+func (h *Handler) GetBookCitation(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "invalid book ID", http.StatusBadRequest)
+		return
+	}
+	format := strings.ToLower(r.URL.Query().Get("format"))
+	if format == "" {
+		format = CitationBibTeX
+	}
+
+	book, err := h.svc.Get(r.Context(), id)
+	if err != nil {
+		h.writeError(w, "error retrieving book", err)
+		return
+	}
+	citation, err := Citation(book, format)
+	if err != nil {
+		h.writeError(w, "error exporting citation", err)
+		return
+	}
+
+	contentType, extension := "application/x-bibtex; charset=utf-8", "bib"
+	if format == CitationRIS {
+		contentType, extension = "application/x-research-info-systems; charset=utf-8", "ris"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="book-%d.%s"`, id, extension))
+	io.WriteString(w, citation)
+}
+
+// GET /books/{id}/cite?style=apa
+
+// GetFormattedCitation godoc
+// @Summary Cite a book
+// @Description Returns the book's reference formatted in APA (7th edition), MLA (9th edition) or Chicago (17th edition, bibliography) style, as plain text and as HTML with the title in italics. The catalog has no publishers, so they are left out.
+// @Tags books
+// @Produce json
+// @Param id path int true "Book ID"
+// @Param style query string false "Citation style" Enums(apa, mla, chicago) default(apa)
+// @Success 200 {object} FormattedCitation
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /books/{id}/cite [get]
+func (h *Handler) GetFormattedCitation(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "invalid book ID", http.StatusBadRequest)
+		return
+	}
+	style := r.URL.Query().Get("style")
+	if style == "" {
+		style = StyleAPA
+	}
+
+	book, err := h.svc.Get(r.Context(), id)
+	if err != nil {
+		h.writeError(w, "error retrieving book", err)
+		return
+	}
+	citation, err := FormatCitation(book, style)
+	if err != nil {
+		h.writeError(w, "error formatting citation", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(citation)
+}
+
+// GET /books/identifiers/{type}/{value}
+
+// GetBookByIdentifier godoc
+// @Summary Look up a book by identifier
+// @Description Finds the book carrying the given ISBN-10, ISBN-13, ISSN or OCLC number. Hyphens and spaces in the value are ignored.
+// @Tags books
+// @Produce json
+// @Param type path string true "Identifier type" Enums(isbn10, isbn13, issn, oclc)
+// @Param value path string true "Identifier value"
+// @Success 200 {object} book.Book
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /books/identifiers/{type}/{value} [get]
+func (h *Handler) GetBookByIdentifier(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	book, err := h.svc.GetByIdentifier(r.Context(), vars["type"], vars["value"])
+	if err != nil {
+		h.writeError(w, "error retrieving book by identifier", err)
 		return
 	}
 
+	setContentLanguage(w, book.Language)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(book)
 }
@@ -150,6 +394,8 @@ func (h *Handler) GetBookByID(w http.ResponseWriter, r *http.Request) {
 // @Param book body book.Book true "Book to create"
 // @Success 201 {object} book.Book
 // @Failure 400 {object} map[string]string
+// @Failure 409 {object} ConflictResponse
+// @Failure 422 {object} map[string]string
 // @Router /books/create [post]
 func (h *Handler) CreateBook(w http.ResponseWriter, r *http.Request) {
 	var b Book
@@ -157,15 +403,205 @@ func (h *Handler) CreateBook(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "invalid JSON", http.StatusBadRequest)
 		return
 	}
-	if err := h.repo.Create(r.Context(), &b); err != nil {
-		h.logger.Error("create failed", zap.Error(err))
-		http.Error(w, "create failed", http.StatusInternalServerError)
+	if err := h.svc.Create(r.Context(), &b); err != nil {
+		h.writeError(w, "create failed", err)
 		return
 	}
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(b)
 }
 
+// POST /books/import
+
+// ImportBooks godoc
+// @Summary Bulk import books from CSV
+// @Description Streams a CSV file into the catalog using COPY. The header must name title, author and isbn columns; other book fields such as call_number or publication_year are optional. The import is all-or-nothing.
+// @Tags books
+// @Accept text/csv
+// @Produce json
+// @Success 201 {object} ImportResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 409 {object} ConflictResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /books/import [post]
+func (h *Handler) ImportBooks(w http.ResponseWriter, r *http.Request) {
+	if !h.config.Features.BulkImport {
+		http.Error(w, "bulk import is disabled", http.StatusNotFound)
+		return
+	}
+	imported, err := h.svc.Import(r.Context(), r.Body)
+	if err != nil {
+		h.writeError(w, "import failed", err)
+		return
+	}
+
+	h.logger.Info("books imported", zap.Int64("count", imported))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(ImportResponse{Imported: imported})
+}
+
+// POST /books/bulk
+
+// BulkCreateBooks godoc
+// @Summary Create many books at once
+// @Description Creates up to 1000 books from an array, in one transaction. Each book is validated and checked for duplicate identifiers, in the catalog and earlier in the array, on its own: those that pass are created and listed in created with their new id, the others in errors with the reason, both by their index in the array. Returns 201 when every book was created and 200 when some were not. Needs features.bulk_import.
+// @Tags books
+// @Accept json
+// @Produce json
+// @Param books body []book.Book true "Books to create"
+// @Success 200 {object} BulkCreateResponse "Some books were not created"
+// @Success 201 {object} BulkCreateResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string "Bulk import is disabled"
+// @Failure 413 {object} map[string]string
+// @Router /books/bulk [post]
+func (h *Handler) BulkCreateBooks(w http.ResponseWriter, r *http.Request) {
+	if !h.config.Features.BulkImport {
+		http.Error(w, "bulk import is disabled", http.StatusNotFound)
+		return
+	}
+	var books []Book
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBulkBytes)).Decode(&books); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "invalid JSON: expected an array of books", http.StatusBadRequest)
+		return
+	}
+	result, err := h.svc.BulkCreate(r.Context(), books)
+	if err != nil {
+		h.writeError(w, "bulk create failed", err)
+		return
+	}
+
+	h.logger.Info("books created in bulk", zap.Int("created", len(result.Created)), zap.Int("failed", len(result.Errors)))
+	status := http.StatusCreated
+	if len(result.Errors) > 0 {
+		status = http.StatusOK
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(result)
+}
+
+// POST /books/bulk-delete
+
+// BulkDeleteBooks godoc
+// @Summary Delete many books at once
+// @Description Deletes the books whose ids are in the array, up to 1000, in one transaction, and returns the ids deleted, those of books that did not exist and, as conflict, those of books kept because a copy of theirs has loans. A delete hook vetoing any of the books deletes none of them.
+// @Tags books
+// @Accept json
+// @Produce json
+// @Param ids body []int true "IDs of the books to delete"
+// @Success 200 {object} BulkDeleteResponse
+// @Failure 400 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Router /books/bulk-delete [post]
+func (h *Handler) BulkDeleteBooks(w http.ResponseWriter, r *http.Request) {
+	var ids []int
+	if err := json.NewDecoder(r.Body).Decode(&ids); err != nil {
+		http.Error(w, "invalid JSON: expected an array of book IDs", http.StatusBadRequest)
+		return
+	}
+	result, err := h.svc.BulkDelete(r.Context(), ids)
+	if err != nil {
+		h.writeError(w, "bulk delete failed", err)
+		return
+	}
+
+	h.logger.Info("books deleted in bulk", zap.Int("deleted", len(result.Deleted)), zap.Int("not_found", len(result.NotFound)),
+		zap.Int("conflict", len(result.Conflict)))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// GET /books/export?after_id=0
+
+// ExportBooks godoc
+// @Summary Export the catalog as CSV
+// @Description Streams every book as CSV (one column per book field, id first) in id order. An interrupted download can be resumed by passing the id of the last complete row as after_id; resumed downloads omit the header so they can be appended to the partial file.
+// @Tags books
+// @Produce text/csv
+// @Param after_id query int false "Resume after this book ID"
+// @Success 200 {string} string "CSV stream"
+// @Failure 400 {object} map[string]string
+// @Router /books/export [get]
+func (h *Handler) ExportBooks(w http.ResponseWriter, r *http.Request) {
+	if !h.config.Features.Export {
+		http.Error(w, "export is disabled", http.StatusNotFound)
+		return
+	}
+	afterID := 0
+	if v := r.URL.Query().Get("after_id"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil || id < 0 {
+			http.Error(w, "invalid after_id", http.StatusBadRequest)
+			return
+		}
+		afterID = id
+	}
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", `attachment; filename="books.csv"`)
+	flusher, _ := w.(http.Flusher)
+
+	cw := csv.NewWriter(w)
+	if afterID == 0 {
+		cw.Write(exportColumns)
+	}
+
+	exported := 0
+	err := h.svc.Export(r.Context(), afterID, func(chunk []Book) error {
+		for _, b := range chunk {
+			if err := cw.Write(exportRecord(b)); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		if flusher != nil {
+			flusher.Flush()
+		}
+		exported += len(chunk)
+		return cw.Error()
+	})
+	cw.Flush()
+	if err != nil {
+		// Headers are already sent; the client resumes from its last complete row
+		h.logger.Error("export aborted", zap.Int("after_id", afterID), zap.Int("exported", exported), zap.Error(err))
+		return
+	}
+	h.logger.Info("export finished", zap.Int("after_id", afterID), zap.Int("exported", exported))
+}
+
+// GET /books/shelf-report?from=800&to=899
+
+// GetShelfReport godoc
+// @Summary Items by shelf range
+// @Description Lists the books whose call numbers fall between from and to (inclusive, natural call number order), grouped by shelf location
+// @Tags books
+// @Produce json
+// @Param from query string true "First call number in the range" example(800)
+// @Param to query string true "Last call number in the range; call numbers starting with it are included" example(899)
+// @Param collection query string false "Only include this collection"
+// @Success 200 {object} ShelfReport
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /books/shelf-report [get]
+func (h *Handler) GetShelfReport(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	report, err := h.svc.ShelfReport(r.Context(), q.Get("from"), q.Get("to"), q.Get("collection"))
+	if err != nil {
+		h.writeError(w, "failed to build shelf report", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
 // PUT /books/{id}
 
 // UpdateBook godoc
@@ -178,6 +614,8 @@ func (h *Handler) CreateBook(w http.ResponseWriter, r *http.Request) {
 // @Success 200 {object} book.Book
 // @Failure 400 {object} map[string]string
 // @Failure 404 {object} map[string]string
+// @Failure 409 {object} ConflictResponse
+// @Failure 422 {object} map[string]string
 // @Router /books/{id} [put]
 func (h *Handler) UpdateBook(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
@@ -189,12 +627,46 @@ func (h *Handler) UpdateBook(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	b.ID = id
+	if err := h.svc.Update(r.Context(), &b); err != nil {
+		h.writeError(w, "update failed", err)
+		return
+	}
+	json.NewEncoder(w).Encode(b)
+}
 
-	if err := h.repo.Update(r.Context(), &b); err != nil {
-		h.logger.Warn("update failed", zap.Error(err))
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+// PATCH /books/{id}
+
+// PatchBook godoc
+// @Summary Update some fields of a book
+// @Description Applies a JSON merge patch to the book: the fields in the body replace the book's, null clears them, and fields left out keep their values, so clients need not resend the whole book. At least one field other than id is required. An isbn sent without identifiers replaces the primary ISBN. The patch applies to the book as catalogued, whatever the Accept-Language.
+// @Tags books
+// @Accept json
+// @Produce json
+// @Param id path int true "Book ID"
+// @Param patch body book.Book true "Fields to change"
+// @Success 200 {object} book.Book
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} ConflictResponse
+// @Failure 422 {object} map[string]string
+// @Router /books/{id} [patch]
+func (h *Handler) PatchBook(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "invalid book ID", http.StatusBadRequest)
+		return
+	}
+	var p Patch
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	b, err := h.svc.Patch(r.Context(), id, p)
+	if err != nil {
+		h.writeError(w, "patch failed", err)
 		return
 	}
+	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(b)
 }
 
@@ -202,21 +674,152 @@ func (h *Handler) UpdateBook(w http.ResponseWriter, r *http.Request) {
 
 // DeleteBook godoc
 // @Summary Delete a book
+// @Description Deletes the book with its copies. A book with copies that have been lent can't be deleted; withdraw its copies instead.
 // @Tags books
 // @Accept json
 // @Produce json
 // @Param id path int true "Book ID"
 // @Success 204 "No Content"
 // @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 422 {object} map[string]string
 // @Router /books/{id} [delete]
 func (h *Handler) DeleteBook(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id, _ := strconv.Atoi(vars["id"])
 
-	if err := h.repo.Delete(r.Context(), id); err != nil {
-		h.logger.Warn("delete failed", zap.Error(err))
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if err := h.svc.Delete(r.Context(), id); err != nil {
+		h.writeError(w, "delete failed", err)
 		return
 	}
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// GET /books/{id}/translations
+
+// ListTranslations godoc
+// @Summary List a book's translations
+// @Tags books
+// @Produce json
+// @Param id path int true "Book ID"
+// @Success 200 {array} Translation
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /books/{id}/translations [get]
+func (h *Handler) ListTranslations(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "invalid book ID", http.StatusBadRequest)
+		return
+	}
+	translations, err := h.svc.ListTranslations(r.Context(), id)
+	if err != nil {
+		h.writeError(w, "error listing translations", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(translations)
+}
+
+// PUT /books/{id}/translations/{language}
+
+// PutTranslation godoc
+// @Summary Add or replace a translation
+// @Description Stores the book's title and description in a language, given as a BCP 47 tag such as "fr" or "pt-BR". Reads with an Accept-Language header return the best matching translation, or the book as catalogued when none matches better.
+// @Tags books
+// @Accept json
+// @Produce json
+// @Param id path int true "Book ID"
+// @Param language path string true "BCP 47 language tag"
+// @Param translation body Translation true "Translated title and description"
+// @Success 200 {object} Translation
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /books/{id}/translations/{language} [put]
+func (h *Handler) PutTranslation(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "invalid book ID", http.StatusBadRequest)
+		return
+	}
+	var t Translation
+	if err := json.NewDecoder(r.Body).Decode(&t); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	t.Language = vars["language"]
+	if err := h.svc.PutTranslation(r.Context(), id, &t); err != nil {
+		h.writeError(w, "error saving translation", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(t)
+}
+
+// DELETE /books/{id}/translations/{language}
+
+// DeleteTranslation godoc
+// @Summary Delete a translation
+// @Tags books
+// @Param id path int true "Book ID"
+// @Param language path string true "BCP 47 language tag"
+// @Success 204 "No Content"
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /books/{id}/translations/{language} [delete]
+func (h *Handler) DeleteTranslation(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "invalid book ID", http.StatusBadRequest)
+		return
+	}
+	if err := h.svc.DeleteTranslation(r.Context(), id, vars["language"]); err != nil {
+		h.writeError(w, "error deleting translation", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// setContentLanguage labels a book response, which Accept-Language may have
+// translated
+func setContentLanguage(w http.ResponseWriter, lang string) {
+	w.Header().Add("Vary", "Accept-Language")
+	if lang != "" {
+		w.Header().Set("Content-Language", lang)
+	}
+}
+
+// writeError answers err, mapping this package's errors before falling back
+// to the shared database error translation
+func (h *Handler) writeError(w http.ResponseWriter, msg string, err error) {
+	switch {
+	case writeDuplicate(w, err):
+	case errors.Is(err, ErrTranslationNotFound):
+		http.Error(w, "translation not found", http.StatusNotFound)
+	case errors.Is(err, ErrNotFound):
+		http.Error(w, "book not found", http.StatusNotFound)
+	case errors.Is(err, ErrHasLoans):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, ErrValidation), errors.Is(err, ErrInvalidSort), errors.Is(err, ErrInvalidImport):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, hooks.ErrVetoed):
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+	default:
+		httperr.Write(w, h.logger, msg, err)
+	}
+}
+
+// writeDuplicate answers 409 Conflict with the existing book's ID if err is a
+// *DuplicateError, and reports whether it did
+func writeDuplicate(w http.ResponseWriter, err error) bool {
+	var dup *DuplicateError
+	if !errors.As(err, &dup) {
+		return false
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusConflict)
+	json.NewEncoder(w).Encode(ConflictResponse{Error: dup.Error(), ExistingID: dup.BookID})
+	return true
+}
@@ -0,0 +1,168 @@
+package book
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"public_library/internal/db"
+	"public_library/utils"
+	"sort"
+	"strings"
+)
+
+// Identifier types a book can carry
+const (
+	IdentifierISBN10 = "isbn10"
+	IdentifierISBN13 = "isbn13"
+	IdentifierISSN   = "issn"
+	IdentifierOCLC   = "oclc"
+)
+
+// NormalizeIdentifier canonicalizes value for its type (hyphens, spaces and
+// prefixes stripped, check character upper-cased) and checks its shape.
+func NormalizeIdentifier(idType, value string) (Identifier, error) {
+	idType = strings.ToLower(strings.TrimSpace(idType))
+	v := strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(value))
+
+	valid := false
+	switch idType {
+	case IdentifierISBN10:
+		valid = len(v) == 10 && isDigits(v[:9]) && (isDigits(v[9:]) || v[9] == 'X')
+	case IdentifierISBN13:
+		valid = len(v) == 13 && isDigits(v)
+	case IdentifierISSN:
+		valid = len(v) == 8 && isDigits(v[:7]) && (isDigits(v[7:]) || v[7] == 'X')
+	case IdentifierOCLC:
+		for _, prefix := range []string{"(OCOLC)", "OCM", "OCN", "ON"} {
+			v = strings.TrimPrefix(v, prefix)
+		}
+		valid = v != "" && isDigits(v)
+	default:
+		return Identifier{}, fmt.Errorf("%w: unknown identifier type %q", ErrValidation, idType)
+	}
+	if !valid {
+		return Identifier{}, fmt.Errorf("%w: %q is not a valid %s", ErrValidation, value, idType)
+	}
+	return Identifier{Type: idType, Value: v}, nil
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// isbnIdentifier types a bare ISBN by its length
+func isbnIdentifier(isbn string) (Identifier, error) {
+	idType := IdentifierISBN13
+	if len(strings.NewReplacer("-", "", " ", "").Replace(isbn)) == 10 {
+		idType = IdentifierISBN10
+	}
+	return NormalizeIdentifier(idType, isbn)
+}
+
+// identifierSet returns the book's normalized, de-duplicated identifiers,
+// including the convenience ISBN field if it is set.
+func (b *Book) identifierSet() ([]Identifier, error) {
+	seen := make(map[Identifier]bool)
+	var set []Identifier
+	add := func(id Identifier) {
+		if !seen[id] {
+			seen[id] = true
+			set = append(set, id)
+		}
+	}
+
+	if strings.TrimSpace(b.ISBN) != "" {
+		id, err := isbnIdentifier(b.ISBN)
+		if err != nil {
+			return nil, err
+		}
+		add(id)
+	}
+	for _, raw := range b.Identifiers {
+		id, err := NormalizeIdentifier(raw.Type, raw.Value)
+		if err != nil {
+			return nil, err
+		}
+		add(id)
+	}
+	sort.Slice(set, func(i, j int) bool {
+		if set[i].Type != set[j].Type {
+			return set[i].Type < set[j].Type
+		}
+		return set[i].Value < set[j].Value
+	})
+	return set, nil
+}
+
+// primaryISBN picks the ISBN shown in the flat isbn field, preferring ISBN-13
+func primaryISBN(ids []Identifier) string {
+	isbn := ""
+	for _, id := range ids {
+		switch id.Type {
+		case IdentifierISBN13:
+			return id.Value
+		case IdentifierISBN10:
+			if isbn == "" {
+				isbn = id.Value
+			}
+		}
+	}
+	return isbn
+}
+
+// identifierList scans the JSON array aggregated by selectBooksSQL
+type identifierList []Identifier
+
+func (l *identifierList) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*l = identifierList{}
+		return nil
+	case []byte:
+		return json.Unmarshal(v, l)
+	case string:
+		return json.Unmarshal([]byte(v), l)
+	default:
+		return fmt.Errorf("cannot scan %T into identifiers", src)
+	}
+}
+
+// replaceIdentifiers makes ids the complete identifier set of bookID
+func replaceIdentifiers(ctx context.Context, q db.Queryer, bookID int, ids []Identifier) error {
+	deleteQuery := fmt.Sprintf(`DELETE FROM %s WHERE book_id = $1`, utils.BookIdentifiersTable)
+	if _, err := q.ExecContext(ctx, deleteQuery, bookID); err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	values := make([]string, len(ids))
+	args := []interface{}{bookID}
+	for i, id := range ids {
+		args = append(args, id.Type, id.Value)
+		values[i] = fmt.Sprintf("($1, $%d, $%d)", len(args)-1, len(args))
+	}
+	insertQuery := fmt.Sprintf(`INSERT INTO %s (book_id, type, value) VALUES %s`,
+		utils.BookIdentifiersTable, strings.Join(values, ", "))
+	_, err := q.ExecContext(ctx, insertQuery, args...)
+	return err
+}
+
+// EAN returns the book's ISBN in ISBN-13 form, as barcodes and EDI trade
+// messages carry it, or "" if the book has no ISBN
+func (b *Book) EAN() string {
+	for _, idType := range []string{IdentifierISBN13, IdentifierISBN10} {
+		for _, id := range b.Identifiers {
+			if id.Type == idType {
+				return isbnAs13(id)
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,221 @@
+package book
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"public_library/utils"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+)
+
+// csvBookSource streams rows from a CSV body straight into COPY without
+// buffering the whole file. The first record must be a header naming the
+// title, author and isbn columns (in any order); every other writable book
+// column (call_number, publication_year, format, ...) is optional.
+type csvBookSource struct {
+	reader  *csv.Reader
+	columns map[string]int
+	line    int
+	current []interface{}
+	err     error
+}
+
+func newCSVBookSource(r io.Reader) (*csvBookSource, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading CSV header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"title", "author", "isbn"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("CSV header is missing required column %q", required)
+		}
+	}
+
+	return &csvBookSource{reader: reader, columns: columns, line: 1}, nil
+}
+
+func (s *csvBookSource) Next() bool {
+	if s.err != nil {
+		return false
+	}
+	record, err := s.reader.Read()
+	if errors.Is(err, io.EOF) {
+		return false
+	}
+	s.line++
+	if err != nil {
+		s.err = fmt.Errorf("line %d: %w", s.line, err)
+		return false
+	}
+
+	field := func(name string) string {
+		if i, ok := s.columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+	intField := func(name string) (*int, error) {
+		v := field(name)
+		if v == "" {
+			return nil, nil
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be a number", name)
+		}
+		return &n, nil
+	}
+
+	b := Book{
+		Title:         field("title"),
+		Author:        field("author"),
+		Description:   field("description"),
+		ISBN:          field("isbn"),
+		CallNumber:    field("call_number"),
+		ShelfLocation: field("shelf_location"),
+		Collection:    field("collection"),
+		Edition:       field("edition"),
+		Format:        field("format"),
+		Audience:      field("audience"),
+		Language:      field("language"),
+		Romanized:     field("romanized"),
+
+		AccessibilityFeatures: parseAccessibilityFeatures(field("accessibility_features")),
+	}
+	if b.Title == "" || b.Author == "" || b.ISBN == "" {
+		s.err = fmt.Errorf("line %d: title, author and isbn are required", s.line)
+		return false
+	}
+	if b.PublicationYear, err = intField("publication_year"); err != nil {
+		s.err = fmt.Errorf("line %d: %w", s.line, err)
+		return false
+	}
+	if b.PageCount, err = intField("page_count"); err != nil {
+		s.err = fmt.Errorf("line %d: %w", s.line, err)
+		return false
+	}
+	if err := b.Validate(); err != nil {
+		s.err = fmt.Errorf("line %d: %w", s.line, err)
+		return false
+	}
+	isbn, err := isbnIdentifier(b.ISBN)
+	if err != nil {
+		s.err = fmt.Errorf("line %d: %w", s.line, err)
+		return false
+	}
+	s.current = append(bookWriteValues(&b), isbn.Type, isbn.Value)
+	return true
+}
+
+func (s *csvBookSource) Values() ([]interface{}, error) {
+	return s.current, nil
+}
+
+func (s *csvBookSource) Err() error {
+	return s.err
+}
+
+// ImportBooks loads every row from src with a single COPY FROM into a staging
+// table, then moves the books and their ISBNs into place in one transaction.
+// The import is all-or-nothing: a malformed row or duplicate identifier
+// aborts it and nothing is inserted. An ISBN already in the catalog is
+// reported as a *DuplicateError.
+func (r *Repository) ImportBooks(ctx context.Context, src pgx.CopyFromSource) (int64, error) {
+	log.Println("<--------ImportBooks starts-------->")
+	defer log.Println("<--------ImportBooks ends-------->")
+
+	conn, err := r.db.Conn(ctx)
+	if err != nil {
+		log.Printf("Failed to acquire connection for import: %v", err)
+		return 0, err
+	}
+	defer conn.Close()
+
+	var copied int64
+	err = conn.Raw(func(driverConn interface{}) error {
+		pgxConn, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			return errors.New("bulk import requires the pgx driver")
+		}
+		tx, err := pgxConn.Conn().Begin(ctx)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback(ctx)
+
+		// The staging table inherits the books id default, so every staged
+		// row is assigned its final id before it is copied across.
+		staging := fmt.Sprintf(`
+			CREATE TEMP TABLE book_import (
+				LIKE %s INCLUDING DEFAULTS,
+				isbn_type TEXT NOT NULL,
+				isbn TEXT NOT NULL
+			) ON COMMIT DROP
+		`, utils.BooksTable)
+		if _, err := tx.Exec(ctx, staging); err != nil {
+			return err
+		}
+
+		copied, err = tx.CopyFrom(ctx,
+			pgx.Identifier{"book_import"},
+			append(append([]string{}, bookWriteColumns...), "isbn_type", "isbn"),
+			src,
+		)
+		if err != nil {
+			return err
+		}
+
+		// Report the first ISBN that is already in the catalog by the book
+		// holding it, rather than as a bare constraint violation
+		findDuplicate := fmt.Sprintf(`
+			SELECT i.book_id, i.type, i.value
+			FROM %s i
+			JOIN book_import s ON i.normalized_isbn = isbn_as_13(s.isbn_type, s.isbn)
+			ORDER BY i.book_id
+			LIMIT 1
+		`, utils.BookIdentifiersTable)
+		var dup DuplicateError
+		err = tx.QueryRow(ctx, findDuplicate).Scan(&dup.BookID, &dup.Identifier.Type, &dup.Identifier.Value)
+		if err == nil {
+			return &dup
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return err
+		}
+
+		columns := strings.Join(bookWriteColumns, ", ")
+		moveBooks := fmt.Sprintf(`INSERT INTO %s (id, %s) SELECT id, %s FROM book_import`,
+			utils.BooksTable, columns, columns)
+		if _, err := tx.Exec(ctx, moveBooks); err != nil {
+			return err
+		}
+		moveISBNs := fmt.Sprintf(`INSERT INTO %s (book_id, type, value) SELECT id, isbn_type, isbn FROM book_import`,
+			utils.BookIdentifiersTable)
+		if _, err := tx.Exec(ctx, moveISBNs); err != nil {
+			return err
+		}
+		return tx.Commit(ctx)
+	})
+	if err != nil {
+		log.Printf("Failed to import books: %v", err)
+		return 0, err
+	}
+	r.InvalidateLists()
+	return copied, nil
+}
@@ -0,0 +1,97 @@
+package book_test
+
+import (
+	"context"
+	"fmt"
+	"public_library/internal/book"
+	"public_library/pkg/fixtures"
+	"public_library/pkg/testutil"
+	"testing"
+	"time"
+)
+
+// TestListSearchPagination pages through a search combined with filters and
+// checks every page against the total
+func TestListSearchPagination(t *testing.T) {
+	h := testutil.New(t)
+	ctx := context.Background()
+
+	// 12 matching books, and books left out by the search, the author filter
+	// or the year filter
+	for i := 0; i < 17; i++ {
+		year := 1990 + i%2*20
+		b := book.Book{
+			Title:           fmt.Sprintf("Harbor Lights %02d", i),
+			Author:          "Ada Marsh",
+			PublicationYear: &year,
+			Format:          "paperback",
+			Audience:        "adult",
+		}
+		switch {
+		case i >= 14:
+			b.Title = fmt.Sprintf("Quiet Fields %02d", i)
+		case i >= 12:
+			b.Author = "Ben Ward"
+		}
+		if err := h.Books.Create(ctx, &b); err != nil {
+			t.Fatal(err)
+		}
+	}
+	from := 1980
+	req := book.PaginationRequest{Search: "harbor", Author: "marsh", YearFrom: &from, PageSize: 5}
+
+	seen := map[int]bool{}
+	for page, wantLen := range []int{5, 5, 2, 0} {
+		req.Page = page + 1
+		books, count, total, err := h.Books.List(ctx, req)
+		if err != nil {
+			t.Fatalf("page %d: %v", req.Page, err)
+		}
+		if total != 12 {
+			t.Errorf("page %d: total = %d, want 12", req.Page, total)
+		}
+		if int(count) != wantLen || len(books) != wantLen {
+			t.Errorf("page %d: got %d books (count %d), want %d", req.Page, len(books), count, wantLen)
+		}
+		for _, b := range books {
+			if seen[b.ID] {
+				t.Errorf("page %d: book %d was on an earlier page", req.Page, b.ID)
+			}
+			seen[b.ID] = true
+		}
+	}
+
+	// A year filter after the search narrows the total, not just the page
+	to := 2000
+	req = book.PaginationRequest{Search: "harbor", Author: "marsh", YearTo: &to, Page: 2, PageSize: 4}
+	books, _, total, err := h.Books.List(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 6 || len(books) != 2 {
+		t.Errorf("got %d books of %d, want 2 of 6", len(books), total)
+	}
+}
+
+// BenchmarkList measures a search with filters against a generated catalog
+// of 500 books, skipping the list cache:
+//
+//	go test ./internal/book -run '^$' -bench '^BenchmarkList$'
+func BenchmarkList(b *testing.B) {
+	h := testutil.New(b)
+	ctx := context.Background()
+	for _, bk := range fixtures.New(1, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)).Books(500) {
+		if err := h.Books.Create(ctx, &bk); err != nil {
+			b.Fatal(err)
+		}
+	}
+	from := 1950
+	req := book.PaginationRequest{Search: "the", YearFrom: &from, Page: 2, PageSize: 20}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, _, _, err := h.Books.List(ctx, req); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -1,30 +1,127 @@
 package book
 
+import "public_library/internal/health"
+
 type Book struct {
-	ID     int    `json:"id" example:"1"`
-	Title  string `json:"title" example:"The Great Gatsby"`
-	Author string `json:"author" example:"F. Scott Fitzgerald"`
-	ISBN   string `json:"isbn" example:"9780743273565"`
+	ID          int    `json:"id" example:"1"`
+	Title       string `json:"title" example:"The Great Gatsby"`
+	Author      string `json:"author" example:"F. Scott Fitzgerald"`
+	Description string `json:"description" example:"A portrait of the Jazz Age on Long Island."`
+	// ISBN is the primary ISBN (ISBN-13 preferred). On write it is added to
+	// Identifiers, typed by its length.
+	ISBN            string       `json:"isbn" example:"9780743273565"`
+	Identifiers     []Identifier `json:"identifiers"`
+	CallNumber      string       `json:"call_number" example:"813.52 FIT"`
+	ShelfLocation   string       `json:"shelf_location" example:"2F-A12"`
+	Collection      string       `json:"collection" example:"Adult Fiction"`
+	PublicationYear *int         `json:"publication_year,omitempty" example:"1925"`
+	Edition         string       `json:"edition" example:"1st"`
+	PageCount       *int         `json:"page_count,omitempty" example:"180"`
+	Format          string       `json:"format" example:"hardcover" enums:"hardcover,paperback,large_print,audiobook_cd,audiobook,ebook,dvd,bluray,magazine"`
+	Series          *SeriesRef   `json:"series,omitempty"`
+	Audience        string       `json:"audience" example:"adult" enums:"children,teen,adult"`
+	// AccessibilityFeatures uses the schema.org accessibilityFeature terms
+	AccessibilityFeatures []string `json:"accessibility_features" example:"largePrint,dyslexiaFriendly"`
+	// Language is the BCP 47 tag of Title and Description. On read it names
+	// the translation chosen by Accept-Language, if any.
+	Language string `json:"language" example:"en"`
+	// Romanized is the title and author in Latin script, such as the pinyin
+	// of a Chinese title, for searches typed without the original script
+	Romanized string `json:"romanized" example:"Hong lou meng Cao Xueqin"`
+
+	// translations are loaded with the book to localize it
+	translations []Translation
+}
+
+// SeriesRef places a book in a series. Clients write id and position;
+// title and label are filled in on read.
+type SeriesRef struct {
+	ID       int      `json:"id" example:"3"`
+	Position *float64 `json:"position,omitempty" example:"2"`
+	Title    string   `json:"title,omitempty" example:"The Lord of the Rings"`
+	Label    string   `json:"label,omitempty" example:"The Lord of the Rings #2"`
+}
+
+// Identifier is one typed identifier of a book; each type+value pair is
+// unique across the catalog
+type Identifier struct {
+	Type  string `json:"type" example:"isbn13"` // isbn10, isbn13, issn or oclc
+	Value string `json:"value" example:"9780743273565"`
+}
+
+// Translation is a book's title and description in another language
+type Translation struct {
+	Language    string `json:"language" example:"fr"` // BCP 47 tag, from the path on write
+	Title       string `json:"title" example:"Gatsby le Magnifique"`
+	Description string `json:"description" example:"Un portrait de l'ère du jazz à Long Island."`
 }
 
 // PaginationRequest represents a request for paginated data with search
 type PaginationRequest struct {
-	Page     int    `json:"page"`
-	PageSize int    `json:"page_size"`
-	Search   string `json:"search"`
+	Page     int    `json:"page" example:"1"`                 // 1-based; defaults to 1
+	PageSize int    `json:"page_size" example:"10"`           // defaults to and is capped by the server configuration
+	Search   string `json:"search" example:"gatsby jazz age"` // title, author, ISBN or full text
+	// Author, Title and ISBN filter on one field each, unlike Search:
+	// authors containing Author, titles starting with Title (both ignoring
+	// case) and books carrying ISBN, in its ISBN-10 or ISBN-13 form
+	Author string `json:"author,omitempty" example:"tolkien"`
+	Title  string `json:"title,omitempty" example:"the lord"`
+	ISBN   string `json:"isbn,omitempty" example:"9780618640157"`
+	// Sort is a single sort key; SortBy adds more, applied after it in order
+	Sort      *Sort    `json:"sort,omitempty"`
+	SortBy    []Sort   `json:"sort_by,omitempty"`
+	YearFrom  *int     `json:"year_from,omitempty" example:"1900"` // inclusive
+	YearTo    *int     `json:"year_to,omitempty" example:"1950"`   // inclusive
+	Formats   []string `json:"formats,omitempty" example:"dvd,bluray"`
+	Audiences []string `json:"audiences,omitempty" example:"children,teen"`
+	// AccessibilityFeatures matches books that have every listed feature
+	AccessibilityFeatures []string `json:"accessibility_features,omitempty" example:"braille"`
+}
+
+// sortKeys returns the sort keys of the request, Sort first
+func (req PaginationRequest) sortKeys() []Sort {
+	if req.Sort == nil {
+		return req.SortBy
+	}
+	return append([]Sort{*req.Sort}, req.SortBy...)
 }
 
 // Sort represents sorting options for queries
 type Sort struct {
-	Field string `json:"field"`
-	Order string `json:"order"` // "asc" or "desc"
+	Field string `json:"field" example:"call_number"` // "id", "title", "author", "call_number" or "publication_year"
+	Order string `json:"order" example:"asc"`         // "asc" or "desc"
 }
 
 type BookResponse struct {
 	ID     int    `json:"id" example:"1"`
 	Title  string `json:"title" example:"The Great Gatsby"`
 	Author string `json:"author" example:"F. Scott Fitzgerald"`
-	ISBN   string `json:"isbn" example:"9780743273565"`
+	// Description is shortened in list views; detail views return it in full.
+	Description string `json:"description" example:"A portrait of the Jazz Age on Long Island."`
+	// ISBN is the primary ISBN (ISBN-13 preferred). On write it is added to
+	// Identifiers, typed by its length.
+	ISBN            string       `json:"isbn" example:"9780743273565"`
+	Identifiers     []Identifier `json:"identifiers"`
+	CallNumber      string       `json:"call_number" example:"813.52 FIT"`
+	ShelfLocation   string       `json:"shelf_location" example:"2F-A12"`
+	Collection      string       `json:"collection" example:"Adult Fiction"`
+	PublicationYear *int         `json:"publication_year,omitempty" example:"1925"`
+	Edition         string       `json:"edition" example:"1st"`
+	PageCount       *int         `json:"page_count,omitempty" example:"180"`
+	Format          string       `json:"format" example:"hardcover" enums:"hardcover,paperback,large_print,audiobook_cd,audiobook,ebook,dvd,bluray,magazine"`
+	Series          *SeriesRef   `json:"series,omitempty"`
+	Audience        string       `json:"audience" example:"adult" enums:"children,teen,adult"`
+	// AccessibilityFeatures uses the schema.org accessibilityFeature terms
+	AccessibilityFeatures []string `json:"accessibility_features" example:"largePrint,dyslexiaFriendly"`
+	// Language is the BCP 47 tag of Title and Description. On read it names
+	// the translation chosen by Accept-Language, if any.
+	Language string `json:"language" example:"en"`
+	// Romanized is the title and author in Latin script, such as the pinyin
+	// of a Chinese title, for searches typed without the original script
+	Romanized string `json:"romanized" example:"Hong lou meng Cao Xueqin"`
+
+	// translations are loaded with the book to localize it
+	translations []Translation
 }
 
 // PaginationResponse represents a paginated response
@@ -32,6 +129,9 @@ type PaginationResponse struct {
 	TotalCount int64       `json:"total_count"`
 	PageCount  int64       `json:"page_count"`
 	Data       interface{} `json:"data"`
+	// Suggestions are titles and authors close to a search that found
+	// nothing, most similar first, to offer as corrections
+	Suggestions []string `json:"suggestions,omitempty" example:"The Great Gatsby,F. Scott Fitzgerald"`
 }
 
 // StatusResponse represents the health check response
@@ -40,9 +140,76 @@ type StatusResponse struct {
 	Version   string `json:"version"`
 	Timestamp string `json:"timestamp"`
 	Message   string `json:"message"`
+	// Checks lists each dependency check; failures make Status "degraded"
+	Checks []health.Result `json:"checks,omitempty"`
 }
 
 // ErrorResponse represents a standard error response
 type ErrorResponse struct {
 	Error string `json:"error"`
 }
+
+// ConflictResponse is returned when a write would duplicate an identifier
+// already held by another book
+type ConflictResponse struct {
+	Error      string `json:"error" example:"isbn13 9780743273565 already belongs to book 42"`
+	ExistingID int    `json:"existing_id" example:"42"`
+}
+
+// ImportResponse reports the outcome of a bulk import
+type ImportResponse struct {
+	Imported int64 `json:"imported" example:"500000"`
+}
+
+// BulkCreateResponse reports the outcome of a bulk create by each book's
+// position in the request
+type BulkCreateResponse struct {
+	Created []BulkCreated `json:"created"`
+	Errors  []BulkError   `json:"errors"`
+}
+
+// BulkCreated is a book of a bulk create that was created
+type BulkCreated struct {
+	Index int `json:"index" example:"0"`
+	ID    int `json:"id" example:"1043"`
+}
+
+// BulkError is a book of a bulk create that was not created, and why
+type BulkError struct {
+	Index int    `json:"index" example:"3"`
+	Error string `json:"error" example:"validation failed: page_count must be between 1 and 100000"`
+	// ExistingID is the book that already holds a duplicate identifier
+	ExistingID int `json:"existing_id,omitempty" example:"42"`
+}
+
+// BulkDeleteResponse reports which books of a bulk delete were deleted,
+// which did not exist and which were kept because a copy of theirs has
+// loans, each by ascending id
+type BulkDeleteResponse struct {
+	Deleted  []int `json:"deleted" example:"12,31"`
+	NotFound []int `json:"not_found" example:"99"`
+	Conflict []int `json:"conflict" example:"7"`
+}
+
+// ShelfItem is one book in a shelf range report
+type ShelfItem struct {
+	ID         int    `json:"id" example:"1"`
+	Title      string `json:"title" example:"The Great Gatsby"`
+	Author     string `json:"author" example:"F. Scott Fitzgerald"`
+	CallNumber string `json:"call_number" example:"813.52 FIT"`
+	Collection string `json:"collection" example:"Adult Fiction"`
+}
+
+// ShelfGroup holds the items on one shelf, in call number order
+type ShelfGroup struct {
+	ShelfLocation string      `json:"shelf_location" example:"2F-A12"`
+	Items         []ShelfItem `json:"items"`
+}
+
+// ShelfReport lists the items whose call numbers fall in a range, by shelf
+type ShelfReport struct {
+	From       string       `json:"from" example:"800"`
+	To         string       `json:"to" example:"899"`
+	TotalItems int          `json:"total_items" example:"312"`
+	Shelves    []ShelfGroup `json:"shelves"`
+}
@@ -0,0 +1,64 @@
+package book
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// Patch is a partial update of a book, as a JSON merge patch (RFC 7396) of
+// its top-level fields: the fields present replace the book's, null clearing
+// them, and the fields left out keep their values
+type Patch map[string]json.RawMessage
+
+// Validate checks that the patch changes at least one field and leaves the
+// id alone. Unknown fields are caught by Apply.
+func (p Patch) Validate() error {
+	if _, ok := p["id"]; ok {
+		return fmt.Errorf("%w: id cannot be changed", ErrValidation)
+	}
+	if len(p) == 0 {
+		return fmt.Errorf("%w: patch must set at least one field", ErrValidation)
+	}
+	return nil
+}
+
+// Apply returns a copy of b with the patch applied. An isbn patched without
+// identifiers replaces the book's primary ISBN rather than adding to it.
+func (p Patch) Apply(b Book) (*Book, error) {
+	if _, ok := p["identifiers"]; !ok && b.ISBN != "" {
+		if _, ok := p["isbn"]; ok {
+			var ids []Identifier
+			for _, id := range b.Identifiers {
+				if id.Value != b.ISBN {
+					ids = append(ids, id)
+				}
+			}
+			b.Identifiers = ids
+		}
+	}
+	current, err := json.Marshal(b)
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(current, &fields); err != nil {
+		return nil, err
+	}
+	for name, value := range p {
+		fields[name] = value
+	}
+	merged, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(merged))
+	dec.DisallowUnknownFields()
+	var patched Book
+	if err := dec.Decode(&patched); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrValidation, err)
+	}
+	patched.ID = b.ID
+	return &patched, nil
+}
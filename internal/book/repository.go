@@ -3,21 +3,351 @@ package book
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
+	"public_library/internal/cache"
+	"public_library/internal/config"
+	"public_library/internal/db"
 	"public_library/utils"
+	"sort"
 	"strings"
 )
 
-var ErrNotFound = errors.New("book not found")
+var (
+	ErrNotFound    = errors.New("book not found")
+	ErrInvalidSort = errors.New("invalid sort")
+	// ErrHasLoans is returned for deleting a book with copies that have
+	// been lent; their loans are circulation history
+	ErrHasLoans = errors.New("book has copies with loans; withdraw them instead")
+)
+
+// loansFKey is the constraint that keeps copies with loans from being deleted
+const loansFKey = "loans_acquisition_id_fkey"
 
 type Repository struct {
-	db *sql.DB
+	db        *db.DB
+	retrier   *db.Retrier
+	listCache *cache.Cache[string, listResult]
+	catalog   config.CatalogConfig
+}
+
+// listResult is a cached page of ListAllBooks
+type listResult struct {
+	books      []BookResponse
+	totalCount int64
+}
+
+func NewRepository(conn *db.DB, retrier *db.Retrier, cacheCfg cache.Config, catalogCfg config.CatalogConfig) *Repository {
+	return &Repository{
+		db:        conn,
+		retrier:   retrier,
+		listCache: cache.New[string, listResult]("book_list", cacheCfg),
+		catalog:   catalogCfg,
+	}
+}
+
+// listCacheKey normalizes req so equivalent requests share a cache entry.
+// Restricted catalog views and each language preference are cached separately.
+func listCacheKey(ctx context.Context, req PaginationRequest, limit int) string {
+	req.Search = strings.ToLower(strings.TrimSpace(req.Search))
+	req.Author = strings.ToLower(strings.TrimSpace(req.Author))
+	req.Title = strings.ToLower(strings.TrimSpace(req.Title))
+	req.Page = max(req.Page, 1)
+	req.PageSize = limit
+	key, _ := json.Marshal(req)
+	return audienceLimit(ctx) + "|" + languagesKey(ctx) + "|" + string(key)
+}
+
+// InvalidateLists drops cached list pages after any write to the catalog,
+// including writes to related data shown in book responses
+func (r *Repository) InvalidateLists() {
+	r.listCache.Purge()
+}
+
+// selectBooksSQL returns the one statement used to load books for both list
+// and detail views. The filtered, paginated page of books is selected first in
+// a CTE and related data is hydrated alongside it in the same statement, so
+// loading a page never costs more round trips as the page grows.
+// Relations must be added here as joins or aggregates over "page", never as
+// per-row lookups from Go. whereSQL and orderSQL reference books as "b".
+func selectBooksSQL(whereSQL, orderSQL, paginationSQL string) string {
+	return fmt.Sprintf(`
+	WITH page AS (
+		SELECT
+			b.id, b.title, b.author, b.description,
+			b.call_number, b.shelf_location, b.collection,
+			b.publication_year, b.edition, b.page_count, b.format,
+			b.series_id, b.series_position, b.audience, b.accessibility_features,
+			b.language, b.romanized,
+			ROW_NUMBER() OVER (ORDER BY %[3]s) AS position
+		FROM %[1]s b
+		WHERE %[2]s
+		ORDER BY %[3]s
+		%[4]s
+	)
+	SELECT
+		page.id,
+		page.title,
+		page.author,
+		page.description,
+		page.call_number,
+		page.shelf_location,
+		page.collection,
+		page.publication_year,
+		page.edition,
+		page.page_count,
+		page.format,
+		page.series_id,
+		page.series_position,
+		page.audience,
+		to_json(page.accessibility_features),
+		page.language,
+		page.romanized,
+		s.title,
+		COALESCE(ids.identifiers, '[]'),
+		COALESCE(tr.translations, '[]')
+	FROM page
+	LEFT JOIN %[6]s s ON s.id = page.series_id
+	LEFT JOIN LATERAL (
+		SELECT json_agg(json_build_object('type', i.type, 'value', i.value) ORDER BY i.type, i.value) AS identifiers
+		FROM %[5]s i
+		WHERE i.book_id = page.id
+	) ids ON true
+	LEFT JOIN LATERAL (
+		SELECT json_agg(json_build_object('language', t.language, 'title', t.title, 'description', t.description) ORDER BY t.language) AS translations
+		FROM %[7]s t
+		WHERE t.book_id = page.id
+	) tr ON true
+	ORDER BY page.position
+`, utils.BooksTable, whereSQL, orderSQL, paginationSQL, utils.BookIdentifiersTable, utils.SeriesTable, utils.BookTranslationsTable)
+}
+
+// sortColumns whitelists the fields a list can be sorted by. The id is always
+// appended as a tie-breaker so pagination is stable. Titles stored before
+// sort_title existed sort by their full title until they are next written.
+var sortColumns = map[string]string{
+	"id":               "b.id",
+	"title":            "COALESCE(NULLIF(b.sort_title, ''), b.title)",
+	"author":           "b.author",
+	"call_number":      "b.call_number_sort",
+	"publication_year": "b.publication_year",
 }
 
-func NewRepository(db *sql.DB) *Repository {
-	return &Repository{db: db}
+// SortCollation returns the ICU collation of PostgreSQL that orders titles
+// for a BCP 47 locale, e.g. "fr-x-icu"; "und" is the root collation
+func SortCollation(locale string) string {
+	return locale + "-x-icu"
+}
+
+// orderBySQL builds the ORDER BY expression for the sort keys, in order,
+// defaulting to id order. Ties are broken by id, in the direction of the
+// first key. Titles and authors are ordered by collation.
+func orderBySQL(keys []Sort, collation string) (string, error) {
+	var terms []string
+	seen := map[string]bool{}
+	tieBreak := ""
+	for _, sort := range keys {
+		if sort.Field == "" {
+			continue
+		}
+		field := strings.ToLower(sort.Field)
+		column, ok := sortColumns[field]
+		if !ok {
+			return "", fmt.Errorf("%w: cannot sort by %q", ErrInvalidSort, sort.Field)
+		}
+		if seen[field] {
+			return "", fmt.Errorf("%w: %q is sorted by more than once", ErrInvalidSort, sort.Field)
+		}
+		seen[field] = true
+		direction := "ASC"
+		switch strings.ToLower(sort.Order) {
+		case "", utils.ASC:
+		case utils.DESC:
+			direction = "DESC"
+		default:
+			return "", fmt.Errorf("%w: order must be %q or %q", ErrInvalidSort, utils.ASC, utils.DESC)
+		}
+		if tieBreak == "" {
+			tieBreak = "b.id " + direction
+		}
+		if field == "title" || field == "author" {
+			column = fmt.Sprintf(`%s COLLATE "%s"`, column, collation)
+		}
+		terms = append(terms, column+" "+direction)
+		if field == "id" {
+			// ids are unique, so later keys could never apply
+			return strings.Join(terms, ", "), nil
+		}
+	}
+	if len(terms) == 0 {
+		return "b.id", nil
+	}
+	return strings.Join(append(terms, tieBreak), ", "), nil
+}
+
+// bookWriteColumns are the columns written by Create, Update and imports.
+// bookWriteValues must return values in the same order.
+var bookWriteColumns = []string{
+	"title", "author", "description",
+	"call_number", "call_number_sort", "shelf_location", "collection",
+	"publication_year", "edition", "page_count", "format",
+	"series_id", "series_position", "audience", "accessibility_features",
+	"language", "sort_title", "romanized", "search_latin",
+}
+
+func bookWriteValues(b *Book) []interface{} {
+	var seriesID *int
+	var seriesPosition *float64
+	if b.Series != nil {
+		seriesID, seriesPosition = &b.Series.ID, b.Series.Position
+	}
+	return []interface{}{
+		b.Title, b.Author, b.Description,
+		b.CallNumber, CallNumberSortKey(b.CallNumber), b.ShelfLocation, b.Collection,
+		b.PublicationYear, b.Edition, b.PageCount, b.Format,
+		seriesID, seriesPosition, b.Audience, b.AccessibilityFeatures,
+		b.Language, SortTitle(b.Title, b.Language), b.Romanized,
+		Romanize(b.Title + " " + b.Author + " " + b.Romanized),
+	}
+}
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanBook scans one row produced by selectBooksSQL
+func scanBook(row rowScanner) (Book, error) {
+	var b Book
+	var ids identifierList
+	var features featureList
+	var translations translationList
+	var seriesID *int
+	var seriesPosition *float64
+	var seriesTitle *string
+	err := row.Scan(
+		&b.ID,
+		&b.Title,
+		&b.Author,
+		&b.Description,
+		&b.CallNumber,
+		&b.ShelfLocation,
+		&b.Collection,
+		&b.PublicationYear,
+		&b.Edition,
+		&b.PageCount,
+		&b.Format,
+		&seriesID,
+		&seriesPosition,
+		&b.Audience,
+		&features,
+		&b.Language,
+		&b.Romanized,
+		&seriesTitle,
+		&ids,
+		&translations,
+	)
+	b.Identifiers = ids
+	b.AccessibilityFeatures = features
+	b.translations = translations
+	b.ISBN = primaryISBN(ids)
+	if seriesID != nil && seriesTitle != nil {
+		b.Series = newSeriesRef(*seriesID, *seriesTitle, seriesPosition)
+	}
+	return b, err
+}
+
+// pageBounds resolves the LIMIT and OFFSET of a list request. An omitted page
+// or page_size takes the first page or the configured default; a page_size
+// above the configured maximum is clamped to it.
+func (r *Repository) pageBounds(req PaginationRequest) (limit, offset int, err error) {
+	if req.Page < 0 {
+		return 0, 0, fmt.Errorf("%w: page must be at least 1", ErrValidation)
+	}
+	if req.PageSize < 0 {
+		return 0, 0, fmt.Errorf("%w: page_size must be at least 1", ErrValidation)
+	}
+	page := max(req.Page, 1)
+	limit = req.PageSize
+	if limit == 0 {
+		limit = r.catalog.DefaultPageSize
+	}
+	if r.catalog.MaxPageSize > 0 {
+		limit = min(limit, r.catalog.MaxPageSize)
+	}
+	return limit, (page - 1) * limit, nil
+}
+
+// listWhere builds the WHERE clause of a list request's search and filters,
+// with the context's audience limit, and its arguments
+func listWhere(ctx context.Context, req PaginationRequest) (string, []interface{}, error) {
+	var whereClauses []string
+	var args []interface{}
+
+	whereClauses = append(whereClauses, "1=1") // base condition
+
+	if strings.TrimSpace(req.Search) != "" {
+		var clause string
+		clause, args = searchClause(req.Search, args)
+		whereClauses = append(whereClauses, clause)
+	}
+
+	if author := strings.TrimSpace(req.Author); author != "" {
+		args = append(args, "%"+likeEscaper.Replace(author)+"%")
+		whereClauses = append(whereClauses, fmt.Sprintf("b.author ILIKE $%d", len(args)))
+	}
+	if title := strings.TrimSpace(req.Title); title != "" {
+		args = append(args, likeEscaper.Replace(title)+"%")
+		whereClauses = append(whereClauses, fmt.Sprintf("b.title ILIKE $%d", len(args)))
+	}
+	if strings.TrimSpace(req.ISBN) != "" {
+		isbn, err := isbnIdentifier(req.ISBN)
+		if err != nil {
+			return "", nil, err
+		}
+		args = append(args, isbnAs13(isbn))
+		whereClauses = append(whereClauses, fmt.Sprintf(
+			"EXISTS (SELECT 1 FROM %s i WHERE i.book_id = b.id AND i.normalized_isbn = $%d)",
+			utils.BookIdentifiersTable, len(args)))
+	}
+
+	if req.YearFrom != nil {
+		args = append(args, *req.YearFrom)
+		whereClauses = append(whereClauses, fmt.Sprintf("b.publication_year >= $%d", len(args)))
+	}
+	if req.YearTo != nil {
+		args = append(args, *req.YearTo)
+		whereClauses = append(whereClauses, fmt.Sprintf("b.publication_year <= $%d", len(args)))
+	}
+	if len(req.Audiences) > 0 {
+		audiences := make([]string, len(req.Audiences))
+		for i, a := range req.Audiences {
+			audiences[i] = strings.ToLower(strings.TrimSpace(a))
+		}
+		args = append(args, audiences)
+		whereClauses = append(whereClauses, fmt.Sprintf("b.audience = ANY($%d)", len(args)))
+	}
+	if len(req.Formats) > 0 {
+		formats := make([]string, len(req.Formats))
+		for i, f := range req.Formats {
+			formats[i] = strings.ToLower(strings.TrimSpace(f))
+		}
+		args = append(args, formats)
+		whereClauses = append(whereClauses, fmt.Sprintf("b.format = ANY($%d)", len(args)))
+	}
+	if len(req.AccessibilityFeatures) > 0 {
+		features, err := normalizeAccessibilityFeatures(req.AccessibilityFeatures)
+		if err != nil {
+			return "", nil, err
+		}
+		args = append(args, features)
+		whereClauses = append(whereClauses, fmt.Sprintf("b.accessibility_features @> $%d::text[]", len(args)))
+	}
+
+	whereSQL, args := restrictAudience(ctx, strings.Join(whereClauses, " AND "), args)
+	return whereSQL, args, nil
 }
 
 func (r *Repository) ListAllBooks(ctx context.Context, req PaginationRequest) ([]BookResponse, int64, int64, error) {
@@ -29,75 +359,74 @@ func (r *Repository) ListAllBooks(ctx context.Context, req PaginationRequest) ([
 		totalCount int64
 	)
 
-	// Build WHERE clauses and args
-	var whereClauses []string
-	var args []interface{}
-
-	whereClauses = append(whereClauses, "1=1") // base condition
+	whereSQL, args, err := listWhere(ctx, req)
+	if err != nil {
+		return nil, 0, 0, err
+	}
 
-	if req.Search != "" {
-		whereClauses = append(whereClauses, "currency ILIKE ?")
-		args = append(args, "%"+req.Search+"%")
+	orderSQL, err := orderBySQL(req.sortKeys(), SortCollation(r.catalog.SortLocale))
+	if err != nil {
+		return nil, 0, 0, err
 	}
 
-	whereSQL := strings.Join(whereClauses, " AND ")
+	limit, offset, err := r.pageBounds(req)
+	if err != nil {
+		return nil, 0, 0, err
+	}
 
-	// Pagination
-	limit := req.PageSize
-	if limit == 0 {
-		limit = 10
+	cacheKey := listCacheKey(ctx, req, limit)
+	if cached, ok := r.listCache.Get(cacheKey); ok {
+		return cached.books, int64(len(cached.books)), cached.totalCount, nil
 	}
-	offset := (req.Page - 1) * limit
+	// A write during the queries purges the cache; this page is then stale
+	gen := r.listCache.Generation()
 
 	// --- Count Query ---
-	countQuery := fmt.Sprintf(`SELECT COUNT(*) FROM %s WHERE %s`, utils.BooksTable, whereSQL)
-	err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&totalCount)
+	countQuery := fmt.Sprintf(`SELECT COUNT(*) FROM %s b WHERE %s`, utils.BooksTable, whereSQL)
+	err = r.retrier.Do(ctx, "ListAllBooks.count", func() error {
+		return r.db.QueryRowContext(ctx, countQuery, args...).Scan(&totalCount)
+	})
 	if err != nil {
 		log.Printf("Failed to count books: %v", err)
 		return nil, 0, 0, err
 	}
 
 	// --- Data Query ---
-	dataQuery := fmt.Sprintf(`
-	SELECT
-		id,
-		title,
-		author,
-		isbn
-	FROM %s
-	WHERE %s
-	LIMIT $%d OFFSET $%d
-`, utils.BooksTable, whereSQL, len(args)+1, len(args)+2)
+	dataQuery := selectBooksSQL(whereSQL, orderSQL, fmt.Sprintf("LIMIT $%d OFFSET $%d", len(args)+1, len(args)+2))
 
 	argsWithPagination := append(args, limit, offset)
 
-	rows, err := r.db.QueryContext(ctx, dataQuery, argsWithPagination...)
-	if err != nil {
-		log.Printf("Failed to fetch books: %v", err)
-		return nil, 0, 0, err
-	}
-	defer rows.Close()
-
-	for rows.Next() {
-		var b BookResponse
-		err := rows.Scan(
-			&b.ID,
-			&b.Title,
-			&b.Author,
-			&b.ISBN,
-		)
+	err = r.retrier.Do(ctx, "ListAllBooks.data", func() error {
+		responses = nil
+		rows, err := r.db.QueryContext(ctx, dataQuery, argsWithPagination...)
 		if err != nil {
-			log.Printf("Failed to scan book row: %v", err)
-			return nil, 0, 0, err
+			log.Printf("Failed to fetch books: %v", err)
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			b, err := scanBook(rows)
+			if err != nil {
+				log.Printf("Failed to scan book row: %v", err)
+				return err
+			}
+			localize(ctx, &b)
+			b.Description = previewDescription(b.Description, r.catalog.DescriptionPreview)
+			responses = append(responses, BookResponse(b))
 		}
-		responses = append(responses, b)
-	}
 
-	if err = rows.Err(); err != nil {
-		log.Printf("Row iteration error: %v", err)
+		if err := rows.Err(); err != nil {
+			log.Printf("Row iteration error: %v", err)
+			return err
+		}
+		return nil
+	})
+	if err != nil {
 		return nil, 0, 0, err
 	}
 
+	r.listCache.SetUnlessPurged(gen, cacheKey, listResult{books: responses, totalCount: totalCount})
 	return responses, int64(len(responses)), totalCount, nil
 }
 
@@ -105,14 +434,15 @@ func (r *Repository) GetByID(ctx context.Context, id int) (*Book, error) {
 	log.Println("<--------GetByID starts-------->")
 	defer log.Println("<--------GetByID ends-------->")
 
-	const query = `
-		SELECT id, title, author, isbn
-		FROM books
-		WHERE id = $1
-	`
+	where, args := restrictAudience(ctx, "b.id = $1", []interface{}{id})
+	query := selectBooksSQL(where, "b.id", "")
 
 	var b Book
-	err := r.db.QueryRowContext(ctx, query, id).Scan(&b.ID, &b.Title, &b.Author, &b.ISBN)
+	err := r.retrier.Do(ctx, "GetByID", func() error {
+		var err error
+		b, err = scanBook(r.db.QueryRowContext(ctx, query, args...))
+		return err
+	})
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			log.Printf("Book with id=%d not found", id)
@@ -122,6 +452,7 @@ func (r *Repository) GetByID(ctx context.Context, id int) (*Book, error) {
 		return nil, err
 	}
 
+	localize(ctx, &b)
 	return &b, nil
 }
 
@@ -129,50 +460,360 @@ func (r *Repository) Create(ctx context.Context, b *Book) error {
 	log.Println("<--------Create starts-------->")
 	defer log.Println("<--------Create ends-------->")
 
-	const query = `
-		INSERT INTO books (title, author, isbn)
-		VALUES ($1, $2, $3)
-		RETURNING id
-	`
+	ids, err := b.identifierSet()
+	if err != nil {
+		return err
+	}
 
-	err := r.db.QueryRowContext(ctx, query, b.Title, b.Author, b.ISBN).Scan(&b.ID)
+	query := insertBookSQL()
+
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
+		log.Printf("Failed to begin create transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := tx.QueryRowContext(ctx, query, bookWriteValues(b)...).Scan(&b.ID); err != nil {
 		log.Printf("Failed to create book %+v: %v", b, err)
 		return err
 	}
+	if err := replaceIdentifiers(ctx, tx, b.ID, ids); err != nil {
+		log.Printf("Failed to store identifiers for book id=%d: %v", b.ID, err)
+		tx.Rollback()
+		return r.duplicateOr(ctx, err, 0, ids)
+	}
+	if err := tx.Commit(); err != nil {
+		log.Printf("Failed to commit book %+v: %v", b, err)
+		return err
+	}
+
+	b.Identifiers = ids
+	b.ISBN = primaryISBN(ids)
+	r.InvalidateLists()
 	return nil
 }
 
-func (r *Repository) Update(ctx context.Context, b *Book) error {
-	log.Println("<--------Update starts-------->")
-	defer log.Println("<--------Update ends-------->")
+// insertBookSQL inserts the bookWriteColumns of a book, returning its id
+func insertBookSQL() string {
+	placeholders := make([]string, len(bookWriteColumns))
+	for i := range placeholders {
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+	}
+	return fmt.Sprintf(`
+		INSERT INTO %s (%s)
+		VALUES (%s)
+		RETURNING id
+	`, utils.BooksTable, strings.Join(bookWriteColumns, ", "), strings.Join(placeholders, ", "))
+}
 
-	const query = `
-		UPDATE books
-		SET title = $1, author = $2, isbn = $3
-		WHERE id = $4
-	`
+// CreateBooks stores books in one transaction, filling in their ids. A
+// book whose identifiers turn out to be taken, by a book written since they
+// were checked, is rolled back alone and its error returned at its position
+// in errs; any other failure aborts the whole transaction.
+func (r *Repository) CreateBooks(ctx context.Context, books []*Book) ([]error, error) {
+	log.Println("<--------CreateBooks starts-------->")
+	defer log.Println("<--------CreateBooks ends-------->")
 
-	result, err := r.db.ExecContext(ctx, query, b.Title, b.Author, b.ISBN, b.ID)
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
-		log.Printf("Failed to update book id=%d: %v", b.ID, err)
-		return err
+		log.Printf("Failed to begin bulk create transaction: %v", err)
+		return nil, err
 	}
+	defer tx.Rollback()
 
-	rowsAffected, err := result.RowsAffected()
+	query := insertBookSQL()
+	errs := make([]error, len(books))
+	for i, b := range books {
+		ids, err := b.identifierSet()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := tx.ExecContext(ctx, `SAVEPOINT bulk_book`); err != nil {
+			return nil, err
+		}
+		err = tx.QueryRowContext(ctx, query, bookWriteValues(b)...).Scan(&b.ID)
+		if err == nil {
+			err = replaceIdentifiers(ctx, tx, b.ID, ids)
+		}
+		if err != nil {
+			if !isIdentifierConflict(err) {
+				log.Printf("Failed to create book %d of %d: %v", i+1, len(books), err)
+				return nil, err
+			}
+			if _, err := tx.ExecContext(ctx, `ROLLBACK TO SAVEPOINT bulk_book`); err != nil {
+				return nil, err
+			}
+			b.ID = 0
+			errs[i] = r.duplicateOr(ctx, err, 0, ids)
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, `RELEASE SAVEPOINT bulk_book`); err != nil {
+			return nil, err
+		}
+		b.Identifiers = ids
+		b.ISBN = primaryISBN(ids)
+	}
+	if err := tx.Commit(); err != nil {
+		log.Printf("Failed to commit %d books: %v", len(books), err)
+		return nil, err
+	}
+
+	r.InvalidateLists()
+	return errs, nil
+}
+
+func (r *Repository) Update(ctx context.Context, b *Book) error {
+	log.Println("<--------Update starts-------->")
+	defer log.Println("<--------Update ends-------->")
+
+	ids, err := b.identifierSet()
 	if err != nil {
-		log.Printf("Failed to get rows affected for book id=%d update: %v", b.ID, err)
 		return err
 	}
 
-	if rowsAffected == 0 {
+	query := updateBookSQL()
+	args := append(bookWriteValues(b), b.ID)
+
+	// The update replaces the row and its identifiers with fixed values, so
+	// re-running the whole transaction is idempotent and safe to retry
+	err = r.retrier.Do(ctx, "Update", func() error {
+		tx, err := r.db.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+
+		result, err := tx.ExecContext(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rowsAffected == 0 {
+			return ErrNotFound
+		}
+		if err := replaceIdentifiers(ctx, tx, b.ID, ids); err != nil {
+			return err
+		}
+		return tx.Commit()
+	})
+	if errors.Is(err, ErrNotFound) {
 		log.Printf("No book found to update with id=%d", b.ID)
 		return ErrNotFound
 	}
+	if err != nil {
+		log.Printf("Failed to update book id=%d: %v", b.ID, err)
+		return r.duplicateOr(ctx, err, b.ID, ids)
+	}
 
+	b.Identifiers = ids
+	b.ISBN = primaryISBN(ids)
+	r.InvalidateLists()
 	return nil
 }
 
+// updateBookSQL overwrites the bookWriteColumns of the book whose id
+// follows their values
+func updateBookSQL() string {
+	assignments := make([]string, len(bookWriteColumns))
+	for i, column := range bookWriteColumns {
+		assignments[i] = fmt.Sprintf("%s = $%d", column, i+1)
+	}
+	return fmt.Sprintf(`
+		UPDATE %s
+		SET %s
+		WHERE id = $%d
+	`, utils.BooksTable, strings.Join(assignments, ", "), len(bookWriteColumns)+1)
+}
+
+// PatchBook applies patch to the book with id as catalogued, untranslated,
+// and writes the result in one transaction that holds the row, so
+// concurrent writes cannot interleave with it. check runs on the patched
+// book before it is written and may reject or amend it.
+func (r *Repository) PatchBook(ctx context.Context, id int, patch Patch, check func(*Book) error) (*Book, error) {
+	log.Println("<--------PatchBook starts-------->")
+	defer log.Println("<--------PatchBook ends-------->")
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("Failed to begin patch transaction: %v", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	lock := fmt.Sprintf(`SELECT id FROM %s WHERE id = $1 FOR UPDATE`, utils.BooksTable)
+	if err := tx.QueryRowContext(ctx, lock, id).Scan(&id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Printf("No book found to patch with id=%d", id)
+			return nil, ErrNotFound
+		}
+		log.Printf("Failed to lock book id=%d: %v", id, err)
+		return nil, err
+	}
+	current, err := scanBook(tx.QueryRowContext(ctx, selectBooksSQL("b.id = $1", "b.id", ""), id))
+	if err != nil {
+		log.Printf("Failed to read book id=%d: %v", id, err)
+		return nil, err
+	}
+
+	b, err := patch.Apply(current)
+	if err != nil {
+		return nil, err
+	}
+	if err := check(b); err != nil {
+		return nil, err
+	}
+	ids, err := b.identifierSet()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.ExecContext(ctx, updateBookSQL(), append(bookWriteValues(b), b.ID)...); err != nil {
+		log.Printf("Failed to patch book id=%d: %v", id, err)
+		return nil, err
+	}
+	if err := replaceIdentifiers(ctx, tx, b.ID, ids); err != nil {
+		log.Printf("Failed to store identifiers for book id=%d: %v", id, err)
+		tx.Rollback()
+		return nil, r.duplicateOr(ctx, err, b.ID, ids)
+	}
+	if err := tx.Commit(); err != nil {
+		log.Printf("Failed to commit patch of book id=%d: %v", id, err)
+		return nil, err
+	}
+
+	b.Identifiers = ids
+	b.ISBN = primaryISBN(ids)
+	r.InvalidateLists()
+	return b, nil
+}
+
+// ListBySeries returns the books of a series in reading order; volumes
+// without a position come last
+func (r *Repository) ListBySeries(ctx context.Context, seriesID int) ([]Book, error) {
+	log.Println("<--------ListBySeries starts-------->")
+	defer log.Println("<--------ListBySeries ends-------->")
+
+	where, args := restrictAudience(ctx, "b.series_id = $1", []interface{}{seriesID})
+	query := selectBooksSQL(where, "b.series_position NULLS LAST, b.id", "")
+
+	var books []Book
+	err := r.retrier.Do(ctx, "ListBySeries", func() error {
+		books = []Book{}
+		rows, err := r.db.QueryContext(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			b, err := scanBook(rows)
+			if err != nil {
+				return err
+			}
+			localize(ctx, &b)
+			books = append(books, b)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		log.Printf("Failed to list books of series id=%d: %v", seriesID, err)
+		return nil, err
+	}
+	return books, nil
+}
+
+// ListAddedAfter returns up to limit books added after the book with
+// afterID that match the search and filters of req, in the order they were
+// added. Paging and sorting of req are ignored.
+func (r *Repository) ListAddedAfter(ctx context.Context, req PaginationRequest, afterID, limit int) ([]Book, error) {
+	log.Println("<--------ListAddedAfter starts-------->")
+	defer log.Println("<--------ListAddedAfter ends-------->")
+
+	where, args, err := listWhere(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	args = append(args, afterID, limit)
+	query := selectBooksSQL(fmt.Sprintf("(%s) AND b.id > $%d", where, len(args)-1), "b.id",
+		fmt.Sprintf("LIMIT $%d", len(args)))
+
+	var books []Book
+	err = r.retrier.Do(ctx, "ListAddedAfter", func() error {
+		books = []Book{}
+		rows, err := r.db.QueryContext(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			b, err := scanBook(rows)
+			if err != nil {
+				return err
+			}
+			localize(ctx, &b)
+			books = append(books, b)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		log.Printf("Failed to list books added after id=%d: %v", afterID, err)
+		return nil, err
+	}
+	return books, nil
+}
+
+// LastID returns the id of the book added last, or 0 for an empty catalog.
+// Ids grow with each book added, so books added later have higher ones.
+func (r *Repository) LastID(ctx context.Context) (int, error) {
+	var id int
+	query := fmt.Sprintf(`SELECT COALESCE(max(id), 0) FROM %s`, utils.BooksTable)
+	err := r.retrier.Do(ctx, "LastID", func() error {
+		return r.db.QueryRowContext(ctx, query).Scan(&id)
+	})
+	if err != nil {
+		log.Printf("Failed to get the last book id: %v", err)
+	}
+	return id, err
+}
+
+// GetByIdentifier looks a book up by one of its typed identifiers
+func (r *Repository) GetByIdentifier(ctx context.Context, idType, value string) (*Book, error) {
+	log.Println("<--------GetByIdentifier starts-------->")
+	defer log.Println("<--------GetByIdentifier ends-------->")
+
+	id, err := NormalizeIdentifier(idType, value)
+	if err != nil {
+		return nil, err
+	}
+
+	where := fmt.Sprintf(`EXISTS (
+		SELECT 1 FROM %s i WHERE i.book_id = b.id AND i.type = $1 AND i.value = $2
+	)`, utils.BookIdentifiersTable)
+	where, args := restrictAudience(ctx, where, []interface{}{id.Type, id.Value})
+	query := selectBooksSQL(where, "b.id", "")
+
+	var b Book
+	err = r.retrier.Do(ctx, "GetByIdentifier", func() error {
+		var err error
+		b, err = scanBook(r.db.QueryRowContext(ctx, query, args...))
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Printf("Book with %s=%s not found", id.Type, id.Value)
+			return nil, ErrNotFound
+		}
+		log.Printf("Failed to get book by %s=%s: %v", id.Type, id.Value, err)
+		return nil, err
+	}
+
+	localize(ctx, &b)
+	return &b, nil
+}
+
 func (r *Repository) Delete(ctx context.Context, id int) error {
 	log.Println("<--------Delete starts-------->")
 	defer log.Println("<--------Delete ends-------->")
@@ -183,6 +824,9 @@ func (r *Repository) Delete(ctx context.Context, id int) error {
 
 	result, err := r.db.ExecContext(ctx, query, id)
 	if err != nil {
+		if db.IsStillReferenced(err, loansFKey) {
+			return ErrHasLoans
+		}
 		log.Printf("Failed to delete book id=%d: %v", id, err)
 		return err
 	}
@@ -198,5 +842,97 @@ func (r *Repository) Delete(ctx context.Context, id int) error {
 		return ErrNotFound
 	}
 
+	r.InvalidateLists()
 	return nil
 }
+
+// DeleteBooks deletes the books with ids in one transaction and returns the
+// ids of those deleted and of those left alone because a copy of theirs has
+// loans, each in ascending order. The books and all their copies are locked
+// first, so no copy can be lent while they are checked or deleted.
+func (r *Repository) DeleteBooks(ctx context.Context, ids []int) (deleted, conflict []int, err error) {
+	log.Println("<--------DeleteBooks starts-------->")
+	defer log.Println("<--------DeleteBooks ends-------->")
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+
+	lockBooks := fmt.Sprintf(`SELECT id FROM %s WHERE id = ANY($1::int[]) ORDER BY id FOR UPDATE`, utils.BooksTable)
+	existing, err := queryIDs(ctx, tx, lockBooks, ids)
+	if err != nil {
+		log.Printf("Failed to lock %d books for delete: %v", len(ids), err)
+		return nil, nil, err
+	}
+	lockCopies := fmt.Sprintf(`
+		SELECT a.book_id, EXISTS (SELECT 1 FROM %s l WHERE l.acquisition_id = a.id)
+		FROM %s a
+		WHERE a.book_id = ANY($1::int[])
+		ORDER BY a.id
+		FOR UPDATE OF a
+	`, utils.LoansTable, utils.AcquisitionsTable)
+	rows, err := tx.QueryContext(ctx, lockCopies, existing)
+	if err != nil {
+		log.Printf("Failed to lock the copies of %d books: %v", len(existing), err)
+		return nil, nil, err
+	}
+	hasLoans := make(map[int]bool)
+	for rows.Next() {
+		var bookID int
+		var lent bool
+		if err := rows.Scan(&bookID, &lent); err != nil {
+			rows.Close()
+			return nil, nil, err
+		}
+		hasLoans[bookID] = hasLoans[bookID] || lent
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		log.Printf("Failed to check %d books for loans: %v", len(existing), err)
+		return nil, nil, err
+	}
+
+	conflict, deletable := []int{}, []int{}
+	for _, id := range existing {
+		if hasLoans[id] {
+			conflict = append(conflict, id)
+		} else {
+			deletable = append(deletable, id)
+		}
+	}
+
+	query := fmt.Sprintf(`DELETE FROM %s WHERE id = ANY($1::int[]) RETURNING id`, utils.BooksTable)
+	deleted, err = queryIDs(ctx, tx, query, deletable)
+	if err != nil {
+		log.Printf("Failed to delete %d books: %v", len(deletable), err)
+		return nil, nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, nil, err
+	}
+	sort.Ints(deleted)
+
+	r.InvalidateLists()
+	return deleted, conflict, nil
+}
+
+// queryIDs runs a query returning one id column with ids as its argument
+func queryIDs(ctx context.Context, q db.Queryer, query string, ids []int) ([]int, error) {
+	rows, err := q.QueryContext(ctx, query, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []int{}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		out = append(out, id)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,113 @@
+package book
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"public_library/utils"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// searchClause builds the WHERE condition for a list search, appending its
+// arguments to args. A term matches books whose title or author contains it,
+// whose full text (title, author and description) matches it as a web-style
+// query, whose romanized title and author contain its romanized form, whose
+// author sounds like every word of it, or, if the term is shaped like an
+// ISBN, that carry that ISBN in either its ISBN-10 or ISBN-13 form.
+func searchClause(term string, args []interface{}) (string, []interface{}) {
+	term = strings.TrimSpace(term)
+
+	args = append(args, "%"+likeEscaper.Replace(term)+"%")
+	like := len(args)
+	args = append(args, term)
+	text := len(args)
+	args = append(args, "%"+likeEscaper.Replace(Romanize(term))+"%")
+	latin := len(args)
+	conditions := []string{
+		fmt.Sprintf("b.title ILIKE $%d", like),
+		fmt.Sprintf("b.author ILIKE $%d", like),
+		fmt.Sprintf("b.search_vector @@ websearch_to_tsquery('english', $%d)", text),
+		fmt.Sprintf("b.search_latin LIKE $%d", latin),
+	}
+
+	if romanized := Romanize(term); phonetic(romanized) {
+		args = append(args, romanized)
+		conditions = append(conditions, fmt.Sprintf(
+			"b.author_phonetic @> ARRAY(SELECT dmetaphone(w) FROM regexp_split_to_table($%d, '[^[:alpha:]]+') AS w WHERE length(w) > 1)",
+			len(args)))
+	}
+
+	if isbn, err := isbnIdentifier(term); err == nil {
+		args = append(args, isbnAs13(isbn))
+		conditions = append(conditions, fmt.Sprintf(
+			"EXISTS (SELECT 1 FROM %s i WHERE i.book_id = b.id AND i.normalized_isbn = $%d)",
+			utils.BookIdentifiersTable, len(args)))
+	}
+	return "(" + strings.Join(conditions, " OR ") + ")", args
+}
+
+// phonetic reports whether a romanized term has a word Double Metaphone can
+// encode: two or more Latin letters. Without one its codes would be empty,
+// and every author's codes contain the empty set.
+func phonetic(romanized string) bool {
+	for _, word := range strings.FieldsFunc(romanized, func(r rune) bool { return !unicode.IsLetter(r) }) {
+		if utf8.RuneCountInString(word) > 1 && strings.IndexFunc(word, func(r rune) bool { return !unicode.Is(unicode.Latin, r) }) < 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Suggest returns up to catalog.suggestions titles and authors that contain
+// a close match of term, most similar first, by the trigram word similarity
+// of pg_trgm. They are offered as corrections when a search finds nothing.
+func (r *Repository) Suggest(ctx context.Context, term string) ([]string, error) {
+	log.Println("<--------Suggest starts-------->")
+	defer log.Println("<--------Suggest ends-------->")
+
+	term = strings.TrimSpace(term)
+	if term == "" || r.catalog.Suggestions == 0 {
+		return nil, nil
+	}
+	where, args := restrictAudience(ctx, "true", []interface{}{term})
+	args = append(args, r.catalog.Suggestions)
+	query := fmt.Sprintf(`
+		SELECT suggestion FROM (
+			SELECT b.title AS suggestion, word_similarity($1, b.title) AS score
+			FROM %[1]s b WHERE $1 <%% b.title AND %[2]s
+			UNION ALL
+			SELECT b.author, word_similarity($1, b.author)
+			FROM %[1]s b WHERE $1 <%% b.author AND %[2]s
+		) s
+		GROUP BY suggestion
+		ORDER BY max(score) DESC, suggestion
+		LIMIT $%[3]d
+	`, utils.BooksTable, where, len(args))
+
+	var suggestions []string
+	err := r.retrier.Do(ctx, "Suggest", func() error {
+		suggestions = nil
+		rows, err := r.db.QueryContext(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var suggestion string
+			if err := rows.Scan(&suggestion); err != nil {
+				return err
+			}
+			suggestions = append(suggestions, suggestion)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		log.Printf("Failed to suggest corrections for %q: %v", term, err)
+		return nil, err
+	}
+	return suggestions, nil
+}
@@ -0,0 +1,172 @@
+package book
+
+import (
+	"context"
+	"public_library/internal/config"
+	"regexp"
+	"strconv"
+	"testing"
+)
+
+var placeholder = regexp.MustCompile(`\$(\d+)`)
+
+// checkPlaceholders fails unless the placeholders of query are exactly $1
+// to $n for the n arguments
+func checkPlaceholders(t *testing.T, query string, args []interface{}) {
+	t.Helper()
+	used := map[int]bool{}
+	for _, m := range placeholder.FindAllStringSubmatch(query, -1) {
+		n, _ := strconv.Atoi(m[1])
+		if n < 1 || n > len(args) {
+			t.Errorf("placeholder $%d out of range for %d args in %s", n, len(args), query)
+		}
+		used[n] = true
+	}
+	for n := 1; n <= len(args); n++ {
+		if !used[n] {
+			t.Errorf("arg $%d (%v) is not used in %s", n, args[n-1], query)
+		}
+	}
+}
+
+// argFor returns the argument bound to the last placeholder following
+// prefix in query, such as "b.author ILIKE "; filters come after the search
+func argFor(t *testing.T, query string, args []interface{}, prefix string) interface{} {
+	t.Helper()
+	m := regexp.MustCompile(regexp.QuoteMeta(prefix)+`\$(\d+)`).FindAllStringSubmatch(query, -1)
+	if m == nil {
+		t.Fatalf("%q not found in %s", prefix, query)
+	}
+	n, _ := strconv.Atoi(m[len(m)-1][1])
+	return args[n-1]
+}
+
+func TestListWhereSearchAndFilters(t *testing.T) {
+	from, to := 1900, 1950
+	audienceCtx, err := WithAudienceLimit(context.Background(), AudienceTeen)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		req      PaginationRequest
+		wantArgs int
+		bound    map[string]interface{}
+	}{
+		{
+			name:     "no search or filters",
+			ctx:      context.Background(),
+			req:      PaginationRequest{Page: 2, PageSize: 10},
+			wantArgs: 0,
+		},
+		{
+			name:     "search only",
+			ctx:      context.Background(),
+			req:      PaginationRequest{Search: "gatsby"},
+			wantArgs: 4, // like, full text, romanized like and phonetic
+			bound:    map[string]interface{}{"b.title ILIKE ": "%gatsby%"},
+		},
+		{
+			name: "search with filters",
+			ctx:  context.Background(),
+			req: PaginationRequest{
+				Page: 3, PageSize: 5, Search: "gatsby", Author: "fitz", Title: "The ",
+				YearFrom: &from, YearTo: &to, Formats: []string{"Book"},
+			},
+			wantArgs: 4 + 5,
+			bound: map[string]interface{}{
+				"b.author ILIKE ":        "%fitz%",
+				"b.title ILIKE ":         "The%",
+				"b.publication_year >= ": 1900,
+				"b.publication_year <= ": 1950,
+			},
+		},
+		{
+			name:     "ISBN search with an ISBN filter and an audience limit",
+			ctx:      audienceCtx,
+			req:      PaginationRequest{Search: "0-618-64015-7", ISBN: "9780618640157", Page: 1, PageSize: 20},
+			wantArgs: 4 + 1 + 1, // no phonetic word; the ISBN condition, the filter and the audiences
+		},
+		{
+			name:     "search of escaped wildcards",
+			ctx:      context.Background(),
+			req:      PaginationRequest{Search: "100%_done"},
+			wantArgs: 4,
+			bound:    map[string]interface{}{"b.title ILIKE ": `%100\%\_done%`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			where, args, err := listWhere(tt.ctx, tt.req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(args) != tt.wantArgs {
+				t.Fatalf("got %d args %v, want %d", len(args), args, tt.wantArgs)
+			}
+			checkPlaceholders(t, where, args)
+			for prefix, want := range tt.bound {
+				if got := argFor(t, where, args, prefix); got != want {
+					t.Errorf("%s bound to %v, want %v", prefix, got, want)
+				}
+			}
+
+			// ListAllBooks binds LIMIT and OFFSET after the filters
+			data := selectBooksSQL(where, "b.id", "LIMIT $"+strconv.Itoa(len(args)+1)+" OFFSET $"+strconv.Itoa(len(args)+2))
+			checkPlaceholders(t, data, append(args, 10, 0))
+		})
+	}
+}
+
+func TestListWhereAudienceLimitIsLast(t *testing.T) {
+	ctx, err := WithAudienceLimit(context.Background(), AudienceChildren)
+	if err != nil {
+		t.Fatal(err)
+	}
+	where, args, err := listWhere(ctx, PaginationRequest{Search: "owl", Audiences: []string{"children"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkPlaceholders(t, where, args)
+	limit, ok := args[len(args)-1].([]string)
+	if !ok || len(limit) != 1 || limit[0] != AudienceChildren {
+		t.Errorf("last arg = %v, want the audience limit [children]", args[len(args)-1])
+	}
+}
+
+func TestListWhereInvalidISBN(t *testing.T) {
+	if _, _, err := listWhere(context.Background(), PaginationRequest{Search: "gatsby", ISBN: "123"}); err == nil {
+		t.Error("expected an error for an invalid ISBN filter")
+	}
+}
+
+func TestPageBounds(t *testing.T) {
+	r := &Repository{catalog: config.CatalogConfig{DefaultPageSize: 10, MaxPageSize: 50}}
+	tests := []struct {
+		req                   PaginationRequest
+		wantLimit, wantOffset int
+		wantErr               bool
+	}{
+		{req: PaginationRequest{}, wantLimit: 10, wantOffset: 0},
+		{req: PaginationRequest{Page: 1, PageSize: 20}, wantLimit: 20, wantOffset: 0},
+		{req: PaginationRequest{Page: 3, PageSize: 20}, wantLimit: 20, wantOffset: 40},
+		{req: PaginationRequest{Page: 2}, wantLimit: 10, wantOffset: 10},
+		{req: PaginationRequest{Page: 2, PageSize: 500}, wantLimit: 50, wantOffset: 50},
+		{req: PaginationRequest{Page: -1}, wantErr: true},
+		{req: PaginationRequest{PageSize: -5}, wantErr: true},
+	}
+	for _, tt := range tests {
+		limit, offset, err := r.pageBounds(tt.req)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("pageBounds(%+v): expected an error", tt.req)
+			}
+			continue
+		}
+		if err != nil || limit != tt.wantLimit || offset != tt.wantOffset {
+			t.Errorf("pageBounds(%+v) = %d, %d, %v; want %d, %d", tt.req, limit, offset, err, tt.wantLimit, tt.wantOffset)
+		}
+	}
+}
@@ -0,0 +1,12 @@
+package book
+
+import "strconv"
+
+// newSeriesRef builds the series info shown on a book, e.g. "Dune #2"
+func newSeriesRef(id int, title string, position *float64) *SeriesRef {
+	ref := &SeriesRef{ID: id, Title: title, Position: position, Label: title}
+	if position != nil {
+		ref.Label = title + " #" + strconv.FormatFloat(*position, 'f', -1, 64)
+	}
+	return ref
+}
@@ -0,0 +1,390 @@
+package book
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"public_library/internal/eventbus"
+	"public_library/internal/hooks"
+	"sort"
+	"strings"
+)
+
+// ErrInvalidImport is wrapped by errors about the content of an import file
+var ErrInvalidImport = errors.New("invalid import file")
+
+// maxBulkBooks caps the books of one bulk create; larger loads go through
+// the CSV import
+const maxBulkBooks = 1000
+
+// Service holds the catalog's business rules. Handlers (or any other entry
+// point, such as a CLI) only translate requests into Service calls, and the
+// Repository only runs SQL.
+type Service struct {
+	repo    *Repository
+	formats *FormatCatalog
+	bus     *eventbus.Bus
+	hooks   *hooks.Registry[Book]
+}
+
+// NewService creates the book service. Every committed write is published to
+// bus under the eventbus.Book* topics with the book as payload.
+func NewService(repo *Repository, formats *FormatCatalog, bus *eventbus.Bus) *Service {
+	return &Service{repo: repo, formats: formats, bus: bus, hooks: hooks.NewRegistry[Book]()}
+}
+
+// Ping checks that the catalog database is reachable
+func (s *Service) Ping(ctx context.Context) error {
+	return s.repo.db.PingContext(ctx)
+}
+
+// Formats lists the format taxonomy with each format's loan period
+func (s *Service) Formats() []Format {
+	return s.formats.All()
+}
+
+func (s *Service) List(ctx context.Context, req PaginationRequest) ([]BookResponse, int64, int64, error) {
+	return s.repo.ListAllBooks(ctx, req)
+}
+
+// ListAddedAfter returns up to limit books added after the book with
+// afterID that match the search and filters of req, oldest first
+func (s *Service) ListAddedAfter(ctx context.Context, req PaginationRequest, afterID, limit int) ([]Book, error) {
+	return s.repo.ListAddedAfter(ctx, req, afterID, limit)
+}
+
+// LastID returns the id of the book added last, or 0 for an empty catalog
+func (s *Service) LastID(ctx context.Context) (int, error) {
+	return s.repo.LastID(ctx)
+}
+
+// Suggest returns "did you mean" titles and authors close to a search term
+func (s *Service) Suggest(ctx context.Context, term string) ([]string, error) {
+	return s.repo.Suggest(ctx, term)
+}
+
+func (s *Service) Get(ctx context.Context, id int) (*Book, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *Service) GetByIdentifier(ctx context.Context, idType, value string) (*Book, error) {
+	return s.repo.GetByIdentifier(ctx, idType, value)
+}
+
+// ListBySeries returns a series' volumes in reading order
+func (s *Service) ListBySeries(ctx context.Context, seriesID int) ([]Book, error) {
+	return s.repo.ListBySeries(ctx, seriesID)
+}
+
+// ListTranslations returns the translations of the book with id
+func (s *Service) ListTranslations(ctx context.Context, id int) ([]Translation, error) {
+	if _, err := s.repo.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.repo.ListTranslations(ctx, id)
+}
+
+// PutTranslation validates t and stores it as the book's translation in
+// t.Language, replacing any earlier one
+func (s *Service) PutTranslation(ctx context.Context, id int, t *Translation) error {
+	if err := t.Validate(); err != nil {
+		return err
+	}
+	if _, err := s.repo.GetByID(ctx, id); err != nil {
+		return err
+	}
+	return s.repo.PutTranslation(ctx, id, *t)
+}
+
+// DeleteTranslation removes the book's translation in lang
+func (s *Service) DeleteTranslation(ctx context.Context, id int, lang string) error {
+	tag, err := normalizeLanguage(lang)
+	if err != nil {
+		return err
+	}
+	return s.repo.DeleteTranslation(ctx, id, tag)
+}
+
+// Hooks returns the registry deployments use to extend book writes
+func (s *Service) Hooks() *hooks.Registry[Book] {
+	return s.hooks
+}
+
+// Create validates b, rejects identifiers already held by another book and
+// stores it, filling in its id
+func (s *Service) Create(ctx context.Context, b *Book) error {
+	if err := s.beforeWrite(ctx, hooks.BeforeCreate, b); err != nil {
+		return err
+	}
+	if err := s.repo.Create(ctx, b); err != nil {
+		return err
+	}
+	s.afterWrite(ctx, hooks.AfterCreate, b)
+	s.bus.Publish(ctx, eventbus.BookCreated, b.ID, *b)
+	return nil
+}
+
+// BulkCreate validates each of books and stores those that pass in one
+// transaction, filling in their ids. Books that fail validation, are vetoed
+// by a hook or duplicate an identifier, in the catalog or earlier in the
+// list, are reported by their index without failing the others.
+func (s *Service) BulkCreate(ctx context.Context, books []Book) (*BulkCreateResponse, error) {
+	if len(books) == 0 || len(books) > maxBulkBooks {
+		return nil, fmt.Errorf("%w: between 1 and %d books are required", ErrValidation, maxBulkBooks)
+	}
+	out := &BulkCreateResponse{Created: []BulkCreated{}, Errors: []BulkError{}}
+	fail := func(i int, err error) {
+		e := BulkError{Index: i, Error: err.Error()}
+		var dup *DuplicateError
+		if errors.As(err, &dup) {
+			e.ExistingID = dup.BookID
+		}
+		out.Errors = append(out.Errors, e)
+	}
+
+	var valid []*Book
+	var indexes []int
+	seen := make(map[string]int)
+	for i := range books {
+		b := &books[i]
+		b.ID = 0
+		if err := s.beforeWrite(ctx, hooks.BeforeCreate, b); err != nil {
+			if !errors.Is(err, ErrValidation) && !errors.Is(err, ErrDuplicate) && !errors.Is(err, hooks.ErrVetoed) {
+				return nil, err
+			}
+			fail(i, err)
+			continue
+		}
+		ids, _ := b.identifierSet()
+		if err := repeatedIdentifier(ids, seen, i); err != nil {
+			fail(i, err)
+			continue
+		}
+		valid = append(valid, b)
+		indexes = append(indexes, i)
+	}
+
+	if len(valid) > 0 {
+		errs, err := s.repo.CreateBooks(ctx, valid)
+		if err != nil {
+			return nil, err
+		}
+		for j, b := range valid {
+			if errs[j] != nil {
+				fail(indexes[j], errs[j])
+				continue
+			}
+			out.Created = append(out.Created, BulkCreated{Index: indexes[j], ID: b.ID})
+			s.afterWrite(ctx, hooks.AfterCreate, b)
+			s.bus.Publish(ctx, eventbus.BookCreated, b.ID, *b)
+		}
+	}
+	sort.Slice(out.Errors, func(i, j int) bool { return out.Errors[i].Index < out.Errors[j].Index })
+	return out, nil
+}
+
+// repeatedIdentifier returns an error if one of ids, ISBNs compared in
+// either form, was already seen earlier in a bulk create, and otherwise
+// records them as seen at index
+func repeatedIdentifier(ids []Identifier, seen map[string]int, index int) error {
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = id.Type + ":" + id.Value
+		if isbn := isbnAs13(id); isbn != "" {
+			keys[i] = "isbn:" + isbn
+		}
+		if first, ok := seen[keys[i]]; ok {
+			return fmt.Errorf("%w: %s %s is also given to the book at index %d", ErrDuplicate, id.Type, id.Value, first)
+		}
+	}
+	for _, key := range keys {
+		seen[key] = index
+	}
+	return nil
+}
+
+// Update validates b and overwrites the book with b's id
+func (s *Service) Update(ctx context.Context, b *Book) error {
+	if err := s.beforeWrite(ctx, hooks.BeforeUpdate, b); err != nil {
+		return err
+	}
+	if err := s.repo.Update(ctx, b); err != nil {
+		return err
+	}
+	s.afterWrite(ctx, hooks.AfterUpdate, b)
+	s.bus.Publish(ctx, eventbus.BookUpdated, b.ID, *b)
+	return nil
+}
+
+// Patch applies a partial update to the book with id and returns the book
+// as written. The patched book is validated and goes through the update
+// hooks like a full update.
+func (s *Service) Patch(ctx context.Context, id int, p Patch) (*Book, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	b, err := s.repo.PatchBook(ctx, id, p, func(b *Book) error {
+		return s.beforeWrite(ctx, hooks.BeforeUpdate, b)
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.afterWrite(ctx, hooks.AfterUpdate, b)
+	s.bus.Publish(ctx, eventbus.BookUpdated, b.ID, *b)
+	return b, nil
+}
+
+func (s *Service) Delete(ctx context.Context, id int) error {
+	// Delete hooks receive the book as it was, so it is only loaded if a
+	// hook needs it
+	b := &Book{ID: id}
+	if s.hooks.Has(hooks.BeforeDelete) || s.hooks.Has(hooks.AfterDelete) {
+		var err error
+		if b, err = s.repo.GetByID(ctx, id); err != nil {
+			return err
+		}
+	}
+	if err := s.hooks.Run(ctx, hooks.BeforeDelete, b); err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.afterWrite(ctx, hooks.AfterDelete, b)
+	s.bus.Publish(ctx, eventbus.BookDeleted, id, nil)
+	return nil
+}
+
+// BulkDelete deletes the books with ids in one transaction and reports
+// which were deleted, which did not exist and which were kept because a copy
+// of theirs has loans. A delete hook vetoing any of the books deletes none
+// of them.
+func (s *Service) BulkDelete(ctx context.Context, ids []int) (*BulkDeleteResponse, error) {
+	if len(ids) == 0 || len(ids) > maxBulkBooks {
+		return nil, fmt.Errorf("%w: between 1 and %d ids are required", ErrValidation, maxBulkBooks)
+	}
+	unique := make([]int, 0, len(ids))
+	seen := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+
+	// as with Delete, the books are only loaded if a hook needs them
+	books := make(map[int]*Book, len(unique))
+	if s.hooks.Has(hooks.BeforeDelete) || s.hooks.Has(hooks.AfterDelete) {
+		for _, id := range unique {
+			b, err := s.repo.GetByID(ctx, id)
+			if errors.Is(err, ErrNotFound) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			if err := s.hooks.Run(ctx, hooks.BeforeDelete, b); err != nil {
+				return nil, err
+			}
+			books[id] = b
+		}
+	}
+
+	deleted, conflict, err := s.repo.DeleteBooks(ctx, unique)
+	if err != nil {
+		return nil, err
+	}
+	out := &BulkDeleteResponse{Deleted: deleted, NotFound: []int{}, Conflict: conflict}
+	for _, id := range conflict {
+		delete(seen, id)
+	}
+	for _, id := range deleted {
+		delete(seen, id)
+		b, ok := books[id]
+		if !ok {
+			b = &Book{ID: id}
+		}
+		s.afterWrite(ctx, hooks.AfterDelete, b)
+		s.bus.Publish(ctx, eventbus.BookDeleted, id, nil)
+	}
+	for id := range seen {
+		out.NotFound = append(out.NotFound, id)
+	}
+	sort.Ints(out.NotFound)
+	return out, nil
+}
+
+// beforeWrite runs the hooks at p on the validated book, then the write
+// checks on the result, so changes made by a hook are validated too
+func (s *Service) beforeWrite(ctx context.Context, p hooks.Point, b *Book) error {
+	if err := b.Validate(); err != nil {
+		return err
+	}
+	if err := s.hooks.Run(ctx, p, b); err != nil {
+		return err
+	}
+	return s.checkWrite(ctx, b)
+}
+
+// afterWrite runs the hooks at p. The write has already committed, so a hook
+// error is logged rather than returned.
+func (s *Service) afterWrite(ctx context.Context, p hooks.Point, b *Book) {
+	if err := s.hooks.Run(ctx, p, b); err != nil {
+		log.Printf("Book hook failed for id=%d: %v", b.ID, err)
+	}
+}
+
+// checkWrite applies the rules every written book must satisfy. The
+// duplicate check gives a precise error up front; the database constraints
+// still catch a duplicate written concurrently.
+func (s *Service) checkWrite(ctx context.Context, b *Book) error {
+	if err := b.Validate(); err != nil {
+		return err
+	}
+	ids, err := b.identifierSet()
+	if err != nil {
+		return err
+	}
+	return s.repo.findDuplicate(ctx, b.ID, ids)
+}
+
+// Import loads a CSV file of books, all or nothing. Problems with the file
+// itself wrap ErrInvalidImport.
+func (s *Service) Import(ctx context.Context, body io.Reader) (int64, error) {
+	src, err := newCSVBookSource(body)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", ErrInvalidImport, err)
+	}
+	imported, err := s.repo.ImportBooks(ctx, src)
+	if err != nil {
+		if src.Err() != nil {
+			return 0, fmt.Errorf("%w: %w", ErrInvalidImport, src.Err())
+		}
+		return 0, err
+	}
+	// Imports publish one event for the batch rather than one per book
+	s.bus.Publish(ctx, eventbus.BooksImported, 0, imported)
+	return imported, nil
+}
+
+// Export streams the catalog in id order after afterID, one chunk at a time
+func (s *Service) Export(ctx context.Context, afterID int, fn func([]Book) error) error {
+	return s.repo.ExportBooks(ctx, afterID, fn)
+}
+
+// ShelfReport lists the books with call numbers from from to to, by shelf
+func (s *Service) ShelfReport(ctx context.Context, from, to, collection string) (*ShelfReport, error) {
+	from, to = strings.TrimSpace(from), strings.TrimSpace(to)
+	if from == "" || to == "" {
+		return nil, fmt.Errorf("%w: from and to are required", ErrValidation)
+	}
+	return s.repo.ShelfReport(ctx, from, to, collection)
+}
+
+// InvalidateLists drops cached list pages after a change to data shown in
+// book responses, such as a series title
+func (s *Service) InvalidateLists() {
+	s.repo.InvalidateLists()
+}
@@ -0,0 +1,58 @@
+package book
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"public_library/utils"
+)
+
+// ShelfReport lists books with call numbers between from and to, grouped by
+// shelf location. Call numbers that begin with to are included, so a range
+// of "800" to "899" covers "899.123".
+func (r *Repository) ShelfReport(ctx context.Context, from, to, collection string) (*ShelfReport, error) {
+	log.Println("<--------ShelfReport starts-------->")
+	defer log.Println("<--------ShelfReport ends-------->")
+
+	query := fmt.Sprintf(`
+		SELECT id, title, author, call_number, shelf_location, collection
+		FROM %s
+		WHERE call_number <> ''
+			AND call_number_sort >= $1
+			AND (call_number_sort <= $2 OR starts_with(call_number_sort, $2))
+			AND ($3 = '' OR collection = $3)
+		ORDER BY call_number_sort, id
+	`, utils.BooksTable)
+
+	rows, err := r.db.QueryContext(ctx, query, CallNumberSortKey(from), CallNumberSortKey(to), collection)
+	if err != nil {
+		log.Printf("Failed to query shelf range %s-%s: %v", from, to, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	report := &ShelfReport{From: from, To: to, Shelves: []ShelfGroup{}}
+	shelfIndex := make(map[string]int)
+	for rows.Next() {
+		var item ShelfItem
+		var shelf string
+		if err := rows.Scan(&item.ID, &item.Title, &item.Author, &item.CallNumber, &shelf, &item.Collection); err != nil {
+			log.Printf("Failed to scan shelf report row: %v", err)
+			return nil, err
+		}
+		i, ok := shelfIndex[shelf]
+		if !ok {
+			i = len(report.Shelves)
+			shelfIndex[shelf] = i
+			report.Shelves = append(report.Shelves, ShelfGroup{ShelfLocation: shelf})
+		}
+		report.Shelves[i].Items = append(report.Shelves[i].Items, item)
+		report.TotalItems++
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("Row iteration error: %v", err)
+		return nil, err
+	}
+
+	return report, nil
+}
@@ -0,0 +1,53 @@
+package book
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// leadingArticles are the articles a title is filed without, by language.
+// Elided forms such as "l'" attach to the next word.
+var leadingArticles = map[string][]string{
+	"en": {"the", "a", "an"},
+	"fr": {"le", "la", "les", "l'", "un", "une"},
+	"es": {"el", "la", "los", "las", "un", "una"},
+	"it": {"il", "lo", "la", "i", "gli", "le", "l'", "un", "una", "uno"},
+	"pt": {"o", "a", "os", "as", "um", "uma"},
+	"de": {"der", "die", "das", "ein", "eine"},
+	"nl": {"de", "het", "een"},
+}
+
+// defaultArticleLanguage is whose articles are left off titles of books
+// with no language, like the English full-text configuration of search
+const defaultArticleLanguage = "en"
+
+// SortTitle returns the key a title is filed under: the title without
+// leading punctuation or the leading article of its language, so "The
+// Hobbit" files under H and "L'Étranger" under É. Case and accents are left
+// for the database collation to order.
+func SortTitle(title, language string) string {
+	s := strings.TrimLeftFunc(strings.Join(strings.Fields(title), " "), func(r rune) bool {
+		return unicode.IsPunct(r) || unicode.IsSpace(r)
+	})
+	if language == "" {
+		language = defaultArticleLanguage
+	}
+	primary, _, _ := strings.Cut(strings.ToLower(language), "-")
+	normalized := strings.ReplaceAll(s, "’", "'")
+	for _, article := range leadingArticles[primary] {
+		if len(normalized) <= len(article) || !strings.EqualFold(normalized[:len(article)], article) {
+			continue
+		}
+		rest := normalized[len(article):]
+		if !strings.HasSuffix(article, "'") {
+			if r, _ := utf8.DecodeRuneInString(rest); r != ' ' {
+				continue
+			}
+		}
+		if rest = strings.TrimLeftFunc(rest, unicode.IsSpace); rest != "" {
+			return rest
+		}
+	}
+	return s
+}
@@ -0,0 +1,202 @@
+package book
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"public_library/utils"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/language"
+)
+
+// ErrTranslationNotFound is returned when a book has no translation in the
+// requested language
+var ErrTranslationNotFound = errors.New("translation not found")
+
+type languagesCtxKey struct{}
+
+// WithLanguages makes catalog reads made with ctx return titles and
+// descriptions in the best language of an Accept-Language header, falling
+// back to the book's own. A malformed header is ignored, as HTTP allows.
+func WithLanguages(ctx context.Context, acceptLanguage string) context.Context {
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, languagesCtxKey{}, tags)
+}
+
+func preferredLanguages(ctx context.Context) []language.Tag {
+	tags, _ := ctx.Value(languagesCtxKey{}).([]language.Tag)
+	return tags
+}
+
+// languagesKey identifies the preferred languages in list cache keys
+func languagesKey(ctx context.Context) string {
+	tags := preferredLanguages(ctx)
+	names := make([]string, len(tags))
+	for i, t := range tags {
+		names[i] = t.String()
+	}
+	return strings.Join(names, ",")
+}
+
+// normalizeLanguage checks that tag is a well-formed BCP 47 tag and returns
+// its canonical form, e.g. "pt-br" becomes "pt-BR"
+func normalizeLanguage(tag string) (string, error) {
+	tag = strings.TrimSpace(tag)
+	if tag == "" {
+		return "", nil
+	}
+	t, err := language.Parse(tag)
+	if err != nil {
+		return "", fmt.Errorf("%w: language %q is not a BCP 47 tag", ErrValidation, tag)
+	}
+	return t.String(), nil
+}
+
+// localize replaces b's title and description with the translation that best
+// matches the languages preferred in ctx. The catalogued language is always
+// a candidate, so a book is only translated when that is a better match.
+func localize(ctx context.Context, b *Book) {
+	prefs := preferredLanguages(ctx)
+	if len(prefs) == 0 || len(b.translations) == 0 {
+		return
+	}
+	original := language.Und
+	if b.Language != "" {
+		original = language.Make(b.Language)
+	}
+	supported := []language.Tag{original}
+	for _, t := range b.translations {
+		supported = append(supported, language.Make(t.Language))
+	}
+	_, i, confidence := language.NewMatcher(supported).Match(prefs...)
+	if i == 0 || confidence == language.No {
+		return
+	}
+	t := b.translations[i-1]
+	b.Title, b.Language = t.Title, t.Language
+	if t.Description != "" {
+		b.Description = t.Description
+	}
+}
+
+// Validate checks the translation and normalizes its language tag
+func (t *Translation) Validate() error {
+	if t.Language == "" {
+		return fmt.Errorf("%w: language is required", ErrValidation)
+	}
+	tag, err := normalizeLanguage(t.Language)
+	if err != nil {
+		return err
+	}
+	t.Language = tag
+	t.Title = strings.TrimSpace(t.Title)
+	if t.Title == "" {
+		return fmt.Errorf("%w: title is required", ErrValidation)
+	}
+	if utf8.RuneCountInString(t.Description) > maxDescriptionLength {
+		return fmt.Errorf("%w: description must be at most %d characters", ErrValidation, maxDescriptionLength)
+	}
+	return nil
+}
+
+// translationList scans the JSON array aggregated by selectBooksSQL
+type translationList []Translation
+
+func (l *translationList) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*l = translationList{}
+		return nil
+	case []byte:
+		return json.Unmarshal(v, l)
+	case string:
+		return json.Unmarshal([]byte(v), l)
+	default:
+		return fmt.Errorf("cannot scan %T into translations", src)
+	}
+}
+
+// ListTranslations returns the translations of a book by language
+func (r *Repository) ListTranslations(ctx context.Context, bookID int) ([]Translation, error) {
+	log.Println("<--------ListTranslations starts-------->")
+	defer log.Println("<--------ListTranslations ends-------->")
+
+	query := fmt.Sprintf(`
+		SELECT language, title, description FROM %s WHERE book_id = $1 ORDER BY language
+	`, utils.BookTranslationsTable)
+
+	translations := []Translation{}
+	err := r.retrier.Do(ctx, "ListTranslations", func() error {
+		translations = translations[:0]
+		rows, err := r.db.QueryContext(ctx, query, bookID)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var t Translation
+			if err := rows.Scan(&t.Language, &t.Title, &t.Description); err != nil {
+				return err
+			}
+			translations = append(translations, t)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		log.Printf("Failed to list translations of book id=%d: %v", bookID, err)
+		return nil, err
+	}
+	return translations, nil
+}
+
+// PutTranslation creates or replaces the translation of a book in
+// t.Language
+func (r *Repository) PutTranslation(ctx context.Context, bookID int, t Translation) error {
+	log.Println("<--------PutTranslation starts-------->")
+	defer log.Println("<--------PutTranslation ends-------->")
+
+	query := fmt.Sprintf(`
+		INSERT INTO %s (book_id, language, title, description)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (book_id, language) DO UPDATE
+		SET title = EXCLUDED.title, description = EXCLUDED.description
+	`, utils.BookTranslationsTable)
+
+	if _, err := r.db.ExecContext(ctx, query, bookID, t.Language, t.Title, t.Description); err != nil {
+		log.Printf("Failed to save %s translation of book id=%d: %v", t.Language, bookID, err)
+		return err
+	}
+	r.InvalidateLists()
+	return nil
+}
+
+// DeleteTranslation removes the translation of a book in lang
+func (r *Repository) DeleteTranslation(ctx context.Context, bookID int, lang string) error {
+	log.Println("<--------DeleteTranslation starts-------->")
+	defer log.Println("<--------DeleteTranslation ends-------->")
+
+	query := fmt.Sprintf(`DELETE FROM %s WHERE book_id = $1 AND language = $2`, utils.BookTranslationsTable)
+
+	result, err := r.db.ExecContext(ctx, query, bookID, lang)
+	if err != nil {
+		log.Printf("Failed to delete %s translation of book id=%d: %v", lang, bookID, err)
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		log.Printf("Failed to get rows affected for translation delete: %v", err)
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrTranslationNotFound
+	}
+	r.InvalidateLists()
+	return nil
+}
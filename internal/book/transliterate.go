@@ -0,0 +1,64 @@
+package book
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// cyrillicLatin romanizes Russian, Ukrainian, Belarusian and Bulgarian
+// letters close to BGN/PCGN, the romanization library patrons are most
+// likely to type
+var cyrillicLatin = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e",
+	'ж': "zh", 'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m",
+	'н': "n", 'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u",
+	'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch",
+	'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu", 'я': "ya",
+	'і': "i", 'ї': "yi", 'є': "ye", 'ґ': "g", 'ў': "u",
+}
+
+// greekLatin romanizes unaccented Greek letters after ELOT 743
+var greekLatin = map[rune]string{
+	'α': "a", 'β': "v", 'γ': "g", 'δ': "d", 'ε': "e", 'ζ': "z", 'η': "i",
+	'θ': "th", 'ι': "i", 'κ': "k", 'λ': "l", 'μ': "m", 'ν': "n", 'ξ': "x",
+	'ο': "o", 'π': "p", 'ρ': "r", 'σ': "s", 'ς': "s", 'τ': "t", 'υ': "y",
+	'φ': "f", 'χ': "ch", 'ψ': "ps", 'ω': "o",
+}
+
+// Romanize returns the lower-case Latin form of s that search matches
+// against: Cyrillic and Greek are transliterated and accents dropped, so
+// "Достоевский", "Dostoevsky" and "Dostoévsky" all become "dostoevsky".
+// Scripts without a mechanical romanization, such as Chinese and Japanese,
+// are left as they are; a book's romanized field carries their pinyin or
+// romaji.
+func Romanize(s string) string {
+	runes := []rune(strings.ToLower(norm.NFC.String(s)))
+	var out strings.Builder
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		// the adjectival ending -ий/-ый is written -y, as in Dostoevsky
+		if (r == 'и' || r == 'ы') && i+1 < len(runes) && runes[i+1] == 'й' &&
+			(i+2 == len(runes) || !unicode.IsLetter(runes[i+2])) {
+			out.WriteString("y")
+			i++
+			continue
+		}
+		if latin, ok := cyrillicLatin[r]; ok {
+			out.WriteString(latin)
+			continue
+		}
+		for _, d := range norm.NFD.String(string(r)) {
+			if unicode.Is(unicode.Mn, d) {
+				continue
+			}
+			if latin, ok := greekLatin[d]; ok {
+				out.WriteString(latin)
+			} else {
+				out.WriteRune(d)
+			}
+		}
+	}
+	return out.String()
+}
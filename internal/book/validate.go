@@ -0,0 +1,65 @@
+package book
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	"unicode/utf8"
+)
+
+// ErrValidation is wrapped by every error returned from Validate
+var ErrValidation = errors.New("validation failed")
+
+const (
+	// Gutenberg's Bible is as old as a printed, catalogued edition gets
+	minPublicationYear = 1450
+	maxPageCount       = 100000
+	maxEditionLength   = 100
+)
+
+// Validate checks that the book's descriptive fields hold sensible values.
+// It normalizes the format, audience, accessibility and language codes in
+// place.
+func (b *Book) Validate() error {
+	format, err := normalizeFormat(b.Format)
+	if err != nil {
+		return err
+	}
+	b.Format = format
+	audience, err := normalizeAudience(b.Audience)
+	if err != nil {
+		return err
+	}
+	b.Audience = audience
+	features, err := normalizeAccessibilityFeatures(b.AccessibilityFeatures)
+	if err != nil {
+		return err
+	}
+	b.AccessibilityFeatures = features
+	if b.Language, err = normalizeLanguage(b.Language); err != nil {
+		return err
+	}
+
+	if b.PublicationYear != nil {
+		maxYear := time.Now().Year() + 1 // forthcoming titles are catalogued ahead of release
+		if *b.PublicationYear < minPublicationYear || *b.PublicationYear > maxYear {
+			return fmt.Errorf("%w: publication_year must be between %d and %d", ErrValidation, minPublicationYear, maxYear)
+		}
+	}
+	if b.PageCount != nil && (*b.PageCount < 1 || *b.PageCount > maxPageCount) {
+		return fmt.Errorf("%w: page_count must be between 1 and %d", ErrValidation, maxPageCount)
+	}
+	if utf8.RuneCountInString(b.Description) > maxDescriptionLength {
+		return fmt.Errorf("%w: description must be at most %d characters", ErrValidation, maxDescriptionLength)
+	}
+	if len(b.Edition) > maxEditionLength {
+		return fmt.Errorf("%w: edition must be at most %d characters", ErrValidation, maxEditionLength)
+	}
+	if b.Series != nil && b.Series.Position != nil && *b.Series.Position <= 0 {
+		return fmt.Errorf("%w: series position must be positive", ErrValidation)
+	}
+	if _, err := b.identifierSet(); err != nil {
+		return err
+	}
+	return nil
+}
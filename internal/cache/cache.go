@@ -0,0 +1,127 @@
+package cache
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	hits = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "cache_hits_total",
+		Help: "Number of cache lookups that returned a live entry.",
+	}, []string{"cache"})
+
+	misses = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "cache_misses_total",
+		Help: "Number of cache lookups that found nothing or an expired entry.",
+	}, []string{"cache"})
+)
+
+// Config controls an in-memory TTL cache. A zero TTL disables caching.
+type Config struct {
+	TTL        time.Duration `yaml:"ttl"`
+	MaxEntries int           `yaml:"max_entries"`
+}
+
+type entry[V any] struct {
+	value     V
+	expiresAt time.Time
+}
+
+// Cache is a small in-process TTL cache safe for concurrent use
+type Cache[K comparable, V any] struct {
+	name  string
+	cfg   Config
+	mu    sync.Mutex
+	items map[K]entry[V]
+	// gen counts purges, so values loaded before one can be told apart
+	gen uint64
+}
+
+func New[K comparable, V any](name string, cfg Config) *Cache[K, V] {
+	return &Cache[K, V]{name: name, cfg: cfg, items: make(map[K]entry[V])}
+}
+
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	var zero V
+	if c.cfg.TTL <= 0 {
+		return zero, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.items[key]
+	if !ok || time.Now().After(e.expiresAt) {
+		misses.WithLabelValues(c.name).Inc()
+		return zero, false
+	}
+	hits.WithLabelValues(c.name).Inc()
+	return e.value, true
+}
+
+func (c *Cache[K, V]) Set(key K, value V) {
+	if c.cfg.TTL <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.setLocked(key, value)
+}
+
+// Generation returns the number of purges so far. Read it before loading a
+// value and store the value with SetUnlessPurged.
+func (c *Cache[K, V]) Generation() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.gen
+}
+
+// SetUnlessPurged stores value like Set, unless the cache was purged after gen
+// was read: the value may then have been loaded before the data changed.
+func (c *Cache[K, V]) SetUnlessPurged(gen uint64, key K, value V) {
+	if c.cfg.TTL <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.gen == gen {
+		c.setLocked(key, value)
+	}
+}
+
+// Purge drops every entry; call it whenever the underlying data changes
+func (c *Cache[K, V]) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.items = make(map[K]entry[V])
+	c.gen++
+}
+
+func (c *Cache[K, V]) setLocked(key K, value V) {
+	if c.cfg.MaxEntries > 0 && len(c.items) >= c.cfg.MaxEntries {
+		c.evictLocked()
+	}
+	c.items[key] = entry[V]{value: value, expiresAt: time.Now().Add(c.cfg.TTL)}
+}
+
+// evictLocked drops expired entries, or an arbitrary one if none have expired
+func (c *Cache[K, V]) evictLocked() {
+	now := time.Now()
+	for k, e := range c.items {
+		if now.After(e.expiresAt) {
+			delete(c.items, k)
+		}
+	}
+	if len(c.items) < c.cfg.MaxEntries {
+		return
+	}
+	for k := range c.items {
+		delete(c.items, k)
+		return
+	}
+}
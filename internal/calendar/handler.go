@@ -0,0 +1,172 @@
+package calendar
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"public_library/internal/httperr"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	svc    *Service
+	logger *zap.Logger
+}
+
+func NewHandler(svc *Service, l *zap.Logger) *Handler {
+	return &Handler{svc: svc, logger: l}
+}
+
+// GET /closures?branch=Central&from=2026-12-01&to=2026-12-31
+
+// ListClosures godoc
+// @Summary List library closures
+// @Description Lists the closures overlapping a period, earliest first. With a branch, lists that branch's closures and those of every branch.
+// @Tags calendar
+// @Produce json
+// @Param branch query string false "Branch"
+// @Param from query string false "First day, YYYY-MM-DD"
+// @Param to query string false "Last day, YYYY-MM-DD"
+// @Success 200 {array} Closure
+// @Failure 400 {object} map[string]string
+// @Router /closures [get]
+func (h *Handler) ListClosures(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	list, err := h.svc.List(r.Context(), ListRequest{Branch: q.Get("branch"), From: q.Get("from"), To: q.Get("to")})
+	if err != nil {
+		h.writeError(w, "failed to list closures", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// GET /closures/{id}
+
+// GetClosure godoc
+// @Summary Get a closure
+// @Tags calendar
+// @Produce json
+// @Param id path int true "Closure ID"
+// @Success 200 {object} Closure
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /closures/{id} [get]
+func (h *Handler) GetClosure(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	c, err := h.svc.Get(r.Context(), id)
+	if err != nil {
+		h.writeError(w, "failed to get closure", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(c)
+}
+
+// POST /closures
+
+// CreateClosure godoc
+// @Summary Add a closure to the calendar
+// @Description Closes a branch, or with no branch every branch, from starts_on to ends_on (default starts_on). Loans don't fall due on closed days and aren't fined for them.
+// @Tags calendar
+// @Accept json
+// @Produce json
+// @Param closure body Closure true "Closure to add"
+// @Success 201 {object} Closure
+// @Failure 400 {object} map[string]string
+// @Router /closures [post]
+func (h *Handler) CreateClosure(w http.ResponseWriter, r *http.Request) {
+	var c Closure
+	if err := json.NewDecoder(r.Body).Decode(&c); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if err := h.svc.Create(r.Context(), &c); err != nil {
+		h.writeError(w, "failed to create closure", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(c)
+}
+
+// PUT /closures/{id}
+
+// UpdateClosure godoc
+// @Summary Update a closure
+// @Description Loans already out keep their due dates.
+// @Tags calendar
+// @Accept json
+// @Produce json
+// @Param id path int true "Closure ID"
+// @Param closure body Closure true "Updated closure"
+// @Success 200 {object} Closure
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /closures/{id} [put]
+func (h *Handler) UpdateClosure(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	var c Closure
+	if err := json.NewDecoder(r.Body).Decode(&c); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	c.ID = id
+	if err := h.svc.Update(r.Context(), &c); err != nil {
+		h.writeError(w, "failed to update closure", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(c)
+}
+
+// DELETE /closures/{id}
+
+// DeleteClosure godoc
+// @Summary Remove a closure from the calendar
+// @Tags calendar
+// @Param id path int true "Closure ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /closures/{id} [delete]
+func (h *Handler) DeleteClosure(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	if err := h.svc.Delete(r.Context(), id); err != nil {
+		h.writeError(w, "failed to delete closure", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func pathID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "invalid closure ID", http.StatusBadRequest)
+		return 0, false
+	}
+	return id, true
+}
+
+func (h *Handler) writeError(w http.ResponseWriter, msg string, err error) {
+	switch {
+	case errors.Is(err, ErrInvalid):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, ErrNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	default:
+		httperr.Write(w, h.logger, msg, err)
+	}
+}
@@ -0,0 +1,23 @@
+package calendar
+
+import "time"
+
+// Closure is a run of days a branch, or with no branch the whole library,
+// is closed, e.g. a public holiday or a refurbishment
+type Closure struct {
+	ID int `json:"id" example:"1"`
+	// Branch is empty for closures of every branch
+	Branch    string    `json:"branch,omitempty" example:"Central"`
+	StartsOn  string    `json:"starts_on" example:"2026-12-24"`
+	EndsOn    string    `json:"ends_on" example:"2026-12-26"`
+	Reason    string    `json:"reason,omitempty" example:"Christmas"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ListRequest selects closures of a branch, with those of every branch,
+// overlapping a period; empty fields don't filter
+type ListRequest struct {
+	Branch string
+	From   string // inclusive, YYYY-MM-DD
+	To     string // inclusive, YYYY-MM-DD
+}
@@ -0,0 +1,143 @@
+package calendar
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"public_library/internal/db"
+	"public_library/utils"
+)
+
+// ErrNotFound is returned when no closure has the requested id
+var ErrNotFound = errors.New("closure not found")
+
+// Dates are selected as text so they read as YYYY-MM-DD
+var closureMapping = db.Mapping[Closure]{
+	Table:   utils.ClosuresTable,
+	Columns: []string{"id", "branch", "starts_on::text", "ends_on::text", "reason", "created_at"},
+	Fields: func(c *Closure) []interface{} {
+		return []interface{}{&c.ID, &c.Branch, &c.StartsOn, &c.EndsOn, &c.Reason, &c.CreatedAt}
+	},
+	Writable: []string{"branch", "starts_on", "ends_on", "reason"},
+	Values: func(c *Closure) []interface{} {
+		return []interface{}{c.Branch, c.StartsOn, c.EndsOn, c.Reason}
+	},
+	ID: func(c *Closure) int { return c.ID },
+}
+
+type Repository struct {
+	db       *db.DB
+	closures *db.Table[Closure]
+}
+
+func NewRepository(conn *db.DB) *Repository {
+	return &Repository{db: conn, closures: db.NewTable(conn, closureMapping)}
+}
+
+// List returns the closures matching req, earliest first
+func (r *Repository) List(ctx context.Context, req ListRequest) ([]Closure, error) {
+	query := fmt.Sprintf(`
+		SELECT id, branch, starts_on::text, ends_on::text, reason, created_at
+		FROM %s
+		WHERE ($1 = '' OR branch IN ('', $1))
+			AND (NULLIF($2, '') IS NULL OR ends_on >= NULLIF($2, '')::date)
+			AND (NULLIF($3, '') IS NULL OR starts_on <= NULLIF($3, '')::date)
+		ORDER BY starts_on, branch, id
+	`, utils.ClosuresTable)
+	rows, err := r.db.QueryContext(ctx, query, req.Branch, req.From, req.To)
+	if err != nil {
+		log.Printf("Failed to list closures: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []Closure{}
+	for rows.Next() {
+		var c Closure
+		if err := rows.Scan(closureMapping.Fields(&c)...); err != nil {
+			return nil, err
+		}
+		list = append(list, c)
+	}
+	return list, rows.Err()
+}
+
+// Affecting returns the closures of branch and of every branch overlapping
+// from to to, inclusive
+func (r *Repository) Affecting(ctx context.Context, branch, from, to string) ([]Closure, error) {
+	query := fmt.Sprintf(`
+		SELECT id, branch, starts_on::text, ends_on::text, reason, created_at
+		FROM %s
+		WHERE branch IN ('', $1) AND ends_on >= $2 AND starts_on <= $3
+		ORDER BY starts_on, id
+	`, utils.ClosuresTable)
+	rows, err := r.db.QueryContext(ctx, query, branch, from, to)
+	if err != nil {
+		log.Printf("Failed to look up the closures of branch %q: %v", branch, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []Closure
+	for rows.Next() {
+		var c Closure
+		if err := rows.Scan(closureMapping.Fields(&c)...); err != nil {
+			return nil, err
+		}
+		list = append(list, c)
+	}
+	return list, rows.Err()
+}
+
+func (r *Repository) Get(ctx context.Context, id int) (*Closure, error) {
+	c, err := r.closures.Get(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		log.Printf("Failed to get closure id=%d: %v", id, err)
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (r *Repository) Create(ctx context.Context, c *Closure) error {
+	log.Println("<--------Create closure starts-------->")
+	defer log.Println("<--------Create closure ends-------->")
+
+	if err := r.closures.Insert(ctx, c); err != nil {
+		log.Printf("Failed to create closure %+v: %v", c, err)
+		return err
+	}
+	return nil
+}
+
+func (r *Repository) Update(ctx context.Context, c *Closure) error {
+	log.Println("<--------Update closure starts-------->")
+	defer log.Println("<--------Update closure ends-------->")
+
+	if err := r.closures.Update(ctx, c); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		log.Printf("Failed to update closure id=%d: %v", c.ID, err)
+		return err
+	}
+	return nil
+}
+
+func (r *Repository) Delete(ctx context.Context, id int) error {
+	log.Println("<--------Delete closure starts-------->")
+	defer log.Println("<--------Delete closure ends-------->")
+
+	if err := r.closures.Delete(ctx, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		log.Printf("Failed to delete closure id=%d: %v", id, err)
+		return err
+	}
+	return nil
+}
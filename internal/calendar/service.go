@@ -0,0 +1,133 @@
+// Package calendar keeps the days branches are closed. Circulation moves
+// due dates off closed days and doesn't fine for them.
+package calendar
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ErrInvalid is wrapped by errors about the content of a request
+var ErrInvalid = errors.New("invalid closure")
+
+const (
+	maxTextLength = 200
+	// lookahead bounds how far NextOpen looks for an open day
+	lookahead = 366
+)
+
+type Service struct {
+	repo *Repository
+}
+
+func NewService(repo *Repository) *Service {
+	return &Service{repo: repo}
+}
+
+// List returns the closures matching req, earliest first
+func (s *Service) List(ctx context.Context, req ListRequest) ([]Closure, error) {
+	for name, date := range map[string]string{"from": req.From, "to": req.To} {
+		if _, err := time.Parse(time.DateOnly, date); date != "" && err != nil {
+			return nil, fmt.Errorf("%w: %s must be a date (YYYY-MM-DD)", ErrInvalid, name)
+		}
+	}
+	req.Branch = strings.TrimSpace(req.Branch)
+	return s.repo.List(ctx, req)
+}
+
+func (s *Service) Get(ctx context.Context, id int) (*Closure, error) {
+	return s.repo.Get(ctx, id)
+}
+
+func (s *Service) Create(ctx context.Context, c *Closure) error {
+	if err := c.validate(); err != nil {
+		return err
+	}
+	return s.repo.Create(ctx, c)
+}
+
+func (s *Service) Update(ctx context.Context, c *Closure) error {
+	if err := c.validate(); err != nil {
+		return err
+	}
+	return s.repo.Update(ctx, c)
+}
+
+func (s *Service) Delete(ctx context.Context, id int) error {
+	return s.repo.Delete(ctx, id)
+}
+
+// NextOpen returns t, or if branch is closed on t's day, the same time on
+// the first day after it that the branch is open
+func (s *Service) NextOpen(ctx context.Context, branch string, t time.Time) (time.Time, error) {
+	closures, err := s.repo.Affecting(ctx, branch, t.Format(time.DateOnly),
+		t.AddDate(0, 0, lookahead).Format(time.DateOnly))
+	if err != nil {
+		return t, err
+	}
+	// closures are ordered by start, so one pass moves t past every run of
+	// overlapping or back-to-back closures
+	for _, c := range closures {
+		day := t.Format(time.DateOnly)
+		if c.StartsOn <= day && day <= c.EndsOn {
+			end, err := time.ParseInLocation(time.DateOnly, c.EndsOn, t.Location())
+			if err != nil {
+				return t, err
+			}
+			t = time.Date(end.Year(), end.Month(), end.Day()+1, t.Hour(), t.Minute(), t.Second(), 0, t.Location())
+		}
+	}
+	return t, nil
+}
+
+// ClosedDays counts the days after from's day up to and including to's day
+// that branch is closed
+func (s *Service) ClosedDays(ctx context.Context, branch string, from, to time.Time) (int, error) {
+	first := time.Date(from.Year(), from.Month(), from.Day()+1, 0, 0, 0, 0, from.Location())
+	last := to.In(from.Location()).Format(time.DateOnly)
+	if first.Format(time.DateOnly) > last {
+		return 0, nil
+	}
+	closures, err := s.repo.Affecting(ctx, branch, first.Format(time.DateOnly), last)
+	if err != nil {
+		return 0, err
+	}
+	closed := 0
+	for d := first; d.Format(time.DateOnly) <= last; d = d.AddDate(0, 0, 1) {
+		day := d.Format(time.DateOnly)
+		for _, c := range closures {
+			if c.StartsOn <= day && day <= c.EndsOn {
+				closed++
+				break
+			}
+		}
+	}
+	return closed, nil
+}
+
+func (c *Closure) validate() error {
+	c.Branch = strings.TrimSpace(c.Branch)
+	c.Reason = strings.TrimSpace(c.Reason)
+	if utf8.RuneCountInString(c.Branch) > maxTextLength || utf8.RuneCountInString(c.Reason) > maxTextLength {
+		return fmt.Errorf("%w: branch and reason must be at most %d characters", ErrInvalid, maxTextLength)
+	}
+	starts, err := time.Parse(time.DateOnly, c.StartsOn)
+	if err != nil {
+		return fmt.Errorf("%w: starts_on is required and must be a date (YYYY-MM-DD)", ErrInvalid)
+	}
+	if c.EndsOn == "" {
+		c.EndsOn = c.StartsOn
+	}
+	ends, err := time.Parse(time.DateOnly, c.EndsOn)
+	if err != nil {
+		return fmt.Errorf("%w: ends_on must be a date (YYYY-MM-DD)", ErrInvalid)
+	}
+	if ends.Before(starts) {
+		return fmt.Errorf("%w: ends_on must not be before starts_on", ErrInvalid)
+	}
+	return nil
+}
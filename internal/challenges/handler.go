@@ -0,0 +1,250 @@
+package challenges
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"public_library/internal/httperr"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	svc    *Service
+	logger *zap.Logger
+}
+
+func NewHandler(svc *Service, l *zap.Logger) *Handler {
+	return &Handler{svc: svc, logger: l}
+}
+
+// GET /members/{id}/challenges
+
+// ListChallenges godoc
+// @Summary List a member's reading challenges
+// @Description Lists the member's challenges with how many books are logged toward each, most recent year first.
+// @Tags challenges
+// @Produce json
+// @Param id path string true "Member ID"
+// @Success 200 {array} Challenge
+// @Router /members/{id}/challenges [get]
+func (h *Handler) ListChallenges(w http.ResponseWriter, r *http.Request) {
+	list, err := h.svc.List(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		h.writeError(w, "failed to list reading challenges", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// GET /members/{id}/challenges/{year}
+
+// GetChallenge godoc
+// @Summary Get a member's progress in a reading challenge
+// @Description Returns the challenge with the books logged toward it in the order they were finished and the badges they earned.
+// @Tags challenges
+// @Produce json
+// @Param id path string true "Member ID"
+// @Param year path int true "Year"
+// @Success 200 {object} Progress
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /members/{id}/challenges/{year} [get]
+func (h *Handler) GetChallenge(w http.ResponseWriter, r *http.Request) {
+	year, ok := pathYear(w, r)
+	if !ok {
+		return
+	}
+	p, err := h.svc.Get(r.Context(), mux.Vars(r)["id"], year)
+	if err != nil {
+		h.writeError(w, "failed to get reading challenge", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(p)
+}
+
+// PUT /members/{id}/challenges/{year}
+
+// SetChallengeGoal godoc
+// @Summary Set a member's reading goal for a year
+// @Description Joins the member to the year's challenge with the goal, or changes their goal. From then on, every book they return that year is logged toward it. hide_from_leaderboard keeps them off the leaderboard.
+// @Tags challenges
+// @Accept json
+// @Produce json
+// @Param id path string true "Member ID"
+// @Param year path int true "Year"
+// @Param goal body GoalRequest true "Goal"
+// @Success 200 {object} Progress "Goal changed"
+// @Success 201 {object} Progress "Challenge joined"
+// @Failure 400 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Router /members/{id}/challenges/{year} [put]
+func (h *Handler) SetChallengeGoal(w http.ResponseWriter, r *http.Request) {
+	year, ok := pathYear(w, r)
+	if !ok {
+		return
+	}
+	var req GoalRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	p, created, err := h.svc.SetGoal(r.Context(), mux.Vars(r)["id"], year, req)
+	if err != nil {
+		h.writeError(w, "failed to set reading goal", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if created {
+		w.WriteHeader(http.StatusCreated)
+	}
+	json.NewEncoder(w).Encode(p)
+}
+
+// DELETE /members/{id}/challenges/{year}
+
+// DeleteChallenge godoc
+// @Summary Leave a reading challenge
+// @Description Removes the member's challenge for the year and the books logged toward it.
+// @Tags challenges
+// @Param id path string true "Member ID"
+// @Param year path int true "Year"
+// @Success 204 "No Content"
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /members/{id}/challenges/{year} [delete]
+func (h *Handler) DeleteChallenge(w http.ResponseWriter, r *http.Request) {
+	year, ok := pathYear(w, r)
+	if !ok {
+		return
+	}
+	if err := h.svc.Delete(r.Context(), mux.Vars(r)["id"], year); err != nil {
+		h.writeError(w, "failed to delete reading challenge", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// POST /members/{id}/challenges/{year}/books
+
+// LogChallengeBook godoc
+// @Summary Log a book read toward a challenge
+// @Description Logs a catalog book the member finished, such as one read from their own shelves, toward their challenge. Books returned that year are logged by themselves. A book counts once per challenge.
+// @Tags challenges
+// @Accept json
+// @Produce json
+// @Param id path string true "Member ID"
+// @Param year path int true "Year"
+// @Param book body LogRequest true "Book finished"
+// @Success 200 {object} Progress
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Router /members/{id}/challenges/{year}/books [post]
+func (h *Handler) LogChallengeBook(w http.ResponseWriter, r *http.Request) {
+	year, ok := pathYear(w, r)
+	if !ok {
+		return
+	}
+	var req LogRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	p, err := h.svc.LogBook(r.Context(), mux.Vars(r)["id"], year, req)
+	if err != nil {
+		h.writeError(w, "failed to log book", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(p)
+}
+
+// DELETE /members/{id}/challenges/{year}/books/{book_id}
+
+// RemoveChallengeBook godoc
+// @Summary Remove a book from a challenge
+// @Tags challenges
+// @Param id path string true "Member ID"
+// @Param year path int true "Year"
+// @Param book_id path int true "Book ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /members/{id}/challenges/{year}/books/{book_id} [delete]
+func (h *Handler) RemoveChallengeBook(w http.ResponseWriter, r *http.Request) {
+	year, ok := pathYear(w, r)
+	if !ok {
+		return
+	}
+	vars := mux.Vars(r)
+	bookID, err := strconv.Atoi(vars["book_id"])
+	if err != nil {
+		http.Error(w, "invalid book ID", http.StatusBadRequest)
+		return
+	}
+	if err := h.svc.RemoveBook(r.Context(), vars["id"], year, bookID); err != nil {
+		h.writeError(w, "failed to remove book", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GET /challenges/{year}/leaderboard?limit=10
+
+// GetLeaderboard godoc
+// @Summary Get a reading challenge leaderboard
+// @Description Ranks the members with a challenge for the year by the books logged toward it; ties share a rank. Members who set hide_from_leaderboard are left out, and the others are shown by first name and last initial only.
+// @Tags challenges
+// @Produce json
+// @Param year path int true "Year"
+// @Param limit query int false "Standings to return, at most 100" default(10)
+// @Success 200 {object} Leaderboard
+// @Failure 400 {object} map[string]string
+// @Router /challenges/{year}/leaderboard [get]
+func (h *Handler) GetLeaderboard(w http.ResponseWriter, r *http.Request) {
+	year, ok := pathYear(w, r)
+	if !ok {
+		return
+	}
+	limit := 0
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			http.Error(w, "invalid limit parameter", http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+	board, err := h.svc.Leaderboard(r.Context(), year, limit)
+	if err != nil {
+		h.writeError(w, "failed to get leaderboard", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(board)
+}
+
+func pathYear(w http.ResponseWriter, r *http.Request) (int, bool) {
+	year, err := strconv.Atoi(mux.Vars(r)["year"])
+	if err != nil {
+		http.Error(w, "invalid year", http.StatusBadRequest)
+		return 0, false
+	}
+	return year, true
+}
+
+func (h *Handler) writeError(w http.ResponseWriter, msg string, err error) {
+	switch {
+	case errors.Is(err, ErrInvalid):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, ErrNotFound), errors.Is(err, ErrBookNotLogged):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	default:
+		httperr.Write(w, h.logger, msg, err)
+	}
+}
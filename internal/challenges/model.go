@@ -0,0 +1,82 @@
+package challenges
+
+import "time"
+
+// Sources of the books logged toward a challenge
+const (
+	// SourceReturn books were logged when the member returned a loan
+	SourceReturn = "return"
+	// SourceManual books were logged by the member
+	SourceManual = "manual"
+)
+
+// Challenge is a member's goal of books to read in a year
+type Challenge struct {
+	ID       int    `json:"id" example:"4"`
+	MemberID string `json:"member_id" example:"m-1001"`
+	Year     int    `json:"year" example:"2026"`
+	Goal     int    `json:"goal" example:"24"`
+	// Completed counts the books logged toward the goal
+	Completed int `json:"completed" example:"9"`
+	// HideFromLeaderboard opts the member out of the year's leaderboard
+	HideFromLeaderboard bool      `json:"hide_from_leaderboard" example:"false"`
+	CreatedAt           time.Time `json:"created_at"`
+	UpdatedAt           time.Time `json:"updated_at"`
+}
+
+// GoalRequest sets a member's goal for a year
+type GoalRequest struct {
+	Goal                int  `json:"goal" example:"24"`
+	HideFromLeaderboard bool `json:"hide_from_leaderboard" example:"false"`
+}
+
+// Book is a book logged toward a challenge
+type Book struct {
+	BookID int    `json:"book_id" example:"1"`
+	Title  string `json:"title" example:"The Great Gatsby"`
+	Author string `json:"author" example:"F. Scott Fitzgerald"`
+	// CompletedOn is the day the book was finished, YYYY-MM-DD
+	CompletedOn string `json:"completed_on" example:"2026-03-17"`
+	Source      string `json:"source" example:"return" enums:"return,manual"`
+}
+
+// LogRequest logs a book the member finished
+type LogRequest struct {
+	BookID int `json:"book_id" example:"1"`
+	// CompletedOn defaults to today and must fall in the challenge's year
+	CompletedOn string `json:"completed_on" example:"2026-03-17"`
+}
+
+// Badge is a milestone of a challenge
+type Badge struct {
+	Code string `json:"code" example:"ten_books"`
+	Name string `json:"name" example:"Ten books"`
+	// EarnedOn is the day the book that earned it was finished
+	EarnedOn string `json:"earned_on" example:"2026-06-02"`
+}
+
+// Progress is a challenge with its books and the badges earned so far
+type Progress struct {
+	Challenge Challenge `json:"challenge"`
+	// Percent of the goal reached, capped at 100
+	Percent   int     `json:"percent" example:"37"`
+	Remaining int     `json:"remaining" example:"15"`
+	Books     []Book  `json:"books"`
+	Badges    []Badge `json:"badges"`
+}
+
+// Standing is a member's place on a leaderboard
+type Standing struct {
+	Rank int `json:"rank" example:"1"`
+	// Name shows the member's first name and last initial only
+	Name      string `json:"name" example:"Ada L."`
+	Completed int    `json:"completed" example:"31"`
+	Goal      int    `json:"goal" example:"24"`
+	Percent   int    `json:"percent" example:"100"`
+}
+
+// Leaderboard ranks the members with a challenge for a year by books read
+type Leaderboard struct {
+	Year      int        `json:"year" example:"2026"`
+	Standings []Standing `json:"standings"`
+}
@@ -0,0 +1,232 @@
+package challenges
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"public_library/internal/db"
+	"public_library/utils"
+	"time"
+)
+
+var (
+	// ErrNotFound is returned when the member has no challenge for the year
+	ErrNotFound = errors.New("reading challenge not found")
+	// ErrBookNotLogged is returned for removing a book not logged toward
+	// the challenge
+	ErrBookNotLogged = errors.New("book is not logged toward this challenge")
+)
+
+type Repository struct {
+	db *db.DB
+}
+
+func NewRepository(conn *db.DB) *Repository {
+	return &Repository{db: conn}
+}
+
+// selectChallengesSQL selects challenges ("c") with how many books were
+// logged toward them
+const selectChallengesSQL = `
+	SELECT c.id, c.member_id, c.year, c.goal,
+		(SELECT count(*) FROM %[2]s b WHERE b.challenge_id = c.id),
+		c.hide_from_leaderboard, c.created_at, c.updated_at
+	FROM %[1]s c
+`
+
+func selectChallenges(where string) string {
+	return fmt.Sprintf(selectChallengesSQL, utils.ReadingChallengesTable, utils.ChallengeBooksTable) + where
+}
+
+func scanChallenge(row interface{ Scan(...interface{}) error }) (Challenge, error) {
+	var c Challenge
+	err := row.Scan(&c.ID, &c.MemberID, &c.Year, &c.Goal, &c.Completed, &c.HideFromLeaderboard, &c.CreatedAt,
+		&c.UpdatedAt)
+	return c, err
+}
+
+// SetGoal creates the member's challenge for the year, or replaces its goal
+// and leaderboard choice, and reports whether it was created
+func (r *Repository) SetGoal(ctx context.Context, memberID string, year int, req GoalRequest) (*Challenge, bool, error) {
+	log.Println("<--------Set reading challenge goal starts-------->")
+	defer log.Println("<--------Set reading challenge goal ends-------->")
+
+	var created bool
+	query := fmt.Sprintf(`
+		INSERT INTO %s (member_id, year, goal, hide_from_leaderboard) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (member_id, year) DO UPDATE SET goal = EXCLUDED.goal,
+			hide_from_leaderboard = EXCLUDED.hide_from_leaderboard, updated_at = now()
+		RETURNING xmax = 0
+	`, utils.ReadingChallengesTable)
+	err := r.db.QueryRowContext(ctx, query, memberID, year, req.Goal, req.HideFromLeaderboard).Scan(&created)
+	if err != nil {
+		log.Printf("Failed to set the %d challenge of member id=%s: %v", year, memberID, err)
+		return nil, false, err
+	}
+	c, err := r.Get(ctx, memberID, year)
+	if err != nil {
+		return nil, false, err
+	}
+	return c, created, nil
+}
+
+func (r *Repository) Get(ctx context.Context, memberID string, year int) (*Challenge, error) {
+	c, err := scanChallenge(r.db.QueryRowContext(ctx, selectChallenges(`WHERE c.member_id = $1 AND c.year = $2`),
+		memberID, year))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		log.Printf("Failed to get the %d challenge of member id=%s: %v", year, memberID, err)
+		return nil, err
+	}
+	return &c, nil
+}
+
+// List returns the member's challenges, most recent year first
+func (r *Repository) List(ctx context.Context, memberID string) ([]Challenge, error) {
+	rows, err := r.db.QueryContext(ctx, selectChallenges(`WHERE c.member_id = $1 ORDER BY c.year DESC`), memberID)
+	if err != nil {
+		log.Printf("Failed to list challenges of member id=%s: %v", memberID, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []Challenge{}
+	for rows.Next() {
+		c, err := scanChallenge(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, c)
+	}
+	return list, rows.Err()
+}
+
+// Delete removes the challenge and the books logged toward it
+func (r *Repository) Delete(ctx context.Context, memberID string, year int) error {
+	log.Println("<--------Delete reading challenge starts-------->")
+	defer log.Println("<--------Delete reading challenge ends-------->")
+
+	query := fmt.Sprintf(`DELETE FROM %s WHERE member_id = $1 AND year = $2`, utils.ReadingChallengesTable)
+	res, err := r.db.ExecContext(ctx, query, memberID, year)
+	if err != nil {
+		log.Printf("Failed to delete the %d challenge of member id=%s: %v", year, memberID, err)
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ListBooks returns the books logged toward the challenge in the order they
+// were finished
+func (r *Repository) ListBooks(ctx context.Context, challengeID int) ([]Book, error) {
+	query := fmt.Sprintf(`
+		SELECT k.book_id, b.title, b.author, to_char(k.completed_on, 'YYYY-MM-DD'), k.source
+		FROM %s k JOIN %s b ON b.id = k.book_id
+		WHERE k.challenge_id = $1
+		ORDER BY k.completed_on, k.logged_at, k.book_id
+	`, utils.ChallengeBooksTable, utils.BooksTable)
+	rows, err := r.db.QueryContext(ctx, query, challengeID)
+	if err != nil {
+		log.Printf("Failed to list books of challenge id=%d: %v", challengeID, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []Book{}
+	for rows.Next() {
+		var b Book
+		if err := rows.Scan(&b.BookID, &b.Title, &b.Author, &b.CompletedOn, &b.Source); err != nil {
+			return nil, err
+		}
+		list = append(list, b)
+	}
+	return list, rows.Err()
+}
+
+// LogBook logs the book toward the challenge. A book counts once per
+// challenge; logging it again keeps the first entry.
+func (r *Repository) LogBook(ctx context.Context, challengeID, bookID int, completedOn time.Time, source string) error {
+	query := fmt.Sprintf(`
+		INSERT INTO %s (challenge_id, book_id, completed_on, source) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (challenge_id, book_id) DO NOTHING
+	`, utils.ChallengeBooksTable)
+	if _, err := r.db.ExecContext(ctx, query, challengeID, bookID, completedOn, source); err != nil {
+		log.Printf("Failed to log book id=%d toward challenge id=%d: %v", bookID, challengeID, err)
+		return err
+	}
+	return nil
+}
+
+// LogReturn logs the book toward the member's challenge for the year it was
+// returned in, if they have one, and reports whether it did
+func (r *Repository) LogReturn(ctx context.Context, memberID string, bookID int, returnedAt time.Time) (bool, error) {
+	query := fmt.Sprintf(`
+		INSERT INTO %s (challenge_id, book_id, completed_on, source)
+		SELECT id, $2, $3, '%s' FROM %s WHERE member_id = $1 AND year = $4
+		ON CONFLICT (challenge_id, book_id) DO NOTHING
+	`, utils.ChallengeBooksTable, SourceReturn, utils.ReadingChallengesTable)
+	res, err := r.db.ExecContext(ctx, query, memberID, bookID, returnedAt, returnedAt.Year())
+	if err != nil {
+		log.Printf("Failed to log the return of book id=%d by member id=%s: %v", bookID, memberID, err)
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+func (r *Repository) RemoveBook(ctx context.Context, challengeID, bookID int) error {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE challenge_id = $1 AND book_id = $2`, utils.ChallengeBooksTable)
+	res, err := r.db.ExecContext(ctx, query, challengeID, bookID)
+	if err != nil {
+		log.Printf("Failed to remove book id=%d from challenge id=%d: %v", bookID, challengeID, err)
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrBookNotLogged
+	}
+	return nil
+}
+
+// Leaderboard returns the members with the most books logged toward their
+// challenge for the year, leaving out those who opted out. Ties share a
+// rank.
+func (r *Repository) Leaderboard(ctx context.Context, year, limit int) ([]Standing, error) {
+	query := fmt.Sprintf(`
+		SELECT rank() OVER (ORDER BY t.completed DESC), t.name, t.completed, t.goal
+		FROM (
+			SELECT m.name, c.goal, c.id,
+				(SELECT count(*) FROM %[2]s b WHERE b.challenge_id = c.id) AS completed
+			FROM %[1]s c JOIN %[3]s m ON m.id = c.member_id
+			WHERE c.year = $1 AND NOT c.hide_from_leaderboard
+		) t
+		WHERE t.completed > 0
+		ORDER BY t.completed DESC, t.id
+		LIMIT $2
+	`, utils.ReadingChallengesTable, utils.ChallengeBooksTable, utils.MembersTable)
+	rows, err := r.db.QueryContext(ctx, query, year, limit)
+	if err != nil {
+		log.Printf("Failed to get the %d leaderboard: %v", year, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []Standing{}
+	for rows.Next() {
+		var s Standing
+		if err := rows.Scan(&s.Rank, &s.Name, &s.Completed, &s.Goal); err != nil {
+			return nil, err
+		}
+		list = append(list, s)
+	}
+	return list, rows.Err()
+}
@@ -0,0 +1,198 @@
+// Package challenges runs yearly reading challenges: members set a goal of
+// books for a year, the books they return are logged toward it, and they
+// can log other books they read themselves. Progress earns badges, and a
+// leaderboard ranks the members who haven't opted out of it.
+package challenges
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ErrInvalid is wrapped by errors about the content of a request
+var ErrInvalid = errors.New("invalid reading challenge request")
+
+const (
+	// firstYear is the earliest year a challenge can be set for
+	firstYear = 2000
+	maxGoal   = 1000
+	// defaultLeaderboard and maxLeaderboard size the leaderboard
+	defaultLeaderboard = 10
+	maxLeaderboard     = 100
+)
+
+// fixed returns a fixed number of books whatever the goal
+func fixed(n int) func(int) int {
+	return func(int) int { return n }
+}
+
+// milestones are the badges a challenge can earn, by how many books logged
+// toward a goal earn them
+var milestones = []struct {
+	code  string
+	name  string
+	books func(goal int) int
+}{
+	{"first_book", "First book", fixed(1)},
+	{"five_books", "Five books", fixed(5)},
+	{"ten_books", "Ten books", fixed(10)},
+	{"twenty_five_books", "Twenty-five books", fixed(25)},
+	{"fifty_books", "Fifty books", fixed(50)},
+	{"hundred_books", "A hundred books", fixed(100)},
+	{"halfway", "Halfway there", func(goal int) int { return (goal + 1) / 2 }},
+	{"goal_reached", "Goal reached", func(goal int) int { return goal }},
+}
+
+type Service struct {
+	repo *Repository
+}
+
+func NewService(repo *Repository) *Service {
+	return &Service{repo: repo}
+}
+
+// SetGoal validates req and sets the member's goal for the year, creating
+// the challenge if needed. It reports whether the challenge was created.
+func (s *Service) SetGoal(ctx context.Context, memberID string, year int, req GoalRequest) (*Progress, bool, error) {
+	if err := checkYear(year); err != nil {
+		return nil, false, err
+	}
+	if req.Goal < 1 || req.Goal > maxGoal {
+		return nil, false, fmt.Errorf("%w: goal must be between 1 and %d books", ErrInvalid, maxGoal)
+	}
+	c, created, err := s.repo.SetGoal(ctx, memberID, year, req)
+	if err != nil {
+		return nil, false, err
+	}
+	p, err := s.progress(ctx, c)
+	return p, created, err
+}
+
+// Get returns the member's progress toward their goal for the year
+func (s *Service) Get(ctx context.Context, memberID string, year int) (*Progress, error) {
+	c, err := s.repo.Get(ctx, memberID, year)
+	if err != nil {
+		return nil, err
+	}
+	return s.progress(ctx, c)
+}
+
+// List returns the member's challenges, most recent year first
+func (s *Service) List(ctx context.Context, memberID string) ([]Challenge, error) {
+	return s.repo.List(ctx, memberID)
+}
+
+// Delete removes the member's challenge for the year and its books
+func (s *Service) Delete(ctx context.Context, memberID string, year int) error {
+	return s.repo.Delete(ctx, memberID, year)
+}
+
+// LogBook logs a book the member finished toward their challenge for the
+// year it was finished in
+func (s *Service) LogBook(ctx context.Context, memberID string, year int, req LogRequest) (*Progress, error) {
+	c, err := s.repo.Get(ctx, memberID, year)
+	if err != nil {
+		return nil, err
+	}
+	if req.BookID <= 0 {
+		return nil, fmt.Errorf("%w: book_id is required", ErrInvalid)
+	}
+	completed := time.Now()
+	if v := strings.TrimSpace(req.CompletedOn); v != "" {
+		if completed, err = time.Parse(time.DateOnly, v); err != nil {
+			return nil, fmt.Errorf("%w: completed_on must be a date, YYYY-MM-DD", ErrInvalid)
+		}
+	}
+	if completed.Year() != year || completed.After(time.Now()) {
+		return nil, fmt.Errorf("%w: completed_on must be in %d and not in the future", ErrInvalid, year)
+	}
+	if err := s.repo.LogBook(ctx, c.ID, req.BookID, completed, SourceManual); err != nil {
+		return nil, err
+	}
+	return s.Get(ctx, memberID, year)
+}
+
+// RemoveBook removes a book from the member's challenge for the year
+func (s *Service) RemoveBook(ctx context.Context, memberID string, year, bookID int) error {
+	c, err := s.repo.Get(ctx, memberID, year)
+	if err != nil {
+		return err
+	}
+	return s.repo.RemoveBook(ctx, c.ID, bookID)
+}
+
+// LogReturn logs a book the member returned toward their challenge for the
+// year, if they have one
+func (s *Service) LogReturn(ctx context.Context, memberID string, bookID int, returnedAt time.Time) error {
+	_, err := s.repo.LogReturn(ctx, memberID, bookID, returnedAt)
+	return err
+}
+
+// Leaderboard ranks the members with a challenge for the year by the books
+// logged toward it. Members who opted out are left out, and the others are
+// named by first name and last initial only.
+func (s *Service) Leaderboard(ctx context.Context, year, limit int) (*Leaderboard, error) {
+	if err := checkYear(year); err != nil {
+		return nil, err
+	}
+	if limit == 0 {
+		limit = defaultLeaderboard
+	}
+	if limit < 1 || limit > maxLeaderboard {
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalid, maxLeaderboard)
+	}
+	list, err := s.repo.Leaderboard(ctx, year, limit)
+	if err != nil {
+		return nil, err
+	}
+	for i := range list {
+		st := &list[i]
+		st.Name = shortName(st.Name)
+		st.Percent = percent(st.Completed, st.Goal)
+	}
+	return &Leaderboard{Year: year, Standings: list}, nil
+}
+
+func (s *Service) progress(ctx context.Context, c *Challenge) (*Progress, error) {
+	books, err := s.repo.ListBooks(ctx, c.ID)
+	if err != nil {
+		return nil, err
+	}
+	p := &Progress{Challenge: *c, Percent: percent(len(books), c.Goal), Remaining: max(c.Goal-len(books), 0),
+		Books: books, Badges: []Badge{}}
+	for _, m := range milestones {
+		if n := m.books(c.Goal); n <= len(books) {
+			p.Badges = append(p.Badges, Badge{Code: m.code, Name: m.name, EarnedOn: books[n-1].CompletedOn})
+		}
+	}
+	return p, nil
+}
+
+func checkYear(year int) error {
+	if year < firstYear || year > time.Now().Year()+1 {
+		return fmt.Errorf("%w: year must be between %d and next year", ErrInvalid, firstYear)
+	}
+	return nil
+}
+
+// percent is how much of goal completed reaches, capped at 100
+func percent(completed, goal int) int {
+	if goal <= 0 {
+		return 0
+	}
+	return min(completed*100/goal, 100)
+}
+
+// shortName turns "Ada King Lovelace" into "Ada L."
+func shortName(name string) string {
+	parts := strings.Fields(name)
+	if len(parts) < 2 {
+		return name
+	}
+	last, _ := utf8.DecodeRuneInString(parts[len(parts)-1])
+	return parts[0] + " " + string(last) + "."
+}
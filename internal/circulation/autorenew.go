@@ -0,0 +1,52 @@
+package circulation
+
+import (
+	"context"
+	"errors"
+	"public_library/internal/eventbus"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// AutoRenew renews the loans of members who opted in to automatic renewal
+// that fall due within auto_renew_days_before days, and returns how many
+// it renewed. Loans of books other members are waiting for, with no
+// renewals left under the policy in force now or of suspended or expired
+// members are left to fall due. Each renewed loan is published for the
+// member to be told its new due date.
+func (s *Service) AutoRenew(ctx context.Context) (int, error) {
+	ids, err := s.repo.AutoRenewable(ctx, time.Now().AddDate(0, 0, s.cfg.AutoRenewDaysBefore))
+	if err != nil {
+		return 0, err
+	}
+	renewed := 0
+	for _, id := range ids {
+		l, err := s.Renew(ctx, id)
+		switch {
+		case errors.Is(err, ErrOnHold), errors.Is(err, ErrRenewalLimit), errors.Is(err, ErrReturned),
+			errors.Is(err, ErrSuspended), errors.Is(err, ErrExpired):
+			continue
+		case err != nil:
+			return renewed, err
+		}
+		s.bus.Publish(ctx, eventbus.LoanAutoRenewed, l.ID, l)
+		renewed++
+	}
+	return renewed, nil
+}
+
+// StartAutoRenewJob renews loans at start and every auto_renew_interval
+// until ctx is cancelled. A non-positive interval disables it.
+func (s *Service) StartAutoRenewJob(ctx context.Context, logger *zap.Logger) {
+	runEvery(ctx, s.cfg.AutoRenewInterval, func() {
+		renewed, err := s.AutoRenew(ctx)
+		if err != nil {
+			logger.Error("Automatic loan renewal failed", zap.Error(err))
+			return
+		}
+		if renewed > 0 {
+			logger.Info("Loans renewed automatically", zap.Int("count", renewed))
+		}
+	})
+}
@@ -0,0 +1,46 @@
+package circulation
+
+import (
+	"context"
+	"fmt"
+	"public_library/internal/book"
+)
+
+// maxAvailabilityBooks caps the books of one availability check
+const maxAvailabilityBooks = 200
+
+// Availability returns how many copies of each book are on the shelf, in
+// one query for the whole list, so a reading list or wishlist view doesn't
+// check its books one by one. Repeated IDs are answered once; books that
+// don't exist or are outside the caller's audience limit are listed in
+// NotFound.
+func (s *Service) Availability(ctx context.Context, req AvailabilityRequest) (*AvailabilityReport, error) {
+	if len(req.BookIDs) == 0 || len(req.BookIDs) > maxAvailabilityBooks {
+		return nil, fmt.Errorf("%w: between 1 and %d book_ids are required", ErrInvalid, maxAvailabilityBooks)
+	}
+	var ids []int
+	seen := make(map[int]bool, len(req.BookIDs))
+	for _, id := range req.BookIDs {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	list, err := s.repo.Availability(ctx, ids, book.AllowedAudiences(ctx))
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int]Availability, len(list))
+	for _, a := range list {
+		byID[a.BookID] = a
+	}
+	out := &AvailabilityReport{Books: make([]Availability, 0, len(list)), NotFound: []int{}}
+	for _, id := range ids {
+		if a, ok := byID[id]; ok {
+			out.Books = append(out.Books, a)
+		} else {
+			out.NotFound = append(out.NotFound, id)
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,142 @@
+package circulation
+
+import (
+	"context"
+	"fmt"
+	"public_library/internal/acquisition"
+	"public_library/internal/eventbus"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ClaimReturned records a member's claim to have returned a loan the
+// library still has out. Its fine stops at what returning the copy now
+// would cost and isn't counted against the member while the claim is open,
+// and the copy's branch is asked for a shelf check.
+func (s *Service) ClaimReturned(ctx context.Context, id int) (*Loan, error) {
+	l, err := s.repo.GetLoan(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case l.ReturnedAt != nil:
+		return nil, ErrReturned
+	case l.ClaimedReturnedAt != nil:
+		return nil, ErrClaimed
+	}
+	m, err := s.repo.GetMember(ctx, l.MemberID)
+	if err != nil {
+		return nil, err
+	}
+	c, err := s.repo.GetCopy(ctx, l.AcquisitionID)
+	if err != nil {
+		return nil, err
+	}
+	fine, currency, err := s.fine(ctx, m.PatronGroup, c, l.DueAt, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.ClaimReturned(ctx, id, fine, currency)
+}
+
+// ResolveClaim settles a claim that a loan was returned. Found returns the
+// loan as of the claim, with the fine it had then, and puts the copy back
+// into circulation at its branch. Lost withdraws the copy and charges the
+// member for it on top of the fine.
+func (s *Service) ResolveClaim(ctx context.Context, id int, req ResolveClaimRequest) (*Loan, error) {
+	charge := strings.TrimSpace(req.Charge)
+	switch req.Outcome {
+	case ClaimFound:
+		if charge != "" {
+			return nil, fmt.Errorf("%w: charge only applies to lost copies", ErrInvalid)
+		}
+	case ClaimLost:
+		if charge != "" && !acquisition.ValidPrice(charge) {
+			return nil, fmt.Errorf("%w: charge must be a non-negative amount with at most four decimals", ErrInvalid)
+		}
+	default:
+		return nil, fmt.Errorf("%w: outcome must be %s or %s", ErrInvalid, ClaimFound, ClaimLost)
+	}
+	l, err := s.repo.GetLoan(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case l.ReturnedAt != nil:
+		return nil, ErrReturned
+	case l.ClaimedReturnedAt == nil:
+		return nil, ErrNotClaimed
+	}
+	if req.Outcome == ClaimFound {
+		return s.resolveFound(ctx, l)
+	}
+	if charge == "" {
+		cost, currency, err := s.repo.CopyCost(ctx, l.AcquisitionID)
+		if err != nil {
+			return nil, err
+		}
+		if currency != s.cfg.Currency {
+			return nil, fmt.Errorf("%w: the copy was paid for in %s; give the charge in %s", ErrInvalid, currency, s.cfg.Currency)
+		}
+		charge = cost
+	}
+	return s.repo.ResolveLost(ctx, id, charge, s.cfg.Currency)
+}
+
+// resolveFound returns a loan claimed returned as of the claim and routes
+// its copy as if checked in at its branch, where it was found
+func (s *Service) resolveFound(ctx context.Context, l *Loan) (*Loan, error) {
+	returned, err := s.returnLoan(ctx, l, *l.ClaimedReturnedAt)
+	if err != nil {
+		return nil, err
+	}
+	c, err := s.repo.GetCopy(ctx, l.AcquisitionID)
+	if err != nil {
+		return nil, err
+	}
+	rt, err := s.repo.Route(ctx, c.ID, c.Branch, false)
+	if err != nil {
+		return nil, err
+	}
+	if rt.Shelved {
+		if h, err := s.repo.GetHold(ctx, *rt.HoldID); err == nil {
+			s.bus.Publish(ctx, eventbus.HoldReady, h.ID, *h)
+		}
+	}
+	return returned, nil
+}
+
+// ShelfChecks returns the shelf checks the branch has still to do, in call
+// number order, for the morning's search of the shelves
+func (s *Service) ShelfChecks(ctx context.Context, branch string) ([]ShelfCheck, error) {
+	branch = strings.TrimSpace(branch)
+	if branch == "" || utf8.RuneCountInString(branch) > maxTextLength {
+		return nil, fmt.Errorf("%w: branch is required and must be at most %d characters", ErrInvalid, maxTextLength)
+	}
+	return s.repo.ListShelfChecks(ctx, branch)
+}
+
+// RecordShelfCheck records what a shelf check found. A copy found resolves
+// the claim on its loan as found, unless it is already resolved; one not
+// found leaves the claim for staff to resolve.
+func (s *Service) RecordShelfCheck(ctx context.Context, id int, res ShelfCheckResult) (*ShelfCheck, error) {
+	note := strings.TrimSpace(res.Note)
+	if utf8.RuneCountInString(note) > maxTextLength {
+		return nil, fmt.Errorf("%w: note must be at most %d characters", ErrInvalid, maxTextLength)
+	}
+	sc, err := s.repo.RecordShelfCheck(ctx, id, res.Found, note)
+	if err != nil || !res.Found {
+		return sc, err
+	}
+	l, err := s.repo.GetLoan(ctx, sc.LoanID)
+	if err != nil {
+		return nil, err
+	}
+	if l.ReturnedAt == nil && l.ClaimedReturnedAt != nil {
+		if _, err := s.resolveFound(ctx, l); err != nil {
+			return nil, err
+		}
+	}
+	return sc, nil
+}
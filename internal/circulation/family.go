@@ -0,0 +1,155 @@
+package circulation
+
+import (
+	"context"
+	"fmt"
+	"public_library/internal/config"
+	"strings"
+)
+
+// Link links a child member to the guardian with the permissions of req.
+// Only members of the child group can be linked, to guardians outside the
+// child and guest groups.
+func (s *Service) Link(ctx context.Context, guardianID string, req LinkRequest) (*Link, error) {
+	childID := strings.TrimSpace(req.ChildID)
+	if childID == "" {
+		return nil, fmt.Errorf("%w: child_id is required", ErrInvalid)
+	}
+	g, err := s.repo.GetMember(ctx, guardianID)
+	if err != nil {
+		return nil, err
+	}
+	if g.ID == childID {
+		return nil, fmt.Errorf("%w: a member can't be their own guardian", ErrInvalid)
+	}
+	if g.PatronGroup == config.GroupChild || g.PatronGroup == config.GroupGuest {
+		return nil, fmt.Errorf("%w: guardians can't be in the %s or %s patron group", ErrInvalid, config.GroupChild, config.GroupGuest)
+	}
+	c, err := s.repo.GetMember(ctx, childID)
+	if err != nil {
+		return nil, err
+	}
+	if c.PatronGroup != config.GroupChild {
+		return nil, fmt.Errorf("%w: only members of the %s patron group can be linked to a guardian", ErrInvalid, config.GroupChild)
+	}
+	if err := req.LinkPermissions.validate(); err != nil {
+		return nil, err
+	}
+	k := &Link{GuardianID: g.ID, ChildID: c.ID, LinkPermissions: req.LinkPermissions}
+	if err := s.repo.CreateLink(ctx, k); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// ListLinks returns the member's family links, those to their children
+// first, then those to their guardians
+func (s *Service) ListLinks(ctx context.Context, memberID string) ([]Link, error) {
+	if _, err := s.repo.GetMember(ctx, memberID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListLinks(ctx, memberID)
+}
+
+// UpdateLink replaces what the link lets the guardian do
+func (s *Service) UpdateLink(ctx context.Context, guardianID, childID string, p LinkPermissions) (*Link, error) {
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+	return s.repo.UpdateLink(ctx, guardianID, childID, p)
+}
+
+// Unlink removes the link of the child to the guardian
+func (s *Service) Unlink(ctx context.Context, guardianID, childID string) error {
+	return s.repo.DeleteLink(ctx, guardianID, childID)
+}
+
+// FamilyLoans returns the child's loans to the guardian, like ListLoans.
+// Returned loans are left out unless the link shows the child's history.
+func (s *Service) FamilyLoans(ctx context.Context, guardianID, childID, status string) ([]Loan, error) {
+	k, err := s.repo.GetLink(ctx, guardianID, childID)
+	if err != nil {
+		return nil, err
+	}
+	if !k.ViewLoans {
+		return nil, ErrNotPermitted
+	}
+	if !k.ViewHistory {
+		if status == LoansReturned {
+			return nil, ErrNotPermitted
+		}
+		status = LoansOut
+	}
+	return s.ListLoans(ctx, childID, status)
+}
+
+// FamilyHolds returns the child's holds to the guardian
+func (s *Service) FamilyHolds(ctx context.Context, guardianID, childID string) ([]Hold, error) {
+	k, err := s.repo.GetLink(ctx, guardianID, childID)
+	if err != nil {
+		return nil, err
+	}
+	if !k.ViewHolds {
+		return nil, ErrNotPermitted
+	}
+	return s.repo.ListHolds(ctx, childID)
+}
+
+// FamilyPlaceHold places a hold for the child on the guardian's behalf,
+// like PlaceHold
+func (s *Service) FamilyPlaceHold(ctx context.Context, guardianID, childID string, bookID int, pickupBranch string) (*Hold, error) {
+	if err := s.canManage(ctx, guardianID, childID); err != nil {
+		return nil, err
+	}
+	return s.PlaceHold(ctx, childID, bookID, pickupBranch)
+}
+
+// FamilyCancelHold cancels one of the child's holds on the guardian's
+// behalf
+func (s *Service) FamilyCancelHold(ctx context.Context, guardianID, childID string, holdID int) (*Hold, error) {
+	if err := s.canManage(ctx, guardianID, childID); err != nil {
+		return nil, err
+	}
+	h, err := s.repo.GetHold(ctx, holdID)
+	if err != nil {
+		return nil, err
+	}
+	if h.MemberID != childID {
+		return nil, ErrHoldNotFound
+	}
+	return s.repo.CancelHold(ctx, holdID)
+}
+
+// FamilyRenew renews one of the child's loans on the guardian's behalf,
+// like Renew
+func (s *Service) FamilyRenew(ctx context.Context, guardianID, childID string, loanID int) (*Loan, error) {
+	if err := s.canManage(ctx, guardianID, childID); err != nil {
+		return nil, err
+	}
+	l, err := s.repo.GetLoan(ctx, loanID)
+	if err != nil {
+		return nil, err
+	}
+	if l.MemberID != childID {
+		return nil, ErrLoanNotFound
+	}
+	return s.Renew(ctx, loanID)
+}
+
+func (s *Service) canManage(ctx context.Context, guardianID, childID string) error {
+	k, err := s.repo.GetLink(ctx, guardianID, childID)
+	if err != nil {
+		return err
+	}
+	if !k.Manage {
+		return ErrNotPermitted
+	}
+	return nil
+}
+
+func (p LinkPermissions) validate() error {
+	if p.ViewHistory && !p.ViewLoans {
+		return fmt.Errorf("%w: view_history needs view_loans", ErrInvalid)
+	}
+	return nil
+}
@@ -0,0 +1,1042 @@
+package circulation
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"public_library/internal/httperr"
+	"public_library/internal/policy"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	svc    *Service
+	logger *zap.Logger
+}
+
+func NewHandler(svc *Service, l *zap.Logger) *Handler {
+	return &Handler{svc: svc, logger: l}
+}
+
+// GET /patron-groups
+
+// ListPatronGroups godoc
+// @Summary List the patron groups and their lending policies
+// @Tags circulation
+// @Produce json
+// @Success 200 {array} PatronGroup
+// @Router /patron-groups [get]
+func (h *Handler) ListPatronGroups(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.svc.Groups())
+}
+
+// POST /members
+
+// CreateMember godoc
+// @Summary Register a member
+// @Description id is the member's ID in the identity system; patron_group defaults to adult.
+// @Tags circulation
+// @Accept json
+// @Produce json
+// @Param member body Member true "Member to register"
+// @Success 201 {object} Member
+// @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /members [post]
+func (h *Handler) CreateMember(w http.ResponseWriter, r *http.Request) {
+	var m Member
+	if err := json.NewDecoder(r.Body).Decode(&m); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if err := h.svc.CreateMember(r.Context(), &m); err != nil {
+		h.writeError(w, "failed to create member", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(m)
+}
+
+// POST /members/guests
+
+// CreateGuest godoc
+// @Summary Register a guest account
+// @Description Registers a visitor without a full registration in the guest patron group, under a generated id. The account expires after days, circulation.guest_days by default, and an expired guest can't check out, renew or place holds. The guest group's limits apply, by default two copies for use in the library only.
+// @Tags circulation
+// @Accept json
+// @Produce json
+// @Param guest body GuestRequest true "Guest to register"
+// @Success 201 {object} Member
+// @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /members/guests [post]
+func (h *Handler) CreateGuest(w http.ResponseWriter, r *http.Request) {
+	var req GuestRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	m, err := h.svc.CreateGuest(r.Context(), req)
+	if err != nil {
+		h.writeError(w, "failed to create guest", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(m)
+}
+
+// GET /members/{id}
+
+// GetMember godoc
+// @Summary Get a member
+// @Tags circulation
+// @Produce json
+// @Param id path string true "Member ID"
+// @Success 200 {object} Member
+// @Failure 404 {object} map[string]string
+// @Router /members/{id} [get]
+func (h *Handler) GetMember(w http.ResponseWriter, r *http.Request) {
+	m, err := h.svc.GetMember(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		h.writeError(w, "failed to get member", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(m)
+}
+
+// PUT /members/{id}
+
+// UpdateMember godoc
+// @Summary Update a member
+// @Description Moving a member to another patron group applies its limits to new checkouts and holds.
+// @Tags circulation
+// @Accept json
+// @Produce json
+// @Param id path string true "Member ID"
+// @Param member body Member true "Updated member"
+// @Success 200 {object} Member
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /members/{id} [put]
+func (h *Handler) UpdateMember(w http.ResponseWriter, r *http.Request) {
+	var m Member
+	if err := json.NewDecoder(r.Body).Decode(&m); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	m.ID = mux.Vars(r)["id"]
+	if err := h.svc.UpdateMember(r.Context(), &m); err != nil {
+		h.writeError(w, "failed to update member", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(m)
+}
+
+// PUT /members/{id}/suspension
+
+// SuspendMember godoc
+// @Summary Suspend a member
+// @Description Blocks the member's checkouts, renewals and holds until the suspension expires or is lifted; returns are still accepted. Replaces any suspension in force. suspended_at is set by the server.
+// @Tags circulation
+// @Accept json
+// @Produce json
+// @Param id path string true "Member ID"
+// @Param suspension body Suspension true "Reason and optional expiry"
+// @Success 200 {object} Member
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /members/{id}/suspension [put]
+func (h *Handler) SuspendMember(w http.ResponseWriter, r *http.Request) {
+	var sp Suspension
+	if err := json.NewDecoder(r.Body).Decode(&sp); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	m, err := h.svc.Suspend(r.Context(), mux.Vars(r)["id"], sp)
+	if err != nil {
+		h.writeError(w, "failed to suspend member", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(m)
+}
+
+// DELETE /members/{id}/suspension
+
+// UnsuspendMember godoc
+// @Summary Lift a member's suspension
+// @Tags circulation
+// @Produce json
+// @Param id path string true "Member ID"
+// @Success 200 {object} Member
+// @Failure 404 {object} map[string]string
+// @Router /members/{id}/suspension [delete]
+func (h *Handler) UnsuspendMember(w http.ResponseWriter, r *http.Request) {
+	m, err := h.svc.Unsuspend(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		h.writeError(w, "failed to lift the member's suspension", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(m)
+}
+
+// GET /members/{id}/status
+
+// GetMemberStatus godoc
+// @Summary Get a member's circulation status
+// @Description Counts the member's loans out, overdue loans and waiting holds against the limits of their patron group, totals their fines and shows any suspension in force, so self-service kiosks and SIP2 gateways can tell whether the member may check out or place holds.
+// @Tags circulation
+// @Produce json
+// @Param id path string true "Member ID"
+// @Success 200 {object} Status
+// @Failure 404 {object} map[string]string
+// @Router /members/{id}/status [get]
+func (h *Handler) GetMemberStatus(w http.ResponseWriter, r *http.Request) {
+	st, err := h.svc.Status(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		h.writeError(w, "failed to get member status", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(st)
+}
+
+// GET /members/{id}/loans?status=out
+
+// ListMemberLoans godoc
+// @Summary List a member's loans
+// @Description Loans still out come first, soonest due first, then returned loans, most recent first.
+// @Tags circulation
+// @Produce json
+// @Param id path string true "Member ID"
+// @Param status query string false "Only loans out or returned" Enums(out, returned)
+// @Success 200 {array} Loan
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /members/{id}/loans [get]
+func (h *Handler) ListMemberLoans(w http.ResponseWriter, r *http.Request) {
+	list, err := h.svc.ListLoans(r.Context(), mux.Vars(r)["id"], r.URL.Query().Get("status"))
+	if err != nil {
+		h.writeError(w, "failed to list loans", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// HoldRequest is the body of PlaceHold
+type HoldRequest struct {
+	BookID int `json:"book_id" example:"1"`
+	// PickupBranch defaults to the branch the copy is checked in at
+	PickupBranch string `json:"pickup_branch" example:"Central"`
+}
+
+// POST /members/{id}/holds
+
+// PlaceHold godoc
+// @Summary Place a hold on a book
+// @Description Queues the member for the next copy of the book, within the hold limit of their patron group. The first copy of the book checked in is set aside for the oldest hold and sent to its pickup_branch. Checking a copy of the book out to the member fulfills the hold. Suspended members can't place holds.
+// @Tags circulation
+// @Accept json
+// @Produce json
+// @Param id path string true "Member ID"
+// @Param hold body HoldRequest true "Book to hold"
+// @Success 201 {object} Hold
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /members/{id}/holds [post]
+func (h *Handler) PlaceHold(w http.ResponseWriter, r *http.Request) {
+	var req HoldRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	hold, err := h.svc.PlaceHold(r.Context(), mux.Vars(r)["id"], req.BookID, req.PickupBranch)
+	if err != nil {
+		h.writeError(w, "failed to place hold", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(hold)
+}
+
+// POST /members/{id}/loans/anonymize
+
+// AnonymizeMemberLoans godoc
+// @Summary Anonymize a member's loan history
+// @Description Unlinks the member's returned loans from them, keeping the loans for circulation counts. Loans with a fine or a replacement charge stay linked. Members with anonymize_loans set, or every member when the library's policy is to anonymize, have loans unlinked as they are returned.
+// @Tags circulation
+// @Produce json
+// @Param id path string true "Member ID"
+// @Success 200 {object} Anonymized
+// @Failure 404 {object} map[string]string
+// @Router /members/{id}/loans/anonymize [post]
+func (h *Handler) AnonymizeMemberLoans(w http.ResponseWriter, r *http.Request) {
+	result, err := h.svc.AnonymizeLoans(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		h.writeError(w, "failed to anonymize loans", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// GET /members/{id}/holds
+
+// ListMemberHolds godoc
+// @Summary List a member's holds
+// @Tags circulation
+// @Produce json
+// @Param id path string true "Member ID"
+// @Success 200 {array} Hold
+// @Failure 404 {object} map[string]string
+// @Router /members/{id}/holds [get]
+func (h *Handler) ListMemberHolds(w http.ResponseWriter, r *http.Request) {
+	list, err := h.svc.ListHolds(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		h.writeError(w, "failed to list holds", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// DELETE /holds/{id}
+
+// CancelHold godoc
+// @Summary Cancel a waiting hold
+// @Tags circulation
+// @Param id path int true "Hold ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /holds/{id} [delete]
+func (h *Handler) CancelHold(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "invalid hold ID")
+	if !ok {
+		return
+	}
+	if _, err := h.svc.CancelHold(r.Context(), id); err != nil {
+		h.writeError(w, "failed to cancel hold", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// POST /loans
+
+// Checkout godoc
+// @Summary Check a copy out to a member
+// @Description Lends the copy on the terms the loan policy sets for the member's patron group and the copy's format: loan period, renewals and loan limits. Copies on course reserve lend on the reserve's terms. Loans don't fall due on days the copy's branch is closed. Withdrawn copies, copies out for repair and copies already out can't be checked out, and suspended members can't check out.
+// @Tags circulation
+// @Accept json
+// @Produce json
+// @Param checkout body CheckoutRequest true "Member and copy"
+// @Success 201 {object} Loan
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /loans [post]
+func (h *Handler) Checkout(w http.ResponseWriter, r *http.Request) {
+	var req CheckoutRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	l, err := h.svc.Checkout(r.Context(), req)
+	if err != nil {
+		h.writeError(w, "failed to check out", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(l)
+}
+
+// POST /desk/checkouts
+
+// DeskCheckout godoc
+// @Summary Check out a scanned copy to a scanned card
+// @Description Checks out the copy with the item barcode to the member with the library card, on the same terms and checks as POST /loans, in one call. The card and the member's standing and loan limits are checked together with the copy's availability as part of the checkout. A denied checkout returns 409 with every reason it was denied for, so the desk can resolve them at once.
+// @Tags circulation
+// @Accept json
+// @Produce json
+// @Param checkout body DeskCheckoutRequest true "Scanned card number and item barcode"
+// @Success 201 {object} DeskCheckout "Checked out"
+// @Failure 400 {object} map[string]string
+// @Failure 409 {object} DeskCheckout "Denied"
+// @Router /desk/checkouts [post]
+func (h *Handler) DeskCheckout(w http.ResponseWriter, r *http.Request) {
+	var req DeskCheckoutRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	out, err := h.svc.DeskCheckout(r.Context(), req)
+	if err != nil {
+		h.writeError(w, "failed to check out", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if len(out.Denials) > 0 {
+		w.WriteHeader(http.StatusConflict)
+	} else {
+		w.WriteHeader(http.StatusCreated)
+	}
+	json.NewEncoder(w).Encode(out)
+}
+
+// POST /circulation/checkin/batch
+
+// BatchCheckin godoc
+// @Summary Check in the copies a returns sorter read
+// @Description Checks in each barcode at the branch, in order: returns the copy's loan if it is out, fining it as POST /loans/{id}/return does, then routes the copy. A copy of a book with waiting holds is set aside for the oldest hold and goes on the hold shelf if the hold is picked up at the branch, or in transit to its pickup branch; a copy trapped before keeps its hold. Other copies are reshelved, or in transit to their home branch unless their collection floats under circulation.floating: then they are reshelved and the branch becomes their home branch. Holds whose copy reaches the hold shelf are published on the event bus as hold.ready. Barcodes that can't be checked in carry an error without failing the batch.
+// @Tags circulation
+// @Accept json
+// @Produce json
+// @Param checkin body BatchCheckinRequest true "Branch and scanned barcodes, at most 500"
+// @Success 200 {object} BatchCheckin
+// @Failure 400 {object} map[string]string
+// @Router /circulation/checkin/batch [post]
+func (h *Handler) BatchCheckin(w http.ResponseWriter, r *http.Request) {
+	var req BatchCheckinRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	out, err := h.svc.BatchCheckin(r.Context(), req)
+	if err != nil {
+		h.writeError(w, "failed to check in", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(out)
+}
+
+// POST /books/availability
+
+// CheckAvailability godoc
+// @Summary Check the availability of several books at once
+// @Description Returns, for each book asked for, how many copies are on the shelf, on loan and waited for by holds, when the first copy on loan is due back and the branches with a copy available, in one call for a whole reading list or wishlist. Books are answered in the order asked, once each; IDs of books that don't exist or are outside the caller's audience limit are listed in not_found. At most 200 books per call.
+// @Tags circulation
+// @Accept json
+// @Produce json
+// @Param request body AvailabilityRequest true "Books to check"
+// @Success 200 {object} AvailabilityReport
+// @Failure 400 {object} map[string]string
+// @Router /books/availability [post]
+func (h *Handler) CheckAvailability(w http.ResponseWriter, r *http.Request) {
+	var req AvailabilityRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	report, err := h.svc.Availability(r.Context(), req)
+	if err != nil {
+		h.writeError(w, "failed to check availability", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// GET /acquisitions/{id}/history
+
+// GetCopyHistory godoc
+// @Summary Get the history of a copy
+// @Description Returns everything recorded about a physical copy, oldest first: its acquisition, checkouts and returns, the holds it was trapped and shelved for, repairs, moves to another home branch, transits after check-in and its withdrawal. Useful for investigating damage disputes. Dates without a time of day, such as repair dates, are given as the start of the day.
+// @Tags circulation
+// @Produce json
+// @Param id path int true "Copy (acquisition) ID"
+// @Success 200 {object} CopyHistory
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /copies/{id}/history [get]
+// @Router /acquisitions/{id}/history [get]
+func (h *Handler) GetCopyHistory(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "invalid copy ID")
+	if !ok {
+		return
+	}
+	history, err := h.svc.CopyHistory(r.Context(), id)
+	if err != nil {
+		h.writeError(w, "failed to get copy history", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(history)
+}
+
+// GET /hold-shelf?branch=Central&since=2026-10-14T08:00:00Z
+
+// ListHoldShelf godoc
+// @Summary List the copies awaiting pickup at a branch
+// @Description Lists the copies on the branch's hold shelf by member name, with the date each hold must be picked up by. Holds past it are marked expired until POST /hold-shelf/clear-expired clears them.
+// @Tags circulation
+// @Produce json
+// @Param branch query string true "Pickup branch"
+// @Param since query string false "Only copies shelved since (RFC 3339)"
+// @Success 200 {array} ShelfItem
+// @Failure 400 {object} map[string]string
+// @Router /hold-shelf [get]
+func (h *Handler) ListHoldShelf(w http.ResponseWriter, r *http.Request) {
+	since, ok := sinceParam(w, r)
+	if !ok {
+		return
+	}
+	list, err := h.svc.HoldShelf(r.Context(), r.URL.Query().Get("branch"), since)
+	if err != nil {
+		h.writeError(w, "failed to list hold shelf", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// GET /hold-shelf/slips?branch=Central&since=2026-10-14T08:00:00Z
+
+// PrintHoldSlips godoc
+// @Summary Print the hold-shelf slips of a branch
+// @Description Returns a printable slip for each copy on the branch's hold shelf, by member name, separated by form feeds. Slips show the member's name, the last four digits of their card, the copy and the date to pick it up by. since limits them to the copies shelved since the last print run.
+// @Tags circulation
+// @Produce plain
+// @Param branch query string true "Pickup branch"
+// @Param since query string false "Only copies shelved since (RFC 3339)"
+// @Success 200 {string} string
+// @Failure 400 {object} map[string]string
+// @Router /hold-shelf/slips [get]
+func (h *Handler) PrintHoldSlips(w http.ResponseWriter, r *http.Request) {
+	since, ok := sinceParam(w, r)
+	if !ok {
+		return
+	}
+	list, err := h.svc.HoldShelf(r.Context(), r.URL.Query().Get("branch"), since)
+	if err != nil {
+		h.writeError(w, "failed to print hold slips", err)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	if err := WriteSlips(w, list); err != nil {
+		h.logger.Warn("hold slips interrupted", zap.Error(err))
+	}
+}
+
+// POST /hold-shelf/clear-expired?branch=Central
+
+// ClearExpiredHolds godoc
+// @Summary Clear the expired holds from a branch's hold shelf
+// @Description Expires the holds not picked up within circulation.hold_shelf_days of their copy reaching the hold shelf, publishing each on the event bus as hold.expired. Each copy goes back into circulation as if checked in at the branch: set aside for the next hold on its book, or reshelved or sent home.
+// @Tags circulation
+// @Produce json
+// @Param branch query string true "Pickup branch"
+// @Success 200 {object} ClearedShelf
+// @Failure 400 {object} map[string]string
+// @Router /hold-shelf/clear-expired [post]
+func (h *Handler) ClearExpiredHolds(w http.ResponseWriter, r *http.Request) {
+	out, err := h.svc.ClearExpired(r.Context(), r.URL.Query().Get("branch"))
+	if err != nil {
+		h.writeError(w, "failed to clear expired holds", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(out)
+}
+
+// GET /loans/{id}
+
+// GetLoan godoc
+// @Summary Get a loan
+// @Tags circulation
+// @Produce json
+// @Param id path int true "Loan ID"
+// @Success 200 {object} Loan
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /loans/{id} [get]
+func (h *Handler) GetLoan(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "invalid loan ID")
+	if !ok {
+		return
+	}
+	l, err := h.svc.GetLoan(r.Context(), id)
+	if err != nil {
+		h.writeError(w, "failed to get loan", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(l)
+}
+
+// POST /loans/{id}/renew
+
+// RenewLoan godoc
+// @Summary Renew a loan
+// @Description Sets the loan due a new loan period from now. Loans with no renewals left under the loan policy in force, of a book other members hold or of a suspended member can't be renewed.
+// @Tags circulation
+// @Produce json
+// @Param id path int true "Loan ID"
+// @Success 200 {object} Loan
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /loans/{id}/renew [post]
+func (h *Handler) RenewLoan(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "invalid loan ID")
+	if !ok {
+		return
+	}
+	l, err := h.svc.Renew(r.Context(), id)
+	if err != nil {
+		h.writeError(w, "failed to renew loan", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(l)
+}
+
+// POST /loans/{id}/return
+
+// ReturnLoan godoc
+// @Summary Check a loan in
+// @Description Records the copy back. Copies returned late are fined at the rate of the member's patron group for each day or part of a day the branch was open, up to the loan policy's cap. Copies back within the grace period aren't fined.
+// @Tags circulation
+// @Produce json
+// @Param id path int true "Loan ID"
+// @Success 200 {object} Loan
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /loans/{id}/return [post]
+func (h *Handler) ReturnLoan(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "invalid loan ID")
+	if !ok {
+		return
+	}
+	l, err := h.svc.Return(r.Context(), id)
+	if err != nil {
+		h.writeError(w, "failed to return loan", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(l)
+}
+
+// POST /loans/{id}/claim-returned
+
+// ClaimReturned godoc
+// @Summary Record a member's claim to have returned a loan
+// @Description For a copy the member says they returned but the library still has out. The loan's fine stops at what returning the copy now would cost and isn't counted in the member's fines while the claim is open, the loan can't be renewed, and the copy's branch gets a shelf check at GET /shelf-checks. Resolve the claim with POST /loans/{id}/claim-returned/resolve; a shelf check finding the copy, or the copy being checked in, resolves it as found.
+// @Tags circulation
+// @Produce json
+// @Param id path int true "Loan ID"
+// @Success 200 {object} Loan
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /loans/{id}/claim-returned [post]
+func (h *Handler) ClaimReturned(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "invalid loan ID")
+	if !ok {
+		return
+	}
+	l, err := h.svc.ClaimReturned(r.Context(), id)
+	if err != nil {
+		h.writeError(w, "failed to claim loan returned", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(l)
+}
+
+// POST /loans/{id}/claim-returned/resolve
+
+// ResolveClaim godoc
+// @Summary Resolve a claim that a loan was returned
+// @Description found returns the loan as of the claim, charging the fine it had then, and puts the copy back into circulation at its branch. lost withdraws the copy and adds a replacement charge to what the member owes: charge, or the copy's replacement cost (or price) when that is in the circulation currency.
+// @Tags circulation
+// @Accept json
+// @Produce json
+// @Param id path int true "Loan ID"
+// @Param request body ResolveClaimRequest true "Outcome of the claim"
+// @Success 200 {object} Loan
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /loans/{id}/claim-returned/resolve [post]
+func (h *Handler) ResolveClaim(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "invalid loan ID")
+	if !ok {
+		return
+	}
+	var req ResolveClaimRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	l, err := h.svc.ResolveClaim(r.Context(), id, req)
+	if err != nil {
+		h.writeError(w, "failed to resolve claim", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(l)
+}
+
+// GET /shelf-checks?branch=Central
+
+// ListShelfChecks godoc
+// @Summary List the shelf checks a branch has to do
+// @Description Lists the copies members claim to have returned that the branch should look for, in call number order, until each check is recorded or its claim resolved.
+// @Tags circulation
+// @Produce json
+// @Param branch query string true "Branch"
+// @Success 200 {array} ShelfCheck
+// @Failure 400 {object} map[string]string
+// @Router /shelf-checks [get]
+func (h *Handler) ListShelfChecks(w http.ResponseWriter, r *http.Request) {
+	list, err := h.svc.ShelfChecks(r.Context(), r.URL.Query().Get("branch"))
+	if err != nil {
+		h.writeError(w, "failed to list shelf checks", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// POST /shelf-checks/{id}/result
+
+// RecordShelfCheck godoc
+// @Summary Record what a shelf check found
+// @Description A copy found resolves the claim on its loan as found. One not found leaves the claim open for staff to resolve, usually as lost.
+// @Tags circulation
+// @Accept json
+// @Produce json
+// @Param id path int true "Shelf check ID"
+// @Param request body ShelfCheckResult true "What the check found"
+// @Success 200 {object} ShelfCheck
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /shelf-checks/{id}/result [post]
+func (h *Handler) RecordShelfCheck(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "invalid shelf check ID")
+	if !ok {
+		return
+	}
+	var res ShelfCheckResult
+	if err := json.NewDecoder(r.Body).Decode(&res); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	sc, err := h.svc.RecordShelfCheck(r.Context(), id, res)
+	if err != nil {
+		h.writeError(w, "failed to record shelf check", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sc)
+}
+
+// GET /members/{id}/links
+
+// ListMemberLinks godoc
+// @Summary List a member's family links
+// @Description Lists the links of the member to their children, then to their guardians, with what each lets the guardian see and do.
+// @Tags family
+// @Produce json
+// @Param id path string true "Member ID"
+// @Success 200 {array} Link
+// @Failure 404 {object} map[string]string
+// @Router /members/{id}/links [get]
+func (h *Handler) ListMemberLinks(w http.ResponseWriter, r *http.Request) {
+	list, err := h.svc.ListLinks(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		h.writeError(w, "failed to list member links", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// POST /members/{id}/links
+
+// LinkChild godoc
+// @Summary Link a child to a guardian
+// @Description Links a member of the child patron group to the guardian member. Each permission is off unless given: view_loans shows the child's loans out, view_history their returned loans too, view_holds their holds, and manage lets the guardian renew loans and place and cancel holds for the child.
+// @Tags family
+// @Accept json
+// @Produce json
+// @Param id path string true "Guardian member ID"
+// @Param link body LinkRequest true "Child and permissions"
+// @Success 201 {object} Link
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /members/{id}/links [post]
+func (h *Handler) LinkChild(w http.ResponseWriter, r *http.Request) {
+	var req LinkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	k, err := h.svc.Link(r.Context(), mux.Vars(r)["id"], req)
+	if err != nil {
+		h.writeError(w, "failed to link member", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(k)
+}
+
+// PUT /members/{id}/links/{child_id}
+
+// UpdateMemberLink godoc
+// @Summary Change what a family link allows
+// @Description Replaces the permissions of the link, e.g. when the child withdraws consent for their guardian to see their loan history.
+// @Tags family
+// @Accept json
+// @Produce json
+// @Param id path string true "Guardian member ID"
+// @Param child_id path string true "Child member ID"
+// @Param permissions body LinkPermissions true "Permissions"
+// @Success 200 {object} Link
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /members/{id}/links/{child_id} [put]
+func (h *Handler) UpdateMemberLink(w http.ResponseWriter, r *http.Request) {
+	var p LinkPermissions
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	vars := mux.Vars(r)
+	k, err := h.svc.UpdateLink(r.Context(), vars["id"], vars["child_id"], p)
+	if err != nil {
+		h.writeError(w, "failed to update member link", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(k)
+}
+
+// DELETE /members/{id}/links/{child_id}
+
+// UnlinkChild godoc
+// @Summary Unlink a child from a guardian
+// @Tags family
+// @Param id path string true "Guardian member ID"
+// @Param child_id path string true "Child member ID"
+// @Success 204 "No Content"
+// @Failure 404 {object} map[string]string
+// @Router /members/{id}/links/{child_id} [delete]
+func (h *Handler) UnlinkChild(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	if err := h.svc.Unlink(r.Context(), vars["id"], vars["child_id"]); err != nil {
+		h.writeError(w, "failed to unlink member", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GET /members/{id}/family/{child_id}/loans?status=out
+
+// ListFamilyLoans godoc
+// @Summary List a linked child's loans
+// @Description Lists the child's loans to the guardian, as GET /members/{id}/loans does, if the link has view_loans. Returned loans are only listed with view_history.
+// @Tags family
+// @Produce json
+// @Param id path string true "Guardian member ID"
+// @Param child_id path string true "Child member ID"
+// @Param status query string false "Only loans out or returned" Enums(out, returned)
+// @Success 200 {array} Loan
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /members/{id}/family/{child_id}/loans [get]
+func (h *Handler) ListFamilyLoans(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	list, err := h.svc.FamilyLoans(r.Context(), vars["id"], vars["child_id"], r.URL.Query().Get("status"))
+	if err != nil {
+		h.writeError(w, "failed to list loans", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// POST /members/{id}/family/{child_id}/loans/{loan_id}/renew
+
+// RenewFamilyLoan godoc
+// @Summary Renew a linked child's loan
+// @Description Renews the child's loan as POST /loans/{id}/renew does, if the link has manage.
+// @Tags family
+// @Produce json
+// @Param id path string true "Guardian member ID"
+// @Param child_id path string true "Child member ID"
+// @Param loan_id path int true "Loan ID"
+// @Success 200 {object} Loan
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /members/{id}/family/{child_id}/loans/{loan_id}/renew [post]
+func (h *Handler) RenewFamilyLoan(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	loanID, err := strconv.Atoi(vars["loan_id"])
+	if err != nil {
+		http.Error(w, "invalid loan ID", http.StatusBadRequest)
+		return
+	}
+	l, err := h.svc.FamilyRenew(r.Context(), vars["id"], vars["child_id"], loanID)
+	if err != nil {
+		h.writeError(w, "failed to renew loan", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(l)
+}
+
+// GET /members/{id}/family/{child_id}/holds
+
+// ListFamilyHolds godoc
+// @Summary List a linked child's holds
+// @Description Lists the child's holds to the guardian if the link has view_holds.
+// @Tags family
+// @Produce json
+// @Param id path string true "Guardian member ID"
+// @Param child_id path string true "Child member ID"
+// @Success 200 {array} Hold
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /members/{id}/family/{child_id}/holds [get]
+func (h *Handler) ListFamilyHolds(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	list, err := h.svc.FamilyHolds(r.Context(), vars["id"], vars["child_id"])
+	if err != nil {
+		h.writeError(w, "failed to list holds", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// POST /members/{id}/family/{child_id}/holds
+
+// PlaceFamilyHold godoc
+// @Summary Place a hold for a linked child
+// @Description Places a hold for the child as POST /members/{id}/holds does, within the child's hold limit, if the link has manage.
+// @Tags family
+// @Accept json
+// @Produce json
+// @Param id path string true "Guardian member ID"
+// @Param child_id path string true "Child member ID"
+// @Param hold body HoldRequest true "Book to hold"
+// @Success 201 {object} Hold
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /members/{id}/family/{child_id}/holds [post]
+func (h *Handler) PlaceFamilyHold(w http.ResponseWriter, r *http.Request) {
+	var req HoldRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	vars := mux.Vars(r)
+	hold, err := h.svc.FamilyPlaceHold(r.Context(), vars["id"], vars["child_id"], req.BookID, req.PickupBranch)
+	if err != nil {
+		h.writeError(w, "failed to place hold", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(hold)
+}
+
+// DELETE /members/{id}/family/{child_id}/holds/{hold_id}
+
+// CancelFamilyHold godoc
+// @Summary Cancel a linked child's hold
+// @Description Cancels the child's hold if the link has manage.
+// @Tags family
+// @Param id path string true "Guardian member ID"
+// @Param child_id path string true "Child member ID"
+// @Param hold_id path int true "Hold ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /members/{id}/family/{child_id}/holds/{hold_id} [delete]
+func (h *Handler) CancelFamilyHold(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	holdID, err := strconv.Atoi(vars["hold_id"])
+	if err != nil {
+		http.Error(w, "invalid hold ID", http.StatusBadRequest)
+		return
+	}
+	if _, err := h.svc.FamilyCancelHold(r.Context(), vars["id"], vars["child_id"], holdID); err != nil {
+		h.writeError(w, "failed to cancel hold", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func pathID(w http.ResponseWriter, r *http.Request, msg string) (int, bool) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, msg, http.StatusBadRequest)
+		return 0, false
+	}
+	return id, true
+}
+
+// sinceParam parses the optional since query parameter
+func sinceParam(w http.ResponseWriter, r *http.Request) (*time.Time, bool) {
+	v := r.URL.Query().Get("since")
+	if v == "" {
+		return nil, true
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		http.Error(w, "invalid since parameter, expected RFC 3339", http.StatusBadRequest)
+		return nil, false
+	}
+	return &t, true
+}
+
+func (h *Handler) writeError(w http.ResponseWriter, msg string, err error) {
+	switch {
+	case errors.Is(err, ErrInvalid), errors.Is(err, policy.ErrInvalid):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, ErrMemberNotFound), errors.Is(err, ErrLoanNotFound), errors.Is(err, ErrHoldNotFound),
+		errors.Is(err, ErrCopyNotFound), errors.Is(err, ErrBookNotFound), errors.Is(err, ErrLinkNotFound),
+		errors.Is(err, ErrShelfCheckNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, ErrNotPermitted):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, ErrWithdrawn), errors.Is(err, ErrInRepair), errors.Is(err, ErrOnLoan), errors.Is(err, ErrHeldForOther),
+		errors.Is(err, ErrLoanLimit), errors.Is(err, ErrFormatLimit), errors.Is(err, ErrHoldLimit), errors.Is(err, ErrAlreadyHeld),
+		errors.Is(err, ErrRenewalLimit), errors.Is(err, ErrOnHold), errors.Is(err, ErrReturned),
+		errors.Is(err, ErrHoldClosed), errors.Is(err, ErrSuspended), errors.Is(err, ErrExpired),
+		errors.Is(err, ErrClaimed), errors.Is(err, ErrNotClaimed), errors.Is(err, ErrShelfChecked):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		httperr.Write(w, h.logger, msg, err)
+	}
+}
@@ -0,0 +1,15 @@
+package circulation
+
+import (
+	"context"
+	"public_library/internal/book"
+)
+
+// CopyHistory returns a copy's lifecycle, oldest first: its acquisition,
+// checkouts and returns, holds it was trapped and shelved for, repairs,
+// moves between branches, transits and withdrawal, for following up damage
+// disputes. Copies of books outside the caller's audience limit are not
+// found.
+func (s *Service) CopyHistory(ctx context.Context, acquisitionID int) (*CopyHistory, error) {
+	return s.repo.CopyHistory(ctx, acquisitionID, book.AllowedAudiences(ctx))
+}
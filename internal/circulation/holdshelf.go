@@ -0,0 +1,95 @@
+package circulation
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"public_library/internal/eventbus"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// HoldShelf returns the copies on the branch's hold shelf by member name,
+// those shelved since the given time if it is set, for the daily pickup
+// list and its slips
+func (s *Service) HoldShelf(ctx context.Context, branch string, since *time.Time) ([]ShelfItem, error) {
+	branch = strings.TrimSpace(branch)
+	if branch == "" || utf8.RuneCountInString(branch) > maxTextLength {
+		return nil, fmt.Errorf("%w: branch is required and must be at most %d characters", ErrInvalid, maxTextLength)
+	}
+	return s.repo.ListShelf(ctx, branch, s.cfg.HoldShelfDays, since)
+}
+
+// ClearExpired expires the holds whose copy has been on the branch's hold
+// shelf for longer than hold_shelf_days and puts their copies back into
+// circulation: each is set aside for the next hold on its book, if any, as
+// if it were checked in at the branch. Expired holds are published on the
+// event bus under eventbus.HoldExpired.
+func (s *Service) ClearExpired(ctx context.Context, branch string) (*ClearedShelf, error) {
+	branch = strings.TrimSpace(branch)
+	if branch == "" || utf8.RuneCountInString(branch) > maxTextLength {
+		return nil, fmt.Errorf("%w: branch is required and must be at most %d characters", ErrInvalid, maxTextLength)
+	}
+	expired, err := s.repo.ExpireShelved(ctx, branch, s.cfg.HoldShelfDays)
+	if err != nil {
+		return nil, err
+	}
+	out := &ClearedShelf{Branch: branch, Holds: make([]ClearedHold, 0, len(expired))}
+	for _, it := range expired {
+		if h, err := s.repo.GetHold(ctx, it.HoldID); err == nil {
+			s.bus.Publish(ctx, eventbus.HoldExpired, h.ID, *h)
+		}
+		c, err := s.repo.GetCopy(ctx, it.AcquisitionID)
+		if err != nil {
+			return nil, err
+		}
+		rt, err := s.repo.Route(ctx, c.ID, branch, s.floats(c.Collection, branch))
+		if err != nil {
+			return nil, err
+		}
+		if rt.Shelved {
+			if h, err := s.repo.GetHold(ctx, *rt.HoldID); err == nil {
+				s.bus.Publish(ctx, eventbus.HoldReady, h.ID, *h)
+			}
+		}
+		out.Holds = append(out.Holds, ClearedHold{HoldID: it.HoldID, MemberID: it.MemberID, Title: it.Title,
+			AcquisitionID: it.AcquisitionID, Barcode: it.Barcode, Disposition: rt.Disposition,
+			Destination: rt.Destination, Floated: rt.Floated, NextHoldID: rt.HoldID})
+	}
+	return out, nil
+}
+
+// WriteSlips writes a printable hold-shelf slip for each item, separated by
+// form feeds so receipt printers cut between them. Slips show the last four
+// digits of the card number only.
+func WriteSlips(w io.Writer, items []ShelfItem) error {
+	for i, it := range items {
+		if i > 0 {
+			if _, err := io.WriteString(w, "\f"); err != nil {
+				return err
+			}
+		}
+		card := it.CardNumber
+		if n := len(card); n > 4 {
+			card = strings.Repeat("*", n-4) + card[n-4:]
+		}
+		_, err := fmt.Fprintf(w, "HOLD FOR\n%s\n", it.MemberName)
+		if err == nil && card != "" {
+			_, err = fmt.Fprintf(w, "Card: %s\n", card)
+		}
+		if err == nil {
+			_, err = fmt.Fprintf(w, "\n%s\n%s\n", it.Title, it.CallNumber)
+		}
+		if err == nil && it.Barcode != "" {
+			_, err = fmt.Fprintf(w, "Item: %s\n", it.Barcode)
+		}
+		if err == nil {
+			_, err = fmt.Fprintf(w, "\nPick up at %s by %s\n", it.PickupBranch, it.PickupBy.Format("Mon 2 Jan 2006"))
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
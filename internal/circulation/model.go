@@ -0,0 +1,501 @@
+package circulation
+
+import "time"
+
+// Member is a library patron. ID is the member's ID in the identity system
+// in front of the API, the one reading lists and the X-Member-ID header use.
+type Member struct {
+	ID         string `json:"id" example:"m-1001"`
+	CardNumber string `json:"card_number,omitempty" example:"21234567890128"`
+	Name       string `json:"name" example:"Ada Lovelace"`
+	Email      string `json:"email,omitempty" example:"ada@example.org"`
+	// PatronGroup decides the member's loan and hold limits, loan periods
+	// and fine rate
+	PatronGroup string `json:"patron_group" example:"adult" enums:"child,adult,senior,student,staff,guest"`
+	// AutoRenew opts the member in to having loans renewed shortly before
+	// they are due, when no one else is waiting and renewals are left
+	AutoRenew bool `json:"auto_renew" example:"false"`
+	// AnonymizeLoans unlinks the member's loans from them once they are
+	// returned without a fine
+	AnonymizeLoans bool `json:"anonymize_loans" example:"false"`
+	// AnalyticsOptOut leaves the member's searches and views out of the
+	// anonymous usage counts
+	AnalyticsOptOut bool `json:"analytics_opt_out" example:"false"`
+	// Suspension is set while the member is suspended, through
+	// PUT /members/{id}/suspension
+	Suspension *Suspension `json:"suspension,omitempty"`
+	// ExpiresAt is when a guest account expires; expired members can't
+	// check out, renew or place holds
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// expired reports whether the member's account has expired
+func (m *Member) expired() bool {
+	return m.ExpiresAt != nil && !m.ExpiresAt.After(time.Now())
+}
+
+// GuestRequest registers a guest account for a visitor
+type GuestRequest struct {
+	Name       string `json:"name" example:"Grace Hopper"`
+	Email      string `json:"email" example:"grace@example.org"`
+	CardNumber string `json:"card_number" example:"29990000000017"`
+	// Days is how long the account lasts, circulation.guest_days by default
+	// and at most circulation.max_guest_days
+	Days int `json:"days" example:"3"`
+}
+
+// Suspension blocks a member's checkouts, renewals and holds; they can
+// still return copies
+type Suspension struct {
+	Reason      string    `json:"reason" example:"Repeated damage to items"`
+	SuspendedAt time.Time `json:"suspended_at"`
+	// Until is when the suspension expires; without it the member stays
+	// suspended until it is lifted
+	Until *time.Time `json:"until,omitempty"`
+}
+
+// LinkPermissions are what a family link lets the guardian do with the
+// child's account. Each is off until consented to.
+type LinkPermissions struct {
+	// ViewLoans shows the child's loans out to the guardian
+	ViewLoans bool `json:"view_loans" example:"true"`
+	// ViewHistory also shows the child's returned loans; it needs
+	// ViewLoans
+	ViewHistory bool `json:"view_history" example:"false"`
+	// ViewHolds shows the child's holds to the guardian
+	ViewHolds bool `json:"view_holds" example:"true"`
+	// Manage lets the guardian renew the child's loans and place and cancel
+	// their holds
+	Manage bool `json:"manage" example:"false"`
+}
+
+// Link links a child member to a guardian member
+type Link struct {
+	GuardianID   string `json:"guardian_id" example:"m-1001"`
+	GuardianName string `json:"guardian_name" example:"Ada Lovelace"`
+	ChildID      string `json:"child_id" example:"m-1002"`
+	ChildName    string `json:"child_name" example:"Byron Lovelace"`
+	LinkPermissions
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// LinkRequest links a child to the guardian with the given permissions
+type LinkRequest struct {
+	ChildID string `json:"child_id" example:"m-1002"`
+	LinkPermissions
+}
+
+// PatronGroup is the lending policy of one patron group
+type PatronGroup struct {
+	Name      string `json:"name" example:"adult"`
+	LoanLimit int    `json:"loan_limit" example:"30"`
+	// LoanDays is the loan period; 0 uses the loan period of each format
+	LoanDays   int    `json:"loan_days" example:"0"`
+	FinePerDay string `json:"fine_per_day" example:"0.25"`
+	Currency   string `json:"currency" example:"USD"`
+	HoldLimit  int    `json:"hold_limit" example:"15"`
+	// InLibrary groups borrow for use in the library only
+	InLibrary bool `json:"in_library" example:"false"`
+}
+
+// Status sums up what a member has out and owes against the limits of
+// their patron group
+type Status struct {
+	MemberID     string `json:"member_id" example:"m-1001"`
+	PatronGroup  string `json:"patron_group" example:"adult"`
+	LoansOut     int    `json:"loans_out" example:"3"`
+	LoanLimit    int    `json:"loan_limit" example:"30"`
+	Overdue      int    `json:"overdue" example:"1"`
+	HoldsWaiting int    `json:"holds_waiting" example:"2"`
+	HoldLimit    int    `json:"hold_limit" example:"15"`
+	// ClaimsReturned counts the loans the member claims to have returned
+	// whose claim is not resolved yet; they are not counted as overdue
+	ClaimsReturned int `json:"claims_returned" example:"0"`
+	// Fines is the total of the fines and replacement charges on returned
+	// loans and the fines accrued so far on loans out past due, leaving out
+	// those of loans claimed returned until the claim is resolved
+	Fines string `json:"fines" example:"1.75"`
+	// Paid is the total of the payments the member made at the desk, and
+	// Balance what is left of Fines after them
+	Paid     string `json:"paid" example:"1.00"`
+	Balance  string `json:"balance" example:"0.75"`
+	Currency string `json:"currency" example:"USD"`
+	// Suspended members can't check out, renew or place holds
+	Suspended  bool        `json:"suspended" example:"false"`
+	Suspension *Suspension `json:"suspension,omitempty"`
+	// Expired guest accounts can't check out, renew or place holds either
+	Expired     bool       `json:"expired" example:"false"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	CanCheckout bool       `json:"can_checkout" example:"true"`
+	CanHold     bool       `json:"can_hold" example:"true"`
+}
+
+// Loan is the checkout of one copy to a member
+type Loan struct {
+	ID int `json:"id" example:"1"`
+	// MemberID is empty once the loan has been anonymized
+	MemberID      string `json:"member_id" example:"m-1001"`
+	AcquisitionID int    `json:"acquisition_id" example:"31"`
+	BookID        int    `json:"book_id" example:"1"`
+	Title         string `json:"title" example:"The Left Hand of Darkness"`
+	// CourseReserveID is set for loans of copies on course reserve, which
+	// lend on the reserve's terms
+	CourseReserveID *int       `json:"course_reserve_id,omitempty" example:"4"`
+	CheckedOutAt    time.Time  `json:"checked_out_at"`
+	DueAt           time.Time  `json:"due_at"`
+	Renewals        int        `json:"renewals" example:"0"`
+	MaxRenewals     int        `json:"max_renewals" example:"2"`
+	Overdue         bool       `json:"overdue" example:"false"`
+	ReturnedAt      *time.Time `json:"returned_at,omitempty"`
+	// Fine is what the member was charged for returning the copy late, in
+	// Currency; on loans out past due, what has accrued so far
+	Fine     string `json:"fine,omitempty" example:"0.75"`
+	Currency string `json:"currency,omitempty" example:"USD"`
+	// ClaimedReturnedAt is set when the member claims to have returned the
+	// copy; the fine stops accruing until the claim is resolved, as
+	// ClaimOutcome found or lost
+	ClaimedReturnedAt *time.Time `json:"claimed_returned_at,omitempty"`
+	ClaimOutcome      string     `json:"claim_outcome,omitempty" example:"lost" enums:"found,lost"`
+	// ReplacementCharge is what the member was charged for a copy lost, in
+	// Currency
+	ReplacementCharge string `json:"replacement_charge,omitempty" example:"24.99"`
+}
+
+// Outcomes of a claim that a loan was returned
+const (
+	// ClaimFound returns the loan as of the claim: the copy was in the
+	// library all along
+	ClaimFound = "found"
+	// ClaimLost withdraws the copy as lost and charges the member for it
+	ClaimLost = "lost"
+)
+
+// ResolveClaimRequest resolves a claim that a loan was returned
+type ResolveClaimRequest struct {
+	Outcome string `json:"outcome" example:"lost" enums:"found,lost"`
+	// Charge is what a lost copy costs the member, in the circulation
+	// currency; it defaults to the copy's replacement cost, or the price
+	// paid, when that is in the circulation currency
+	Charge string `json:"charge,omitempty" example:"24.99"`
+}
+
+// ShelfCheck asks a branch to look for a copy a member claims to have
+// returned, on its shelf and wherever returns may have gone astray
+type ShelfCheck struct {
+	ID                int       `json:"id" example:"5"`
+	LoanID            int       `json:"loan_id" example:"12"`
+	MemberID          string    `json:"member_id" example:"m-1001"`
+	AcquisitionID     int       `json:"acquisition_id" example:"31"`
+	Barcode           string    `json:"barcode,omitempty" example:"31234000123456"`
+	Title             string    `json:"title" example:"The Dispossessed"`
+	CallNumber        string    `json:"call_number" example:"813.54 LEG"`
+	ShelfLocation     string    `json:"shelf_location,omitempty" example:"Stacks, level 2"`
+	Branch            string    `json:"branch" example:"Central"`
+	ClaimedReturnedAt time.Time `json:"claimed_returned_at"`
+	RequestedAt       time.Time `json:"requested_at"`
+	// CheckedAt, Found and Note are set once the shelf check is done
+	CheckedAt *time.Time `json:"checked_at,omitempty"`
+	Found     *bool      `json:"found,omitempty" example:"false"`
+	Note      string     `json:"note,omitempty" example:"Not on the shelf or the returns trolley"`
+}
+
+// ShelfCheckResult records what a shelf check found
+type ShelfCheckResult struct {
+	Found bool   `json:"found" example:"false"`
+	Note  string `json:"note" example:"Not on the shelf or the returns trolley"`
+}
+
+// Anonymized reports how many returned loans were unlinked from a member
+type Anonymized struct {
+	MemberID string `json:"member_id" example:"m-1001"`
+	Loans    int    `json:"loans" example:"42"`
+}
+
+// CheckoutRequest lends a copy to a member
+type CheckoutRequest struct {
+	MemberID      string `json:"member_id" example:"m-1001"`
+	AcquisitionID int    `json:"acquisition_id" example:"31"`
+}
+
+// DeskCheckoutRequest checks out a copy as scanned at the circulation desk:
+// the member's library card and the copy's item barcode
+type DeskCheckoutRequest struct {
+	CardNumber string `json:"card_number" example:"21234567890128"`
+	Barcode    string `json:"barcode" example:"31234000123456"`
+}
+
+// Reasons a desk checkout is denied for
+const (
+	DenyCardNotFound    = "card_not_found"
+	DenyMemberSuspended = "member_suspended"
+	DenyMemberExpired   = "member_expired"
+	DenyLoanLimit       = "loan_limit"
+	DenyFormatLimit     = "format_limit"
+	DenyItemNotFound    = "item_not_found"
+	DenyItemWithdrawn   = "item_withdrawn"
+	DenyItemInRepair    = "item_in_repair"
+	DenyItemOnLoan      = "item_on_loan"
+	DenyItemOnHold      = "item_on_hold"
+)
+
+// Denial is one reason a desk checkout was denied for
+type Denial struct {
+	Code    string `json:"code" example:"loan_limit" enums:"card_not_found,member_suspended,member_expired,loan_limit,format_limit,item_not_found,item_withdrawn,item_in_repair,item_on_loan,item_on_hold"`
+	Message string `json:"message" example:"member has reached the loan limit"`
+}
+
+// DeskCheckout is the outcome of a desk checkout: the loan, or every reason
+// it was denied for
+type DeskCheckout struct {
+	// Member is the card's member, left out for unknown cards
+	Member  *Member  `json:"member,omitempty"`
+	Loan    *Loan    `json:"loan,omitempty"`
+	Denials []Denial `json:"denials,omitempty"`
+}
+
+// BatchCheckinRequest checks in the copies a returns sorter read, at the
+// branch the sorter is at
+type BatchCheckinRequest struct {
+	Branch   string   `json:"branch" example:"Central"`
+	Barcodes []string `json:"barcodes" example:"31234000123456,31234000654321"`
+}
+
+// Where a copy goes after it is checked in
+const (
+	// DispositionReshelve puts the copy back on the shelf
+	DispositionReshelve = "reshelve"
+	// DispositionHoldShelf puts the copy on the hold shelf for the hold it
+	// was trapped for
+	DispositionHoldShelf = "hold_shelf"
+	// DispositionTransit sends the copy to Destination: the pickup branch
+	// of its hold, or its home branch
+	DispositionTransit = "transit"
+)
+
+// CheckinItem is the outcome of checking in one copy of a batch
+type CheckinItem struct {
+	Barcode       string `json:"barcode" example:"31234000123456"`
+	AcquisitionID int    `json:"acquisition_id,omitempty" example:"31"`
+	Title         string `json:"title,omitempty" example:"The Left Hand of Darkness"`
+	// LoanID is the loan the check-in returned, if the copy was out
+	LoanID *int `json:"loan_id,omitempty" example:"12"`
+	// Fine is charged on the returned loan, in Currency
+	Fine        string `json:"fine,omitempty" example:"0.75"`
+	Currency    string `json:"currency,omitempty" example:"USD"`
+	Disposition string `json:"disposition,omitempty" example:"transit" enums:"reshelve,hold_shelf,transit"`
+	// Destination is the branch a copy in transit goes to
+	Destination string `json:"destination,omitempty" example:"Eastside"`
+	// Floated is set when the copy, of a floating collection, stays at the
+	// branch and it became its home branch
+	Floated bool `json:"floated,omitempty" example:"false"`
+	// HoldID is the hold the copy was trapped for
+	HoldID *int `json:"hold_id,omitempty" example:"7"`
+	// Error is why the copy could not be checked in, such as an unknown
+	// barcode; the rest of the batch is checked in regardless
+	Error string `json:"error,omitempty" example:""`
+}
+
+// BatchCheckin reports the outcome of every copy of a batch, in the order
+// they were read
+type BatchCheckin struct {
+	Branch string        `json:"branch" example:"Central"`
+	Items  []CheckinItem `json:"items"`
+	// Returned counts the loans returned; Failed the copies not checked in
+	Returned int `json:"returned" example:"38"`
+	Failed   int `json:"failed" example:"1"`
+}
+
+// Hold is a member's request for the next available copy of a book
+type Hold struct {
+	ID       int       `json:"id" example:"1"`
+	MemberID string    `json:"member_id" example:"m-1001"`
+	BookID   int       `json:"book_id" example:"1"`
+	Title    string    `json:"title" example:"The Dispossessed"`
+	Status   string    `json:"status" example:"waiting" enums:"waiting,fulfilled,cancelled,expired"`
+	PlacedAt time.Time `json:"placed_at"`
+	// PickupBranch is where the member picks the copy up; without one it is
+	// the branch the copy is checked in at
+	PickupBranch string `json:"pickup_branch,omitempty" example:"Central"`
+	// AcquisitionID is the copy set aside for the hold at check-in
+	AcquisitionID *int `json:"acquisition_id,omitempty" example:"31"`
+	// ShelvedAt is when the copy reached the pickup branch's hold shelf
+	ShelvedAt *time.Time `json:"shelved_at,omitempty"`
+	// ClosedAt is when the hold was fulfilled by a checkout, cancelled or
+	// expired on the hold shelf
+	ClosedAt *time.Time `json:"closed_at,omitempty"`
+}
+
+// Hold statuses
+const (
+	HoldWaiting   = "waiting"
+	HoldFulfilled = "fulfilled"
+	HoldCancelled = "cancelled"
+	HoldExpired   = "expired"
+)
+
+// ShelfItem is a copy on a branch's hold shelf and the member it waits for
+type ShelfItem struct {
+	HoldID        int       `json:"hold_id" example:"7"`
+	MemberID      string    `json:"member_id" example:"m-1001"`
+	MemberName    string    `json:"member_name" example:"Ada Lovelace"`
+	CardNumber    string    `json:"card_number,omitempty" example:"21234567890128"`
+	BookID        int       `json:"book_id" example:"1"`
+	Title         string    `json:"title" example:"The Dispossessed"`
+	CallNumber    string    `json:"call_number" example:"813.54 LEG"`
+	AcquisitionID int       `json:"acquisition_id" example:"31"`
+	Barcode       string    `json:"barcode,omitempty" example:"31234000123456"`
+	PickupBranch  string    `json:"pickup_branch" example:"Central"`
+	ShelvedAt     time.Time `json:"shelved_at"`
+	// PickupBy is when the hold expires, circulation.hold_shelf_days after
+	// the copy was shelved
+	PickupBy time.Time `json:"pickup_by"`
+	Expired  bool      `json:"expired" example:"false"`
+}
+
+// ClearedHold is an expired hold cleared from the hold shelf and where its
+// copy goes next
+type ClearedHold struct {
+	HoldID        int    `json:"hold_id" example:"7"`
+	MemberID      string `json:"member_id" example:"m-1001"`
+	Title         string `json:"title" example:"The Dispossessed"`
+	AcquisitionID int    `json:"acquisition_id" example:"31"`
+	Barcode       string `json:"barcode,omitempty" example:"31234000123456"`
+	Disposition   string `json:"disposition" example:"hold_shelf" enums:"reshelve,hold_shelf,transit"`
+	Destination   string `json:"destination,omitempty" example:"Eastside"`
+	Floated       bool   `json:"floated,omitempty" example:"false"`
+	// NextHoldID is the hold the copy was set aside for next
+	NextHoldID *int `json:"next_hold_id,omitempty" example:"9"`
+}
+
+// ClearedShelf reports the expired holds cleared from a branch's hold shelf
+type ClearedShelf struct {
+	Branch string        `json:"branch" example:"Central"`
+	Holds  []ClearedHold `json:"holds"`
+}
+
+// AvailabilityRequest names the books to check, such as those of a
+// member's wishlist
+type AvailabilityRequest struct {
+	BookIDs []int `json:"book_ids" example:"1,12,31"`
+}
+
+// Availability is how many copies of a book are on the shelf
+type Availability struct {
+	BookID int `json:"book_id" example:"12"`
+	// Copies counts the copies not withdrawn, and Available those on the
+	// shelf: not on loan, in repair or set aside for a hold
+	Copies    int `json:"copies" example:"3"`
+	Available int `json:"available" example:"1"`
+	OnLoan    int `json:"on_loan" example:"2"`
+	// HoldsWaiting counts the holds still waiting for a copy
+	HoldsWaiting int `json:"holds_waiting" example:"0"`
+	// NextDueAt is when the first copy on loan is due back
+	NextDueAt *time.Time `json:"next_due_at,omitempty"`
+	// Branches are where copies are available, by name
+	Branches []string `json:"branches" example:"Central"`
+}
+
+// AvailabilityReport is the availability of each book asked for, in the
+// order asked
+type AvailabilityReport struct {
+	Books []Availability `json:"books"`
+	// NotFound lists the IDs of books that don't exist or are outside the
+	// caller's audience limit
+	NotFound []int `json:"not_found" example:"99"`
+}
+
+// Copy history event types. Moves, transits and withdrawals come from the
+// copy's event log; the rest from its loans, holds and repairs.
+const (
+	CopyAcquired       = "acquired"
+	CopyCheckedOut     = "checked_out"
+	CopyReturned       = "returned"
+	CopyTrapped        = "trapped"
+	CopyShelved        = "shelved"
+	CopySentForRepair  = "sent_for_repair"
+	CopyBackFromRepair = "back_from_repair"
+	CopyMoved          = "moved"
+	CopyInTransit      = "transit"
+	CopyWithdrawn      = "withdrawn"
+	CopyClaimReturned  = "claimed_returned"
+)
+
+// CopyEvent is one thing that happened to a copy. Only the fields that
+// apply to its type are set.
+type CopyEvent struct {
+	Type string    `json:"type" example:"checked_out"`
+	At   time.Time `json:"at"`
+	// LoanID, HoldID and RepairID are the loan, hold or repair the event
+	// belongs to
+	LoanID   *int `json:"loan_id,omitempty" example:"7"`
+	HoldID   *int `json:"hold_id,omitempty"`
+	RepairID *int `json:"repair_id,omitempty"`
+	// MemberID is the borrower or the member the copy was trapped for;
+	// anonymized loans have none
+	MemberID string `json:"member_id,omitempty" example:"m-1001"`
+	// FromBranch and Branch are where a copy moved or was sent from and to,
+	// Branch the pickup branch of a hold or where the copy was acquired
+	FromBranch string `json:"from_branch,omitempty"`
+	Branch     string `json:"branch,omitempty" example:"Central"`
+	// DueAt and Renewals are those of a checkout's loan
+	DueAt    *time.Time `json:"due_at,omitempty"`
+	Renewals int        `json:"renewals,omitempty"`
+	// Amount is the fine of a return or the cost of a repair, in Currency
+	Amount   string `json:"amount,omitempty"`
+	Currency string `json:"currency,omitempty"`
+	// Vendor and Damage are those of a repair
+	Vendor string `json:"vendor,omitempty"`
+	Damage string `json:"damage,omitempty" example:"water damage to the last pages"`
+}
+
+// CopyHistory is a copy with everything recorded about it, oldest first
+type CopyHistory struct {
+	AcquisitionID int         `json:"acquisition_id" example:"3"`
+	BookID        int         `json:"book_id" example:"12"`
+	Title         string      `json:"title" example:"The Left Hand of Darkness"`
+	Barcode       string      `json:"barcode,omitempty" example:"31234000123456"`
+	Branch        string      `json:"branch" example:"Central"`
+	WithdrawnAt   *time.Time  `json:"withdrawn_at,omitempty"`
+	Events        []CopyEvent `json:"events"`
+}
+
+// Loan statuses, for listing a member's loans
+const (
+	LoansOut      = "out"
+	LoansReturned = "returned"
+)
+
+// overdueLoan is what bringing the fine of a loan up to date needs to know
+// about it
+type overdueLoan struct {
+	ID          int
+	DueAt       time.Time
+	PatronGroup string
+	Copy        copyInfo
+	Fine        string
+}
+
+// copyInfo is what checking out a copy needs to know about it
+type copyInfo struct {
+	ID         int
+	BookID     int
+	Title      string
+	Format     string
+	Collection string
+	Branch     string
+}
+
+// routing is where a checked-in copy goes next
+type routing struct {
+	Disposition string
+	Destination string
+	// HoldID is the hold the copy is trapped for; Shelved is set when the
+	// copy has just reached its hold shelf
+	HoldID  *int
+	Shelved bool
+	// Floated is set when the copy's home branch moved to the branch it was
+	// checked in at
+	Floated bool
+}
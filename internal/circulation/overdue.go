@@ -0,0 +1,85 @@
+package circulation
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// AccrueFines brings the fine of every loan out past due up to what
+// returning it now would cost, and returns how many fines changed. Loans
+// still within their grace period accrue nothing yet.
+func (s *Service) AccrueFines(ctx context.Context) (int, error) {
+	loans, err := s.repo.OpenFines(ctx)
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now()
+	changed := 0
+	for _, l := range loans {
+		fine, currency, err := s.fine(ctx, l.PatronGroup, &l.Copy, l.DueAt, now)
+		if err != nil {
+			return changed, err
+		}
+		if sameAmount(fine, l.Fine) {
+			continue
+		}
+		updated, err := s.repo.AccrueFine(ctx, l.ID, fine, currency)
+		if err != nil {
+			return changed, err
+		}
+		if updated {
+			changed++
+		}
+	}
+	return changed, nil
+}
+
+// sameAmount reports whether two decimal amounts, either possibly empty,
+// are equal
+func sameAmount(a, b string) bool {
+	x, okX := new(big.Rat).SetString(a)
+	y, okY := new(big.Rat).SetString(b)
+	if !okX || !okY {
+		return a == b
+	}
+	return x.Cmp(y) == 0
+}
+
+// StartOverdueJob accrues fines at start and every overdue_interval until
+// ctx is cancelled. A non-positive interval disables it.
+func (s *Service) StartOverdueJob(ctx context.Context, logger *zap.Logger) {
+	runEvery(ctx, s.cfg.OverdueInterval, func() {
+		changed, err := s.AccrueFines(ctx)
+		if err != nil {
+			logger.Error("Overdue fine accrual failed", zap.Error(err))
+			return
+		}
+		if changed > 0 {
+			logger.Info("Overdue fines accrued", zap.Int("count", changed))
+		}
+	})
+}
+
+// runEvery runs job at start and every interval until ctx is cancelled. A
+// non-positive interval doesn't run it at all.
+func runEvery(ctx context.Context, interval time.Duration, job func()) {
+	if interval <= 0 {
+		return
+	}
+	go func() {
+		job()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				job()
+			}
+		}
+	}()
+}
@@ -0,0 +1,1446 @@
+package circulation
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"public_library/internal/db"
+	"public_library/internal/policy"
+	"public_library/utils"
+	"time"
+)
+
+var (
+	// ErrMemberNotFound is returned when no member has the requested id
+	ErrMemberNotFound = errors.New("member not found")
+	// ErrLoanNotFound is returned when no loan has the requested id
+	ErrLoanNotFound = errors.New("loan not found")
+	// ErrHoldNotFound is returned when no hold has the requested id
+	ErrHoldNotFound = errors.New("hold not found")
+	// ErrCopyNotFound is returned when no acquisition has the copy's id
+	ErrCopyNotFound = errors.New("copy not found")
+	// ErrCardNotFound is returned when no member has the card number
+	ErrCardNotFound = errors.New("no member has this card number")
+	// ErrBarcodeNotFound is returned when no copy has the item barcode
+	ErrBarcodeNotFound = errors.New("no copy has this barcode")
+	// ErrBookNotFound is returned for holds on a book that doesn't exist
+	ErrBookNotFound = errors.New("book not found")
+	// ErrWithdrawn is returned for checking out a withdrawn copy
+	ErrWithdrawn = errors.New("copy has been withdrawn")
+	// ErrInRepair is returned for checking out a copy out for repair
+	ErrInRepair = errors.New("copy is out for repair")
+	// ErrOnLoan is returned for checking out a copy that is already out
+	ErrOnLoan = errors.New("copy is already on loan")
+	// ErrHeldForOther is returned for checking out a copy trapped for
+	// another member's hold
+	ErrHeldForOther = errors.New("copy is set aside for another member's hold")
+	// ErrLoanLimit is returned when a checkout would take a member over the
+	// loan limit of their patron group
+	ErrLoanLimit = errors.New("member has reached the loan limit")
+	// ErrFormatLimit is returned when a checkout would take a member over
+	// the loan policy's limit on loans of the copy's format
+	ErrFormatLimit = errors.New("member has reached the loan limit for this format")
+	// ErrHoldLimit is returned when a hold would take a member over the
+	// hold limit of their patron group
+	ErrHoldLimit = errors.New("member has reached the hold limit")
+	// ErrAlreadyHeld is returned for a second waiting hold on the same book
+	ErrAlreadyHeld = errors.New("member already has a hold on this book")
+	// ErrRenewalLimit is returned for renewing a loan with no renewals left
+	ErrRenewalLimit = errors.New("loan has no renewals left")
+	// ErrOnHold is returned for renewing a loan of a book other members
+	// are waiting for
+	ErrOnHold = errors.New("other members are waiting for this book")
+	// ErrReturned is returned for renewing or returning a returned loan
+	ErrReturned = errors.New("loan has already been returned")
+	// ErrHoldClosed is returned for cancelling a fulfilled or cancelled hold
+	ErrHoldClosed = errors.New("hold is no longer waiting")
+	// ErrSuspended is returned for checkouts, renewals and holds of a
+	// suspended member
+	ErrSuspended = errors.New("member is suspended")
+	// ErrExpired is returned for checkouts, renewals and holds of a member
+	// whose guest account has expired
+	ErrExpired = errors.New("member's account has expired")
+	// ErrLinkNotFound is returned when the members are not linked
+	ErrLinkNotFound = errors.New("member is not linked to this guardian")
+	// ErrNotPermitted is returned when a family link doesn't allow the
+	// guardian what they asked for
+	ErrNotPermitted = errors.New("the family link doesn't allow this")
+	// ErrClaimed is returned for claiming, or renewing, a loan already
+	// claimed returned
+	ErrClaimed = errors.New("loan is claimed returned")
+	// ErrNotClaimed is returned for resolving a claim on a loan nobody
+	// claimed returned
+	ErrNotClaimed = errors.New("loan is not claimed returned")
+	// ErrShelfCheckNotFound is returned when no shelf check has the
+	// requested id
+	ErrShelfCheckNotFound = errors.New("shelf check not found")
+	// ErrShelfChecked is returned for recording a shelf check twice
+	ErrShelfChecked = errors.New("shelf check has already been done")
+)
+
+type Repository struct {
+	db *db.DB
+}
+
+func NewRepository(conn *db.DB) *Repository {
+	return &Repository{db: conn}
+}
+
+const memberColumns = `id, COALESCE(card_number, ''), name, email, patron_group, auto_renew, anonymize_loans,
+	analytics_opt_out, suspension_reason, suspended_at, suspended_until, expires_at, created_at`
+
+// suspendedSQL is true for members ("m") with a suspension in force
+const suspendedSQL = `(m.suspended_at IS NOT NULL AND (m.suspended_until IS NULL OR m.suspended_until > now()))`
+
+// expiredSQL is true for members ("m") whose account has expired
+const expiredSQL = `(m.expires_at IS NOT NULL AND m.expires_at <= now())`
+
+// scanMember scans memberColumns, leaving out expired suspensions
+func scanMember(row interface{ Scan(...interface{}) error }) (Member, error) {
+	var m Member
+	var reason string
+	var suspendedAt, until *time.Time
+	err := row.Scan(&m.ID, &m.CardNumber, &m.Name, &m.Email, &m.PatronGroup, &m.AutoRenew, &m.AnonymizeLoans,
+		&m.AnalyticsOptOut, &reason, &suspendedAt, &until, &m.ExpiresAt, &m.CreatedAt)
+	if suspendedAt != nil && (until == nil || until.After(time.Now())) {
+		m.Suspension = &Suspension{Reason: reason, SuspendedAt: *suspendedAt, Until: until}
+	}
+	return m, err
+}
+
+func (r *Repository) CreateMember(ctx context.Context, m *Member) error {
+	log.Println("<--------Create member starts-------->")
+	defer log.Println("<--------Create member ends-------->")
+
+	query := fmt.Sprintf(`
+		INSERT INTO %s (id, card_number, name, email, patron_group, auto_renew, anonymize_loans, expires_at,
+			analytics_opt_out)
+		VALUES ($1, NULLIF($2, ''), $3, $4, $5, $6, $7, $8, $9)
+		RETURNING created_at
+	`, utils.MembersTable)
+	err := r.db.QueryRowContext(ctx, query, m.ID, m.CardNumber, m.Name, m.Email, m.PatronGroup, m.AutoRenew,
+		m.AnonymizeLoans, m.ExpiresAt, m.AnalyticsOptOut).Scan(&m.CreatedAt)
+	if err != nil {
+		log.Printf("Failed to create member id=%s: %v", m.ID, err)
+		return err
+	}
+	return nil
+}
+
+func (r *Repository) GetMember(ctx context.Context, id string) (*Member, error) {
+	query := fmt.Sprintf(`SELECT %s FROM %s WHERE id = $1`, memberColumns, utils.MembersTable)
+	m, err := scanMember(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrMemberNotFound
+		}
+		log.Printf("Failed to get member id=%s: %v", id, err)
+		return nil, err
+	}
+	return &m, nil
+}
+
+// GetMemberByCard returns the member with the library card number
+func (r *Repository) GetMemberByCard(ctx context.Context, cardNumber string) (*Member, error) {
+	query := fmt.Sprintf(`SELECT %s FROM %s WHERE card_number = $1`, memberColumns, utils.MembersTable)
+	m, err := scanMember(r.db.QueryRowContext(ctx, query, cardNumber))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrCardNotFound
+		}
+		log.Printf("Failed to get the member with card %s: %v", cardNumber, err)
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (r *Repository) UpdateMember(ctx context.Context, m *Member) error {
+	log.Println("<--------Update member starts-------->")
+	defer log.Println("<--------Update member ends-------->")
+
+	query := fmt.Sprintf(`
+		UPDATE %s SET card_number = NULLIF($2, ''), name = $3, email = $4, patron_group = $5, auto_renew = $6,
+			anonymize_loans = $7, expires_at = $8, analytics_opt_out = $9
+		WHERE id = $1
+		RETURNING %s
+	`, utils.MembersTable, memberColumns)
+	updated, err := scanMember(r.db.QueryRowContext(ctx, query, m.ID, m.CardNumber, m.Name, m.Email, m.PatronGroup,
+		m.AutoRenew, m.AnonymizeLoans, m.ExpiresAt, m.AnalyticsOptOut))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrMemberNotFound
+		}
+		log.Printf("Failed to update member id=%s: %v", m.ID, err)
+		return err
+	}
+	*m = updated
+	return nil
+}
+
+// Suspend suspends the member with sp's reason until sp.Until, replacing
+// any suspension in force
+func (r *Repository) Suspend(ctx context.Context, id string, sp *Suspension) (*Member, error) {
+	log.Println("<--------Suspend member starts-------->")
+	defer log.Println("<--------Suspend member ends-------->")
+
+	query := fmt.Sprintf(`
+		UPDATE %s SET suspension_reason = $2, suspended_at = now(), suspended_until = $3
+		WHERE id = $1
+		RETURNING %s
+	`, utils.MembersTable, memberColumns)
+	m, err := scanMember(r.db.QueryRowContext(ctx, query, id, sp.Reason, sp.Until))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrMemberNotFound
+		}
+		log.Printf("Failed to suspend member id=%s: %v", id, err)
+		return nil, err
+	}
+	return &m, nil
+}
+
+// Unsuspend lifts the member's suspension, if any
+func (r *Repository) Unsuspend(ctx context.Context, id string) (*Member, error) {
+	log.Println("<--------Unsuspend member starts-------->")
+	defer log.Println("<--------Unsuspend member ends-------->")
+
+	query := fmt.Sprintf(`
+		UPDATE %s SET suspension_reason = '', suspended_at = NULL, suspended_until = NULL
+		WHERE id = $1
+		RETURNING %s
+	`, utils.MembersTable, memberColumns)
+	m, err := scanMember(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrMemberNotFound
+		}
+		log.Printf("Failed to lift the suspension of member id=%s: %v", id, err)
+		return nil, err
+	}
+	return &m, nil
+}
+
+// Status counts the member's loans out, overdue loans and waiting holds and
+// totals their fines and payments. It doesn't check that the member exists.
+func (r *Repository) Status(ctx context.Context, memberID string) (*Status, error) {
+	st := &Status{MemberID: memberID}
+	query := fmt.Sprintf(`
+		SELECT
+			(SELECT COUNT(*) FROM %[1]s WHERE member_id = $1 AND returned_at IS NULL),
+			(SELECT COUNT(*) FROM %[1]s
+				WHERE member_id = $1 AND returned_at IS NULL AND claimed_returned_at IS NULL AND due_at < now()),
+			(SELECT COUNT(*) FROM %[1]s WHERE member_id = $1 AND returned_at IS NULL AND claimed_returned_at IS NOT NULL),
+			(SELECT COUNT(*) FROM %[2]s WHERE member_id = $1 AND status = 'waiting'),
+			(SELECT COALESCE(SUM(COALESCE(fine, 0) + COALESCE(replacement_charge, 0)), 0)::text FROM %[1]s
+				WHERE member_id = $1 AND (returned_at IS NOT NULL OR claimed_returned_at IS NULL)),
+			(SELECT COALESCE(SUM(amount), 0)::text FROM %[3]s WHERE member_id = $1)
+	`, utils.LoansTable, utils.HoldsTable, utils.PaymentsTable)
+	err := r.db.QueryRowContext(ctx, query, memberID).Scan(&st.LoansOut, &st.Overdue, &st.ClaimsReturned,
+		&st.HoldsWaiting, &st.Fines, &st.Paid)
+	if err != nil {
+		log.Printf("Failed to get the status of member id=%s: %v", memberID, err)
+		return nil, err
+	}
+	return st, nil
+}
+
+// GetCopy returns what checking out the copy needs to know about it
+func (r *Repository) GetCopy(ctx context.Context, acquisitionID int) (*copyInfo, error) {
+	return r.getCopy(ctx, `a.id = $1`, acquisitionID)
+}
+
+// GetCopyByBarcode returns what checking out the copy with the item barcode
+// needs to know about it
+func (r *Repository) GetCopyByBarcode(ctx context.Context, barcode string) (*copyInfo, error) {
+	c, err := r.getCopy(ctx, `a.barcode = $1`, barcode)
+	if errors.Is(err, ErrCopyNotFound) {
+		return nil, ErrBarcodeNotFound
+	}
+	return c, err
+}
+
+func (r *Repository) getCopy(ctx context.Context, where string, arg interface{}) (*copyInfo, error) {
+	var c copyInfo
+	query := fmt.Sprintf(`
+		SELECT a.id, a.book_id, b.title, b.format, b.collection, a.branch
+		FROM %s a JOIN %s b ON b.id = a.book_id
+		WHERE %s
+	`, utils.AcquisitionsTable, utils.BooksTable, where)
+	err := r.db.QueryRowContext(ctx, query, arg).Scan(&c.ID, &c.BookID, &c.Title, &c.Format, &c.Collection, &c.Branch)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrCopyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// selectLoansSQL joins loans ("l") to their copy and its book
+const selectLoansSQL = `
+	SELECT l.id, COALESCE(l.member_id, ''), l.acquisition_id, a.book_id, b.title, l.course_reserve_id, l.checked_out_at,
+		l.due_at, l.renewals, l.max_renewals, l.returned_at IS NULL AND l.claimed_returned_at IS NULL AND l.due_at < now(),
+		l.returned_at, COALESCE(l.fine::text, ''), l.currency, l.claimed_returned_at, COALESCE(l.claim_outcome, ''),
+		COALESCE(l.replacement_charge::text, '')
+	FROM %[1]s l
+	JOIN %[2]s a ON a.id = l.acquisition_id
+	JOIN %[3]s b ON b.id = a.book_id
+`
+
+func selectLoans(where string) string {
+	return fmt.Sprintf(selectLoansSQL, utils.LoansTable, utils.AcquisitionsTable, utils.BooksTable) + where
+}
+
+func scanLoan(row interface{ Scan(...interface{}) error }) (Loan, error) {
+	var l Loan
+	err := row.Scan(&l.ID, &l.MemberID, &l.AcquisitionID, &l.BookID, &l.Title, &l.CourseReserveID, &l.CheckedOutAt,
+		&l.DueAt, &l.Renewals, &l.MaxRenewals, &l.Overdue, &l.ReturnedAt, &l.Fine, &l.Currency, &l.ClaimedReturnedAt,
+		&l.ClaimOutcome, &l.ReplacementCharge)
+	return l, err
+}
+
+// Checkout lends the copy of l to its member, filling in l from the stored
+// row, counts the checkout on the copy and fulfills the member's hold on the
+// book. The member and the copy are locked while the loan limits and the
+// copy are checked, so neither can be lent past its limit concurrently.
+// Every reason the checkout is refused for is returned, joined.
+func (r *Repository) Checkout(ctx context.Context, l *Loan, loanLimit int, formatLimit *policy.FormatLimit) error {
+	log.Println("<--------Checkout starts-------->")
+	defer log.Println("<--------Checkout ends-------->")
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var formats []string
+	if formatLimit != nil {
+		formats = formatLimit.Formats
+	}
+	var suspended, expired bool
+	var loansOut, formatLoansOut int
+	lockMember := fmt.Sprintf(`
+		SELECT %[5]s, %[6]s,
+			(SELECT COUNT(*) FROM %[1]s l WHERE l.member_id = m.id AND l.returned_at IS NULL),
+			(SELECT COUNT(*) FROM %[1]s l
+				JOIN %[2]s a ON a.id = l.acquisition_id
+				JOIN %[3]s b ON b.id = a.book_id
+				WHERE l.member_id = m.id AND l.returned_at IS NULL AND b.format = ANY($2))
+		FROM %[4]s m WHERE m.id = $1
+		FOR UPDATE OF m
+	`, utils.LoansTable, utils.AcquisitionsTable, utils.BooksTable, utils.MembersTable, suspendedSQL, expiredSQL)
+	var denied []error
+	err = tx.QueryRowContext(ctx, lockMember, l.MemberID, formats).Scan(&suspended, &expired, &loansOut, &formatLoansOut)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		denied = append(denied, ErrMemberNotFound)
+	case err != nil:
+		return err
+	default:
+		if suspended {
+			denied = append(denied, ErrSuspended)
+		}
+		if expired {
+			denied = append(denied, ErrExpired)
+		}
+		if loansOut >= loanLimit {
+			denied = append(denied, ErrLoanLimit)
+		}
+		if formatLimit != nil && formatLoansOut >= formatLimit.Limit {
+			denied = append(denied, ErrFormatLimit)
+		}
+	}
+
+	var bookID int
+	var withdrawn, inRepair, onLoan, heldForOther bool
+	lockCopy := fmt.Sprintf(`
+		SELECT a.book_id, a.withdrawn_at IS NOT NULL,
+			EXISTS (SELECT 1 FROM %s r WHERE r.acquisition_id = a.id AND r.returned_on IS NULL),
+			EXISTS (SELECT 1 FROM %s l WHERE l.acquisition_id = a.id AND l.returned_at IS NULL),
+			EXISTS (SELECT 1 FROM %s h WHERE h.acquisition_id = a.id AND h.status = 'waiting' AND h.member_id <> $2)
+		FROM %s a WHERE a.id = $1
+		FOR UPDATE
+	`, utils.RepairsTable, utils.LoansTable, utils.HoldsTable, utils.AcquisitionsTable)
+	err = tx.QueryRowContext(ctx, lockCopy, l.AcquisitionID, l.MemberID).Scan(&bookID, &withdrawn, &inRepair, &onLoan, &heldForOther)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		denied = append(denied, ErrCopyNotFound)
+	case err != nil:
+		return err
+	default:
+		if withdrawn {
+			denied = append(denied, ErrWithdrawn)
+		}
+		if inRepair {
+			denied = append(denied, ErrInRepair)
+		}
+		if onLoan {
+			denied = append(denied, ErrOnLoan)
+		}
+		if heldForOther {
+			denied = append(denied, ErrHeldForOther)
+		}
+	}
+	if len(denied) > 0 {
+		return errors.Join(denied...)
+	}
+
+	var id int
+	insert := fmt.Sprintf(`
+		INSERT INTO %s (member_id, acquisition_id, course_reserve_id, due_at, max_renewals)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`, utils.LoansTable)
+	err = tx.QueryRowContext(ctx, insert, l.MemberID, l.AcquisitionID, l.CourseReserveID, l.DueAt, l.MaxRenewals).Scan(&id)
+	if err != nil {
+		log.Printf("Failed to check out acquisition id=%d to member id=%s: %v", l.AcquisitionID, l.MemberID, err)
+		return err
+	}
+	count := fmt.Sprintf(`
+		UPDATE %s SET checkouts = checkouts + 1, last_checkout_on = GREATEST(last_checkout_on, CURRENT_DATE)
+		WHERE id = $1
+	`, utils.AcquisitionsTable)
+	if _, err := tx.ExecContext(ctx, count, l.AcquisitionID); err != nil {
+		return err
+	}
+	fulfill := fmt.Sprintf(`
+		UPDATE %s SET status = 'fulfilled', closed_at = now()
+		WHERE member_id = $1 AND book_id = $2 AND status = 'waiting'
+	`, utils.HoldsTable)
+	if _, err := tx.ExecContext(ctx, fulfill, l.MemberID, bookID); err != nil {
+		return err
+	}
+
+	stored, err := scanLoan(tx.QueryRowContext(ctx, selectLoans(`WHERE l.id = $1`), id))
+	if err != nil {
+		return err
+	}
+	*l = stored
+	return tx.Commit()
+}
+
+func (r *Repository) GetLoan(ctx context.Context, id int) (*Loan, error) {
+	l, err := scanLoan(r.db.QueryRowContext(ctx, selectLoans(`WHERE l.id = $1`), id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrLoanNotFound
+		}
+		log.Printf("Failed to get loan id=%d: %v", id, err)
+		return nil, err
+	}
+	return &l, nil
+}
+
+// ListLoans returns the member's loans with status ("" for all), the
+// soonest due first and then the most recently returned
+func (r *Repository) ListLoans(ctx context.Context, memberID, status string) ([]Loan, error) {
+	query := selectLoans(`
+		WHERE l.member_id = $1 AND ($2 = '' OR ($2 = 'out') = (l.returned_at IS NULL))
+		ORDER BY l.returned_at DESC NULLS FIRST, l.due_at, l.id
+	`)
+	rows, err := r.db.QueryContext(ctx, query, memberID, status)
+	if err != nil {
+		log.Printf("Failed to list loans of member id=%s: %v", memberID, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []Loan{}
+	for rows.Next() {
+		l, err := scanLoan(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, l)
+	}
+	return list, rows.Err()
+}
+
+// Renew moves the due date of the loan to dueAt and counts the renewal,
+// allowing maxRenewals in all. Loans with no renewals left or of a book
+// another member is waiting for are not renewed.
+func (r *Repository) Renew(ctx context.Context, id int, dueAt time.Time, maxRenewals int) (*Loan, error) {
+	log.Println("<--------Renew loan starts-------->")
+	defer log.Println("<--------Renew loan ends-------->")
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var returned, exhausted, held bool
+	check := fmt.Sprintf(`
+		SELECT l.returned_at IS NOT NULL, l.renewals >= $2,
+			EXISTS (
+				SELECT 1 FROM %s h JOIN %s a ON a.book_id = h.book_id
+				WHERE a.id = l.acquisition_id AND h.status = 'waiting' AND h.member_id <> l.member_id
+			)
+		FROM %s l WHERE l.id = $1
+		FOR UPDATE OF l
+	`, utils.HoldsTable, utils.AcquisitionsTable, utils.LoansTable)
+	err = tx.QueryRowContext(ctx, check, id, maxRenewals).Scan(&returned, &exhausted, &held)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, ErrLoanNotFound
+	case err != nil:
+		return nil, err
+	case returned:
+		return nil, ErrReturned
+	case exhausted:
+		return nil, ErrRenewalLimit
+	case held:
+		return nil, ErrOnHold
+	}
+
+	update := fmt.Sprintf(`
+		UPDATE %s SET due_at = $2, renewals = renewals + 1, max_renewals = $3 WHERE id = $1
+	`, utils.LoansTable)
+	if _, err := tx.ExecContext(ctx, update, id, dueAt, maxRenewals); err != nil {
+		log.Printf("Failed to renew loan id=%d: %v", id, err)
+		return nil, err
+	}
+	l, err := scanLoan(tx.QueryRowContext(ctx, selectLoans(`WHERE l.id = $1`), id))
+	if err != nil {
+		return nil, err
+	}
+	return &l, tx.Commit()
+}
+
+// Return records the copy of the loan back at returnedAt with the fine
+// charged for it, if any, unlinking it from its member if anonymize is set
+func (r *Repository) Return(ctx context.Context, id int, returnedAt time.Time, fine, currency string,
+	anonymize bool) (*Loan, error) {
+	log.Println("<--------Return loan starts-------->")
+	defer log.Println("<--------Return loan ends-------->")
+
+	query := fmt.Sprintf(`
+		UPDATE %s SET returned_at = $2, fine = NULLIF($3, '')::numeric, currency = $4,
+			member_id = CASE WHEN $5 THEN NULL ELSE member_id END,
+			claim_outcome = CASE WHEN claimed_returned_at IS NOT NULL THEN 'found' END
+		WHERE id = $1 AND returned_at IS NULL
+	`, utils.LoansTable)
+	result, err := r.db.ExecContext(ctx, query, id, returnedAt, fine, currency, anonymize)
+	if err != nil {
+		log.Printf("Failed to return loan id=%d: %v", id, err)
+		return nil, err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	l, err := r.GetLoan(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if n == 0 {
+		return nil, ErrReturned
+	}
+	return l, nil
+}
+
+// OpenFines returns the loans out past due and those out with a fine, which
+// a renewal may have brought back within their loan period. Loans claimed
+// returned keep the fine they had when claimed.
+func (r *Repository) OpenFines(ctx context.Context) ([]overdueLoan, error) {
+	query := fmt.Sprintf(`
+		SELECT l.id, l.due_at, m.patron_group, a.id, a.book_id, b.format, a.branch, COALESCE(l.fine::text, '')
+		FROM %s l
+		JOIN %s m ON m.id = l.member_id
+		JOIN %s a ON a.id = l.acquisition_id
+		JOIN %s b ON b.id = a.book_id
+		WHERE l.returned_at IS NULL AND l.claimed_returned_at IS NULL AND (l.due_at < now() OR l.fine IS NOT NULL)
+		ORDER BY l.id
+	`, utils.LoansTable, utils.MembersTable, utils.AcquisitionsTable, utils.BooksTable)
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		log.Printf("Failed to list overdue loans: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []overdueLoan
+	for rows.Next() {
+		var l overdueLoan
+		if err := rows.Scan(&l.ID, &l.DueAt, &l.PatronGroup, &l.Copy.ID, &l.Copy.BookID, &l.Copy.Format,
+			&l.Copy.Branch, &l.Fine); err != nil {
+			return nil, err
+		}
+		list = append(list, l)
+	}
+	return list, rows.Err()
+}
+
+// AccrueFine sets the fine accrued so far on a loan still out and not
+// claimed returned. It reports whether the loan was.
+func (r *Repository) AccrueFine(ctx context.Context, id int, fine, currency string) (bool, error) {
+	query := fmt.Sprintf(`
+		UPDATE %s SET fine = NULLIF($2, '')::numeric, currency = $3
+		WHERE id = $1 AND returned_at IS NULL AND claimed_returned_at IS NULL
+	`, utils.LoansTable)
+	result, err := r.db.ExecContext(ctx, query, id, fine, currency)
+	if err != nil {
+		log.Printf("Failed to accrue the fine of loan id=%d: %v", id, err)
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+// AutoRenewable returns the IDs of the loans out that fall due before
+// dueBefore, belong to members who opted in to automatic renewal and have
+// renewals left, soonest due first. Renewing still checks for holds.
+func (r *Repository) AutoRenewable(ctx context.Context, dueBefore time.Time) ([]int, error) {
+	query := fmt.Sprintf(`
+		SELECT l.id
+		FROM %s l JOIN %s m ON m.id = l.member_id
+		WHERE l.returned_at IS NULL AND l.claimed_returned_at IS NULL AND m.auto_renew AND l.due_at > now()
+			AND l.due_at <= $1 AND l.renewals < l.max_renewals
+		ORDER BY l.due_at, l.id
+	`, utils.LoansTable, utils.MembersTable)
+	rows, err := r.db.QueryContext(ctx, query, dueBefore)
+	if err != nil {
+		log.Printf("Failed to list auto-renewable loans: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// AnonymizeLoans unlinks the member's returned loans that charged nothing,
+// neither a fine nor a replacement charge, from them and returns how many it
+// unlinked
+func (r *Repository) AnonymizeLoans(ctx context.Context, memberID string) (int, error) {
+	log.Println("<--------Anonymize loans starts-------->")
+	defer log.Println("<--------Anonymize loans ends-------->")
+
+	query := fmt.Sprintf(`
+		UPDATE %s SET member_id = NULL
+		WHERE member_id = $1 AND returned_at IS NOT NULL AND fine IS NULL AND replacement_charge IS NULL
+	`, utils.LoansTable)
+	result, err := r.db.ExecContext(ctx, query, memberID)
+	if err != nil {
+		log.Printf("Failed to anonymize the loans of member id=%s: %v", memberID, err)
+		return 0, err
+	}
+	n, err := result.RowsAffected()
+	return int(n), err
+}
+
+// selectHoldsSQL joins holds ("h") to their book
+const selectHoldsSQL = `
+	SELECT h.id, h.member_id, h.book_id, b.title, h.status, h.placed_at, h.closed_at, h.pickup_branch,
+		h.acquisition_id, h.shelved_at
+	FROM %[1]s h
+	JOIN %[2]s b ON b.id = h.book_id
+`
+
+func selectHolds(where string) string {
+	return fmt.Sprintf(selectHoldsSQL, utils.HoldsTable, utils.BooksTable) + where
+}
+
+func scanHold(row interface{ Scan(...interface{}) error }) (Hold, error) {
+	var h Hold
+	err := row.Scan(&h.ID, &h.MemberID, &h.BookID, &h.Title, &h.Status, &h.PlacedAt, &h.ClosedAt, &h.PickupBranch,
+		&h.AcquisitionID, &h.ShelvedAt)
+	return h, err
+}
+
+// PlaceHold stores h as waiting, filling it in from the stored row. The
+// member is locked while their holds are counted against holdLimit.
+func (r *Repository) PlaceHold(ctx context.Context, h *Hold, holdLimit int) error {
+	log.Println("<--------Place hold starts-------->")
+	defer log.Println("<--------Place hold ends-------->")
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var waiting int
+	var suspended, expired, held, bookExists bool
+	lock := fmt.Sprintf(`
+		SELECT %[4]s, %[5]s,
+			(SELECT COUNT(*) FROM %[1]s h WHERE h.member_id = m.id AND h.status = 'waiting'),
+			EXISTS (SELECT 1 FROM %[1]s h WHERE h.member_id = m.id AND h.book_id = $2 AND h.status = 'waiting'),
+			EXISTS (SELECT 1 FROM %[2]s b WHERE b.id = $2)
+		FROM %[3]s m WHERE m.id = $1
+		FOR UPDATE
+	`, utils.HoldsTable, utils.BooksTable, utils.MembersTable, suspendedSQL, expiredSQL)
+	err = tx.QueryRowContext(ctx, lock, h.MemberID, h.BookID).Scan(&suspended, &expired, &waiting, &held, &bookExists)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return ErrMemberNotFound
+	case err != nil:
+		return err
+	case suspended:
+		return ErrSuspended
+	case expired:
+		return ErrExpired
+	case !bookExists:
+		return ErrBookNotFound
+	case held:
+		return ErrAlreadyHeld
+	case waiting >= holdLimit:
+		return ErrHoldLimit
+	}
+
+	var id int
+	insert := fmt.Sprintf(`INSERT INTO %s (member_id, book_id, pickup_branch) VALUES ($1, $2, $3) RETURNING id`, utils.HoldsTable)
+	if err := tx.QueryRowContext(ctx, insert, h.MemberID, h.BookID, h.PickupBranch).Scan(&id); err != nil {
+		log.Printf("Failed to place a hold on book id=%d for member id=%s: %v", h.BookID, h.MemberID, err)
+		return err
+	}
+	stored, err := scanHold(tx.QueryRowContext(ctx, selectHolds(`WHERE h.id = $1`), id))
+	if err != nil {
+		return err
+	}
+	*h = stored
+	return tx.Commit()
+}
+
+// ListHolds returns the member's holds, waiting holds first, most recent
+// first
+func (r *Repository) ListHolds(ctx context.Context, memberID string) ([]Hold, error) {
+	query := selectHolds(`WHERE h.member_id = $1 ORDER BY h.status <> 'waiting', h.placed_at DESC, h.id DESC`)
+	rows, err := r.db.QueryContext(ctx, query, memberID)
+	if err != nil {
+		log.Printf("Failed to list holds of member id=%s: %v", memberID, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []Hold{}
+	for rows.Next() {
+		h, err := scanHold(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, h)
+	}
+	return list, rows.Err()
+}
+
+// CancelHold cancels a waiting hold
+func (r *Repository) CancelHold(ctx context.Context, id int) (*Hold, error) {
+	log.Println("<--------Cancel hold starts-------->")
+	defer log.Println("<--------Cancel hold ends-------->")
+
+	query := fmt.Sprintf(`
+		UPDATE %s SET status = 'cancelled', closed_at = now()
+		WHERE id = $1 AND status = 'waiting'
+	`, utils.HoldsTable)
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		log.Printf("Failed to cancel hold id=%d: %v", id, err)
+		return nil, err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	h, err := scanHold(r.db.QueryRowContext(ctx, selectHolds(`WHERE h.id = $1`), id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrHoldNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if n == 0 {
+		return nil, ErrHoldClosed
+	}
+	return &h, nil
+}
+
+func (r *Repository) GetHold(ctx context.Context, id int) (*Hold, error) {
+	h, err := scanHold(r.db.QueryRowContext(ctx, selectHolds(`WHERE h.id = $1`), id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrHoldNotFound
+		}
+		log.Printf("Failed to get hold id=%d: %v", id, err)
+		return nil, err
+	}
+	return &h, nil
+}
+
+// OpenLoanID returns the id of the copy's loan out, or 0 if it is not out
+func (r *Repository) OpenLoanID(ctx context.Context, acquisitionID int) (int, error) {
+	var id int
+	query := fmt.Sprintf(`SELECT id FROM %s WHERE acquisition_id = $1 AND returned_at IS NULL`, utils.LoansTable)
+	err := r.db.QueryRowContext(ctx, query, acquisitionID).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	return id, err
+}
+
+// Route decides where a copy checked in at branch goes next. A copy already
+// trapped for a hold goes to the hold's pickup branch; otherwise it is
+// trapped for the oldest waiting hold on its book without a copy, if any.
+// A copy reaching its pickup branch goes on the hold shelf, and one without
+// a hold goes back to its home branch, unless it floats: then branch becomes
+// its home branch. Withdrawn copies are not trapped.
+func (r *Repository) Route(ctx context.Context, acquisitionID int, branch string, floats bool) (*routing, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var bookID int
+	var home string
+	var withdrawn bool
+	lockCopy := fmt.Sprintf(`SELECT book_id, branch, withdrawn_at IS NOT NULL FROM %s WHERE id = $1 FOR UPDATE`,
+		utils.AcquisitionsTable)
+	err = tx.QueryRowContext(ctx, lockCopy, acquisitionID).Scan(&bookID, &home, &withdrawn)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrCopyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var holdID int
+	var pickup string
+	trapped := fmt.Sprintf(`
+		SELECT id, pickup_branch FROM %s
+		WHERE acquisition_id = $1 AND status = 'waiting'
+		FOR UPDATE
+	`, utils.HoldsTable)
+	err = tx.QueryRowContext(ctx, trapped, acquisitionID).Scan(&holdID, &pickup)
+	if errors.Is(err, sql.ErrNoRows) && !withdrawn {
+		trap := fmt.Sprintf(`
+			UPDATE %[1]s SET acquisition_id = $2, trapped_at = now(),
+				pickup_branch = CASE WHEN pickup_branch = '' THEN $3 ELSE pickup_branch END
+			WHERE id = (
+				SELECT id FROM %[1]s
+				WHERE book_id = $1 AND status = 'waiting' AND acquisition_id IS NULL
+				ORDER BY placed_at, id
+				LIMIT 1
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING id, pickup_branch
+		`, utils.HoldsTable)
+		err = tx.QueryRowContext(ctx, trap, bookID, acquisitionID, branch).Scan(&holdID, &pickup)
+	}
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		rt := &routing{Disposition: DispositionReshelve}
+		switch {
+		case home == "" || home == branch:
+		case floats:
+			rehome := fmt.Sprintf(`UPDATE %s SET branch = $2 WHERE id = $1`, utils.AcquisitionsTable)
+			if _, err := tx.ExecContext(ctx, rehome, acquisitionID, branch); err != nil {
+				log.Printf("Failed to float acquisition id=%d to %s: %v", acquisitionID, branch, err)
+				return nil, err
+			}
+			rt.Floated = true
+		default:
+			rt.Disposition, rt.Destination = DispositionTransit, home
+			if err := logTransit(ctx, tx, acquisitionID, branch, home); err != nil {
+				return nil, err
+			}
+		}
+		return rt, tx.Commit()
+	case err != nil:
+		log.Printf("Failed to route acquisition id=%d: %v", acquisitionID, err)
+		return nil, err
+	}
+
+	rt := &routing{HoldID: &holdID}
+	if pickup != branch {
+		rt.Disposition, rt.Destination = DispositionTransit, pickup
+		if err := logTransit(ctx, tx, acquisitionID, branch, pickup); err != nil {
+			return nil, err
+		}
+		return rt, tx.Commit()
+	}
+	rt.Disposition = DispositionHoldShelf
+	shelve := fmt.Sprintf(`UPDATE %s SET shelved_at = now() WHERE id = $1 AND shelved_at IS NULL`, utils.HoldsTable)
+	res, err := tx.ExecContext(ctx, shelve, holdID)
+	if err != nil {
+		return nil, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	rt.Shelved = n > 0
+	return rt, tx.Commit()
+}
+
+// logTransit records, for the copy's history, that it was sent from the
+// branch it was checked in at to another
+func logTransit(ctx context.Context, tx db.Queryer, acquisitionID int, from, to string) error {
+	query := fmt.Sprintf(`INSERT INTO %s (acquisition_id, kind, from_branch, to_branch) VALUES ($1, $2, $3, $4)`,
+		utils.CopyEventsTable)
+	if _, err := tx.ExecContext(ctx, query, acquisitionID, CopyInTransit, from, to); err != nil {
+		log.Printf("Failed to log the transit of acquisition id=%d to %s: %v", acquisitionID, to, err)
+		return err
+	}
+	return nil
+}
+
+// selectShelfSQL selects the waiting holds ("h") whose copy is on a hold
+// shelf, with their member, book and copy; $1 is the hold shelf period in
+// days
+const selectShelfSQL = `
+	SELECT h.id, h.member_id, m.name, COALESCE(m.card_number, ''), h.book_id, b.title, b.call_number,
+		h.acquisition_id, COALESCE(a.barcode, ''), h.pickup_branch, h.shelved_at,
+		h.shelved_at + make_interval(days => $1)
+	FROM %[1]s h
+	JOIN %[2]s m ON m.id = h.member_id
+	JOIN %[3]s b ON b.id = h.book_id
+	JOIN %[4]s a ON a.id = h.acquisition_id
+	WHERE h.status = 'waiting' AND h.shelved_at IS NOT NULL
+`
+
+func selectShelf(where string) string {
+	return fmt.Sprintf(selectShelfSQL, utils.HoldsTable, utils.MembersTable, utils.BooksTable,
+		utils.AcquisitionsTable) + where
+}
+
+func scanShelfItem(row interface{ Scan(...interface{}) error }) (ShelfItem, error) {
+	var it ShelfItem
+	err := row.Scan(&it.HoldID, &it.MemberID, &it.MemberName, &it.CardNumber, &it.BookID, &it.Title, &it.CallNumber,
+		&it.AcquisitionID, &it.Barcode, &it.PickupBranch, &it.ShelvedAt, &it.PickupBy)
+	it.Expired = time.Now().After(it.PickupBy)
+	return it, err
+}
+
+func queryShelf(ctx context.Context, q db.Queryer, query string, args ...interface{}) ([]ShelfItem, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []ShelfItem{}
+	for rows.Next() {
+		it, err := scanShelfItem(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, it)
+	}
+	return list, rows.Err()
+}
+
+// Availability returns the availability of the books with ids, in one
+// query, by id. Books that don't exist or whose audience is not in
+// audiences are left out; a nil audiences matches any.
+func (r *Repository) Availability(ctx context.Context, ids []int, audiences []string) ([]Availability, error) {
+	query := fmt.Sprintf(`
+		SELECT b.id,
+			count(c.id),
+			count(c.id) FILTER (WHERE c.state = 'available'),
+			count(c.id) FILTER (WHERE c.state = 'on_loan'),
+			(SELECT count(*) FROM %[4]s h WHERE h.book_id = b.id AND h.status = 'waiting' AND h.acquisition_id IS NULL),
+			min(c.due_at),
+			COALESCE(json_agg(DISTINCT c.branch ORDER BY c.branch) FILTER (WHERE c.state = 'available'), '[]')
+		FROM %[1]s b
+		LEFT JOIN LATERAL (
+			SELECT a.id, a.branch, l.due_at,
+				CASE
+					WHEN l.id IS NOT NULL THEN 'on_loan'
+					WHEN EXISTS (SELECT 1 FROM %[5]s rp WHERE rp.acquisition_id = a.id AND rp.returned_on IS NULL) THEN 'in_repair'
+					WHEN EXISTS (SELECT 1 FROM %[4]s h WHERE h.acquisition_id = a.id AND h.status = 'waiting') THEN 'held'
+					ELSE 'available'
+				END AS state
+			FROM %[2]s a
+			LEFT JOIN %[3]s l ON l.acquisition_id = a.id AND l.returned_at IS NULL
+			WHERE a.book_id = b.id AND a.withdrawn_at IS NULL
+		) c ON true
+		WHERE b.id = ANY($1::int[]) AND ($2::text[] IS NULL OR b.audience = ANY($2))
+		GROUP BY b.id
+		ORDER BY b.id
+	`, utils.BooksTable, utils.AcquisitionsTable, utils.LoansTable, utils.HoldsTable, utils.RepairsTable)
+	rows, err := r.db.QueryContext(ctx, query, ids, audiences)
+	if err != nil {
+		log.Printf("Failed to check the availability of %d books: %v", len(ids), err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []Availability
+	for rows.Next() {
+		var a Availability
+		var branches []byte
+		if err := rows.Scan(&a.BookID, &a.Copies, &a.Available, &a.OnLoan, &a.HoldsWaiting, &a.NextDueAt, &branches); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(branches, &a.Branches); err != nil {
+			return nil, err
+		}
+		list = append(list, a)
+	}
+	return list, rows.Err()
+}
+
+// copyEventsSQL selects the events of the copy $1 from its acquisition,
+// loans, holds, repairs and event log, oldest first. Dates are taken as the
+// start of their day.
+const copyEventsSQL = `
+	SELECT 'acquired', a.acquired_on::timestamptz, NULL::int, NULL::int, NULL::int, '', '', a.branch,
+		NULL::timestamptz, 0, '', '', '', ''
+	FROM %[1]s a WHERE a.id = $1
+	UNION ALL
+	SELECT 'checked_out', l.checked_out_at, l.id, NULL, NULL, COALESCE(l.member_id, ''), '', '',
+		l.due_at, l.renewals, '', '', '', ''
+	FROM %[2]s l WHERE l.acquisition_id = $1
+	UNION ALL
+	SELECT 'returned', l.returned_at, l.id, NULL, NULL, COALESCE(l.member_id, ''), '', '',
+		NULL, 0, COALESCE(l.fine::text, ''), l.currency, '', ''
+	FROM %[2]s l WHERE l.acquisition_id = $1 AND l.returned_at IS NOT NULL
+	UNION ALL
+	SELECT 'claimed_returned', l.claimed_returned_at, l.id, NULL, NULL, COALESCE(l.member_id, ''), '', '',
+		NULL, 0, '', '', '', ''
+	FROM %[2]s l WHERE l.acquisition_id = $1 AND l.claimed_returned_at IS NOT NULL
+	UNION ALL
+	SELECT 'trapped', h.trapped_at, NULL, h.id, NULL, h.member_id, '', h.pickup_branch,
+		NULL, 0, '', '', '', ''
+	FROM %[3]s h WHERE h.acquisition_id = $1 AND h.trapped_at IS NOT NULL
+	UNION ALL
+	SELECT 'shelved', h.shelved_at, NULL, h.id, NULL, h.member_id, '', h.pickup_branch,
+		NULL, 0, '', '', '', ''
+	FROM %[3]s h WHERE h.acquisition_id = $1 AND h.shelved_at IS NOT NULL
+	UNION ALL
+	SELECT 'sent_for_repair', rp.sent_on::timestamptz, NULL, NULL, rp.id, '', '', '',
+		NULL, 0, '', '', rp.vendor, rp.damage
+	FROM %[4]s rp WHERE rp.acquisition_id = $1
+	UNION ALL
+	SELECT 'back_from_repair', rp.returned_on::timestamptz, NULL, NULL, rp.id, '', '', '',
+		NULL, 0, COALESCE(rp.cost::text, ''), rp.currency, rp.vendor, ''
+	FROM %[4]s rp WHERE rp.acquisition_id = $1 AND rp.returned_on IS NOT NULL
+	UNION ALL
+	SELECT e.kind, e.created_at, NULL, NULL, NULL, '', e.from_branch, e.to_branch,
+		NULL, 0, '', '', '', ''
+	FROM %[5]s e WHERE e.acquisition_id = $1
+	ORDER BY 2
+`
+
+// CopyHistory returns the copy with its events, if its book is within the
+// audiences given (nil for any)
+func (r *Repository) CopyHistory(ctx context.Context, acquisitionID int, audiences []string) (*CopyHistory, error) {
+	h := CopyHistory{Events: []CopyEvent{}}
+	query := fmt.Sprintf(`
+		SELECT a.id, a.book_id, b.title, COALESCE(a.barcode, ''), a.branch, a.withdrawn_at
+		FROM %s a
+		JOIN %s b ON b.id = a.book_id
+		WHERE a.id = $1 AND ($2::text[] IS NULL OR b.audience = ANY($2))
+	`, utils.AcquisitionsTable, utils.BooksTable)
+	err := r.db.QueryRowContext(ctx, query, acquisitionID, audiences).
+		Scan(&h.AcquisitionID, &h.BookID, &h.Title, &h.Barcode, &h.Branch, &h.WithdrawnAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrCopyNotFound
+	}
+	if err != nil {
+		log.Printf("Failed to get acquisition id=%d: %v", acquisitionID, err)
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, fmt.Sprintf(copyEventsSQL, utils.AcquisitionsTable, utils.LoansTable,
+		utils.HoldsTable, utils.RepairsTable, utils.CopyEventsTable), acquisitionID)
+	if err != nil {
+		log.Printf("Failed to get the history of acquisition id=%d: %v", acquisitionID, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var e CopyEvent
+		if err := rows.Scan(&e.Type, &e.At, &e.LoanID, &e.HoldID, &e.RepairID, &e.MemberID, &e.FromBranch, &e.Branch,
+			&e.DueAt, &e.Renewals, &e.Amount, &e.Currency, &e.Vendor, &e.Damage); err != nil {
+			return nil, err
+		}
+		h.Events = append(h.Events, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return &h, nil
+}
+
+// ListShelf returns the copies on the branch's hold shelf shelved since the
+// given time, or all of them, by member name and title
+func (r *Repository) ListShelf(ctx context.Context, branch string, shelfDays int, since *time.Time) ([]ShelfItem, error) {
+	list, err := queryShelf(ctx, r.db, selectShelf(`
+		AND h.pickup_branch = $2 AND ($3::timestamptz IS NULL OR h.shelved_at >= $3)
+		ORDER BY m.name, b.title, h.id
+	`), shelfDays, branch, since)
+	if err != nil {
+		log.Printf("Failed to list hold shelf of %s: %v", branch, err)
+		return nil, err
+	}
+	return list, nil
+}
+
+// ExpireShelved expires the holds on the branch's hold shelf for longer
+// than shelfDays and returns them, releasing their copies
+func (r *Repository) ExpireShelved(ctx context.Context, branch string, shelfDays int) ([]ShelfItem, error) {
+	log.Println("<--------Expire shelved holds starts-------->")
+	defer log.Println("<--------Expire shelved holds ends-------->")
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	list, err := queryShelf(ctx, tx, selectShelf(`
+		AND h.pickup_branch = $2 AND h.shelved_at + make_interval(days => $1) < now()
+		ORDER BY m.name, b.title, h.id
+		FOR UPDATE OF h
+	`), shelfDays, branch)
+	if err != nil {
+		log.Printf("Failed to find expired holds of %s: %v", branch, err)
+		return nil, err
+	}
+	if len(list) == 0 {
+		return list, nil
+	}
+	ids := make([]int, len(list))
+	for i, it := range list {
+		ids[i] = it.HoldID
+	}
+	expire := fmt.Sprintf(`UPDATE %s SET status = 'expired', closed_at = now() WHERE id = ANY($1)`, utils.HoldsTable)
+	if _, err := tx.ExecContext(ctx, expire, ids); err != nil {
+		log.Printf("Failed to expire holds of %s: %v", branch, err)
+		return nil, err
+	}
+	return list, tx.Commit()
+}
+
+// selectLinksSQL joins family links ("k") to their guardian ("g") and
+// child ("c")
+const selectLinksSQL = `
+	SELECT k.guardian_id, g.name, k.child_id, c.name, k.view_loans, k.view_history, k.view_holds, k.manage,
+		k.created_at, k.updated_at
+	FROM %[1]s k
+	JOIN %[2]s g ON g.id = k.guardian_id
+	JOIN %[2]s c ON c.id = k.child_id
+`
+
+func selectLinks(where string) string {
+	return fmt.Sprintf(selectLinksSQL, utils.MemberLinksTable, utils.MembersTable) + where
+}
+
+func scanLink(row interface{ Scan(...interface{}) error }) (Link, error) {
+	var k Link
+	err := row.Scan(&k.GuardianID, &k.GuardianName, &k.ChildID, &k.ChildName, &k.ViewLoans, &k.ViewHistory,
+		&k.ViewHolds, &k.Manage, &k.CreatedAt, &k.UpdatedAt)
+	return k, err
+}
+
+// CreateLink links the child to the guardian, filling in k from the stored
+// row
+func (r *Repository) CreateLink(ctx context.Context, k *Link) error {
+	log.Println("<--------Create member link starts-------->")
+	defer log.Println("<--------Create member link ends-------->")
+
+	query := fmt.Sprintf(`
+		INSERT INTO %s (guardian_id, child_id, view_loans, view_history, view_holds, manage)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, utils.MemberLinksTable)
+	_, err := r.db.ExecContext(ctx, query, k.GuardianID, k.ChildID, k.ViewLoans, k.ViewHistory, k.ViewHolds, k.Manage)
+	if err != nil {
+		log.Printf("Failed to link member id=%s to guardian id=%s: %v", k.ChildID, k.GuardianID, err)
+		return err
+	}
+	stored, err := r.GetLink(ctx, k.GuardianID, k.ChildID)
+	if err != nil {
+		return err
+	}
+	*k = *stored
+	return nil
+}
+
+func (r *Repository) GetLink(ctx context.Context, guardianID, childID string) (*Link, error) {
+	k, err := scanLink(r.db.QueryRowContext(ctx, selectLinks(`WHERE k.guardian_id = $1 AND k.child_id = $2`),
+		guardianID, childID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrLinkNotFound
+		}
+		log.Printf("Failed to get the link of member id=%s to guardian id=%s: %v", childID, guardianID, err)
+		return nil, err
+	}
+	return &k, nil
+}
+
+// ListLinks returns the links of the member, as a guardian or as a child
+func (r *Repository) ListLinks(ctx context.Context, memberID string) ([]Link, error) {
+	query := selectLinks(`
+		WHERE k.guardian_id = $1 OR k.child_id = $1
+		ORDER BY k.guardian_id <> $1, c.name, g.name
+	`)
+	rows, err := r.db.QueryContext(ctx, query, memberID)
+	if err != nil {
+		log.Printf("Failed to list links of member id=%s: %v", memberID, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []Link{}
+	for rows.Next() {
+		k, err := scanLink(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, k)
+	}
+	return list, rows.Err()
+}
+
+// UpdateLink replaces the permissions of the link
+func (r *Repository) UpdateLink(ctx context.Context, guardianID, childID string, p LinkPermissions) (*Link, error) {
+	log.Println("<--------Update member link starts-------->")
+	defer log.Println("<--------Update member link ends-------->")
+
+	query := fmt.Sprintf(`
+		UPDATE %s SET view_loans = $3, view_history = $4, view_holds = $5, manage = $6, updated_at = now()
+		WHERE guardian_id = $1 AND child_id = $2
+	`, utils.MemberLinksTable)
+	res, err := r.db.ExecContext(ctx, query, guardianID, childID, p.ViewLoans, p.ViewHistory, p.ViewHolds, p.Manage)
+	if err != nil {
+		log.Printf("Failed to update the link of member id=%s to guardian id=%s: %v", childID, guardianID, err)
+		return nil, err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return nil, err
+	} else if n == 0 {
+		return nil, ErrLinkNotFound
+	}
+	return r.GetLink(ctx, guardianID, childID)
+}
+
+// DeleteGuardianLinks unlinks the child from all their guardians
+func (r *Repository) DeleteGuardianLinks(ctx context.Context, childID string) error {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE child_id = $1`, utils.MemberLinksTable)
+	if _, err := r.db.ExecContext(ctx, query, childID); err != nil {
+		log.Printf("Failed to unlink member id=%s from their guardians: %v", childID, err)
+		return err
+	}
+	return nil
+}
+
+func (r *Repository) DeleteLink(ctx context.Context, guardianID, childID string) error {
+	log.Println("<--------Delete member link starts-------->")
+	defer log.Println("<--------Delete member link ends-------->")
+
+	query := fmt.Sprintf(`DELETE FROM %s WHERE guardian_id = $1 AND child_id = $2`, utils.MemberLinksTable)
+	res, err := r.db.ExecContext(ctx, query, guardianID, childID)
+	if err != nil {
+		log.Printf("Failed to unlink member id=%s from guardian id=%s: %v", childID, guardianID, err)
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrLinkNotFound
+	}
+	return nil
+}
+
+// ClaimReturned records the member's claim to have returned the loan,
+// setting its fine to the one given, which no longer accrues, and asks the
+// copy's branch for a shelf check
+func (r *Repository) ClaimReturned(ctx context.Context, id int, fine, currency string) (*Loan, error) {
+	log.Println("<--------Claim returned starts-------->")
+	defer log.Println("<--------Claim returned ends-------->")
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var branch string
+	claim := fmt.Sprintf(`
+		UPDATE %s l SET claimed_returned_at = now(), fine = NULLIF($2, '')::numeric, currency = $3
+		FROM %s a
+		WHERE l.id = $1 AND a.id = l.acquisition_id AND l.returned_at IS NULL AND l.claimed_returned_at IS NULL
+		RETURNING a.branch
+	`, utils.LoansTable, utils.AcquisitionsTable)
+	err = tx.QueryRowContext(ctx, claim, id, fine, currency).Scan(&branch)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, r.unchangedLoanError(ctx, id, ErrClaimed)
+	}
+	if err != nil {
+		log.Printf("Failed to claim loan id=%d returned: %v", id, err)
+		return nil, err
+	}
+	check := fmt.Sprintf(`INSERT INTO %s (loan_id, branch) VALUES ($1, $2)`, utils.ShelfChecksTable)
+	if _, err := tx.ExecContext(ctx, check, id, branch); err != nil {
+		log.Printf("Failed to request a shelf check for loan id=%d: %v", id, err)
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return r.GetLoan(ctx, id)
+}
+
+// ResolveLost closes a loan claimed returned as lost, charging the member
+// charge on top of the fine, and withdraws its copy
+func (r *Repository) ResolveLost(ctx context.Context, id int, charge, currency string) (*Loan, error) {
+	log.Println("<--------Resolve lost claim starts-------->")
+	defer log.Println("<--------Resolve lost claim ends-------->")
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var acquisitionID int
+	resolve := fmt.Sprintf(`
+		UPDATE %s SET returned_at = now(), claim_outcome = 'lost', replacement_charge = $2::numeric,
+			currency = CASE WHEN currency = '' THEN $3 ELSE currency END
+		WHERE id = $1 AND returned_at IS NULL AND claimed_returned_at IS NOT NULL
+		RETURNING acquisition_id
+	`, utils.LoansTable)
+	err = tx.QueryRowContext(ctx, resolve, id, charge, currency).Scan(&acquisitionID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, r.unchangedLoanError(ctx, id, ErrNotClaimed)
+	}
+	if err != nil {
+		log.Printf("Failed to resolve the claim on loan id=%d as lost: %v", id, err)
+		return nil, err
+	}
+	withdraw := fmt.Sprintf(`UPDATE %s SET withdrawn_at = now() WHERE id = $1 AND withdrawn_at IS NULL`,
+		utils.AcquisitionsTable)
+	if _, err := tx.ExecContext(ctx, withdraw, acquisitionID); err != nil {
+		log.Printf("Failed to withdraw lost acquisition id=%d: %v", acquisitionID, err)
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return r.GetLoan(ctx, id)
+}
+
+// unchangedLoanError tells why an update found no loan id to change: it
+// doesn't exist or was returned, or else err
+func (r *Repository) unchangedLoanError(ctx context.Context, id int, err error) error {
+	l, getErr := r.GetLoan(ctx, id)
+	switch {
+	case getErr != nil:
+		return getErr
+	case l.ReturnedAt != nil:
+		return ErrReturned
+	}
+	return err
+}
+
+// CopyCost returns what replacing the copy costs, or the price paid for it
+// when no replacement cost is recorded, and the currency of either
+func (r *Repository) CopyCost(ctx context.Context, acquisitionID int) (string, string, error) {
+	var cost, currency string
+	query := fmt.Sprintf(`SELECT COALESCE(replacement_cost, price)::text, currency FROM %s WHERE id = $1`,
+		utils.AcquisitionsTable)
+	err := r.db.QueryRowContext(ctx, query, acquisitionID).Scan(&cost, &currency)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", "", ErrCopyNotFound
+	}
+	return cost, currency, err
+}
+
+// selectShelfChecksSQL joins shelf checks ("s") to their loan, its copy and
+// its book
+const selectShelfChecksSQL = `
+	SELECT s.id, s.loan_id, COALESCE(l.member_id, ''), l.acquisition_id, COALESCE(a.barcode, ''), b.title,
+		b.call_number, b.shelf_location, s.branch, l.claimed_returned_at, s.requested_at, s.checked_at, s.found, s.note
+	FROM %[1]s s
+	JOIN %[2]s l ON l.id = s.loan_id
+	JOIN %[3]s a ON a.id = l.acquisition_id
+	JOIN %[4]s b ON b.id = a.book_id
+`
+
+func selectShelfChecks(where string) string {
+	return fmt.Sprintf(selectShelfChecksSQL, utils.ShelfChecksTable, utils.LoansTable, utils.AcquisitionsTable,
+		utils.BooksTable) + where
+}
+
+func scanShelfCheck(row interface{ Scan(...interface{}) error }) (ShelfCheck, error) {
+	var sc ShelfCheck
+	err := row.Scan(&sc.ID, &sc.LoanID, &sc.MemberID, &sc.AcquisitionID, &sc.Barcode, &sc.Title, &sc.CallNumber,
+		&sc.ShelfLocation, &sc.Branch, &sc.ClaimedReturnedAt, &sc.RequestedAt, &sc.CheckedAt, &sc.Found, &sc.Note)
+	return sc, err
+}
+
+// ListShelfChecks returns the shelf checks the branch has still to do for
+// claims not resolved yet, in call number order
+func (r *Repository) ListShelfChecks(ctx context.Context, branch string) ([]ShelfCheck, error) {
+	rows, err := r.db.QueryContext(ctx, selectShelfChecks(`
+		WHERE s.branch = $1 AND s.checked_at IS NULL AND l.returned_at IS NULL
+		ORDER BY b.call_number_sort, s.id
+	`), branch)
+	if err != nil {
+		log.Printf("Failed to list shelf checks of %s: %v", branch, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []ShelfCheck{}
+	for rows.Next() {
+		sc, err := scanShelfCheck(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, sc)
+	}
+	return list, rows.Err()
+}
+
+func (r *Repository) GetShelfCheck(ctx context.Context, id int) (*ShelfCheck, error) {
+	sc, err := scanShelfCheck(r.db.QueryRowContext(ctx, selectShelfChecks(`WHERE s.id = $1`), id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrShelfCheckNotFound
+		}
+		log.Printf("Failed to get shelf check id=%d: %v", id, err)
+		return nil, err
+	}
+	return &sc, nil
+}
+
+// RecordShelfCheck records what a shelf check not done yet found
+func (r *Repository) RecordShelfCheck(ctx context.Context, id int, found bool, note string) (*ShelfCheck, error) {
+	query := fmt.Sprintf(`
+		UPDATE %s SET checked_at = now(), found = $2, note = $3
+		WHERE id = $1 AND checked_at IS NULL
+	`, utils.ShelfChecksTable)
+	result, err := r.db.ExecContext(ctx, query, id, found, note)
+	if err != nil {
+		log.Printf("Failed to record shelf check id=%d: %v", id, err)
+		return nil, err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	sc, err := r.GetShelfCheck(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if n == 0 {
+		return nil, ErrShelfChecked
+	}
+	return sc, nil
+}
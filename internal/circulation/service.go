@@ -0,0 +1,564 @@
+// Package circulation lends copies to members and takes holds on books. Each
+// member belongs to a patron group whose loan and hold limits, loan period
+// and fine rate are set in config and refined by the loan policy; copies on
+// course reserve lend on the reserve's terms instead.
+package circulation
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"public_library/internal/acquisition"
+	"public_library/internal/calendar"
+	"public_library/internal/config"
+	"public_library/internal/courses"
+	"public_library/internal/eventbus"
+	"public_library/internal/policy"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ErrInvalid is wrapped by errors about the content of a request
+var ErrInvalid = errors.New("invalid circulation request")
+
+const (
+	maxTextLength = 200
+	// maxCheckinBarcodes caps the copies of a batch check-in
+	maxCheckinBarcodes = 500
+)
+
+type Service struct {
+	repo     *Repository
+	courses  *courses.Service
+	policy   *policy.Engine
+	calendar *calendar.Service
+	cfg      config.CirculationConfig
+	bus      *eventbus.Bus
+}
+
+// NewService creates the circulation service. Loans are published on bus
+// under eventbus.LoanCheckedOut when checked out, eventbus.LoanAutoRenewed
+// when the auto-renew job renews them and eventbus.LoanReturned when
+// returned; holds under eventbus.HoldPlaced when placed and eventbus.HoldReady
+// when their copy reaches the hold shelf.
+func NewService(repo *Repository, reserves *courses.Service, engine *policy.Engine, closures *calendar.Service,
+	cfg config.CirculationConfig, bus *eventbus.Bus) *Service {
+	return &Service{repo: repo, courses: reserves, policy: engine, calendar: closures, cfg: cfg, bus: bus}
+}
+
+// Groups returns the lending policy of every patron group
+func (s *Service) Groups() []PatronGroup {
+	groups := make([]PatronGroup, 0, len(config.PatronGroups))
+	for _, name := range config.PatronGroups {
+		g := s.cfg.Groups[name]
+		groups = append(groups, PatronGroup{Name: name, LoanLimit: g.LoanLimit, LoanDays: g.LoanDays,
+			FinePerDay: g.FinePerDay, Currency: s.cfg.Currency, HoldLimit: g.HoldLimit, InLibrary: g.InLibrary})
+	}
+	return groups
+}
+
+// CreateMember validates m and registers it
+func (s *Service) CreateMember(ctx context.Context, m *Member) error {
+	m.ID = strings.TrimSpace(m.ID)
+	if m.ID == "" || utf8.RuneCountInString(m.ID) > maxTextLength {
+		return fmt.Errorf("%w: id is required and must be at most %d characters", ErrInvalid, maxTextLength)
+	}
+	if err := m.validate(); err != nil {
+		return err
+	}
+	return s.repo.CreateMember(ctx, m)
+}
+
+// CreateGuest registers a guest account for a visitor without a full
+// registration, in the guest patron group, expiring after req.Days or
+// guest_days. Guests get a generated id.
+func (s *Service) CreateGuest(ctx context.Context, req GuestRequest) (*Member, error) {
+	days := req.Days
+	if days == 0 {
+		days = s.cfg.GuestDays
+	}
+	if days < 1 || days > s.cfg.MaxGuestDays {
+		return nil, fmt.Errorf("%w: days must be between 1 and %d", ErrInvalid, s.cfg.MaxGuestDays)
+	}
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	expires := time.Now().AddDate(0, 0, days)
+	m := &Member{ID: "guest-" + hex.EncodeToString(b), Name: req.Name, Email: req.Email, CardNumber: req.CardNumber,
+		PatronGroup: config.GroupGuest, ExpiresAt: &expires}
+	if err := m.validate(); err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateMember(ctx, m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (s *Service) GetMember(ctx context.Context, id string) (*Member, error) {
+	return s.repo.GetMember(ctx, id)
+}
+
+// UpdateMember validates m and replaces the member with m.ID, e.g. to move
+// them to another patron group. A member moving out of the child group is
+// unlinked from their guardians.
+func (s *Service) UpdateMember(ctx context.Context, m *Member) error {
+	if err := m.validate(); err != nil {
+		return err
+	}
+	if err := s.repo.UpdateMember(ctx, m); err != nil {
+		return err
+	}
+	if m.PatronGroup != config.GroupChild {
+		return s.repo.DeleteGuardianLinks(ctx, m.ID)
+	}
+	return nil
+}
+
+// Suspend suspends a member for sp.Reason until sp.Until, or until the
+// suspension is lifted if sp.Until is nil
+func (s *Service) Suspend(ctx context.Context, memberID string, sp Suspension) (*Member, error) {
+	sp.Reason = strings.TrimSpace(sp.Reason)
+	if sp.Reason == "" || utf8.RuneCountInString(sp.Reason) > maxTextLength {
+		return nil, fmt.Errorf("%w: reason is required and must be at most %d characters", ErrInvalid, maxTextLength)
+	}
+	if sp.Until != nil && !sp.Until.After(time.Now()) {
+		return nil, fmt.Errorf("%w: until must be in the future", ErrInvalid)
+	}
+	return s.repo.Suspend(ctx, memberID, &sp)
+}
+
+// Unsuspend lifts a member's suspension
+func (s *Service) Unsuspend(ctx context.Context, memberID string) (*Member, error) {
+	return s.repo.Unsuspend(ctx, memberID)
+}
+
+// Status sums up the member's loans, holds and fines against the limits of
+// their patron group
+func (s *Service) Status(ctx context.Context, memberID string) (*Status, error) {
+	m, err := s.repo.GetMember(ctx, memberID)
+	if err != nil {
+		return nil, err
+	}
+	st, err := s.repo.Status(ctx, m.ID)
+	if err != nil {
+		return nil, err
+	}
+	terms, err := s.policy.Terms(m.PatronGroup, "")
+	if err != nil {
+		return nil, err
+	}
+	st.PatronGroup, st.LoanLimit, st.HoldLimit = m.PatronGroup, terms.LoanLimit, s.cfg.Groups[m.PatronGroup].HoldLimit
+	fines, okFines := new(big.Rat).SetString(st.Fines)
+	paid, okPaid := new(big.Rat).SetString(st.Paid)
+	if okFines && okPaid {
+		st.Balance = acquisition.FormatAmount(new(big.Rat).Sub(fines, paid), s.cfg.Currency)
+		st.Fines = acquisition.FormatAmount(fines, s.cfg.Currency)
+		st.Paid = acquisition.FormatAmount(paid, s.cfg.Currency)
+	}
+	st.Currency = s.cfg.Currency
+	st.Suspended, st.Suspension = m.Suspension != nil, m.Suspension
+	st.Expired, st.ExpiresAt = m.expired(), m.ExpiresAt
+	st.CanCheckout = !st.Suspended && !st.Expired && st.LoansOut < st.LoanLimit
+	st.CanHold = !st.Suspended && !st.Expired && st.HoldsWaiting < st.HoldLimit
+	return st, nil
+}
+
+// Checkout lends a copy to a member on the terms the loan policy sets for
+// their patron group and the copy's format, or on the terms of the course
+// reserve the copy is on. Loans due on a day the copy's branch is closed
+// are due the next day it is open.
+func (s *Service) Checkout(ctx context.Context, req CheckoutRequest) (*Loan, error) {
+	m, err := s.repo.GetMember(ctx, strings.TrimSpace(req.MemberID))
+	if err != nil {
+		return nil, err
+	}
+	c, err := s.repo.GetCopy(ctx, req.AcquisitionID)
+	if err != nil {
+		return nil, err
+	}
+	return s.checkout(ctx, m, c)
+}
+
+func (s *Service) checkout(ctx context.Context, m *Member, c *copyInfo) (*Loan, error) {
+	terms, period, reserveID, err := s.loanTerms(ctx, m, c)
+	if err != nil {
+		return nil, err
+	}
+	due, err := s.calendar.NextOpen(ctx, c.Branch, time.Now().Add(period))
+	if err != nil {
+		return nil, err
+	}
+	l := &Loan{MemberID: m.ID, AcquisitionID: c.ID, CourseReserveID: reserveID,
+		DueAt: due, MaxRenewals: terms.MaxRenewals}
+	if err := s.repo.Checkout(ctx, l, terms.LoanLimit, terms.FormatLimit); err != nil {
+		return nil, err
+	}
+	s.bus.Publish(ctx, eventbus.LoanCheckedOut, l.ID, *l)
+	return l, nil
+}
+
+// denialCodes names the errors a desk checkout can be denied with
+var denialCodes = []struct {
+	err  error
+	code string
+}{
+	{ErrCardNotFound, DenyCardNotFound},
+	{ErrMemberNotFound, DenyCardNotFound},
+	{ErrSuspended, DenyMemberSuspended},
+	{ErrExpired, DenyMemberExpired},
+	{ErrLoanLimit, DenyLoanLimit},
+	{ErrFormatLimit, DenyFormatLimit},
+	{ErrBarcodeNotFound, DenyItemNotFound},
+	{ErrCopyNotFound, DenyItemNotFound},
+	{ErrWithdrawn, DenyItemWithdrawn},
+	{ErrInRepair, DenyItemInRepair},
+	{ErrOnLoan, DenyItemOnLoan},
+	{ErrHeldForOther, DenyItemOnHold},
+}
+
+// denials returns the denials err is made of, as joined by errors.Join,
+// and false if any part of err is not a denial
+func denials(err error) ([]Denial, bool) {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		var list []Denial
+		for _, e := range joined.Unwrap() {
+			d, ok := denials(e)
+			if !ok {
+				return nil, false
+			}
+			list = append(list, d...)
+		}
+		return list, true
+	}
+	for _, dc := range denialCodes {
+		if errors.Is(err, dc.err) {
+			return []Denial{{Code: dc.code, Message: err.Error()}}, true
+		}
+	}
+	return nil, false
+}
+
+// DeskCheckout checks out the copy with the scanned item barcode to the
+// member with the scanned card, like Checkout. The member's standing and
+// limits and the copy's availability are checked together with the
+// checkout, and a denied checkout reports every reason it was denied for.
+func (s *Service) DeskCheckout(ctx context.Context, req DeskCheckoutRequest) (*DeskCheckout, error) {
+	card, barcode := strings.TrimSpace(req.CardNumber), strings.TrimSpace(req.Barcode)
+	if card == "" || barcode == "" {
+		return nil, fmt.Errorf("%w: card_number and barcode are required", ErrInvalid)
+	}
+	out := &DeskCheckout{}
+	var denied []error
+	m, err := s.repo.GetMemberByCard(ctx, card)
+	if errors.Is(err, ErrCardNotFound) {
+		denied = append(denied, err)
+	} else if err != nil {
+		return nil, err
+	}
+	out.Member = m
+	c, err := s.repo.GetCopyByBarcode(ctx, barcode)
+	if errors.Is(err, ErrBarcodeNotFound) {
+		denied = append(denied, err)
+	} else if err != nil {
+		return nil, err
+	}
+	if len(denied) == 0 {
+		l, err := s.checkout(ctx, m, c)
+		if err == nil {
+			out.Loan = l
+			return out, nil
+		}
+		denied = append(denied, err)
+	}
+	list, ok := denials(errors.Join(denied...))
+	if !ok {
+		return nil, errors.Join(denied...)
+	}
+	out.Denials = list
+	return out, nil
+}
+
+// loanTerms returns the policy terms a copy lends on to a member and how
+// long it lends for. Copies on course reserve lend for the reserve's loan
+// period and renew as often as it allows.
+func (s *Service) loanTerms(ctx context.Context, m *Member, c *copyInfo) (policy.Terms, time.Duration, *int, error) {
+	terms, err := s.policy.Terms(m.PatronGroup, c.Format)
+	if err != nil {
+		return terms, 0, nil, err
+	}
+	reserve, err := s.courses.ActiveReserve(ctx, c.ID)
+	if err != nil {
+		return terms, 0, nil, err
+	}
+	if reserve != nil {
+		terms.MaxRenewals = reserve.MaxRenewals
+		return terms, time.Duration(reserve.LoanHours) * time.Hour, &reserve.ID, nil
+	}
+	return terms, terms.LoanPeriod(time.Now()), nil, nil
+}
+
+func (s *Service) GetLoan(ctx context.Context, id int) (*Loan, error) {
+	return s.repo.GetLoan(ctx, id)
+}
+
+// ListLoans returns the member's loans, those out or returned or all
+func (s *Service) ListLoans(ctx context.Context, memberID, status string) ([]Loan, error) {
+	if status != "" && status != LoansOut && status != LoansReturned {
+		return nil, fmt.Errorf("%w: status must be %s or %s", ErrInvalid, LoansOut, LoansReturned)
+	}
+	if _, err := s.repo.GetMember(ctx, memberID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListLoans(ctx, memberID, status)
+}
+
+// Renew extends a loan by a new loan period from now, if the loan policy
+// in force now leaves it a renewal and the member isn't suspended
+func (s *Service) Renew(ctx context.Context, id int) (*Loan, error) {
+	l, err := s.repo.GetLoan(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if l.ReturnedAt != nil {
+		return nil, ErrReturned
+	}
+	if l.ClaimedReturnedAt != nil {
+		return nil, ErrClaimed
+	}
+	m, err := s.repo.GetMember(ctx, l.MemberID)
+	if err != nil {
+		return nil, err
+	}
+	if m.Suspension != nil {
+		return nil, ErrSuspended
+	}
+	if m.expired() {
+		return nil, ErrExpired
+	}
+	c, err := s.repo.GetCopy(ctx, l.AcquisitionID)
+	if err != nil {
+		return nil, err
+	}
+	terms, period, _, err := s.loanTerms(ctx, m, c)
+	if err != nil {
+		return nil, err
+	}
+	due, err := s.calendar.NextOpen(ctx, c.Branch, time.Now().Add(period))
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.Renew(ctx, id, due, terms.MaxRenewals)
+}
+
+// Return checks a loan in, charging the fine the loan policy sets for every
+// day it is late that its branch was open, unless it is back within the
+// grace period. Loans returned without a fine are anonymized if the library
+// or the member asks for it. A copy claimed returned that turns up at
+// check-in resolves the claim as found.
+func (s *Service) Return(ctx context.Context, id int) (*Loan, error) {
+	l, err := s.repo.GetLoan(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if l.ReturnedAt != nil {
+		return nil, ErrReturned
+	}
+	return s.returnLoan(ctx, l, time.Now())
+}
+
+// returnLoan returns l as of returnedAt. A loan claimed returned is fined
+// as of the claim, as the claim left its fine.
+func (s *Service) returnLoan(ctx context.Context, l *Loan, returnedAt time.Time) (*Loan, error) {
+	m, err := s.repo.GetMember(ctx, l.MemberID)
+	if err != nil {
+		return nil, err
+	}
+	c, err := s.repo.GetCopy(ctx, l.AcquisitionID)
+	if err != nil {
+		return nil, err
+	}
+	finedTo := returnedAt
+	if l.ClaimedReturnedAt != nil {
+		finedTo = *l.ClaimedReturnedAt
+	}
+	fine, currency, err := s.fine(ctx, m.PatronGroup, c, l.DueAt, finedTo)
+	if err != nil {
+		return nil, err
+	}
+	anonymize := (s.cfg.AnonymizeLoans || m.AnonymizeLoans) && fine == ""
+	returned, err := s.repo.Return(ctx, l.ID, returnedAt, fine, currency, anonymize)
+	if err != nil {
+		return nil, err
+	}
+	s.bus.Publish(ctx, eventbus.LoanReturned, returned.ID, *returned)
+	return returned, nil
+}
+
+// BatchCheckin checks in the copies a returns sorter read at a branch, in
+// order. The loan of each copy out is returned as by Return, and each copy
+// is then routed: trapped for the next hold on its book and put on the hold
+// shelf or sent to the hold's pickup branch, or else reshelved or sent home;
+// copies of a floating collection are rehomed to the branch instead.
+// Copies that can't be checked in report why without failing the batch.
+func (s *Service) BatchCheckin(ctx context.Context, req BatchCheckinRequest) (*BatchCheckin, error) {
+	branch := strings.TrimSpace(req.Branch)
+	if branch == "" || utf8.RuneCountInString(branch) > maxTextLength {
+		return nil, fmt.Errorf("%w: branch is required and must be at most %d characters", ErrInvalid, maxTextLength)
+	}
+	if len(req.Barcodes) == 0 || len(req.Barcodes) > maxCheckinBarcodes {
+		return nil, fmt.Errorf("%w: between 1 and %d barcodes are required", ErrInvalid, maxCheckinBarcodes)
+	}
+	out := &BatchCheckin{Branch: branch, Items: make([]CheckinItem, 0, len(req.Barcodes))}
+	for _, barcode := range req.Barcodes {
+		item, err := s.checkin(ctx, strings.TrimSpace(barcode), branch)
+		if err != nil {
+			if !errors.Is(err, ErrBarcodeNotFound) && !errors.Is(err, ErrCopyNotFound) && !errors.Is(err, ErrReturned) {
+				return nil, err
+			}
+			item.Error = err.Error()
+			out.Failed++
+		} else if item.LoanID != nil {
+			out.Returned++
+		}
+		out.Items = append(out.Items, item)
+	}
+	return out, nil
+}
+
+// checkin checks in and routes one copy of a batch
+func (s *Service) checkin(ctx context.Context, barcode, branch string) (CheckinItem, error) {
+	item := CheckinItem{Barcode: barcode}
+	c, err := s.repo.GetCopyByBarcode(ctx, barcode)
+	if err != nil {
+		return item, err
+	}
+	item.AcquisitionID, item.Title = c.ID, c.Title
+	loanID, err := s.repo.OpenLoanID(ctx, c.ID)
+	if err != nil {
+		return item, err
+	}
+	if loanID != 0 {
+		l, err := s.Return(ctx, loanID)
+		if err != nil {
+			return item, err
+		}
+		item.LoanID, item.Fine, item.Currency = &l.ID, l.Fine, l.Currency
+	}
+	rt, err := s.repo.Route(ctx, c.ID, branch, s.floats(c.Collection, branch))
+	if err != nil {
+		return item, err
+	}
+	item.Disposition, item.Destination, item.Floated, item.HoldID = rt.Disposition, rt.Destination, rt.Floated, rt.HoldID
+	if rt.Shelved {
+		h, err := s.repo.GetHold(ctx, *rt.HoldID)
+		if err != nil {
+			return item, err
+		}
+		s.bus.Publish(ctx, eventbus.HoldReady, h.ID, *h)
+	}
+	return item, nil
+}
+
+// floats reports whether copies of the collection returned at branch stay
+// there, under the first floating rule listing the collection
+func (s *Service) floats(collection, branch string) bool {
+	for _, rule := range s.cfg.Floating {
+		if slices.Contains(rule.Collections, collection) {
+			return len(rule.Branches) == 0 || slices.Contains(rule.Branches, branch)
+		}
+	}
+	return false
+}
+
+// AnonymizeLoans unlinks the member's returned loans that charged nothing
+// from them, e.g. after they opt in to anonymize_loans. Loans with a fine or
+// a replacement charge stay linked, paid or not, since the member's balance
+// sums their charges against the member's payments.
+func (s *Service) AnonymizeLoans(ctx context.Context, memberID string) (*Anonymized, error) {
+	m, err := s.repo.GetMember(ctx, memberID)
+	if err != nil {
+		return nil, err
+	}
+	n, err := s.repo.AnonymizeLoans(ctx, m.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &Anonymized{MemberID: m.ID, Loans: n}, nil
+}
+
+// fine returns what returning a copy due at due on returned costs a member
+// of group, and its currency, or two empty strings if nothing
+func (s *Service) fine(ctx context.Context, group string, c *copyInfo, due, returned time.Time) (string, string, error) {
+	terms, err := s.policy.Terms(group, c.Format)
+	if err != nil {
+		return "", "", err
+	}
+	closed, err := s.calendar.ClosedDays(ctx, c.Branch, due, returned)
+	if err != nil {
+		return "", "", err
+	}
+	fine := terms.Fine(due, returned, closed)
+	if fine == "" {
+		return "", "", nil
+	}
+	return fine, terms.Currency, nil
+}
+
+// PlaceHold puts a member in the queue for the next copy of a book, to pick
+// up at pickupBranch or, if empty, where the copy is checked in
+func (s *Service) PlaceHold(ctx context.Context, memberID string, bookID int, pickupBranch string) (*Hold, error) {
+	pickupBranch = strings.TrimSpace(pickupBranch)
+	if utf8.RuneCountInString(pickupBranch) > maxTextLength {
+		return nil, fmt.Errorf("%w: pickup_branch must be at most %d characters", ErrInvalid, maxTextLength)
+	}
+	m, err := s.repo.GetMember(ctx, memberID)
+	if err != nil {
+		return nil, err
+	}
+	h := &Hold{MemberID: m.ID, BookID: bookID, PickupBranch: pickupBranch}
+	if err := s.repo.PlaceHold(ctx, h, s.cfg.Groups[m.PatronGroup].HoldLimit); err != nil {
+		return nil, err
+	}
+	s.bus.Publish(ctx, eventbus.HoldPlaced, h.ID, *h)
+	return h, nil
+}
+
+func (s *Service) ListHolds(ctx context.Context, memberID string) ([]Hold, error) {
+	if _, err := s.repo.GetMember(ctx, memberID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListHolds(ctx, memberID)
+}
+
+func (s *Service) CancelHold(ctx context.Context, id int) (*Hold, error) {
+	return s.repo.CancelHold(ctx, id)
+}
+
+func (m *Member) validate() error {
+	m.Name = strings.TrimSpace(m.Name)
+	if m.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalid)
+	}
+	m.CardNumber = strings.TrimSpace(m.CardNumber)
+	m.Email = strings.TrimSpace(m.Email)
+	for field, value := range map[string]string{"name": m.Name, "card_number": m.CardNumber, "email": m.Email} {
+		if utf8.RuneCountInString(value) > maxTextLength {
+			return fmt.Errorf("%w: %s must be at most %d characters", ErrInvalid, field, maxTextLength)
+		}
+	}
+	m.PatronGroup = strings.ToLower(strings.TrimSpace(m.PatronGroup))
+	if m.PatronGroup == "" {
+		m.PatronGroup = config.GroupAdult
+	}
+	if !slices.Contains(config.PatronGroups, m.PatronGroup) {
+		return fmt.Errorf("%w: patron_group must be one of %s", ErrInvalid, strings.Join(config.PatronGroups, ", "))
+	}
+	return nil
+}
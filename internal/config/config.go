@@ -0,0 +1,837 @@
+// Package config loads and validates the application configuration. Every
+// other package receives its settings from here rather than reading files or
+// hardcoding defaults itself.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"public_library/internal/cache"
+	"regexp"
+	"slices"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+type AppConfig struct {
+	DB              DBConfig              `yaml:"db"`
+	Server          ServerConfig          `yaml:"server"`
+	Stats           StatsConfig           `yaml:"stats"`
+	Partitions      PartitionConfig       `yaml:"partitions"`
+	Cache           CacheConfig           `yaml:"cache"`
+	Formats         FormatsConfig         `yaml:"formats"`
+	Media           MediaConfig           `yaml:"media"`
+	Catalog         CatalogConfig         `yaml:"catalog"`
+	Features        FeaturesConfig        `yaml:"features"`
+	Health          HealthConfig          `yaml:"health"`
+	Maintenance     MaintenanceConfig     `yaml:"maintenance"`
+	Admin           AdminConfig           `yaml:"admin"`
+	RateLimit       RateLimitConfig       `yaml:"rate_limit"`
+	Usage           UsageConfig           `yaml:"usage"`
+	Analytics       AnalyticsConfig       `yaml:"analytics"`
+	Experiments     ExperimentsConfig     `yaml:"experiments"`
+	Recommendations RecommendationsConfig `yaml:"recommendations"`
+	Feed            FeedConfig            `yaml:"feed"`
+	Embeddings      EmbeddingsConfig      `yaml:"embeddings"`
+	Sandbox         SandboxConfig         `yaml:"sandbox"`
+	ILSSync         ILSSyncConfig         `yaml:"ils_sync"`
+	Acquisitions    AcquisitionsConfig    `yaml:"acquisitions"`
+	EDI             EDIConfig             `yaml:"edi"`
+	Serials         SerialsConfig         `yaml:"serials"`
+	Circulation     CirculationConfig     `yaml:"circulation"`
+	Policy          PolicyConfig          `yaml:"policy"`
+	SavedSearches   SavedSearchesConfig   `yaml:"saved_searches"`
+	Devices         DevicesConfig         `yaml:"devices"`
+}
+
+// DBConfig holds the PostgreSQL connection settings
+type DBConfig struct {
+	Host     string `yaml:"host"`
+	Port     string `yaml:"port"`
+	User     string `yaml:"user"`
+	Password string `yaml:"password"`
+	DBName   string `yaml:"dbname"`
+	SSLMode  string `yaml:"sslmode"`
+	// Schema holds the tables instead of public; it is created if missing
+	Schema             string        `yaml:"schema"`
+	Retry              RetryConfig   `yaml:"retry"`
+	SlowQueryThreshold time.Duration `yaml:"slow_query_threshold"`
+}
+
+// RetryConfig controls how transient database errors are retried
+type RetryConfig struct {
+	MaxAttempts int           `yaml:"max_attempts"`
+	BaseDelay   time.Duration `yaml:"base_delay"`
+	MaxDelay    time.Duration `yaml:"max_delay"`
+}
+
+// ServerConfig holds HTTP server configuration
+type ServerConfig struct {
+	Port     int            `yaml:"port"`
+	Host     string         `yaml:"host"`
+	Timeouts TimeoutsConfig `yaml:"timeouts"`
+}
+
+// TimeoutsConfig holds per-route-class request timeouts.
+// A zero value disables the timeout for that class of route.
+type TimeoutsConfig struct {
+	Read   time.Duration `yaml:"read"`
+	Write  time.Duration `yaml:"write"`
+	Import time.Duration `yaml:"import"`
+}
+
+// StatsConfig controls how often the statistics views are refreshed
+type StatsConfig struct {
+	RefreshInterval time.Duration `yaml:"refresh_interval"`
+}
+
+// PartitionConfig controls monthly partition maintenance for append-heavy tables
+type PartitionConfig struct {
+	// MonthsAhead is how many future monthly partitions are kept created
+	MonthsAhead int `yaml:"months_ahead"`
+	// RetentionMonths maps a partitioned table to how many months of history
+	// to keep; older partitions are dropped. Zero or missing keeps everything.
+	RetentionMonths map[string]int `yaml:"retention_months"`
+}
+
+// CacheConfig holds the TTL caches used by the repositories
+type CacheConfig struct {
+	BookList cache.Config `yaml:"book_list"`
+}
+
+// FormatsConfig overrides the default loan period (in days) per format code
+type FormatsConfig struct {
+	LoanDays map[string]int `yaml:"loan_days"`
+}
+
+// MediaConfig controls where audio and e-book assets are stored and how they
+// are streamed
+type MediaConfig struct {
+	StorageDir     string        `yaml:"storage_dir"`
+	MaxUploadBytes int64         `yaml:"max_upload_bytes"`
+	TokenSecret    string        `yaml:"token_secret"`
+	TokenTTL       time.Duration `yaml:"token_ttl"`
+	// MaxLoanPeriod bounds how far ahead a stream token's expires_at may be
+	MaxLoanPeriod time.Duration `yaml:"max_loan_period"`
+}
+
+// CatalogConfig controls how books are presented in catalog responses
+type CatalogConfig struct {
+	// DescriptionPreview is the number of characters of a description shown
+	// in list views; 0 shows the full description
+	DescriptionPreview int `yaml:"description_preview"`
+	// DefaultPageSize applies when a list request gives no page_size; larger
+	// page sizes are clamped to MaxPageSize
+	DefaultPageSize int `yaml:"default_page_size"`
+	MaxPageSize     int `yaml:"max_page_size"`
+	// SortLocale is the BCP 47 locale whose ICU collation sorts titles, such
+	// as en, fr or de-AT; und is the locale-neutral root collation. It must
+	// be one PostgreSQL has an ICU collation for.
+	SortLocale string `yaml:"sort_locale"`
+	// Suggestions is how many "did you mean" titles and authors a search
+	// that finds nothing returns; 0 turns them off
+	Suggestions int `yaml:"suggestions"`
+}
+
+// FeaturesConfig switches optional endpoints on or off
+type FeaturesConfig struct {
+	BulkImport bool `yaml:"bulk_import"`
+	Export     bool `yaml:"export"`
+}
+
+// HealthConfig controls the readiness checks behind GET /health
+type HealthConfig struct {
+	// Timeout bounds each check that does not set its own
+	Timeout time.Duration `yaml:"timeout"`
+	// CacheTTL is how long a check's result is reused before probing again
+	CacheTTL time.Duration      `yaml:"cache_ttl"`
+	Checks   []IntegrationCheck `yaml:"checks"`
+}
+
+// Types of integration check
+const (
+	CheckHTTP = "http" // HEAD request to a URL; any status below 500 is up
+	CheckTCP  = "tcp"  // TCP connect to host:port
+	CheckSMTP = "smtp" // SMTP greeting from host:port
+)
+
+// IntegrationCheck is one external dependency probed by GET /health, such
+// as an S3 bucket, an SMTP relay, the Stripe API or an Elasticsearch cluster
+type IntegrationCheck struct {
+	Name    string        `yaml:"name"`
+	Type    string        `yaml:"type"`
+	Target  string        `yaml:"target"`
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// MaintenanceConfig sets the maintenance mode the API starts in; it can be
+// switched at runtime through PUT /admin/maintenance
+type MaintenanceConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Message is returned to rejected writes; empty uses a generic notice
+	Message string `yaml:"message"`
+	// RetryAfter is sent as the Retry-After header; zero omits it
+	RetryAfter time.Duration `yaml:"retry_after"`
+}
+
+// AdminConfig protects the /admin endpoints
+type AdminConfig struct {
+	// Token is the shared bearer token for /admin; empty disables the admin API
+	Token string `yaml:"token"`
+}
+
+// RateLimitConfig is the limit of API keys without an override in
+// api_key_limits. Zero means unlimited.
+type RateLimitConfig struct {
+	RequestsPerMinute int `yaml:"requests_per_minute"`
+	// Burst is how many requests may arrive at once; 0 uses RequestsPerMinute
+	Burst      int `yaml:"burst"`
+	DailyQuota int `yaml:"daily_quota"`
+	// ReloadInterval is how often overrides are re-read from the database
+	// to pick up changes made through other instances
+	ReloadInterval time.Duration `yaml:"reload_interval"`
+}
+
+// UsageConfig controls per-API-key usage analytics
+type UsageConfig struct {
+	// FlushInterval is how often counted requests are written to the database
+	FlushInterval time.Duration `yaml:"flush_interval"`
+}
+
+// AnalyticsConfig controls the anonymous catalog usage counters reported by
+// GET /stats/usage
+type AnalyticsConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// FlushInterval is how often the counts are written to the database and
+	// the members who opted out are re-read
+	FlushInterval time.Duration `yaml:"flush_interval"`
+	// MinQueryCount is how often a zero-result query must have been searched
+	// to be reported, so rare queries can't single out a patron
+	MinQueryCount int `yaml:"min_query_count"`
+}
+
+// ExperimentsConfig controls the A/B experiments managed through
+// /admin/experiments
+type ExperimentsConfig struct {
+	// ReloadInterval is how often experiments are re-read from the database
+	// to pick up changes made through other instances
+	ReloadInterval time.Duration `yaml:"reload_interval"`
+	// FlushInterval is how often exposures and conversions are written to
+	// the database
+	FlushInterval time.Duration `yaml:"flush_interval"`
+}
+
+// RecommendationsConfig controls the book recommendations
+type RecommendationsConfig struct {
+	// Strategy is the recommender used: co_borrowing, content or random
+	Strategy string `yaml:"strategy"`
+	// Experiment names the experiment whose variants, when named after a
+	// strategy, override Strategy for the callers in them; empty disables it
+	Experiment   string `yaml:"experiment"`
+	DefaultLimit int    `yaml:"default_limit"`
+	MaxLimit     int    `yaml:"max_limit"`
+	// MinCoBorrowers is how many members must have borrowed a book with the
+	// seeds for co_borrowing to recommend it
+	MinCoBorrowers int `yaml:"min_co_borrowers"`
+}
+
+// FeedConfig controls the members' home feed
+type FeedConfig struct {
+	// NewArrivalDays is how many days after its first copy was acquired a
+	// book counts as a new arrival
+	NewArrivalDays int `yaml:"new_arrival_days"`
+	// DueSoonDays is how many days before it is due a loan shows in the feed
+	DueSoonDays int `yaml:"due_soon_days"`
+	// FavoriteCollections is how many of the collections a member borrows
+	// most from new arrivals are picked from
+	FavoriteCollections int `yaml:"favorite_collections"`
+	DefaultLimit        int `yaml:"default_limit"`
+	// MaxLimit may not exceed recommendations.max_limit, as the feed asks
+	// for as many recommendations as items
+	MaxLimit int `yaml:"max_limit"`
+}
+
+// Embedding providers
+const (
+	EmbeddingsOpenAI = "openai"
+	EmbeddingsOllama = "ollama"
+)
+
+// EmbeddingsConfig computes text embeddings of the books' titles and
+// descriptions, stored with pgvector, for semantic similarity
+type EmbeddingsConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Provider is openai, for the OpenAI API or a server compatible with
+	// its /embeddings endpoint, or ollama, for a local model served by Ollama
+	Provider string `yaml:"provider"`
+	// BaseURL defaults to https://api.openai.com/v1 for openai and
+	// http://localhost:11434 for ollama
+	BaseURL string `yaml:"base_url"`
+	// APIKey is sent as a bearer token; Ollama needs none
+	APIKey string `yaml:"api_key"`
+	Model  string `yaml:"model"`
+	// Dimensions is the length of the model's vectors. The embeddings column
+	// is created with it, so changing it means dropping book_embeddings.
+	Dimensions int `yaml:"dimensions"`
+	// BatchSize is how many books are embedded per request
+	BatchSize int `yaml:"batch_size"`
+	// Interval is how often books added or edited since are embedded
+	Interval time.Duration `yaml:"interval"`
+	// Timeout bounds each request to the provider
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// SandboxConfig runs the API as a public demo: its tables live in a separate
+// schema filled with demo data, which is dropped and rebuilt every
+// ResetInterval, so visitors may write freely without touching real data
+type SandboxConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Schema is dropped on every reset, so it must not hold anything else
+	Schema        string        `yaml:"schema"`
+	ResetInterval time.Duration `yaml:"reset_interval"`
+	// GeneratedBooks is how many fixture books are added to the demo catalog
+	GeneratedBooks int `yaml:"generated_books"`
+}
+
+// ILS providers the sync can pull from
+const (
+	ILSKoha   = "koha"
+	ILSSierra = "sierra"
+)
+
+// ILSSyncConfig pulls bib and item records from a Koha or Sierra ILS, so the
+// catalog can run alongside a legacy ILS during a migration
+type ILSSyncConfig struct {
+	Enabled  bool   `yaml:"enabled"`
+	Provider string `yaml:"provider"`
+	// BaseURL is the root of the ILS REST API, e.g.
+	// https://koha.example.org/api/v1 or https://sierra.example.org/iii/sierra-api/v6
+	BaseURL string `yaml:"base_url"`
+	// ClientID and ClientSecret are the OAuth2 client credentials (Koha) or
+	// API key and secret (Sierra)
+	ClientID     string `yaml:"client_id"`
+	ClientSecret string `yaml:"client_secret"`
+	// Interval is the time between scheduled runs; 0 only syncs when a run
+	// is started through the admin API
+	Interval time.Duration `yaml:"interval"`
+	// PageSize is how many bib records are requested at a time
+	PageSize int `yaml:"page_size"`
+	// Timeout bounds each request to the ILS
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// Exchange rate sources for acquisition reports
+const (
+	RatesStatic = "static"
+	RatesHTTP   = "http"
+)
+
+// AcquisitionsConfig sets the currency acquisition reports are totalled in
+// and where the exchange rates to it come from
+type AcquisitionsConfig struct {
+	// Currency is the ISO 4217 code of report totals, e.g. USD
+	Currency string              `yaml:"currency"`
+	Rates    ExchangeRatesConfig `yaml:"rates"`
+}
+
+// ExchangeRatesConfig gives rates as units of a currency per unit of the
+// report currency, e.g. EUR: 0.92 when the report currency is USD
+type ExchangeRatesConfig struct {
+	// Source is "static", using Static, or "http", fetching URL
+	Source string             `yaml:"source"`
+	Static map[string]float64 `yaml:"static"`
+	// URL answers {"rates": {"EUR": 0.92, ...}} for the report currency, as
+	// https://api.frankfurter.app/latest?from=USD does
+	URL string `yaml:"url"`
+	// RefreshInterval is how long fetched rates are used before refetching
+	RefreshInterval time.Duration `yaml:"refresh_interval"`
+	Timeout         time.Duration `yaml:"timeout"`
+}
+
+// EDIConfig identifies the library in EDIFACT interchanges with vendors
+type EDIConfig struct {
+	// SenderID is the library's 13-digit GLN or 7-digit SAN; ORDERS messages
+	// can't be generated without it
+	SenderID string `yaml:"sender_id"`
+}
+
+// SerialsConfig schedules the reminders to renew serial subscriptions
+type SerialsConfig struct {
+	// RenewalNoticeDays are how many days before a subscription ends to
+	// remind the acquisitions team to renew it, e.g. [90, 30, 7]
+	RenewalNoticeDays []int `yaml:"renewal_notice_days"`
+	// ReminderInterval is how often subscriptions are checked for due
+	// reminders; 0 disables them
+	ReminderInterval time.Duration `yaml:"reminder_interval"`
+}
+
+// Patron groups: the member categories with their own lending policy
+const (
+	GroupChild   = "child"
+	GroupAdult   = "adult"
+	GroupSenior  = "senior"
+	GroupStudent = "student"
+	GroupStaff   = "staff"
+	// GroupGuest is for visitors without a full registration, whose
+	// accounts expire after circulation.guest_days
+	GroupGuest = "guest"
+)
+
+// PatronGroups lists every patron group
+var PatronGroups = []string{GroupChild, GroupAdult, GroupSenior, GroupStudent, GroupStaff, GroupGuest}
+
+// CirculationConfig holds the lending policy of each patron group
+type CirculationConfig struct {
+	// Currency the fine rates are charged in
+	Currency string `yaml:"currency"`
+	// MaxRenewals is how often a loan may be renewed; course reserves set
+	// their own
+	MaxRenewals int `yaml:"max_renewals"`
+	// GraceDays is how many open days past due a copy may be returned
+	// without a fine; returned later, it is fined from the due date
+	GraceDays int `yaml:"grace_days"`
+	// OverdueInterval is how often the fines of loans out past due are
+	// brought up to date; 0 disables the overdue job
+	OverdueInterval time.Duration `yaml:"overdue_interval"`
+	// AutoRenewInterval is how often the loans of members who opted in to
+	// automatic renewal are renewed; 0 disables the auto-renew job
+	AutoRenewInterval time.Duration `yaml:"auto_renew_interval"`
+	// AutoRenewDaysBefore is how many days before a loan is due the
+	// auto-renew job renews it
+	AutoRenewDaysBefore int `yaml:"auto_renew_days_before"`
+	// AnonymizeLoans unlinks every loan from its member when it is returned
+	// without a fine; members can also opt in one by one
+	AnonymizeLoans bool `yaml:"anonymize_loans"`
+	// HoldShelfDays is how many days a copy waits on the hold shelf for its
+	// member before the hold expires
+	HoldShelfDays int `yaml:"hold_shelf_days"`
+	// Floating lists the collections whose copies float: returned at another
+	// branch, they stay there and it becomes their home branch rather than
+	// being sent home
+	Floating []FloatingRule `yaml:"floating"`
+	// GuestDays is how long a guest account lasts unless asked otherwise,
+	// and MaxGuestDays the longest it may
+	GuestDays    int `yaml:"guest_days"`
+	MaxGuestDays int `yaml:"max_guest_days"`
+	// Groups maps every patron group to its limits
+	Groups map[string]PatronGroupConfig `yaml:"groups"`
+}
+
+// FloatingRule lets the copies of the Collections float among Branches; an
+// empty Branches lets them float to every branch
+type FloatingRule struct {
+	Collections []string `yaml:"collections"`
+	Branches    []string `yaml:"branches"`
+}
+
+// PatronGroupConfig is the lending policy of one patron group
+type PatronGroupConfig struct {
+	// LoanLimit is how many copies a member may have out at once
+	LoanLimit int `yaml:"loan_limit"`
+	// LoanDays is the loan period; 0 uses the loan period of the copy's
+	// format (formats.loan_days)
+	LoanDays int `yaml:"loan_days"`
+	// FinePerDay is charged for each day a copy is returned late, a decimal
+	// amount in the circulation currency
+	FinePerDay string `yaml:"fine_per_day"`
+	// HoldLimit is how many holds a member may have waiting at once
+	HoldLimit int `yaml:"hold_limit"`
+	// InLibrary lends for use in the library only: loans are due by the
+	// end of the day and can't be renewed
+	InLibrary bool `yaml:"in_library"`
+}
+
+// PolicyConfig lists the loan rules that refine the patron group policies
+// for particular formats or groups, e.g. shorter DVD loans for children.
+// For each term, the first matching rule that sets it decides it; terms no
+// rule sets come from the patron group and the format.
+type PolicyConfig struct {
+	Rules []LoanRule `yaml:"rules"`
+}
+
+// SavedSearchesConfig controls the searches members save to be alerted to
+// new books
+type SavedSearchesConfig struct {
+	// AlertInterval is how often saved searches are checked against the
+	// books added since; 0 disables alerts
+	AlertInterval time.Duration `yaml:"alert_interval"`
+	// MaxPerMember caps how many searches one member can save
+	MaxPerMember int `yaml:"max_per_member"`
+}
+
+// DevicesConfig controls the monitoring of self-check kiosks and gate
+// readers
+type DevicesConfig struct {
+	// SilentAfter is how long a device may go without a heartbeat before it
+	// shows as silent and is alerted
+	SilentAfter time.Duration `yaml:"silent_after"`
+	// CheckInterval is how often devices are checked for silence; 0
+	// disables alerts
+	CheckInterval time.Duration `yaml:"check_interval"`
+	// ErrorRetention is how long reported errors are kept; 0 keeps them
+	ErrorRetention time.Duration `yaml:"error_retention"`
+}
+
+// LoanRule sets loan terms for copies in one of Formats lent to members of
+// one of Groups; an empty list matches every format or group
+type LoanRule struct {
+	Formats []string `yaml:"formats"`
+	Groups  []string `yaml:"groups"`
+	// LoanDays is the loan period
+	LoanDays *int `yaml:"loan_days"`
+	// MaxLoans caps how many copies in Formats a member may have out at once;
+	// with no formats it replaces the group's loan limit
+	MaxLoans *int `yaml:"max_loans"`
+	// MaxRenewals is how often a loan may be renewed
+	MaxRenewals *int `yaml:"max_renewals"`
+	// GraceDays replaces circulation.grace_days
+	GraceDays *int `yaml:"grace_days"`
+	// MaxFine caps the fine for returning one copy late, a decimal amount in
+	// the circulation currency
+	MaxFine string `yaml:"max_fine"`
+}
+
+var currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+var localePattern = regexp.MustCompile(`^[A-Za-z]{2,8}(-[A-Za-z0-9]{1,8})*$`)
+
+var amountPattern = regexp.MustCompile(`^\d{1,10}(\.\d{1,4})?$`)
+
+var partyIDPattern = regexp.MustCompile(`^(\d{13}|\d{7})$`)
+
+var identifierPattern = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+
+// Default returns the configuration used for anything a file leaves out
+func Default() AppConfig {
+	return AppConfig{
+		DB: DBConfig{
+			SlowQueryThreshold: 200 * time.Millisecond,
+			Retry: RetryConfig{
+				MaxAttempts: 3,
+				BaseDelay:   50 * time.Millisecond,
+				MaxDelay:    time.Second,
+			},
+		},
+		Cache: CacheConfig{
+			BookList: cache.Config{TTL: 30 * time.Second, MaxEntries: 1000},
+		},
+		Catalog: CatalogConfig{
+			DescriptionPreview: 200,
+			DefaultPageSize:    10,
+			MaxPageSize:        100,
+			SortLocale:         "und",
+			Suggestions:        5,
+		},
+		Features: FeaturesConfig{
+			BulkImport: true,
+			Export:     true,
+		},
+		Health: HealthConfig{
+			Timeout:  2 * time.Second,
+			CacheTTL: 10 * time.Second,
+		},
+		RateLimit: RateLimitConfig{
+			ReloadInterval: time.Minute,
+		},
+		Sandbox: SandboxConfig{
+			Schema:         "sandbox",
+			ResetInterval:  time.Hour,
+			GeneratedBooks: 200,
+		},
+		Usage: UsageConfig{
+			FlushInterval: time.Minute,
+		},
+		Analytics: AnalyticsConfig{
+			Enabled:       true,
+			FlushInterval: time.Minute,
+			MinQueryCount: 3,
+		},
+		Experiments: ExperimentsConfig{
+			ReloadInterval: time.Minute,
+			FlushInterval:  time.Minute,
+		},
+		Recommendations: RecommendationsConfig{
+			Strategy:       "co_borrowing",
+			Experiment:     "recommendations",
+			DefaultLimit:   10,
+			MaxLimit:       50,
+			MinCoBorrowers: 2,
+		},
+		Feed: FeedConfig{
+			NewArrivalDays:      30,
+			DueSoonDays:         3,
+			FavoriteCollections: 3,
+			DefaultLimit:        20,
+			MaxLimit:            50,
+		},
+		Embeddings: EmbeddingsConfig{
+			Provider:   EmbeddingsOpenAI,
+			Model:      "text-embedding-3-small",
+			Dimensions: 1536,
+			BatchSize:  64,
+			Interval:   5 * time.Minute,
+			Timeout:    30 * time.Second,
+		},
+		ILSSync: ILSSyncConfig{
+			Interval: time.Hour,
+			PageSize: 100,
+			Timeout:  30 * time.Second,
+		},
+		Acquisitions: AcquisitionsConfig{
+			Currency: "USD",
+			Rates: ExchangeRatesConfig{
+				Source:          RatesStatic,
+				RefreshInterval: 12 * time.Hour,
+				Timeout:         10 * time.Second,
+			},
+		},
+		Serials: SerialsConfig{
+			RenewalNoticeDays: []int{90, 30, 7},
+			ReminderInterval:  time.Hour,
+		},
+		Circulation: CirculationConfig{
+			Currency:            "USD",
+			MaxRenewals:         2,
+			OverdueInterval:     time.Hour,
+			AutoRenewInterval:   24 * time.Hour,
+			AutoRenewDaysBefore: 2,
+			HoldShelfDays:       7,
+			GuestDays:           1,
+			MaxGuestDays:        30,
+			Groups: map[string]PatronGroupConfig{
+				GroupChild:   {LoanLimit: 10, FinePerDay: "0", HoldLimit: 5},
+				GroupAdult:   {LoanLimit: 30, FinePerDay: "0.25", HoldLimit: 15},
+				GroupSenior:  {LoanLimit: 30, FinePerDay: "0.10", HoldLimit: 15},
+				GroupStudent: {LoanLimit: 20, FinePerDay: "0.10", HoldLimit: 10},
+				GroupStaff:   {LoanLimit: 50, LoanDays: 42, FinePerDay: "0", HoldLimit: 25},
+				GroupGuest:   {LoanLimit: 2, FinePerDay: "0", InLibrary: true},
+			},
+		},
+		SavedSearches: SavedSearchesConfig{
+			AlertInterval: time.Hour,
+			MaxPerMember:  20,
+		},
+		Devices: DevicesConfig{
+			SilentAfter:    5 * time.Minute,
+			CheckInterval:  time.Minute,
+			ErrorRetention: 90 * 24 * time.Hour,
+		},
+		Media: MediaConfig{
+			StorageDir:     "data/media",
+			MaxUploadBytes: 2 << 30,
+			TokenTTL:       time.Hour,
+			MaxLoanPeriod:  21 * 24 * time.Hour,
+		},
+		Partitions: PartitionConfig{
+			MonthsAhead: 3,
+		},
+		Stats: StatsConfig{
+			RefreshInterval: 5 * time.Minute,
+		},
+		Server: ServerConfig{
+			Port: 8080,
+			Timeouts: TimeoutsConfig{
+				Read:   2 * time.Second,
+				Write:  5 * time.Second,
+				Import: 10 * time.Second,
+			},
+		},
+	}
+}
+
+// Load reads the YAML file at path over the defaults and validates the result
+func Load(path string) (AppConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return AppConfig{}, err
+	}
+
+	cfg := Default()
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return AppConfig{}, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return AppConfig{}, fmt.Errorf("invalid config %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// Validate reports every setting that is out of range, joined into one error
+func (c AppConfig) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(c.Server.Port > 0 && c.Server.Port <= 65535, "server.port must be between 1 and 65535")
+	check(c.Server.Timeouts.Read >= 0, "server.timeouts.read must not be negative")
+	check(c.Server.Timeouts.Write >= 0, "server.timeouts.write must not be negative")
+	check(c.Server.Timeouts.Import >= 0, "server.timeouts.import must not be negative")
+
+	check(c.DB.Retry.MaxAttempts >= 1, "db.retry.max_attempts must be at least 1")
+	check(c.DB.Retry.BaseDelay >= 0, "db.retry.base_delay must not be negative")
+	check(c.DB.Retry.MaxDelay >= c.DB.Retry.BaseDelay, "db.retry.max_delay must not be less than base_delay")
+	check(c.DB.SlowQueryThreshold >= 0, "db.slow_query_threshold must not be negative")
+
+	check(c.Stats.RefreshInterval >= 0, "stats.refresh_interval must not be negative")
+	check(c.Partitions.MonthsAhead >= 0, "partitions.months_ahead must not be negative")
+	for table, months := range c.Partitions.RetentionMonths {
+		check(months >= 0, "partitions.retention_months.%s must not be negative", table)
+	}
+	check(c.Cache.BookList.TTL >= 0, "cache.book_list.ttl must not be negative")
+	check(c.Cache.BookList.MaxEntries >= 0, "cache.book_list.max_entries must not be negative")
+
+	check(c.Catalog.DescriptionPreview >= 0, "catalog.description_preview must not be negative")
+	check(c.Catalog.DefaultPageSize >= 1, "catalog.default_page_size must be at least 1")
+	check(localePattern.MatchString(c.Catalog.SortLocale), "catalog.sort_locale must be a BCP 47 locale such as en or fr-CA")
+	check(c.Catalog.Suggestions >= 0, "catalog.suggestions must not be negative")
+	check(c.Catalog.MaxPageSize == 0 || c.Catalog.MaxPageSize >= c.Catalog.DefaultPageSize,
+		"catalog.max_page_size must be 0 (no limit) or at least default_page_size")
+
+	check(c.Health.Timeout > 0, "health.timeout must be positive")
+	check(c.Health.CacheTTL >= 0, "health.cache_ttl must not be negative")
+	names := make(map[string]bool)
+	for i, ic := range c.Health.Checks {
+		check(ic.Name != "", "health.checks[%d].name is required", i)
+		check(!names[ic.Name], "health.checks[%d].name %q is used twice", i, ic.Name)
+		names[ic.Name] = true
+		check(ic.Type == CheckHTTP || ic.Type == CheckTCP || ic.Type == CheckSMTP,
+			"health.checks[%d].type must be one of http, tcp, smtp", i)
+		check(ic.Target != "", "health.checks[%d].target is required", i)
+		check(ic.Timeout >= 0, "health.checks[%d].timeout must not be negative", i)
+	}
+
+	check(c.Maintenance.RetryAfter >= 0, "maintenance.retry_after must not be negative")
+
+	check(c.RateLimit.RequestsPerMinute >= 0, "rate_limit.requests_per_minute must not be negative")
+	check(c.RateLimit.Burst >= 0, "rate_limit.burst must not be negative")
+	check(c.RateLimit.DailyQuota >= 0, "rate_limit.daily_quota must not be negative")
+	check(c.RateLimit.ReloadInterval >= 0, "rate_limit.reload_interval must not be negative")
+
+	check(c.Usage.FlushInterval > 0, "usage.flush_interval must be positive")
+	check(c.Analytics.FlushInterval > 0, "analytics.flush_interval must be positive")
+	check(c.Analytics.MinQueryCount >= 1, "analytics.min_query_count must be at least 1")
+	check(c.Experiments.ReloadInterval >= 0, "experiments.reload_interval must not be negative")
+	check(c.Experiments.FlushInterval > 0, "experiments.flush_interval must be positive")
+	check(c.Recommendations.MaxLimit >= 1, "recommendations.max_limit must be at least 1")
+	check(c.Recommendations.DefaultLimit >= 1 && c.Recommendations.DefaultLimit <= c.Recommendations.MaxLimit,
+		"recommendations.default_limit must be between 1 and recommendations.max_limit")
+	check(c.Recommendations.MinCoBorrowers >= 1, "recommendations.min_co_borrowers must be at least 1")
+	check(c.Feed.NewArrivalDays >= 1, "feed.new_arrival_days must be at least 1")
+	check(c.Feed.DueSoonDays >= 0, "feed.due_soon_days must not be negative")
+	check(c.Feed.FavoriteCollections >= 1, "feed.favorite_collections must be at least 1")
+	check(c.Feed.MaxLimit >= 1 && c.Feed.MaxLimit <= c.Recommendations.MaxLimit,
+		"feed.max_limit must be between 1 and recommendations.max_limit")
+	check(c.Feed.DefaultLimit >= 1 && c.Feed.DefaultLimit <= c.Feed.MaxLimit,
+		"feed.default_limit must be between 1 and feed.max_limit")
+
+	if c.Sandbox.Enabled {
+		check(identifierPattern.MatchString(c.Sandbox.Schema), "sandbox.schema must be a lowercase SQL identifier")
+		check(c.Sandbox.Schema != "public" && c.Sandbox.Schema != c.DB.Schema,
+			"sandbox.schema must not be public or db.schema, since it is dropped on every reset")
+		check(c.Sandbox.ResetInterval > 0, "sandbox.reset_interval must be positive")
+		check(c.Sandbox.GeneratedBooks >= 0, "sandbox.generated_books must not be negative")
+	}
+
+	if c.Embeddings.Enabled {
+		check(c.Embeddings.Provider == EmbeddingsOpenAI || c.Embeddings.Provider == EmbeddingsOllama,
+			"embeddings.provider must be openai or ollama")
+		if c.Embeddings.BaseURL != "" {
+			u, err := url.Parse(c.Embeddings.BaseURL)
+			check(err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "", "embeddings.base_url must be an http(s) URL")
+		}
+		check(c.Embeddings.Provider != EmbeddingsOpenAI || c.Embeddings.BaseURL != "" || c.Embeddings.APIKey != "",
+			"embeddings.api_key is required for the OpenAI API")
+		check(c.Embeddings.Model != "", "embeddings.model is required")
+		// pgvector indexes vectors of up to 2000 dimensions
+		check(c.Embeddings.Dimensions >= 1 && c.Embeddings.Dimensions <= 2000, "embeddings.dimensions must be between 1 and 2000")
+		check(c.Embeddings.BatchSize >= 1, "embeddings.batch_size must be at least 1")
+		check(c.Embeddings.Interval > 0, "embeddings.interval must be positive")
+	}
+	if c.ILSSync.Enabled {
+		check(c.ILSSync.Provider == ILSKoha || c.ILSSync.Provider == ILSSierra, "ils_sync.provider must be koha or sierra")
+		u, err := url.Parse(c.ILSSync.BaseURL)
+		check(err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "", "ils_sync.base_url must be an http(s) URL")
+		check(c.ILSSync.ClientID != "" && c.ILSSync.ClientSecret != "", "ils_sync.client_id and client_secret are required")
+		check(c.ILSSync.Interval >= 0, "ils_sync.interval must not be negative")
+		check(c.ILSSync.PageSize >= 1 && c.ILSSync.PageSize <= 2000, "ils_sync.page_size must be between 1 and 2000")
+		check(c.ILSSync.Timeout > 0, "ils_sync.timeout must be positive")
+	}
+
+	check(currencyPattern.MatchString(c.Acquisitions.Currency), "acquisitions.currency must be an ISO 4217 code such as USD")
+	switch c.Acquisitions.Rates.Source {
+	case RatesStatic:
+		for currency, rate := range c.Acquisitions.Rates.Static {
+			check(currencyPattern.MatchString(currency), "acquisitions.rates.static: %q is not an ISO 4217 code", currency)
+			check(rate > 0, "acquisitions.rates.static.%s must be positive", currency)
+		}
+	case RatesHTTP:
+		u, err := url.Parse(c.Acquisitions.Rates.URL)
+		check(err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "", "acquisitions.rates.url must be an http(s) URL")
+		check(c.Acquisitions.Rates.RefreshInterval > 0, "acquisitions.rates.refresh_interval must be positive")
+		check(c.Acquisitions.Rates.Timeout > 0, "acquisitions.rates.timeout must be positive")
+	default:
+		check(false, "acquisitions.rates.source must be static or http")
+	}
+
+	check(c.EDI.SenderID == "" || partyIDPattern.MatchString(c.EDI.SenderID), "edi.sender_id must be a 13-digit GLN or a 7-digit SAN")
+
+	for i, days := range c.Serials.RenewalNoticeDays {
+		check(days >= 0, "serials.renewal_notice_days[%d] must not be negative", i)
+	}
+	check(c.Serials.ReminderInterval >= 0, "serials.reminder_interval must not be negative")
+
+	check(currencyPattern.MatchString(c.Circulation.Currency), "circulation.currency must be an ISO 4217 code such as USD")
+	check(c.Circulation.MaxRenewals >= 0, "circulation.max_renewals must not be negative")
+	check(c.Circulation.GraceDays >= 0, "circulation.grace_days must not be negative")
+	check(c.Circulation.OverdueInterval >= 0, "circulation.overdue_interval must not be negative")
+	check(c.Circulation.AutoRenewInterval >= 0, "circulation.auto_renew_interval must not be negative")
+	check(c.Circulation.AutoRenewDaysBefore >= 0, "circulation.auto_renew_days_before must not be negative")
+	check(c.Circulation.HoldShelfDays > 0, "circulation.hold_shelf_days must be positive")
+	check(c.Circulation.GuestDays > 0, "circulation.guest_days must be positive")
+	check(c.Circulation.MaxGuestDays >= c.Circulation.GuestDays, "circulation.max_guest_days must be at least guest_days")
+	for _, group := range PatronGroups {
+		_, ok := c.Circulation.Groups[group]
+		check(ok, "circulation.groups.%s is required", group)
+	}
+	for group, g := range c.Circulation.Groups {
+		check(slices.Contains(PatronGroups, group), "circulation.groups: unknown patron group %q", group)
+		check(g.LoanLimit >= 0, "circulation.groups.%s.loan_limit must not be negative", group)
+		check(g.LoanDays >= 0, "circulation.groups.%s.loan_days must not be negative", group)
+		check(amountPattern.MatchString(g.FinePerDay), "circulation.groups.%s.fine_per_day must be a non-negative decimal amount", group)
+		check(g.HoldLimit >= 0, "circulation.groups.%s.hold_limit must not be negative", group)
+	}
+	for i, rule := range c.Circulation.Floating {
+		check(len(rule.Collections) > 0, "circulation.floating[%d].collections is required", i)
+	}
+
+	for i, rule := range c.Policy.Rules {
+		for _, group := range rule.Groups {
+			check(slices.Contains(PatronGroups, group), "policy.rules[%d]: unknown patron group %q", i, group)
+		}
+		check(rule.LoanDays == nil || *rule.LoanDays >= 1, "policy.rules[%d].loan_days must be at least 1", i)
+		check(rule.MaxLoans == nil || *rule.MaxLoans >= 0, "policy.rules[%d].max_loans must not be negative", i)
+		check(rule.MaxRenewals == nil || *rule.MaxRenewals >= 0, "policy.rules[%d].max_renewals must not be negative", i)
+		check(rule.GraceDays == nil || *rule.GraceDays >= 0, "policy.rules[%d].grace_days must not be negative", i)
+		check(rule.MaxFine == "" || amountPattern.MatchString(rule.MaxFine), "policy.rules[%d].max_fine must be a non-negative decimal amount", i)
+	}
+
+	check(c.SavedSearches.AlertInterval >= 0, "saved_searches.alert_interval must not be negative")
+	check(c.SavedSearches.MaxPerMember >= 1, "saved_searches.max_per_member must be at least 1")
+
+	check(c.Devices.SilentAfter > 0, "devices.silent_after must be positive")
+	check(c.Devices.CheckInterval >= 0, "devices.check_interval must not be negative")
+	check(c.Devices.ErrorRetention >= 0, "devices.error_retention must not be negative")
+
+	check(c.Media.StorageDir != "", "media.storage_dir is required")
+	check(c.Media.MaxUploadBytes > 0, "media.max_upload_bytes must be positive")
+	check(c.Media.TokenTTL > 0, "media.token_ttl must be positive")
+	check(c.Media.MaxLoanPeriod >= c.Media.TokenTTL, "media.max_loan_period must be at least media.token_ttl")
+
+	return errors.Join(errs...)
+}
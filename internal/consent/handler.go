@@ -0,0 +1,141 @@
+package consent
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"public_library/internal/httperr"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	svc    *Service
+	logger *zap.Logger
+}
+
+func NewHandler(svc *Service, l *zap.Logger) *Handler {
+	return &Handler{svc: svc, logger: l}
+}
+
+// GET /consent-documents?current=true
+
+// ListConsentDocuments godoc
+// @Summary List the documents members accept
+// @Description Lists every published version by kind, newest first, or with current=true only the current version of each kind.
+// @Tags consent
+// @Produce json
+// @Param current query bool false "Only the current versions"
+// @Success 200 {array} Document
+// @Failure 400 {object} map[string]string
+// @Router /consent-documents [get]
+func (h *Handler) ListConsentDocuments(w http.ResponseWriter, r *http.Request) {
+	var current bool
+	if v := r.URL.Query().Get("current"); v != "" {
+		var err error
+		if current, err = strconv.ParseBool(v); err != nil {
+			http.Error(w, "invalid current", http.StatusBadRequest)
+			return
+		}
+	}
+	list, err := h.svc.ListDocuments(r.Context(), current)
+	if err != nil {
+		h.writeError(w, "failed to list consent documents", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// POST /consent-documents
+
+// PublishConsentDocument godoc
+// @Summary Publish a document version
+// @Description Makes the document the current version of its kind. If it is required, members must accept it before they can use the API again, even if they accepted an earlier version.
+// @Tags consent
+// @Accept json
+// @Produce json
+// @Param document body Document true "Document version to publish"
+// @Success 201 {object} Document
+// @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /consent-documents [post]
+func (h *Handler) PublishConsentDocument(w http.ResponseWriter, r *http.Request) {
+	var d Document
+	if err := json.NewDecoder(r.Body).Decode(&d); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if err := h.svc.Publish(r.Context(), &d); err != nil {
+		h.writeError(w, "failed to publish consent document", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(d)
+}
+
+// GET /members/{id}/consents
+
+// GetMemberConsents godoc
+// @Summary Get a member's consents
+// @Description Lists the document versions the member has accepted and the current versions of required documents they still have to accept.
+// @Tags consent
+// @Produce json
+// @Param id path string true "Member ID"
+// @Success 200 {object} MemberConsents
+// @Router /members/{id}/consents [get]
+func (h *Handler) GetMemberConsents(w http.ResponseWriter, r *http.Request) {
+	c, err := h.svc.MemberConsents(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		h.writeError(w, "failed to get member consents", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(c)
+}
+
+// POST /members/{id}/consents
+
+// AcceptConsentDocument godoc
+// @Summary Record a member accepting a document
+// @Description Only the current version of a document can be accepted. Accepting it again keeps the time it was first accepted.
+// @Tags consent
+// @Accept json
+// @Produce json
+// @Param id path string true "Member ID"
+// @Param acceptance body AcceptRequest true "Document accepted"
+// @Success 200 {object} Acceptance
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /members/{id}/consents [post]
+func (h *Handler) AcceptConsentDocument(w http.ResponseWriter, r *http.Request) {
+	var req AcceptRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	a, err := h.svc.Accept(r.Context(), mux.Vars(r)["id"], req)
+	if err != nil {
+		h.writeError(w, "failed to record consent", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(a)
+}
+
+func (h *Handler) writeError(w http.ResponseWriter, msg string, err error) {
+	switch {
+	case errors.Is(err, ErrInvalid):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, ErrNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, ErrSuperseded):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		httperr.Write(w, h.logger, msg, err)
+	}
+}
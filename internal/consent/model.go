@@ -0,0 +1,40 @@
+package consent
+
+import "time"
+
+// Document is one published version of a terms of use, privacy policy or
+// other document members accept. The most recently published version of
+// each kind is the current one.
+type Document struct {
+	ID      int    `json:"id" example:"1"`
+	Kind    string `json:"kind" example:"terms"`
+	Version string `json:"version" example:"2026-10"`
+	Title   string `json:"title,omitempty" example:"Terms of use"`
+	URL     string `json:"url,omitempty" example:"https://library.example.org/terms/2026-10"`
+	// Required documents must be accepted in their current version before the
+	// member can use the API
+	Required    bool      `json:"required" example:"true"`
+	PublishedAt time.Time `json:"published_at"`
+}
+
+// Acceptance records a member accepting one document version
+type Acceptance struct {
+	MemberID   string    `json:"member_id" example:"m-1001"`
+	DocumentID int       `json:"document_id" example:"1"`
+	Kind       string    `json:"kind" example:"terms"`
+	Version    string    `json:"version" example:"2026-10"`
+	AcceptedAt time.Time `json:"accepted_at"`
+}
+
+// MemberConsents lists what a member has accepted and the current versions
+// of required documents they still have to
+type MemberConsents struct {
+	MemberID    string       `json:"member_id" example:"m-1001"`
+	Accepted    []Acceptance `json:"accepted"`
+	Outstanding []Document   `json:"outstanding"`
+}
+
+// AcceptRequest accepts a document version for a member
+type AcceptRequest struct {
+	DocumentID int `json:"document_id" example:"1"`
+}
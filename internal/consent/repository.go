@@ -0,0 +1,173 @@
+package consent
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"public_library/internal/db"
+	"public_library/utils"
+)
+
+// ErrNotFound is returned when no document has the requested id
+var ErrNotFound = errors.New("consent document not found")
+
+var documentMapping = db.Mapping[Document]{
+	Table:   utils.ConsentDocumentsTable,
+	Columns: []string{"id", "kind", "version", "title", "url", "required", "published_at"},
+	Fields: func(d *Document) []interface{} {
+		return []interface{}{&d.ID, &d.Kind, &d.Version, &d.Title, &d.URL, &d.Required, &d.PublishedAt}
+	},
+	Writable: []string{"kind", "version", "title", "url", "required"},
+	Values: func(d *Document) []interface{} {
+		return []interface{}{d.Kind, d.Version, d.Title, d.URL, d.Required}
+	},
+	ID: func(d *Document) int { return d.ID },
+}
+
+// currentSQL selects the current version of each kind of document ("d")
+const currentSQL = `
+	SELECT DISTINCT ON (d.kind) d.id, d.kind, d.version, d.title, d.url, d.required, d.published_at
+	FROM %s d
+	ORDER BY d.kind, d.published_at DESC, d.id DESC
+`
+
+type Repository struct {
+	db        *db.DB
+	documents *db.Table[Document]
+}
+
+func NewRepository(conn *db.DB) *Repository {
+	return &Repository{db: conn, documents: db.NewTable(conn, documentMapping)}
+}
+
+// ListDocuments returns every published version, or only the current one of
+// each kind, by kind and newest first
+func (r *Repository) ListDocuments(ctx context.Context, current bool) ([]Document, error) {
+	if current {
+		return r.queryDocuments(ctx, fmt.Sprintf(currentSQL, utils.ConsentDocumentsTable))
+	}
+	return r.documents.List(ctx, db.ListOptions{OrderBy: []string{"kind", "published_at DESC", "id DESC"}})
+}
+
+func (r *Repository) GetDocument(ctx context.Context, id int) (*Document, error) {
+	d, err := r.documents.Get(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		log.Printf("Failed to get consent document id=%d: %v", id, err)
+		return nil, err
+	}
+	return &d, nil
+}
+
+// IsCurrent reports whether the document is the current version of its kind
+func (r *Repository) IsCurrent(ctx context.Context, d *Document) (bool, error) {
+	var current bool
+	query := fmt.Sprintf(`
+		SELECT NOT EXISTS (
+			SELECT 1 FROM %s
+			WHERE kind = $1 AND (published_at, id) > ($2, $3)
+		)
+	`, utils.ConsentDocumentsTable)
+	err := r.db.QueryRowContext(ctx, query, d.Kind, d.PublishedAt, d.ID).Scan(&current)
+	return current, err
+}
+
+// Publish stores d as the new current version of its kind
+func (r *Repository) Publish(ctx context.Context, d *Document) error {
+	log.Println("<--------Publish consent document starts-------->")
+	defer log.Println("<--------Publish consent document ends-------->")
+
+	if err := r.documents.Insert(ctx, d); err != nil {
+		log.Printf("Failed to publish consent document %s %s: %v", d.Kind, d.Version, err)
+		return err
+	}
+	return nil
+}
+
+// Accept records the member accepting the document, keeping the time of
+// the first acceptance if they already had
+func (r *Repository) Accept(ctx context.Context, memberID string, d *Document) (*Acceptance, error) {
+	log.Println("<--------Accept consent document starts-------->")
+	defer log.Println("<--------Accept consent document ends-------->")
+
+	a := &Acceptance{MemberID: memberID, DocumentID: d.ID, Kind: d.Kind, Version: d.Version}
+	query := fmt.Sprintf(`
+		WITH inserted AS (
+			INSERT INTO %[1]s (member_id, document_id) VALUES ($1, $2)
+			ON CONFLICT (member_id, document_id) DO NOTHING
+			RETURNING accepted_at
+		)
+		SELECT accepted_at FROM inserted
+		UNION ALL
+		SELECT accepted_at FROM %[1]s WHERE member_id = $1 AND document_id = $2
+		LIMIT 1
+	`, utils.ConsentsTable)
+	if err := r.db.QueryRowContext(ctx, query, memberID, d.ID).Scan(&a.AcceptedAt); err != nil {
+		log.Printf("Failed to record member id=%s accepting consent document id=%d: %v", memberID, d.ID, err)
+		return nil, err
+	}
+	return a, nil
+}
+
+// Acceptances returns the document versions the member has accepted, most
+// recent first
+func (r *Repository) Acceptances(ctx context.Context, memberID string) ([]Acceptance, error) {
+	query := fmt.Sprintf(`
+		SELECT c.member_id, c.document_id, d.kind, d.version, c.accepted_at
+		FROM %s c JOIN %s d ON d.id = c.document_id
+		WHERE c.member_id = $1
+		ORDER BY c.accepted_at DESC, d.id DESC
+	`, utils.ConsentsTable, utils.ConsentDocumentsTable)
+	rows, err := r.db.QueryContext(ctx, query, memberID)
+	if err != nil {
+		log.Printf("Failed to list the consents of member id=%s: %v", memberID, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []Acceptance{}
+	for rows.Next() {
+		var a Acceptance
+		if err := rows.Scan(&a.MemberID, &a.DocumentID, &a.Kind, &a.Version, &a.AcceptedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, a)
+	}
+	return list, rows.Err()
+}
+
+// Outstanding returns the current versions of required documents the
+// member hasn't accepted
+func (r *Repository) Outstanding(ctx context.Context, memberID string) ([]Document, error) {
+	query := fmt.Sprintf(`
+		SELECT * FROM (`+currentSQL+`) current
+		WHERE current.required AND NOT EXISTS (
+			SELECT 1 FROM %s c WHERE c.member_id = $1 AND c.document_id = current.id
+		)
+		ORDER BY current.kind
+	`, utils.ConsentDocumentsTable, utils.ConsentsTable)
+	return r.queryDocuments(ctx, query, memberID)
+}
+
+func (r *Repository) queryDocuments(ctx context.Context, query string, args ...interface{}) ([]Document, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		log.Printf("Failed to list consent documents: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []Document{}
+	for rows.Next() {
+		var d Document
+		if err := rows.Scan(documentMapping.Fields(&d)...); err != nil {
+			return nil, err
+		}
+		list = append(list, d)
+	}
+	return list, rows.Err()
+}
@@ -0,0 +1,102 @@
+// Package consent tracks which versions of the terms of use, privacy policy
+// and other documents members have accepted. Publishing a new version of a
+// required document makes every member accept it again before they can use
+// the API; middleware.Consent enforces it.
+package consent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	// ErrInvalid is wrapped by errors about the content of a request
+	ErrInvalid = errors.New("invalid consent request")
+	// ErrSuperseded is returned for accepting a document version that is no
+	// longer current
+	ErrSuperseded = errors.New("a newer version of this document has been published")
+)
+
+const maxTextLength = 200
+
+var kindPattern = regexp.MustCompile(`^[a-z][a-z0-9_-]{0,49}$`)
+
+type Service struct {
+	repo *Repository
+}
+
+func NewService(repo *Repository) *Service {
+	return &Service{repo: repo}
+}
+
+// ListDocuments returns every published document version, or only the
+// current version of each kind
+func (s *Service) ListDocuments(ctx context.Context, current bool) ([]Document, error) {
+	return s.repo.ListDocuments(ctx, current)
+}
+
+// Publish validates d and makes it the current version of its kind.
+// Members who accepted an earlier version of a required document have to
+// accept this one.
+func (s *Service) Publish(ctx context.Context, d *Document) error {
+	d.Kind = strings.ToLower(strings.TrimSpace(d.Kind))
+	if !kindPattern.MatchString(d.Kind) {
+		return fmt.Errorf("%w: kind must be a lower-case name such as terms or privacy", ErrInvalid)
+	}
+	d.Version = strings.TrimSpace(d.Version)
+	if d.Version == "" {
+		return fmt.Errorf("%w: version is required", ErrInvalid)
+	}
+	d.Title = strings.TrimSpace(d.Title)
+	d.URL = strings.TrimSpace(d.URL)
+	for field, value := range map[string]string{"version": d.Version, "title": d.Title, "url": d.URL} {
+		if utf8.RuneCountInString(value) > maxTextLength {
+			return fmt.Errorf("%w: %s must be at most %d characters", ErrInvalid, field, maxTextLength)
+		}
+	}
+	return s.repo.Publish(ctx, d)
+}
+
+// Accept records the member accepting the current version of a document
+func (s *Service) Accept(ctx context.Context, memberID string, req AcceptRequest) (*Acceptance, error) {
+	memberID = strings.TrimSpace(memberID)
+	if memberID == "" {
+		return nil, fmt.Errorf("%w: member id is required", ErrInvalid)
+	}
+	d, err := s.repo.GetDocument(ctx, req.DocumentID)
+	if err != nil {
+		return nil, err
+	}
+	current, err := s.repo.IsCurrent(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+	if !current {
+		return nil, ErrSuperseded
+	}
+	return s.repo.Accept(ctx, memberID, d)
+}
+
+// MemberConsents lists what the member has accepted and what they still
+// have to
+func (s *Service) MemberConsents(ctx context.Context, memberID string) (*MemberConsents, error) {
+	accepted, err := s.repo.Acceptances(ctx, memberID)
+	if err != nil {
+		return nil, err
+	}
+	outstanding, err := s.repo.Outstanding(ctx, memberID)
+	if err != nil {
+		return nil, err
+	}
+	return &MemberConsents{MemberID: memberID, Accepted: accepted, Outstanding: outstanding}, nil
+}
+
+// Outstanding returns the current versions of required documents the
+// member hasn't accepted
+func (s *Service) Outstanding(ctx context.Context, memberID string) ([]Document, error) {
+	return s.repo.Outstanding(ctx, memberID)
+}
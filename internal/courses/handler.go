@@ -0,0 +1,294 @@
+package courses
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"public_library/internal/httperr"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	svc    *Service
+	logger *zap.Logger
+}
+
+func NewHandler(svc *Service, l *zap.Logger) *Handler {
+	return &Handler{svc: svc, logger: l}
+}
+
+// GET /courses?term=Fall%202026
+
+// ListCourses godoc
+// @Summary List courses
+// @Tags courses
+// @Produce json
+// @Param term query string false "Only courses of this term"
+// @Success 200 {array} Course
+// @Router /courses [get]
+func (h *Handler) ListCourses(w http.ResponseWriter, r *http.Request) {
+	list, err := h.svc.List(r.Context(), r.URL.Query().Get("term"))
+	if err != nil {
+		h.writeError(w, "failed to list courses", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// GET /courses/{id}
+
+// GetCourse godoc
+// @Summary Get a course with its reserves
+// @Tags courses
+// @Produce json
+// @Param id path int true "Course ID"
+// @Success 200 {object} CourseResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /courses/{id} [get]
+func (h *Handler) GetCourse(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id", "invalid course ID")
+	if !ok {
+		return
+	}
+	c, err := h.svc.Get(r.Context(), id)
+	if err != nil {
+		h.writeError(w, "failed to get course", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(c)
+}
+
+// POST /courses
+
+// CreateCourse godoc
+// @Summary Create a course
+// @Tags courses
+// @Accept json
+// @Produce json
+// @Param course body Course true "Course to create"
+// @Success 201 {object} Course
+// @Failure 400 {object} map[string]string
+// @Router /courses [post]
+func (h *Handler) CreateCourse(w http.ResponseWriter, r *http.Request) {
+	var c Course
+	if err := json.NewDecoder(r.Body).Decode(&c); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if err := h.svc.Create(r.Context(), &c); err != nil {
+		h.writeError(w, "failed to create course", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(c)
+}
+
+// PUT /courses/{id}
+
+// UpdateCourse godoc
+// @Summary Update a course
+// @Description Changing the dates changes when the course's reserves lend on reserve terms.
+// @Tags courses
+// @Accept json
+// @Produce json
+// @Param id path int true "Course ID"
+// @Param course body Course true "Updated course"
+// @Success 200 {object} Course
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /courses/{id} [put]
+func (h *Handler) UpdateCourse(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id", "invalid course ID")
+	if !ok {
+		return
+	}
+	var c Course
+	if err := json.NewDecoder(r.Body).Decode(&c); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	c.ID = id
+	if err := h.svc.Update(r.Context(), &c); err != nil {
+		h.writeError(w, "failed to update course", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(c)
+}
+
+// DELETE /courses/{id}
+
+// DeleteCourse godoc
+// @Summary Delete a course
+// @Description Deletes the course and takes its copies off reserve
+// @Tags courses
+// @Param id path int true "Course ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /courses/{id} [delete]
+func (h *Handler) DeleteCourse(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id", "invalid course ID")
+	if !ok {
+		return
+	}
+	if err := h.svc.Delete(r.Context(), id); err != nil {
+		h.writeError(w, "failed to delete course", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GET /courses/{id}/reserves
+
+// ListReserves godoc
+// @Summary List the reserves of a course
+// @Description The reserve list students see: each copy on reserve with its book, branch, loan terms and whether it is available.
+// @Tags courses
+// @Produce json
+// @Param id path int true "Course ID"
+// @Success 200 {array} Reserve
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /courses/{id}/reserves [get]
+func (h *Handler) ListReserves(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id", "invalid course ID")
+	if !ok {
+		return
+	}
+	list, err := h.svc.ListReserves(r.Context(), id)
+	if err != nil {
+		h.writeError(w, "failed to list reserves", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// POST /courses/{id}/reserves
+
+// AddReserve godoc
+// @Summary Put a copy on reserve for a course
+// @Description While the course runs, the copy lends for loan_hours (default 3) and renews at most max_renewals times (default 0). A copy can be on reserve for one course at a time.
+// @Tags courses
+// @Accept json
+// @Produce json
+// @Param id path int true "Course ID"
+// @Param reserve body Reserve true "Copy and loan terms"
+// @Success 201 {object} Reserve
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /courses/{id}/reserves [post]
+func (h *Handler) AddReserve(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id", "invalid course ID")
+	if !ok {
+		return
+	}
+	var rs Reserve
+	if err := json.NewDecoder(r.Body).Decode(&rs); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	rs.CourseID = id
+	if err := h.svc.AddReserve(r.Context(), &rs); err != nil {
+		h.writeError(w, "failed to add reserve", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(rs)
+}
+
+// PUT /courses/{id}/reserves/{reserve_id}
+
+// UpdateReserve godoc
+// @Summary Change the loan terms of a reserve
+// @Tags courses
+// @Accept json
+// @Produce json
+// @Param id path int true "Course ID"
+// @Param reserve_id path int true "Reserve ID"
+// @Param reserve body Reserve true "Loan terms and note"
+// @Success 200 {object} Reserve
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /courses/{id}/reserves/{reserve_id} [put]
+func (h *Handler) UpdateReserve(w http.ResponseWriter, r *http.Request) {
+	courseID, ok := pathID(w, r, "id", "invalid course ID")
+	if !ok {
+		return
+	}
+	id, ok := pathID(w, r, "reserve_id", "invalid reserve ID")
+	if !ok {
+		return
+	}
+	var rs Reserve
+	if err := json.NewDecoder(r.Body).Decode(&rs); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	rs.ID, rs.CourseID = id, courseID
+	if err := h.svc.UpdateReserve(r.Context(), &rs); err != nil {
+		h.writeError(w, "failed to update reserve", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rs)
+}
+
+// DELETE /courses/{id}/reserves/{reserve_id}
+
+// RemoveReserve godoc
+// @Summary Take a copy off reserve
+// @Tags courses
+// @Param id path int true "Course ID"
+// @Param reserve_id path int true "Reserve ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /courses/{id}/reserves/{reserve_id} [delete]
+func (h *Handler) RemoveReserve(w http.ResponseWriter, r *http.Request) {
+	courseID, ok := pathID(w, r, "id", "invalid course ID")
+	if !ok {
+		return
+	}
+	id, ok := pathID(w, r, "reserve_id", "invalid reserve ID")
+	if !ok {
+		return
+	}
+	if err := h.svc.RemoveReserve(r.Context(), courseID, id); err != nil {
+		h.writeError(w, "failed to remove reserve", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func pathID(w http.ResponseWriter, r *http.Request, name, msg string) (int, bool) {
+	id, err := strconv.Atoi(mux.Vars(r)[name])
+	if err != nil {
+		http.Error(w, msg, http.StatusBadRequest)
+		return 0, false
+	}
+	return id, true
+}
+
+func (h *Handler) writeError(w http.ResponseWriter, msg string, err error) {
+	switch {
+	case errors.Is(err, ErrInvalid):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, ErrCourseNotFound), errors.Is(err, ErrReserveNotFound), errors.Is(err, ErrCopyNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, ErrOnReserve):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		httperr.Write(w, h.logger, msg, err)
+	}
+}
@@ -0,0 +1,47 @@
+package courses
+
+import "time"
+
+// Course is a class whose instructor puts items on reserve for its students
+type Course struct {
+	ID int `json:"id" example:"12"`
+	// Code is the course's catalog code, unique within a term
+	Code       string `json:"code" example:"HIST 210"`
+	Title      string `json:"title" example:"The Atlantic World, 1500-1800"`
+	Instructor string `json:"instructor" example:"Dr. Amara Okafor"`
+	Term       string `json:"term" example:"Fall 2026"`
+	// StartsOn and EndsOn bound when the course's reserves apply
+	StartsOn  string    `json:"starts_on" example:"2026-09-01"`
+	EndsOn    string    `json:"ends_on" example:"2026-12-18"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CourseResponse is a course with its reserves
+type CourseResponse struct {
+	Course
+	Reserves []Reserve `json:"reserves"`
+}
+
+// Reserve is a copy held on reserve for a course. While the course runs
+// the copy lends for LoanHours and can be renewed at most MaxRenewals
+// times, so every student gets a turn.
+type Reserve struct {
+	ID            int `json:"id" example:"40"`
+	CourseID      int `json:"course_id" example:"12"`
+	AcquisitionID int `json:"acquisition_id" example:"31"`
+	// LoanHours is the reserve loan period; it defaults to 3
+	LoanHours int `json:"loan_hours" example:"3"`
+	// MaxRenewals defaults to 0, no renewals
+	MaxRenewals int    `json:"max_renewals" example:"0"`
+	Note        string `json:"note,omitempty" example:"Chapters 3-5 required for week 2"`
+	// The copy and its book, filled in on reads
+	BookID     int    `json:"book_id" example:"1"`
+	Title      string `json:"title" example:"The Hunger Games"`
+	Author     string `json:"author" example:"Suzanne Collins"`
+	CallNumber string `json:"call_number" example:"813.6 COL"`
+	Branch     string `json:"branch" example:"Central"`
+	// Available is false while the copy is withdrawn, out for repair or on
+	// loan
+	Available bool      `json:"available" example:"true"`
+	CreatedAt time.Time `json:"created_at"`
+}
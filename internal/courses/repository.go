@@ -0,0 +1,272 @@
+package courses
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"public_library/internal/db"
+	"public_library/utils"
+)
+
+var (
+	ErrCourseNotFound  = errors.New("course not found")
+	ErrReserveNotFound = errors.New("reserve not found")
+	ErrCopyNotFound    = errors.New("copy not found")
+	// ErrOnReserve is returned for reserving a copy already on reserve for
+	// a course that hasn't ended
+	ErrOnReserve = errors.New("copy is already on reserve")
+)
+
+// Dates are selected as text so they read as YYYY-MM-DD
+var courseMapping = db.Mapping[Course]{
+	Table:   utils.CoursesTable,
+	Columns: []string{"id", "code", "title", "instructor", "term", "starts_on::text", "ends_on::text", "created_at"},
+	Fields: func(c *Course) []interface{} {
+		return []interface{}{&c.ID, &c.Code, &c.Title, &c.Instructor, &c.Term, &c.StartsOn, &c.EndsOn, &c.CreatedAt}
+	},
+	Writable: []string{"code", "title", "instructor", "term", "starts_on", "ends_on"},
+	Values: func(c *Course) []interface{} {
+		return []interface{}{c.Code, c.Title, c.Instructor, c.Term, c.StartsOn, c.EndsOn}
+	},
+	ID: func(c *Course) int { return c.ID },
+}
+
+type Repository struct {
+	db      *db.DB
+	courses *db.Table[Course]
+}
+
+func NewRepository(conn *db.DB) *Repository {
+	return &Repository{db: conn, courses: db.NewTable(conn, courseMapping)}
+}
+
+// List returns the courses of a term, or of every term if term is empty,
+// by code
+func (r *Repository) List(ctx context.Context, term string) ([]Course, error) {
+	opts := db.ListOptions{OrderBy: []string{"code", "id"}}
+	if term != "" {
+		opts.Filters = []db.Filter{{Column: "term", Op: "=", Value: term}}
+	}
+	list, err := r.courses.List(ctx, opts)
+	if err != nil {
+		log.Printf("Failed to list courses: %v", err)
+		return nil, err
+	}
+	return list, nil
+}
+
+func (r *Repository) Get(ctx context.Context, id int) (*Course, error) {
+	c, err := r.courses.Get(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrCourseNotFound
+		}
+		log.Printf("Failed to get course id=%d: %v", id, err)
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (r *Repository) Create(ctx context.Context, c *Course) error {
+	log.Println("<--------Create course starts-------->")
+	defer log.Println("<--------Create course ends-------->")
+
+	if err := r.courses.Insert(ctx, c); err != nil {
+		log.Printf("Failed to create course %+v: %v", c, err)
+		return err
+	}
+	return nil
+}
+
+func (r *Repository) Update(ctx context.Context, c *Course) error {
+	log.Println("<--------Update course starts-------->")
+	defer log.Println("<--------Update course ends-------->")
+
+	if err := r.courses.Update(ctx, c); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrCourseNotFound
+		}
+		log.Printf("Failed to update course id=%d: %v", c.ID, err)
+		return err
+	}
+	return nil
+}
+
+// Delete removes the course and takes its copies off reserve
+func (r *Repository) Delete(ctx context.Context, id int) error {
+	log.Println("<--------Delete course starts-------->")
+	defer log.Println("<--------Delete course ends-------->")
+
+	if err := r.courses.Delete(ctx, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrCourseNotFound
+		}
+		log.Printf("Failed to delete course id=%d: %v", id, err)
+		return err
+	}
+	return nil
+}
+
+// selectReservesSQL joins reserves ("rs") to their copy and book
+const selectReservesSQL = `
+	SELECT rs.id, rs.course_id, rs.acquisition_id, rs.loan_hours, rs.max_renewals, rs.note,
+		a.book_id, b.title, b.author, b.call_number, a.branch,
+		a.withdrawn_at IS NULL AND NOT EXISTS (
+			SELECT 1 FROM %[4]s r WHERE r.acquisition_id = a.id AND r.returned_on IS NULL
+		) AND NOT EXISTS (
+			SELECT 1 FROM %[5]s l WHERE l.acquisition_id = a.id AND l.returned_at IS NULL
+		),
+		rs.created_at
+	FROM %[1]s rs
+	JOIN %[2]s a ON a.id = rs.acquisition_id
+	JOIN %[3]s b ON b.id = a.book_id
+`
+
+func selectReserves(where string) string {
+	return fmt.Sprintf(selectReservesSQL, utils.CourseReservesTable, utils.AcquisitionsTable, utils.BooksTable,
+		utils.RepairsTable, utils.LoansTable) + where
+}
+
+func scanReserve(row interface{ Scan(...interface{}) error }) (Reserve, error) {
+	var rs Reserve
+	err := row.Scan(&rs.ID, &rs.CourseID, &rs.AcquisitionID, &rs.LoanHours, &rs.MaxRenewals, &rs.Note,
+		&rs.BookID, &rs.Title, &rs.Author, &rs.CallNumber, &rs.Branch, &rs.Available, &rs.CreatedAt)
+	return rs, err
+}
+
+// ListReserves returns the reserves of a course by title
+func (r *Repository) ListReserves(ctx context.Context, courseID int) ([]Reserve, error) {
+	rows, err := r.db.QueryContext(ctx, selectReserves(`WHERE rs.course_id = $1 ORDER BY b.title, rs.id`), courseID)
+	if err != nil {
+		log.Printf("Failed to list reserves of course id=%d: %v", courseID, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []Reserve{}
+	for rows.Next() {
+		rs, err := scanReserve(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, rs)
+	}
+	return list, rows.Err()
+}
+
+// AddReserve puts a copy on reserve for a course and fills in rs from the
+// stored row. A copy can be on reserve for one running course at a time.
+func (r *Repository) AddReserve(ctx context.Context, rs *Reserve) error {
+	log.Println("<--------Add reserve starts-------->")
+	defer log.Println("<--------Add reserve ends-------->")
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Locking the copy serializes reserves of it
+	var copyID int
+	lock := fmt.Sprintf(`SELECT id FROM %s WHERE id = $1 FOR UPDATE`, utils.AcquisitionsTable)
+	if err := tx.QueryRowContext(ctx, lock, rs.AcquisitionID).Scan(&copyID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrCopyNotFound
+		}
+		return err
+	}
+	var reserved bool
+	check := fmt.Sprintf(`
+		SELECT EXISTS (
+			SELECT 1 FROM %s rs JOIN %s c ON c.id = rs.course_id
+			WHERE rs.acquisition_id = $1 AND c.ends_on >= CURRENT_DATE
+		)
+	`, utils.CourseReservesTable, utils.CoursesTable)
+	if err := tx.QueryRowContext(ctx, check, rs.AcquisitionID).Scan(&reserved); err != nil {
+		return err
+	}
+	if reserved {
+		return ErrOnReserve
+	}
+
+	var id int
+	insert := fmt.Sprintf(`
+		INSERT INTO %s (course_id, acquisition_id, loan_hours, max_renewals, note)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`, utils.CourseReservesTable)
+	if err := tx.QueryRowContext(ctx, insert, rs.CourseID, rs.AcquisitionID, rs.LoanHours, rs.MaxRenewals, rs.Note).Scan(&id); err != nil {
+		log.Printf("Failed to reserve acquisition id=%d for course id=%d: %v", rs.AcquisitionID, rs.CourseID, err)
+		return err
+	}
+	stored, err := scanReserve(tx.QueryRowContext(ctx, selectReserves(`WHERE rs.id = $1`), id))
+	if err != nil {
+		return err
+	}
+	*rs = stored
+	return tx.Commit()
+}
+
+// UpdateReserve changes the loan terms and note of a reserve of a course
+func (r *Repository) UpdateReserve(ctx context.Context, rs *Reserve) error {
+	query := fmt.Sprintf(`
+		UPDATE %s SET loan_hours = $3, max_renewals = $4, note = $5
+		WHERE id = $1 AND course_id = $2
+	`, utils.CourseReservesTable)
+	result, err := r.db.ExecContext(ctx, query, rs.ID, rs.CourseID, rs.LoanHours, rs.MaxRenewals, rs.Note)
+	if err != nil {
+		log.Printf("Failed to update reserve id=%d: %v", rs.ID, err)
+		return err
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrReserveNotFound
+	}
+	stored, err := scanReserve(r.db.QueryRowContext(ctx, selectReserves(`WHERE rs.id = $1`), rs.ID))
+	if err != nil {
+		return err
+	}
+	*rs = stored
+	return nil
+}
+
+// RemoveReserve takes a copy off reserve for a course
+func (r *Repository) RemoveReserve(ctx context.Context, courseID, id int) error {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE id = $1 AND course_id = $2`, utils.CourseReservesTable)
+	result, err := r.db.ExecContext(ctx, query, id, courseID)
+	if err != nil {
+		log.Printf("Failed to remove reserve id=%d: %v", id, err)
+		return err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrReserveNotFound
+	}
+	return nil
+}
+
+// ActiveReserve returns the reserve of a copy for a course running on the
+// current date, or nil if the copy isn't on reserve
+func (r *Repository) ActiveReserve(ctx context.Context, acquisitionID int) (*Reserve, error) {
+	query := selectReserves(fmt.Sprintf(`
+		JOIN %s c ON c.id = rs.course_id
+		WHERE rs.acquisition_id = $1 AND CURRENT_DATE BETWEEN c.starts_on AND c.ends_on
+		ORDER BY c.ends_on DESC
+		LIMIT 1
+	`, utils.CoursesTable))
+	rs, err := scanReserve(r.db.QueryRowContext(ctx, query, acquisitionID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		log.Printf("Failed to look up the reserve of acquisition id=%d: %v", acquisitionID, err)
+		return nil, err
+	}
+	return &rs, nil
+}
@@ -0,0 +1,147 @@
+// Package courses runs course reserves: instructors put copies on reserve
+// for a course, and while the course runs those copies lend for a few hours
+// with limited renewals, so a whole class can use them.
+package courses
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ErrInvalid is wrapped by errors about the content of a request
+var ErrInvalid = errors.New("invalid course request")
+
+const (
+	maxTextLength = 500
+	// defaultLoanHours applies to reserves that don't set a loan period
+	defaultLoanHours = 3
+	// maxLoanHours keeps reserve loans short: two weeks at most
+	maxLoanHours = 14 * 24
+)
+
+type Service struct {
+	repo *Repository
+}
+
+func NewService(repo *Repository) *Service {
+	return &Service{repo: repo}
+}
+
+// List returns the courses of a term, or every course if term is empty
+func (s *Service) List(ctx context.Context, term string) ([]Course, error) {
+	return s.repo.List(ctx, strings.TrimSpace(term))
+}
+
+// Get returns a course with its reserves
+func (s *Service) Get(ctx context.Context, id int) (*CourseResponse, error) {
+	c, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	reserves, err := s.repo.ListReserves(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return &CourseResponse{Course: *c, Reserves: reserves}, nil
+}
+
+func (s *Service) Create(ctx context.Context, c *Course) error {
+	if err := c.validate(); err != nil {
+		return err
+	}
+	return s.repo.Create(ctx, c)
+}
+
+func (s *Service) Update(ctx context.Context, c *Course) error {
+	if err := c.validate(); err != nil {
+		return err
+	}
+	return s.repo.Update(ctx, c)
+}
+
+func (s *Service) Delete(ctx context.Context, id int) error {
+	return s.repo.Delete(ctx, id)
+}
+
+func (c *Course) validate() error {
+	fields := map[string]*string{"code": &c.Code, "title": &c.Title, "instructor": &c.Instructor, "term": &c.Term}
+	for name, v := range fields {
+		*v = strings.TrimSpace(*v)
+		if utf8.RuneCountInString(*v) > maxTextLength {
+			return fmt.Errorf("%w: %s must be at most %d characters", ErrInvalid, name, maxTextLength)
+		}
+	}
+	if c.Code == "" || c.Title == "" {
+		return fmt.Errorf("%w: code and title are required", ErrInvalid)
+	}
+	starts, err := time.Parse(time.DateOnly, c.StartsOn)
+	if err != nil {
+		return fmt.Errorf("%w: starts_on must be a date (YYYY-MM-DD)", ErrInvalid)
+	}
+	ends, err := time.Parse(time.DateOnly, c.EndsOn)
+	if err != nil {
+		return fmt.Errorf("%w: ends_on must be a date (YYYY-MM-DD)", ErrInvalid)
+	}
+	if ends.Before(starts) {
+		return fmt.Errorf("%w: ends_on must not be before starts_on", ErrInvalid)
+	}
+	return nil
+}
+
+// ListReserves returns the reserve list of a course, by title
+func (s *Service) ListReserves(ctx context.Context, courseID int) ([]Reserve, error) {
+	if _, err := s.repo.Get(ctx, courseID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListReserves(ctx, courseID)
+}
+
+// AddReserve validates rs and puts its copy on reserve for its course
+func (s *Service) AddReserve(ctx context.Context, rs *Reserve) error {
+	if _, err := s.repo.Get(ctx, rs.CourseID); err != nil {
+		return err
+	}
+	if err := rs.validate(); err != nil {
+		return err
+	}
+	return s.repo.AddReserve(ctx, rs)
+}
+
+// UpdateReserve changes the loan terms of a reserve
+func (s *Service) UpdateReserve(ctx context.Context, rs *Reserve) error {
+	if err := rs.validate(); err != nil {
+		return err
+	}
+	return s.repo.UpdateReserve(ctx, rs)
+}
+
+func (s *Service) RemoveReserve(ctx context.Context, courseID, id int) error {
+	return s.repo.RemoveReserve(ctx, courseID, id)
+}
+
+// ActiveReserve returns the reserve terms a copy lends under today, or nil
+// if it isn't on reserve for a running course
+func (s *Service) ActiveReserve(ctx context.Context, acquisitionID int) (*Reserve, error) {
+	return s.repo.ActiveReserve(ctx, acquisitionID)
+}
+
+func (rs *Reserve) validate() error {
+	if rs.LoanHours == 0 {
+		rs.LoanHours = defaultLoanHours
+	}
+	if rs.LoanHours < 1 || rs.LoanHours > maxLoanHours {
+		return fmt.Errorf("%w: loan_hours must be between 1 and %d", ErrInvalid, maxLoanHours)
+	}
+	if rs.MaxRenewals < 0 {
+		return fmt.Errorf("%w: max_renewals must not be negative", ErrInvalid)
+	}
+	rs.Note = strings.TrimSpace(rs.Note)
+	if utf8.RuneCountInString(rs.Note) > maxTextLength {
+		return fmt.Errorf("%w: note must be at most %d characters", ErrInvalid, maxTextLength)
+	}
+	return nil
+}
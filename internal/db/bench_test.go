@@ -0,0 +1,46 @@
+package db
+
+import (
+	"public_library/internal/config"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Benchmarks of the per-query overhead added to every database call, run
+// with
+//
+//	go test ./internal/db -run '^$' -bench . -benchmem
+
+func BenchmarkSanitizeArgs(b *testing.B) {
+	args := []interface{}{42, "m-1001", strings.Repeat("x", 500), []string{"children", "teen"}, nil, time.Now(), 3.5}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		sanitizeArgs(args)
+	}
+}
+
+func BenchmarkSanitizeSQL(b *testing.B) {
+	query := selectTestSQL
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		sanitizeSQL(query)
+	}
+}
+
+func BenchmarkRetryBackoff(b *testing.B) {
+	r := NewRetrier(config.RetryConfig{MaxAttempts: 5, BaseDelay: 50 * time.Millisecond, MaxDelay: time.Second})
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		r.backoff(i%5 + 1)
+	}
+}
+
+const selectTestSQL = `
+	SELECT b.id, b.title, b.author
+	FROM books b
+	WHERE (1=1 AND (b.title ILIKE $1 OR b.author ILIKE $1))
+		AND b.publication_year >= $2
+	ORDER BY b.title, b.id
+	LIMIT $3 OFFSET $4
+`
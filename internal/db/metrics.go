@@ -0,0 +1,74 @@
+package db
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	queriesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "db_queries_total",
+		Help: "Number of database queries issued, by calling endpoint.",
+	}, []string{"endpoint"})
+
+	querySecondsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "db_query_seconds_total",
+		Help: "Cumulative time spent in database queries, by calling endpoint.",
+	}, []string{"endpoint"})
+
+	queriesPerRequest = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "db_queries_per_request",
+		Help:    "Number of database queries issued while serving a single request.",
+		Buckets: []float64{0, 1, 2, 3, 5, 10, 20, 50, 100},
+	}, []string{"endpoint"})
+
+	querySecondsPerRequest = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "db_query_seconds_per_request",
+		Help:    "Time spent in database queries while serving a single request.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"endpoint"})
+)
+
+const queryStatsKey ctxKey = endpointKey + 1
+
+// QueryStats accumulates the queries issued while serving one request.
+// A request whose query count grows with its result size is an N+1 pattern.
+type QueryStats struct {
+	mu       sync.Mutex
+	count    int
+	duration time.Duration
+}
+
+// WithQueryStats attaches a fresh QueryStats to ctx
+func WithQueryStats(ctx context.Context) (context.Context, *QueryStats) {
+	stats := &QueryStats{}
+	return context.WithValue(ctx, queryStatsKey, stats), stats
+}
+
+func (s *QueryStats) add(elapsed time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.count++
+	s.duration += elapsed
+}
+
+// ObserveRequest records the per-request totals for endpoint
+func ObserveRequest(endpoint string, stats *QueryStats) {
+	stats.mu.Lock()
+	defer stats.mu.Unlock()
+	queriesPerRequest.WithLabelValues(endpoint).Observe(float64(stats.count))
+	querySecondsPerRequest.WithLabelValues(endpoint).Observe(stats.duration.Seconds())
+}
+
+func recordQuery(ctx context.Context, elapsed time.Duration) {
+	endpoint := EndpointFromContext(ctx)
+	queriesTotal.WithLabelValues(endpoint).Inc()
+	querySecondsTotal.WithLabelValues(endpoint).Add(elapsed.Seconds())
+	if stats, ok := ctx.Value(queryStatsKey).(*QueryStats); ok {
+		stats.add(elapsed)
+	}
+}
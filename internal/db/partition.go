@@ -0,0 +1,162 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"public_library/internal/config"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// PartitionedTables lists the tables partitioned by month: audit_log on
+// created_at and loan_history on returned_at
+var PartitionedTables = []string{"audit_log", "loan_history"}
+
+// partitionKeys names the column each of PartitionedTables is partitioned on
+var partitionKeys = map[string]string{"audit_log": "created_at", "loan_history": "returned_at"}
+
+const partitionSuffixLayout = "y2006m01"
+
+func partitionName(table string, month time.Time) string {
+	return table + "_" + month.Format(partitionSuffixLayout)
+}
+
+func monthStart(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// MaintainPartitions creates the partitions for the current month and the
+// configured number of months ahead, then drops partitions past retention.
+// Rows that reached the default partition, such as the loan_history backfill,
+// get partitions for their months too, back to the oldest of them.
+func MaintainPartitions(ctx context.Context, conn Queryer, cfg config.PartitionConfig, now time.Time) error {
+	current := monthStart(now.UTC())
+	for _, table := range PartitionedTables {
+		from, err := oldestDefaultMonth(ctx, conn, table)
+		if err != nil {
+			return err
+		}
+		if from.IsZero() || from.After(current) {
+			from = current
+		}
+		for month := from; !month.After(current.AddDate(0, cfg.MonthsAhead, 0)); month = month.AddDate(0, 1, 0) {
+			if err := createPartition(ctx, conn, table, month); err != nil {
+				return fmt.Errorf("creating partition %s: %w", partitionName(table, month), err)
+			}
+		}
+
+		retention := cfg.RetentionMonths[table]
+		if retention <= 0 {
+			continue
+		}
+		if err := dropExpiredPartitions(ctx, conn, table, current.AddDate(0, -retention, 0)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// oldestDefaultMonth returns the month of the oldest row in the default
+// partition of table, or the zero time when it is empty
+func oldestDefaultMonth(ctx context.Context, conn Queryer, table string) (time.Time, error) {
+	var oldest sql.NullTime
+	query := fmt.Sprintf(`SELECT min(%s) FROM %s_default`, partitionKeys[table], table)
+	if err := conn.QueryRowContext(ctx, query).Scan(&oldest); err != nil {
+		return time.Time{}, fmt.Errorf("reading the default partition of %s: %w", table, err)
+	}
+	if !oldest.Valid {
+		return time.Time{}, nil
+	}
+	return monthStart(oldest.Time.UTC()), nil
+}
+
+// createPartition adds the partition of table for month unless it is already
+// attached. Postgres refuses a partition whose range has rows in the default
+// partition, so those rows are moved into the new table before it is
+// attached. The statements go out as one query, which Postgres runs as a
+// single transaction, so the moved rows never drop out of table.
+func createPartition(ctx context.Context, conn Queryer, table string, month time.Time) error {
+	name := partitionName(table, month)
+	var attached bool
+	err := conn.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM pg_inherits WHERE inhrelid = to_regclass($1))`, name).Scan(&attached)
+	if err != nil || attached {
+		return err
+	}
+
+	from, to := month.Format(time.DateOnly), month.AddDate(0, 1, 0).Format(time.DateOnly)
+	key := partitionKeys[table]
+	stmts := []string{
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (LIKE %s INCLUDING DEFAULTS INCLUDING CONSTRAINTS)`, name, table),
+		fmt.Sprintf(`WITH moved AS (DELETE FROM %s_default WHERE %s >= '%s' AND %s < '%s' RETURNING *)
+			INSERT INTO %s SELECT * FROM moved`, table, key, from, key, to, name),
+		fmt.Sprintf(`ALTER TABLE %s ATTACH PARTITION %s FOR VALUES FROM ('%s') TO ('%s')`, table, name, from, to),
+	}
+	_, err = conn.ExecContext(ctx, strings.Join(stmts, ";\n"))
+	return err
+}
+
+// dropExpiredPartitions drops every monthly partition of table that starts before cutoff
+func dropExpiredPartitions(ctx context.Context, conn Queryer, table string, cutoff time.Time) error {
+	const query = `
+		SELECT c.relname
+		FROM pg_inherits i
+		JOIN pg_class c ON c.oid = i.inhrelid
+		JOIN pg_class p ON p.oid = i.inhparent
+		WHERE p.oid = to_regclass($1)
+	`
+	rows, err := conn.QueryContext(ctx, query, table)
+	if err != nil {
+		return fmt.Errorf("listing partitions of %s: %w", table, err)
+	}
+	var expired []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return err
+		}
+		month, err := time.Parse(partitionSuffixLayout, strings.TrimPrefix(name, table+"_"))
+		if err != nil {
+			continue // not a monthly partition, e.g. the default partition
+		}
+		if month.Before(cutoff) {
+			expired = append(expired, name)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, name := range expired {
+		if _, err := conn.ExecContext(ctx, fmt.Sprintf(`DROP TABLE IF EXISTS %s`, name)); err != nil {
+			return fmt.Errorf("dropping partition %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// StartPartitionMaintenance runs MaintainPartitions now and then daily until ctx is cancelled
+func StartPartitionMaintenance(ctx context.Context, conn *sql.DB, cfg config.PartitionConfig, logger *zap.Logger) {
+	run := func() {
+		if err := MaintainPartitions(ctx, conn, cfg, time.Now()); err != nil {
+			logger.Error("Partition maintenance failed", zap.Error(err))
+		}
+	}
+	run()
+	go func() {
+		ticker := time.NewTicker(24 * time.Hour)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				run()
+			}
+		}
+	}()
+}
@@ -0,0 +1,58 @@
+package db_test
+
+import (
+	"context"
+	"public_library/internal/config"
+	"public_library/internal/db"
+	"public_library/pkg/testutil"
+	"testing"
+	"time"
+)
+
+// TestMaintainPartitionsAfterBackfill upgrades a database that already has
+// returned loans, this month and long ago, to loan_history and checks that
+// maintenance still creates this month's partition and prunes the old loan
+func TestMaintainPartitionsAfterBackfill(t *testing.T) {
+	h := testutil.New(t)
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	// Loans inserted already returned skip the history trigger, like loans
+	// returned before loan_history existed
+	seed := []string{
+		`INSERT INTO members (id, name, patron_group) VALUES ('m-1', 'Ada Marsh', 'adult')`,
+		`INSERT INTO books (title, author) VALUES ('Harbor Lights', 'Ada Marsh')`,
+		`INSERT INTO acquisitions (book_id, price, currency) SELECT id, 10, 'EUR' FROM books`,
+		`INSERT INTO loans (member_id, acquisition_id, checked_out_at, due_at, max_renewals, returned_at)
+		SELECT 'm-1', a.id, r - interval '14 days', r, 2, r
+		FROM acquisitions a, (VALUES (now()), (now() - interval '3 years')) AS v (r)`,
+	}
+	for _, stmt := range seed {
+		if _, err := h.DB.ExecContext(ctx, stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+	if err := db.ApplySchema(ctx, h.DB); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.PartitionConfig{MonthsAhead: 2, RetentionMonths: map[string]int{"loan_history": 12}}
+	for run := 1; run <= 2; run++ {
+		if err := db.MaintainPartitions(ctx, h.DB, cfg, now); err != nil {
+			t.Fatalf("run %d: %v", run, err)
+		}
+	}
+
+	var inDefault, inMonth, total int
+	err := h.DB.QueryRowContext(ctx, `SELECT
+		(SELECT count(*) FROM loan_history_default),
+		(SELECT count(*) FROM loan_history_`+now.Format("y2006m01")+`),
+		(SELECT count(*) FROM loan_history)`).Scan(&inDefault, &inMonth, &total)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inDefault != 0 || inMonth != 1 || total != 1 {
+		t.Errorf("got %d loans in the default partition, %d in this month's and %d in all; want 0, 1 and 1",
+			inDefault, inMonth, total)
+	}
+}
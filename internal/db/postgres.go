@@ -4,53 +4,24 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
-	"gopkg.in/yaml.v3"
-	"os"
+	"net/url"
+	"public_library/internal/config"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"go.uber.org/zap"
 
 	_ "github.com/jackc/pgx/v5/stdlib" // PostgreSQL driver
 )
 
-type Config struct {
-	Host     string `yaml:"host"`
-	Port     string `yaml:"port"`
-	User     string `yaml:"user"`
-	Password string `yaml:"password"`
-	DBName   string `yaml:"dbname"`
-	SSLMode  string `yaml:"sslmode"`
-}
-
-// ServerConfig holds HTTP server configuration
-type ServerConfig struct {
-	Port int    `yaml:"port"`
-	Host string `yaml:"host"`
-}
-
-type AppConfig struct {
-	DB     Config       `yaml:"db"`
-	Server ServerConfig `yaml:"server"`
-}
-
-func LoadConfigFromYAML(path string) (Config, error) {
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return Config{}, err
-	}
-
-	var appConfig AppConfig
-	if err := yaml.Unmarshal(data, &appConfig); err != nil {
-		return Config{}, err
-	}
-
-	return appConfig.DB, nil
-}
-
 // InitConnection initializes and verifies a secure DB connection
-func InitConnection(cfg Config, logger *zap.Logger) *sql.DB {
+func InitConnection(cfg config.DBConfig, logger *zap.Logger) *sql.DB {
 	dsn := fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=%s",
 		cfg.User, cfg.Password, cfg.Host, cfg.Port, cfg.DBName, cfg.SSLMode)
+	if cfg.Schema != "" {
+		// public stays on the path for the extensions' types and operators
+		dsn += "&search_path=" + url.QueryEscape(cfg.Schema+",public")
+	}
 
 	db, err := sql.Open("pgx", dsn)
 	if err != nil {
@@ -70,23 +41,37 @@ func InitConnection(cfg Config, logger *zap.Logger) *sql.DB {
 	}
 
 	logger.Info("Successfully connected to database")
+	if cfg.Schema != "" {
+		if _, err := db.ExecContext(ctx, "CREATE SCHEMA IF NOT EXISTS "+pgx.Identifier{cfg.Schema}.Sanitize()); err != nil {
+			logger.Fatal("Failed to create schema", zap.String("schema", cfg.Schema), zap.Error(err))
+		}
+	}
 	createTables(db, logger)
 	return db
 }
 
 func createTables(db *sql.DB, logger *zap.Logger) {
-	schema := `
-	CREATE TABLE IF NOT EXISTS books (
-		id SERIAL PRIMARY KEY,
-		title TEXT NOT NULL,
-		author TEXT NOT NULL,
-		isbn TEXT NOT NULL
-	);`
-
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	if _, err := db.ExecContext(ctx, schema); err != nil {
-		logger.Fatal("Failed to create books table", zap.Error(err))
+	if err := ApplySchema(ctx, db); err != nil {
+		logger.Fatal("Failed to apply schema", zap.Error(err))
+	}
+}
+
+// Execer runs statements; *sql.DB and *sql.Tx both implement it
+type Execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// ApplySchema brings the database schema up to date. It is safe to run on
+// every start, including against a connection opened by an embedding program.
+// Tables are created in the first schema of the connection's search_path.
+func ApplySchema(ctx context.Context, db Execer) error {
+	for _, stmt := range schema {
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("applying schema statement %q: %w", stmt, err)
+		}
 	}
+	return nil
 }
@@ -0,0 +1,144 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type ctxKey int
+
+const endpointKey ctxKey = iota
+
+// WithEndpoint tags ctx with the API endpoint that is issuing queries
+func WithEndpoint(ctx context.Context, endpoint string) context.Context {
+	return context.WithValue(ctx, endpointKey, endpoint)
+}
+
+// EndpointFromContext returns the endpoint set by WithEndpoint, or "unknown"
+func EndpointFromContext(ctx context.Context) string {
+	if endpoint, ok := ctx.Value(endpointKey).(string); ok {
+		return endpoint
+	}
+	return "unknown"
+}
+
+// DB wraps *sql.DB, records per-endpoint query metrics and logs any
+// statement slower than the configured threshold
+type DB struct {
+	*sql.DB
+	slowThreshold time.Duration
+	logger        *zap.Logger
+}
+
+func NewDB(conn *sql.DB, slowThreshold time.Duration, logger *zap.Logger) *DB {
+	return &DB{DB: conn, slowThreshold: slowThreshold, logger: logger}
+}
+
+func (d *DB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	start := time.Now()
+	rows, err := d.DB.QueryContext(ctx, query, args...)
+	d.observe(ctx, query, args, time.Since(start))
+	return rows, err
+}
+
+func (d *DB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	start := time.Now()
+	row := d.DB.QueryRowContext(ctx, query, args...)
+	d.observe(ctx, query, args, time.Since(start))
+	return row
+}
+
+func (d *DB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	start := time.Now()
+	result, err := d.DB.ExecContext(ctx, query, args...)
+	d.observe(ctx, query, args, time.Since(start))
+	return result, err
+}
+
+// Queryer is implemented by both *DB and *Tx so helpers can run either way
+type Queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// Tx is a transaction whose statements are instrumented like DB's
+type Tx struct {
+	*sql.Tx
+	db *DB
+}
+
+func (d *DB) BeginTx(ctx context.Context, opts *sql.TxOptions) (*Tx, error) {
+	tx, err := d.DB.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &Tx{Tx: tx, db: d}, nil
+}
+
+func (t *Tx) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	start := time.Now()
+	rows, err := t.Tx.QueryContext(ctx, query, args...)
+	t.db.observe(ctx, query, args, time.Since(start))
+	return rows, err
+}
+
+func (t *Tx) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	start := time.Now()
+	row := t.Tx.QueryRowContext(ctx, query, args...)
+	t.db.observe(ctx, query, args, time.Since(start))
+	return row
+}
+
+func (t *Tx) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	start := time.Now()
+	result, err := t.Tx.ExecContext(ctx, query, args...)
+	t.db.observe(ctx, query, args, time.Since(start))
+	return result, err
+}
+
+func (d *DB) observe(ctx context.Context, query string, args []interface{}, elapsed time.Duration) {
+	recordQuery(ctx, elapsed)
+	if d.slowThreshold <= 0 || elapsed < d.slowThreshold {
+		return
+	}
+	d.logger.Warn("Slow query",
+		zap.String("endpoint", EndpointFromContext(ctx)),
+		zap.Duration("duration", elapsed),
+		zap.Duration("threshold", d.slowThreshold),
+		zap.String("sql", sanitizeSQL(query)),
+		zap.Strings("params", sanitizeArgs(args)),
+	)
+}
+
+// sanitizeSQL collapses whitespace so multi-line statements log on one line
+func sanitizeSQL(query string) string {
+	return strings.Join(strings.Fields(query), " ")
+}
+
+// sanitizeArgs describes parameters for logging by their type, and length
+// for strings, byte slices and other collections, never their values: they
+// include card numbers, emails and member ids
+func sanitizeArgs(args []interface{}) []string {
+	out := make([]string, len(args))
+	for i, arg := range args {
+		if arg == nil {
+			out[i] = "<nil>"
+			continue
+		}
+		v := reflect.ValueOf(arg)
+		switch v.Kind() {
+		case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+			out[i] = fmt.Sprintf("%T(len=%d)", arg, v.Len())
+		default:
+			out[i] = fmt.Sprintf("%T", arg)
+		}
+	}
+	return out
+}
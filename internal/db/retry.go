@@ -0,0 +1,118 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"math/rand"
+	"public_library/internal/config"
+	"syscall"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	retryAttempts = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "db_retry_attempts_total",
+		Help: "Number of times a database operation was retried after a transient error.",
+	}, []string{"operation"})
+
+	retryExhausted = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "db_retry_exhausted_total",
+		Help: "Number of database operations that still failed after all retry attempts.",
+	}, []string{"operation"})
+)
+
+// Retrier re-runs idempotent database operations that fail with a transient error
+type Retrier struct {
+	cfg config.RetryConfig
+}
+
+func NewRetrier(cfg config.RetryConfig) *Retrier {
+	if cfg.MaxAttempts < 1 {
+		cfg.MaxAttempts = 1
+	}
+	return &Retrier{cfg: cfg}
+}
+
+// Do runs fn, retrying with jittered exponential backoff while it returns a
+// retryable error. Only pass operations that are safe to run more than once.
+func (r *Retrier) Do(ctx context.Context, operation string, fn func() error) error {
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = fn()
+		if err == nil || !IsRetryable(err) {
+			return err
+		}
+		if attempt >= r.cfg.MaxAttempts {
+			if r.cfg.MaxAttempts > 1 {
+				retryExhausted.WithLabelValues(operation).Inc()
+			}
+			return err
+		}
+
+		delay := r.backoff(attempt)
+		log.Printf("Retrying %s after transient error (attempt %d/%d, wait %s): %v",
+			operation, attempt+1, r.cfg.MaxAttempts, delay, err)
+		retryAttempts.WithLabelValues(operation).Inc()
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+	}
+}
+
+// backoff returns a random delay in [0, min(MaxDelay, BaseDelay*2^(attempt-1))]
+func (r *Retrier) backoff(attempt int) time.Duration {
+	d := r.cfg.BaseDelay << (attempt - 1)
+	if r.cfg.MaxDelay > 0 && (d > r.cfg.MaxDelay || d <= 0) {
+		d = r.cfg.MaxDelay
+	}
+	if d <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(d) + 1))
+}
+
+// IsStillReferenced reports whether err is a delete refused by the foreign
+// key constraint, such as loans_acquisition_id_fkey, still referencing the row
+func IsStillReferenced(err error, constraint string) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23503" && pgErr.ConstraintName == constraint // foreign_key_violation
+}
+
+// IsRetryable reports whether err is a transient failure worth retrying:
+// serialization failures, deadlocks, connection exceptions and resets.
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case "40001", // serialization_failure
+			"40P01", // deadlock_detected
+			"57P01", // admin_shutdown
+			"53300": // too_many_connections
+			return true
+		}
+		// Class 08 - connection exception
+		return len(pgErr.Code) == 5 && pgErr.Code[:2] == "08"
+	}
+
+	if pgconn.SafeToRetry(err) {
+		return true
+	}
+
+	return errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
@@ -0,0 +1,852 @@
+package db
+
+// schema is applied in order at startup. Every statement must be idempotent.
+var schema = []string{
+	`CREATE TABLE IF NOT EXISTS books (
+		id SERIAL PRIMARY KEY,
+		title TEXT NOT NULL,
+		author TEXT NOT NULL
+	)`,
+	`ALTER TABLE books
+		ADD COLUMN IF NOT EXISTS call_number TEXT NOT NULL DEFAULT '',
+		ADD COLUMN IF NOT EXISTS call_number_sort TEXT NOT NULL DEFAULT '',
+		ADD COLUMN IF NOT EXISTS shelf_location TEXT NOT NULL DEFAULT '',
+		ADD COLUMN IF NOT EXISTS collection TEXT NOT NULL DEFAULT ''`,
+	`CREATE INDEX IF NOT EXISTS books_call_number_sort_idx ON books (call_number_sort)`,
+	`ALTER TABLE books
+		ADD COLUMN IF NOT EXISTS publication_year INT,
+		ADD COLUMN IF NOT EXISTS edition TEXT NOT NULL DEFAULT '',
+		ADD COLUMN IF NOT EXISTS page_count INT,
+		ADD COLUMN IF NOT EXISTS format TEXT NOT NULL DEFAULT ''`,
+	`CREATE INDEX IF NOT EXISTS books_publication_year_idx ON books (publication_year)`,
+
+	// A book carries any number of typed identifiers, each unique per type
+	`CREATE TABLE IF NOT EXISTS book_identifiers (
+		id SERIAL PRIMARY KEY,
+		book_id INT NOT NULL REFERENCES books (id) ON DELETE CASCADE,
+		type TEXT NOT NULL CHECK (type IN ('isbn10', 'isbn13', 'issn', 'oclc')),
+		value TEXT NOT NULL,
+		UNIQUE (type, value)
+	)`,
+	`CREATE INDEX IF NOT EXISTS book_identifiers_book_id_idx ON book_identifiers (book_id)`,
+	// Move the legacy single isbn column into book_identifiers, once
+	`DO $$
+	BEGIN
+		IF EXISTS (
+			SELECT 1 FROM information_schema.columns
+			WHERE table_schema = current_schema() AND table_name = 'books' AND column_name = 'isbn'
+		) THEN
+			INSERT INTO book_identifiers (book_id, type, value)
+			SELECT id,
+				CASE WHEN length(v) = 10 THEN 'isbn10' ELSE 'isbn13' END,
+				v
+			FROM (
+				SELECT id, upper(regexp_replace(isbn, '[^0-9Xx]', '', 'g')) AS v
+				FROM books
+			) normalized
+			WHERE v <> ''
+			ON CONFLICT (type, value) DO NOTHING;
+			ALTER TABLE books DROP COLUMN isbn;
+		END IF;
+	END
+	$$`,
+	// isbn_as_13 converts an ISBN-10 to its ISBN-13 form so both forms of the
+	// same ISBN compare equal; other identifier types map to NULL
+	`CREATE OR REPLACE FUNCTION isbn_as_13(id_type TEXT, id_value TEXT) RETURNS TEXT
+	LANGUAGE sql IMMUTABLE AS $$
+		SELECT CASE id_type
+			WHEN 'isbn13' THEN id_value
+			WHEN 'isbn10' THEN '978' || left(id_value, 9) || ((10 - (38 + (
+				SELECT sum(substr(id_value, i, 1)::int * CASE WHEN i % 2 = 0 THEN 1 ELSE 3 END)
+				FROM generate_series(1, 9) AS i
+			)) % 10) % 10)::text
+		END
+	$$`,
+	`ALTER TABLE book_identifiers
+		ADD COLUMN IF NOT EXISTS normalized_isbn TEXT GENERATED ALWAYS AS (isbn_as_13(type, value)) STORED`,
+	// A normalized ISBN may be held by one book only. The same book may list
+	// both the ISBN-10 and ISBN-13 forms, so this is an exclusion constraint
+	// rather than a plain unique index.
+	`CREATE EXTENSION IF NOT EXISTS btree_gist`,
+	`DO $$
+	BEGIN
+		IF NOT EXISTS (
+			SELECT 1 FROM pg_constraint
+			WHERE conname = 'book_identifiers_isbn_excl' AND connamespace = current_schema()::regnamespace
+		) THEN
+			ALTER TABLE book_identifiers ADD CONSTRAINT book_identifiers_isbn_excl
+				EXCLUDE USING gist (normalized_isbn WITH =, book_id WITH <>)
+				WHERE (normalized_isbn IS NOT NULL);
+		END IF;
+	END
+	$$`,
+
+	`CREATE TABLE IF NOT EXISTS series (
+		id SERIAL PRIMARY KEY,
+		title TEXT NOT NULL,
+		description TEXT NOT NULL DEFAULT ''
+	)`,
+	`ALTER TABLE books
+		ADD COLUMN IF NOT EXISTS series_id INT REFERENCES series (id) ON DELETE SET NULL,
+		ADD COLUMN IF NOT EXISTS series_position DOUBLE PRECISION`,
+	`CREATE INDEX IF NOT EXISTS books_series_idx ON books (series_id, series_position)`,
+	`ALTER TABLE books
+		ADD COLUMN IF NOT EXISTS audience TEXT NOT NULL DEFAULT 'adult'
+		CHECK (audience IN ('children', 'teen', 'adult'))`,
+	`CREATE INDEX IF NOT EXISTS books_audience_idx ON books (audience)`,
+	// schema.org accessibilityFeature terms; lists filter with @>, hence GIN
+	`ALTER TABLE books ADD COLUMN IF NOT EXISTS accessibility_features TEXT[] NOT NULL DEFAULT '{}'`,
+	`CREATE INDEX IF NOT EXISTS books_accessibility_idx ON books USING GIN (accessibility_features)`,
+	`ALTER TABLE books ADD COLUMN IF NOT EXISTS description TEXT NOT NULL DEFAULT ''`,
+	// language is the BCP 47 tag of the title and description as catalogued;
+	// empty when unknown
+	`ALTER TABLE books ADD COLUMN IF NOT EXISTS language TEXT NOT NULL DEFAULT ''`,
+	// sort_title is the title without its leading article, written with the
+	// book; lists sort it with the ICU collation of catalog.sort_locale
+	`ALTER TABLE books ADD COLUMN IF NOT EXISTS sort_title TEXT NOT NULL DEFAULT ''`,
+	// romanized is the catalogued Latin-script title and author, e.g. pinyin;
+	// search_latin is the lower-case romanization of title, author and
+	// romanized that search matches in either script, written with the book
+	`ALTER TABLE books ADD COLUMN IF NOT EXISTS romanized TEXT NOT NULL DEFAULT ''`,
+	`ALTER TABLE books ADD COLUMN IF NOT EXISTS search_latin TEXT NOT NULL DEFAULT ''`,
+	// Titles and descriptions in other languages, chosen by Accept-Language
+	`CREATE TABLE IF NOT EXISTS book_translations (
+		book_id INT NOT NULL REFERENCES books (id) ON DELETE CASCADE,
+		language TEXT NOT NULL,
+		title TEXT NOT NULL,
+		description TEXT NOT NULL DEFAULT '',
+		PRIMARY KEY (book_id, language)
+	)`,
+	// Full-text search weights title matches above author, and author above description
+	`ALTER TABLE books ADD COLUMN IF NOT EXISTS search_vector tsvector
+		GENERATED ALWAYS AS (
+			setweight(to_tsvector('english', title), 'A') ||
+			setweight(to_tsvector('english', author), 'B') ||
+			setweight(to_tsvector('english', description), 'C')
+		) STORED`,
+	`CREATE INDEX IF NOT EXISTS books_search_idx ON books USING GIN (search_vector)`,
+	// Trigram indexes serve substring (ILIKE) search on title and author
+	`CREATE EXTENSION IF NOT EXISTS pg_trgm`,
+	`CREATE INDEX IF NOT EXISTS books_title_trgm_idx ON books USING GIN (title gin_trgm_ops)`,
+	`CREATE INDEX IF NOT EXISTS books_author_trgm_idx ON books USING GIN (author gin_trgm_ops)`,
+	`CREATE INDEX IF NOT EXISTS books_search_latin_trgm_idx ON books USING GIN (search_latin gin_trgm_ops)`,
+	// phonetic_codes lists the Double Metaphone codes, primary and alternate,
+	// of each word of two or more letters, so spellings of a name that sound
+	// alike share a code: Dostoyevsky and Dostoevski are both TSTF
+	`CREATE EXTENSION IF NOT EXISTS fuzzystrmatch`,
+	`CREATE OR REPLACE FUNCTION phonetic_codes(s TEXT) RETURNS TEXT[]
+	LANGUAGE sql IMMUTABLE AS $$
+		SELECT COALESCE(array_agg(DISTINCT c), '{}')
+		FROM regexp_split_to_table(lower(s), '[^[:alpha:]]+') AS w,
+			LATERAL (VALUES (dmetaphone(w)), (dmetaphone_alt(w))) AS codes (c)
+		WHERE length(w) > 1 AND c <> ''
+	$$`,
+	`ALTER TABLE books ADD COLUMN IF NOT EXISTS author_phonetic TEXT[]
+		GENERATED ALWAYS AS (phonetic_codes(author)) STORED`,
+	`CREATE INDEX IF NOT EXISTS books_author_phonetic_idx ON books USING GIN (author_phonetic)`,
+
+	`CREATE TABLE IF NOT EXISTS book_assets (
+		id SERIAL PRIMARY KEY,
+		book_id INT NOT NULL REFERENCES books (id) ON DELETE CASCADE,
+		filename TEXT NOT NULL,
+		content_type TEXT NOT NULL,
+		size_bytes BIGINT NOT NULL,
+		storage_path TEXT NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`,
+	`CREATE INDEX IF NOT EXISTS book_assets_book_id_idx ON book_assets (book_id)`,
+
+	// Per-API-key overrides of the default rate limit, edited through
+	// /admin/rate-limits; 0 means unlimited
+	`CREATE TABLE IF NOT EXISTS api_key_limits (
+		api_key_id TEXT PRIMARY KEY,
+		requests_per_minute INT NOT NULL CHECK (requests_per_minute >= 0),
+		burst INT NOT NULL DEFAULT 0 CHECK (burst >= 0),
+		daily_quota INT NOT NULL DEFAULT 0 CHECK (daily_quota >= 0),
+		updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`,
+
+	// Request counts per API key, day (UTC) and endpoint, added to by the
+	// usage recorder's periodic flush
+	`CREATE TABLE IF NOT EXISTS api_key_usage (
+		api_key_id TEXT NOT NULL,
+		day DATE NOT NULL,
+		endpoint TEXT NOT NULL,
+		requests BIGINT NOT NULL DEFAULT 0,
+		client_errors BIGINT NOT NULL DEFAULT 0,
+		server_errors BIGINT NOT NULL DEFAULT 0,
+		PRIMARY KEY (api_key_id, day, endpoint)
+	)`,
+
+	// Members' shelves and ratings of catalog titles. Members live in the
+	// identity system in front of the API, so member_id is its opaque ID.
+	`CREATE TABLE IF NOT EXISTS reading_list_entries (
+		member_id TEXT NOT NULL,
+		book_id INT NOT NULL REFERENCES books (id) ON DELETE CASCADE,
+		shelf TEXT NOT NULL CHECK (shelf IN ('read', 'currently-reading', 'to-read', 'did-not-finish')),
+		rating NUMERIC(3, 2) CHECK (rating > 0 AND rating <= 5),
+		date_read DATE,
+		updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		PRIMARY KEY (member_id, book_id)
+	)`,
+	`CREATE INDEX IF NOT EXISTS reading_list_entries_book_id_idx ON reading_list_entries (book_id)`,
+
+	// One row per ILS provider: where the next incremental sync starts and
+	// the report of the last run
+	`CREATE TABLE IF NOT EXISTS ils_sync_state (
+		provider TEXT PRIMARY KEY,
+		synced_through TIMESTAMPTZ,
+		last_report JSONB,
+		updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`,
+
+	// One row per purchased copy. Prices keep the currency they were paid in
+	// and are only converted when reported.
+	`CREATE TABLE IF NOT EXISTS acquisitions (
+		id SERIAL PRIMARY KEY,
+		book_id INT NOT NULL REFERENCES books (id) ON DELETE CASCADE,
+		price NUMERIC NOT NULL CHECK (price >= 0),
+		currency CHAR(3) NOT NULL,
+		funding_source TEXT NOT NULL DEFAULT '',
+		acquired_on DATE NOT NULL DEFAULT CURRENT_DATE,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`,
+	`CREATE INDEX IF NOT EXISTS acquisitions_book_id_idx ON acquisitions (book_id)`,
+	`CREATE INDEX IF NOT EXISTS acquisitions_acquired_on_idx ON acquisitions (acquired_on)`,
+	// Copies are held at a branch. Insurance valuations use the replacement
+	// cost, or the price paid when none is recorded.
+	`ALTER TABLE acquisitions
+		ADD COLUMN IF NOT EXISTS branch TEXT NOT NULL DEFAULT '',
+		ADD COLUMN IF NOT EXISTS replacement_cost NUMERIC CHECK (replacement_cost >= 0)`,
+	// Circulation counts drive weeding; withdrawn copies stay on record for
+	// the disposal report but are no longer part of the collection.
+	`ALTER TABLE acquisitions
+		ADD COLUMN IF NOT EXISTS checkouts INT NOT NULL DEFAULT 0,
+		ADD COLUMN IF NOT EXISTS last_checkout_on DATE,
+		ADD COLUMN IF NOT EXISTS withdrawn_at TIMESTAMPTZ`,
+	// RFID tags identify copies to self-check machines and security gates.
+	// A copy can carry several tags, e.g. one per disc of a set; uid is the
+	// tag's upper-case hex UID.
+	`CREATE TABLE IF NOT EXISTS rfid_tags (
+		uid TEXT PRIMARY KEY,
+		acquisition_id INT NOT NULL REFERENCES acquisitions (id) ON DELETE CASCADE,
+		encoded_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`,
+	`CREATE INDEX IF NOT EXISTS rfid_tags_acquisition_idx ON rfid_tags (acquisition_id)`,
+	// Copies are scanned at the circulation desk by their item barcode
+	`ALTER TABLE acquisitions ADD COLUMN IF NOT EXISTS barcode TEXT UNIQUE`,
+	// Self-check kiosks and gate readers register by serial and send
+	// heartbeats; silent_alerted_at is set once a silence has been alerted
+	// and cleared by the next heartbeat
+	`CREATE TABLE IF NOT EXISTS devices (
+		id SERIAL PRIMARY KEY,
+		serial TEXT NOT NULL UNIQUE,
+		kind TEXT NOT NULL CHECK (kind IN ('kiosk', 'gate')),
+		name TEXT NOT NULL,
+		branch TEXT NOT NULL DEFAULT '',
+		location TEXT NOT NULL DEFAULT '',
+		version TEXT NOT NULL DEFAULT '',
+		last_seen_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		silent_alerted_at TIMESTAMPTZ,
+		registered_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`,
+	`CREATE TABLE IF NOT EXISTS device_errors (
+		id BIGSERIAL PRIMARY KEY,
+		device_id INT NOT NULL REFERENCES devices (id) ON DELETE CASCADE,
+		code TEXT NOT NULL,
+		message TEXT NOT NULL DEFAULT '',
+		reported_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`,
+	`CREATE INDEX IF NOT EXISTS device_errors_device_idx ON device_errors (device_id, reported_at)`,
+	`CREATE INDEX IF NOT EXISTS device_errors_reported_idx ON device_errors (reported_at)`,
+	// Purchase orders are traded with vendors over EDIFACT. Lines copy the
+	// book's ISBN, title and author as they were ordered; quoted and invoiced
+	// figures are filled in from the vendor's QUOTES and INVOIC messages.
+	`CREATE TABLE IF NOT EXISTS purchase_orders (
+		id SERIAL PRIMARY KEY,
+		vendor TEXT NOT NULL,
+		vendor_id TEXT NOT NULL,
+		currency CHAR(3) NOT NULL,
+		funding_source TEXT NOT NULL DEFAULT '',
+		status TEXT NOT NULL DEFAULT 'open',
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		sent_at TIMESTAMPTZ
+	)`,
+	`CREATE INDEX IF NOT EXISTS purchase_orders_status_idx ON purchase_orders (status)`,
+	`CREATE TABLE IF NOT EXISTS purchase_order_lines (
+		order_id INT NOT NULL REFERENCES purchase_orders (id) ON DELETE CASCADE,
+		line_number INT NOT NULL,
+		book_id INT NOT NULL REFERENCES books (id),
+		isbn TEXT NOT NULL,
+		title TEXT NOT NULL,
+		author TEXT NOT NULL DEFAULT '',
+		quantity INT NOT NULL CHECK (quantity > 0),
+		price NUMERIC CHECK (price >= 0),
+		quoted_price NUMERIC,
+		invoiced_quantity INT NOT NULL DEFAULT 0,
+		invoiced_price NUMERIC,
+		PRIMARY KEY (order_id, line_number)
+	)`,
+	// Each vendor message is applied to an order once, however often the
+	// interchange carrying it is posted.
+	`CREATE TABLE IF NOT EXISTS edi_messages (
+		message_type TEXT NOT NULL,
+		reference TEXT NOT NULL,
+		order_id INT NOT NULL REFERENCES purchase_orders (id) ON DELETE CASCADE,
+		received_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		UNIQUE (message_type, reference, order_id)
+	)`,
+	`CREATE SEQUENCE IF NOT EXISTS edi_interchange_seq`,
+	// A weeding proposal snapshots the copies its criteria matched; reviewers
+	// mark candidates to keep, and approval withdraws the rest.
+	`CREATE TABLE IF NOT EXISTS weeding_proposals (
+		id SERIAL PRIMARY KEY,
+		criteria JSONB NOT NULL,
+		note TEXT NOT NULL DEFAULT '',
+		status TEXT NOT NULL DEFAULT 'proposed',
+		disposal TEXT NOT NULL DEFAULT '',
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		decided_at TIMESTAMPTZ
+	)`,
+	`CREATE TABLE IF NOT EXISTS weeding_candidates (
+		proposal_id INT NOT NULL REFERENCES weeding_proposals (id) ON DELETE CASCADE,
+		acquisition_id INT NOT NULL REFERENCES acquisitions (id) ON DELETE CASCADE,
+		decision TEXT NOT NULL DEFAULT 'withdraw' CHECK (decision IN ('withdraw', 'keep')),
+		note TEXT NOT NULL DEFAULT '',
+		PRIMARY KEY (proposal_id, acquisition_id)
+	)`,
+	`CREATE INDEX IF NOT EXISTS weeding_candidates_acquisition_id_idx ON weeding_candidates (acquisition_id)`,
+	// Damaged copies go out to a bindery or repair vendor; a copy is out, and
+	// doesn't circulate, until its repair has a return date.
+	`CREATE TABLE IF NOT EXISTS repairs (
+		id SERIAL PRIMARY KEY,
+		acquisition_id INT NOT NULL REFERENCES acquisitions (id) ON DELETE CASCADE,
+		kind TEXT NOT NULL CHECK (kind IN ('repair', 'binding')),
+		vendor TEXT NOT NULL,
+		damage TEXT NOT NULL DEFAULT '',
+		cost NUMERIC CHECK (cost >= 0),
+		currency TEXT NOT NULL DEFAULT '',
+		sent_on DATE NOT NULL DEFAULT CURRENT_DATE,
+		expected_on DATE NOT NULL,
+		returned_on DATE,
+		note TEXT NOT NULL DEFAULT '',
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`,
+	`CREATE UNIQUE INDEX IF NOT EXISTS repairs_out_idx ON repairs (acquisition_id) WHERE returned_on IS NULL`,
+	// Course reserves: copies an instructor puts on short loan for a course
+	// while it runs
+	`CREATE TABLE IF NOT EXISTS courses (
+		id SERIAL PRIMARY KEY,
+		code TEXT NOT NULL,
+		title TEXT NOT NULL,
+		instructor TEXT NOT NULL DEFAULT '',
+		term TEXT NOT NULL DEFAULT '',
+		starts_on DATE NOT NULL,
+		ends_on DATE NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		UNIQUE (term, code)
+	)`,
+	`CREATE TABLE IF NOT EXISTS course_reserves (
+		id SERIAL PRIMARY KEY,
+		course_id INT NOT NULL REFERENCES courses (id) ON DELETE CASCADE,
+		acquisition_id INT NOT NULL REFERENCES acquisitions (id) ON DELETE CASCADE,
+		loan_hours INT NOT NULL CHECK (loan_hours > 0),
+		max_renewals INT NOT NULL DEFAULT 0 CHECK (max_renewals >= 0),
+		note TEXT NOT NULL DEFAULT '',
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		UNIQUE (course_id, acquisition_id)
+	)`,
+	`CREATE INDEX IF NOT EXISTS course_reserves_acquisition_id_idx ON course_reserves (acquisition_id)`,
+	// Closure calendar: days a branch, or with an empty branch every branch,
+	// is closed
+	`CREATE TABLE IF NOT EXISTS closures (
+		id SERIAL PRIMARY KEY,
+		branch TEXT NOT NULL DEFAULT '',
+		starts_on DATE NOT NULL,
+		ends_on DATE NOT NULL,
+		reason TEXT NOT NULL DEFAULT '',
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		CHECK (ends_on >= starts_on)
+	)`,
+	`CREATE INDEX IF NOT EXISTS closures_branch_idx ON closures (branch, ends_on)`,
+	// Shelf map: the floor, aisle and side of each range of shelving at a
+	// branch, and the call numbers it holds between first_sort and
+	// last_sort (call number sort keys, last inclusive as a prefix)
+	`CREATE TABLE IF NOT EXISTS shelf_ranges (
+		id SERIAL PRIMARY KEY,
+		branch TEXT NOT NULL,
+		collection TEXT NOT NULL DEFAULT '',
+		floor TEXT NOT NULL DEFAULT '',
+		aisle TEXT NOT NULL DEFAULT '',
+		side TEXT NOT NULL DEFAULT '' CHECK (side IN ('', 'left', 'right')),
+		unit TEXT NOT NULL DEFAULT '',
+		first_call_number TEXT NOT NULL,
+		last_call_number TEXT NOT NULL,
+		first_sort TEXT NOT NULL,
+		last_sort TEXT NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`,
+	`CREATE INDEX IF NOT EXISTS shelf_ranges_branch_idx ON shelf_ranges (branch, first_sort)`,
+	// Circulation: members, keyed like reading lists by the identity system's
+	// member ID, their loans of copies and their holds on books. A copy has
+	// at most one loan out and a member one waiting hold per book.
+	`CREATE TABLE IF NOT EXISTS members (
+		id TEXT PRIMARY KEY,
+		card_number TEXT UNIQUE,
+		name TEXT NOT NULL,
+		email TEXT NOT NULL DEFAULT '',
+		patron_group TEXT NOT NULL CHECK (patron_group IN ('child', 'adult', 'senior', 'student', 'staff')),
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`,
+	// Guest accounts expire at expires_at; other members have none
+	`ALTER TABLE members ADD COLUMN IF NOT EXISTS expires_at TIMESTAMPTZ`,
+	`ALTER TABLE members DROP CONSTRAINT IF EXISTS members_patron_group_check,
+		ADD CONSTRAINT members_patron_group_check
+			CHECK (patron_group IN ('child', 'adult', 'senior', 'student', 'staff', 'guest'))`,
+	// Members opt in to having their loans renewed automatically
+	`ALTER TABLE members ADD COLUMN IF NOT EXISTS auto_renew BOOLEAN NOT NULL DEFAULT false`,
+	// A suspended member can't check out, renew or place holds until
+	// suspended_until, or with none until the suspension is lifted
+	`ALTER TABLE members
+		ADD COLUMN IF NOT EXISTS suspension_reason TEXT NOT NULL DEFAULT '',
+		ADD COLUMN IF NOT EXISTS suspended_at TIMESTAMPTZ,
+		ADD COLUMN IF NOT EXISTS suspended_until TIMESTAMPTZ`,
+	// Family links let a guardian see and manage a child member's loans and
+	// holds, as far as each link's flags allow
+	`CREATE TABLE IF NOT EXISTS member_links (
+		guardian_id TEXT NOT NULL REFERENCES members (id) ON DELETE CASCADE,
+		child_id TEXT NOT NULL REFERENCES members (id) ON DELETE CASCADE,
+		view_loans BOOLEAN NOT NULL DEFAULT false,
+		view_history BOOLEAN NOT NULL DEFAULT false,
+		view_holds BOOLEAN NOT NULL DEFAULT false,
+		manage BOOLEAN NOT NULL DEFAULT false,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		PRIMARY KEY (guardian_id, child_id),
+		CHECK (guardian_id <> child_id)
+	)`,
+	`CREATE INDEX IF NOT EXISTS member_links_child_id_idx ON member_links (child_id)`,
+	`CREATE TABLE IF NOT EXISTS loans (
+		id SERIAL PRIMARY KEY,
+		member_id TEXT NOT NULL REFERENCES members (id),
+		acquisition_id INT NOT NULL REFERENCES acquisitions (id) ON DELETE RESTRICT,
+		course_reserve_id INT REFERENCES course_reserves (id) ON DELETE SET NULL,
+		checked_out_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		due_at TIMESTAMPTZ NOT NULL,
+		renewals INT NOT NULL DEFAULT 0,
+		max_renewals INT NOT NULL CHECK (max_renewals >= 0),
+		returned_at TIMESTAMPTZ,
+		fine NUMERIC CHECK (fine >= 0),
+		currency TEXT NOT NULL DEFAULT ''
+	)`,
+	// Anonymized loans keep their copy and dates for circulation counts but
+	// no longer name the member; members opt in with anonymize_loans
+	`ALTER TABLE loans ALTER COLUMN member_id DROP NOT NULL`,
+	`ALTER TABLE members ADD COLUMN IF NOT EXISTS anonymize_loans BOOLEAN NOT NULL DEFAULT false`,
+	`CREATE UNIQUE INDEX IF NOT EXISTS loans_out_idx ON loans (acquisition_id) WHERE returned_at IS NULL`,
+	`CREATE INDEX IF NOT EXISTS loans_member_id_idx ON loans (member_id)`,
+	`CREATE TABLE IF NOT EXISTS holds (
+		id SERIAL PRIMARY KEY,
+		member_id TEXT NOT NULL REFERENCES members (id) ON DELETE CASCADE,
+		book_id INT NOT NULL REFERENCES books (id) ON DELETE CASCADE,
+		status TEXT NOT NULL DEFAULT 'waiting' CHECK (status IN ('waiting', 'fulfilled', 'cancelled')),
+		placed_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		closed_at TIMESTAMPTZ
+	)`,
+	`CREATE UNIQUE INDEX IF NOT EXISTS holds_waiting_idx ON holds (member_id, book_id) WHERE status = 'waiting'`,
+	`CREATE INDEX IF NOT EXISTS holds_book_id_idx ON holds (book_id) WHERE status = 'waiting'`,
+	// Holds are picked up at pickup_branch, or where their copy is checked
+	// in when none is given. A copy checked in while the book has waiting
+	// holds is trapped for the oldest one and sits on the pickup branch's
+	// hold shelf from shelved_at.
+	`ALTER TABLE holds
+		ADD COLUMN IF NOT EXISTS pickup_branch TEXT NOT NULL DEFAULT '',
+		ADD COLUMN IF NOT EXISTS acquisition_id INT REFERENCES acquisitions (id) ON DELETE SET NULL,
+		ADD COLUMN IF NOT EXISTS trapped_at TIMESTAMPTZ,
+		ADD COLUMN IF NOT EXISTS shelved_at TIMESTAMPTZ`,
+	`CREATE UNIQUE INDEX IF NOT EXISTS holds_trapped_idx ON holds (acquisition_id) WHERE status = 'waiting'`,
+	// Holds not picked up within circulation.hold_shelf_days of reaching the
+	// hold shelf expire, releasing their copy
+	`ALTER TABLE holds DROP CONSTRAINT IF EXISTS holds_status_check,
+		ADD CONSTRAINT holds_status_check CHECK (status IN ('waiting', 'fulfilled', 'cancelled', 'expired'))`,
+	`CREATE INDEX IF NOT EXISTS holds_shelved_idx ON holds (pickup_branch, shelved_at) WHERE status = 'waiting'`,
+	// A member can claim to have returned a loan still out. The claim stops
+	// its fine accruing and asks the copy's branch for a shelf check; staff
+	// resolve it as found, returning the loan as of the claim, or lost,
+	// withdrawing the copy and charging the member for it.
+	`ALTER TABLE loans
+		ADD COLUMN IF NOT EXISTS claimed_returned_at TIMESTAMPTZ,
+		ADD COLUMN IF NOT EXISTS claim_outcome TEXT CHECK (claim_outcome IN ('found', 'lost')),
+		ADD COLUMN IF NOT EXISTS replacement_charge NUMERIC CHECK (replacement_charge >= 0)`,
+	`CREATE TABLE IF NOT EXISTS shelf_checks (
+		id SERIAL PRIMARY KEY,
+		loan_id INT NOT NULL REFERENCES loans (id) ON DELETE CASCADE,
+		branch TEXT NOT NULL,
+		requested_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		checked_at TIMESTAMPTZ,
+		found BOOLEAN,
+		note TEXT NOT NULL DEFAULT ''
+	)`,
+	`CREATE INDEX IF NOT EXISTS shelf_checks_open_idx ON shelf_checks (branch, requested_at) WHERE checked_at IS NULL`,
+	// Fines are paid in person into a cash drawer: staff open a drawer
+	// session at a branch with its float, payments are taken against it,
+	// and closing it with the cash counted reconciles the drawer. Payments
+	// are in the circulation currency and count towards the member's
+	// balance, against one loan's charges or generally.
+	`CREATE TABLE IF NOT EXISTS drawer_sessions (
+		id SERIAL PRIMARY KEY,
+		branch TEXT NOT NULL,
+		staff TEXT NOT NULL,
+		currency TEXT NOT NULL,
+		opening_float NUMERIC NOT NULL CHECK (opening_float >= 0),
+		opened_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		closed_at TIMESTAMPTZ,
+		counted_cash NUMERIC CHECK (counted_cash >= 0),
+		note TEXT NOT NULL DEFAULT ''
+	)`,
+	`CREATE UNIQUE INDEX IF NOT EXISTS drawer_sessions_open_idx ON drawer_sessions (branch, staff) WHERE closed_at IS NULL`,
+	`CREATE TABLE IF NOT EXISTS payments (
+		id SERIAL PRIMARY KEY,
+		member_id TEXT NOT NULL REFERENCES members (id),
+		loan_id INT REFERENCES loans (id) ON DELETE RESTRICT,
+		session_id INT NOT NULL REFERENCES drawer_sessions (id),
+		method TEXT NOT NULL CHECK (method IN ('cash', 'card')),
+		amount NUMERIC NOT NULL CHECK (amount > 0),
+		currency TEXT NOT NULL,
+		reference TEXT NOT NULL DEFAULT '',
+		taken_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`,
+	`CREATE INDEX IF NOT EXISTS payments_member_id_idx ON payments (member_id)`,
+	`CREATE INDEX IF NOT EXISTS payments_session_id_idx ON payments (session_id)`,
+	// copy_events logs what happens to a copy besides its loans, holds and
+	// repairs, for its history: moves to another home branch and withdrawals,
+	// recorded by trigger whatever makes them, and transits after check-in
+	`CREATE TABLE IF NOT EXISTS copy_events (
+		id BIGSERIAL PRIMARY KEY,
+		acquisition_id INT NOT NULL REFERENCES acquisitions (id) ON DELETE CASCADE,
+		kind TEXT NOT NULL CHECK (kind IN ('moved', 'transit', 'withdrawn')),
+		from_branch TEXT NOT NULL DEFAULT '',
+		to_branch TEXT NOT NULL DEFAULT '',
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`,
+	`CREATE INDEX IF NOT EXISTS copy_events_acquisition_idx ON copy_events (acquisition_id, created_at)`,
+	`CREATE OR REPLACE FUNCTION log_copy_events() RETURNS trigger AS $$
+	BEGIN
+		IF NEW.branch IS DISTINCT FROM OLD.branch THEN
+			INSERT INTO copy_events (acquisition_id, kind, from_branch, to_branch)
+			VALUES (NEW.id, 'moved', OLD.branch, NEW.branch);
+		END IF;
+		IF NEW.withdrawn_at IS NOT NULL AND OLD.withdrawn_at IS NULL THEN
+			INSERT INTO copy_events (acquisition_id, kind, from_branch)
+			VALUES (NEW.id, 'withdrawn', NEW.branch);
+		END IF;
+		RETURN NEW;
+	END;
+	$$ LANGUAGE plpgsql`,
+	`CREATE OR REPLACE TRIGGER acquisitions_copy_events
+		AFTER UPDATE OF branch, withdrawn_at ON acquisitions
+		FOR EACH ROW EXECUTE FUNCTION log_copy_events()`,
+	// Consent documents are versioned per kind; the latest published version
+	// of a kind is current. consents records members accepting a version.
+	`CREATE TABLE IF NOT EXISTS consent_documents (
+		id SERIAL PRIMARY KEY,
+		kind TEXT NOT NULL,
+		version TEXT NOT NULL,
+		title TEXT NOT NULL DEFAULT '',
+		url TEXT NOT NULL DEFAULT '',
+		required BOOLEAN NOT NULL DEFAULT true,
+		published_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		UNIQUE (kind, version)
+	)`,
+	`CREATE TABLE IF NOT EXISTS consents (
+		member_id TEXT NOT NULL,
+		document_id INT NOT NULL REFERENCES consent_documents (id) ON DELETE CASCADE,
+		accepted_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		PRIMARY KEY (member_id, document_id)
+	)`,
+	// A member's saved searches alert them to books added after last_book_id
+	// that match query, the search and filters of a book list request
+	`CREATE TABLE IF NOT EXISTS saved_searches (
+		id SERIAL PRIMARY KEY,
+		member_id TEXT NOT NULL REFERENCES members (id) ON DELETE CASCADE,
+		name TEXT NOT NULL,
+		query JSONB NOT NULL,
+		last_book_id INT NOT NULL DEFAULT 0,
+		last_alerted_at TIMESTAMPTZ,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`,
+	`CREATE INDEX IF NOT EXISTS saved_searches_member_idx ON saved_searches (member_id)`,
+	// A member's reading challenge is their goal of books for a year; the
+	// books they return that year are logged toward it with source
+	// 'return', and the ones they read elsewhere are logged by hand
+	`CREATE TABLE IF NOT EXISTS reading_challenges (
+		id SERIAL PRIMARY KEY,
+		member_id TEXT NOT NULL REFERENCES members (id) ON DELETE CASCADE,
+		year INT NOT NULL,
+		goal INT NOT NULL CHECK (goal > 0),
+		hide_from_leaderboard BOOLEAN NOT NULL DEFAULT false,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		UNIQUE (member_id, year)
+	)`,
+	`CREATE INDEX IF NOT EXISTS reading_challenges_year_idx ON reading_challenges (year)`,
+	`CREATE TABLE IF NOT EXISTS challenge_books (
+		challenge_id INT NOT NULL REFERENCES reading_challenges (id) ON DELETE CASCADE,
+		book_id INT NOT NULL REFERENCES books (id) ON DELETE CASCADE,
+		completed_on DATE NOT NULL,
+		source TEXT NOT NULL CHECK (source IN ('return', 'manual')),
+		logged_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		PRIMARY KEY (challenge_id, book_id)
+	)`,
+	// Staff picks are lists of books curated by staff, shown publicly and in
+	// their feed once published. A book is on a list once, at its position.
+	`CREATE TABLE IF NOT EXISTS pick_lists (
+		id SERIAL PRIMARY KEY,
+		slug TEXT NOT NULL UNIQUE,
+		title TEXT NOT NULL,
+		description TEXT NOT NULL DEFAULT '',
+		published BOOLEAN NOT NULL DEFAULT false,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`,
+	`CREATE TABLE IF NOT EXISTS pick_list_items (
+		list_id INT NOT NULL REFERENCES pick_lists (id) ON DELETE CASCADE,
+		position INT NOT NULL,
+		book_id INT NOT NULL REFERENCES books (id) ON DELETE CASCADE,
+		note TEXT NOT NULL DEFAULT '',
+		PRIMARY KEY (list_id, position),
+		UNIQUE (list_id, book_id)
+	)`,
+	// The homepage carousel features a staff picks list or a title, one of
+	// them, on each day from starts_on through ends_on
+	`CREATE TABLE IF NOT EXISTS carousel_features (
+		id SERIAL PRIMARY KEY,
+		list_id INT REFERENCES pick_lists (id) ON DELETE CASCADE,
+		book_id INT REFERENCES books (id) ON DELETE CASCADE,
+		headline TEXT NOT NULL DEFAULT '',
+		position INT NOT NULL DEFAULT 0,
+		starts_on DATE NOT NULL,
+		ends_on DATE NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		CHECK ((list_id IS NULL) <> (book_id IS NULL)),
+		CHECK (ends_on >= starts_on)
+	)`,
+	`CREATE INDEX IF NOT EXISTS carousel_features_days_idx ON carousel_features (starts_on, ends_on)`,
+	// Members suggest titles for the library to buy; staff accept one by
+	// ordering it, recording the book and order, or reject it with a reason
+	`CREATE TABLE IF NOT EXISTS suggestions (
+		id SERIAL PRIMARY KEY,
+		member_id TEXT NOT NULL REFERENCES members (id) ON DELETE CASCADE,
+		title TEXT NOT NULL,
+		author TEXT NOT NULL DEFAULT '',
+		isbn TEXT NOT NULL DEFAULT '',
+		note TEXT NOT NULL DEFAULT '',
+		status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'accepted', 'rejected')),
+		reason TEXT NOT NULL DEFAULT '',
+		book_id INT REFERENCES books (id) ON DELETE SET NULL,
+		order_id INT REFERENCES purchase_orders (id) ON DELETE SET NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		decided_at TIMESTAMPTZ
+	)`,
+	`CREATE INDEX IF NOT EXISTS suggestions_member_idx ON suggestions (member_id)`,
+	`CREATE INDEX IF NOT EXISTS suggestions_status_idx ON suggestions (status, created_at)`,
+	// Suggestions of titles the catalog has are matched to them, with a hold
+	// for the member
+	`ALTER TABLE suggestions ADD COLUMN IF NOT EXISTS hold_id INT REFERENCES holds (id) ON DELETE SET NULL`,
+	`ALTER TABLE suggestions DROP CONSTRAINT IF EXISTS suggestions_status_check,
+		ADD CONSTRAINT suggestions_status_check CHECK (status IN ('pending', 'accepted', 'rejected', 'matched'))`,
+
+	// Anonymous catalog usage: daily counts with no member, API key or
+	// client behind them. Views keep the IDs of deleted books so a flush
+	// never fails on one; reports join them away.
+	`CREATE TABLE IF NOT EXISTS analytics_daily (
+		day DATE PRIMARY KEY,
+		searches BIGINT NOT NULL DEFAULT 0,
+		zero_result_searches BIGINT NOT NULL DEFAULT 0,
+		views BIGINT NOT NULL DEFAULT 0
+	)`,
+	`CREATE TABLE IF NOT EXISTS analytics_zero_results (
+		day DATE NOT NULL,
+		query TEXT NOT NULL,
+		searches BIGINT NOT NULL DEFAULT 0,
+		PRIMARY KEY (day, query)
+	)`,
+	`CREATE TABLE IF NOT EXISTS analytics_views (
+		day DATE NOT NULL,
+		book_id INT NOT NULL,
+		views BIGINT NOT NULL DEFAULT 0,
+		PRIMARY KEY (day, book_id)
+	)`,
+	// Members opt out of the counters with analytics_opt_out
+	`ALTER TABLE members ADD COLUMN IF NOT EXISTS analytics_opt_out BOOLEAN NOT NULL DEFAULT false`,
+
+	// A/B experiments, managed through /admin/experiments. Units are hashes
+	// of a member or API key; a unit keeps the variant of its first exposure.
+	`CREATE TABLE IF NOT EXISTS experiments (
+		key TEXT PRIMARY KEY,
+		description TEXT NOT NULL DEFAULT '',
+		variants JSONB NOT NULL,
+		running BOOLEAN NOT NULL DEFAULT false,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`,
+	`CREATE TABLE IF NOT EXISTS experiment_assignments (
+		experiment TEXT NOT NULL REFERENCES experiments (key) ON DELETE CASCADE,
+		unit TEXT NOT NULL,
+		variant TEXT NOT NULL,
+		exposed_at TIMESTAMPTZ NOT NULL,
+		PRIMARY KEY (experiment, unit)
+	)`,
+	`CREATE TABLE IF NOT EXISTS experiment_conversions (
+		experiment TEXT NOT NULL REFERENCES experiments (key) ON DELETE CASCADE,
+		unit TEXT NOT NULL,
+		metric TEXT NOT NULL,
+		variant TEXT NOT NULL,
+		events BIGINT NOT NULL,
+		first_at TIMESTAMPTZ NOT NULL,
+		last_at TIMESTAMPTZ NOT NULL,
+		PRIMARY KEY (experiment, unit, metric)
+	)`,
+	// Serials arrive as issues: a subscription's issues are predicted from
+	// its first issue and checked in or claimed as they fall due. Issues
+	// checked in without being predicted have no sequence.
+	`CREATE TABLE IF NOT EXISTS serials (
+		id SERIAL PRIMARY KEY,
+		title TEXT NOT NULL,
+		issn TEXT NOT NULL DEFAULT '',
+		publisher TEXT NOT NULL DEFAULT '',
+		frequency TEXT NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS serial_subscriptions (
+		id SERIAL PRIMARY KEY,
+		serial_id INT NOT NULL REFERENCES serials (id) ON DELETE CASCADE,
+		vendor TEXT NOT NULL DEFAULT '',
+		copies INT NOT NULL DEFAULT 1,
+		starts_on DATE NOT NULL,
+		ends_on DATE NOT NULL,
+		first_volume INT NOT NULL DEFAULT 0,
+		first_number INT NOT NULL DEFAULT 1,
+		first_issue_on DATE NOT NULL,
+		issues_per_volume INT NOT NULL DEFAULT 0,
+		claim_after_days INT NOT NULL DEFAULT 30,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)`,
+	`CREATE INDEX IF NOT EXISTS serial_subscriptions_serial_id_idx ON serial_subscriptions (serial_id)`,
+	`CREATE TABLE IF NOT EXISTS serial_issues (
+		id SERIAL PRIMARY KEY,
+		subscription_id INT NOT NULL REFERENCES serial_subscriptions (id) ON DELETE CASCADE,
+		sequence INT,
+		volume INT NOT NULL,
+		number INT NOT NULL,
+		expected_on DATE,
+		status TEXT NOT NULL DEFAULT 'expected' CHECK (status IN ('expected', 'received', 'claimed')),
+		received_on DATE,
+		copies_received INT NOT NULL DEFAULT 0,
+		claim_count INT NOT NULL DEFAULT 0,
+		last_claimed_on DATE,
+		note TEXT NOT NULL DEFAULT '',
+		UNIQUE (subscription_id, sequence)
+	)`,
+	`CREATE INDEX IF NOT EXISTS serial_issues_status_expected_on_idx ON serial_issues (status, expected_on)`,
+	// Subscriptions record what each period cost. A renewal reminder is sent
+	// once per subscription and notice period, whichever instance sends it.
+	`ALTER TABLE serial_subscriptions
+		ADD COLUMN IF NOT EXISTS cost NUMERIC CHECK (cost >= 0),
+		ADD COLUMN IF NOT EXISTS currency TEXT NOT NULL DEFAULT ''`,
+	`CREATE TABLE IF NOT EXISTS serial_renewal_reminders (
+		subscription_id INT NOT NULL REFERENCES serial_subscriptions (id) ON DELETE CASCADE,
+		days_before INT NOT NULL,
+		sent_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		PRIMARY KEY (subscription_id, days_before)
+	)`,
+	// Statistics are served from materialized views so dashboard requests
+	// don't scan the catalog; they are refreshed by the stats refresher.
+	// Each view has a unique index so it can be refreshed CONCURRENTLY.
+	`CREATE MATERIALIZED VIEW IF NOT EXISTS library_stats AS
+		SELECT
+			1 AS id,
+			COUNT(*) AS total_books,
+			COUNT(DISTINCT author) AS total_authors,
+			now() AS refreshed_at
+		FROM books`,
+	`CREATE UNIQUE INDEX IF NOT EXISTS library_stats_id_idx ON library_stats (id)`,
+	`CREATE MATERIALIZED VIEW IF NOT EXISTS author_stats AS
+		SELECT author, COUNT(*) AS book_count
+		FROM books
+		GROUP BY author`,
+	`CREATE UNIQUE INDEX IF NOT EXISTS author_stats_author_idx ON author_stats (author)`,
+
+	// audit_log is append-only and partitioned by month; partitions are
+	// created ahead of time and pruned by StartPartitionMaintenance. The
+	// default partition only catches rows if maintenance falls behind, and
+	// the next run moves them into their month's partition.
+	`CREATE TABLE IF NOT EXISTS audit_log (
+		id BIGSERIAL,
+		entity TEXT NOT NULL,
+		entity_id INT NOT NULL,
+		action TEXT NOT NULL,
+		data JSONB,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		PRIMARY KEY (id, created_at)
+	) PARTITION BY RANGE (created_at)`,
+	`CREATE TABLE IF NOT EXISTS audit_log_default PARTITION OF audit_log DEFAULT`,
+	`CREATE INDEX IF NOT EXISTS audit_log_entity_idx ON audit_log (entity, entity_id, created_at)`,
+	`CREATE OR REPLACE FUNCTION audit_books() RETURNS trigger AS $$
+	BEGIN
+		IF TG_OP = 'DELETE' THEN
+			INSERT INTO audit_log (entity, entity_id, action, data)
+			VALUES ('book', OLD.id, lower(TG_OP), to_jsonb(OLD));
+			RETURN OLD;
+		END IF;
+		INSERT INTO audit_log (entity, entity_id, action, data)
+		VALUES ('book', NEW.id, lower(TG_OP), to_jsonb(NEW));
+		RETURN NEW;
+	END;
+	$$ LANGUAGE plpgsql`,
+	`CREATE OR REPLACE TRIGGER books_audit
+		AFTER INSERT OR UPDATE OR DELETE ON books
+		FOR EACH ROW EXECUTE FUNCTION audit_books()`,
+
+	// loan_history keeps a row per returned loan for circulation reporting,
+	// partitioned by month of return like audit_log. It names no member, so
+	// anonymizing loans doesn't touch it and old months can be dropped.
+	`CREATE TABLE IF NOT EXISTS loan_history (
+		id BIGSERIAL,
+		loan_id INT NOT NULL,
+		acquisition_id INT NOT NULL,
+		book_id INT NOT NULL,
+		branch TEXT NOT NULL,
+		checked_out_at TIMESTAMPTZ NOT NULL,
+		due_at TIMESTAMPTZ NOT NULL,
+		returned_at TIMESTAMPTZ NOT NULL,
+		renewals INT NOT NULL,
+		fine NUMERIC,
+		currency TEXT NOT NULL,
+		PRIMARY KEY (id, returned_at)
+	) PARTITION BY RANGE (returned_at)`,
+	`CREATE TABLE IF NOT EXISTS loan_history_default PARTITION OF loan_history DEFAULT`,
+	`CREATE INDEX IF NOT EXISTS loan_history_book_id_idx ON loan_history (book_id, returned_at)`,
+	`CREATE OR REPLACE FUNCTION log_loan_history() RETURNS trigger AS $$
+	BEGIN
+		INSERT INTO loan_history (loan_id, acquisition_id, book_id, branch, checked_out_at, due_at, returned_at,
+			renewals, fine, currency)
+		SELECT NEW.id, NEW.acquisition_id, a.book_id, a.branch, NEW.checked_out_at, NEW.due_at, NEW.returned_at,
+			NEW.renewals, NEW.fine, NEW.currency
+		FROM acquisitions a WHERE a.id = NEW.acquisition_id;
+		RETURN NEW;
+	END;
+	$$ LANGUAGE plpgsql`,
+	`CREATE OR REPLACE TRIGGER loans_history
+		AFTER UPDATE OF returned_at ON loans
+		FOR EACH ROW WHEN (OLD.returned_at IS NULL AND NEW.returned_at IS NOT NULL)
+		EXECUTE FUNCTION log_loan_history()`,
+	// Loans returned before loan_history existed are copied in once. They
+	// land in the default partition until partition maintenance moves them
+	// into partitions for their months.
+	`INSERT INTO loan_history (loan_id, acquisition_id, book_id, branch, checked_out_at, due_at, returned_at,
+		renewals, fine, currency)
+	SELECT l.id, l.acquisition_id, a.book_id, a.branch, l.checked_out_at, l.due_at, l.returned_at,
+		l.renewals, l.fine, l.currency
+	FROM loans l JOIN acquisitions a ON a.id = l.acquisition_id
+	WHERE l.returned_at IS NOT NULL AND NOT EXISTS (SELECT 1 FROM loan_history)`,
+}
@@ -0,0 +1,189 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Mapping describes how an entity type is stored in a table keyed by a
+// SERIAL id column, so its repository can use Table for plain CRUD and only
+// write SQL for its special queries.
+type Mapping[T any] struct {
+	Table string
+	// Columns are selected in this order into the pointers returned by
+	// Fields. The first column must be "id".
+	Columns []string
+	Fields  func(*T) []interface{}
+	// Writable are written by Insert and Update, in the order of the values
+	// returned by Values. Database defaults fill every other column.
+	Writable []string
+	Values   func(*T) []interface{}
+	ID       func(*T) int
+}
+
+// Filter is one condition of a List or Count. Column must be one of the
+// mapping's Columns and Op one of =, <>, <, <=, >, >=, ILIKE or IN; IN takes a
+// slice and matches any of its elements.
+type Filter struct {
+	Column string
+	Op     string
+	Value  interface{}
+}
+
+// ListOptions filters, orders and paginates a List. OrderBy entries are
+// column names, optionally followed by " DESC". A zero Limit returns every
+// matching row.
+type ListOptions struct {
+	Filters []Filter
+	OrderBy []string
+	Limit   int
+	Offset  int
+}
+
+var filterOps = map[string]string{
+	"=": "=", "<>": "<>", "<": "<", "<=": "<=", ">": ">", ">=": ">=",
+	"ILIKE": "ILIKE", "IN": "= ANY",
+}
+
+// Table implements CRUD for one mapped entity type. Get, Update and Delete
+// return sql.ErrNoRows when no row has the id.
+type Table[T any] struct {
+	q Queryer
+	m Mapping[T]
+}
+
+func NewTable[T any](q Queryer, m Mapping[T]) *Table[T] {
+	return &Table[T]{q: q, m: m}
+}
+
+func (t *Table[T]) selectSQL() string {
+	return fmt.Sprintf(`SELECT %s FROM %s`, strings.Join(t.m.Columns, ", "), t.m.Table)
+}
+
+// Get loads the row with id
+func (t *Table[T]) Get(ctx context.Context, id int) (T, error) {
+	var v T
+	query := t.selectSQL() + ` WHERE id = $1`
+	err := t.q.QueryRowContext(ctx, query, id).Scan(t.m.Fields(&v)...)
+	return v, err
+}
+
+// List loads the rows matching opts
+func (t *Table[T]) List(ctx context.Context, opts ListOptions) ([]T, error) {
+	whereSQL, args, err := t.whereSQL(opts.Filters)
+	if err != nil {
+		return nil, err
+	}
+	orderSQL, err := t.orderSQL(opts.OrderBy)
+	if err != nil {
+		return nil, err
+	}
+	query := t.selectSQL() + whereSQL + orderSQL
+	if opts.Limit > 0 {
+		args = append(args, opts.Limit, opts.Offset)
+		query += fmt.Sprintf(` LIMIT $%d OFFSET $%d`, len(args)-1, len(args))
+	}
+
+	rows, err := t.q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []T{}
+	for rows.Next() {
+		var v T
+		if err := rows.Scan(t.m.Fields(&v)...); err != nil {
+			return nil, err
+		}
+		list = append(list, v)
+	}
+	return list, rows.Err()
+}
+
+// Count returns the number of rows matching filters
+func (t *Table[T]) Count(ctx context.Context, filters []Filter) (int64, error) {
+	whereSQL, args, err := t.whereSQL(filters)
+	if err != nil {
+		return 0, err
+	}
+	var n int64
+	query := fmt.Sprintf(`SELECT COUNT(*) FROM %s`, t.m.Table) + whereSQL
+	err = t.q.QueryRowContext(ctx, query, args...).Scan(&n)
+	return n, err
+}
+
+// Insert writes v as a new row and reloads it, so the id and any defaulted
+// columns are filled in
+func (t *Table[T]) Insert(ctx context.Context, v *T) error {
+	placeholders := make([]string, len(t.m.Writable))
+	for i := range placeholders {
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+	}
+	query := fmt.Sprintf(`INSERT INTO %s (%s) VALUES (%s) RETURNING %s`,
+		t.m.Table, strings.Join(t.m.Writable, ", "), strings.Join(placeholders, ", "),
+		strings.Join(t.m.Columns, ", "))
+	return t.q.QueryRowContext(ctx, query, t.m.Values(v)...).Scan(t.m.Fields(v)...)
+}
+
+// Update overwrites the writable columns of the row with v's id and reloads it
+func (t *Table[T]) Update(ctx context.Context, v *T) error {
+	assignments := make([]string, len(t.m.Writable))
+	for i, column := range t.m.Writable {
+		assignments[i] = fmt.Sprintf("%s = $%d", column, i+1)
+	}
+	query := fmt.Sprintf(`UPDATE %s SET %s WHERE id = $%d RETURNING %s`,
+		t.m.Table, strings.Join(assignments, ", "), len(t.m.Writable)+1,
+		strings.Join(t.m.Columns, ", "))
+	args := append(t.m.Values(v), t.m.ID(v))
+	return t.q.QueryRowContext(ctx, query, args...).Scan(t.m.Fields(v)...)
+}
+
+// Delete removes the row with id
+func (t *Table[T]) Delete(ctx context.Context, id int) error {
+	var deleted int
+	query := fmt.Sprintf(`DELETE FROM %s WHERE id = $1 RETURNING id`, t.m.Table)
+	return t.q.QueryRowContext(ctx, query, id).Scan(&deleted)
+}
+
+func (t *Table[T]) whereSQL(filters []Filter) (string, []interface{}, error) {
+	if len(filters) == 0 {
+		return "", nil, nil
+	}
+	clauses := make([]string, len(filters))
+	args := make([]interface{}, len(filters))
+	for i, f := range filters {
+		op, ok := filterOps[strings.ToUpper(f.Op)]
+		if !ok {
+			return "", nil, fmt.Errorf("%s: unsupported filter operator %q", t.m.Table, f.Op)
+		}
+		if !slices.Contains(t.m.Columns, f.Column) {
+			return "", nil, fmt.Errorf("%s: cannot filter by %q", t.m.Table, f.Column)
+		}
+		args[i] = f.Value
+		if op == "= ANY" {
+			clauses[i] = fmt.Sprintf("%s = ANY($%d)", f.Column, i+1)
+		} else {
+			clauses[i] = fmt.Sprintf("%s %s $%d", f.Column, op, i+1)
+		}
+	}
+	return " WHERE " + strings.Join(clauses, " AND "), args, nil
+}
+
+func (t *Table[T]) orderSQL(orderBy []string) (string, error) {
+	if len(orderBy) == 0 {
+		return " ORDER BY id", nil
+	}
+	terms := make([]string, len(orderBy))
+	for i, term := range orderBy {
+		column, direction, _ := strings.Cut(strings.TrimSpace(term), " ")
+		direction = strings.ToUpper(strings.TrimSpace(direction))
+		if !slices.Contains(t.m.Columns, column) || (direction != "" && direction != "ASC" && direction != "DESC") {
+			return "", fmt.Errorf("%s: cannot order by %q", t.m.Table, term)
+		}
+		terms[i] = strings.TrimSpace(column + " " + direction)
+	}
+	return " ORDER BY " + strings.Join(terms, ", "), nil
+}
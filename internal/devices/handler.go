@@ -0,0 +1,240 @@
+package devices
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"public_library/internal/httperr"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	svc    *Service
+	logger *zap.Logger
+}
+
+func NewHandler(svc *Service, l *zap.Logger) *Handler {
+	return &Handler{svc: svc, logger: l}
+}
+
+// POST /devices
+
+// RegisterDevice godoc
+// @Summary Register a kiosk or gate reader
+// @Description Registers the device by its serial, or updates the registration of a device already registered with it, keeping its id. Registering counts as a heartbeat.
+// @Tags devices
+// @Accept json
+// @Produce json
+// @Param device body Registration true "Device to register"
+// @Success 200 {object} Device "Registration updated"
+// @Success 201 {object} Device "Device registered"
+// @Failure 400 {object} map[string]string
+// @Router /devices [post]
+func (h *Handler) RegisterDevice(w http.ResponseWriter, r *http.Request) {
+	var reg Registration
+	if err := json.NewDecoder(r.Body).Decode(&reg); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	d, created, err := h.svc.Register(r.Context(), reg)
+	if err != nil {
+		h.writeError(w, "failed to register device", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if created {
+		w.WriteHeader(http.StatusCreated)
+	}
+	json.NewEncoder(w).Encode(d)
+}
+
+// POST /devices/{id}/heartbeat
+
+// SendHeartbeat godoc
+// @Summary Send a device heartbeat
+// @Description Records that the device is alive. A device without a heartbeat for devices.silent_after shows as silent and is alerted as device.silent.
+// @Tags devices
+// @Accept json
+// @Param id path int true "Device ID"
+// @Param heartbeat body Heartbeat false "Heartbeat"
+// @Success 204 "No Content"
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /devices/{id}/heartbeat [post]
+func (h *Handler) SendHeartbeat(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	var hb Heartbeat
+	if err := json.NewDecoder(r.Body).Decode(&hb); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if err := h.svc.Heartbeat(r.Context(), id, hb); err != nil {
+		h.writeError(w, "failed to record heartbeat", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// POST /devices/{id}/errors
+
+// ReportDeviceError godoc
+// @Summary Report a device error
+// @Description Records an error of the device, such as a printer jam or a failed reader, for the admin dashboard. Reporting an error counts as a heartbeat.
+// @Tags devices
+// @Accept json
+// @Produce json
+// @Param id path int true "Device ID"
+// @Param error body ErrorReport true "Error to report"
+// @Success 201 {object} DeviceError
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /devices/{id}/errors [post]
+func (h *Handler) ReportDeviceError(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	var report ErrorReport
+	if err := json.NewDecoder(r.Body).Decode(&report); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	e, err := h.svc.ReportError(r.Context(), id, report)
+	if err != nil {
+		h.writeError(w, "failed to report device error", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(e)
+}
+
+// GET /admin/devices?branch=Central&kind=kiosk&status=silent
+
+// GetDeviceDashboard godoc
+// @Summary Get the status of every device
+// @Description Lists the registered devices with their status, last heartbeat and last error, and counts how many are online and silent. The counts cover the branch and kind asked for, whatever the status filter.
+// @Tags admin
+// @Produce json
+// @Security AdminToken
+// @Param branch query string false "Branch"
+// @Param kind query string false "Kind" Enums(kiosk, gate)
+// @Param status query string false "Status" Enums(online, silent)
+// @Success 200 {object} Dashboard
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /admin/devices [get]
+func (h *Handler) GetDeviceDashboard(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	dash, err := h.svc.Dashboard(r.Context(), ListRequest{Branch: q.Get("branch"), Kind: q.Get("kind"), Status: q.Get("status")})
+	if err != nil {
+		h.writeError(w, "failed to list devices", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(dash)
+}
+
+// GET /admin/devices/{id}
+
+// GetDevice godoc
+// @Summary Get a device
+// @Tags admin
+// @Produce json
+// @Security AdminToken
+// @Param id path int true "Device ID"
+// @Success 200 {object} Device
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /admin/devices/{id} [get]
+func (h *Handler) GetDevice(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	d, err := h.svc.Get(r.Context(), id)
+	if err != nil {
+		h.writeError(w, "failed to get device", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(d)
+}
+
+// GET /admin/devices/{id}/errors
+
+// ListDeviceErrors godoc
+// @Summary List a device's errors
+// @Description Returns the last 100 errors the device reported, most recent first.
+// @Tags admin
+// @Produce json
+// @Security AdminToken
+// @Param id path int true "Device ID"
+// @Success 200 {array} DeviceError
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /admin/devices/{id}/errors [get]
+func (h *Handler) ListDeviceErrors(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	list, err := h.svc.ListErrors(r.Context(), id)
+	if err != nil {
+		h.writeError(w, "failed to list device errors", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// DELETE /admin/devices/{id}
+
+// DeleteDevice godoc
+// @Summary Remove a device from the registry
+// @Description Removes a decommissioned device and its errors. A device that registers again is added back.
+// @Tags admin
+// @Security AdminToken
+// @Param id path int true "Device ID"
+// @Success 204 "No Content"
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /admin/devices/{id} [delete]
+func (h *Handler) DeleteDevice(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	if err := h.svc.Delete(r.Context(), id); err != nil {
+		h.writeError(w, "failed to delete device", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func pathID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "invalid device ID", http.StatusBadRequest)
+		return 0, false
+	}
+	return id, true
+}
+
+func (h *Handler) writeError(w http.ResponseWriter, msg string, err error) {
+	switch {
+	case errors.Is(err, ErrInvalid):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, ErrNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	default:
+		httperr.Write(w, h.logger, msg, err)
+	}
+}
@@ -0,0 +1,90 @@
+package devices
+
+import "time"
+
+// Kinds of devices
+const (
+	// KindKiosk is a self-check kiosk
+	KindKiosk = "kiosk"
+	// KindGate is a security gate reader
+	KindGate = "gate"
+)
+
+var kinds = []string{KindKiosk, KindGate}
+
+// Device statuses. A device is silent once it has sent no heartbeat for
+// devices.silent_after.
+const (
+	StatusOnline = "online"
+	StatusSilent = "silent"
+)
+
+var statuses = []string{StatusOnline, StatusSilent}
+
+// Device is a registered kiosk or gate reader and its last known state
+type Device struct {
+	ID int `json:"id" example:"3"`
+	// Serial identifies the device across registrations
+	Serial     string    `json:"serial" example:"SC-2041-0193"`
+	Kind       string    `json:"kind" example:"kiosk" enums:"kiosk,gate"`
+	Name       string    `json:"name" example:"Central self-check 1"`
+	Branch     string    `json:"branch" example:"Central"`
+	Location   string    `json:"location" example:"Ground floor, by the entrance"`
+	Version    string    `json:"version" example:"4.2.1"`
+	Status     string    `json:"status" example:"online" enums:"online,silent"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	// SilentAlertedAt is when the device was alerted as silent; it is
+	// cleared by the next heartbeat
+	SilentAlertedAt *time.Time   `json:"silent_alerted_at,omitempty"`
+	LastError       *DeviceError `json:"last_error,omitempty"`
+	// RecentErrors counts the errors reported in the last 24 hours
+	RecentErrors int       `json:"recent_errors" example:"0"`
+	RegisteredAt time.Time `json:"registered_at"`
+}
+
+// Registration registers a device, or updates the registration of the
+// device with the serial
+type Registration struct {
+	Serial string `json:"serial" example:"SC-2041-0193"`
+	Kind   string `json:"kind" example:"kiosk" enums:"kiosk,gate"`
+	// Name defaults to the serial
+	Name     string `json:"name" example:"Central self-check 1"`
+	Branch   string `json:"branch" example:"Central"`
+	Location string `json:"location" example:"Ground floor, by the entrance"`
+	Version  string `json:"version" example:"4.2.1"`
+}
+
+// Heartbeat tells the API that a device is alive
+type Heartbeat struct {
+	// Version replaces the software version registered, when given
+	Version string `json:"version" example:"4.2.1"`
+}
+
+// DeviceError is an error a device reported
+type DeviceError struct {
+	ID         int64     `json:"id" example:"812"`
+	DeviceID   int       `json:"device_id" example:"3"`
+	Code       string    `json:"code" example:"printer_jam"`
+	Message    string    `json:"message" example:"Receipt printer paper jam"`
+	ReportedAt time.Time `json:"reported_at"`
+}
+
+// ErrorReport reports an error of a device
+type ErrorReport struct {
+	Code    string `json:"code" example:"printer_jam"`
+	Message string `json:"message" example:"Receipt printer paper jam"`
+}
+
+// ListRequest filters the devices of the dashboard
+type ListRequest struct {
+	Branch string
+	Kind   string
+	Status string
+}
+
+// Dashboard summarizes the devices for the admin dashboard
+type Dashboard struct {
+	Online  int      `json:"online" example:"14"`
+	Silent  int      `json:"silent" example:"1"`
+	Devices []Device `json:"devices"`
+}
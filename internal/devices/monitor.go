@@ -0,0 +1,62 @@
+package devices
+
+import (
+	"context"
+	"public_library/internal/eventbus"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// AlertSilent publishes an alert for every device that has gone silent since
+// the last check and returns how many were alerted. A device is alerted
+// once per silence; its next heartbeat clears the alert.
+func (s *Service) AlertSilent(ctx context.Context) (int, error) {
+	silent, err := s.repo.MarkSilent(ctx, s.cfg.SilentAfter)
+	if err != nil {
+		return 0, err
+	}
+	for i := range silent {
+		d := &silent[i]
+		d.Status = StatusSilent
+		s.bus.Publish(ctx, eventbus.DeviceSilent, d.ID, *d)
+	}
+	return len(silent), nil
+}
+
+// StartMonitor checks for silent devices at start and every check_interval
+// until ctx is cancelled, and prunes the errors older than error_retention.
+// A non-positive interval disables it.
+func (s *Service) StartMonitor(ctx context.Context, logger *zap.Logger) {
+	if s.cfg.CheckInterval <= 0 {
+		return
+	}
+	check := func() {
+		silent, err := s.AlertSilent(ctx)
+		if err != nil {
+			logger.Error("Device monitor failed", zap.Error(err))
+			return
+		}
+		if silent > 0 {
+			logger.Warn("Devices went silent", zap.Int("count", silent))
+		}
+		if s.cfg.ErrorRetention > 0 {
+			if _, err := s.repo.PruneErrors(ctx, time.Now().Add(-s.cfg.ErrorRetention)); err != nil {
+				logger.Error("Pruning device errors failed", zap.Error(err))
+			}
+		}
+	}
+	go func() {
+		check()
+		ticker := time.NewTicker(s.cfg.CheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				check()
+			}
+		}
+	}()
+}
@@ -0,0 +1,269 @@
+package devices
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"public_library/internal/db"
+	"public_library/utils"
+	"time"
+)
+
+// ErrNotFound is returned when no device has the requested id
+var ErrNotFound = errors.New("device not found")
+
+type Repository struct {
+	db *db.DB
+}
+
+func NewRepository(conn *db.DB) *Repository {
+	return &Repository{db: conn}
+}
+
+// selectDevicesSQL selects devices ("d") with their last error and how many
+// they reported in the last 24 hours
+const selectDevicesSQL = `
+	SELECT d.id, d.serial, d.kind, d.name, d.branch, d.location, d.version, d.last_seen_at,
+		d.silent_alerted_at, d.registered_at, e.id, e.code, e.message, e.reported_at,
+		(SELECT count(*) FROM %[2]s c WHERE c.device_id = d.id AND c.reported_at > now() - interval '24 hours')
+	FROM %[1]s d
+	LEFT JOIN LATERAL (
+		SELECT id, code, message, reported_at FROM %[2]s
+		WHERE device_id = d.id
+		ORDER BY reported_at DESC, id DESC
+		LIMIT 1
+	) e ON true
+`
+
+func selectDevices(where string) string {
+	return fmt.Sprintf(selectDevicesSQL, utils.DevicesTable, utils.DeviceErrorsTable) + where
+}
+
+func scanDevice(row interface{ Scan(...interface{}) error }) (Device, error) {
+	var d Device
+	var errID sql.NullInt64
+	var code, message sql.NullString
+	var reportedAt sql.NullTime
+	err := row.Scan(&d.ID, &d.Serial, &d.Kind, &d.Name, &d.Branch, &d.Location, &d.Version, &d.LastSeenAt,
+		&d.SilentAlertedAt, &d.RegisteredAt, &errID, &code, &message, &reportedAt, &d.RecentErrors)
+	if err != nil {
+		return d, err
+	}
+	if errID.Valid {
+		d.LastError = &DeviceError{ID: errID.Int64, DeviceID: d.ID, Code: code.String,
+			Message: message.String, ReportedAt: reportedAt.Time}
+	}
+	return d, nil
+}
+
+func (r *Repository) queryDevices(ctx context.Context, query string, args ...interface{}) ([]Device, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []Device{}
+	for rows.Next() {
+		d, err := scanDevice(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, d)
+	}
+	return list, rows.Err()
+}
+
+// Register records the device, or updates the registration of the device
+// with its serial, and reports whether it was new. Registering counts as a
+// heartbeat.
+func (r *Repository) Register(ctx context.Context, reg Registration) (*Device, bool, error) {
+	log.Println("<--------Register device starts-------->")
+	defer log.Println("<--------Register device ends-------->")
+
+	var id int
+	var created bool
+	query := fmt.Sprintf(`
+		INSERT INTO %s (serial, kind, name, branch, location, version) VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (serial) DO UPDATE SET kind = EXCLUDED.kind, name = EXCLUDED.name,
+			branch = EXCLUDED.branch, location = EXCLUDED.location, version = EXCLUDED.version,
+			last_seen_at = now(), silent_alerted_at = NULL
+		RETURNING id, xmax = 0
+	`, utils.DevicesTable)
+	err := r.db.QueryRowContext(ctx, query, reg.Serial, reg.Kind, reg.Name, reg.Branch, reg.Location,
+		reg.Version).Scan(&id, &created)
+	if err != nil {
+		log.Printf("Failed to register device %s: %v", reg.Serial, err)
+		return nil, false, err
+	}
+	d, err := r.GetByID(ctx, id)
+	if err != nil {
+		return nil, false, err
+	}
+	return d, created, nil
+}
+
+func (r *Repository) GetByID(ctx context.Context, id int) (*Device, error) {
+	d, err := scanDevice(r.db.QueryRowContext(ctx, selectDevices(`WHERE d.id = $1`), id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		log.Printf("Failed to get device id=%d: %v", id, err)
+		return nil, err
+	}
+	return &d, nil
+}
+
+// List returns the devices of the branch and kind, or of every branch and
+// kind, by branch and name
+func (r *Repository) List(ctx context.Context, branch, kind string) ([]Device, error) {
+	list, err := r.queryDevices(ctx, selectDevices(`
+		WHERE ($1 = '' OR d.branch = $1) AND ($2 = '' OR d.kind = $2)
+		ORDER BY d.branch, d.name, d.id
+	`), branch, kind)
+	if err != nil {
+		log.Printf("Failed to list devices: %v", err)
+		return nil, err
+	}
+	return list, nil
+}
+
+// Heartbeat records that the device is alive, clearing its silent alert.
+// An empty version keeps the registered one.
+func (r *Repository) Heartbeat(ctx context.Context, id int, version string) error {
+	query := fmt.Sprintf(`
+		UPDATE %s SET last_seen_at = now(), silent_alerted_at = NULL,
+			version = CASE WHEN $2 = '' THEN version ELSE $2 END
+		WHERE id = $1
+	`, utils.DevicesTable)
+	res, err := r.db.ExecContext(ctx, query, id, version)
+	if err != nil {
+		log.Printf("Failed to record heartbeat of device id=%d: %v", id, err)
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ReportError records an error of the device. A device reporting errors is
+// alive, so it counts as a heartbeat.
+func (r *Repository) ReportError(ctx context.Context, id int, report ErrorReport) (*DeviceError, error) {
+	log.Println("<--------Report device error starts-------->")
+	defer log.Println("<--------Report device error ends-------->")
+
+	e := &DeviceError{}
+	query := fmt.Sprintf(`
+		WITH d AS (
+			UPDATE %[1]s SET last_seen_at = now(), silent_alerted_at = NULL
+			WHERE id = $1
+			RETURNING id
+		)
+		INSERT INTO %[2]s (device_id, code, message)
+		SELECT id, $2, $3 FROM d
+		RETURNING id, device_id, code, message, reported_at
+	`, utils.DevicesTable, utils.DeviceErrorsTable)
+	err := r.db.QueryRowContext(ctx, query, id, report.Code, report.Message).
+		Scan(&e.ID, &e.DeviceID, &e.Code, &e.Message, &e.ReportedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		log.Printf("Failed to report error of device id=%d: %v", id, err)
+		return nil, err
+	}
+	return e, nil
+}
+
+// ListErrors returns the device's last errors, most recent first
+func (r *Repository) ListErrors(ctx context.Context, id, limit int) ([]DeviceError, error) {
+	query := fmt.Sprintf(`
+		SELECT id, device_id, code, message, reported_at FROM %s
+		WHERE device_id = $1
+		ORDER BY reported_at DESC, id DESC
+		LIMIT $2
+	`, utils.DeviceErrorsTable)
+	rows, err := r.db.QueryContext(ctx, query, id, limit)
+	if err != nil {
+		log.Printf("Failed to list errors of device id=%d: %v", id, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []DeviceError{}
+	for rows.Next() {
+		var e DeviceError
+		if err := rows.Scan(&e.ID, &e.DeviceID, &e.Code, &e.Message, &e.ReportedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, e)
+	}
+	return list, rows.Err()
+}
+
+// Delete removes the device and its errors, e.g. when it is decommissioned
+func (r *Repository) Delete(ctx context.Context, id int) error {
+	log.Println("<--------Delete device starts-------->")
+	defer log.Println("<--------Delete device ends-------->")
+
+	query := fmt.Sprintf(`DELETE FROM %s WHERE id = $1`, utils.DevicesTable)
+	res, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		log.Printf("Failed to delete device id=%d: %v", id, err)
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// MarkSilent marks the devices without a heartbeat for silentAfter as
+// alerted and returns them. Devices already alerted are left out, so each
+// silence is alerted once.
+func (r *Repository) MarkSilent(ctx context.Context, silentAfter time.Duration) ([]Device, error) {
+	query := fmt.Sprintf(`
+		UPDATE %s SET silent_alerted_at = now()
+		WHERE silent_alerted_at IS NULL AND last_seen_at < now() - make_interval(secs => $1)
+		RETURNING id
+	`, utils.DevicesTable)
+	rows, err := r.db.QueryContext(ctx, query, silentAfter.Seconds())
+	if err != nil {
+		log.Printf("Failed to mark silent devices: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil || len(ids) == 0 {
+		return nil, err
+	}
+	return r.queryDevices(ctx, selectDevices(`WHERE d.id = ANY($1) ORDER BY d.id`), ids)
+}
+
+// PruneErrors deletes the errors reported before the cutoff and returns how
+// many were deleted
+func (r *Repository) PruneErrors(ctx context.Context, before time.Time) (int64, error) {
+	query := fmt.Sprintf(`DELETE FROM %s WHERE reported_at < $1`, utils.DeviceErrorsTable)
+	res, err := r.db.ExecContext(ctx, query, before)
+	if err != nil {
+		log.Printf("Failed to prune device errors: %v", err)
+		return 0, err
+	}
+	return res.RowsAffected()
+}
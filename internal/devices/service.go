@@ -0,0 +1,154 @@
+// Package devices is the registry of self-check kiosks and security gate
+// readers. Devices register themselves, send heartbeats and report their
+// errors; the admin dashboard shows which are online, and a scheduled job
+// alerts on the event bus when one goes silent.
+package devices
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"public_library/internal/config"
+	"public_library/internal/eventbus"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ErrInvalid is wrapped by errors about the content of a request
+var ErrInvalid = errors.New("invalid device request")
+
+const (
+	maxTextLength    = 200
+	maxMessageLength = 1000
+	// maxErrors caps the errors listed for a device
+	maxErrors = 100
+)
+
+type Service struct {
+	repo *Repository
+	cfg  config.DevicesConfig
+	bus  *eventbus.Bus
+}
+
+// NewService creates the device registry. Devices going silent are published
+// on bus under eventbus.DeviceSilent.
+func NewService(repo *Repository, cfg config.DevicesConfig, bus *eventbus.Bus) *Service {
+	return &Service{repo: repo, cfg: cfg, bus: bus}
+}
+
+// Register validates reg and registers the device, or updates the
+// registration of the device with its serial. It reports whether the device
+// was new.
+func (s *Service) Register(ctx context.Context, reg Registration) (*Device, bool, error) {
+	reg.Serial = strings.TrimSpace(reg.Serial)
+	if reg.Serial == "" || utf8.RuneCountInString(reg.Serial) > maxTextLength {
+		return nil, false, fmt.Errorf("%w: serial is required and must be at most %d characters", ErrInvalid, maxTextLength)
+	}
+	reg.Kind = strings.ToLower(strings.TrimSpace(reg.Kind))
+	if !slices.Contains(kinds, reg.Kind) {
+		return nil, false, fmt.Errorf("%w: kind must be one of %s", ErrInvalid, strings.Join(kinds, ", "))
+	}
+	reg.Name = strings.TrimSpace(reg.Name)
+	if reg.Name == "" {
+		reg.Name = reg.Serial
+	}
+	reg.Branch = strings.TrimSpace(reg.Branch)
+	reg.Location = strings.TrimSpace(reg.Location)
+	reg.Version = strings.TrimSpace(reg.Version)
+	for _, v := range []string{reg.Name, reg.Branch, reg.Location, reg.Version} {
+		if utf8.RuneCountInString(v) > maxTextLength {
+			return nil, false, fmt.Errorf("%w: name, branch, location and version must be at most %d characters", ErrInvalid, maxTextLength)
+		}
+	}
+	d, created, err := s.repo.Register(ctx, reg)
+	if err != nil {
+		return nil, false, err
+	}
+	s.setStatus(d)
+	return d, created, nil
+}
+
+// Get returns a device and its status
+func (s *Service) Get(ctx context.Context, id int) (*Device, error) {
+	d, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	s.setStatus(d)
+	return d, nil
+}
+
+// Dashboard returns the devices matching req with their status, and how
+// many of them are online and silent
+func (s *Service) Dashboard(ctx context.Context, req ListRequest) (*Dashboard, error) {
+	req.Kind = strings.ToLower(req.Kind)
+	if req.Kind != "" && !slices.Contains(kinds, req.Kind) {
+		return nil, fmt.Errorf("%w: kind must be one of %s", ErrInvalid, strings.Join(kinds, ", "))
+	}
+	req.Status = strings.ToLower(req.Status)
+	if req.Status != "" && !slices.Contains(statuses, req.Status) {
+		return nil, fmt.Errorf("%w: status must be one of %s", ErrInvalid, strings.Join(statuses, ", "))
+	}
+	list, err := s.repo.List(ctx, req.Branch, req.Kind)
+	if err != nil {
+		return nil, err
+	}
+	dash := &Dashboard{Devices: []Device{}}
+	for i := range list {
+		d := &list[i]
+		s.setStatus(d)
+		if d.Status == StatusSilent {
+			dash.Silent++
+		} else {
+			dash.Online++
+		}
+		if req.Status == "" || d.Status == req.Status {
+			dash.Devices = append(dash.Devices, *d)
+		}
+	}
+	return dash, nil
+}
+
+// Heartbeat records that the device is alive
+func (s *Service) Heartbeat(ctx context.Context, id int, hb Heartbeat) error {
+	hb.Version = strings.TrimSpace(hb.Version)
+	if utf8.RuneCountInString(hb.Version) > maxTextLength {
+		return fmt.Errorf("%w: version must be at most %d characters", ErrInvalid, maxTextLength)
+	}
+	return s.repo.Heartbeat(ctx, id, hb.Version)
+}
+
+// ReportError validates and records an error of the device
+func (s *Service) ReportError(ctx context.Context, id int, report ErrorReport) (*DeviceError, error) {
+	report.Code = strings.TrimSpace(report.Code)
+	if report.Code == "" || utf8.RuneCountInString(report.Code) > maxTextLength {
+		return nil, fmt.Errorf("%w: code is required and must be at most %d characters", ErrInvalid, maxTextLength)
+	}
+	report.Message = strings.TrimSpace(report.Message)
+	if utf8.RuneCountInString(report.Message) > maxMessageLength {
+		return nil, fmt.Errorf("%w: message must be at most %d characters", ErrInvalid, maxMessageLength)
+	}
+	return s.repo.ReportError(ctx, id, report)
+}
+
+// ListErrors returns the device's last errors, most recent first
+func (s *Service) ListErrors(ctx context.Context, id int) ([]DeviceError, error) {
+	if _, err := s.repo.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.repo.ListErrors(ctx, id, maxErrors)
+}
+
+// Delete removes the device from the registry
+func (s *Service) Delete(ctx context.Context, id int) error {
+	return s.repo.Delete(ctx, id)
+}
+
+func (s *Service) setStatus(d *Device) {
+	d.Status = StatusOnline
+	if time.Since(d.LastSeenAt) > s.cfg.SilentAfter {
+		d.Status = StatusSilent
+	}
+}
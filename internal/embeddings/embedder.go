@@ -0,0 +1,133 @@
+package embeddings
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"public_library/internal/config"
+	"strconv"
+	"strings"
+)
+
+// Embedder turns texts into vectors whose cosine distance reflects how
+// close their meanings are
+type Embedder interface {
+	// Embed returns one vector per text, in order
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// NewEmbedder returns the Embedder for the configured provider
+func NewEmbedder(cfg config.EmbeddingsConfig) (Embedder, error) {
+	client := &http.Client{Timeout: cfg.Timeout}
+	switch cfg.Provider {
+	case config.EmbeddingsOpenAI:
+		return &openAI{client: client, base: baseURL(cfg, "https://api.openai.com/v1"), apiKey: cfg.APIKey, model: cfg.Model}, nil
+	case config.EmbeddingsOllama:
+		return &ollama{client: client, base: baseURL(cfg, "http://localhost:11434"), apiKey: cfg.APIKey, model: cfg.Model}, nil
+	default:
+		return nil, fmt.Errorf("unknown embeddings provider %q", cfg.Provider)
+	}
+}
+
+func baseURL(cfg config.EmbeddingsConfig, fallback string) string {
+	if cfg.BaseURL == "" {
+		return fallback
+	}
+	return strings.TrimRight(cfg.BaseURL, "/")
+}
+
+// openAI calls the /embeddings endpoint of the OpenAI API, which other
+// servers such as vLLM and LocalAI also implement
+type openAI struct {
+	client *http.Client
+	base   string
+	apiKey string
+	model  string
+}
+
+func (o *openAI) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	var resp struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+	}
+	body := map[string]interface{}{"model": o.model, "input": texts}
+	if err := postJSON(ctx, o.client, o.base+"/embeddings", o.apiKey, body, &resp); err != nil {
+		return nil, err
+	}
+	// the API may return the vectors out of order; index places them
+	vectors := make([][]float32, len(texts))
+	for _, d := range resp.Data {
+		if d.Index < 0 || d.Index >= len(texts) {
+			return nil, fmt.Errorf("embeddings response has index %d for %d texts", d.Index, len(texts))
+		}
+		vectors[d.Index] = d.Embedding
+	}
+	return vectors, nil
+}
+
+// ollama calls /api/embed of a local Ollama server
+type ollama struct {
+	client *http.Client
+	base   string
+	apiKey string
+	model  string
+}
+
+func (o *ollama) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	var resp struct {
+		Embeddings [][]float32 `json:"embeddings"`
+	}
+	body := map[string]interface{}{"model": o.model, "input": texts}
+	if err := postJSON(ctx, o.client, o.base+"/api/embed", o.apiKey, body, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Embeddings, nil
+}
+
+func postJSON(ctx context.Context, client *http.Client, url, apiKey string, body, out interface{}) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("POST %s: %d %s: %s", url, resp.StatusCode, http.StatusText(resp.StatusCode), strings.TrimSpace(string(msg)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("POST %s: decoding response: %w", url, err)
+	}
+	return nil
+}
+
+// vectorLiteral formats v as a pgvector literal, e.g. "[0.1,-0.2]"
+func vectorLiteral(v []float32) string {
+	var b strings.Builder
+	b.Grow(len(v) * 12)
+	b.WriteByte('[')
+	for i, x := range v {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(strconv.FormatFloat(float64(x), 'g', -1, 32))
+	}
+	b.WriteByte(']')
+	return b.String()
+}
@@ -0,0 +1,124 @@
+package embeddings
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"public_library/internal/analytics"
+	"public_library/internal/book"
+	"public_library/internal/httperr"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	svc    *Service
+	logger *zap.Logger
+}
+
+func NewHandler(svc *Service, l *zap.Logger) *Handler {
+	return &Handler{svc: svc, logger: l}
+}
+
+// GET /books/{id}/similar?limit=10
+
+// GetSimilarBooks godoc
+// @Summary Find books similar in meaning to a book
+// @Description Returns the books whose title and description are closest in meaning to the book's, by the cosine similarity of their embeddings, most similar first. Books outside the caller's audience limit are left out. Needs embeddings.enabled; a book added or edited is compared once the indexer has embedded it, within embeddings.interval.
+// @Tags books
+// @Produce json
+// @Param id path int true "Book ID"
+// @Param limit query int false "Books to return, at most 50" default(10)
+// @Success 200 {object} Matches
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string "Book not found, or embeddings are disabled"
+// @Failure 409 {object} map[string]string "Book not embedded yet"
+// @Router /books/{id}/similar [get]
+func (h *Handler) GetSimilarBooks(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "invalid book ID", http.StatusBadRequest)
+		return
+	}
+	var limit int
+	if v := r.URL.Query().Get("limit"); v != "" {
+		if limit, err = strconv.Atoi(v); err != nil {
+			http.Error(w, "invalid limit parameter", http.StatusBadRequest)
+			return
+		}
+	}
+	matches, err := h.svc.Similar(r.Context(), id, limit)
+	if err != nil {
+		h.writeError(w, "failed to find similar books", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(matches)
+}
+
+// POST /books/search/natural
+
+// SearchNatural godoc
+// @Summary Search books by a natural-language description
+// @Description Finds the books a free-text query such as "mystery novels set in Venice" describes. With embeddings.enabled the books are ranked by how close their title and description are in meaning to the query, most similar first; when embeddings are disabled, the provider fails or nothing is embedded yet, the query runs as a keyword search of the catalog, and method says which one answered. Books outside the caller's audience limit are left out.
+// @Tags books
+// @Accept json
+// @Produce json
+// @Param request body NaturalSearchRequest true "Query"
+// @Success 200 {object} NaturalSearchResult
+// @Failure 400 {object} map[string]string
+// @Router /books/search/natural [post]
+func (h *Handler) SearchNatural(w http.ResponseWriter, r *http.Request) {
+	var req NaturalSearchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	result, err := h.svc.NaturalSearch(r.Context(), req)
+	if err != nil {
+		h.writeError(w, "failed to search books", err)
+		return
+	}
+	analytics.RecordSearch(r.Context(), result.Query, int64(len(result.Books)))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// GET /admin/embeddings
+
+// GetEmbeddingsStatus godoc
+// @Summary Get the state of the embedding index
+// @Description Counts the books of the catalog and those whose embedding is up to date with their title, description and the configured model.
+// @Tags admin
+// @Produce json
+// @Security AdminToken
+// @Success 200 {object} IndexStatus
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string "Embeddings are disabled"
+// @Router /admin/embeddings [get]
+func (h *Handler) GetEmbeddingsStatus(w http.ResponseWriter, r *http.Request) {
+	st, err := h.svc.Status(r.Context())
+	if err != nil {
+		h.writeError(w, "failed to read the embedding index", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(st)
+}
+
+func (h *Handler) writeError(w http.ResponseWriter, msg string, err error) {
+	switch {
+	case errors.Is(err, ErrInvalid):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, ErrDisabled):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, book.ErrNotFound):
+		http.Error(w, "book not found", http.StatusNotFound)
+	case errors.Is(err, ErrNotIndexed):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		httperr.Write(w, h.logger, msg, err)
+	}
+}
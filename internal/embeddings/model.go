@@ -0,0 +1,59 @@
+package embeddings
+
+// Match is a book close in meaning to a book or a query
+type Match struct {
+	BookID int    `json:"book_id" example:"12"`
+	Title  string `json:"title" example:"The Dispossessed"`
+	Author string `json:"author" example:"Ursula K. Le Guin"`
+	// Similarity is the cosine similarity of the embeddings, 1 for the same
+	// meaning; keyword matches have none
+	Similarity *float64 `json:"similarity,omitempty" example:"0.83"`
+}
+
+// Matches are the books closest in meaning, most similar first
+type Matches struct {
+	// Model is the embedding model the books were compared with
+	Model string  `json:"model" example:"text-embedding-3-small"`
+	Books []Match `json:"books"`
+}
+
+// Search methods
+const (
+	MethodSemantic = "semantic"
+	MethodKeyword  = "keyword"
+)
+
+// NaturalSearchRequest is a free-text description of the books wanted
+type NaturalSearchRequest struct {
+	Query string `json:"query" example:"mystery novels set in Venice"`
+	// Limit defaults to 10, at most 50
+	Limit int `json:"limit,omitempty" example:"10"`
+}
+
+// NaturalSearchResult are the books found for a natural-language query
+type NaturalSearchResult struct {
+	Query string `json:"query" example:"mystery novels set in Venice"`
+	// Method is semantic when the books were ranked by the embedding index,
+	// or keyword when it was disabled, unavailable or found nothing and the
+	// query ran as a catalog search instead
+	Method string `json:"method" example:"semantic" enums:"semantic,keyword"`
+	// Model is the embedding model of a semantic search
+	Model string  `json:"model,omitempty" example:"text-embedding-3-small"`
+	Books []Match `json:"books"`
+}
+
+// IndexStatus tells how much of the catalog is embedded
+type IndexStatus struct {
+	Model string `json:"model" example:"text-embedding-3-small"`
+	// Books counts the books of the catalog, and Embedded those whose
+	// embedding is up to date with their title, description and the model
+	Books    int `json:"books" example:"5120"`
+	Embedded int `json:"embedded" example:"5096"`
+}
+
+// document is the text of a book to embed and the hash it is stored under
+type document struct {
+	BookID int
+	Text   string
+	Hash   string
+}
@@ -0,0 +1,189 @@
+package embeddings
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"public_library/internal/db"
+	"public_library/utils"
+)
+
+// ErrNotIndexed is returned when a book has no embedding by the configured
+// model yet
+var ErrNotIndexed = errors.New("book has not been embedded yet")
+
+// contentSQL is the text of a book ("b") that is embedded; its md5 tells
+// when an embedding is out of date
+const contentSQL = `b.title || E'\n\n' || b.description`
+
+type Repository struct {
+	db *db.DB
+}
+
+func NewRepository(conn *db.DB) *Repository {
+	return &Repository{db: conn}
+}
+
+// EnsureSchema creates the pgvector extension and the embeddings table with
+// vectors of dimensions. They are not part of the main schema, as pgvector
+// is only needed, and only installed, where embeddings are enabled.
+func (r *Repository) EnsureSchema(ctx context.Context, dimensions int) error {
+	for _, stmt := range []string{
+		`CREATE EXTENSION IF NOT EXISTS vector`,
+		// One embedding per book, of the content hashed in content_hash
+		// with model; edited books and a new model are embedded again
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+			book_id INT PRIMARY KEY REFERENCES %s (id) ON DELETE CASCADE,
+			model TEXT NOT NULL,
+			content_hash TEXT NOT NULL,
+			embedding vector(%d) NOT NULL,
+			embedded_at TIMESTAMPTZ NOT NULL DEFAULT now()
+		)`, utils.BookEmbeddingsTable, utils.BooksTable, dimensions),
+		fmt.Sprintf(`CREATE INDEX IF NOT EXISTS book_embeddings_embedding_idx ON %s
+			USING hnsw (embedding vector_cosine_ops)`, utils.BookEmbeddingsTable),
+	} {
+		if _, err := r.db.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("creating the embeddings schema: %w", err)
+		}
+	}
+	return nil
+}
+
+// Stale returns up to limit books without an embedding of their current
+// content by model, by id
+func (r *Repository) Stale(ctx context.Context, model string, limit int) ([]document, error) {
+	query := fmt.Sprintf(`
+		SELECT b.id, %[3]s, md5(%[3]s) FROM %[1]s b
+		LEFT JOIN %[2]s e ON e.book_id = b.id
+		WHERE e.book_id IS NULL OR e.model <> $1 OR e.content_hash <> md5(%[3]s)
+		ORDER BY b.id
+		LIMIT $2
+	`, utils.BooksTable, utils.BookEmbeddingsTable, contentSQL)
+	rows, err := r.db.QueryContext(ctx, query, model, limit)
+	if err != nil {
+		log.Printf("Failed to list books to embed: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var docs []document
+	for rows.Next() {
+		var d document
+		if err := rows.Scan(&d.BookID, &d.Text, &d.Hash); err != nil {
+			return nil, err
+		}
+		docs = append(docs, d)
+	}
+	return docs, rows.Err()
+}
+
+// Put stores the embeddings of docs by model, vectors[i] being that of
+// docs[i]. Books deleted since they were read are skipped.
+func (r *Repository) Put(ctx context.Context, model string, docs []document, vectors []string) error {
+	log.Println("<--------Put embeddings starts-------->")
+	defer log.Println("<--------Put embeddings ends-------->")
+
+	ids := make([]int, len(docs))
+	hashes := make([]string, len(docs))
+	for i, d := range docs {
+		ids[i], hashes[i] = d.BookID, d.Hash
+	}
+	query := fmt.Sprintf(`
+		INSERT INTO %s (book_id, model, content_hash, embedding)
+		SELECT v.book_id, $1, v.hash, v.embedding::vector
+		FROM unnest($2::int[], $3::text[], $4::text[]) AS v (book_id, hash, embedding)
+		JOIN %s b ON b.id = v.book_id
+		ON CONFLICT (book_id) DO UPDATE SET model = EXCLUDED.model, content_hash = EXCLUDED.content_hash,
+			embedding = EXCLUDED.embedding, embedded_at = now()
+	`, utils.BookEmbeddingsTable, utils.BooksTable)
+	if _, err := r.db.ExecContext(ctx, query, model, ids, hashes, vectors); err != nil {
+		log.Printf("Failed to store %d embeddings: %v", len(docs), err)
+		return err
+	}
+	return nil
+}
+
+func (r *Repository) queryMatches(ctx context.Context, query string, args ...interface{}) ([]Match, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []Match{}
+	for rows.Next() {
+		var m Match
+		if err := rows.Scan(&m.BookID, &m.Title, &m.Author, &m.Similarity); err != nil {
+			return nil, err
+		}
+		list = append(list, m)
+	}
+	return list, rows.Err()
+}
+
+// SimilarTo returns the books whose embeddings by model are closest to that
+// of the book, most similar first. A book edited since it was embedded is
+// compared by its previous embedding. A nil audiences matches any.
+func (r *Repository) SimilarTo(ctx context.Context, bookID int, model string, audiences []string, limit int) ([]Match, error) {
+	var embedded bool
+	err := r.db.QueryRowContext(ctx, fmt.Sprintf(`SELECT model = $2 FROM %s WHERE book_id = $1`, utils.BookEmbeddingsTable),
+		bookID, model).Scan(&embedded)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && !embedded) {
+		return nil, ErrNotIndexed
+	}
+	if err != nil {
+		log.Printf("Failed to read the embedding of book id=%d: %v", bookID, err)
+		return nil, err
+	}
+	query := fmt.Sprintf(`
+		SELECT b.id, b.title, b.author, 1 - (e.embedding <=> s.embedding)
+		FROM %[1]s s
+		JOIN %[1]s e ON e.model = s.model AND e.book_id <> s.book_id
+		JOIN %[2]s b ON b.id = e.book_id
+		WHERE s.book_id = $1 AND ($2::text[] IS NULL OR b.audience = ANY($2))
+		ORDER BY e.embedding <=> s.embedding, b.id
+		LIMIT $3
+	`, utils.BookEmbeddingsTable, utils.BooksTable)
+	list, err := r.queryMatches(ctx, query, bookID, audiences, limit)
+	if err != nil {
+		log.Printf("Failed to find books similar to book id=%d: %v", bookID, err)
+		return nil, err
+	}
+	return list, nil
+}
+
+// Nearest returns the books whose embeddings by model are closest to the
+// vector, most similar first. A nil audiences matches any.
+func (r *Repository) Nearest(ctx context.Context, vector, model string, audiences []string, limit int) ([]Match, error) {
+	query := fmt.Sprintf(`
+		SELECT b.id, b.title, b.author, 1 - (e.embedding <=> $1::vector)
+		FROM %s e
+		JOIN %s b ON b.id = e.book_id
+		WHERE e.model = $2 AND ($3::text[] IS NULL OR b.audience = ANY($3))
+		ORDER BY e.embedding <=> $1::vector, b.id
+		LIMIT $4
+	`, utils.BookEmbeddingsTable, utils.BooksTable)
+	list, err := r.queryMatches(ctx, query, vector, model, audiences, limit)
+	if err != nil {
+		log.Printf("Failed to find the books nearest a query: %v", err)
+		return nil, err
+	}
+	return list, nil
+}
+
+// Status counts the books and those embedded up to date by model
+func (r *Repository) Status(ctx context.Context, model string) (*IndexStatus, error) {
+	st := &IndexStatus{Model: model}
+	query := fmt.Sprintf(`
+		SELECT count(*), count(e.book_id) FILTER (WHERE e.model = $1 AND e.content_hash = md5(%s))
+		FROM %s b
+		LEFT JOIN %s e ON e.book_id = b.id
+	`, contentSQL, utils.BooksTable, utils.BookEmbeddingsTable)
+	if err := r.db.QueryRowContext(ctx, query, model).Scan(&st.Books, &st.Embedded); err != nil {
+		log.Printf("Failed to count embedded books: %v", err)
+		return nil, err
+	}
+	return st, nil
+}
@@ -0,0 +1,267 @@
+// Package embeddings compares books by meaning. With embeddings.enabled, a
+// background job embeds the title and description of every book added or
+// edited, through an OpenAI-compatible API or a local Ollama model, and
+// stores the vectors with pgvector; books are then compared by the cosine
+// similarity of their embeddings, to each other or to a free-text query.
+// Natural-language searches fall back to the keyword search of the catalog
+// when embeddings are disabled or unavailable.
+package embeddings
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"public_library/internal/book"
+	"public_library/internal/config"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+)
+
+var (
+	// ErrDisabled is returned when embeddings are not enabled
+	ErrDisabled = errors.New("embeddings are disabled")
+	// ErrInvalid is wrapped by errors about the content of a request
+	ErrInvalid = errors.New("invalid embeddings request")
+)
+
+const (
+	defaultLimit = 10
+	maxLimit     = 50
+	// maxTextLength keeps the text of a book well within the models' input
+	// limits; the start of a description says most about the book
+	maxTextLength = 8000
+	// maxQueryLength caps free-text queries
+	maxQueryLength = 500
+)
+
+type Service struct {
+	repo     *Repository
+	books    *book.Service
+	embedder Embedder
+	cfg      config.EmbeddingsConfig
+	logger   *zap.Logger
+}
+
+// NewService creates the embeddings service; without embeddings.enabled
+// every call returns ErrDisabled
+func NewService(repo *Repository, books *book.Service, cfg config.EmbeddingsConfig, logger *zap.Logger) (*Service, error) {
+	s := &Service{repo: repo, books: books, cfg: cfg, logger: logger}
+	if !cfg.Enabled {
+		return s, nil
+	}
+	embedder, err := NewEmbedder(cfg)
+	if err != nil {
+		return nil, err
+	}
+	s.embedder = embedder
+	return s, nil
+}
+
+// Enabled reports whether embeddings are enabled
+func (s *Service) Enabled() bool {
+	return s.embedder != nil
+}
+
+// Index embeds the books without an up-to-date embedding, a batch at a time,
+// and returns how many were embedded
+func (s *Service) Index(ctx context.Context) (int, error) {
+	if !s.Enabled() {
+		return 0, ErrDisabled
+	}
+	// the table goes with the sandbox schema on every reset, so it is
+	// checked on every run rather than once at start
+	if err := s.repo.EnsureSchema(ctx, s.cfg.Dimensions); err != nil {
+		return 0, err
+	}
+	embedded := 0
+	for ctx.Err() == nil {
+		docs, err := s.repo.Stale(ctx, s.cfg.Model, s.cfg.BatchSize)
+		if err != nil || len(docs) == 0 {
+			return embedded, err
+		}
+		texts := make([]string, len(docs))
+		for i, d := range docs {
+			texts[i] = truncate(d.Text, maxTextLength)
+		}
+		vectors, err := s.embed(ctx, texts)
+		if err != nil {
+			return embedded, fmt.Errorf("embedding books %d to %d: %w", docs[0].BookID, docs[len(docs)-1].BookID, err)
+		}
+		if err := s.repo.Put(ctx, s.cfg.Model, docs, vectors); err != nil {
+			return embedded, err
+		}
+		embedded += len(docs)
+		if len(docs) < s.cfg.BatchSize {
+			return embedded, nil
+		}
+	}
+	return embedded, ctx.Err()
+}
+
+// embed returns the pgvector literals of the embeddings of texts, checking
+// they have embeddings.dimensions
+func (s *Service) embed(ctx context.Context, texts []string) ([]string, error) {
+	vectors, err := s.embedder.Embed(ctx, texts)
+	if err != nil {
+		return nil, err
+	}
+	if len(vectors) != len(texts) {
+		return nil, fmt.Errorf("got %d embeddings for %d texts", len(vectors), len(texts))
+	}
+	literals := make([]string, len(vectors))
+	for i, v := range vectors {
+		if len(v) != s.cfg.Dimensions {
+			return nil, fmt.Errorf("model %s returned %d dimensions, embeddings.dimensions is %d", s.cfg.Model, len(v), s.cfg.Dimensions)
+		}
+		literals[i] = vectorLiteral(v)
+	}
+	return literals, nil
+}
+
+// StartIndexer embeds new and edited books at start and every
+// embeddings.interval until ctx is cancelled
+func (s *Service) StartIndexer(ctx context.Context, logger *zap.Logger) {
+	if !s.Enabled() {
+		return
+	}
+	index := func() {
+		n, err := s.Index(ctx)
+		if err != nil && ctx.Err() == nil {
+			logger.Error("Embedding books failed", zap.String("provider", s.cfg.Provider), zap.Int("embedded", n), zap.Error(err))
+			return
+		}
+		if n > 0 {
+			logger.Info("Embedded books", zap.Int("count", n))
+		}
+	}
+	go func() {
+		index()
+		ticker := time.NewTicker(s.cfg.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				index()
+			}
+		}
+	}()
+}
+
+// Similar returns up to limit books closest in meaning to the book, within
+// the caller's audience limit
+func (s *Service) Similar(ctx context.Context, bookID, limit int) (*Matches, error) {
+	if !s.Enabled() {
+		return nil, ErrDisabled
+	}
+	limit, err := checkLimit(limit)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.books.Get(ctx, bookID); err != nil {
+		return nil, err
+	}
+	list, err := s.repo.SimilarTo(ctx, bookID, s.cfg.Model, book.AllowedAudiences(ctx), limit)
+	if err != nil {
+		return nil, err
+	}
+	return &Matches{Model: s.cfg.Model, Books: list}, nil
+}
+
+// Search returns up to limit books closest in meaning to a free-text query,
+// such as "mystery novels set in Venice", within the caller's audience limit
+func (s *Service) Search(ctx context.Context, query string, limit int) (*Matches, error) {
+	if !s.Enabled() {
+		return nil, ErrDisabled
+	}
+	limit, err := checkLimit(limit)
+	if err != nil {
+		return nil, err
+	}
+	if query, err = checkQuery(query); err != nil {
+		return nil, err
+	}
+	vectors, err := s.embed(ctx, []string{query})
+	if err != nil {
+		return nil, err
+	}
+	list, err := s.repo.Nearest(ctx, vectors[0], s.cfg.Model, book.AllowedAudiences(ctx), limit)
+	if err != nil {
+		return nil, err
+	}
+	return &Matches{Model: s.cfg.Model, Books: list}, nil
+}
+
+// NaturalSearch finds the books a free-text query describes. With
+// embeddings enabled they are ranked by meaning; when embeddings are
+// disabled, the provider fails or nothing is embedded yet, the query runs as
+// a keyword search of the catalog instead, so the endpoint always answers.
+func (s *Service) NaturalSearch(ctx context.Context, req NaturalSearchRequest) (*NaturalSearchResult, error) {
+	limit, err := checkLimit(req.Limit)
+	if err != nil {
+		return nil, err
+	}
+	query, err := checkQuery(req.Query)
+	if err != nil {
+		return nil, err
+	}
+	if s.Enabled() {
+		matches, err := s.Search(ctx, query, limit)
+		switch {
+		case err == nil && len(matches.Books) > 0:
+			return &NaturalSearchResult{Query: query, Method: MethodSemantic, Model: matches.Model, Books: matches.Books}, nil
+		case err != nil && ctx.Err() != nil:
+			return nil, err
+		case err != nil:
+			s.logger.Warn("Semantic search failed, falling back to keyword search", zap.String("provider", s.cfg.Provider), zap.Error(err))
+		}
+	}
+	books, _, _, err := s.books.List(ctx, book.PaginationRequest{Search: query, PageSize: limit})
+	if err != nil {
+		return nil, err
+	}
+	list := make([]Match, len(books))
+	for i, b := range books {
+		list[i] = Match{BookID: b.ID, Title: b.Title, Author: b.Author}
+	}
+	return &NaturalSearchResult{Query: query, Method: MethodKeyword, Books: list}, nil
+}
+
+// Status tells how much of the catalog is embedded
+func (s *Service) Status(ctx context.Context) (*IndexStatus, error) {
+	if !s.Enabled() {
+		return nil, ErrDisabled
+	}
+	return s.repo.Status(ctx, s.cfg.Model)
+}
+
+func checkLimit(limit int) (int, error) {
+	if limit == 0 {
+		return defaultLimit, nil
+	}
+	if limit < 1 || limit > maxLimit {
+		return 0, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalid, maxLimit)
+	}
+	return limit, nil
+}
+
+// checkQuery trims a free-text query and checks its length
+func checkQuery(query string) (string, error) {
+	query = strings.TrimSpace(query)
+	if query == "" || utf8.RuneCountInString(query) > maxQueryLength {
+		return "", fmt.Errorf("%w: query is required and must be at most %d characters", ErrInvalid, maxQueryLength)
+	}
+	return query, nil
+}
+
+// truncate cuts s to at most n runes
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
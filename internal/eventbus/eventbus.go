@@ -0,0 +1,112 @@
+// Package eventbus is an in-process publish/subscribe bus for domain events.
+// Services publish what happened; subsystems such as cache invalidation,
+// webhooks or notifications subscribe without the publisher knowing them.
+package eventbus
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Event topics published by the services
+const (
+	BookCreated   = "book.created"
+	BookUpdated   = "book.updated"
+	BookDeleted   = "book.deleted"
+	BooksImported = "books.imported"
+	SeriesCreated = "series.created"
+	SeriesUpdated = "series.updated"
+	SeriesDeleted = "series.deleted"
+	// SerialRenewalDue carries a Renewal of the serials package when a
+	// subscription comes within a renewal notice period
+	SerialRenewalDue = "serial.renewal_due"
+	// LoanAutoRenewed carries a Loan of the circulation package, with its
+	// new due date, when the auto-renew job renews it
+	LoanAutoRenewed = "loan.auto_renewed"
+	// SavedSearchMatched carries an Alert of the savedsearch package when
+	// books added to the catalog match a member's saved search
+	SavedSearchMatched = "saved_search.matched"
+	// DeviceSilent carries a Device of the devices package when a kiosk or
+	// gate reader has sent no heartbeat for devices.silent_after
+	DeviceSilent = "device.silent"
+	// HoldReady carries a Hold of the circulation package when the copy
+	// set aside for it reaches the hold shelf of its pickup branch
+	HoldReady = "hold.ready"
+	// HoldExpired carries a Hold of the circulation package when it expires
+	// on the hold shelf without being picked up
+	HoldExpired = "hold.expired"
+	// LoanReturned carries a Loan of the circulation package when its copy
+	// is checked in; its MemberID is empty if it was anonymized on return
+	LoanReturned = "loan.returned"
+	// SuggestionDecided carries a Suggestion of the suggestions package when
+	// staff accept or reject it, for the member to be told
+	SuggestionDecided = "suggestion.decided"
+	// LoanCheckedOut carries a Loan of the circulation package when a copy
+	// is checked out
+	LoanCheckedOut = "loan.checked_out"
+	// HoldPlaced carries a Hold of the circulation package when a member
+	// joins the queue for a book
+	HoldPlaced = "hold.placed"
+)
+
+// Event is one occurrence of a topic. Payload is topic-specific, typically
+// the entity after the change.
+type Event struct {
+	Topic      string
+	EntityID   int
+	Payload    interface{}
+	OccurredAt time.Time
+}
+
+// Handler reacts to an event. Handlers run synchronously in Publish order, so
+// slow work should be handed off to a goroutine or queue.
+type Handler func(ctx context.Context, e Event)
+
+// Bus delivers events to the handlers subscribed to their topic. The zero
+// value is not usable; create one with New.
+type Bus struct {
+	mu       sync.RWMutex
+	handlers map[string][]Handler
+	logger   *zap.Logger
+}
+
+func New(logger *zap.Logger) *Bus {
+	return &Bus{handlers: make(map[string][]Handler), logger: logger}
+}
+
+// Subscribe registers h for topic. The topic "*" receives every event.
+func (b *Bus) Subscribe(topic string, h Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers[topic] = append(b.handlers[topic], h)
+}
+
+// Publish delivers an event to its subscribers after the change has been
+// committed. A panicking handler is logged and does not stop delivery to the
+// others or fail the publisher.
+func (b *Bus) Publish(ctx context.Context, topic string, entityID int, payload interface{}) {
+	if b == nil {
+		return
+	}
+	e := Event{Topic: topic, EntityID: entityID, Payload: payload, OccurredAt: time.Now().UTC()}
+
+	b.mu.RLock()
+	handlers := append(append([]Handler{}, b.handlers[topic]...), b.handlers["*"]...)
+	b.mu.RUnlock()
+
+	for _, h := range handlers {
+		b.deliver(ctx, h, e)
+	}
+}
+
+func (b *Bus) deliver(ctx context.Context, h Handler, e Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			b.logger.Error("event handler panicked", zap.String("topic", e.Topic), zap.Any("panic", r))
+		}
+	}()
+	h(ctx, e)
+}
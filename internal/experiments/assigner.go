@@ -0,0 +1,326 @@
+package experiments
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// VariantHeader lists the variants a response was served with, as
+// experiment=variant pairs
+const VariantHeader = "X-Experiment-Variants"
+
+type exposureKey struct {
+	experiment, unit string
+}
+
+type exposure struct {
+	variant string
+	at      time.Time
+}
+
+type conversionKey struct {
+	experiment, unit, metric string
+}
+
+type conversion struct {
+	variant     string
+	events      int64
+	first, last time.Time
+}
+
+// batch holds the exposures and conversions of one flush
+type batch struct {
+	exposures   map[exposureKey]exposure
+	conversions map[conversionKey]*conversion
+}
+
+func newBatch() batch {
+	return batch{exposures: make(map[exposureKey]exposure), conversions: make(map[conversionKey]*conversion)}
+}
+
+// Assigner buckets units into the variants of the running experiments and
+// aggregates their exposures and conversions until the next flush. Every
+// instance reloads the experiments on its own; assignment only depends on
+// the experiment and the unit, so they all agree.
+type Assigner struct {
+	repo   *Repository
+	logger *zap.Logger
+
+	mu          sync.Mutex
+	experiments map[string]Experiment
+	pending     batch
+}
+
+func NewAssigner(repo *Repository, logger *zap.Logger) *Assigner {
+	return &Assigner{repo: repo, logger: logger, experiments: make(map[string]Experiment), pending: newBatch()}
+}
+
+// Reload replaces the experiments with those in the database
+func (a *Assigner) Reload(ctx context.Context) error {
+	list, err := a.repo.List(ctx)
+	if err != nil {
+		return err
+	}
+	experiments := make(map[string]Experiment, len(list))
+	for _, e := range list {
+		experiments[e.Key] = e
+	}
+	a.mu.Lock()
+	a.experiments = experiments
+	a.mu.Unlock()
+	return nil
+}
+
+// set and unset apply an admin change on this instance immediately
+func (a *Assigner) set(e Experiment) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.experiments[e.Key] = e
+}
+
+func (a *Assigner) unset(key string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.experiments, key)
+}
+
+// assign returns the variant of unit in the experiment, if it is running
+func (a *Assigner) assign(key, unit string) (string, bool) {
+	a.mu.Lock()
+	e, ok := a.experiments[key]
+	a.mu.Unlock()
+	if !ok || !e.Running {
+		return "", false
+	}
+	return bucket(e, unit)
+}
+
+// bucket picks the variant of unit by hashing it with the experiment key,
+// so a unit stays in its variant for as long as the variants don't change
+func bucket(e Experiment, unit string) (string, bool) {
+	total := 0
+	for _, v := range e.Variants {
+		total += v.Weight
+	}
+	if total <= 0 {
+		return "", false
+	}
+	sum := sha256.Sum256([]byte(e.Key + "\x00" + unit))
+	n := int(binary.BigEndian.Uint64(sum[:8]) % uint64(total))
+	for _, v := range e.Variants {
+		if n < v.Weight {
+			return v.Name, true
+		}
+		n -= v.Weight
+	}
+	return "", false
+}
+
+// running returns the keys of the running experiments, sorted
+func (a *Assigner) running() []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	var keys []string
+	for k, e := range a.experiments {
+		if e.Running {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// expose counts that unit was served variant of the experiment
+func (a *Assigner) expose(key, unit, variant string, at time.Time) {
+	k := exposureKey{experiment: key, unit: hashUnit(unit)}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if _, ok := a.pending.exposures[k]; !ok {
+		a.pending.exposures[k] = exposure{variant: variant, at: at}
+	}
+}
+
+// Convert counts a conversion of unit on metric in the experiment, if it is
+// running, and reports whether it was counted
+func (a *Assigner) Convert(key, unit, metric string, at time.Time) bool {
+	variant, ok := a.assign(key, unit)
+	if !ok {
+		return false
+	}
+	k := conversionKey{experiment: key, unit: hashUnit(unit), metric: metric}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	c, ok := a.pending.conversions[k]
+	if !ok {
+		c = &conversion{variant: variant, first: at}
+		a.pending.conversions[k] = c
+	}
+	c.events++
+	c.last = at
+	return true
+}
+
+// ConvertMember counts a conversion of the member on metric in every
+// running experiment. Results only count it for experiments the member was
+// exposed to before.
+func (a *Assigner) ConvertMember(memberID, metric string, at time.Time) {
+	for _, key := range a.running() {
+		a.Convert(key, MemberUnit(memberID), metric, at)
+	}
+}
+
+// Flush adds the pending exposures and conversions to the database. If that
+// fails they are merged back and retried on the next flush.
+func (a *Assigner) Flush(ctx context.Context) error {
+	a.mu.Lock()
+	b := a.pending
+	a.pending = newBatch()
+	a.mu.Unlock()
+
+	if len(b.exposures) == 0 && len(b.conversions) == 0 {
+		return nil
+	}
+	if err := a.repo.Add(ctx, b); err != nil {
+		a.mu.Lock()
+		for k, e := range b.exposures {
+			if p, ok := a.pending.exposures[k]; !ok || e.at.Before(p.at) {
+				a.pending.exposures[k] = e
+			}
+		}
+		for k, c := range b.conversions {
+			if p, ok := a.pending.conversions[k]; ok {
+				p.events += c.events
+				p.first = c.first
+			} else {
+				a.pending.conversions[k] = c
+			}
+		}
+		a.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// StartJobs reloads the experiments every reloadInterval and flushes every
+// flushInterval until ctx is cancelled. A non-positive reloadInterval only
+// loads them once. Whoever cancels ctx should Flush once more to keep the
+// last counts.
+func (a *Assigner) StartJobs(ctx context.Context, reloadInterval, flushInterval time.Duration) {
+	go func() {
+		if err := a.Reload(ctx); err != nil {
+			a.logger.Error("Experiment reload failed", zap.Error(err))
+		}
+		var reload <-chan time.Time
+		if reloadInterval > 0 {
+			ticker := time.NewTicker(reloadInterval)
+			defer ticker.Stop()
+			reload = ticker.C
+		}
+		flush := time.NewTicker(flushInterval)
+		defer flush.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-reload:
+				if err := a.Reload(ctx); err != nil {
+					a.logger.Error("Experiment reload failed", zap.Error(err))
+				}
+			case <-flush.C:
+				if err := a.Flush(ctx); err != nil {
+					a.logger.Error("Experiment flush failed", zap.Error(err))
+				}
+			}
+		}
+	}()
+}
+
+// MemberUnit is the unit of the requests made for a member
+func MemberUnit(memberID string) string {
+	return "member:" + memberID
+}
+
+// APIKeyUnit is the unit of the requests of an API key not made for a member
+func APIKeyUnit(apiKeyID string) string {
+	return "key:" + apiKeyID
+}
+
+// hashUnit keeps member IDs and API keys out of the stored results
+func hashUnit(unit string) string {
+	sum := sha256.Sum256([]byte(unit))
+	return hex.EncodeToString(sum[:16])
+}
+
+type requestKey struct{}
+
+// request is the unit of a request and the variants it was served with
+type request struct {
+	assigner *Assigner
+	unit     string
+	header   http.Header
+
+	mu     sync.Mutex
+	served map[string]string
+}
+
+// WithUnit returns a copy of ctx whose experiments bucket unit. The variants
+// asked for are added to header under VariantHeader.
+func WithUnit(ctx context.Context, a *Assigner, unit string, header http.Header) context.Context {
+	return context.WithValue(ctx, requestKey{}, &request{assigner: a, unit: unit, header: header, served: map[string]string{}})
+}
+
+// Unit returns the unit of the request, or "" when it has none
+func Unit(ctx context.Context) string {
+	if req, ok := ctx.Value(requestKey{}).(*request); ok {
+		return req.unit
+	}
+	return ""
+}
+
+// VariantOf returns the variant of the request in the experiment with key and
+// counts the exposure. It returns "" when the experiment isn't running or
+// the request has no unit, for the caller to keep its default behavior.
+func VariantOf(ctx context.Context, key string) string {
+	req, ok := ctx.Value(requestKey{}).(*request)
+	if !ok {
+		return ""
+	}
+	variant, ok := req.assigner.assign(key, req.unit)
+	if !ok {
+		return ""
+	}
+	req.mu.Lock()
+	defer req.mu.Unlock()
+	if _, served := req.served[key]; !served {
+		req.served[key] = variant
+		req.header.Add(VariantHeader, key+"="+variant)
+		req.assigner.expose(key, req.unit, variant, time.Now())
+	}
+	return variant
+}
+
+// Assignments returns the variants of the request in every running
+// experiment, counting them as exposures
+func Assignments(ctx context.Context) []Assignment {
+	list := []Assignment{}
+	req, ok := ctx.Value(requestKey{}).(*request)
+	if !ok {
+		return list
+	}
+	for _, key := range req.assigner.running() {
+		if v := VariantOf(ctx, key); v != "" {
+			list = append(list, Assignment{Experiment: key, Variant: v})
+		}
+	}
+	return list
+}
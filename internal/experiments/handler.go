@@ -0,0 +1,197 @@
+package experiments
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"public_library/internal/httperr"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	svc    *Service
+	logger *zap.Logger
+}
+
+func NewHandler(svc *Service, l *zap.Logger) *Handler {
+	return &Handler{svc: svc, logger: l}
+}
+
+// GET /experiments/assignments
+
+// GetAssignments godoc
+// @Summary Get the caller's experiment variants
+// @Description Returns the variant of the caller in every running experiment, for clients to adapt their own behavior, and counts them as exposures. The caller is the member in X-Member-ID or, without one, the API key in X-API-Key-ID; callers with neither are in no experiment. The variants are also listed in the X-Experiment-Variants response header.
+// @Tags experiments
+// @Produce json
+// @Param X-Member-ID header string false "Member ID"
+// @Success 200 {array} Assignment
+// @Router /experiments/assignments [get]
+func (h *Handler) GetAssignments(w http.ResponseWriter, r *http.Request) {
+	list := Assignments(r.Context())
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// POST /experiments/{key}/conversions
+
+// RecordConversion godoc
+// @Summary Record a conversion
+// @Description Counts that the caller did what the experiment tries to bring about on the metric, such as following a recommendation. Results only count conversions after the caller was exposed to the experiment. Checkouts and holds are recorded by the API as the checkout and hold metrics of every running experiment.
+// @Tags experiments
+// @Accept json
+// @Param key path string true "Experiment key"
+// @Param X-Member-ID header string false "Member ID"
+// @Param conversion body ConversionRequest true "Conversion"
+// @Success 204 "No Content"
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /experiments/{key}/conversions [post]
+func (h *Handler) RecordConversion(w http.ResponseWriter, r *http.Request) {
+	var req ConversionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if err := h.svc.Convert(r.Context(), mux.Vars(r)["key"], req); err != nil {
+		h.writeError(w, "failed to record conversion", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GET /admin/experiments
+
+// ListExperiments godoc
+// @Summary List experiments
+// @Tags admin
+// @Produce json
+// @Security AdminToken
+// @Success 200 {array} Experiment
+// @Failure 401 {object} map[string]string
+// @Router /admin/experiments [get]
+func (h *Handler) ListExperiments(w http.ResponseWriter, r *http.Request) {
+	list, err := h.svc.List(r.Context())
+	if err != nil {
+		h.writeError(w, "failed to list experiments", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// GET /admin/experiments/{key}
+
+// GetExperiment godoc
+// @Summary Get an experiment
+// @Tags admin
+// @Produce json
+// @Security AdminToken
+// @Param key path string true "Experiment key"
+// @Success 200 {object} Experiment
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /admin/experiments/{key} [get]
+func (h *Handler) GetExperiment(w http.ResponseWriter, r *http.Request) {
+	e, err := h.svc.Get(r.Context(), mux.Vars(r)["key"])
+	if err != nil {
+		h.writeError(w, "failed to get experiment", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(e)
+}
+
+// PUT /admin/experiments/{key}
+
+// PutExperiment godoc
+// @Summary Create or replace an experiment
+// @Description Stores the experiment, which takes effect on this instance at once and on the others at their next reload. Units are bucketed by weight, by a hash of the unit and the key, so they stay in their variant while the variants don't change. Setting running to false stops the experiment and keeps its results.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security AdminToken
+// @Param key path string true "Experiment key"
+// @Param experiment body ExperimentRequest true "Experiment"
+// @Success 200 {object} Experiment "Experiment replaced"
+// @Success 201 {object} Experiment "Experiment created"
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /admin/experiments/{key} [put]
+func (h *Handler) PutExperiment(w http.ResponseWriter, r *http.Request) {
+	var req ExperimentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	e, created, err := h.svc.Put(r.Context(), mux.Vars(r)["key"], req)
+	if err != nil {
+		h.writeError(w, "failed to put experiment", err)
+		return
+	}
+	h.logger.Info("Experiment changed", zap.String("key", e.Key), zap.Bool("running", e.Running))
+	w.Header().Set("Content-Type", "application/json")
+	if created {
+		w.WriteHeader(http.StatusCreated)
+	}
+	json.NewEncoder(w).Encode(e)
+}
+
+// DELETE /admin/experiments/{key}
+
+// DeleteExperiment godoc
+// @Summary Delete an experiment
+// @Description Removes the experiment with its results. Stop it instead to keep them.
+// @Tags admin
+// @Security AdminToken
+// @Param key path string true "Experiment key"
+// @Success 204 "No Content"
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /admin/experiments/{key} [delete]
+func (h *Handler) DeleteExperiment(w http.ResponseWriter, r *http.Request) {
+	if err := h.svc.Delete(r.Context(), mux.Vars(r)["key"]); err != nil {
+		h.writeError(w, "failed to delete experiment", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GET /admin/experiments/{key}/results
+
+// GetExperimentResults godoc
+// @Summary Compare the variants of an experiment
+// @Description Returns the units exposed to each variant and, per metric, how many of them converted after their exposure, with the conversion rate. A unit counts in the variant it was first exposed to. Counts are flushed periodically, so the last minute or so may be missing.
+// @Tags admin
+// @Produce json
+// @Security AdminToken
+// @Param key path string true "Experiment key"
+// @Success 200 {object} Results
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /admin/experiments/{key}/results [get]
+func (h *Handler) GetExperimentResults(w http.ResponseWriter, r *http.Request) {
+	res, err := h.svc.Results(r.Context(), mux.Vars(r)["key"])
+	if err != nil {
+		h.writeError(w, "failed to get experiment results", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+func (h *Handler) writeError(w http.ResponseWriter, msg string, err error) {
+	switch {
+	case errors.Is(err, ErrInvalid), errors.Is(err, ErrNoUnit):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, ErrNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, ErrNotRunning):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		httperr.Write(w, h.logger, msg, err)
+	}
+}
@@ -0,0 +1,68 @@
+package experiments
+
+import "time"
+
+// Experiment splits the requests of members and API keys between variants,
+// such as different recommendation algorithms
+type Experiment struct {
+	Key         string    `json:"key" example:"recommendations"`
+	Description string    `json:"description" example:"Co-borrowing against content-based recommendations"`
+	Variants    []Variant `json:"variants"`
+	// Running experiments assign variants; stopped ones keep their results
+	// and leave every request on the default behavior
+	Running   bool      `json:"running" example:"true"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Variant is one arm of an experiment. Weight is its share of the units
+// against the weights of the other variants.
+type Variant struct {
+	Name   string `json:"name" example:"co_borrowing"`
+	Weight int    `json:"weight" example:"50"`
+}
+
+// ExperimentRequest creates or replaces an experiment. Changing the
+// variants or weights of a running experiment moves units between them.
+type ExperimentRequest struct {
+	Description string    `json:"description" example:"Co-borrowing against content-based recommendations"`
+	Variants    []Variant `json:"variants"`
+	Running     bool      `json:"running" example:"true"`
+}
+
+// Assignment is the variant a unit is in for one experiment
+type Assignment struct {
+	Experiment string `json:"experiment" example:"recommendations"`
+	Variant    string `json:"variant" example:"co_borrowing"`
+}
+
+// ConversionRequest records that the caller did what an experiment tries to
+// bring about, such as following a recommendation
+type ConversionRequest struct {
+	Metric string `json:"metric" example:"click"`
+}
+
+// MetricResult is how many units of a variant converted on one metric
+// after being exposed to it
+type MetricResult struct {
+	Metric string `json:"metric" example:"checkout"`
+	Units  int64  `json:"units" example:"41"`
+	Events int64  `json:"events" example:"63"`
+	// Rate is units / the units exposed to the variant
+	Rate float64 `json:"rate" example:"0.082"`
+}
+
+// VariantResult is the units exposed to a variant and their conversions
+type VariantResult struct {
+	Variant string         `json:"variant" example:"co_borrowing"`
+	Weight  int            `json:"weight" example:"50"`
+	Units   int64          `json:"units" example:"500"`
+	Metrics []MetricResult `json:"metrics"`
+}
+
+// Results compares the variants of an experiment
+type Results struct {
+	Experiment string          `json:"experiment" example:"recommendations"`
+	Running    bool            `json:"running" example:"true"`
+	Variants   []VariantResult `json:"variants"`
+}
@@ -0,0 +1,251 @@
+package experiments
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"public_library/internal/db"
+	"public_library/utils"
+	"time"
+)
+
+// ErrNotFound is returned when no experiment has the requested key
+var ErrNotFound = errors.New("experiment not found")
+
+type Repository struct {
+	db *db.DB
+}
+
+func NewRepository(conn *db.DB) *Repository {
+	return &Repository{db: conn}
+}
+
+const experimentColumns = `key, description, variants, running, created_at, updated_at`
+
+func scanExperiment(row interface{ Scan(...interface{}) error }) (Experiment, error) {
+	var e Experiment
+	var variants []byte
+	err := row.Scan(&e.Key, &e.Description, &variants, &e.Running, &e.CreatedAt, &e.UpdatedAt)
+	if err != nil {
+		return e, err
+	}
+	return e, json.Unmarshal(variants, &e.Variants)
+}
+
+// List returns every experiment by key
+func (r *Repository) List(ctx context.Context) ([]Experiment, error) {
+	query := fmt.Sprintf(`SELECT %s FROM %s ORDER BY key`, experimentColumns, utils.ExperimentsTable)
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		log.Printf("Failed to list experiments: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []Experiment{}
+	for rows.Next() {
+		e, err := scanExperiment(rows)
+		if err != nil {
+			log.Printf("Failed to scan experiment: %v", err)
+			return nil, err
+		}
+		list = append(list, e)
+	}
+	return list, rows.Err()
+}
+
+func (r *Repository) Get(ctx context.Context, key string) (*Experiment, error) {
+	query := fmt.Sprintf(`SELECT %s FROM %s WHERE key = $1`, experimentColumns, utils.ExperimentsTable)
+	e, err := scanExperiment(r.db.QueryRowContext(ctx, query, key))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		log.Printf("Failed to get experiment %q: %v", key, err)
+		return nil, err
+	}
+	return &e, nil
+}
+
+// Put creates or replaces the experiment with e.Key and sets its dates.
+// It reports whether the experiment was new.
+func (r *Repository) Put(ctx context.Context, e *Experiment) (bool, error) {
+	log.Println("<--------Put experiment starts-------->")
+	defer log.Println("<--------Put experiment ends-------->")
+
+	variants, err := json.Marshal(e.Variants)
+	if err != nil {
+		return false, err
+	}
+	var created bool
+	query := fmt.Sprintf(`INSERT INTO %s (key, description, variants, running)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (key) DO UPDATE SET
+			description = EXCLUDED.description,
+			variants = EXCLUDED.variants,
+			running = EXCLUDED.running,
+			updated_at = now()
+		RETURNING created_at, updated_at, xmax = 0`, utils.ExperimentsTable)
+	err = r.db.QueryRowContext(ctx, query, e.Key, e.Description, variants, e.Running).
+		Scan(&e.CreatedAt, &e.UpdatedAt, &created)
+	if err != nil {
+		log.Printf("Failed to put experiment %q: %v", e.Key, err)
+		return false, err
+	}
+	return created, nil
+}
+
+// Delete removes the experiment with its assignments and conversions
+func (r *Repository) Delete(ctx context.Context, key string) error {
+	log.Println("<--------Delete experiment starts-------->")
+	defer log.Println("<--------Delete experiment ends-------->")
+
+	query := fmt.Sprintf(`DELETE FROM %s WHERE key = $1`, utils.ExperimentsTable)
+	res, err := r.db.ExecContext(ctx, query, key)
+	if err != nil {
+		log.Printf("Failed to delete experiment %q: %v", key, err)
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Add stores a batch of exposures and conversions in one transaction. A unit
+// keeps the variant and time of its first exposure. Counts for experiments
+// deleted since they were recorded are dropped.
+func (r *Repository) Add(ctx context.Context, b batch) error {
+	log.Println("<--------Add experiment metrics starts-------->")
+	defer log.Println("<--------Add experiment metrics ends-------->")
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if len(b.exposures) > 0 {
+		var keys, units, variants []string
+		var at []time.Time
+		for k, e := range b.exposures {
+			keys, units, variants, at = append(keys, k.experiment), append(units, k.unit), append(variants, e.variant), append(at, e.at)
+		}
+		query := fmt.Sprintf(`INSERT INTO %[1]s (experiment, unit, variant, exposed_at)
+			SELECT t.experiment, t.unit, t.variant, t.exposed_at
+			FROM unnest($1::text[], $2::text[], $3::text[], $4::timestamptz[]) AS t (experiment, unit, variant, exposed_at)
+			JOIN %[2]s e ON e.key = t.experiment
+			ON CONFLICT (experiment, unit) DO NOTHING`, utils.ExperimentAssignmentsTable, utils.ExperimentsTable)
+		if _, err := tx.ExecContext(ctx, query, keys, units, variants, at); err != nil {
+			log.Printf("Failed to add %d experiment exposures: %v", len(keys), err)
+			return err
+		}
+	}
+
+	if len(b.conversions) > 0 {
+		var keys, units, metrics, variants []string
+		var events []int64
+		var first, last []time.Time
+		for k, c := range b.conversions {
+			keys, units, metrics = append(keys, k.experiment), append(units, k.unit), append(metrics, k.metric)
+			variants, events = append(variants, c.variant), append(events, c.events)
+			first, last = append(first, c.first), append(last, c.last)
+		}
+		query := fmt.Sprintf(`INSERT INTO %[1]s (experiment, unit, metric, variant, events, first_at, last_at)
+			SELECT t.experiment, t.unit, t.metric, t.variant, t.events, t.first_at, t.last_at
+			FROM unnest($1::text[], $2::text[], $3::text[], $4::text[], $5::bigint[], $6::timestamptz[], $7::timestamptz[])
+				AS t (experiment, unit, metric, variant, events, first_at, last_at)
+			JOIN %[2]s e ON e.key = t.experiment
+			ON CONFLICT (experiment, unit, metric) DO UPDATE SET
+				events = %[1]s.events + EXCLUDED.events,
+				last_at = GREATEST(%[1]s.last_at, EXCLUDED.last_at)`,
+			utils.ExperimentConversionsTable, utils.ExperimentsTable)
+		if _, err := tx.ExecContext(ctx, query, keys, units, metrics, variants, events, first, last); err != nil {
+			log.Printf("Failed to add %d experiment conversions: %v", len(keys), err)
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Results counts the units exposed to each variant of e and those that
+// converted on each metric since their exposure, in the variant they were
+// exposed to
+func (r *Repository) Results(ctx context.Context, e *Experiment) (*Results, error) {
+	log.Println("<--------Experiment results starts-------->")
+	defer log.Println("<--------Experiment results ends-------->")
+
+	res := &Results{Experiment: e.Key, Running: e.Running, Variants: []VariantResult{}}
+	byName := map[string]int{}
+	variant := func(name string) *VariantResult {
+		i, ok := byName[name]
+		if !ok {
+			i = len(res.Variants)
+			res.Variants = append(res.Variants, VariantResult{Variant: name, Metrics: []MetricResult{}})
+			byName[name] = i
+		}
+		return &res.Variants[i]
+	}
+	// Variants removed from the experiment keep their results, after the
+	// current ones
+	for _, v := range e.Variants {
+		variant(v.Name).Weight = v.Weight
+	}
+
+	unitsQuery := fmt.Sprintf(`
+		SELECT variant, count(*) FROM %s
+		WHERE experiment = $1
+		GROUP BY variant
+		ORDER BY variant
+	`, utils.ExperimentAssignmentsTable)
+	rows, err := r.db.QueryContext(ctx, unitsQuery, e.Key)
+	if err != nil {
+		log.Printf("Failed to count the units of experiment %q: %v", e.Key, err)
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		var units int64
+		if err := rows.Scan(&name, &units); err != nil {
+			return nil, err
+		}
+		variant(name).Units = units
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	conversionsQuery := fmt.Sprintf(`
+		SELECT a.variant, c.metric, count(*), SUM(c.events)
+		FROM %[1]s c
+		JOIN %[2]s a ON a.experiment = c.experiment AND a.unit = c.unit AND a.variant = c.variant
+		WHERE c.experiment = $1 AND c.last_at >= a.exposed_at
+		GROUP BY a.variant, c.metric
+		ORDER BY a.variant, c.metric
+	`, utils.ExperimentConversionsTable, utils.ExperimentAssignmentsTable)
+	convRows, err := r.db.QueryContext(ctx, conversionsQuery, e.Key)
+	if err != nil {
+		log.Printf("Failed to count the conversions of experiment %q: %v", e.Key, err)
+		return nil, err
+	}
+	defer convRows.Close()
+	for convRows.Next() {
+		var name string
+		var m MetricResult
+		if err := convRows.Scan(&name, &m.Metric, &m.Units, &m.Events); err != nil {
+			return nil, err
+		}
+		v := variant(name)
+		if v.Units > 0 {
+			m.Rate = float64(m.Units) / float64(v.Units)
+		}
+		v.Metrics = append(v.Metrics, m)
+	}
+	return res, convRows.Err()
+}
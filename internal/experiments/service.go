@@ -0,0 +1,143 @@
+// Package experiments runs A/B experiments on API behavior, such as
+// different recommendation algorithms. Requests are bucketed by unit: the
+// member in X-Member-ID or, without one, the API key in X-API-Key-ID. A
+// handler asks for the variant of its request with VariantOf and the response
+// names it in X-Experiment-Variants. Exposures and conversions, recorded by
+// clients or from checkouts and holds on the event bus, are aggregated in
+// memory and flushed periodically, with units stored as hashes.
+package experiments
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+var (
+	// ErrInvalid is wrapped by errors about the content of a request
+	ErrInvalid = errors.New("invalid experiment request")
+	// ErrNoUnit is returned for conversions of requests without a member or
+	// API key to bucket
+	ErrNoUnit = errors.New("request has no member or API key to bucket")
+	// ErrNotRunning is returned for conversions in a stopped experiment
+	ErrNotRunning = errors.New("experiment is not running")
+)
+
+// Metrics recorded from the event bus
+const (
+	MetricCheckout = "checkout"
+	MetricHold     = "hold"
+)
+
+const (
+	maxTextLength = 200
+	maxVariants   = 10
+	maxWeight     = 1000
+)
+
+// namePattern is what experiment keys, variant names and metrics look like
+var namePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+type Service struct {
+	repo     *Repository
+	assigner *Assigner
+}
+
+func NewService(repo *Repository, assigner *Assigner) *Service {
+	return &Service{repo: repo, assigner: assigner}
+}
+
+func (s *Service) List(ctx context.Context) ([]Experiment, error) {
+	return s.repo.List(ctx)
+}
+
+func (s *Service) Get(ctx context.Context, key string) (*Experiment, error) {
+	return s.repo.Get(ctx, key)
+}
+
+// Put validates req and creates or replaces the experiment with key. The
+// change applies on this instance at once and on the others at their next
+// reload. It reports whether the experiment was new.
+func (s *Service) Put(ctx context.Context, key string, req ExperimentRequest) (*Experiment, bool, error) {
+	if !namePattern.MatchString(key) {
+		return nil, false, fmt.Errorf("%w: key must be lowercase letters, digits, _ and -, at most 64 characters", ErrInvalid)
+	}
+	req.Description = strings.TrimSpace(req.Description)
+	if utf8.RuneCountInString(req.Description) > maxTextLength {
+		return nil, false, fmt.Errorf("%w: description must be at most %d characters", ErrInvalid, maxTextLength)
+	}
+	if len(req.Variants) < 2 || len(req.Variants) > maxVariants {
+		return nil, false, fmt.Errorf("%w: an experiment has 2 to %d variants", ErrInvalid, maxVariants)
+	}
+	seen := map[string]bool{}
+	total := 0
+	for i := range req.Variants {
+		v := &req.Variants[i]
+		v.Name = strings.ToLower(strings.TrimSpace(v.Name))
+		if !namePattern.MatchString(v.Name) {
+			return nil, false, fmt.Errorf("%w: variant names must be lowercase letters, digits, _ and -, at most 64 characters", ErrInvalid)
+		}
+		if seen[v.Name] {
+			return nil, false, fmt.Errorf("%w: variant %s is listed twice", ErrInvalid, v.Name)
+		}
+		seen[v.Name] = true
+		if v.Weight < 0 || v.Weight > maxWeight {
+			return nil, false, fmt.Errorf("%w: weights must be between 0 and %d", ErrInvalid, maxWeight)
+		}
+		total += v.Weight
+	}
+	if total == 0 {
+		return nil, false, fmt.Errorf("%w: at least one variant needs a weight", ErrInvalid)
+	}
+
+	e := &Experiment{Key: key, Description: req.Description, Variants: req.Variants, Running: req.Running}
+	created, err := s.repo.Put(ctx, e)
+	if err != nil {
+		return nil, false, err
+	}
+	s.assigner.set(*e)
+	return e, created, nil
+}
+
+// Delete removes the experiment and its results
+func (s *Service) Delete(ctx context.Context, key string) error {
+	if err := s.repo.Delete(ctx, key); err != nil {
+		return err
+	}
+	s.assigner.unset(key)
+	return nil
+}
+
+// Results compares the variants of the experiment. Counts are flushed
+// periodically, so the last minute or so may be missing.
+func (s *Service) Results(ctx context.Context, key string) (*Results, error) {
+	e, err := s.repo.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.Results(ctx, e)
+}
+
+// Convert records a conversion on req.Metric of the request's unit in the
+// experiment with key
+func (s *Service) Convert(ctx context.Context, key string, req ConversionRequest) error {
+	req.Metric = strings.ToLower(strings.TrimSpace(req.Metric))
+	if !namePattern.MatchString(req.Metric) {
+		return fmt.Errorf("%w: metric must be lowercase letters, digits, _ and -, at most 64 characters", ErrInvalid)
+	}
+	unit := Unit(ctx)
+	if unit == "" {
+		return ErrNoUnit
+	}
+	if s.assigner.Convert(key, unit, req.Metric, time.Now()) {
+		return nil
+	}
+	if _, err := s.repo.Get(ctx, key); err != nil {
+		return err
+	}
+	return ErrNotRunning
+}
@@ -0,0 +1,72 @@
+package feed
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"public_library/internal/circulation"
+	"public_library/internal/httperr"
+	"public_library/internal/readinglist"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	svc    *Service
+	logger *zap.Logger
+}
+
+func NewHandler(svc *Service, l *zap.Logger) *Handler {
+	return &Handler{svc: svc, logger: l}
+}
+
+// GET /me/feed?limit=20
+
+// GetMyFeed godoc
+// @Summary Get the calling member's home feed
+// @Description Returns a single ranked feed for the app's home screen. Holds ready for pickup come first, soonest to expire first, then loans due within feed.due_soon_days or overdue, soonest due first. New arrivals in the member's favorite collections (those they borrow most from) follow, alternating with the books recommended to them as by /me/recommendations. New arrivals are books whose first copy was acquired in the last feed.new_arrival_days that the member hasn't borrowed; members with no loans on record get new arrivals from every collection. The member is identified by the X-Member-ID header set by the gateway.
+// @Tags members
+// @Produce json
+// @Param X-Member-ID header string true "Member ID"
+// @Param limit query int false "Items to return, at most feed.max_limit" default(20)
+// @Success 200 {object} Feed
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /me/feed [get]
+func (h *Handler) GetMyFeed(w http.ResponseWriter, r *http.Request) {
+	member := strings.TrimSpace(r.Header.Get(readinglist.MemberHeader))
+	if member == "" {
+		http.Error(w, readinglist.MemberHeader+" header is required", http.StatusUnauthorized)
+		return
+	}
+	var limit int
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			http.Error(w, "invalid limit parameter", http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+	feed, err := h.svc.ForMember(r.Context(), member, limit)
+	if err != nil {
+		h.writeError(w, "failed to build feed", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(feed)
+}
+
+func (h *Handler) writeError(w http.ResponseWriter, msg string, err error) {
+	switch {
+	case errors.Is(err, ErrInvalid):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, circulation.ErrMemberNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	default:
+		httperr.Write(w, h.logger, msg, err)
+	}
+}
@@ -0,0 +1,61 @@
+package feed
+
+import "time"
+
+// Kinds of feed items, in the order the feed ranks them
+const (
+	// KindHoldReady is a held copy waiting on the hold shelf
+	KindHoldReady = "hold_ready"
+	// KindDueSoon is a loan due within feed.due_soon_days, or overdue
+	KindDueSoon = "due_soon"
+	// KindNewArrival is a book new to one of the member's favorite
+	// collections
+	KindNewArrival = "new_arrival"
+	// KindRecommended is a book recommended to the member
+	KindRecommended = "recommended"
+)
+
+// Item is one entry of a member's home feed. BookID, Title and Author are
+// set for every kind; the other fields belong to the kind named.
+type Item struct {
+	Kind   string `json:"kind" example:"hold_ready" enums:"hold_ready,due_soon,new_arrival,recommended"`
+	BookID int    `json:"book_id" example:"12"`
+	Title  string `json:"title" example:"The Dispossessed"`
+	Author string `json:"author" example:"Ursula K. Le Guin"`
+
+	HoldID       *int   `json:"hold_id,omitempty" example:"7"`
+	PickupBranch string `json:"pickup_branch,omitempty" example:"Central"`
+	// PickupBy is when the ready hold expires
+	PickupBy *time.Time `json:"pickup_by,omitempty"`
+
+	LoanID  *int       `json:"loan_id,omitempty" example:"31"`
+	DueAt   *time.Time `json:"due_at,omitempty"`
+	Overdue bool       `json:"overdue,omitempty" example:"false"`
+
+	Collection string `json:"collection,omitempty" example:"Adult Fiction"`
+	// AcquiredOn is when the library received the first copy of a new
+	// arrival
+	AcquiredOn *time.Time `json:"acquired_on,omitempty"`
+
+	// Strategy is the recommender that picked a recommended book
+	Strategy string `json:"strategy,omitempty" example:"co_borrowing" enums:"co_borrowing,content,random"`
+}
+
+// Feed is a member's home feed, most pressing first
+type Feed struct {
+	MemberID string `json:"member_id" example:"m-1001"`
+	// FavoriteCollections are the collections the member borrows most from,
+	// which new arrivals are picked from; empty when they have no loans on
+	// record, and new arrivals then come from every collection
+	FavoriteCollections []string `json:"favorite_collections" example:"Adult Fiction,Science Fiction"`
+	Items               []Item   `json:"items"`
+}
+
+// arrival is a book whose first copy was acquired recently
+type arrival struct {
+	BookID     int
+	Title      string
+	Author     string
+	Collection string
+	AcquiredOn time.Time
+}
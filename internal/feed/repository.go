@@ -0,0 +1,110 @@
+package feed
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"public_library/internal/db"
+	"public_library/utils"
+	"time"
+)
+
+type Repository struct {
+	db *db.DB
+}
+
+func NewRepository(conn *db.DB) *Repository {
+	return &Repository{db: conn}
+}
+
+// FavoriteCollections returns the collections of the books the member
+// borrowed, most borrowed first. Anonymized loans no longer name the
+// member, so they don't count.
+func (r *Repository) FavoriteCollections(ctx context.Context, memberID string, limit int) ([]string, error) {
+	query := fmt.Sprintf(`
+		SELECT b.collection FROM %s l
+		JOIN %s a ON a.id = l.acquisition_id
+		JOIN %s b ON b.id = a.book_id
+		WHERE l.member_id = $1 AND b.collection <> ''
+		GROUP BY b.collection
+		ORDER BY count(*) DESC, max(l.checked_out_at) DESC, b.collection
+		LIMIT $2
+	`, utils.LoansTable, utils.AcquisitionsTable, utils.BooksTable)
+	rows, err := r.db.QueryContext(ctx, query, memberID, limit)
+	if err != nil {
+		log.Printf("Failed to find the favorite collections of member id=%s: %v", memberID, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []string{}
+	for rows.Next() {
+		var c string
+		if err := rows.Scan(&c); err != nil {
+			return nil, err
+		}
+		list = append(list, c)
+	}
+	return list, rows.Err()
+}
+
+// NewArrivals returns the books whose first copy was acquired on or after
+// since and that still have a copy in the collection, newest first. Nil
+// collections or audiences match any; books the member borrowed are left
+// out.
+func (r *Repository) NewArrivals(ctx context.Context, memberID string, collections, audiences []string, since time.Time, limit int) ([]arrival, error) {
+	query := fmt.Sprintf(`
+		SELECT b.id, b.title, b.author, b.collection, min(a.acquired_on)
+		FROM %[1]s b
+		JOIN %[2]s a ON a.book_id = b.id
+		WHERE ($1::text[] IS NULL OR b.collection = ANY($1))
+			AND ($2::text[] IS NULL OR b.audience = ANY($2))
+			AND NOT EXISTS (
+				SELECT 1 FROM %[3]s l
+				JOIN %[2]s c ON c.id = l.acquisition_id
+				WHERE l.member_id = $3 AND c.book_id = b.id
+			)
+		GROUP BY b.id
+		HAVING min(a.acquired_on) >= $4::date AND bool_or(a.withdrawn_at IS NULL)
+		ORDER BY min(a.acquired_on) DESC, b.id DESC
+		LIMIT $5
+	`, utils.BooksTable, utils.AcquisitionsTable, utils.LoansTable)
+	rows, err := r.db.QueryContext(ctx, query, collections, audiences, memberID, since, limit)
+	if err != nil {
+		log.Printf("Failed to list new arrivals for member id=%s: %v", memberID, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []arrival
+	for rows.Next() {
+		var a arrival
+		if err := rows.Scan(&a.BookID, &a.Title, &a.Author, &a.Collection, &a.AcquiredOn); err != nil {
+			return nil, err
+		}
+		list = append(list, a)
+	}
+	return list, rows.Err()
+}
+
+// Authors returns the authors of the books with ids, by id
+func (r *Repository) Authors(ctx context.Context, ids []int) (map[int]string, error) {
+	query := fmt.Sprintf(`SELECT id, author FROM %s WHERE id = ANY($1)`, utils.BooksTable)
+	rows, err := r.db.QueryContext(ctx, query, ids)
+	if err != nil {
+		log.Printf("Failed to read the authors of %d books: %v", len(ids), err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	authors := make(map[int]string, len(ids))
+	for rows.Next() {
+		var id int
+		var author string
+		if err := rows.Scan(&id, &author); err != nil {
+			return nil, err
+		}
+		authors[id] = author
+	}
+	return authors, rows.Err()
+}
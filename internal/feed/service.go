@@ -0,0 +1,142 @@
+// Package feed builds a member's home feed for the app: holds ready for
+// pickup and loans due soon first, most pressing first, then new arrivals in
+// the collections the member borrows most from, alternating with the books
+// recommended to them.
+package feed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"public_library/internal/book"
+	"public_library/internal/circulation"
+	"public_library/internal/config"
+	"public_library/internal/recommendations"
+	"slices"
+	"time"
+)
+
+// ErrInvalid is wrapped by errors about the content of a request
+var ErrInvalid = errors.New("invalid feed request")
+
+type Service struct {
+	repo        *Repository
+	circulation *circulation.Service
+	recommend   *recommendations.Service
+	cfg         config.FeedConfig
+	shelfDays   int
+}
+
+// NewService creates the feed service. Ready holds expire
+// circulation.hold_shelf_days after their copy reached the hold shelf.
+func NewService(repo *Repository, circ *circulation.Service, rec *recommendations.Service, cfg config.FeedConfig, circCfg config.CirculationConfig) *Service {
+	return &Service{repo: repo, circulation: circ, recommend: rec, cfg: cfg, shelfDays: circCfg.HoldShelfDays}
+}
+
+// ForMember returns the member's feed, up to limit items
+func (s *Service) ForMember(ctx context.Context, memberID string, limit int) (*Feed, error) {
+	if limit == 0 {
+		limit = s.cfg.DefaultLimit
+	}
+	if limit < 1 || limit > s.cfg.MaxLimit {
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalid, s.cfg.MaxLimit)
+	}
+	now := time.Now()
+	items, err := s.pressing(ctx, memberID, now)
+	if err != nil {
+		return nil, err
+	}
+	favorites, err := s.repo.FavoriteCollections(ctx, memberID, s.cfg.FavoriteCollections)
+	if err != nil {
+		return nil, err
+	}
+	var collections []string
+	if len(favorites) > 0 {
+		collections = favorites
+	}
+	arrivals, err := s.repo.NewArrivals(ctx, memberID, collections, book.AllowedAudiences(ctx),
+		now.AddDate(0, 0, -s.cfg.NewArrivalDays), limit)
+	if err != nil {
+		return nil, err
+	}
+	recs, err := s.recommend.ForMember(ctx, memberID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[int]bool{}
+	for _, it := range items {
+		seen[it.BookID] = true
+	}
+	for i := 0; i < len(arrivals) || i < len(recs.Books); i++ {
+		if i < len(arrivals) && !seen[arrivals[i].BookID] {
+			a := arrivals[i]
+			seen[a.BookID] = true
+			items = append(items, Item{Kind: KindNewArrival, BookID: a.BookID, Title: a.Title, Author: a.Author,
+				Collection: a.Collection, AcquiredOn: &a.AcquiredOn})
+		}
+		if i < len(recs.Books) && !seen[recs.Books[i].BookID] {
+			rec := recs.Books[i]
+			seen[rec.BookID] = true
+			items = append(items, Item{Kind: KindRecommended, BookID: rec.BookID, Title: rec.Title, Author: rec.Author,
+				Collection: rec.Collection, Strategy: rec.Strategy})
+		}
+	}
+	return &Feed{MemberID: memberID, FavoriteCollections: favorites, Items: items[:min(len(items), limit)]}, nil
+}
+
+// pressing returns the member's holds ready for pickup, soonest to expire
+// first, then their loans due within feed.due_soon_days, soonest due first
+func (s *Service) pressing(ctx context.Context, memberID string, now time.Time) ([]Item, error) {
+	holds, err := s.circulation.ListHolds(ctx, memberID)
+	if err != nil {
+		return nil, err
+	}
+	loans, err := s.circulation.ListLoans(ctx, memberID, circulation.LoansOut)
+	if err != nil {
+		return nil, err
+	}
+
+	ready, due := []Item{}, []Item{}
+	for _, h := range holds {
+		if h.Status != circulation.HoldWaiting || h.ShelvedAt == nil {
+			continue
+		}
+		pickupBy := h.ShelvedAt.AddDate(0, 0, s.shelfDays)
+		if pickupBy.Before(now) {
+			// expired holds are cleared from the shelf, nothing left to pick up
+			continue
+		}
+		id := h.ID
+		ready = append(ready, Item{Kind: KindHoldReady, BookID: h.BookID, Title: h.Title, HoldID: &id,
+			PickupBranch: h.PickupBranch, PickupBy: &pickupBy})
+	}
+	soon := now.AddDate(0, 0, s.cfg.DueSoonDays)
+	for _, l := range loans {
+		if l.DueAt.After(soon) {
+			continue
+		}
+		id, dueAt := l.ID, l.DueAt
+		due = append(due, Item{Kind: KindDueSoon, BookID: l.BookID, Title: l.Title, LoanID: &id,
+			DueAt: &dueAt, Overdue: l.Overdue || dueAt.Before(now)})
+	}
+	slices.SortStableFunc(ready, func(a, b Item) int { return a.PickupBy.Compare(*b.PickupBy) })
+	slices.SortStableFunc(due, func(a, b Item) int { return a.DueAt.Compare(*b.DueAt) })
+
+	items := append(ready, due...)
+	if len(items) == 0 {
+		return items, nil
+	}
+	ids := make([]int, len(items))
+	for i, it := range items {
+		ids[i] = it.BookID
+	}
+	authors, err := s.repo.Authors(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range items {
+		items[i].Author = authors[items[i].BookID]
+	}
+	return items, nil
+}
@@ -0,0 +1,221 @@
+// Package health runs the readiness checks behind GET /health: the catalog
+// database plus whatever external integrations are configured (object
+// storage, mail, payments, search). Each check has its own timeout and its
+// result is cached, so one slow dependency neither delays every probe nor
+// gets hammered by them.
+package health
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"public_library/internal/config"
+)
+
+// Check probes one dependency and returns nil when it is usable
+type Check func(ctx context.Context) error
+
+// Result is the outcome of one check as reported by GET /health
+type Result struct {
+	Name      string `json:"name"`
+	Status    string `json:"status"`
+	Error     string `json:"error,omitempty"`
+	LatencyMs int64  `json:"latency_ms"`
+	CheckedAt string `json:"checked_at"`
+	// Cached is true when the result was served from the cache rather than
+	// probed for this request
+	Cached bool `json:"cached"`
+}
+
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+)
+
+type registered struct {
+	name    string
+	check   Check
+	timeout time.Duration
+	ttl     time.Duration
+
+	mu        sync.Mutex
+	last      Result
+	expiresAt time.Time
+}
+
+// Checker runs the registered checks concurrently
+type Checker struct {
+	cfg    config.HealthConfig
+	checks []*registered
+}
+
+func NewChecker(cfg config.HealthConfig) *Checker {
+	return &Checker{cfg: cfg}
+}
+
+// Register adds a check with the default timeout and cache TTL
+func (c *Checker) Register(name string, check Check) {
+	c.register(name, check, c.cfg.Timeout)
+}
+
+func (c *Checker) register(name string, check Check, timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = c.cfg.Timeout
+	}
+	c.checks = append(c.checks, &registered{name: name, check: check, timeout: timeout, ttl: c.cfg.CacheTTL})
+}
+
+// RegisterConfigured adds a check for every entry of the health.checks
+// section of the configuration
+func (c *Checker) RegisterConfigured() error {
+	for _, ic := range c.cfg.Checks {
+		check, err := integrationCheck(ic)
+		if err != nil {
+			return fmt.Errorf("health check %q: %w", ic.Name, err)
+		}
+		c.register(ic.Name, check, ic.Timeout)
+	}
+	return nil
+}
+
+// Run returns the result of every check, in registration order, and whether
+// all of them are up
+func (c *Checker) Run(ctx context.Context) ([]Result, bool) {
+	results := make([]Result, len(c.checks))
+	var wg sync.WaitGroup
+	for i, rc := range c.checks {
+		wg.Add(1)
+		go func(i int, rc *registered) {
+			defer wg.Done()
+			results[i] = rc.run(ctx)
+		}(i, rc)
+	}
+	wg.Wait()
+
+	healthy := true
+	for _, res := range results {
+		if res.Status != StatusUp {
+			healthy = false
+		}
+	}
+	return results, healthy
+}
+
+// run serves the cached result while it is fresh; otherwise it probes under
+// the check's own timeout. Concurrent callers of a stale check wait for one
+// probe rather than each starting their own.
+func (rc *registered) run(ctx context.Context) Result {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	now := time.Now()
+	if now.Before(rc.expiresAt) {
+		res := rc.last
+		res.Cached = true
+		return res
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx, rc.timeout)
+	defer cancel()
+	err := rc.check(checkCtx)
+	if err != nil && ctx.Err() != nil {
+		// The probe request went away; don't cache a failure that says
+		// nothing about the dependency
+		return Result{Name: rc.name, Status: StatusDown, Error: ctx.Err().Error(), CheckedAt: now.UTC().Format(time.RFC3339)}
+	}
+
+	res := Result{
+		Name:      rc.name,
+		Status:    StatusUp,
+		LatencyMs: time.Since(now).Milliseconds(),
+		CheckedAt: now.UTC().Format(time.RFC3339),
+	}
+	if err != nil {
+		res.Status = StatusDown
+		res.Error = err.Error()
+	}
+	rc.last = res
+	rc.expiresAt = now.Add(rc.ttl)
+	return res
+}
+
+// integrationCheck builds the probe for one configured integration
+func integrationCheck(ic config.IntegrationCheck) (Check, error) {
+	if ic.Target == "" {
+		return nil, fmt.Errorf("target is required")
+	}
+	switch ic.Type {
+	case config.CheckHTTP:
+		return HTTPCheck(ic.Target), nil
+	case config.CheckTCP:
+		return TCPCheck(ic.Target), nil
+	case config.CheckSMTP:
+		return SMTPCheck(ic.Target), nil
+	default:
+		return nil, fmt.Errorf("unknown type %q", ic.Type)
+	}
+}
+
+// HTTPCheck sends a HEAD request to url. Any response below 500 counts as
+// up: an S3 bucket or the Stripe API answer 403 or 401 to an anonymous
+// probe, which still shows they are reachable and serving.
+func HTTPCheck(url string) Check {
+	return func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= 500 {
+			return fmt.Errorf("%s returned %s", url, resp.Status)
+		}
+		return nil
+	}
+}
+
+// TCPCheck opens a connection to addr (host:port) and closes it again
+func TCPCheck(addr string) Check {
+	return func(ctx context.Context) error {
+		var d net.Dialer
+		conn, err := d.DialContext(ctx, "tcp", addr)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}
+}
+
+// SMTPCheck connects to the mail server at addr and expects its 220 greeting
+func SMTPCheck(addr string) Check {
+	return func(ctx context.Context) error {
+		var d net.Dialer
+		conn, err := d.DialContext(ctx, "tcp", addr)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		if deadline, ok := ctx.Deadline(); ok {
+			conn.SetDeadline(deadline)
+		}
+
+		greeting, err := bufio.NewReader(conn).ReadString('\n')
+		if err != nil {
+			return fmt.Errorf("reading greeting: %w", err)
+		}
+		if !strings.HasPrefix(greeting, "220") {
+			return fmt.Errorf("unexpected greeting %q", strings.TrimSpace(greeting))
+		}
+		fmt.Fprint(conn, "QUIT\r\n")
+		return nil
+	}
+}
@@ -0,0 +1,84 @@
+// Package hooks lets deployments extend the services at fixed points, for
+// example to sync writes to an external system, without forking handler code.
+package hooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Point names where in an operation a hook runs
+type Point string
+
+// Before hooks run ahead of the write and can veto it by returning an error.
+// After hooks run once the write has committed; they cannot undo it, so
+// their errors are only reported.
+const (
+	BeforeCreate Point = "before-create"
+	AfterCreate  Point = "after-create"
+	BeforeUpdate Point = "before-update"
+	AfterUpdate  Point = "after-update"
+	BeforeDelete Point = "before-delete"
+	AfterDelete  Point = "after-delete"
+)
+
+// ErrVetoed is matched by every error a hook returns from Run
+var ErrVetoed = errors.New("operation vetoed")
+
+// Func is a hook. It may modify the entity in a before hook.
+type Func[T any] func(ctx context.Context, v *T) error
+
+// VetoError reports the hook error that stopped an operation
+type VetoError struct {
+	Point Point
+	Err   error
+}
+
+func (e *VetoError) Error() string {
+	return fmt.Sprintf("%s hook vetoed the operation: %v", e.Point, e.Err)
+}
+
+func (e *VetoError) Unwrap() []error {
+	return []error{ErrVetoed, e.Err}
+}
+
+// Registry holds the hooks registered for one entity type
+type Registry[T any] struct {
+	mu    sync.RWMutex
+	hooks map[Point][]Func[T]
+}
+
+func NewRegistry[T any]() *Registry[T] {
+	return &Registry[T]{hooks: make(map[Point][]Func[T])}
+}
+
+// Register adds f to the hooks run at p, after those already registered
+func (r *Registry[T]) Register(p Point, f Func[T]) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.hooks[p] = append(r.hooks[p], f)
+}
+
+// Has reports whether any hook is registered at p
+func (r *Registry[T]) Has(p Point) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.hooks[p]) > 0
+}
+
+// Run calls the hooks at p in registration order and stops at the first
+// error, which it returns as a *VetoError
+func (r *Registry[T]) Run(ctx context.Context, p Point, v *T) error {
+	r.mu.RLock()
+	hooks := r.hooks[p]
+	r.mu.RUnlock()
+
+	for _, f := range hooks {
+		if err := f(ctx, v); err != nil {
+			return &VetoError{Point: p, Err: err}
+		}
+	}
+	return nil
+}
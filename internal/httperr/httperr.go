@@ -0,0 +1,95 @@
+// Package httperr translates errors from the data layer into HTTP responses,
+// so every handler answers the same failure with the same status and never
+// leaks driver or SQL error text to clients.
+package httperr
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.uber.org/zap"
+)
+
+// StatusClientClosedRequest is the de facto status for requests the client
+// abandoned before a response was written
+const StatusClientClosedRequest = 499
+
+// Error is an HTTP status with a message that is safe to show clients
+type Error struct {
+	Status  int
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Translate returns the status and client-safe message err should be
+// answered with. Errors it does not recognize are internal server errors.
+func Translate(err error) *Error {
+	switch {
+	case errors.Is(err, context.Canceled):
+		return &Error{StatusClientClosedRequest, "request canceled"}
+	case errors.Is(err, context.DeadlineExceeded):
+		return &Error{http.StatusGatewayTimeout, "request timed out"}
+	case errors.Is(err, sql.ErrNoRows):
+		return &Error{http.StatusNotFound, "not found"}
+	}
+
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return &Error{http.StatusInternalServerError, "internal server error"}
+	}
+	switch pgErr.Code {
+	case "23505", "23P01": // unique_violation, exclusion_violation
+		return &Error{http.StatusConflict, "conflicts with an existing record"}
+	case "23503": // foreign_key_violation
+		if strings.Contains(pgErr.Detail, "is still referenced") {
+			return &Error{http.StatusConflict, "record is still referenced by other records"}
+		}
+		return &Error{http.StatusUnprocessableEntity, "references a record that does not exist"}
+	case "23502", "23514": // not_null_violation, check_violation
+		return &Error{http.StatusBadRequest, "invalid value" + column(pgErr)}
+	case "57014": // query_canceled, e.g. statement_timeout
+		return &Error{http.StatusGatewayTimeout, "request timed out"}
+	case "40001", "40P01", "55P03", "53300", "57P01": // serialization, deadlock, lock, connections, shutdown
+		return &Error{http.StatusServiceUnavailable, "database temporarily unavailable"}
+	}
+	if len(pgErr.Code) != 5 {
+		return &Error{http.StatusInternalServerError, "internal server error"}
+	}
+	switch pgErr.Code[:2] {
+	case "22": // data_exception
+		return &Error{http.StatusBadRequest, "invalid value" + column(pgErr)}
+	case "08", "53": // connection_exception, insufficient_resources
+		return &Error{http.StatusServiceUnavailable, "database temporarily unavailable"}
+	}
+	return &Error{http.StatusInternalServerError, "internal server error"}
+}
+
+func column(pgErr *pgconn.PgError) string {
+	if pgErr.ColumnName == "" {
+		return ""
+	}
+	return " for " + pgErr.ColumnName
+}
+
+// Write answers err as translated by Translate. Server-side failures are
+// logged as errors with msg; client errors are logged as warnings.
+func Write(w http.ResponseWriter, logger *zap.Logger, msg string, err error) {
+	e := Translate(err)
+	switch {
+	case e.Status >= 500:
+		logger.Error(msg, zap.Error(err), zap.Int("status", e.Status))
+	default:
+		logger.Warn(msg, zap.Error(err), zap.Int("status", e.Status))
+	}
+	if e.Status == http.StatusServiceUnavailable {
+		w.Header().Set("Retry-After", "1")
+	}
+	http.Error(w, e.Message, e.Status)
+}
@@ -0,0 +1,89 @@
+package ilssync
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"public_library/internal/httperr"
+	"strconv"
+
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	syncer *Syncer
+	logger *zap.Logger
+}
+
+func NewHandler(s *Syncer, l *zap.Logger) *Handler {
+	return &Handler{syncer: s, logger: l}
+}
+
+// GET /admin/ils-sync
+
+// GetSyncStatus godoc
+// @Summary Get the ILS sync status
+// @Description Returns the configured Koha or Sierra sync, whether a run is in progress, where the next incremental run starts and the report of the last run.
+// @Tags admin
+// @Produce json
+// @Security AdminToken
+// @Success 200 {object} Status
+// @Failure 401 {object} map[string]string
+// @Router /admin/ils-sync [get]
+func (h *Handler) GetSyncStatus(w http.ResponseWriter, r *http.Request) {
+	status, err := h.syncer.Status(r.Context())
+	if err != nil {
+		httperr.Write(w, h.logger, "failed to get ILS sync status", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(status)
+}
+
+// POST /admin/ils-sync/run?full=true
+
+// RunSync godoc
+// @Summary Start an ILS sync run
+// @Description Starts a sync in the background and returns at once; follow it with GET /admin/ils-sync. A run fetches the records changed since the last successful run, or every record with full=true, e.g. to pick up item changes that did not touch their bib record.
+// @Tags admin
+// @Produce json
+// @Security AdminToken
+// @Param full query bool false "Fetch every record"
+// @Success 202 {object} Status
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /admin/ils-sync/run [post]
+func (h *Handler) RunSync(w http.ResponseWriter, r *http.Request) {
+	full := false
+	if v := r.URL.Query().Get("full"); v != "" {
+		var err error
+		if full, err = strconv.ParseBool(v); err != nil {
+			http.Error(w, "full must be true or false", http.StatusBadRequest)
+			return
+		}
+	}
+
+	if err := h.syncer.Trigger(full); err != nil {
+		switch {
+		case errors.Is(err, ErrDisabled):
+			http.Error(w, err.Error(), http.StatusNotFound)
+		case errors.Is(err, ErrRunning):
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			httperr.Write(w, h.logger, "failed to start ILS sync", err)
+		}
+		return
+	}
+	h.logger.Info("ILS sync started by admin", zap.Bool("full", full))
+
+	status, err := h.syncer.Status(r.Context())
+	if err != nil {
+		httperr.Write(w, h.logger, "failed to get ILS sync status", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(status)
+}
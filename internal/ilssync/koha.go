@@ -0,0 +1,155 @@
+package ilssync
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"public_library/internal/config"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// koha reads biblios and items through the Koha REST API (/api/v1), with
+// an API client created under Administration > API keys for a staff user
+// allowed to view the catalogue
+type koha struct {
+	base     string
+	pageSize int
+	api      *apiClient
+}
+
+func newKoha(base string, cfg config.ILSSyncConfig, client *http.Client) *koha {
+	return &koha{
+		base:     base,
+		pageSize: cfg.PageSize,
+		api: &apiClient{
+			http: client,
+			tokenRequest: func(ctx context.Context) (*http.Request, error) {
+				form := url.Values{
+					"grant_type":    {"client_credentials"},
+					"client_id":     {cfg.ClientID},
+					"client_secret": {cfg.ClientSecret},
+				}
+				req, err := http.NewRequestWithContext(ctx, http.MethodPost, base+"/oauth/token", strings.NewReader(form.Encode()))
+				if err != nil {
+					return nil, err
+				}
+				req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+				return req, nil
+			},
+		},
+	}
+}
+
+type kohaBiblio struct {
+	BiblioID int    `json:"biblio_id"`
+	Title    string `json:"title"`
+	Subtitle string `json:"subtitle"`
+	Author   string `json:"author"`
+	// ISBN and ISSN hold every value of the record, separated by " | "
+	ISBN string `json:"isbn"`
+	ISSN string `json:"issn"`
+	// PublicationYear is free text, e.g. "c1954"
+	PublicationYear string `json:"publication_year"`
+	CopyrightDate   *int   `json:"copyright_date"`
+	Timestamp       string `json:"timestamp"`
+}
+
+type kohaItem struct {
+	BiblioID       int    `json:"biblio_id"`
+	ExternalID     string `json:"external_id"`
+	CallNumber     string `json:"callnumber"`
+	Location       string `json:"location"`
+	CollectionCode string `json:"collection_code"`
+	Withdrawn      int    `json:"withdrawn"`
+}
+
+// yearNoise is stripped from MARC 260/264 $c years such as "c1954." or "[1954]"
+var yearNoise = strings.NewReplacer("c", "", "[", "", "]", "", "©", "", ".", "")
+
+func (k *koha) Fetch(ctx context.Context, since time.Time, fn func([]Record) error) error {
+	for page := 1; ; page++ {
+		query := url.Values{
+			"_page":     {strconv.Itoa(page)},
+			"_per_page": {strconv.Itoa(k.pageSize)},
+			"_order_by": {"+biblio_id"},
+		}
+		if !since.IsZero() {
+			query.Set("q", fmt.Sprintf(`{"timestamp":{">=":%q}}`, since.UTC().Format(time.RFC3339)))
+		}
+		var biblios []kohaBiblio
+		if err := k.api.getJSON(ctx, k.base+"/biblios?"+query.Encode(), &biblios); err != nil {
+			return err
+		}
+		if len(biblios) == 0 {
+			return nil
+		}
+
+		items, err := k.items(ctx, biblios)
+		if err != nil {
+			return err
+		}
+		records := make([]Record, len(biblios))
+		for i, b := range biblios {
+			records[i] = b.record(items[b.BiblioID])
+		}
+		if err := fn(records); err != nil {
+			return err
+		}
+		if len(biblios) < k.pageSize {
+			return nil
+		}
+	}
+}
+
+// items fetches the items of a page of biblios in one request
+func (k *koha) items(ctx context.Context, biblios []kohaBiblio) (map[int][]Item, error) {
+	ids := make([]int, len(biblios))
+	for i, b := range biblios {
+		ids[i] = b.BiblioID
+	}
+	filter, _ := json.Marshal(map[string]interface{}{"biblio_id": map[string][]int{"-in": ids}})
+	query := url.Values{"q": {string(filter)}, "_per_page": {"-1"}, "_order_by": {"+item_id"}}
+
+	var all []kohaItem
+	if err := k.api.getJSON(ctx, k.base+"/items?"+query.Encode(), &all); err != nil {
+		return nil, err
+	}
+	items := make(map[int][]Item)
+	for _, it := range all {
+		if it.Withdrawn != 0 {
+			continue
+		}
+		items[it.BiblioID] = append(items[it.BiblioID], Item{
+			Barcode:    it.ExternalID,
+			CallNumber: strings.TrimSpace(it.CallNumber),
+			Location:   it.Location,
+			Collection: it.CollectionCode,
+		})
+	}
+	return items, nil
+}
+
+func (b kohaBiblio) record(items []Item) Record {
+	title := trimPunctuation(b.Title)
+	if sub := trimPunctuation(b.Subtitle); sub != "" {
+		title += ": " + sub
+	}
+	rec := Record{
+		ID:          strconv.Itoa(b.BiblioID),
+		Title:       title,
+		Author:      trimPunctuation(b.Author),
+		Identifiers: identifiers(strings.Split(b.ISBN, "|"), strings.Split(b.ISSN, "|"), nil),
+		Items:       items,
+	}
+	if year, err := strconv.Atoi(yearNoise.Replace(strings.TrimSpace(b.PublicationYear))); err == nil {
+		rec.PublicationYear = &year
+	} else if b.CopyrightDate != nil {
+		rec.PublicationYear = b.CopyrightDate
+	}
+	rec.UpdatedAt, _ = time.Parse(time.RFC3339, b.Timestamp)
+	return rec
+}
@@ -0,0 +1,79 @@
+package ilssync
+
+import (
+	"public_library/internal/book"
+	"time"
+)
+
+// Record is a bib record of the ILS with its items
+type Record struct {
+	// ID is the record's ID in the ILS, used in reports
+	ID              string
+	Title           string
+	Author          string
+	PublicationYear *int
+	Identifiers     []book.Identifier
+	Items           []Item
+	UpdatedAt       time.Time
+}
+
+// Item is one physical copy of a record. The catalog has no item model, so
+// the first item with a call number supplies the book's call number, shelf
+// location and collection.
+type Item struct {
+	Barcode    string
+	CallNumber string
+	Location   string
+	Collection string
+}
+
+// Outcomes of reconciling one record
+const (
+	OutcomeCreated   = "created"
+	OutcomeUpdated   = "updated"
+	OutcomeUnchanged = "unchanged"
+	OutcomeSkipped   = "skipped"
+	OutcomeConflict  = "conflict"
+)
+
+// maxProblems bounds the problems kept in a report
+const maxProblems = 100
+
+// Report is the outcome of one sync run
+type Report struct {
+	Provider   string     `json:"provider" example:"koha"`
+	Full       bool       `json:"full"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	Fetched    int        `json:"fetched" example:"1200"`
+	Created    int        `json:"created" example:"40"`
+	Updated    int        `json:"updated" example:"12"`
+	Unchanged  int        `json:"unchanged" example:"1140"`
+	Skipped    int        `json:"skipped" example:"6"`
+	Conflicts  int        `json:"conflicts" example:"2"`
+	// Problems lists skipped and conflicting records, the first 100 of them
+	Problems []Problem `json:"problems"`
+	// Error is set when the run stopped early; records before it were applied
+	Error string `json:"error,omitempty"`
+}
+
+// Problem is a record the sync could not apply
+type Problem struct {
+	RecordID string `json:"record_id" example:"1042"`
+	Outcome  string `json:"outcome" example:"conflict"`
+	Reason   string `json:"reason" example:"isbn13 9780261102354 and oclc 12345 belong to different books (7, 19)"`
+}
+
+// Status describes the sync configuration and its last run
+type Status struct {
+	Enabled  bool   `json:"enabled"`
+	Provider string `json:"provider,omitempty" example:"koha"`
+	// Interval is the time between scheduled runs; empty when runs are only
+	// started by hand
+	Interval string `json:"interval,omitempty" example:"1h0m0s"`
+	Running  bool   `json:"running"`
+	// SyncedThrough is where the next incremental run picks up; records
+	// changed in the ILS after it are fetched
+	SyncedThrough *time.Time `json:"synced_through,omitempty"`
+	LastRun       *Report    `json:"last_run,omitempty"`
+}
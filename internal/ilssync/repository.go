@@ -0,0 +1,103 @@
+package ilssync
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"public_library/internal/db"
+	"public_library/utils"
+	"time"
+)
+
+// lockKey is the advisory lock held while a sync runs, so that with several
+// instances only one of them syncs at a time
+const lockKey = "ils_sync"
+
+type Repository struct {
+	db *db.DB
+}
+
+func NewRepository(conn *db.DB) *Repository {
+	return &Repository{db: conn}
+}
+
+// State returns the cursor and last report of provider; both are nil before
+// its first run
+func (r *Repository) State(ctx context.Context, provider string) (*time.Time, *Report, error) {
+	log.Println("<--------ILS sync state starts-------->")
+	defer log.Println("<--------ILS sync state ends-------->")
+
+	query := fmt.Sprintf(`SELECT synced_through, last_report FROM %s WHERE provider = $1`, utils.ILSSyncStateTable)
+	var syncedThrough sql.NullTime
+	var report []byte
+	err := r.db.QueryRowContext(ctx, query, provider).Scan(&syncedThrough, &report)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		log.Printf("Failed to get ILS sync state for %s: %v", provider, err)
+		return nil, nil, err
+	}
+
+	var last *Report
+	if report != nil {
+		last = &Report{}
+		if err := json.Unmarshal(report, last); err != nil {
+			return nil, nil, err
+		}
+	}
+	if !syncedThrough.Valid {
+		return nil, last, nil
+	}
+	return &syncedThrough.Time, last, nil
+}
+
+// SaveRun stores the report of a run. syncedThrough moves the cursor; nil
+// keeps it, as after a failed run.
+func (r *Repository) SaveRun(ctx context.Context, report *Report, syncedThrough *time.Time) error {
+	log.Println("<--------Save ILS sync run starts-------->")
+	defer log.Println("<--------Save ILS sync run ends-------->")
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+	query := fmt.Sprintf(`INSERT INTO %[1]s (provider, synced_through, last_report, updated_at)
+		VALUES ($1, $2, $3, now())
+		ON CONFLICT (provider) DO UPDATE SET
+			synced_through = COALESCE(EXCLUDED.synced_through, %[1]s.synced_through),
+			last_report = EXCLUDED.last_report,
+			updated_at = now()`, utils.ILSSyncStateTable)
+	if _, err := r.db.ExecContext(ctx, query, report.Provider, syncedThrough, data); err != nil {
+		log.Printf("Failed to save ILS sync run for %s: %v", report.Provider, err)
+		return err
+	}
+	return nil
+}
+
+// TryLock takes the sync's advisory lock on a connection of its own. It
+// reports false if another instance holds it; otherwise release must be
+// called when the run ends.
+func (r *Repository) TryLock(ctx context.Context) (release func(), ok bool, err error) {
+	conn, err := r.db.Conn(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	if err := conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock(hashtext($1))`, lockKey).Scan(&ok); err != nil || !ok {
+		conn.Close()
+		return nil, false, err
+	}
+	return func() {
+		// The lock belongs to the session, so if it cannot be released the
+		// connection is discarded instead of going back to the pool
+		if _, err := conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock(hashtext($1))`, lockKey); err != nil {
+			log.Printf("Failed to release ILS sync lock: %v", err)
+			conn.Raw(func(interface{}) error { return driver.ErrBadConn })
+		}
+		conn.Close()
+	}, true, nil
+}
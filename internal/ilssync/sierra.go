@@ -0,0 +1,187 @@
+package ilssync
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"public_library/internal/config"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// sierra reads bibs and items through the Sierra REST API (v6), with an API
+// key whose role includes Bibs Read and Items Read
+type sierra struct {
+	base     string
+	pageSize int
+	api      *apiClient
+}
+
+func newSierra(base string, cfg config.ILSSyncConfig, client *http.Client) *sierra {
+	return &sierra{
+		base:     base,
+		pageSize: cfg.PageSize,
+		api: &apiClient{
+			http: client,
+			tokenRequest: func(ctx context.Context) (*http.Request, error) {
+				body := strings.NewReader("grant_type=client_credentials")
+				req, err := http.NewRequestWithContext(ctx, http.MethodPost, base+"/token", body)
+				if err != nil {
+					return nil, err
+				}
+				req.SetBasicAuth(cfg.ClientID, cfg.ClientSecret)
+				req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+				return req, nil
+			},
+		},
+	}
+}
+
+type sierraPage[T any] struct {
+	Total   int `json:"total"`
+	Entries []T `json:"entries"`
+}
+
+type sierraBib struct {
+	ID          string           `json:"id"`
+	UpdatedDate string           `json:"updatedDate"`
+	Title       string           `json:"title"`
+	Author      string           `json:"author"`
+	PublishYear *int             `json:"publishYear"`
+	VarFields   []sierraVarField `json:"varFields"`
+}
+
+type sierraVarField struct {
+	MarcTag   string `json:"marcTag"`
+	Subfields []struct {
+		Tag     string `json:"tag"`
+		Content string `json:"content"`
+	} `json:"subfields"`
+}
+
+type sierraItem struct {
+	BibIDs     []string `json:"bibIds"`
+	Barcode    string   `json:"barcode"`
+	CallNumber string   `json:"callNumber"`
+	Location   struct {
+		Code string `json:"code"`
+	} `json:"location"`
+}
+
+// subfieldDelimiter matches the |a, |b ... markers in Sierra call numbers
+var subfieldDelimiter = regexp.MustCompile(`\|[a-z0-9]`)
+
+func (s *sierra) Fetch(ctx context.Context, since time.Time, fn func([]Record) error) error {
+	for offset := 0; ; offset += s.pageSize {
+		query := url.Values{
+			"limit":      {strconv.Itoa(s.pageSize)},
+			"offset":     {strconv.Itoa(offset)},
+			"fields":     {"id,updatedDate,title,author,publishYear,varFields"},
+			"deleted":    {"false"},
+			"suppressed": {"false"},
+		}
+		if !since.IsZero() {
+			query.Set("updatedDate", "["+since.UTC().Format(time.RFC3339)+",]")
+		}
+		var page sierraPage[sierraBib]
+		if err := s.api.getJSON(ctx, s.base+"/bibs?"+query.Encode(), &page); err != nil {
+			// Sierra answers an offset past the last record with 404
+			if isStatus(err, http.StatusNotFound) && offset > 0 {
+				return nil
+			}
+			return err
+		}
+		if len(page.Entries) == 0 {
+			return nil
+		}
+
+		items, err := s.items(ctx, page.Entries)
+		if err != nil {
+			return err
+		}
+		records := make([]Record, len(page.Entries))
+		for i, b := range page.Entries {
+			records[i] = b.record(items[b.ID])
+		}
+		if err := fn(records); err != nil {
+			return err
+		}
+		if len(page.Entries) < s.pageSize {
+			return nil
+		}
+	}
+}
+
+// items fetches the items of a page of bibs, following the item pages
+func (s *sierra) items(ctx context.Context, bibs []sierraBib) (map[string][]Item, error) {
+	ids := make([]string, len(bibs))
+	for i, b := range bibs {
+		ids[i] = b.ID
+	}
+	items := make(map[string][]Item)
+	const limit = 2000 // the most Sierra returns at once
+	for offset := 0; ; offset += limit {
+		query := url.Values{
+			"bibIds":     {strings.Join(ids, ",")},
+			"fields":     {"bibIds,barcode,callNumber,location"},
+			"deleted":    {"false"},
+			"suppressed": {"false"},
+			"limit":      {strconv.Itoa(limit)},
+			"offset":     {strconv.Itoa(offset)},
+		}
+		var page sierraPage[sierraItem]
+		err := s.api.getJSON(ctx, s.base+"/items?"+query.Encode(), &page)
+		if isStatus(err, http.StatusNotFound) {
+			return items, nil // no (more) items
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, it := range page.Entries {
+			item := Item{
+				Barcode:    it.Barcode,
+				CallNumber: strings.Join(strings.Fields(subfieldDelimiter.ReplaceAllString(it.CallNumber, " ")), " "),
+				Location:   strings.TrimSpace(it.Location.Code),
+			}
+			for _, bibID := range it.BibIDs {
+				items[bibID] = append(items[bibID], item)
+			}
+		}
+		if len(page.Entries) < limit {
+			return items, nil
+		}
+	}
+}
+
+func (b sierraBib) record(items []Item) Record {
+	var isbns, issns, oclcs []string
+	for _, f := range b.VarFields {
+		for _, sf := range f.Subfields {
+			if sf.Tag != "a" {
+				continue
+			}
+			switch f.MarcTag {
+			case "020":
+				isbns = append(isbns, sf.Content)
+			case "022":
+				issns = append(issns, sf.Content)
+			case "035":
+				if strings.HasPrefix(sf.Content, "(OCoLC)") {
+					oclcs = append(oclcs, sf.Content)
+				}
+			}
+		}
+	}
+	rec := Record{
+		ID:              b.ID,
+		Title:           trimPunctuation(b.Title),
+		Author:          trimPunctuation(b.Author),
+		PublicationYear: b.PublishYear,
+		Identifiers:     identifiers(isbns, issns, oclcs),
+		Items:           items,
+	}
+	rec.UpdatedAt, _ = time.Parse(time.RFC3339, b.UpdatedDate)
+	return rec
+}
@@ -0,0 +1,178 @@
+package ilssync
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"public_library/internal/book"
+	"public_library/internal/config"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Source reads bib records from an ILS
+type Source interface {
+	// Fetch calls fn with the records changed since since, or all records
+	// when since is zero, a page at a time
+	Fetch(ctx context.Context, since time.Time, fn func([]Record) error) error
+}
+
+// NewSource returns the Source for the configured provider
+func NewSource(cfg config.ILSSyncConfig) (Source, error) {
+	client := &http.Client{Timeout: cfg.Timeout}
+	base := strings.TrimRight(cfg.BaseURL, "/")
+	switch cfg.Provider {
+	case config.ILSKoha:
+		return newKoha(base, cfg, client), nil
+	case config.ILSSierra:
+		return newSierra(base, cfg, client), nil
+	default:
+		return nil, fmt.Errorf("unknown ILS provider %q", cfg.Provider)
+	}
+}
+
+// apiClient sends GET requests with an OAuth2 client-credentials token,
+// which both Koha and Sierra issue. The token is fetched when missing or
+// about to expire, and once more if the ILS rejects it.
+type apiClient struct {
+	http *http.Client
+	// tokenRequest builds the provider's token request
+	tokenRequest func(ctx context.Context) (*http.Request, error)
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+func (c *apiClient) accessToken(ctx context.Context, renew bool) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !renew && c.token != "" && time.Now().Before(c.expires) {
+		return c.token, nil
+	}
+
+	req, err := c.tokenRequest(ctx)
+	if err != nil {
+		return "", err
+	}
+	var tok tokenResponse
+	if err := c.do(req, &tok); err != nil {
+		return "", fmt.Errorf("requesting ILS token: %w", err)
+	}
+	if tok.AccessToken == "" {
+		return "", fmt.Errorf("requesting ILS token: no access_token in response")
+	}
+	// Renew a minute early so a token does not expire mid-request
+	c.token, c.expires = tok.AccessToken, time.Now().Add(time.Duration(tok.ExpiresIn)*time.Second-time.Minute)
+	return c.token, nil
+}
+
+// getJSON decodes the JSON response to GET url into out
+func (c *apiClient) getJSON(ctx context.Context, url string, out interface{}) error {
+	for attempt := 0; ; attempt++ {
+		token, err := c.accessToken(ctx, attempt > 0)
+		if err != nil {
+			return err
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Accept", "application/json")
+
+		err = c.do(req, out)
+		if isStatus(err, http.StatusUnauthorized) && attempt == 0 {
+			continue
+		}
+		return err
+	}
+}
+
+type statusError struct {
+	method, url string
+	status      int
+	body        string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("%s %s: %d %s: %s", e.method, e.url, e.status, http.StatusText(e.status), e.body)
+}
+
+// isStatus reports whether err is an ILS response with the given status
+func isStatus(err error, status int) bool {
+	var se *statusError
+	return errors.As(err, &se) && se.status == status
+}
+
+func (c *apiClient) do(req *http.Request, out interface{}) error {
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return &statusError{method: req.Method, url: req.URL.Redacted(), status: resp.StatusCode, body: strings.TrimSpace(string(body))}
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("%s %s: decoding response: %w", req.Method, req.URL.Redacted(), err)
+	}
+	return nil
+}
+
+// trimPunctuation drops the ISBD punctuation MARC fields end with, such as
+// "The hobbit /" or "Herbert, Frank.", but keeps the period of a closing
+// initial as in "Tolkien, J. R. R."
+func trimPunctuation(s string) string {
+	s = strings.TrimRight(strings.TrimSpace(s), " /:;,=")
+	if strings.HasSuffix(s, ".") {
+		words := strings.Fields(s)
+		if last := strings.TrimSuffix(words[len(words)-1], "."); len(last) != 1 {
+			s = strings.TrimSuffix(s, ".")
+		}
+	}
+	return s
+}
+
+// identifiers normalizes the ISBNs, ISSNs and OCLC numbers of a record.
+// Values are taken up to the first space, which drops qualifiers such as
+// "(pbk.)"; values that are not valid identifiers are dropped.
+func identifiers(isbns, issns, oclcs []string) []book.Identifier {
+	seen := make(map[book.Identifier]bool)
+	var ids []book.Identifier
+	add := func(idType, value string) {
+		fields := strings.Fields(value)
+		if len(fields) == 0 {
+			return
+		}
+		id, err := book.NormalizeIdentifier(idType, fields[0])
+		if err == nil && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	for _, v := range isbns {
+		idType := book.IdentifierISBN13
+		if f := strings.Fields(v); len(f) > 0 && len(strings.ReplaceAll(f[0], "-", "")) == 10 {
+			idType = book.IdentifierISBN10
+		}
+		add(idType, v)
+	}
+	for _, v := range issns {
+		add(book.IdentifierISSN, v)
+	}
+	for _, v := range oclcs {
+		add(book.IdentifierOCLC, v)
+	}
+	return ids
+}
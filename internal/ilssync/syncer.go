@@ -0,0 +1,302 @@
+// Package ilssync pulls bib and item records from a Koha or Sierra ILS into
+// the catalog, so this service can run alongside a legacy ILS while a
+// library migrates. Records are reconciled with books by identifier: a
+// record whose ISBN, ISSN or OCLC number is already in the catalog updates
+// that book, and any other record creates one. The ILS stays the source of
+// truth for the fields it supplies; everything else about a book is left as
+// it is.
+package ilssync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"public_library/internal/book"
+	"public_library/internal/config"
+	"slices"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+var (
+	// ErrDisabled is returned when ils_sync is not enabled
+	ErrDisabled = errors.New("ILS sync is disabled")
+	// ErrRunning is returned when a run is already in progress here or on
+	// another instance
+	ErrRunning = errors.New("an ILS sync is already running")
+)
+
+// clockSkew is how far back from the start of a run the next incremental
+// run begins, so records saved while the run started, or stamped by an ILS
+// whose clock is slightly behind, are not missed
+const clockSkew = 5 * time.Minute
+
+type Syncer struct {
+	cfg    config.ILSSyncConfig
+	source Source
+	books  *book.Service
+	repo   *Repository
+	logger *zap.Logger
+
+	// ctx outlives the request that starts a run; it is cancelled on shutdown
+	ctx     context.Context
+	mu      sync.Mutex
+	running bool
+}
+
+func New(cfg config.ILSSyncConfig, books *book.Service, repo *Repository, logger *zap.Logger) (*Syncer, error) {
+	s := &Syncer{cfg: cfg, books: books, repo: repo, logger: logger, ctx: context.Background()}
+	if !cfg.Enabled {
+		return s, nil
+	}
+	source, err := NewSource(cfg)
+	if err != nil {
+		return nil, err
+	}
+	s.source = source
+	return s, nil
+}
+
+// Start runs the sync every interval until ctx is cancelled. Runs started
+// through Trigger are cancelled with ctx too.
+func (s *Syncer) Start(ctx context.Context) {
+	s.ctx = ctx
+	if s.cfg.Interval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(s.cfg.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := s.Run(ctx, false); err != nil && !errors.Is(err, ErrRunning) {
+					s.logger.Error("ILS sync failed", zap.String("provider", s.cfg.Provider), zap.Error(err))
+				}
+			}
+		}
+	}()
+}
+
+// Trigger starts a run in the background. A full run fetches every record
+// rather than those changed since the last run.
+func (s *Syncer) Trigger(full bool) error {
+	if err := s.begin(); err != nil {
+		return err
+	}
+	go func() {
+		defer s.end()
+		if _, err := s.run(s.ctx, full); err != nil && !errors.Is(err, ErrRunning) {
+			s.logger.Error("ILS sync failed", zap.String("provider", s.cfg.Provider), zap.Error(err))
+		}
+	}()
+	return nil
+}
+
+// Status reports the configuration, the cursor and the last run
+func (s *Syncer) Status(ctx context.Context) (*Status, error) {
+	status := &Status{Enabled: s.cfg.Enabled}
+	if !s.cfg.Enabled {
+		return status, nil
+	}
+	status.Provider = s.cfg.Provider
+	if s.cfg.Interval > 0 {
+		status.Interval = s.cfg.Interval.String()
+	}
+	s.mu.Lock()
+	status.Running = s.running
+	s.mu.Unlock()
+
+	var err error
+	status.SyncedThrough, status.LastRun, err = s.repo.State(ctx, s.cfg.Provider)
+	return status, err
+}
+
+// Run syncs once and returns its report
+func (s *Syncer) Run(ctx context.Context, full bool) (*Report, error) {
+	if err := s.begin(); err != nil {
+		return nil, err
+	}
+	defer s.end()
+	return s.run(ctx, full)
+}
+
+func (s *Syncer) begin() error {
+	if s.source == nil {
+		return ErrDisabled
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.running {
+		return ErrRunning
+	}
+	s.running = true
+	return nil
+}
+
+func (s *Syncer) end() {
+	s.mu.Lock()
+	s.running = false
+	s.mu.Unlock()
+}
+
+func (s *Syncer) run(ctx context.Context, full bool) (*Report, error) {
+	release, ok, err := s.repo.TryLock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrRunning
+	}
+	defer release()
+
+	var since time.Time
+	if !full {
+		cursor, _, err := s.repo.State(ctx, s.cfg.Provider)
+		if err != nil {
+			return nil, err
+		}
+		if cursor != nil {
+			since = *cursor
+		}
+	}
+
+	report := &Report{Provider: s.cfg.Provider, Full: since.IsZero(), StartedAt: time.Now().UTC(), Problems: []Problem{}}
+	s.logger.Info("ILS sync started", zap.String("provider", s.cfg.Provider), zap.Time("since", since))
+	runErr := s.source.Fetch(ctx, since, func(records []Record) error {
+		for _, rec := range records {
+			report.Fetched++
+			outcome, reason, err := s.apply(ctx, rec)
+			if err != nil {
+				return fmt.Errorf("record %s: %w", rec.ID, err)
+			}
+			report.count(rec.ID, outcome, reason)
+		}
+		return nil
+	})
+
+	finished := time.Now().UTC()
+	report.FinishedAt = &finished
+	var syncedThrough *time.Time
+	if runErr == nil {
+		next := report.StartedAt.Add(-clockSkew)
+		syncedThrough = &next
+	} else {
+		report.Error = runErr.Error()
+	}
+	// The report is saved even when ctx was cancelled mid-run
+	if err := s.repo.SaveRun(context.WithoutCancel(ctx), report, syncedThrough); err != nil {
+		return report, errors.Join(runErr, err)
+	}
+
+	s.logger.Info("ILS sync finished", zap.String("provider", s.cfg.Provider),
+		zap.Int("fetched", report.Fetched), zap.Int("created", report.Created), zap.Int("updated", report.Updated),
+		zap.Int("skipped", report.Skipped), zap.Int("conflicts", report.Conflicts), zap.Error(runErr))
+	return report, runErr
+}
+
+func (r *Report) count(recordID, outcome, reason string) {
+	switch outcome {
+	case OutcomeCreated:
+		r.Created++
+	case OutcomeUpdated:
+		r.Updated++
+	case OutcomeUnchanged:
+		r.Unchanged++
+	case OutcomeSkipped:
+		r.Skipped++
+	case OutcomeConflict:
+		r.Conflicts++
+	}
+	if reason != "" && len(r.Problems) < maxProblems {
+		r.Problems = append(r.Problems, Problem{RecordID: recordID, Outcome: outcome, Reason: reason})
+	}
+}
+
+// apply reconciles one record with the catalog. Problems with the record
+// itself are returned as an outcome and reason; err is only set when the
+// catalog cannot be written, which stops the run.
+func (s *Syncer) apply(ctx context.Context, rec Record) (outcome, reason string, err error) {
+	if len(rec.Identifiers) == 0 {
+		return OutcomeSkipped, "record has no ISBN, ISSN or OCLC number to match on", nil
+	}
+	if rec.Title == "" || rec.Author == "" {
+		return OutcomeSkipped, "record has no title or author", nil
+	}
+
+	var existing *book.Book
+	for _, id := range rec.Identifiers {
+		b, err := s.books.GetByIdentifier(ctx, id.Type, id.Value)
+		if errors.Is(err, book.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return "", "", err
+		}
+		if existing != nil && existing.ID != b.ID {
+			return OutcomeConflict, fmt.Sprintf("its identifiers belong to different books (%d, %d)", existing.ID, b.ID), nil
+		}
+		existing = b
+	}
+
+	if existing == nil {
+		b := &book.Book{}
+		merge(b, rec)
+		err := s.books.Create(ctx, b)
+		return s.outcome(OutcomeCreated, err)
+	}
+	if !merge(existing, rec) {
+		return OutcomeUnchanged, "", nil
+	}
+	return s.outcome(OutcomeUpdated, s.books.Update(ctx, existing))
+}
+
+// outcome sorts a write error into a record problem or a failed run
+func (s *Syncer) outcome(success string, err error) (string, string, error) {
+	switch {
+	case err == nil:
+		return success, "", nil
+	case errors.Is(err, book.ErrDuplicate):
+		return OutcomeConflict, err.Error(), nil
+	case errors.Is(err, book.ErrValidation):
+		return OutcomeSkipped, err.Error(), nil
+	default:
+		return "", "", err
+	}
+}
+
+// merge copies the fields the ILS is the source of truth for into b and
+// adds the record's identifiers to b's. It reports whether b changed.
+func merge(b *book.Book, rec Record) bool {
+	changed := false
+	set := func(field *string, value string) {
+		if value != "" && *field != value {
+			*field, changed = value, true
+		}
+	}
+	set(&b.Title, rec.Title)
+	set(&b.Author, rec.Author)
+	if rec.PublicationYear != nil && (b.PublicationYear == nil || *b.PublicationYear != *rec.PublicationYear) {
+		year := *rec.PublicationYear
+		b.PublicationYear, changed = &year, true
+	}
+	for _, item := range rec.Items {
+		if item.CallNumber == "" {
+			continue
+		}
+		set(&b.CallNumber, item.CallNumber)
+		set(&b.ShelfLocation, item.Location)
+		set(&b.Collection, item.Collection)
+		break
+	}
+	for _, id := range rec.Identifiers {
+		if !slices.Contains(b.Identifiers, id) {
+			b.Identifiers, changed = append(b.Identifiers, id), true
+		}
+	}
+	return changed
+}
@@ -0,0 +1,321 @@
+package media
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"public_library/internal/cache"
+	"public_library/internal/circulation"
+	"public_library/internal/config"
+	"public_library/internal/httperr"
+	"public_library/internal/readinglist"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	repo   *Repository
+	signer *TokenSigner
+	loans  *circulation.Repository
+	cfg    config.MediaConfig
+	// pathPrefix is where the API is mounted, for the stream links handed out
+	pathPrefix string
+	logger     *zap.Logger
+	// pages caches the page list of e-book assets for OPDS-PSE
+	pages *cache.Cache[int, []string]
+}
+
+func NewHandler(r *Repository, signer *TokenSigner, loans *circulation.Repository, cfg config.MediaConfig,
+	pathPrefix string, l *zap.Logger) *Handler {
+	return &Handler{
+		repo:       r,
+		signer:     signer,
+		loans:      loans,
+		cfg:        cfg,
+		pathPrefix: pathPrefix,
+		logger:     l,
+		pages:      cache.New[int, []string]("media_pages", cache.Config{TTL: time.Hour, MaxEntries: 1000}),
+	}
+}
+
+// streamURL is the path that streams the asset of a stream token
+func (h *Handler) streamURL(token string) string {
+	return h.pathPrefix + "/media/stream/" + token
+}
+
+// POST /books/{id}/assets?filename=chapter-01.mp3
+
+// UploadAsset godoc
+// @Summary Upload an audiobook, e-book or cover image
+// @Description Stores the raw request body as an asset of the book. The Content-Type must be an audio type, application/epub+zip, application/vnd.comicbook+zip or, for covers, image/jpeg, image/png, image/gif or image/webp.
+// @Tags media
+// @Accept audio/mpeg
+// @Accept application/epub+zip
+// @Accept application/vnd.comicbook+zip
+// @Accept image/jpeg
+// @Produce json
+// @Param id path int true "Book ID"
+// @Param filename query string true "Original file name"
+// @Success 201 {object} Asset
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 413 {object} map[string]string
+// @Router /books/{id}/assets [post]
+func (h *Handler) UploadAsset(w http.ResponseWriter, r *http.Request) {
+	bookID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "invalid book ID", http.StatusBadRequest)
+		return
+	}
+	filename := filepath.Base(strings.TrimSpace(r.URL.Query().Get("filename")))
+	if filename == "" || filename == "." || filename == string(filepath.Separator) {
+		http.Error(w, "filename is required", http.StatusBadRequest)
+		return
+	}
+	contentType, ok := UploadContentType(r.Header.Get("Content-Type"))
+	if !ok {
+		http.Error(w, ErrContentType.Error(), http.StatusBadRequest)
+		return
+	}
+
+	dir := filepath.Join(h.cfg.StorageDir, strconv.Itoa(bookID))
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		h.logger.Error("failed to create asset directory", zap.Error(err))
+		http.Error(w, "upload failed", http.StatusInternalServerError)
+		return
+	}
+	path := filepath.Join(dir, randomName()+filepath.Ext(filename))
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o640)
+	if err != nil {
+		h.logger.Error("failed to create asset file", zap.Error(err))
+		http.Error(w, "upload failed", http.StatusInternalServerError)
+		return
+	}
+
+	body := http.MaxBytesReader(w, r.Body, h.cfg.MaxUploadBytes)
+	size, err := io.Copy(f, body)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, "file too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		h.logger.Error("failed to store asset", zap.Error(err))
+		http.Error(w, "upload failed", http.StatusInternalServerError)
+		return
+	}
+
+	asset := Asset{BookID: bookID, Filename: filename, ContentType: contentType, SizeBytes: size, storagePath: path}
+	if err := h.repo.Create(r.Context(), &asset); err != nil {
+		os.Remove(path)
+		if httperr.Translate(err).Status == http.StatusUnprocessableEntity { // the book does not exist
+			http.Error(w, "book not found", http.StatusNotFound)
+			return
+		}
+		httperr.Write(w, h.logger, "failed to save asset", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(asset)
+}
+
+// GET /books/{id}/assets
+
+// ListAssets godoc
+// @Summary List a book's audio and e-book assets
+// @Tags media
+// @Produce json
+// @Param id path int true "Book ID"
+// @Success 200 {array} Asset
+// @Failure 400 {object} map[string]string
+// @Router /books/{id}/assets [get]
+func (h *Handler) ListAssets(w http.ResponseWriter, r *http.Request) {
+	bookID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "invalid book ID", http.StatusBadRequest)
+		return
+	}
+	assets, err := h.repo.ListByBook(r.Context(), bookID)
+	if err != nil {
+		httperr.Write(w, h.logger, "failed to list assets", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(assets)
+}
+
+// POST /media/assets/{id}/stream-token
+
+// IssueStreamToken godoc
+// @Summary Issue a streaming token
+// @Description Returns a signed URL that streams the asset until it expires, for the calling member's loan of the asset's book. The loan must be out, not claimed returned and not past due. The token expires at the loan's due date at the latest, and stops working once the loan is returned or claimed returned. EPUB and CBZ assets also get an OPDS-PSE feed URL for page streaming.
+// @Tags media
+// @Accept json
+// @Produce json
+// @Param id path int true "Asset ID"
+// @Param X-Member-ID header string true "Member ID"
+// @Param request body StreamTokenRequest true "The loan the token is for"
+// @Success 200 {object} StreamTokenResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /media/assets/{id}/stream-token [post]
+func (h *Handler) IssueStreamToken(w http.ResponseWriter, r *http.Request) {
+	assetID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "invalid asset ID", http.StatusBadRequest)
+		return
+	}
+	member := strings.TrimSpace(r.Header.Get(readinglist.MemberHeader))
+	if member == "" {
+		http.Error(w, readinglist.MemberHeader+" header is required", http.StatusUnauthorized)
+		return
+	}
+	var req StreamTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if req.LoanID <= 0 {
+		http.Error(w, "loan_id is required", http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now()
+	if req.ExpiresAt != nil && (!req.ExpiresAt.After(now) || req.ExpiresAt.After(now.Add(h.cfg.MaxLoanPeriod))) {
+		http.Error(w, "expires_at must be in the future and at most "+h.cfg.MaxLoanPeriod.String()+" ahead", http.StatusBadRequest)
+		return
+	}
+
+	asset, err := h.repo.GetByID(r.Context(), assetID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			http.Error(w, "asset not found", http.StatusNotFound)
+			return
+		}
+		httperr.Write(w, h.logger, "failed to get asset", err)
+		return
+	}
+	loan, ok := h.memberLoan(w, r, req.LoanID, member)
+	if !ok {
+		return
+	}
+	if loan.BookID != asset.BookID {
+		http.Error(w, "loan is not of the asset's book", http.StatusConflict)
+		return
+	}
+
+	// The token never outlives the loan
+	expiresAt := now.Add(h.cfg.TokenTTL)
+	if req.ExpiresAt != nil {
+		expiresAt = *req.ExpiresAt
+	}
+	if loan.DueAt.Before(expiresAt) {
+		expiresAt = loan.DueAt
+	}
+	token, expiresAt := h.signer.IssueUntil(Grant{AssetID: assetID, LoanID: loan.ID, MemberID: member}, expiresAt)
+	resp := StreamTokenResponse{
+		Token:     token,
+		URL:       h.streamURL(token),
+		ExpiresAt: expiresAt,
+	}
+	if hasPages(asset.ContentType) {
+		resp.OPDSURL = resp.URL + "/opds"
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// memberLoan loads the loan and checks that the member has it out
+func (h *Handler) memberLoan(w http.ResponseWriter, r *http.Request, loanID int, member string) (*circulation.Loan, bool) {
+	loan, err := h.loans.GetLoan(r.Context(), loanID)
+	if err != nil {
+		if errors.Is(err, circulation.ErrLoanNotFound) {
+			http.Error(w, "loan not found", http.StatusNotFound)
+			return nil, false
+		}
+		httperr.Write(w, h.logger, "failed to get loan", err)
+		return nil, false
+	}
+	if loan.MemberID != member {
+		// A loan of another member is reported as not found
+		http.Error(w, "loan not found", http.StatusNotFound)
+		return nil, false
+	}
+	if problem := loanEnded(loan, time.Now()); problem != "" {
+		http.Error(w, problem, http.StatusConflict)
+		return nil, false
+	}
+	return loan, true
+}
+
+// loanEnded returns why streaming for the loan has ended, or "" while it is
+// out, not claimed returned and not past due
+func loanEnded(loan *circulation.Loan, now time.Time) string {
+	switch {
+	case loan.ReturnedAt != nil:
+		return "loan has been returned"
+	case loan.ClaimedReturnedAt != nil:
+		return "loan is claimed returned"
+	case !loan.DueAt.After(now):
+		return "loan is past due"
+	}
+	return ""
+}
+
+// GET /media/stream/{token}
+
+// StreamAsset godoc
+// @Summary Stream an audio asset
+// @Description Serves the asset behind a stream token. Supports Range requests so players can seek without downloading the whole file.
+// @Tags media
+// @Produce audio/mpeg
+// @Param token path string true "Stream token"
+// @Param Range header string false "Byte range, e.g. bytes=0-1048575"
+// @Success 200 {file} file
+// @Success 206 {file} file
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 416 {object} map[string]string
+// @Router /media/stream/{token} [get]
+func (h *Handler) StreamAsset(w http.ResponseWriter, r *http.Request) {
+	asset, ok := h.tokenAsset(w, r)
+	if !ok {
+		return
+	}
+
+	f, err := os.Open(asset.storagePath)
+	if err != nil {
+		h.logger.Error("asset file missing", zap.Int("asset_id", asset.ID), zap.Error(err))
+		http.Error(w, "asset not found", http.StatusNotFound)
+		return
+	}
+	defer f.Close()
+
+	w.Header().Set("Content-Type", asset.ContentType)
+	w.Header().Set("Cache-Control", "private, no-store")
+	// ServeContent handles Range, If-Range and 206/416 responses
+	http.ServeContent(w, r, asset.Filename, asset.CreatedAt, f)
+}
+
+func randomName() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
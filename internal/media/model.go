@@ -0,0 +1,71 @@
+package media
+
+import (
+	"errors"
+	"mime"
+	"strings"
+	"time"
+)
+
+// Content types of e-book assets, besides audio/*, that can be uploaded.
+// Their pages can be streamed with OPDS-PSE (see opds.go).
+const (
+	ContentTypeEPUB = "application/epub+zip"
+	ContentTypeCBZ  = "application/vnd.comicbook+zip"
+)
+
+// ErrContentType rejects an upload that is not an asset type the API stores
+var ErrContentType = errors.New("Content-Type must be an audio type, " + ContentTypeEPUB + ", " + ContentTypeCBZ + " or a JPEG, PNG, GIF or WebP cover image")
+
+// UploadContentType parses the Content-Type header of an upload and returns
+// the content type the asset is stored as. Audio, EPUB, CBZ and cover images
+// are accepted; cover images are stored and streamed like any other asset.
+func UploadContentType(header string) (string, bool) {
+	contentType, _, err := mime.ParseMediaType(header)
+	if err != nil {
+		return "", false
+	}
+	switch {
+	case contentType == "application/x-cbz":
+		return ContentTypeCBZ, true
+	case strings.HasPrefix(contentType, "audio/"), hasPages(contentType):
+		return contentType, true
+	case contentType == "image/jpeg", contentType == "image/png", contentType == "image/gif", contentType == "image/webp":
+		return contentType, true
+	default:
+		return "", false
+	}
+}
+
+// Asset is an uploaded audio, e-book or cover image file belonging to a book
+type Asset struct {
+	ID          int       `json:"id" example:"7"`
+	BookID      int       `json:"book_id" example:"1"`
+	Filename    string    `json:"filename" example:"chapter-01.mp3"`
+	ContentType string    `json:"content_type" example:"audio/mpeg"`
+	SizeBytes   int64     `json:"size_bytes" example:"48213311"`
+	CreatedAt   time.Time `json:"created_at"`
+	storagePath string
+}
+
+// StreamTokenRequest asks for a token to stream an asset of the book on
+// loan to the calling member
+type StreamTokenRequest struct {
+	// LoanID is the member's loan of the asset's book; it must be out, not
+	// claimed returned and not past due
+	LoanID int `json:"loan_id" example:"42"`
+	// ExpiresAt ends access earlier; it defaults to media.token_ttl from now
+	// and may be at most media.max_loan_period ahead. Either way the token
+	// expires at the loan's due date at the latest.
+	ExpiresAt *time.Time `json:"expires_at,omitempty" example:"2024-06-01T00:00:00Z"`
+}
+
+// StreamTokenResponse is a short-lived, signed streaming URL
+type StreamTokenResponse struct {
+	Token     string    `json:"token"`
+	URL       string    `json:"url" example:"/api/v1/media/stream/eyJ..."`
+	ExpiresAt time.Time `json:"expires_at"`
+	// OPDSURL is the OPDS-PSE feed for readers that stream pages; it is only
+	// set for EPUB and CBZ assets
+	OPDSURL string `json:"opds_url,omitempty" example:"/api/v1/media/stream/eyJ.../opds"`
+}
@@ -0,0 +1,274 @@
+package media
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+	"os"
+	"public_library/internal/circulation"
+	"public_library/internal/httperr"
+	"strconv"
+	"time"
+
+	_ "image/gif"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+// OPDS Page Streaming Extension 1.2 (https://anansi-project.github.io/docs/opds-pse/specs/v1.2):
+// the feed behind a stream token links to each page of the e-book as an
+// image, so a reader fetches the pages it shows instead of the whole file.
+// Every page request verifies the token, so streaming stops when the loan
+// it was issued for expires.
+const (
+	pseNamespace   = "http://vaemendis.net/opds-pse/ns"
+	pseStreamRel   = "http://vaemendis.net/opds-pse/stream"
+	acquisitionRel = "http://opds-spec.org/acquisition"
+	opdsFeedType   = "application/atom+xml;profile=opds-catalog;kind=acquisition"
+)
+
+type opdsFeed struct {
+	XMLName  xml.Name    `xml:"feed"`
+	Xmlns    string      `xml:"xmlns,attr"`
+	XmlnsPSE string      `xml:"xmlns:pse,attr"`
+	ID       string      `xml:"id"`
+	Title    string      `xml:"title"`
+	Updated  string      `xml:"updated"`
+	Entries  []opdsEntry `xml:"entry"`
+}
+
+type opdsEntry struct {
+	ID      string     `xml:"id"`
+	Title   string     `xml:"title"`
+	Updated string     `xml:"updated"`
+	Links   []opdsLink `xml:"link"`
+}
+
+type opdsLink struct {
+	Rel   string `xml:"rel,attr"`
+	Type  string `xml:"type,attr"`
+	Href  string `xml:"href,attr"`
+	Count int    `xml:"pse:count,attr,omitempty"`
+}
+
+// GET /media/stream/{token}/opds
+
+// StreamFeed godoc
+// @Summary OPDS-PSE feed of a lent e-book
+// @Description Returns an OPDS acquisition feed whose entry links to the full file and, with the OPDS Page Streaming Extension, to each page image of an EPUB or CBZ. Works until the stream token expires.
+// @Tags media
+// @Produce application/atom+xml
+// @Param token path string true "Stream token"
+// @Success 200 {string} string "OPDS feed"
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Router /media/stream/{token}/opds [get]
+func (h *Handler) StreamFeed(w http.ResponseWriter, r *http.Request) {
+	token := mux.Vars(r)["token"]
+	asset, ok := h.tokenAsset(w, r)
+	if !ok {
+		return
+	}
+	pages, ok := h.assetPages(w, asset)
+	if !ok {
+		return
+	}
+
+	base := h.streamURL(token)
+	id := fmt.Sprintf("urn:public-library:asset:%d", asset.ID)
+	updated := asset.CreatedAt.UTC().Format(time.RFC3339)
+	feed := opdsFeed{
+		Xmlns:    "http://www.w3.org/2005/Atom",
+		XmlnsPSE: pseNamespace,
+		ID:       id + ":feed",
+		Title:    asset.Filename,
+		Updated:  updated,
+		Entries: []opdsEntry{{
+			ID:      id,
+			Title:   asset.Filename,
+			Updated: updated,
+			Links: []opdsLink{
+				{Rel: acquisitionRel, Type: asset.ContentType, Href: base},
+				{Rel: pseStreamRel, Type: "image/jpeg", Href: base + "/pages/{pageNumber}?width={maxWidth}", Count: len(pages)},
+			},
+		}},
+	}
+
+	w.Header().Set("Content-Type", opdsFeedType)
+	w.Header().Set("Cache-Control", "private, no-store")
+	io.WriteString(w, xml.Header)
+	xml.NewEncoder(w).Encode(feed)
+}
+
+// GET /media/stream/{token}/pages/{page}?width=1200
+
+// StreamPage godoc
+// @Summary Stream one page of a lent e-book
+// @Description Serves page {page} (zero-based) of an EPUB or CBZ as an image, read from the archive without sending the rest of it. With width, pages wider than that are scaled down. Works until the stream token expires.
+// @Tags media
+// @Produce image/jpeg
+// @Produce image/png
+// @Param token path string true "Stream token"
+// @Param page path int true "Page number, from 0"
+// @Param width query int false "Maximum width in pixels"
+// @Success 200 {file} file
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Router /media/stream/{token}/pages/{page} [get]
+func (h *Handler) StreamPage(w http.ResponseWriter, r *http.Request) {
+	page, err := strconv.Atoi(mux.Vars(r)["page"])
+	if err != nil || page < 0 {
+		http.Error(w, "invalid page number", http.StatusBadRequest)
+		return
+	}
+	width := 0
+	// Readers that don't fill in the {maxWidth} template send it verbatim
+	if v := r.URL.Query().Get("width"); v != "" && v != "{maxWidth}" {
+		if width, err = strconv.Atoi(v); err != nil || width < 1 {
+			http.Error(w, "invalid width", http.StatusBadRequest)
+			return
+		}
+	}
+
+	asset, ok := h.tokenAsset(w, r)
+	if !ok {
+		return
+	}
+	pages, ok := h.assetPages(w, asset)
+	if !ok {
+		return
+	}
+	if page >= len(pages) {
+		http.Error(w, "page not found", http.StatusNotFound)
+		return
+	}
+
+	f, zr, err := openArchive(asset.storagePath)
+	if err != nil {
+		h.logger.Error("failed to open e-book", zap.Int("asset_id", asset.ID), zap.Error(err))
+		http.Error(w, "asset not found", http.StatusNotFound)
+		return
+	}
+	defer f.Close()
+	entry, err := zr.Open(pages[page])
+	if err != nil {
+		h.logger.Error("e-book page missing", zap.Int("asset_id", asset.ID), zap.String("page", pages[page]), zap.Error(err))
+		http.Error(w, "page not found", http.StatusNotFound)
+		return
+	}
+	defer entry.Close()
+
+	w.Header().Set("Cache-Control", "private, no-store")
+	contentType := pageContentType(pages[page])
+	if width == 0 || contentType == "image/webp" {
+		// webp cannot be decoded with the standard library, so it is sent as is
+		w.Header().Set("Content-Type", contentType)
+		io.Copy(w, entry)
+		return
+	}
+
+	img, format, err := image.Decode(entry)
+	if err != nil {
+		h.logger.Warn("cannot decode e-book page", zap.Int("asset_id", asset.ID), zap.String("page", pages[page]), zap.Error(err))
+		http.Error(w, "page cannot be decoded", http.StatusUnprocessableEntity)
+		return
+	}
+	img = scaleToWidth(img, width)
+	if format == "png" {
+		w.Header().Set("Content-Type", "image/png")
+		png.Encode(w, img)
+		return
+	}
+	w.Header().Set("Content-Type", "image/jpeg")
+	jpeg.Encode(w, img, &jpeg.Options{Quality: 85})
+}
+
+// tokenAsset verifies the stream token of the request and loads its asset.
+// The token's loan is checked again, so a token stops working once its loan
+// is returned or claimed returned.
+func (h *Handler) tokenAsset(w http.ResponseWriter, r *http.Request) (*Asset, bool) {
+	now := time.Now()
+	grant, err := h.signer.Verify(mux.Vars(r)["token"], now)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return nil, false
+	}
+	loan, err := h.loans.GetLoan(r.Context(), grant.LoanID)
+	if err != nil && !errors.Is(err, circulation.ErrLoanNotFound) {
+		httperr.Write(w, h.logger, "failed to get loan", err)
+		return nil, false
+	}
+	if err != nil || loan.MemberID != grant.MemberID || loanEnded(loan, now) != "" {
+		http.Error(w, ErrInvalidToken.Error(), http.StatusForbidden)
+		return nil, false
+	}
+	asset, err := h.repo.GetByID(r.Context(), grant.AssetID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			http.Error(w, "asset not found", http.StatusNotFound)
+			return nil, false
+		}
+		httperr.Write(w, h.logger, "failed to get asset", err)
+		return nil, false
+	}
+	return asset, true
+}
+
+// assetPages returns the page list of an e-book asset. Assets never change
+// once uploaded, so the list is cached by asset ID.
+func (h *Handler) assetPages(w http.ResponseWriter, asset *Asset) ([]string, bool) {
+	if !hasPages(asset.ContentType) {
+		http.Error(w, "only EPUB and CBZ assets can be streamed by page", http.StatusUnprocessableEntity)
+		return nil, false
+	}
+	if pages, ok := h.pages.Get(asset.ID); ok {
+		return pages, true
+	}
+
+	f, zr, err := openArchive(asset.storagePath)
+	if err != nil {
+		h.logger.Error("failed to open e-book", zap.Int("asset_id", asset.ID), zap.Error(err))
+		http.Error(w, "asset not found", http.StatusNotFound)
+		return nil, false
+	}
+	defer f.Close()
+	pages, err := listPages(zr, asset.ContentType)
+	if err != nil {
+		if !errors.Is(err, ErrNoPages) {
+			h.logger.Warn("cannot read e-book pages", zap.Int("asset_id", asset.ID), zap.Error(err))
+		}
+		http.Error(w, ErrNoPages.Error(), http.StatusUnprocessableEntity)
+		return nil, false
+	}
+	h.pages.Set(asset.ID, pages)
+	return pages, true
+}
+
+// openArchive opens a stored EPUB or CBZ; only the central directory is read
+func openArchive(path string) (*os.File, *zip.Reader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	zr, err := zip.NewReader(f, info.Size())
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	return f, zr, nil
+}
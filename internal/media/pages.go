@@ -0,0 +1,203 @@
+package media
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ErrNoPages is returned for e-books without page images, such as
+// reflowable EPUBs, which readers have to download instead
+var ErrNoPages = errors.New("asset has no pages to stream")
+
+// hasPages reports whether assets of contentType are archives of page images
+func hasPages(contentType string) bool {
+	return contentType == ContentTypeEPUB || contentType == ContentTypeCBZ
+}
+
+// pageContentType is the MIME type of a page image, by file extension
+func pageContentType(name string) string {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".jpg", ".jpeg":
+		return "image/jpeg"
+	case ".png":
+		return "image/png"
+	case ".gif":
+		return "image/gif"
+	case ".webp":
+		return "image/webp"
+	default:
+		return ""
+	}
+}
+
+// listPages returns the archive entries holding the page images of a CBZ or
+// fixed-layout EPUB, in reading order
+func listPages(zr *zip.Reader, contentType string) ([]string, error) {
+	var pages []string
+	var err error
+	if contentType == ContentTypeEPUB {
+		pages, err = epubPages(zr)
+	} else {
+		pages = cbzPages(zr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(pages) == 0 {
+		return nil, ErrNoPages
+	}
+	return pages, nil
+}
+
+// cbzPages lists the images of a comic archive in natural name order, so
+// page2.jpg comes before page10.jpg
+func cbzPages(zr *zip.Reader) []string {
+	var pages []string
+	for _, f := range zr.File {
+		name := f.Name
+		if f.FileInfo().IsDir() || strings.HasPrefix(name, "__MACOSX/") || strings.HasPrefix(path.Base(name), ".") {
+			continue
+		}
+		if pageContentType(name) != "" {
+			pages = append(pages, name)
+		}
+	}
+	sort.Slice(pages, func(i, j int) bool { return naturalLess(pages[i], pages[j]) })
+	return pages
+}
+
+// naturalLess compares names with runs of digits compared by value
+func naturalLess(a, b string) bool {
+	for a != "" && b != "" {
+		da, db := leadingDigits(a), leadingDigits(b)
+		if da != "" && db != "" {
+			na, nb := strings.TrimLeft(da, "0"), strings.TrimLeft(db, "0")
+			if len(na) != len(nb) {
+				return len(na) < len(nb)
+			}
+			if na != nb {
+				return na < nb
+			}
+			a, b = a[len(da):], b[len(db):]
+			continue
+		}
+		ca, cb := strings.ToLower(a[:1]), strings.ToLower(b[:1])
+		if ca != cb {
+			return ca < cb
+		}
+		a, b = a[1:], b[1:]
+	}
+	return len(a) < len(b)
+}
+
+func leadingDigits(s string) string {
+	i := 0
+	for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+		i++
+	}
+	return s[:i]
+}
+
+type epubContainer struct {
+	Rootfiles []struct {
+		FullPath string `xml:"full-path,attr"`
+	} `xml:"rootfiles>rootfile"`
+}
+
+type epubPackage struct {
+	Manifest []struct {
+		ID        string `xml:"id,attr"`
+		Href      string `xml:"href,attr"`
+		MediaType string `xml:"media-type,attr"`
+	} `xml:"manifest>item"`
+	Spine []struct {
+		IDRef string `xml:"idref,attr"`
+	} `xml:"spine>itemref"`
+}
+
+// imageRef finds the image a fixed-layout EPUB page document shows, in an
+// <img src>, <image xlink:href> or <image href>
+var imageRef = regexp.MustCompile(`(?i)<(?:img|image)\b[^>]*?\s(?:src|xlink:href|href)\s*=\s*["']([^"']+)["']`)
+
+// epubPages follows the spine of an EPUB: each page is an image in the
+// spine itself or the first image of a spine document. Documents without an
+// image contribute no page, so a reflowable EPUB has none.
+func epubPages(zr *zip.Reader) ([]string, error) {
+	var container epubContainer
+	if err := readXML(zr, "META-INF/container.xml", &container); err != nil {
+		return nil, err
+	}
+	if len(container.Rootfiles) == 0 {
+		return nil, errors.New("EPUB container lists no package document")
+	}
+	opfPath := container.Rootfiles[0].FullPath
+	var pkg epubPackage
+	if err := readXML(zr, opfPath, &pkg); err != nil {
+		return nil, err
+	}
+
+	type item struct{ href, mediaType string }
+	manifest := make(map[string]item, len(pkg.Manifest))
+	for _, it := range pkg.Manifest {
+		manifest[it.ID] = item{href: resolve(path.Dir(opfPath), it.Href), mediaType: it.MediaType}
+	}
+
+	var pages []string
+	for _, ref := range pkg.Spine {
+		it, ok := manifest[ref.IDRef]
+		if !ok {
+			continue
+		}
+		if strings.HasPrefix(it.mediaType, "image/") {
+			pages = append(pages, it.href)
+			continue
+		}
+		doc, err := readEntry(zr, it.href, 1<<20)
+		if err != nil {
+			return nil, err
+		}
+		if m := imageRef.FindSubmatch(doc); m != nil {
+			if img := resolve(path.Dir(it.href), string(m[1])); pageContentType(img) != "" {
+				pages = append(pages, img)
+			}
+		}
+	}
+	return pages, nil
+}
+
+// resolve joins an href found in dir to an archive entry name
+func resolve(dir, href string) string {
+	if u, err := url.PathUnescape(href); err == nil {
+		href = u
+	}
+	href, _, _ = strings.Cut(href, "#")
+	return strings.TrimPrefix(path.Join(dir, href), "/")
+}
+
+func readEntry(zr *zip.Reader, name string, limit int64) ([]byte, error) {
+	f, err := zr.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("EPUB entry %s: %w", name, err)
+	}
+	defer f.Close()
+	return io.ReadAll(io.LimitReader(f, limit))
+}
+
+func readXML(zr *zip.Reader, name string, v interface{}) error {
+	data, err := readEntry(zr, name, 1<<20)
+	if err != nil {
+		return err
+	}
+	if err := xml.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("EPUB entry %s: %w", name, err)
+	}
+	return nil
+}
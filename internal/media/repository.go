@@ -0,0 +1,71 @@
+package media
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"public_library/internal/db"
+	"public_library/utils"
+)
+
+var ErrNotFound = errors.New("asset not found")
+
+var assetMapping = db.Mapping[Asset]{
+	Table:   utils.BookAssetsTable,
+	Columns: []string{"id", "book_id", "filename", "content_type", "size_bytes", "storage_path", "created_at"},
+	Fields: func(a *Asset) []interface{} {
+		return []interface{}{&a.ID, &a.BookID, &a.Filename, &a.ContentType, &a.SizeBytes, &a.storagePath, &a.CreatedAt}
+	},
+	Writable: []string{"book_id", "filename", "content_type", "size_bytes", "storage_path"},
+	Values: func(a *Asset) []interface{} {
+		return []interface{}{a.BookID, a.Filename, a.ContentType, a.SizeBytes, a.storagePath}
+	},
+	ID: func(a *Asset) int { return a.ID },
+}
+
+type Repository struct {
+	table *db.Table[Asset]
+}
+
+func NewRepository(conn *db.DB) *Repository {
+	return &Repository{table: db.NewTable(conn, assetMapping)}
+}
+
+func (r *Repository) Create(ctx context.Context, a *Asset) error {
+	log.Println("<--------Create asset starts-------->")
+	defer log.Println("<--------Create asset ends-------->")
+
+	if err := r.table.Insert(ctx, a); err != nil {
+		log.Printf("Failed to create asset for book id=%d: %v", a.BookID, err)
+		return err
+	}
+	return nil
+}
+
+func (r *Repository) ListByBook(ctx context.Context, bookID int) ([]Asset, error) {
+	log.Println("<--------ListByBook assets starts-------->")
+	defer log.Println("<--------ListByBook assets ends-------->")
+
+	assets, err := r.table.List(ctx, db.ListOptions{
+		Filters: []db.Filter{{Column: "book_id", Op: "=", Value: bookID}},
+		OrderBy: []string{"filename", "id"},
+	})
+	if err != nil {
+		log.Printf("Failed to list assets for book id=%d: %v", bookID, err)
+		return nil, err
+	}
+	return assets, nil
+}
+
+func (r *Repository) GetByID(ctx context.Context, id int) (*Asset, error) {
+	a, err := r.table.Get(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		log.Printf("Failed to get asset id=%d: %v", id, err)
+		return nil, err
+	}
+	return &a, nil
+}
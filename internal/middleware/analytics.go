@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"net/http"
+	"public_library/internal/analytics"
+	"public_library/internal/readinglist"
+	"strings"
+)
+
+// Analytics lets the handlers count the request's searches and views with
+// rec, unless it opted out: with a DNT: 1 or Sec-GPC: 1 header, or by being
+// made for a member, identified by the X-Member-ID header, who set
+// analytics_opt_out.
+func Analytics(rec *analytics.Recorder) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("DNT") == "1" || r.Header.Get("Sec-GPC") == "1" ||
+				rec.OptedOut(strings.TrimSpace(r.Header.Get(readinglist.MemberHeader))) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(analytics.WithRecorder(r.Context(), rec)))
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"net/http"
+	"public_library/internal/book"
+)
+
+// AudienceHeader carries the audience limit of the calling account. It is
+// expected to be set by the authenticating gateway in front of the API.
+const AudienceHeader = "X-Catalog-Audience"
+
+// AudienceLimit restricts catalog reads to the audience named in
+// AudienceHeader, so children's accounts only see age-appropriate materials.
+func AudienceLimit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		audience := r.Header.Get(AudienceHeader)
+		if audience == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		ctx, err := book.WithAudienceLimit(r.Context(), audience)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
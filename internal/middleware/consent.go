@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"net/http"
+	"public_library/internal/consent"
+	"public_library/internal/httperr"
+	"public_library/internal/readinglist"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+// Consent refuses requests made for a member, identified by the X-Member-ID
+// header, with 403 while they haven't accepted the current version of every
+// required consent document. The consent routes stay open so the member
+// can read and accept the documents; requests without the header, such as
+// staff ones, are not checked.
+func Consent(svc *consent.Service, logger *zap.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			member := strings.TrimSpace(r.Header.Get(readinglist.MemberHeader))
+			if member == "" || consentRoute(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			outstanding, err := svc.Outstanding(r.Context(), member)
+			if err != nil {
+				httperr.Write(w, logger, "failed to check member consents", err)
+				return
+			}
+			if len(outstanding) > 0 {
+				documents := make([]string, len(outstanding))
+				for i, d := range outstanding {
+					documents[i] = d.Kind + " " + d.Version
+				}
+				http.Error(w, "consent required: "+strings.Join(documents, ", "), http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func consentRoute(r *http.Request) bool {
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return false
+	}
+	tpl, err := route.GetPathTemplate()
+	return err == nil && (strings.HasSuffix(tpl, "/consent-documents") || strings.HasSuffix(tpl, "/consents"))
+}
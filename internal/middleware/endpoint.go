@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"net/http"
+	"public_library/internal/db"
+
+	"github.com/gorilla/mux"
+)
+
+// Endpoint tags the request context with "METHOD /route/template" so that
+// database instrumentation can attribute queries to the calling endpoint,
+// and records how many queries the request issued once it completes.
+func Endpoint(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := r.Method + " " + r.URL.Path
+		if route := mux.CurrentRoute(r); route != nil {
+			if tpl, err := route.GetPathTemplate(); err == nil {
+				name = r.Method + " " + tpl
+			}
+		}
+		ctx, stats := db.WithQueryStats(db.WithEndpoint(r.Context(), name))
+		next.ServeHTTP(w, r.WithContext(ctx))
+		db.ObserveRequest(name, stats)
+	})
+}
@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"net/http"
+	"public_library/internal/experiments"
+	"public_library/internal/readinglist"
+	"strings"
+)
+
+// Experiments buckets the request into experiments by the member in the
+// X-Member-ID header or, without one, the API key in X-API-Key-ID. Requests
+// with neither are in no experiment.
+func Experiments(a *experiments.Assigner) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			unit := ""
+			if member := strings.TrimSpace(r.Header.Get(readinglist.MemberHeader)); member != "" {
+				unit = experiments.MemberUnit(member)
+			} else if key := r.Header.Get(APIKeyHeader); key != "" {
+				unit = experiments.APIKeyUnit(key)
+			}
+			if unit == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(experiments.WithUnit(r.Context(), a, unit, w.Header())))
+		})
+	}
+}
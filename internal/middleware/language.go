@@ -0,0 +1,17 @@
+package middleware
+
+import (
+	"net/http"
+	"public_library/internal/book"
+)
+
+// Language makes catalog reads return titles and descriptions translated to
+// the best match of the request's Accept-Language header
+func Language(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if accept := r.Header.Get("Accept-Language"); accept != "" {
+			r = r.WithContext(book.WithLanguages(r.Context(), accept))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
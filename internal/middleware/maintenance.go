@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"net/http"
+	"public_library/internal/admin"
+	"strconv"
+)
+
+// Maintenance rejects the wrapped (write) routes with 503 and the configured
+// message while maintenance mode is on. Read routes are not wrapped, so they
+// keep working during migrations and backups.
+func Maintenance(m *admin.Maintenance) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			state := m.State()
+			if !state.Enabled {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if state.RetryAfterSeconds > 0 {
+				w.Header().Set("Retry-After", strconv.Itoa(state.RetryAfterSeconds))
+			}
+			http.Error(w, state.Message, http.StatusServiceUnavailable)
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"public_library/internal/ratelimit"
+	"strconv"
+	"time"
+)
+
+// APIKeyHeader carries the ID of the calling API key. Like AudienceHeader it
+// is expected to be set by the authenticating gateway in front of the API.
+const APIKeyHeader = "X-API-Key-ID"
+
+// RateLimit refuses requests over their API key's rate or daily quota with
+// 429 and Retry-After. Requests without an API key are not limited.
+func RateLimit(l *ratelimit.Limiter) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(APIKeyHeader)
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			d := l.Allow(key, time.Now())
+			if d.QuotaRemaining >= 0 {
+				w.Header().Set("X-Quota-Remaining", strconv.Itoa(d.QuotaRemaining))
+			}
+			if !d.Allowed {
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(d.RetryAfter.Seconds()))))
+				msg := "rate limit exceeded"
+				if d.Reason == ratelimit.ReasonQuota {
+					msg = "daily quota exceeded"
+				}
+				http.Error(w, msg, http.StatusTooManyRequests)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
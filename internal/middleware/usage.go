@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"net/http"
+	"public_library/internal/db"
+	"public_library/internal/usage"
+	"time"
+)
+
+// Usage counts every request that carries an API key, by endpoint and
+// response status. It must run inside Endpoint, which names the endpoint.
+func Usage(rec *usage.Recorder) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(APIKeyHeader)
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			sw := &statusWriter{ResponseWriter: w}
+			next.ServeHTTP(sw, r)
+			if sw.status == 0 {
+				sw.status = http.StatusOK
+			}
+			rec.Record(key, db.EndpointFromContext(r.Context()), sw.status, time.Now())
+		})
+	}
+}
+
+// statusWriter remembers the response status. It keeps Flush working for
+// streaming handlers such as the export.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *statusWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,225 @@
+package payments
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"public_library/internal/httperr"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	svc    *Service
+	logger *zap.Logger
+}
+
+func NewHandler(svc *Service, l *zap.Logger) *Handler {
+	return &Handler{svc: svc, logger: l}
+}
+
+// POST /drawers
+
+// OpenDrawer godoc
+// @Summary Open a cash drawer session
+// @Description Opens a staff member's drawer at a branch with its opening float, which defaults to 0. Payments taken at the desk are recorded against the session until it is closed. A staff member has one drawer open at a branch at a time.
+// @Tags payments
+// @Accept json
+// @Produce json
+// @Param request body OpenRequest true "Branch, staff member and opening float"
+// @Success 201 {object} Session
+// @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /drawers [post]
+func (h *Handler) OpenDrawer(w http.ResponseWriter, r *http.Request) {
+	var req OpenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	sess, err := h.svc.Open(r.Context(), req)
+	if err != nil {
+		h.writeError(w, "failed to open drawer", err)
+		return
+	}
+	h.logger.Info("drawer opened", zap.Int("session_id", sess.ID), zap.String("branch", sess.Branch),
+		zap.String("staff", sess.Staff))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(sess)
+}
+
+// GET /drawers?branch=Central&staff=jdoe&open=true
+
+// ListDrawers godoc
+// @Summary List cash drawer sessions
+// @Description Lists drawer sessions with their totals, most recently opened first.
+// @Tags payments
+// @Produce json
+// @Param branch query string false "Only sessions at this branch"
+// @Param staff query string false "Only sessions of this staff member"
+// @Param open query bool false "Only sessions not closed yet"
+// @Success 200 {array} Session
+// @Failure 400 {object} map[string]string
+// @Router /drawers [get]
+func (h *Handler) ListDrawers(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	req := ListRequest{Branch: q.Get("branch"), Staff: q.Get("staff")}
+	if v := q.Get("open"); v != "" {
+		open, err := strconv.ParseBool(v)
+		if err != nil {
+			http.Error(w, "invalid open", http.StatusBadRequest)
+			return
+		}
+		req.Open = open
+	}
+	list, err := h.svc.List(r.Context(), req)
+	if err != nil {
+		h.writeError(w, "failed to list drawers", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// GET /drawers/{id}
+
+// GetDrawer godoc
+// @Summary Get a cash drawer session's reconciliation report
+// @Description Returns the session with the cash and card payments taken, the cash the drawer should hold and, once it is closed, the cash counted and how far over or short it is, followed by its ledger of payments.
+// @Tags payments
+// @Produce json
+// @Param id path int true "Drawer session ID"
+// @Success 200 {object} Session
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /drawers/{id} [get]
+func (h *Handler) GetDrawer(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "invalid drawer session ID")
+	if !ok {
+		return
+	}
+	sess, err := h.svc.Get(r.Context(), id)
+	if err != nil {
+		h.writeError(w, "failed to get drawer", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sess)
+}
+
+// POST /drawers/{id}/close
+
+// CloseDrawer godoc
+// @Summary Close a cash drawer session
+// @Description Closes the session with the cash counted in the drawer, float included, and returns its reconciliation report. No more payments can be taken into it.
+// @Tags payments
+// @Accept json
+// @Produce json
+// @Param id path int true "Drawer session ID"
+// @Param request body CloseRequest true "Cash counted"
+// @Success 200 {object} Session
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /drawers/{id}/close [post]
+func (h *Handler) CloseDrawer(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "invalid drawer session ID")
+	if !ok {
+		return
+	}
+	var req CloseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	sess, err := h.svc.Close(r.Context(), id, req)
+	if err != nil {
+		h.writeError(w, "failed to close drawer", err)
+		return
+	}
+	h.logger.Info("drawer closed", zap.Int("session_id", sess.ID), zap.String("expected_cash", sess.ExpectedCash),
+		zap.String("counted_cash", sess.CountedCash))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sess)
+}
+
+// POST /drawers/{id}/payments
+
+// TakePayment godoc
+// @Summary Take a payment at the desk
+// @Description Records a cash or card payment from a member into an open drawer session, in the circulation currency, towards one of their loans (loan_id) or what they owe in general. A payment can't be more than the member, or the loan, has left to pay; fines of loans claimed returned aren't owed until the claim is resolved.
+// @Tags payments
+// @Accept json
+// @Produce json
+// @Param id path int true "Drawer session ID"
+// @Param request body PaymentRequest true "Member, method and amount"
+// @Success 201 {object} Payment
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /drawers/{id}/payments [post]
+func (h *Handler) TakePayment(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "invalid drawer session ID")
+	if !ok {
+		return
+	}
+	var req PaymentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	p, err := h.svc.TakePayment(r.Context(), id, req)
+	if err != nil {
+		h.writeError(w, "failed to take payment", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(p)
+}
+
+// GET /members/{id}/payments
+
+// ListMemberPayments godoc
+// @Summary List a member's payments
+// @Description Lists the payments a member made at the desk, most recent first.
+// @Tags payments
+// @Produce json
+// @Param id path string true "Member ID"
+// @Success 200 {array} Payment
+// @Failure 404 {object} map[string]string
+// @Router /members/{id}/payments [get]
+func (h *Handler) ListMemberPayments(w http.ResponseWriter, r *http.Request) {
+	list, err := h.svc.MemberPayments(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		h.writeError(w, "failed to list payments", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+func pathID(w http.ResponseWriter, r *http.Request, msg string) (int, bool) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, msg, http.StatusBadRequest)
+		return 0, false
+	}
+	return id, true
+}
+
+func (h *Handler) writeError(w http.ResponseWriter, msg string, err error) {
+	switch {
+	case errors.Is(err, ErrInvalid):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, ErrNotFound), errors.Is(err, ErrMemberNotFound), errors.Is(err, ErrLoanNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, ErrAlreadyOpen), errors.Is(err, ErrClosed), errors.Is(err, ErrOverpaid):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		httperr.Write(w, h.logger, msg, err)
+	}
+}
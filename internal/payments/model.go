@@ -0,0 +1,87 @@
+package payments
+
+import "time"
+
+// Ways a payment is taken at the desk
+const (
+	MethodCash = "cash"
+	MethodCard = "card"
+)
+
+var methods = []string{MethodCash, MethodCard}
+
+// Session is one staff member's cash drawer at a branch, from opening it
+// with its float to closing it with the cash counted. Amounts are decimal
+// amounts in Currency, the circulation currency.
+type Session struct {
+	ID       int    `json:"id" example:"3"`
+	Branch   string `json:"branch" example:"Central"`
+	Staff    string `json:"staff" example:"jdoe"`
+	Currency string `json:"currency" example:"USD"`
+	// OpeningFloat is the cash the drawer started with
+	OpeningFloat string     `json:"opening_float" example:"100.00"`
+	OpenedAt     time.Time  `json:"opened_at"`
+	ClosedAt     *time.Time `json:"closed_at,omitempty"`
+	// Payments counts the payments taken; CashTaken and CardTaken total
+	// them by method
+	Payments  int    `json:"payments" example:"4"`
+	CashTaken string `json:"cash_taken" example:"12.75"`
+	CardTaken string `json:"card_taken" example:"24.99"`
+	// ExpectedCash is what the drawer should hold: the float and the cash
+	// taken
+	ExpectedCash string `json:"expected_cash" example:"112.75"`
+	// CountedCash is what was in the drawer when it was closed, and
+	// Difference how far that is over (positive) or short (negative) of
+	// ExpectedCash
+	CountedCash string `json:"counted_cash,omitempty" example:"112.50"`
+	Difference  string `json:"difference,omitempty" example:"-0.25"`
+	Note        string `json:"note,omitempty" example:"Short a quarter"`
+	// Ledger lists the session's payments, in the order taken; it is only
+	// included when a single session is requested
+	Ledger []Payment `json:"ledger,omitempty"`
+}
+
+// OpenRequest opens a drawer session
+type OpenRequest struct {
+	Branch       string `json:"branch" example:"Central"`
+	Staff        string `json:"staff" example:"jdoe"`
+	OpeningFloat string `json:"opening_float" example:"100.00"`
+}
+
+// CloseRequest closes a drawer session with the cash counted in it
+type CloseRequest struct {
+	CountedCash string `json:"counted_cash" example:"112.50"`
+	Note        string `json:"note" example:"Short a quarter"`
+}
+
+// Payment is money a member paid at the desk towards what they owe
+type Payment struct {
+	ID       int    `json:"id" example:"17"`
+	MemberID string `json:"member_id" example:"m-1001"`
+	// LoanID is the loan whose fine or replacement charge the payment is
+	// for, if it is for one
+	LoanID    *int      `json:"loan_id,omitempty" example:"12"`
+	SessionID int       `json:"session_id" example:"3"`
+	Method    string    `json:"method" example:"cash" enums:"cash,card"`
+	Amount    string    `json:"amount" example:"2.50"`
+	Currency  string    `json:"currency" example:"USD"`
+	Reference string    `json:"reference,omitempty" example:"Card terminal receipt 004512"`
+	TakenAt   time.Time `json:"taken_at"`
+}
+
+// PaymentRequest takes a payment into a drawer session
+type PaymentRequest struct {
+	MemberID  string `json:"member_id" example:"m-1001"`
+	LoanID    *int   `json:"loan_id,omitempty" example:"12"`
+	Method    string `json:"method" example:"cash" enums:"cash,card"`
+	Amount    string `json:"amount" example:"2.50"`
+	Reference string `json:"reference" example:""`
+}
+
+// ListRequest filters drawer sessions
+type ListRequest struct {
+	Branch string
+	Staff  string
+	// Open lists only the sessions not closed yet
+	Open bool
+}
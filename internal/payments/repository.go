@@ -0,0 +1,306 @@
+package payments
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"public_library/internal/db"
+	"public_library/utils"
+)
+
+var (
+	// ErrNotFound is returned when no drawer session has the requested id
+	ErrNotFound = errors.New("drawer session not found")
+	// ErrMemberNotFound is returned when no member has the requested id
+	ErrMemberNotFound = errors.New("member not found")
+	// ErrLoanNotFound is returned for a payment towards a loan that isn't
+	// the member's
+	ErrLoanNotFound = errors.New("loan not found for this member")
+	// ErrAlreadyOpen is returned for opening a second drawer session for
+	// the same staff member at a branch
+	ErrAlreadyOpen = errors.New("staff member already has a drawer open at this branch")
+	// ErrClosed is returned for taking payments into, or closing, a closed
+	// drawer session
+	ErrClosed = errors.New("drawer session is closed")
+	// ErrOverpaid is returned for a payment of more than the member, or the
+	// loan, has left to pay
+	ErrOverpaid = errors.New("payment is more than is owed")
+)
+
+type Repository struct {
+	db *db.DB
+}
+
+func NewRepository(conn *db.DB) *Repository {
+	return &Repository{db: conn}
+}
+
+// selectSessionsSQL selects drawer sessions ("d") with the totals of their
+// payments ("p"); the where clause is inserted before the grouping
+const selectSessionsSQL = `
+	SELECT d.id, d.branch, d.staff, d.currency, d.opening_float::text, d.opened_at, d.closed_at, count(p.id),
+		COALESCE(sum(p.amount) FILTER (WHERE p.method = 'cash'), 0)::text,
+		COALESCE(sum(p.amount) FILTER (WHERE p.method = 'card'), 0)::text,
+		(d.opening_float + COALESCE(sum(p.amount) FILTER (WHERE p.method = 'cash'), 0))::text,
+		COALESCE(d.counted_cash::text, ''),
+		COALESCE((d.counted_cash - d.opening_float - COALESCE(sum(p.amount) FILTER (WHERE p.method = 'cash'), 0))::text, ''),
+		d.note
+	FROM %[1]s d
+	LEFT JOIN %[2]s p ON p.session_id = d.id
+	%[3]s
+	GROUP BY d.id
+`
+
+func selectSessions(where string) string {
+	return fmt.Sprintf(selectSessionsSQL, utils.DrawerSessionsTable, utils.PaymentsTable, where)
+}
+
+func scanSession(row interface{ Scan(...interface{}) error }) (Session, error) {
+	var s Session
+	err := row.Scan(&s.ID, &s.Branch, &s.Staff, &s.Currency, &s.OpeningFloat, &s.OpenedAt, &s.ClosedAt, &s.Payments,
+		&s.CashTaken, &s.CardTaken, &s.ExpectedCash, &s.CountedCash, &s.Difference, &s.Note)
+	return s, err
+}
+
+const selectPaymentsSQL = `
+	SELECT id, member_id, loan_id, session_id, method, amount::text, currency, reference, taken_at
+	FROM %s
+`
+
+func selectPayments(where string) string {
+	return fmt.Sprintf(selectPaymentsSQL, utils.PaymentsTable) + where
+}
+
+func scanPayment(row interface{ Scan(...interface{}) error }) (Payment, error) {
+	var p Payment
+	err := row.Scan(&p.ID, &p.MemberID, &p.LoanID, &p.SessionID, &p.Method, &p.Amount, &p.Currency, &p.Reference,
+		&p.TakenAt)
+	return p, err
+}
+
+func queryPayments(ctx context.Context, q db.Queryer, query string, args ...interface{}) ([]Payment, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []Payment{}
+	for rows.Next() {
+		p, err := scanPayment(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, p)
+	}
+	return list, rows.Err()
+}
+
+// Open opens a drawer session, filling in s from the stored row. A staff
+// member has one drawer open at a branch at a time.
+func (r *Repository) Open(ctx context.Context, s *Session) error {
+	log.Println("<--------Open drawer session starts-------->")
+	defer log.Println("<--------Open drawer session ends-------->")
+
+	var open bool
+	check := fmt.Sprintf(`SELECT EXISTS (SELECT 1 FROM %s WHERE branch = $1 AND staff = $2 AND closed_at IS NULL)`,
+		utils.DrawerSessionsTable)
+	if err := r.db.QueryRowContext(ctx, check, s.Branch, s.Staff).Scan(&open); err != nil {
+		return err
+	}
+	if open {
+		return ErrAlreadyOpen
+	}
+	var id int
+	insert := fmt.Sprintf(`
+		INSERT INTO %s (branch, staff, currency, opening_float) VALUES ($1, $2, $3, $4::numeric)
+		RETURNING id
+	`, utils.DrawerSessionsTable)
+	if err := r.db.QueryRowContext(ctx, insert, s.Branch, s.Staff, s.Currency, s.OpeningFloat).Scan(&id); err != nil {
+		log.Printf("Failed to open a drawer session for %s at %s: %v", s.Staff, s.Branch, err)
+		return err
+	}
+	stored, err := r.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	*s = *stored
+	return nil
+}
+
+// GetByID returns the drawer session with its ledger
+func (r *Repository) GetByID(ctx context.Context, id int) (*Session, error) {
+	s, err := scanSession(r.db.QueryRowContext(ctx, selectSessions(`WHERE d.id = $1`), id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		log.Printf("Failed to get drawer session id=%d: %v", id, err)
+		return nil, err
+	}
+	s.Ledger, err = queryPayments(ctx, r.db, selectPayments(`WHERE session_id = $1 ORDER BY taken_at, id`), id)
+	if err != nil {
+		log.Printf("Failed to get the ledger of drawer session id=%d: %v", id, err)
+		return nil, err
+	}
+	return &s, nil
+}
+
+// List returns the drawer sessions matching req, most recently opened
+// first, without their ledgers
+func (r *Repository) List(ctx context.Context, req ListRequest) ([]Session, error) {
+	rows, err := r.db.QueryContext(ctx, selectSessions(`
+		WHERE ($1 = '' OR d.branch = $1) AND ($2 = '' OR d.staff = $2) AND (NOT $3 OR d.closed_at IS NULL)
+	`)+`ORDER BY d.opened_at DESC, d.id DESC`, req.Branch, req.Staff, req.Open)
+	if err != nil {
+		log.Printf("Failed to list drawer sessions: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []Session{}
+	for rows.Next() {
+		s, err := scanSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, s)
+	}
+	return list, rows.Err()
+}
+
+// Close closes an open drawer session with the cash counted in it
+func (r *Repository) Close(ctx context.Context, id int, countedCash, note string) (*Session, error) {
+	log.Println("<--------Close drawer session starts-------->")
+	defer log.Println("<--------Close drawer session ends-------->")
+
+	query := fmt.Sprintf(`
+		UPDATE %s SET closed_at = now(), counted_cash = $2::numeric, note = $3
+		WHERE id = $1 AND closed_at IS NULL
+	`, utils.DrawerSessionsTable)
+	result, err := r.db.ExecContext(ctx, query, id, countedCash, note)
+	if err != nil {
+		log.Printf("Failed to close drawer session id=%d: %v", id, err)
+		return nil, err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	s, err := r.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if n == 0 {
+		return nil, ErrClosed
+	}
+	return s, nil
+}
+
+// owedSQL is what member $1 has left to pay: the fines and replacement
+// charges of their loans, leaving out those of loans claimed returned until
+// the claim is resolved, less their payments
+const owedSQL = `
+	COALESCE((
+		SELECT sum(COALESCE(l.fine, 0) + COALESCE(l.replacement_charge, 0)) FROM %[1]s l
+		WHERE l.member_id = $1 AND (l.returned_at IS NOT NULL OR l.claimed_returned_at IS NULL)
+	), 0) - COALESCE((SELECT sum(p.amount) FROM %[2]s p WHERE p.member_id = $1), 0)
+`
+
+// TakePayment records p as taken into its drawer session, filling in p from
+// the stored row. The session must be open, and p can't be more than the
+// member has left to pay, or, for a payment towards a loan, than is left to
+// pay on the loan. The member is locked while this is checked, so
+// concurrent payments can't overpay.
+func (r *Repository) TakePayment(ctx context.Context, p *Payment) error {
+	log.Println("<--------Take payment starts-------->")
+	defer log.Println("<--------Take payment ends-------->")
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var closed bool
+	session := fmt.Sprintf(`SELECT closed_at IS NOT NULL, currency FROM %s WHERE id = $1 FOR SHARE`,
+		utils.DrawerSessionsTable)
+	err = tx.QueryRowContext(ctx, session, p.SessionID).Scan(&closed, &p.Currency)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return ErrNotFound
+	case err != nil:
+		return err
+	case closed:
+		return ErrClosed
+	}
+
+	var covered bool
+	member := fmt.Sprintf(`SELECT (`+owedSQL+`) >= $2::numeric FROM %[3]s m WHERE m.id = $1 FOR UPDATE OF m`,
+		utils.LoansTable, utils.PaymentsTable, utils.MembersTable)
+	err = tx.QueryRowContext(ctx, member, p.MemberID, p.Amount).Scan(&covered)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return ErrMemberNotFound
+	case err != nil:
+		return err
+	case !covered:
+		return ErrOverpaid
+	}
+	if p.LoanID != nil {
+		loan := fmt.Sprintf(`
+			SELECT CASE WHEN l.returned_at IS NOT NULL OR l.claimed_returned_at IS NULL
+					THEN COALESCE(l.fine, 0) + COALESCE(l.replacement_charge, 0) ELSE 0 END
+				- COALESCE((SELECT sum(p.amount) FROM %s p WHERE p.loan_id = l.id), 0) >= $3::numeric
+			FROM %s l WHERE l.id = $1 AND l.member_id = $2
+		`, utils.PaymentsTable, utils.LoansTable)
+		err = tx.QueryRowContext(ctx, loan, *p.LoanID, p.MemberID, p.Amount).Scan(&covered)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrLoanNotFound
+		case err != nil:
+			return err
+		case !covered:
+			return ErrOverpaid
+		}
+	}
+
+	var id int
+	insert := fmt.Sprintf(`
+		INSERT INTO %s (member_id, loan_id, session_id, method, amount, currency, reference)
+		VALUES ($1, $2, $3, $4, $5::numeric, $6, $7)
+		RETURNING id
+	`, utils.PaymentsTable)
+	err = tx.QueryRowContext(ctx, insert, p.MemberID, p.LoanID, p.SessionID, p.Method, p.Amount, p.Currency,
+		p.Reference).Scan(&id)
+	if err != nil {
+		log.Printf("Failed to take a payment from member id=%s: %v", p.MemberID, err)
+		return err
+	}
+	stored, err := scanPayment(tx.QueryRowContext(ctx, selectPayments(`WHERE id = $1`), id))
+	if err != nil {
+		return err
+	}
+	*p = stored
+	return tx.Commit()
+}
+
+// ListByMember returns the member's payments, most recent first
+func (r *Repository) ListByMember(ctx context.Context, memberID string) ([]Payment, error) {
+	var exists bool
+	check := fmt.Sprintf(`SELECT EXISTS (SELECT 1 FROM %s WHERE id = $1)`, utils.MembersTable)
+	if err := r.db.QueryRowContext(ctx, check, memberID).Scan(&exists); err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrMemberNotFound
+	}
+	list, err := queryPayments(ctx, r.db, selectPayments(`WHERE member_id = $1 ORDER BY taken_at DESC, id DESC`), memberID)
+	if err != nil {
+		log.Printf("Failed to list the payments of member id=%s: %v", memberID, err)
+		return nil, err
+	}
+	return list, nil
+}
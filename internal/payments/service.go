@@ -0,0 +1,153 @@
+// Package payments records fines paid in person at the circulation desk,
+// in cash or by card, into the cash drawer of a staff member's session, and
+// reconciles each drawer when it is closed
+package payments
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"public_library/internal/acquisition"
+	"public_library/internal/config"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrInvalid is wrapped by errors about the content of a request
+var ErrInvalid = errors.New("invalid payment request")
+
+const maxTextLength = 200
+
+type Service struct {
+	repo     *Repository
+	currency string
+}
+
+// NewService creates the payments service. Payments are taken in the
+// circulation currency, the one fines are charged in.
+func NewService(repo *Repository, cfg config.CirculationConfig) *Service {
+	return &Service{repo: repo, currency: cfg.Currency}
+}
+
+// Open opens a drawer session for a staff member at a branch with its
+// opening float
+func (s *Service) Open(ctx context.Context, req OpenRequest) (*Session, error) {
+	sess := &Session{Branch: strings.TrimSpace(req.Branch), Staff: strings.TrimSpace(req.Staff),
+		Currency: s.currency, OpeningFloat: strings.TrimSpace(req.OpeningFloat)}
+	if sess.Branch == "" || sess.Staff == "" ||
+		utf8.RuneCountInString(sess.Branch) > maxTextLength || utf8.RuneCountInString(sess.Staff) > maxTextLength {
+		return nil, fmt.Errorf("%w: branch and staff are required and must be at most %d characters", ErrInvalid, maxTextLength)
+	}
+	if sess.OpeningFloat == "" {
+		sess.OpeningFloat = "0"
+	}
+	if !acquisition.ValidPrice(sess.OpeningFloat) {
+		return nil, fmt.Errorf("%w: opening_float must be a non-negative amount with at most four decimals", ErrInvalid)
+	}
+	if err := s.repo.Open(ctx, sess); err != nil {
+		return nil, err
+	}
+	return formatSession(sess), nil
+}
+
+// Get returns a drawer session with its totals and ledger
+func (s *Service) Get(ctx context.Context, id int) (*Session, error) {
+	sess, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return formatSession(sess), nil
+}
+
+// List returns the drawer sessions matching req, most recently opened first
+func (s *Service) List(ctx context.Context, req ListRequest) ([]Session, error) {
+	list, err := s.repo.List(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	for i := range list {
+		formatSession(&list[i])
+	}
+	return list, nil
+}
+
+// Close closes a drawer session with the cash counted in it. The session's
+// report then shows how far the drawer is over or short of the float and
+// the cash taken.
+func (s *Service) Close(ctx context.Context, id int, req CloseRequest) (*Session, error) {
+	counted, note := strings.TrimSpace(req.CountedCash), strings.TrimSpace(req.Note)
+	if !acquisition.ValidPrice(counted) {
+		return nil, fmt.Errorf("%w: counted_cash must be a non-negative amount with at most four decimals", ErrInvalid)
+	}
+	if utf8.RuneCountInString(note) > maxTextLength {
+		return nil, fmt.Errorf("%w: note must be at most %d characters", ErrInvalid, maxTextLength)
+	}
+	sess, err := s.repo.Close(ctx, id, counted, note)
+	if err != nil {
+		return nil, err
+	}
+	return formatSession(sess), nil
+}
+
+// TakePayment records a payment from a member into an open drawer session,
+// towards one of their loans or what they owe in general. A payment can't
+// be more than is left to pay.
+func (s *Service) TakePayment(ctx context.Context, sessionID int, req PaymentRequest) (*Payment, error) {
+	p := &Payment{MemberID: strings.TrimSpace(req.MemberID), LoanID: req.LoanID, SessionID: sessionID,
+		Method: strings.ToLower(strings.TrimSpace(req.Method)), Amount: strings.TrimSpace(req.Amount),
+		Reference: strings.TrimSpace(req.Reference)}
+	if p.MemberID == "" {
+		return nil, fmt.Errorf("%w: member_id is required", ErrInvalid)
+	}
+	if !slices.Contains(methods, p.Method) {
+		return nil, fmt.Errorf("%w: method must be one of %s", ErrInvalid, strings.Join(methods, ", "))
+	}
+	if amount, ok := new(big.Rat).SetString(p.Amount); !acquisition.ValidPrice(p.Amount) || !ok || amount.Sign() == 0 {
+		return nil, fmt.Errorf("%w: amount must be a positive amount with at most four decimals", ErrInvalid)
+	}
+	if utf8.RuneCountInString(p.Reference) > maxTextLength {
+		return nil, fmt.Errorf("%w: reference must be at most %d characters", ErrInvalid, maxTextLength)
+	}
+	if err := s.repo.TakePayment(ctx, p); err != nil {
+		return nil, err
+	}
+	p.Amount = formatAmount(p.Amount, p.Currency)
+	return p, nil
+}
+
+// MemberPayments returns a member's payments, most recent first
+func (s *Service) MemberPayments(ctx context.Context, memberID string) ([]Payment, error) {
+	list, err := s.repo.ListByMember(ctx, memberID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range list {
+		list[i].Amount = formatAmount(list[i].Amount, list[i].Currency)
+	}
+	return list, nil
+}
+
+// formatSession renders the amounts of a session with the decimal places
+// of its currency
+func formatSession(sess *Session) *Session {
+	for _, amount := range []*string{&sess.OpeningFloat, &sess.CashTaken, &sess.CardTaken, &sess.ExpectedCash,
+		&sess.CountedCash, &sess.Difference} {
+		*amount = formatAmount(*amount, sess.Currency)
+	}
+	for i := range sess.Ledger {
+		sess.Ledger[i].Amount = formatAmount(sess.Ledger[i].Amount, sess.Ledger[i].Currency)
+	}
+	return sess
+}
+
+// formatAmount renders a decimal amount with the decimal places of
+// currency; empty amounts stay empty
+func formatAmount(amount, currency string) string {
+	r, ok := new(big.Rat).SetString(amount)
+	if !ok {
+		return amount
+	}
+	return acquisition.FormatAmount(r, currency)
+}
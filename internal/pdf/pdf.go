@@ -0,0 +1,127 @@
+// Package pdf writes plain, text-only PDF documents: lines of monospaced
+// text on A4 pages, enough for printable reports without a PDF library.
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Page geometry in points; Courier is 0.6 em wide at every character
+const (
+	pageWidth  = 595
+	pageHeight = 842
+	margin     = 50
+	fontSize   = 9
+	leading    = 12
+)
+
+// LinesPerPage is how many lines fit on a page
+const LinesPerPage = (pageHeight - 2*margin) / leading
+
+// LineWidth is how many characters fit on a line
+const LineWidth = (pageWidth - 2*margin) * 10 / (fontSize * 6)
+
+// Document accumulates lines and breaks them into pages
+type Document struct {
+	title string
+	pages [][]string
+}
+
+// New starts a document; title is stored in its metadata
+func New(title string) *Document {
+	return &Document{title: title}
+}
+
+// Line adds a line of text, starting a page when the current one is full.
+// Text past LineWidth is cut off.
+func (d *Document) Line(text string) {
+	if len(d.pages) == 0 || len(d.pages[len(d.pages)-1]) == LinesPerPage {
+		d.pages = append(d.pages, nil)
+	}
+	if r := []rune(text); len(r) > LineWidth {
+		text = string(r[:LineWidth])
+	}
+	d.pages[len(d.pages)-1] = append(d.pages[len(d.pages)-1], text)
+}
+
+// PageBreak starts a new page for the next line
+func (d *Document) PageBreak() {
+	d.pages = append(d.pages, nil)
+}
+
+// Bytes renders the document. An empty document has one blank page.
+func (d *Document) Bytes() []byte {
+	pages := d.pages
+	if len(pages) == 0 {
+		pages = [][]string{nil}
+	}
+
+	var buf bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	// Objects 1-4 are the catalog, page tree, font and info; each page is
+	// then a page object followed by its content stream
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>")
+	object(fmt.Sprintf("<< /Title %s /Producer (public_library) >>", literal(d.title)))
+	for i, lines := range pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>",
+			pageWidth, pageHeight, 6+2*i))
+		content := pageContent(lines)
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R /Info 4 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return buf.Bytes()
+}
+
+func pageContent(lines []string) string {
+	var b strings.Builder
+	// each line is shown with ', which first moves down one line
+	fmt.Fprintf(&b, "BT\n/F1 %d Tf\n%d TL\n%d %d Td\n", fontSize, leading, margin, pageHeight-margin-fontSize+leading)
+	for _, line := range lines {
+		b.WriteString(literal(line))
+		b.WriteString(" '\n")
+	}
+	b.WriteString("ET")
+	return b.String()
+}
+
+// literal encodes s as a PDF string in WinAnsiEncoding. Characters outside
+// Latin-1 are printed as '?'.
+func literal(s string) string {
+	var b strings.Builder
+	b.WriteByte('(')
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r >= 0x20 && r < 0x7f:
+			b.WriteRune(r)
+		case r >= 0xa0 && r <= 0xff:
+			fmt.Fprintf(&b, "\\%03o", r)
+		default:
+			b.WriteByte('?')
+		}
+	}
+	b.WriteByte(')')
+	return b.String()
+}
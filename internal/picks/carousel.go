@@ -0,0 +1,86 @@
+package picks
+
+import (
+	"context"
+	"fmt"
+	"public_library/internal/book"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Carousel returns the features on the homepage carousel today
+func (s *Service) Carousel(ctx context.Context) (*Carousel, error) {
+	today := time.Now().Format(time.DateOnly)
+	features, err := s.repo.ActiveFeatures(ctx, today, book.AllowedAudiences(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return &Carousel{Date: today, Features: features}, nil
+}
+
+// ListFeatures returns the features scheduled on day (YYYY-MM-DD), or every
+// feature when day is empty
+func (s *Service) ListFeatures(ctx context.Context, day string) ([]Feature, error) {
+	if _, err := time.Parse(time.DateOnly, day); day != "" && err != nil {
+		return nil, fmt.Errorf("%w: on must be a date (YYYY-MM-DD)", ErrInvalid)
+	}
+	return s.repo.ListFeatures(ctx, day)
+}
+
+func (s *Service) GetFeature(ctx context.Context, id int) (*Feature, error) {
+	return s.repo.GetFeature(ctx, id)
+}
+
+// ScheduleFeature validates req and schedules the list or title on the
+// carousel
+func (s *Service) ScheduleFeature(ctx context.Context, req FeatureRequest) (*Feature, error) {
+	if err := validateFeature(&req); err != nil {
+		return nil, err
+	}
+	return s.repo.CreateFeature(ctx, req)
+}
+
+// UpdateFeature validates req and reschedules the feature
+func (s *Service) UpdateFeature(ctx context.Context, id int, req FeatureRequest) (*Feature, error) {
+	if err := validateFeature(&req); err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateFeature(ctx, id, req); err != nil {
+		return nil, err
+	}
+	return s.repo.GetFeature(ctx, id)
+}
+
+// DeleteFeature takes the feature off the carousel schedule
+func (s *Service) DeleteFeature(ctx context.Context, id int) error {
+	return s.repo.DeleteFeature(ctx, id)
+}
+
+func validateFeature(req *FeatureRequest) error {
+	if (req.ListID == nil) == (req.BookID == nil) {
+		return fmt.Errorf("%w: exactly one of list_id and book_id is required", ErrInvalid)
+	}
+	if req.Position < 0 {
+		return fmt.Errorf("%w: position must not be negative", ErrInvalid)
+	}
+	req.Headline = strings.TrimSpace(req.Headline)
+	if utf8.RuneCountInString(req.Headline) > maxTextLength {
+		return fmt.Errorf("%w: headline must be at most %d characters", ErrInvalid, maxTextLength)
+	}
+	starts, err := time.Parse(time.DateOnly, req.StartsOn)
+	if err != nil {
+		return fmt.Errorf("%w: starts_on is required and must be a date (YYYY-MM-DD)", ErrInvalid)
+	}
+	if req.EndsOn == "" {
+		req.EndsOn = req.StartsOn
+	}
+	ends, err := time.Parse(time.DateOnly, req.EndsOn)
+	if err != nil {
+		return fmt.Errorf("%w: ends_on must be a date (YYYY-MM-DD)", ErrInvalid)
+	}
+	if ends.Before(starts) {
+		return fmt.Errorf("%w: ends_on must not be before starts_on", ErrInvalid)
+	}
+	return nil
+}
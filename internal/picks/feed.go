@@ -0,0 +1,68 @@
+package picks
+
+import (
+	"encoding/xml"
+	"fmt"
+	"time"
+)
+
+// atomFeed is a published list as an Atom feed (RFC 4287), one entry per
+// book in the list's order, for other sites to embed
+type atomFeed struct {
+	XMLName  xml.Name    `xml:"feed"`
+	Xmlns    string      `xml:"xmlns,attr"`
+	ID       string      `xml:"id"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle,omitempty"`
+	Updated  string      `xml:"updated"`
+	Links    []atomLink  `xml:"link"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type atomEntry struct {
+	ID      string     `xml:"id"`
+	Title   string     `xml:"title"`
+	Updated string     `xml:"updated"`
+	Author  atomAuthor `xml:"author"`
+	Summary string     `xml:"summary,omitempty"`
+	Link    atomLink   `xml:"link"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
+
+// feed builds the Atom feed of l, linking to the API mounted at pathPrefix
+func feed(l *List, pathPrefix string) atomFeed {
+	updated := l.UpdatedAt.UTC().Format(time.RFC3339)
+	base := pathPrefix + "/picks/" + l.Slug
+	f := atomFeed{
+		Xmlns:    "http://www.w3.org/2005/Atom",
+		ID:       fmt.Sprintf("urn:public-library:picks:%d", l.ID),
+		Title:    l.Title,
+		Subtitle: l.Description,
+		Updated:  updated,
+		Links: []atomLink{
+			{Rel: "self", Type: "application/atom+xml", Href: base + "/feed"},
+			{Rel: "alternate", Type: "application/json", Href: base},
+		},
+		Entries: make([]atomEntry, 0, len(l.Items)),
+	}
+	for _, it := range l.Items {
+		f.Entries = append(f.Entries, atomEntry{
+			ID:      fmt.Sprintf("urn:public-library:picks:%d:book:%d", l.ID, it.BookID),
+			Title:   it.Title,
+			Updated: updated,
+			Author:  atomAuthor{Name: it.Author},
+			Summary: it.Note,
+			Link:    atomLink{Rel: "alternate", Type: "application/json", Href: fmt.Sprintf("%s/books/%d", pathPrefix, it.BookID)},
+		})
+	}
+	return f
+}
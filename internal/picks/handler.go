@@ -0,0 +1,451 @@
+package picks
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"public_library/internal/httperr"
+	"public_library/internal/middleware"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	svc *Service
+	// pathPrefix is where the API is mounted, for the feed's links
+	pathPrefix string
+	logger     *zap.Logger
+}
+
+func NewHandler(svc *Service, pathPrefix string, l *zap.Logger) *Handler {
+	return &Handler{svc: svc, pathPrefix: pathPrefix, logger: l}
+}
+
+// GET /picks
+
+// ListPicks godoc
+// @Summary List the published staff picks
+// @Description Lists the published staff picks lists without their books, most recently updated first. item_count counts the books within the caller's audience limit.
+// @Tags picks
+// @Produce json
+// @Success 200 {array} List
+// @Router /picks [get]
+func (h *Handler) ListPicks(w http.ResponseWriter, r *http.Request) {
+	list, err := h.svc.ListPublished(r.Context())
+	if err != nil {
+		h.writeError(w, "failed to list staff picks", err)
+		return
+	}
+	embeddable(w)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// GET /picks/{slug}
+
+// GetPicks godoc
+// @Summary Get a published staff picks list
+// @Description Returns the list with its books in order, leaving out those outside the caller's audience limit.
+// @Tags picks
+// @Produce json
+// @Param slug path string true "List slug"
+// @Success 200 {object} List
+// @Failure 404 {object} map[string]string
+// @Router /picks/{slug} [get]
+func (h *Handler) GetPicks(w http.ResponseWriter, r *http.Request) {
+	l, err := h.svc.GetPublished(r.Context(), mux.Vars(r)["slug"])
+	if err != nil {
+		h.writeError(w, "failed to get staff picks", err)
+		return
+	}
+	embeddable(w)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(l)
+}
+
+// GET /picks/{slug}/feed
+
+// GetPicksFeed godoc
+// @Summary Atom feed of a published staff picks list
+// @Description Returns the list as an Atom feed with an entry per book, in order, for embedding on other sites. Entries link to the book.
+// @Tags picks
+// @Produce application/atom+xml
+// @Param slug path string true "List slug"
+// @Success 200 {string} string "Atom feed"
+// @Failure 404 {object} map[string]string
+// @Router /picks/{slug}/feed [get]
+func (h *Handler) GetPicksFeed(w http.ResponseWriter, r *http.Request) {
+	l, err := h.svc.GetPublished(r.Context(), mux.Vars(r)["slug"])
+	if err != nil {
+		h.writeError(w, "failed to get staff picks", err)
+		return
+	}
+	embeddable(w)
+	w.Header().Set("Content-Type", "application/atom+xml")
+	io.WriteString(w, xml.Header)
+	xml.NewEncoder(w).Encode(feed(l, h.pathPrefix))
+}
+
+// GET /admin/picks
+
+// ListAllPicks godoc
+// @Summary List every staff picks list
+// @Description Lists the staff picks lists, drafts included, without their books, most recently updated first.
+// @Tags admin
+// @Produce json
+// @Security AdminToken
+// @Success 200 {array} List
+// @Failure 401 {object} map[string]string
+// @Router /admin/picks [get]
+func (h *Handler) ListAllPicks(w http.ResponseWriter, r *http.Request) {
+	list, err := h.svc.List(r.Context())
+	if err != nil {
+		h.writeError(w, "failed to list staff picks", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// POST /admin/picks
+
+// CreatePicks godoc
+// @Summary Create a staff picks list
+// @Description Creates a list without books. The slug, which names it in its public URL, defaults to one made from the title. A list is only public once published.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security AdminToken
+// @Param list body ListRequest true "List to create"
+// @Success 201 {object} List
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /admin/picks [post]
+func (h *Handler) CreatePicks(w http.ResponseWriter, r *http.Request) {
+	var req ListRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	l, err := h.svc.Create(r.Context(), req)
+	if err != nil {
+		h.writeError(w, "failed to create staff picks", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(l)
+}
+
+// GET /admin/picks/{id}
+
+// GetAnyPicks godoc
+// @Summary Get a staff picks list
+// @Description Returns the list, published or not, with all its books in order.
+// @Tags admin
+// @Produce json
+// @Security AdminToken
+// @Param id path int true "List ID"
+// @Success 200 {object} List
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /admin/picks/{id} [get]
+func (h *Handler) GetAnyPicks(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	l, err := h.svc.Get(r.Context(), id)
+	if err != nil {
+		h.writeError(w, "failed to get staff picks", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(l)
+}
+
+// PUT /admin/picks/{id}
+
+// UpdatePicks godoc
+// @Summary Change a staff picks list
+// @Description Changes the list's slug, title, description and whether it is published, keeping its books.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security AdminToken
+// @Param id path int true "List ID"
+// @Param list body ListRequest true "List details"
+// @Success 200 {object} List
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /admin/picks/{id} [put]
+func (h *Handler) UpdatePicks(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	var req ListRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	l, err := h.svc.Update(r.Context(), id, req)
+	if err != nil {
+		h.writeError(w, "failed to update staff picks", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(l)
+}
+
+// PUT /admin/picks/{id}/items
+
+// SetPicksItems godoc
+// @Summary Set the books on a staff picks list
+// @Description Replaces the books on the list with the ones given, in that order, each with an optional staff note. A book can be on a list once.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security AdminToken
+// @Param id path int true "List ID"
+// @Param items body ItemsRequest true "Books in order"
+// @Success 200 {object} List
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Router /admin/picks/{id}/items [put]
+func (h *Handler) SetPicksItems(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	var req ItemsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	l, err := h.svc.SetItems(r.Context(), id, req)
+	if err != nil {
+		h.writeError(w, "failed to set staff picks books", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(l)
+}
+
+// DELETE /admin/picks/{id}
+
+// DeletePicks godoc
+// @Summary Delete a staff picks list
+// @Tags admin
+// @Security AdminToken
+// @Param id path int true "List ID"
+// @Success 204 "No Content"
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /admin/picks/{id} [delete]
+func (h *Handler) DeletePicks(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	if err := h.svc.Delete(r.Context(), id); err != nil {
+		h.writeError(w, "failed to delete staff picks", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GET /carousel
+
+// GetCarousel godoc
+// @Summary Get today's homepage carousel
+// @Description Returns the staff picks lists and titles scheduled on the carousel today, by position. Lists that aren't published and titles outside the caller's audience limit are left out.
+// @Tags picks
+// @Produce json
+// @Success 200 {object} Carousel
+// @Router /carousel [get]
+func (h *Handler) GetCarousel(w http.ResponseWriter, r *http.Request) {
+	c, err := h.svc.Carousel(r.Context())
+	if err != nil {
+		h.writeError(w, "failed to get carousel", err)
+		return
+	}
+	embeddable(w)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(c)
+}
+
+// GET /admin/carousel?on=2025-07-14
+
+// ListFeatures godoc
+// @Summary List the carousel schedule
+// @Description Lists the features scheduled on the day given, or every feature, by start date and position. Unlike the public carousel, lists that aren't published are included.
+// @Tags admin
+// @Produce json
+// @Security AdminToken
+// @Param on query string false "Day, YYYY-MM-DD"
+// @Success 200 {array} Feature
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /admin/carousel [get]
+func (h *Handler) ListFeatures(w http.ResponseWriter, r *http.Request) {
+	list, err := h.svc.ListFeatures(r.Context(), r.URL.Query().Get("on"))
+	if err != nil {
+		h.writeError(w, "failed to list carousel features", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// POST /admin/carousel
+
+// ScheduleFeature godoc
+// @Summary Schedule a feature on the carousel
+// @Description Schedules a staff picks list or a title, exactly one of list_id and book_id, on the homepage carousel from starts_on through ends_on. A list only shows while it is published.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security AdminToken
+// @Param feature body FeatureRequest true "Feature to schedule"
+// @Success 201 {object} Feature
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Router /admin/carousel [post]
+func (h *Handler) ScheduleFeature(w http.ResponseWriter, r *http.Request) {
+	var req FeatureRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	f, err := h.svc.ScheduleFeature(r.Context(), req)
+	if err != nil {
+		h.writeError(w, "failed to schedule carousel feature", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(f)
+}
+
+// GET /admin/carousel/{id}
+
+// GetFeature godoc
+// @Summary Get a carousel feature
+// @Tags admin
+// @Produce json
+// @Security AdminToken
+// @Param id path int true "Feature ID"
+// @Success 200 {object} Feature
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /admin/carousel/{id} [get]
+func (h *Handler) GetFeature(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	f, err := h.svc.GetFeature(r.Context(), id)
+	if err != nil {
+		h.writeError(w, "failed to get carousel feature", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(f)
+}
+
+// PUT /admin/carousel/{id}
+
+// UpdateFeature godoc
+// @Summary Reschedule a carousel feature
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security AdminToken
+// @Param id path int true "Feature ID"
+// @Param feature body FeatureRequest true "Feature"
+// @Success 200 {object} Feature
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Router /admin/carousel/{id} [put]
+func (h *Handler) UpdateFeature(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	var req FeatureRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	f, err := h.svc.UpdateFeature(r.Context(), id, req)
+	if err != nil {
+		h.writeError(w, "failed to update carousel feature", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(f)
+}
+
+// DELETE /admin/carousel/{id}
+
+// DeleteFeature godoc
+// @Summary Remove a feature from the carousel schedule
+// @Tags admin
+// @Security AdminToken
+// @Param id path int true "Feature ID"
+// @Success 204 "No Content"
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /admin/carousel/{id} [delete]
+func (h *Handler) DeleteFeature(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	if err := h.svc.DeleteFeature(r.Context(), id); err != nil {
+		h.writeError(w, "failed to delete carousel feature", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// embeddable lets pages on other sites fetch a published list and caches it
+// briefly, per audience limit
+func embeddable(w http.ResponseWriter) {
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	w.Header().Set("Vary", middleware.AudienceHeader)
+}
+
+func pathID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "invalid ID", http.StatusBadRequest)
+		return 0, false
+	}
+	return id, true
+}
+
+func (h *Handler) writeError(w http.ResponseWriter, msg string, err error) {
+	switch {
+	case errors.Is(err, ErrInvalid):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, ErrNotFound), errors.Is(err, ErrFeatureNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	default:
+		httperr.Write(w, h.logger, msg, err)
+	}
+}
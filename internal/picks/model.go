@@ -0,0 +1,97 @@
+package picks
+
+import "time"
+
+// List is a curated, ordered list of books put together by staff, such as
+// "Summer Reads 2025"
+type List struct {
+	ID int `json:"id" example:"4"`
+	// Slug names the list in its public URL and feed
+	Slug        string `json:"slug" example:"summer-reads-2025"`
+	Title       string `json:"title" example:"Summer Reads 2025"`
+	Description string `json:"description" example:"Our librarians' favourites for the long days."`
+	// Published lists are shown on the public endpoints and feed; drafts
+	// only to staff
+	Published bool `json:"published" example:"true"`
+	ItemCount int  `json:"item_count" example:"12"`
+	// Items are only included when a single list is requested
+	Items     []Item    `json:"items,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Item is a book on a list, in the list's order
+type Item struct {
+	Position int    `json:"position" example:"1"`
+	BookID   int    `json:"book_id" example:"7"`
+	Title    string `json:"title" example:"The Left Hand of Darkness"`
+	Author   string `json:"author" example:"Ursula K. Le Guin"`
+	// Note is the staff blurb shown with the book
+	Note string `json:"note,omitempty" example:"A winter planet for a hot afternoon."`
+}
+
+// ListRequest creates a list or changes its details
+type ListRequest struct {
+	// Slug defaults to one made from the title
+	Slug        string `json:"slug" example:"summer-reads-2025"`
+	Title       string `json:"title" example:"Summer Reads 2025"`
+	Description string `json:"description" example:"Our librarians' favourites for the long days."`
+	Published   bool   `json:"published" example:"false"`
+}
+
+// ItemRequest puts a book on a list
+type ItemRequest struct {
+	BookID int    `json:"book_id" example:"7"`
+	Note   string `json:"note" example:"A winter planet for a hot afternoon."`
+}
+
+// ItemsRequest replaces the books on a list, in order
+type ItemsRequest struct {
+	Items []ItemRequest `json:"items"`
+}
+
+// Kinds of carousel features
+const (
+	FeatureList = "list"
+	FeatureBook = "book"
+)
+
+// Feature is a staff picks list or a title scheduled on the homepage
+// carousel from StartsOn through EndsOn
+type Feature struct {
+	ID   int    `json:"id" example:"9"`
+	Kind string `json:"kind" example:"list" enums:"list,book"`
+	// ListID and Slug are set for featured lists, BookID and Author for
+	// featured titles
+	ListID *int   `json:"list_id,omitempty" example:"4"`
+	Slug   string `json:"slug,omitempty" example:"summer-reads-2025"`
+	BookID *int   `json:"book_id,omitempty" example:"7"`
+	Author string `json:"author,omitempty" example:"Ursula K. Le Guin"`
+	// Title is the featured list's or book's title
+	Title string `json:"title" example:"Summer Reads 2025"`
+	// Headline is the caption on the carousel, defaulting to the title
+	Headline string `json:"headline" example:"Books for the beach"`
+	// Features are shown by position, then in the order they were scheduled
+	Position  int       `json:"position" example:"1"`
+	StartsOn  string    `json:"starts_on" example:"2025-06-01"`
+	EndsOn    string    `json:"ends_on" example:"2025-08-31"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// FeatureRequest schedules a list or a title, exactly one of them, on the
+// carousel
+type FeatureRequest struct {
+	ListID   *int   `json:"list_id" example:"4"`
+	BookID   *int   `json:"book_id"`
+	Headline string `json:"headline" example:"Books for the beach"`
+	Position int    `json:"position" example:"1"`
+	StartsOn string `json:"starts_on" example:"2025-06-01"`
+	// EndsOn defaults to starts_on
+	EndsOn string `json:"ends_on" example:"2025-08-31"`
+}
+
+// Carousel is the homepage carousel on a day
+type Carousel struct {
+	Date     string    `json:"date" example:"2025-07-14"`
+	Features []Feature `json:"features"`
+}
@@ -0,0 +1,363 @@
+package picks
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"public_library/internal/db"
+	"public_library/utils"
+)
+
+// ErrNotFound is returned when no list has the requested id or slug, or a
+// draft is requested publicly
+var ErrNotFound = errors.New("staff picks list not found")
+
+type Repository struct {
+	db *db.DB
+}
+
+func NewRepository(conn *db.DB) *Repository {
+	return &Repository{db: conn}
+}
+
+// selectListsSQL selects lists ("l") with how many of their books are
+// visible to the audiences in $1, or every book when $1 is NULL
+const selectListsSQL = `
+	SELECT l.id, l.slug, l.title, l.description, l.published,
+		(SELECT count(*) FROM %[2]s i JOIN %[3]s b ON b.id = i.book_id
+			WHERE i.list_id = l.id AND ($1::text[] IS NULL OR b.audience = ANY($1))),
+		l.created_at, l.updated_at
+	FROM %[1]s l
+`
+
+func selectLists(where string) string {
+	return fmt.Sprintf(selectListsSQL, utils.PickListsTable, utils.PickItemsTable, utils.BooksTable) + where
+}
+
+func scanList(row interface{ Scan(...interface{}) error }) (List, error) {
+	var l List
+	err := row.Scan(&l.ID, &l.Slug, &l.Title, &l.Description, &l.Published, &l.ItemCount, &l.CreatedAt, &l.UpdatedAt)
+	return l, err
+}
+
+func (r *Repository) Create(ctx context.Context, req ListRequest) (*List, error) {
+	log.Println("<--------Create staff picks starts-------->")
+	defer log.Println("<--------Create staff picks ends-------->")
+
+	var id int
+	query := fmt.Sprintf(`
+		INSERT INTO %s (slug, title, description, published) VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`, utils.PickListsTable)
+	if err := r.db.QueryRowContext(ctx, query, req.Slug, req.Title, req.Description, req.Published).Scan(&id); err != nil {
+		log.Printf("Failed to create staff picks %s: %v", req.Slug, err)
+		return nil, err
+	}
+	return r.GetByID(ctx, id, nil)
+}
+
+// GetByID returns the list with its books visible to audiences, or every
+// book when audiences is nil
+func (r *Repository) GetByID(ctx context.Context, id int, audiences []string) (*List, error) {
+	return r.get(ctx, `WHERE l.id = $2`, id, audiences)
+}
+
+// GetPublished returns the published list with the slug and its books
+// visible to audiences, or every book when audiences is nil
+func (r *Repository) GetPublished(ctx context.Context, slug string, audiences []string) (*List, error) {
+	return r.get(ctx, `WHERE l.slug = $2 AND l.published`, slug, audiences)
+}
+
+func (r *Repository) get(ctx context.Context, where string, key interface{}, audiences []string) (*List, error) {
+	l, err := scanList(r.db.QueryRowContext(ctx, selectLists(where), audiences, key))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		log.Printf("Failed to get staff picks %v: %v", key, err)
+		return nil, err
+	}
+	if l.Items, err = r.items(ctx, l.ID, audiences); err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+// List returns the lists, published ones only if publishedOnly, most
+// recently updated first
+func (r *Repository) List(ctx context.Context, publishedOnly bool, audiences []string) ([]List, error) {
+	rows, err := r.db.QueryContext(ctx, selectLists(`
+		WHERE l.published OR NOT $2
+		ORDER BY l.updated_at DESC, l.id DESC
+	`), audiences, publishedOnly)
+	if err != nil {
+		log.Printf("Failed to list staff picks: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []List{}
+	for rows.Next() {
+		l, err := scanList(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, l)
+	}
+	return list, rows.Err()
+}
+
+func (r *Repository) items(ctx context.Context, listID int, audiences []string) ([]Item, error) {
+	query := fmt.Sprintf(`
+		SELECT i.position, i.book_id, b.title, b.author, i.note
+		FROM %s i JOIN %s b ON b.id = i.book_id
+		WHERE i.list_id = $1 AND ($2::text[] IS NULL OR b.audience = ANY($2))
+		ORDER BY i.position
+	`, utils.PickItemsTable, utils.BooksTable)
+	rows, err := r.db.QueryContext(ctx, query, listID, audiences)
+	if err != nil {
+		log.Printf("Failed to list books of staff picks id=%d: %v", listID, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []Item{}
+	for rows.Next() {
+		var it Item
+		if err := rows.Scan(&it.Position, &it.BookID, &it.Title, &it.Author, &it.Note); err != nil {
+			return nil, err
+		}
+		items = append(items, it)
+	}
+	return items, rows.Err()
+}
+
+func (r *Repository) Update(ctx context.Context, id int, req ListRequest) error {
+	log.Println("<--------Update staff picks starts-------->")
+	defer log.Println("<--------Update staff picks ends-------->")
+
+	query := fmt.Sprintf(`
+		UPDATE %s SET slug = $2, title = $3, description = $4, published = $5, updated_at = now()
+		WHERE id = $1
+	`, utils.PickListsTable)
+	res, err := r.db.ExecContext(ctx, query, id, req.Slug, req.Title, req.Description, req.Published)
+	if err != nil {
+		log.Printf("Failed to update staff picks id=%d: %v", id, err)
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// SetItems replaces the books on the list with items, numbered in order
+func (r *Repository) SetItems(ctx context.Context, id int, items []ItemRequest) error {
+	log.Println("<--------Set staff picks books starts-------->")
+	defer log.Println("<--------Set staff picks books ends-------->")
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, fmt.Sprintf(`UPDATE %s SET updated_at = now() WHERE id = $1`, utils.PickListsTable), id)
+	if err != nil {
+		log.Printf("Failed to update staff picks id=%d: %v", id, err)
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrNotFound
+	}
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE list_id = $1`, utils.PickItemsTable), id); err != nil {
+		log.Printf("Failed to clear books of staff picks id=%d: %v", id, err)
+		return err
+	}
+	insert := fmt.Sprintf(`INSERT INTO %s (list_id, position, book_id, note) VALUES ($1, $2, $3, $4)`, utils.PickItemsTable)
+	for i, it := range items {
+		if _, err := tx.ExecContext(ctx, insert, id, i+1, it.BookID, it.Note); err != nil {
+			log.Printf("Failed to add book id=%d to staff picks id=%d: %v", it.BookID, id, err)
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Delete removes the list and its books
+func (r *Repository) Delete(ctx context.Context, id int) error {
+	log.Println("<--------Delete staff picks starts-------->")
+	defer log.Println("<--------Delete staff picks ends-------->")
+
+	res, err := r.db.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE id = $1`, utils.PickListsTable), id)
+	if err != nil {
+		log.Printf("Failed to delete staff picks id=%d: %v", id, err)
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ErrFeatureNotFound is returned when no carousel feature has the requested id
+var ErrFeatureNotFound = errors.New("carousel feature not found")
+
+// selectFeaturesSQL selects carousel features ("f") with the list ("l") or
+// book ("b") they feature
+const selectFeaturesSQL = `
+	SELECT f.id, f.list_id, l.slug, f.book_id, b.author, COALESCE(l.title, b.title), f.headline, f.position,
+		f.starts_on::text, f.ends_on::text, f.created_at
+	FROM %[1]s f
+	LEFT JOIN %[2]s l ON l.id = f.list_id
+	LEFT JOIN %[3]s b ON b.id = f.book_id
+`
+
+func selectFeatures(where string) string {
+	return fmt.Sprintf(selectFeaturesSQL, utils.CarouselFeaturesTable, utils.PickListsTable, utils.BooksTable) + where
+}
+
+func scanFeature(row interface{ Scan(...interface{}) error }) (Feature, error) {
+	var f Feature
+	var slug, author sql.NullString
+	err := row.Scan(&f.ID, &f.ListID, &slug, &f.BookID, &author, &f.Title, &f.Headline, &f.Position,
+		&f.StartsOn, &f.EndsOn, &f.CreatedAt)
+	if err != nil {
+		return f, err
+	}
+	f.Slug, f.Author = slug.String, author.String
+	f.Kind = FeatureBook
+	if f.ListID != nil {
+		f.Kind = FeatureList
+	}
+	if f.Headline == "" {
+		f.Headline = f.Title
+	}
+	return f, nil
+}
+
+func (r *Repository) queryFeatures(ctx context.Context, query string, args ...interface{}) ([]Feature, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []Feature{}
+	for rows.Next() {
+		f, err := scanFeature(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, f)
+	}
+	return list, rows.Err()
+}
+
+func (r *Repository) CreateFeature(ctx context.Context, req FeatureRequest) (*Feature, error) {
+	log.Println("<--------Create carousel feature starts-------->")
+	defer log.Println("<--------Create carousel feature ends-------->")
+
+	var id int
+	query := fmt.Sprintf(`
+		INSERT INTO %s (list_id, book_id, headline, position, starts_on, ends_on) VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`, utils.CarouselFeaturesTable)
+	err := r.db.QueryRowContext(ctx, query, req.ListID, req.BookID, req.Headline, req.Position, req.StartsOn,
+		req.EndsOn).Scan(&id)
+	if err != nil {
+		log.Printf("Failed to create carousel feature %+v: %v", req, err)
+		return nil, err
+	}
+	return r.GetFeature(ctx, id)
+}
+
+func (r *Repository) GetFeature(ctx context.Context, id int) (*Feature, error) {
+	f, err := scanFeature(r.db.QueryRowContext(ctx, selectFeatures(`WHERE f.id = $1`), id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrFeatureNotFound
+		}
+		log.Printf("Failed to get carousel feature id=%d: %v", id, err)
+		return nil, err
+	}
+	return &f, nil
+}
+
+// ListFeatures returns the features scheduled on the day, or every feature
+// when day is empty, by start date and position
+func (r *Repository) ListFeatures(ctx context.Context, day string) ([]Feature, error) {
+	list, err := r.queryFeatures(ctx, selectFeatures(`
+		WHERE NULLIF($1, '') IS NULL OR NULLIF($1, '')::date BETWEEN f.starts_on AND f.ends_on
+		ORDER BY f.starts_on, f.position, f.id
+	`), day)
+	if err != nil {
+		log.Printf("Failed to list carousel features: %v", err)
+		return nil, err
+	}
+	return list, nil
+}
+
+// ActiveFeatures returns the features shown on the day, by position: those
+// scheduled on it, leaving out lists that aren't published and titles
+// outside audiences, unless audiences is nil
+func (r *Repository) ActiveFeatures(ctx context.Context, day string, audiences []string) ([]Feature, error) {
+	list, err := r.queryFeatures(ctx, selectFeatures(`
+		WHERE $1::date BETWEEN f.starts_on AND f.ends_on
+			AND (f.list_id IS NULL OR l.published)
+			AND (f.book_id IS NULL OR $2::text[] IS NULL OR b.audience = ANY($2))
+		ORDER BY f.position, f.id
+	`), day, audiences)
+	if err != nil {
+		log.Printf("Failed to list active carousel features: %v", err)
+		return nil, err
+	}
+	return list, nil
+}
+
+func (r *Repository) UpdateFeature(ctx context.Context, id int, req FeatureRequest) error {
+	log.Println("<--------Update carousel feature starts-------->")
+	defer log.Println("<--------Update carousel feature ends-------->")
+
+	query := fmt.Sprintf(`
+		UPDATE %s SET list_id = $2, book_id = $3, headline = $4, position = $5, starts_on = $6, ends_on = $7
+		WHERE id = $1
+	`, utils.CarouselFeaturesTable)
+	res, err := r.db.ExecContext(ctx, query, id, req.ListID, req.BookID, req.Headline, req.Position, req.StartsOn,
+		req.EndsOn)
+	if err != nil {
+		log.Printf("Failed to update carousel feature id=%d: %v", id, err)
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrFeatureNotFound
+	}
+	return nil
+}
+
+func (r *Repository) DeleteFeature(ctx context.Context, id int) error {
+	log.Println("<--------Delete carousel feature starts-------->")
+	defer log.Println("<--------Delete carousel feature ends-------->")
+
+	res, err := r.db.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE id = $1`, utils.CarouselFeaturesTable), id)
+	if err != nil {
+		log.Printf("Failed to delete carousel feature id=%d: %v", id, err)
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrFeatureNotFound
+	}
+	return nil
+}
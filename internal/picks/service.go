@@ -0,0 +1,148 @@
+// Package picks is staff picks: curated, ordered lists of books such as
+// "Summer Reads 2025". Staff put the lists together and publish them; the
+// published ones are public, as JSON and as an Atom feed other sites can
+// embed. The homepage carousel features lists and single titles on the days
+// staff schedule them. Members' own lists are in the readinglist package.
+package picks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"public_library/internal/book"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ErrInvalid is wrapped by errors about the content of a request
+var ErrInvalid = errors.New("invalid staff picks request")
+
+const (
+	maxTextLength        = 200
+	maxDescriptionLength = 2000
+	maxNoteLength        = 500
+	// maxItems caps the books on a list
+	maxItems = 100
+)
+
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+type Service struct {
+	repo *Repository
+}
+
+func NewService(repo *Repository) *Service {
+	return &Service{repo: repo}
+}
+
+// Create validates req and creates a list without books
+func (s *Service) Create(ctx context.Context, req ListRequest) (*List, error) {
+	if err := validate(&req); err != nil {
+		return nil, err
+	}
+	return s.repo.Create(ctx, req)
+}
+
+// Get returns a list, published or not, with all its books
+func (s *Service) Get(ctx context.Context, id int) (*List, error) {
+	return s.repo.GetByID(ctx, id, nil)
+}
+
+// List returns every list, drafts included
+func (s *Service) List(ctx context.Context) ([]List, error) {
+	return s.repo.List(ctx, false, nil)
+}
+
+// GetPublished returns the published list with the slug and its books
+// within the audience limit of ctx
+func (s *Service) GetPublished(ctx context.Context, slug string) (*List, error) {
+	return s.repo.GetPublished(ctx, slug, book.AllowedAudiences(ctx))
+}
+
+// ListPublished returns the published lists, counting their books within
+// the audience limit of ctx
+func (s *Service) ListPublished(ctx context.Context) ([]List, error) {
+	return s.repo.List(ctx, true, book.AllowedAudiences(ctx))
+}
+
+// Update validates req and changes the list's details, keeping its books
+func (s *Service) Update(ctx context.Context, id int, req ListRequest) (*List, error) {
+	if err := validate(&req); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Update(ctx, id, req); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(ctx, id, nil)
+}
+
+// SetItems validates req and replaces the books on the list, in its order
+func (s *Service) SetItems(ctx context.Context, id int, req ItemsRequest) (*List, error) {
+	if len(req.Items) > maxItems {
+		return nil, fmt.Errorf("%w: a list can have at most %d books", ErrInvalid, maxItems)
+	}
+	seen := make(map[int]bool, len(req.Items))
+	for i := range req.Items {
+		it := &req.Items[i]
+		if it.BookID <= 0 {
+			return nil, fmt.Errorf("%w: book_id is required", ErrInvalid)
+		}
+		if seen[it.BookID] {
+			return nil, fmt.Errorf("%w: book id=%d is on the list twice", ErrInvalid, it.BookID)
+		}
+		seen[it.BookID] = true
+		it.Note = strings.TrimSpace(it.Note)
+		if utf8.RuneCountInString(it.Note) > maxNoteLength {
+			return nil, fmt.Errorf("%w: note must be at most %d characters", ErrInvalid, maxNoteLength)
+		}
+	}
+	if err := s.repo.SetItems(ctx, id, req.Items); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(ctx, id, nil)
+}
+
+// Delete removes the list
+func (s *Service) Delete(ctx context.Context, id int) error {
+	return s.repo.Delete(ctx, id)
+}
+
+func validate(req *ListRequest) error {
+	req.Title = strings.TrimSpace(req.Title)
+	if req.Title == "" || utf8.RuneCountInString(req.Title) > maxTextLength {
+		return fmt.Errorf("%w: title is required and must be at most %d characters", ErrInvalid, maxTextLength)
+	}
+	req.Description = strings.TrimSpace(req.Description)
+	if utf8.RuneCountInString(req.Description) > maxDescriptionLength {
+		return fmt.Errorf("%w: description must be at most %d characters", ErrInvalid, maxDescriptionLength)
+	}
+	req.Slug = strings.TrimSpace(req.Slug)
+	if req.Slug == "" {
+		req.Slug = slugify(req.Title)
+	}
+	if !slugPattern.MatchString(req.Slug) || len(req.Slug) > maxTextLength {
+		return fmt.Errorf("%w: slug must be lowercase letters and digits separated by hyphens", ErrInvalid)
+	}
+	return nil
+}
+
+// slugify turns "Summer Reads 2025!" into "summer-reads-2025". Letters
+// outside ASCII are dropped, so a title without any gives an empty slug.
+func slugify(title string) string {
+	var b strings.Builder
+	hyphen := false
+	for _, r := range strings.ToLower(title) {
+		if r < utf8.RuneSelf && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			if hyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(r)
+			hyphen = false
+			continue
+		}
+		hyphen = true
+	}
+	return b.String()
+}
@@ -0,0 +1,158 @@
+// Package policy evaluates the loan rules of policy.rules, on top of the
+// patron group policies and format loan periods, into the terms a copy of
+// a format lends on to a member of a patron group. Circulation asks it at
+// checkout, renewal and return instead of hard-coding any of them.
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"public_library/internal/acquisition"
+	"public_library/internal/book"
+	"public_library/internal/config"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ErrInvalid is wrapped by errors about the content of a request
+var ErrInvalid = errors.New("invalid policy request")
+
+// defaultLoanDays applies to copies whose book has no known format
+const defaultLoanDays = 21
+
+// Terms are what a copy of a format lends on to a member of a patron group
+type Terms struct {
+	PatronGroup string `json:"patron_group" example:"child"`
+	Format      string `json:"format" example:"dvd"`
+	LoanDays    int    `json:"loan_days" example:"3"`
+	// LoanLimit caps the member's loans of every format
+	LoanLimit int `json:"loan_limit" example:"10"`
+	// FormatLimit caps the member's loans of some formats, this one among
+	// them
+	FormatLimit *FormatLimit `json:"format_limit,omitempty"`
+	MaxRenewals int          `json:"max_renewals" example:"1"`
+	FinePerDay  string       `json:"fine_per_day" example:"0.25"`
+	// GraceDays is how many open days past due a copy may be returned
+	// without a fine
+	GraceDays int `json:"grace_days" example:"2"`
+	// MaxFine caps the fine per copy; empty means no cap
+	MaxFine  string `json:"max_fine,omitempty" example:"10.00"`
+	Currency string `json:"currency" example:"USD"`
+	// InLibrary lends for use in the library only, due by the end of the
+	// day without renewals
+	InLibrary bool `json:"in_library,omitempty" example:"false"`
+}
+
+// FormatLimit caps how many copies in Formats a member may have out at once
+type FormatLimit struct {
+	Formats []string `json:"formats" example:"dvd,bluray"`
+	Limit   int      `json:"limit" example:"2"`
+}
+
+// Engine evaluates the configured loan rules
+type Engine struct {
+	circulation config.CirculationConfig
+	rules       []config.LoanRule
+	formats     *book.FormatCatalog
+}
+
+// NewEngine checks that the rules name known formats
+func NewEngine(circulation config.CirculationConfig, cfg config.PolicyConfig, formats *book.FormatCatalog) (*Engine, error) {
+	for i, rule := range cfg.Rules {
+		for _, code := range rule.Formats {
+			if _, ok := formats.Lookup(code); !ok {
+				return nil, fmt.Errorf("policy.rules[%d]: unknown format %q", i, code)
+			}
+		}
+	}
+	return &Engine{circulation: circulation, rules: cfg.Rules, formats: formats}, nil
+}
+
+// Terms evaluates the rules for a copy of format lent to a member of group
+func (e *Engine) Terms(group, format string) (Terms, error) {
+	g, ok := e.circulation.Groups[group]
+	if !ok {
+		return Terms{}, fmt.Errorf("%w: patron_group must be one of %s", ErrInvalid, strings.Join(config.PatronGroups, ", "))
+	}
+	t := Terms{PatronGroup: group, Format: format, LoanDays: g.LoanDays, LoanLimit: g.LoanLimit,
+		MaxRenewals: e.circulation.MaxRenewals, FinePerDay: g.FinePerDay, GraceDays: e.circulation.GraceDays,
+		Currency: e.circulation.Currency, InLibrary: g.InLibrary}
+	if t.LoanDays == 0 {
+		t.LoanDays = defaultLoanDays
+		if f, ok := e.formats.Lookup(format); ok {
+			t.LoanDays = f.LoanDays
+		}
+	}
+
+	var loanDays, maxLoans, maxRenewals, graceDays, maxFine bool
+	for _, rule := range e.rules {
+		if !matches(rule.Formats, format) || !matches(rule.Groups, group) {
+			continue
+		}
+		if rule.LoanDays != nil && !loanDays {
+			t.LoanDays, loanDays = *rule.LoanDays, true
+		}
+		if rule.MaxLoans != nil && !maxLoans {
+			if len(rule.Formats) == 0 {
+				t.LoanLimit = *rule.MaxLoans
+			} else {
+				t.FormatLimit = &FormatLimit{Formats: rule.Formats, Limit: *rule.MaxLoans}
+			}
+			maxLoans = true
+		}
+		if rule.MaxRenewals != nil && !maxRenewals {
+			t.MaxRenewals, maxRenewals = *rule.MaxRenewals, true
+		}
+		if rule.GraceDays != nil && !graceDays {
+			t.GraceDays, graceDays = *rule.GraceDays, true
+		}
+		if rule.MaxFine != "" && !maxFine {
+			t.MaxFine, maxFine = rule.MaxFine, true
+		}
+	}
+	if t.InLibrary {
+		t.LoanDays, t.MaxRenewals = 0, 0
+	}
+	return t, nil
+}
+
+func matches(list []string, value string) bool {
+	return len(list) == 0 || slices.Contains(list, value)
+}
+
+// LoanPeriod is how long a copy lent at now lends for; in-library loans
+// are due at the end of the day
+func (t Terms) LoanPeriod(now time.Time) time.Duration {
+	if t.InLibrary {
+		y, m, d := now.Date()
+		return time.Date(y, m, d+1, 0, 0, 0, 0, now.Location()).Add(-time.Second).Sub(now)
+	}
+	return time.Duration(t.LoanDays) * 24 * time.Hour
+}
+
+// Fine returns what returning a copy due at due on returned costs, for
+// each day or part of a day late that the library was open up to MaxFine,
+// or "" if nothing. closedDays is how many of the days late it was closed.
+// Copies returned within the grace period aren't fined; later ones are
+// fined from the due date.
+func (t Terms) Fine(due, returned time.Time, closedDays int) string {
+	late := returned.Sub(due)
+	rate, ok := new(big.Rat).SetString(t.FinePerDay)
+	if late <= 0 || !ok || rate.Sign() == 0 {
+		return ""
+	}
+	days := int64((late+24*time.Hour-1)/(24*time.Hour)) - int64(closedDays)
+	if days <= int64(t.GraceDays) {
+		return ""
+	}
+	fine := rate.Mul(rate, big.NewRat(days, 1))
+	if limit, ok := new(big.Rat).SetString(t.MaxFine); ok && fine.Cmp(limit) > 0 {
+		fine = limit
+	}
+	if fine.Sign() == 0 {
+		return ""
+	}
+	return acquisition.FormatAmount(fine, t.Currency)
+}
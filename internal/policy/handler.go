@@ -0,0 +1,39 @@
+package policy
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+type Handler struct {
+	engine *Engine
+}
+
+func NewHandler(engine *Engine) *Handler {
+	return &Handler{engine: engine}
+}
+
+// GET /loan-policy?patron_group=child&format=dvd
+
+// GetLoanTerms godoc
+// @Summary Evaluate the loan terms for a patron group and format
+// @Description Shows what a copy of the format lends on to a member of the group under the configured loan rules: loan period, loan limits, renewals and fines. Copies on course reserve lend on the reserve's terms instead.
+// @Tags circulation
+// @Produce json
+// @Param patron_group query string true "Patron group" Enums(child, adult, senior, student, staff)
+// @Param format query string false "Format code"
+// @Success 200 {object} Terms
+// @Failure 400 {object} map[string]string
+// @Router /loan-policy [get]
+func (h *Handler) GetLoanTerms(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	terms, err := h.engine.Terms(strings.ToLower(q.Get("patron_group")), strings.ToLower(q.Get("format")))
+	if errors.Is(err, ErrInvalid) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(terms)
+}
@@ -0,0 +1,123 @@
+package purchasing
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidMessage is wrapped by errors about a malformed EDIFACT interchange
+var ErrInvalidMessage = errors.New("invalid EDIFACT message")
+
+// segment is one EDIFACT segment: a tag and its data elements, each a list
+// of components
+type segment struct {
+	Tag      string
+	Elements [][]string
+}
+
+// value returns component j of element i, or "" if the segment has none
+func (s segment) value(i, j int) string {
+	if i >= len(s.Elements) || j >= len(s.Elements[i]) {
+		return ""
+	}
+	return s.Elements[i][j]
+}
+
+// separators are the service characters of an interchange, set by its UNA
+// segment or defaulted
+type separators struct {
+	component, element, decimal, release, terminator rune
+}
+
+var defaultSeparators = separators{component: ':', element: '+', decimal: '.', release: '?', terminator: '\''}
+
+// parseSegments splits an interchange into segments, honouring a UNA service
+// string advice and release characters. Line breaks between segments, which
+// vendors often add, are ignored.
+func parseSegments(data string) ([]segment, separators, error) {
+	sep := defaultSeparators
+	if strings.HasPrefix(data, "UNA") {
+		una := []rune(data)
+		if len(una) < 9 {
+			return nil, sep, fmt.Errorf("%w: truncated UNA segment", ErrInvalidMessage)
+		}
+		sep = separators{component: una[3], element: una[4], decimal: una[5], release: una[6], terminator: una[8]}
+		data = string(una[9:])
+	}
+
+	var segments []segment
+	var elements [][]string
+	var components []string
+	var current strings.Builder
+	released := false
+	endComponent := func() {
+		components = append(components, current.String())
+		current.Reset()
+	}
+	endElement := func() {
+		endComponent()
+		elements = append(elements, components)
+		components = nil
+	}
+	for _, r := range data {
+		switch {
+		case released:
+			current.WriteRune(r)
+			released = false
+		case r == sep.release:
+			released = true
+		case r == sep.component:
+			endComponent()
+		case r == sep.element:
+			endElement()
+		case r == sep.terminator:
+			endElement()
+			tag := strings.TrimSpace(strings.Join(elements[0], ""))
+			segments = append(segments, segment{Tag: tag, Elements: elements[1:]})
+			elements = nil
+		case (r == '\n' || r == '\r') && current.Len() == 0 && len(components) == 0 && len(elements) == 0:
+			// between segments
+		default:
+			current.WriteRune(r)
+		}
+	}
+	if strings.TrimSpace(current.String()) != "" || len(elements) > 0 {
+		return nil, sep, fmt.Errorf("%w: the last segment is not terminated", ErrInvalidMessage)
+	}
+	return segments, sep, nil
+}
+
+// segmentWriter builds an interchange with the default separators, escaping
+// service characters in values
+type segmentWriter struct {
+	b     strings.Builder
+	count int // segments since the last UNH
+}
+
+var releaser = strings.NewReplacer("?", "??", ":", "?:", "+", "?+", "'", "?'")
+
+// write adds a segment; each element is a list of components
+func (w *segmentWriter) write(tag string, elements ...[]string) {
+	w.b.WriteString(tag)
+	for _, components := range elements {
+		w.b.WriteByte('+')
+		// trailing empty components are omitted
+		for len(components) > 0 && components[len(components)-1] == "" {
+			components = components[:len(components)-1]
+		}
+		for i, c := range components {
+			if i > 0 {
+				w.b.WriteByte(':')
+			}
+			w.b.WriteString(releaser.Replace(c))
+		}
+	}
+	w.b.WriteString("'\n")
+	w.count++
+}
+
+// e is shorthand for an element's components
+func e(components ...string) []string {
+	return components
+}
@@ -0,0 +1,173 @@
+package purchasing
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"public_library/internal/httperr"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+// maxInterchangeBytes bounds a posted EDIFACT interchange; an invoice for a
+// few thousand lines is well under a megabyte
+const maxInterchangeBytes = 8 << 20
+
+type Handler struct {
+	svc    *Service
+	logger *zap.Logger
+}
+
+func NewHandler(svc *Service, l *zap.Logger) *Handler {
+	return &Handler{svc: svc, logger: l}
+}
+
+// POST /purchase-orders
+
+// CreatePurchaseOrder godoc
+// @Summary Create a purchase order
+// @Description Orders quantities of catalog books from a vendor identified by its GLN or SAN. Each line gives book_id, quantity and optionally the expected unit price; the book's ISBN, title and author are copied onto the line.
+// @Tags purchasing
+// @Accept json
+// @Produce json
+// @Param order body PurchaseOrder true "Vendor, currency, funding source and lines"
+// @Success 201 {object} PurchaseOrder
+// @Failure 400 {object} map[string]string
+// @Router /purchase-orders [post]
+func (h *Handler) CreatePurchaseOrder(w http.ResponseWriter, r *http.Request) {
+	var po PurchaseOrder
+	if err := json.NewDecoder(r.Body).Decode(&po); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if err := h.svc.Create(r.Context(), &po); err != nil {
+		h.writeError(w, "failed to create purchase order", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(po)
+}
+
+// GET /purchase-orders?status=sent
+
+// ListPurchaseOrders godoc
+// @Summary List purchase orders
+// @Tags purchasing
+// @Produce json
+// @Param status query string false "Only orders with this status" Enums(open, sent, quoted, partially_invoiced, invoiced)
+// @Success 200 {array} PurchaseOrder
+// @Failure 400 {object} map[string]string
+// @Router /purchase-orders [get]
+func (h *Handler) ListPurchaseOrders(w http.ResponseWriter, r *http.Request) {
+	orders, err := h.svc.List(r.Context(), r.URL.Query().Get("status"))
+	if err != nil {
+		h.writeError(w, "failed to list purchase orders", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(orders)
+}
+
+// GET /purchase-orders/{id}
+
+// GetPurchaseOrder godoc
+// @Summary Get a purchase order
+// @Tags purchasing
+// @Produce json
+// @Param id path int true "Purchase order ID"
+// @Success 200 {object} PurchaseOrder
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /purchase-orders/{id} [get]
+func (h *Handler) GetPurchaseOrder(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "invalid purchase order ID", http.StatusBadRequest)
+		return
+	}
+	po, err := h.svc.Get(r.Context(), id)
+	if err != nil {
+		h.writeError(w, "failed to get purchase order", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(po)
+}
+
+// POST /purchase-orders/{id}/edifact
+
+// SendPurchaseOrder godoc
+// @Summary Generate the EDIFACT ORDERS message of a purchase order
+// @Description Renders the order as an EDIFACT ORDERS interchange (D96A, EDItEUR profile) from edi.sender_id to the vendor, for upload to the vendor's EDI service, and marks an open order sent. Each line carries an RFF+LI reference that vendors echo in their quotes and invoices.
+// @Tags purchasing
+// @Produce application/EDIFACT
+// @Param id path int true "Purchase order ID"
+// @Success 200 {string} string "EDIFACT interchange"
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 503 {object} map[string]string
+// @Router /purchase-orders/{id}/edifact [post]
+func (h *Handler) SendPurchaseOrder(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "invalid purchase order ID", http.StatusBadRequest)
+		return
+	}
+	message, err := h.svc.OrdersMessage(r.Context(), id)
+	if err != nil {
+		h.writeError(w, "failed to generate ORDERS message", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/EDIFACT")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="order-%d.edi"`, id))
+	w.Write([]byte(message))
+}
+
+// POST /edi/messages
+
+// IngestVendorMessages godoc
+// @Summary Ingest vendor quotes and invoices
+// @Description Reads an EDIFACT interchange of QUOTES and INVOIC messages and applies each to the orders it references. Quotes set the quoted price of order lines; invoices add to the invoiced quantity and record every invoiced copy as an acquisition paid from the order's funding source. Lines are matched by their RFF+LI reference, then by ISBN. A message already applied to an order is reported and skipped, so an interchange can be posted again safely.
+// @Tags purchasing
+// @Accept application/EDIFACT
+// @Produce json
+// @Success 200 {array} MessageResult
+// @Failure 400 {object} map[string]string
+// @Failure 413 {object} map[string]string
+// @Router /edi/messages [post]
+func (h *Handler) IngestVendorMessages(w http.ResponseWriter, r *http.Request) {
+	results, err := h.svc.Ingest(r.Context(), http.MaxBytesReader(w, r.Body, maxInterchangeBytes))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, "interchange too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		h.writeError(w, "failed to ingest vendor messages", err)
+		return
+	}
+	for _, result := range results {
+		h.logger.Info("vendor message ingested", zap.String("type", result.Type), zap.String("reference", result.Reference),
+			zap.Int("order_id", result.OrderID), zap.Int("lines_matched", result.LinesMatched),
+			zap.Int("unmatched", len(result.Unmatched)), zap.String("error", result.Error))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(results)
+}
+
+func (h *Handler) writeError(w http.ResponseWriter, msg string, err error) {
+	switch {
+	case errors.Is(err, ErrInvalid), errors.Is(err, ErrInvalidMessage):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, ErrNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, ErrNotConfigured):
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+	default:
+		httperr.Write(w, h.logger, msg, err)
+	}
+}
@@ -0,0 +1,143 @@
+package purchasing
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Vendor message types the ingester applies
+const (
+	MessageInvoice = "INVOIC"
+	MessageQuote   = "QUOTES"
+)
+
+// parseVendorMessages reads the INVOIC and QUOTES messages of an interchange.
+// Other message types in it are skipped.
+func parseVendorMessages(data string) ([]vendorMessage, error) {
+	segments, sep, err := parseSegments(strings.TrimLeft(data, "\uFEFF \r\n"))
+	if err != nil {
+		return nil, err
+	}
+	number := func(s string) string {
+		if sep.decimal != '.' {
+			s = strings.ReplaceAll(s, string(sep.decimal), ".")
+		}
+		return strings.TrimSpace(s)
+	}
+
+	var messages []vendorMessage
+	var msg *vendorMessage
+	var line *messageLine
+	var lineAmount string
+	// endLine derives the unit price of the line from its amount if the
+	// vendor sent no PRI
+	endLine := func() {
+		if line == nil {
+			return
+		}
+		if line.UnitPrice == "" && lineAmount != "" && line.Quantity > 0 {
+			if amount, ok := new(big.Rat).SetString(lineAmount); ok {
+				line.UnitPrice = amount.Quo(amount, big.NewRat(int64(line.Quantity), 1)).FloatString(4)
+			}
+		}
+		msg.Lines = append(msg.Lines, *line)
+		line, lineAmount = nil, ""
+	}
+
+	for _, s := range segments {
+		if s.Tag == "UNH" {
+			messages = append(messages, vendorMessage{Type: s.value(1, 0)})
+			msg = &messages[len(messages)-1]
+			continue
+		}
+		if msg == nil {
+			continue
+		}
+		if s.Tag == "UNT" {
+			endLine()
+			msg = nil
+			continue
+		}
+		if msg.Type != MessageInvoice && msg.Type != MessageQuote {
+			continue
+		}
+		switch s.Tag {
+		case "BGM":
+			msg.Reference = s.value(1, 0)
+		case "DTM":
+			if s.value(0, 0) == "137" && s.value(0, 2) == "102" {
+				if d, err := time.Parse("20060102", s.value(0, 1)); err == nil {
+					msg.Date = d.Format(time.DateOnly)
+				}
+			}
+		case "CUX":
+			if msg.Currency == "" {
+				msg.Currency = s.value(0, 1)
+			}
+		case "RFF":
+			switch s.value(0, 0) {
+			case "ON":
+				if line != nil {
+					line.OrderRef = s.value(0, 1)
+				} else {
+					msg.OrderRef = s.value(0, 1)
+				}
+			case "LI":
+				if line != nil {
+					line.LineRef = s.value(0, 1)
+				}
+			}
+		case "LIN":
+			endLine()
+			line = &messageLine{Position: s.value(0, 0)}
+			if code := s.value(2, 1); code == "EN" || code == "" {
+				line.EAN = s.value(2, 0)
+			}
+		case "PIA":
+			if line != nil && line.EAN == "" && (s.value(1, 1) == "IB" || s.value(1, 1) == "EN") {
+				line.EAN = s.value(1, 0)
+			}
+		case "QTY":
+			if line == nil {
+				continue
+			}
+			qualifier := s.value(0, 0)
+			if (msg.Type == MessageInvoice && qualifier == "47") ||
+				(msg.Type == MessageQuote && (qualifier == "21" || qualifier == "1")) {
+				n, err := strconv.Atoi(number(s.value(0, 1)))
+				if err != nil || n < 0 {
+					return nil, fmt.Errorf("%w: line %s has quantity %q", ErrInvalidMessage, line.Position, s.value(0, 1))
+				}
+				line.Quantity = n
+			}
+		case "PRI":
+			if line == nil {
+				continue
+			}
+			// net price wins over calculation and gross prices
+			if q := s.value(0, 0); q == "AAA" || (line.UnitPrice == "" && (q == "AAE" || q == "AAB")) {
+				line.UnitPrice = number(s.value(0, 1))
+			}
+		case "MOA":
+			if line != nil && s.value(0, 0) == "203" {
+				lineAmount = number(s.value(0, 1))
+			}
+		case "UNS":
+			endLine()
+		}
+	}
+	if msg != nil {
+		return nil, fmt.Errorf("%w: message %s is not terminated by UNT", ErrInvalidMessage, msg.Reference)
+	}
+
+	supported := messages[:0]
+	for _, m := range messages {
+		if m.Type == MessageInvoice || m.Type == MessageQuote {
+			supported = append(supported, m)
+		}
+	}
+	return supported, nil
+}
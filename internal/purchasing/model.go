@@ -0,0 +1,86 @@
+package purchasing
+
+import "time"
+
+// Purchase order statuses. An order is sent once its ORDERS message has been
+// generated, and invoiced once every copy on it has been invoiced.
+const (
+	StatusOpen              = "open"
+	StatusSent              = "sent"
+	StatusQuoted            = "quoted"
+	StatusPartiallyInvoiced = "partially_invoiced"
+	StatusInvoiced          = "invoiced"
+)
+
+var statuses = []string{StatusOpen, StatusSent, StatusQuoted, StatusPartiallyInvoiced, StatusInvoiced}
+
+// PurchaseOrder is an order of catalog books from one vendor
+type PurchaseOrder struct {
+	ID     int    `json:"id" example:"12"`
+	Vendor string `json:"vendor" example:"Gardners Books"`
+	// VendorID identifies the vendor in EDI: a 13-digit GLN or a 7-digit SAN
+	VendorID string `json:"vendor_id" example:"5013546000008"`
+	Currency string `json:"currency" example:"GBP"`
+	// FundingSource is recorded on the acquisitions of invoiced copies
+	FundingSource string      `json:"funding_source" example:"Book fund 2026"`
+	Status        string      `json:"status" example:"open" enums:"open,sent,quoted,partially_invoiced,invoiced"`
+	Lines         []OrderLine `json:"lines"`
+	CreatedAt     time.Time   `json:"created_at"`
+	SentAt        *time.Time  `json:"sent_at,omitempty"`
+}
+
+// OrderLine is a quantity of one book on an order. Clients write book_id,
+// quantity and optionally price; the rest is filled in.
+type OrderLine struct {
+	LineNumber int    `json:"line_number" example:"1"`
+	BookID     int    `json:"book_id" example:"1"`
+	ISBN       string `json:"isbn" example:"9780743273565"`
+	Title      string `json:"title" example:"The Great Gatsby"`
+	Author     string `json:"author" example:"F. Scott Fitzgerald"`
+	Quantity   int    `json:"quantity" example:"2"`
+	// Price is the expected unit price, sent to the vendor if given
+	Price string `json:"price,omitempty" example:"9.99"`
+	// QuotedPrice is the unit price of the vendor's latest quote
+	QuotedPrice      string `json:"quoted_price,omitempty" example:"9.49"`
+	InvoicedQuantity int    `json:"invoiced_quantity" example:"0"`
+	InvoicedPrice    string `json:"invoiced_price,omitempty" example:"9.49"`
+}
+
+// MessageResult is the outcome of one message of an ingested interchange
+type MessageResult struct {
+	// Type is the EDIFACT message type, INVOIC or QUOTES
+	Type string `json:"type" example:"INVOIC"`
+	// Reference is the vendor's document number from BGM
+	Reference string `json:"reference" example:"INV-20417"`
+	OrderID   int    `json:"order_id,omitempty" example:"12"`
+	// LinesMatched counts the message lines applied to order lines
+	LinesMatched int `json:"lines_matched" example:"3"`
+	// Unmatched describes the message lines that matched no order line
+	Unmatched []string `json:"unmatched,omitempty"`
+	// AcquisitionsRecorded counts the invoiced copies recorded as acquisitions
+	AcquisitionsRecorded int `json:"acquisitions_recorded" example:"6"`
+	// Error explains why the message was not applied
+	Error string `json:"error,omitempty" example:"already processed"`
+}
+
+// vendorMessage is an INVOIC or QUOTES message read from an interchange
+type vendorMessage struct {
+	Type      string
+	Reference string
+	OrderRef  string
+	Currency  string
+	Date      string // YYYY-MM-DD, from DTM+137
+	Lines     []messageLine
+}
+
+// messageLine is one LIN group of a vendor message
+type messageLine struct {
+	Position string
+	EAN      string
+	// LineRef is the RFF+LI "order/line" reference echoed from ORDERS
+	LineRef  string
+	OrderRef string
+	Quantity int
+	// UnitPrice is from PRI, or derived from the MOA line amount
+	UnitPrice string
+}
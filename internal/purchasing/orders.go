@@ -0,0 +1,80 @@
+package purchasing
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// partyCodes returns the UNB identification code qualifier and the NAD code
+// list of a party ID: GLNs are 13 digits and SANs 7
+func partyCodes(id string) (unb, nad string) {
+	if len(id) == 7 {
+		return "31B", "31B"
+	}
+	return "14", "9"
+}
+
+// ordersMessage renders po as an EDIFACT ORDERS interchange (D96A, in the
+// EDItEUR library supply profile) from sender to the order's vendor
+func ordersMessage(po *PurchaseOrder, sender, interchangeRef string, now time.Time) string {
+	senderUNB, senderNAD := partyCodes(sender)
+	vendorUNB, vendorNAD := partyCodes(po.VendorID)
+	orderNumber := strconv.Itoa(po.ID)
+
+	var w segmentWriter
+	w.b.WriteString("UNA:+.? '\n")
+	w.write("UNB", e("UNOC", "3"), e(sender, senderUNB), e(po.VendorID, vendorUNB),
+		e(now.Format("060102"), now.Format("1504")), e(interchangeRef))
+	w.count = 0
+	w.write("UNH", e("1"), e("ORDERS", "D", "96A", "UN", "EAN008"))
+	w.write("BGM", e("220"), e(orderNumber), e("9"))
+	w.write("DTM", e("137", now.Format("20060102"), "102"))
+	w.write("NAD", e("BY"), e(sender, "", senderNAD))
+	w.write("NAD", e("SU"), e(po.VendorID, "", vendorNAD))
+	w.write("CUX", e("2", po.Currency, "9"))
+	for _, l := range po.Lines {
+		w.write("LIN", e(strconv.Itoa(l.LineNumber)), e(), e(l.ISBN, "EN"))
+		w.write("PIA", e("5"), e(l.ISBN, "IB"))
+		if l.Author != "" {
+			w.write("IMD", e("L"), e("009"), append(e("", "", ""), textComponents(l.Author)...))
+		}
+		w.write("IMD", e("L"), e("050"), append(e("", "", ""), textComponents(l.Title)...))
+		w.write("QTY", e("21", strconv.Itoa(l.Quantity)))
+		if l.Price != "" {
+			w.write("PRI", e("AAE", l.Price))
+		}
+		w.write("RFF", e("LI", lineRef(po.ID, l.LineNumber)))
+	}
+	w.write("UNS", e("S"))
+	w.write("CNT", e("2", strconv.Itoa(len(po.Lines))))
+	w.write("UNT", e(strconv.Itoa(w.count+1)), e("1"))
+	w.write("UNZ", e("1"), e(interchangeRef))
+	return w.b.String()
+}
+
+// lineRef is the RFF+LI reference of an order line, which vendors echo in
+// their quotes and invoices
+func lineRef(orderID, lineNumber int) string {
+	return fmt.Sprintf("%d/%d", orderID, lineNumber)
+}
+
+// textComponents splits free text into the two 35-character components an
+// IMD description allows, truncating the rest
+func textComponents(text string) []string {
+	const size = 35
+	text = strings.Join(strings.Fields(text), " ")
+	var parts []string
+	for len(parts) < 2 && text != "" {
+		if utf8.RuneCountInString(text) <= size {
+			parts = append(parts, text)
+			break
+		}
+		r := []rune(text)
+		parts = append(parts, string(r[:size]))
+		text = string(r[size:])
+	}
+	return parts
+}
@@ -0,0 +1,258 @@
+package purchasing
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"public_library/internal/db"
+	"public_library/utils"
+	"strconv"
+	"time"
+)
+
+// ErrNotFound is returned when no purchase order has the requested id
+var ErrNotFound = errors.New("purchase order not found")
+
+type Repository struct {
+	db *db.DB
+}
+
+func NewRepository(conn *db.DB) *Repository {
+	return &Repository{db: conn}
+}
+
+// selectOrdersSQL loads orders with their lines aggregated alongside, so a
+// list of orders is one statement. whereSQL references orders as "o".
+func selectOrdersSQL(whereSQL string) string {
+	return fmt.Sprintf(`
+		SELECT o.id, o.vendor, o.vendor_id, o.currency, o.funding_source, o.status, o.created_at, o.sent_at,
+			COALESCE(l.lines, '[]')
+		FROM %[1]s o
+		LEFT JOIN LATERAL (
+			SELECT json_agg(json_build_object(
+				'line_number', l.line_number, 'book_id', l.book_id, 'isbn', l.isbn,
+				'title', l.title, 'author', l.author, 'quantity', l.quantity,
+				'price', COALESCE(l.price::text, ''), 'quoted_price', COALESCE(l.quoted_price::text, ''),
+				'invoiced_quantity', l.invoiced_quantity, 'invoiced_price', COALESCE(l.invoiced_price::text, '')
+			) ORDER BY l.line_number) AS lines
+			FROM %[2]s l
+			WHERE l.order_id = o.id
+		) l ON true
+		WHERE %[3]s
+		ORDER BY o.id DESC
+	`, utils.PurchaseOrdersTable, utils.PurchaseOrderLinesTable, whereSQL)
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanOrder(row rowScanner) (PurchaseOrder, error) {
+	var po PurchaseOrder
+	var lines []byte
+	err := row.Scan(&po.ID, &po.Vendor, &po.VendorID, &po.Currency, &po.FundingSource, &po.Status,
+		&po.CreatedAt, &po.SentAt, &lines)
+	if err != nil {
+		return po, err
+	}
+	return po, json.Unmarshal(lines, &po.Lines)
+}
+
+// Create stores po and its lines, filling in its id, status and creation time
+func (r *Repository) Create(ctx context.Context, po *PurchaseOrder) error {
+	log.Println("<--------Create purchase order starts-------->")
+	defer log.Println("<--------Create purchase order ends-------->")
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	insertOrder := fmt.Sprintf(`
+		INSERT INTO %s (vendor, vendor_id, currency, funding_source)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, status, created_at
+	`, utils.PurchaseOrdersTable)
+	err = tx.QueryRowContext(ctx, insertOrder, po.Vendor, po.VendorID, po.Currency, po.FundingSource).
+		Scan(&po.ID, &po.Status, &po.CreatedAt)
+	if err != nil {
+		log.Printf("Failed to create purchase order: %v", err)
+		return err
+	}
+
+	insertLine := fmt.Sprintf(`
+		INSERT INTO %s (order_id, line_number, book_id, isbn, title, author, quantity, price)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, '')::numeric)
+	`, utils.PurchaseOrderLinesTable)
+	for _, l := range po.Lines {
+		_, err := tx.ExecContext(ctx, insertLine, po.ID, l.LineNumber, l.BookID, l.ISBN, l.Title, l.Author, l.Quantity, l.Price)
+		if err != nil {
+			log.Printf("Failed to create line %d of purchase order id=%d: %v", l.LineNumber, po.ID, err)
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *Repository) GetByID(ctx context.Context, id int) (*PurchaseOrder, error) {
+	po, err := scanOrder(r.db.QueryRowContext(ctx, selectOrdersSQL("o.id = $1"), id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		log.Printf("Failed to get purchase order id=%d: %v", id, err)
+		return nil, err
+	}
+	return &po, nil
+}
+
+// List returns orders newest first, optionally only those with status
+func (r *Repository) List(ctx context.Context, status string) ([]PurchaseOrder, error) {
+	rows, err := r.db.QueryContext(ctx, selectOrdersSQL("$1 = '' OR o.status = $1"), status)
+	if err != nil {
+		log.Printf("Failed to list purchase orders: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	orders := []PurchaseOrder{}
+	for rows.Next() {
+		po, err := scanOrder(rows)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, po)
+	}
+	return orders, rows.Err()
+}
+
+// MarkSent moves an open order to sent and returns a new interchange control
+// reference for its ORDERS message. Orders already sent or further along
+// keep their status, so the message can be generated again.
+func (r *Repository) MarkSent(ctx context.Context, id int) (string, error) {
+	var ref int64
+	if err := r.db.QueryRowContext(ctx, `SELECT nextval('edi_interchange_seq')`).Scan(&ref); err != nil {
+		log.Printf("Failed to allocate an EDI interchange reference: %v", err)
+		return "", err
+	}
+	query := fmt.Sprintf(`
+		UPDATE %s
+		SET status = CASE WHEN status = $2 THEN $3 ELSE status END,
+			sent_at = COALESCE(sent_at, now())
+		WHERE id = $1
+	`, utils.PurchaseOrdersTable)
+	result, err := r.db.ExecContext(ctx, query, id, StatusOpen, StatusSent)
+	if err != nil {
+		log.Printf("Failed to mark purchase order id=%d sent: %v", id, err)
+		return "", err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return "", ErrNotFound
+	}
+	return strconv.FormatInt(ref, 10), nil
+}
+
+// ApplyVendorMessage applies the lines of msg to the order with orderID in
+// one transaction: quoted prices for QUOTES, invoiced quantities and prices
+// for INVOIC. Every invoiced copy with a price is recorded as an acquisition
+// paid from the order's funding source. A message already applied to the
+// order is reported and not applied again.
+func (r *Repository) ApplyVendorMessage(ctx context.Context, orderID int, msg vendorMessage) (MessageResult, error) {
+	log.Println("<--------ApplyVendorMessage starts-------->")
+	defer log.Println("<--------ApplyVendorMessage ends-------->")
+
+	result := MessageResult{Type: msg.Type, Reference: msg.Reference, OrderID: orderID}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return result, err
+	}
+	defer tx.Rollback()
+
+	po, err := scanOrder(tx.QueryRowContext(ctx, selectOrdersSQL("o.id = $1")+" FOR UPDATE OF o", orderID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			result.Error = fmt.Sprintf("order %d not found", orderID)
+			return result, nil
+		}
+		return result, err
+	}
+
+	recordMessage := fmt.Sprintf(`
+		INSERT INTO %s (message_type, reference, order_id) VALUES ($1, $2, $3)
+		ON CONFLICT DO NOTHING
+	`, utils.EDIMessagesTable)
+	inserted, err := tx.ExecContext(ctx, recordMessage, msg.Type, msg.Reference, orderID)
+	if err != nil {
+		return result, err
+	}
+	if n, err := inserted.RowsAffected(); err != nil {
+		return result, err
+	} else if n == 0 {
+		result.Error = "already processed"
+		return result, nil
+	}
+
+	matches, unmatched := matchLines(&po, msg)
+	result.LinesMatched, result.Unmatched = len(matches), unmatched
+
+	currency := msg.Currency
+	if currency == "" {
+		currency = po.Currency
+	}
+	acquiredOn := msg.Date
+	if acquiredOn == "" {
+		acquiredOn = time.Now().Format(time.DateOnly)
+	}
+	updateQuote := fmt.Sprintf(`
+		UPDATE %s SET quoted_price = $3::numeric WHERE order_id = $1 AND line_number = $2
+	`, utils.PurchaseOrderLinesTable)
+	updateInvoice := fmt.Sprintf(`
+		UPDATE %s
+		SET invoiced_quantity = invoiced_quantity + $3, invoiced_price = COALESCE(NULLIF($4, '')::numeric, invoiced_price)
+		WHERE order_id = $1 AND line_number = $2
+	`, utils.PurchaseOrderLinesTable)
+	insertAcquisitions := fmt.Sprintf(`
+		INSERT INTO %s (book_id, price, currency, funding_source, acquired_on)
+		SELECT $1, $2::numeric, $3, $4, $5 FROM generate_series(1, $6)
+	`, utils.AcquisitionsTable)
+
+	for _, m := range matches {
+		line := &po.Lines[m.index]
+		if msg.Type == MessageQuote {
+			if m.unitPrice == "" {
+				continue
+			}
+			if _, err := tx.ExecContext(ctx, updateQuote, orderID, line.LineNumber, m.unitPrice); err != nil {
+				return result, err
+			}
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, updateInvoice, orderID, line.LineNumber, m.quantity, m.unitPrice); err != nil {
+			return result, err
+		}
+		line.InvoicedQuantity += m.quantity
+		if m.unitPrice != "" && m.quantity > 0 {
+			_, err := tx.ExecContext(ctx, insertAcquisitions, line.BookID, m.unitPrice, currency, po.FundingSource, acquiredOn, m.quantity)
+			if err != nil {
+				return result, err
+			}
+			result.AcquisitionsRecorded += m.quantity
+		}
+	}
+
+	status := nextStatus(&po, msg.Type)
+	updateStatus := fmt.Sprintf(`UPDATE %s SET status = $2 WHERE id = $1`, utils.PurchaseOrdersTable)
+	if _, err := tx.ExecContext(ctx, updateStatus, orderID, status); err != nil {
+		return result, err
+	}
+	if err := tx.Commit(); err != nil {
+		log.Printf("Failed to apply %s %s to purchase order id=%d: %v", msg.Type, msg.Reference, orderID, err)
+		return result, err
+	}
+	return result, nil
+}
@@ -0,0 +1,245 @@
+// Package purchasing keeps purchase orders of catalog books and trades them
+// with vendors over EDIFACT: orders are sent as ORDERS messages, and the
+// vendors' QUOTES and INVOIC messages are read back into the orders. Copies
+// invoiced are recorded as acquisitions.
+package purchasing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"public_library/internal/acquisition"
+	"public_library/internal/book"
+	"public_library/internal/config"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+var (
+	// ErrInvalid is wrapped by errors about the content of an order
+	ErrInvalid = errors.New("invalid purchase order")
+	// ErrNotConfigured is returned when EDI is used without edi.sender_id
+	ErrNotConfigured = errors.New("EDI is not configured: set edi.sender_id")
+)
+
+const (
+	maxLineQuantity = 9999
+	maxTextLength   = 200
+)
+
+// partyID matches a GLN (13 digits) or a SAN (7 digits)
+var partyID = regexp.MustCompile(`^(\d{13}|\d{7})$`)
+
+type Service struct {
+	repo   *Repository
+	books  *book.Service
+	sender string
+}
+
+func NewService(repo *Repository, books *book.Service, cfg config.EDIConfig) *Service {
+	return &Service{repo: repo, books: books, sender: cfg.SenderID}
+}
+
+// Create validates po and stores it as an open order. Each line must name a
+// catalog book with an ISBN, which is copied onto the line with the title
+// and author, since EDI vendors match lines by ISBN.
+func (s *Service) Create(ctx context.Context, po *PurchaseOrder) error {
+	po.Vendor = strings.TrimSpace(po.Vendor)
+	if po.Vendor == "" || utf8.RuneCountInString(po.Vendor) > maxTextLength {
+		return fmt.Errorf("%w: vendor is required and must be at most %d characters", ErrInvalid, maxTextLength)
+	}
+	po.VendorID = strings.TrimSpace(po.VendorID)
+	if !partyID.MatchString(po.VendorID) {
+		return fmt.Errorf("%w: vendor_id must be a 13-digit GLN or a 7-digit SAN", ErrInvalid)
+	}
+	currency, ok := acquisition.NormalizeCurrency(po.Currency)
+	if !ok {
+		return fmt.Errorf("%w: currency must be an ISO 4217 code such as USD", ErrInvalid)
+	}
+	po.Currency = currency
+	po.FundingSource = strings.TrimSpace(po.FundingSource)
+	if utf8.RuneCountInString(po.FundingSource) > maxTextLength {
+		return fmt.Errorf("%w: funding_source must be at most %d characters", ErrInvalid, maxTextLength)
+	}
+	if len(po.Lines) == 0 {
+		return fmt.Errorf("%w: an order needs at least one line", ErrInvalid)
+	}
+
+	for i := range po.Lines {
+		l := &po.Lines[i]
+		l.LineNumber = i + 1
+		if l.Quantity < 1 || l.Quantity > maxLineQuantity {
+			return fmt.Errorf("%w: line %d: quantity must be between 1 and %d", ErrInvalid, l.LineNumber, maxLineQuantity)
+		}
+		l.Price = strings.TrimSpace(l.Price)
+		if l.Price != "" && !acquisition.ValidPrice(l.Price) {
+			return fmt.Errorf("%w: line %d: price must be a decimal amount with at most four decimals", ErrInvalid, l.LineNumber)
+		}
+		b, err := s.books.Get(ctx, l.BookID)
+		if err != nil {
+			if errors.Is(err, book.ErrNotFound) {
+				return fmt.Errorf("%w: line %d: book %d not found", ErrInvalid, l.LineNumber, l.BookID)
+			}
+			return err
+		}
+		if l.ISBN = b.EAN(); l.ISBN == "" {
+			return fmt.Errorf("%w: line %d: book %d has no ISBN to order by", ErrInvalid, l.LineNumber, l.BookID)
+		}
+		l.Title, l.Author = b.Title, b.Author
+		l.QuotedPrice, l.InvoicedQuantity, l.InvoicedPrice = "", 0, ""
+	}
+	return s.repo.Create(ctx, po)
+}
+
+func (s *Service) Get(ctx context.Context, id int) (*PurchaseOrder, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+// List returns orders newest first, optionally only those with status
+func (s *Service) List(ctx context.Context, status string) ([]PurchaseOrder, error) {
+	if status != "" && !slices.Contains(statuses, status) {
+		return nil, fmt.Errorf("%w: status must be one of %s", ErrInvalid, strings.Join(statuses, ", "))
+	}
+	return s.repo.List(ctx, status)
+}
+
+// OrdersMessage renders the order as an EDIFACT ORDERS interchange for its
+// vendor and marks it sent
+func (s *Service) OrdersMessage(ctx context.Context, id int) (string, error) {
+	if s.sender == "" {
+		return "", ErrNotConfigured
+	}
+	po, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	ref, err := s.repo.MarkSent(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	return ordersMessage(po, s.sender, ref, time.Now()), nil
+}
+
+// Ingest applies the QUOTES and INVOIC messages of an interchange to the
+// orders they reference. Lines are assigned to orders by their RFF+ON or
+// RFF+LI reference, or the message's RFF+ON; each message and order pair is
+// applied, and reported, on its own.
+func (s *Service) Ingest(ctx context.Context, body io.Reader) ([]MessageResult, error) {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+	messages, err := parseVendorMessages(string(data))
+	if err != nil {
+		return nil, err
+	}
+
+	results := []MessageResult{}
+	for _, msg := range messages {
+		byOrder, order, unassigned := splitByOrder(msg)
+		if len(unassigned) > 0 {
+			results = append(results, MessageResult{Type: msg.Type, Reference: msg.Reference,
+				Unmatched: unassigned, Error: "lines reference no purchase order"})
+		}
+		for _, orderID := range order {
+			part := msg
+			part.Lines = byOrder[orderID]
+			result, err := s.repo.ApplyVendorMessage(ctx, orderID, part)
+			if err != nil {
+				return nil, err
+			}
+			results = append(results, result)
+		}
+	}
+	return results, nil
+}
+
+// splitByOrder groups a message's lines by the order they reference, with
+// the order IDs in first-seen order. Lines with no usable reference are
+// described in unassigned.
+func splitByOrder(msg vendorMessage) (byOrder map[int][]messageLine, order []int, unassigned []string) {
+	byOrder = make(map[int][]messageLine)
+	for _, l := range msg.Lines {
+		ref := l.OrderRef
+		if ref == "" {
+			ref = msg.OrderRef
+		}
+		if ref == "" {
+			ref, _, _ = strings.Cut(l.LineRef, "/")
+		}
+		id, err := strconv.Atoi(strings.TrimSpace(ref))
+		if err != nil || id < 1 {
+			unassigned = append(unassigned, fmt.Sprintf("line %s: no order reference", l.Position))
+			continue
+		}
+		if _, ok := byOrder[id]; !ok {
+			order = append(order, id)
+		}
+		byOrder[id] = append(byOrder[id], l)
+	}
+	return byOrder, order, unassigned
+}
+
+// lineMatch is a message line applied to the order line at index
+type lineMatch struct {
+	index     int
+	quantity  int
+	unitPrice string
+}
+
+// matchLines pairs message lines with order lines, by the RFF+LI reference
+// echoed from the ORDERS message or else by ISBN
+func matchLines(po *PurchaseOrder, msg vendorMessage) ([]lineMatch, []string) {
+	var matches []lineMatch
+	var unmatched []string
+	for _, l := range msg.Lines {
+		index := -1
+		if orderRef, lineRef, ok := strings.Cut(l.LineRef, "/"); ok && orderRef == strconv.Itoa(po.ID) {
+			if n, err := strconv.Atoi(lineRef); err == nil {
+				index = slices.IndexFunc(po.Lines, func(o OrderLine) bool { return o.LineNumber == n })
+			}
+		}
+		if index < 0 && l.EAN != "" {
+			index = slices.IndexFunc(po.Lines, func(o OrderLine) bool { return o.ISBN == l.EAN })
+		}
+		if index < 0 {
+			unmatched = append(unmatched, fmt.Sprintf("line %s: EAN %s is not on order %d", l.Position, l.EAN, po.ID))
+			continue
+		}
+		price := l.UnitPrice
+		if price != "" && !acquisition.ValidPrice(price) {
+			unmatched = append(unmatched, fmt.Sprintf("line %s: price %q is not a valid amount", l.Position, price))
+			price = ""
+		}
+		matches = append(matches, lineMatch{index: index, quantity: l.Quantity, unitPrice: price})
+	}
+	return matches, unmatched
+}
+
+// nextStatus is the order's status after a message of msgType was applied;
+// the invoiced quantities of po are already updated
+func nextStatus(po *PurchaseOrder, msgType string) string {
+	if msgType == MessageQuote {
+		if po.Status == StatusOpen || po.Status == StatusSent {
+			return StatusQuoted
+		}
+		return po.Status
+	}
+	invoiced, complete := false, true
+	for _, l := range po.Lines {
+		invoiced = invoiced || l.InvoicedQuantity > 0
+		complete = complete && l.InvoicedQuantity >= l.Quantity
+	}
+	switch {
+	case complete:
+		return StatusInvoiced
+	case invoiced:
+		return StatusPartiallyInvoiced
+	}
+	return po.Status
+}
@@ -0,0 +1,120 @@
+package ratelimit
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"public_library/internal/httperr"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	repo    *Repository
+	limiter *Limiter
+	logger  *zap.Logger
+}
+
+func NewHandler(r *Repository, l *Limiter, logger *zap.Logger) *Handler {
+	return &Handler{repo: r, limiter: l, logger: logger}
+}
+
+// GET /admin/rate-limits
+
+// ListRateLimits godoc
+// @Summary List rate limits
+// @Description Returns the default limit and every per-key override with its usage today on this instance
+// @Tags admin
+// @Produce json
+// @Security AdminToken
+// @Success 200 {object} LimitsResponse
+// @Failure 401 {object} map[string]string
+// @Router /admin/rate-limits [get]
+func (h *Handler) ListRateLimits(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(LimitsResponse{
+		Default:   h.limiter.Default(),
+		Overrides: h.limiter.Statuses(),
+	})
+}
+
+// GET /admin/rate-limits/{key}
+
+// GetRateLimit godoc
+// @Summary Get the rate limit of an API key
+// @Tags admin
+// @Produce json
+// @Security AdminToken
+// @Param key path string true "API key ID"
+// @Success 200 {object} KeyStatus
+// @Failure 401 {object} map[string]string
+// @Router /admin/rate-limits/{key} [get]
+func (h *Handler) GetRateLimit(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.limiter.Status(mux.Vars(r)["key"]))
+}
+
+// PUT /admin/rate-limits/{key}
+
+// PutRateLimit godoc
+// @Summary Set the rate limit of an API key
+// @Description Stores an override that takes effect on this instance at once and on the others at their next reload
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security AdminToken
+// @Param key path string true "API key ID"
+// @Param limit body Limit true "Rate limit; 0 means unlimited"
+// @Success 200 {object} KeyStatus
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /admin/rate-limits/{key} [put]
+func (h *Handler) PutRateLimit(w http.ResponseWriter, r *http.Request) {
+	kl := KeyLimit{APIKeyID: mux.Vars(r)["key"]}
+	if err := json.NewDecoder(r.Body).Decode(&kl.Limit); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if kl.RequestsPerMinute < 0 || kl.Burst < 0 || kl.DailyQuota < 0 {
+		http.Error(w, "limits must not be negative", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.repo.Put(r.Context(), &kl); err != nil {
+		httperr.Write(w, h.logger, "failed to set rate limit", err)
+		return
+	}
+	h.limiter.set(kl)
+	h.logger.Info("Rate limit changed", zap.String("api_key_id", kl.APIKeyID),
+		zap.Int("requests_per_minute", kl.RequestsPerMinute), zap.Int("daily_quota", kl.DailyQuota))
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.limiter.Status(kl.APIKeyID))
+}
+
+// DELETE /admin/rate-limits/{key}
+
+// DeleteRateLimit godoc
+// @Summary Remove the rate limit override of an API key
+// @Description The key returns to the default limit
+// @Tags admin
+// @Security AdminToken
+// @Param key path string true "API key ID"
+// @Success 204
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /admin/rate-limits/{key} [delete]
+func (h *Handler) DeleteRateLimit(w http.ResponseWriter, r *http.Request) {
+	key := mux.Vars(r)["key"]
+	if err := h.repo.Delete(r.Context(), key); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			http.Error(w, "no rate limit override for this key", http.StatusNotFound)
+			return
+		}
+		httperr.Write(w, h.logger, "failed to delete rate limit", err)
+		return
+	}
+	h.limiter.unset(key)
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,212 @@
+// Package ratelimit enforces per-API-key request rates and daily quotas.
+// Limits come from the ratelimit section of the configuration, overridden
+// per key by rows in api_key_limits that admins edit at runtime.
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"public_library/internal/config"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Reasons a request is refused
+const (
+	ReasonRate  = "rate"
+	ReasonQuota = "quota"
+)
+
+// Decision is the outcome of Limiter.Allow
+type Decision struct {
+	Allowed bool
+	// Reason is ReasonRate or ReasonQuota when the request is refused
+	Reason string
+	// RetryAfter is when the refused request could succeed
+	RetryAfter time.Duration
+	// QuotaRemaining is what is left of the daily quota, or -1 without one
+	QuotaRemaining int
+}
+
+type keyState struct {
+	tokens float64
+	last   time.Time
+	day    string
+	used   int
+}
+
+// Limiter applies a token bucket and a daily quota per API key. Usage is
+// counted per instance; every instance reloads the overrides on its own.
+type Limiter struct {
+	repo     *Repository
+	defaults Limit
+	logger   *zap.Logger
+
+	mu        sync.Mutex
+	overrides map[string]KeyLimit
+	states    map[string]*keyState
+}
+
+func NewLimiter(repo *Repository, cfg config.RateLimitConfig, logger *zap.Logger) *Limiter {
+	return &Limiter{
+		repo: repo,
+		defaults: Limit{
+			RequestsPerMinute: cfg.RequestsPerMinute,
+			Burst:             cfg.Burst,
+			DailyQuota:        cfg.DailyQuota,
+		},
+		logger:    logger,
+		overrides: make(map[string]KeyLimit),
+		states:    make(map[string]*keyState),
+	}
+}
+
+// Default is the limit of keys without an override
+func (l *Limiter) Default() Limit {
+	return l.defaults
+}
+
+// Reload replaces the overrides with the rows in the database
+func (l *Limiter) Reload(ctx context.Context) error {
+	list, err := l.repo.List(ctx)
+	if err != nil {
+		return err
+	}
+	overrides := make(map[string]KeyLimit, len(list))
+	for _, kl := range list {
+		overrides[kl.APIKeyID] = kl
+	}
+	l.mu.Lock()
+	l.overrides = overrides
+	l.mu.Unlock()
+	return nil
+}
+
+// StartReloader reloads the overrides every interval until ctx is cancelled,
+// so changes made through another instance are picked up. A non-positive
+// interval only loads them once.
+func (l *Limiter) StartReloader(ctx context.Context, interval time.Duration) {
+	go func() {
+		if err := l.Reload(ctx); err != nil {
+			l.logger.Error("Rate limit reload failed", zap.Error(err))
+		}
+		if interval <= 0 {
+			return
+		}
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := l.Reload(ctx); err != nil {
+					l.logger.Error("Rate limit reload failed", zap.Error(err))
+				}
+			}
+		}
+	}()
+}
+
+// set and unset apply an admin change on this instance immediately
+func (l *Limiter) set(kl KeyLimit) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.overrides[kl.APIKeyID] = kl
+}
+
+func (l *Limiter) unset(apiKeyID string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.overrides, apiKeyID)
+}
+
+// Status returns the effective limit of apiKeyID and its usage today
+func (l *Limiter) Status(apiKeyID string) KeyStatus {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	status := KeyStatus{APIKeyID: apiKeyID, Limit: l.defaults}
+	if kl, ok := l.overrides[apiKeyID]; ok {
+		updated := kl.UpdatedAt
+		status.Limit, status.Override, status.UpdatedAt = kl.Limit, true, &updated
+	}
+	if st, ok := l.states[apiKeyID]; ok && st.day == today(time.Now()) {
+		status.UsedToday = st.used
+	}
+	return status
+}
+
+// Statuses returns the status of every key with an override
+func (l *Limiter) Statuses() []KeyStatus {
+	l.mu.Lock()
+	keys := make([]string, 0, len(l.overrides))
+	for k := range l.overrides {
+		keys = append(keys, k)
+	}
+	l.mu.Unlock()
+
+	statuses := make([]KeyStatus, 0, len(keys))
+	for _, k := range keys {
+		statuses = append(statuses, l.Status(k))
+	}
+	return statuses
+}
+
+// Allow takes one request of apiKeyID from its bucket and daily quota
+func (l *Limiter) Allow(apiKeyID string, now time.Time) Decision {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	limit := l.defaults
+	if kl, ok := l.overrides[apiKeyID]; ok {
+		limit = kl.Limit
+	}
+	burst := float64(limit.Burst)
+	if burst <= 0 {
+		burst = float64(limit.RequestsPerMinute)
+	}
+
+	st, ok := l.states[apiKeyID]
+	if !ok {
+		st = &keyState{tokens: burst, last: now}
+		l.states[apiKeyID] = st
+	}
+	if day := today(now); st.day != day {
+		st.day, st.used = day, 0
+	}
+
+	d := Decision{Allowed: true, QuotaRemaining: -1}
+	if limit.DailyQuota > 0 && st.used >= limit.DailyQuota {
+		u := now.UTC()
+		midnight := time.Date(u.Year(), u.Month(), u.Day()+1, 0, 0, 0, 0, time.UTC)
+		return Decision{Reason: ReasonQuota, RetryAfter: midnight.Sub(now), QuotaRemaining: 0}
+	}
+
+	if limit.RequestsPerMinute > 0 {
+		perSecond := float64(limit.RequestsPerMinute) / 60
+		st.tokens = math.Min(burst, st.tokens+now.Sub(st.last).Seconds()*perSecond)
+		st.last = now
+		if st.tokens < 1 {
+			wait := time.Duration((1 - st.tokens) / perSecond * float64(time.Second))
+			d = Decision{Reason: ReasonRate, RetryAfter: wait, QuotaRemaining: -1}
+			if limit.DailyQuota > 0 {
+				d.QuotaRemaining = limit.DailyQuota - st.used
+			}
+			return d
+		}
+		st.tokens--
+	}
+
+	st.used++
+	if limit.DailyQuota > 0 {
+		d.QuotaRemaining = limit.DailyQuota - st.used
+	}
+	return d
+}
+
+func today(now time.Time) string {
+	return now.UTC().Format(time.DateOnly)
+}
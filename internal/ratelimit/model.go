@@ -0,0 +1,36 @@
+package ratelimit
+
+import "time"
+
+// Limit is the request rate and daily quota of an API key. Zero means
+// unlimited for that dimension.
+type Limit struct {
+	RequestsPerMinute int `json:"requests_per_minute" example:"600"`
+	// Burst is how many requests may arrive at once; 0 uses RequestsPerMinute
+	Burst      int `json:"burst" example:"60"`
+	DailyQuota int `json:"daily_quota" example:"50000"`
+}
+
+// KeyLimit is an override stored for one API key
+type KeyLimit struct {
+	APIKeyID string `json:"api_key_id" example:"catalog-kiosk"`
+	Limit
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// KeyStatus is the effective limit of an API key and its usage today
+type KeyStatus struct {
+	APIKeyID string `json:"api_key_id" example:"catalog-kiosk"`
+	Limit
+	// Override is false when the key runs on the default limit
+	Override  bool       `json:"override"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+	// UsedToday counts the requests this instance allowed since midnight UTC
+	UsedToday int `json:"used_today" example:"1234"`
+}
+
+// LimitsResponse lists the default limit and every override
+type LimitsResponse struct {
+	Default   Limit       `json:"default"`
+	Overrides []KeyStatus `json:"overrides"`
+}
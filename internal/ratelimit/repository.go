@@ -0,0 +1,85 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"public_library/internal/db"
+	"public_library/utils"
+)
+
+var ErrNotFound = errors.New("rate limit override not found")
+
+type Repository struct {
+	db *db.DB
+}
+
+func NewRepository(conn *db.DB) *Repository {
+	return &Repository{db: conn}
+}
+
+// List loads every per-key override
+func (r *Repository) List(ctx context.Context) ([]KeyLimit, error) {
+	log.Println("<--------List rate limits starts-------->")
+	defer log.Println("<--------List rate limits ends-------->")
+
+	query := fmt.Sprintf(`SELECT api_key_id, requests_per_minute, burst, daily_quota, updated_at
+		FROM %s ORDER BY api_key_id`, utils.APIKeyLimitsTable)
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		log.Printf("Failed to list rate limits: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []KeyLimit
+	for rows.Next() {
+		var kl KeyLimit
+		if err := rows.Scan(&kl.APIKeyID, &kl.RequestsPerMinute, &kl.Burst, &kl.DailyQuota, &kl.UpdatedAt); err != nil {
+			log.Printf("Failed to scan rate limit: %v", err)
+			return nil, err
+		}
+		list = append(list, kl)
+	}
+	return list, rows.Err()
+}
+
+// Put creates or replaces the override of kl.APIKeyID and sets UpdatedAt
+func (r *Repository) Put(ctx context.Context, kl *KeyLimit) error {
+	log.Println("<--------Put rate limit starts-------->")
+	defer log.Println("<--------Put rate limit ends-------->")
+
+	query := fmt.Sprintf(`INSERT INTO %s (api_key_id, requests_per_minute, burst, daily_quota)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (api_key_id) DO UPDATE SET
+			requests_per_minute = EXCLUDED.requests_per_minute,
+			burst = EXCLUDED.burst,
+			daily_quota = EXCLUDED.daily_quota,
+			updated_at = now()
+		RETURNING updated_at`, utils.APIKeyLimitsTable)
+	err := r.db.QueryRowContext(ctx, query, kl.APIKeyID, kl.RequestsPerMinute, kl.Burst, kl.DailyQuota).Scan(&kl.UpdatedAt)
+	if err != nil {
+		log.Printf("Failed to put rate limit for %q: %v", kl.APIKeyID, err)
+		return err
+	}
+	return nil
+}
+
+// Delete removes the override of apiKeyID, returning it to the default limit
+func (r *Repository) Delete(ctx context.Context, apiKeyID string) error {
+	log.Println("<--------Delete rate limit starts-------->")
+	defer log.Println("<--------Delete rate limit ends-------->")
+
+	query := fmt.Sprintf(`DELETE FROM %s WHERE api_key_id = $1`, utils.APIKeyLimitsTable)
+	res, err := r.db.ExecContext(ctx, query, apiKeyID)
+	if err != nil {
+		log.Printf("Failed to delete rate limit for %q: %v", apiKeyID, err)
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		log.Printf("No rate limit override for %q", apiKeyID)
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package readinglist
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"public_library/internal/httperr"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// MemberHeader identifies the member whose reading list a request is for.
+// Like X-Catalog-Audience it is expected to be set by the authenticating
+// gateway in front of the API.
+const MemberHeader = "X-Member-ID"
+
+// maxImportBytes bounds an uploaded export; a Goodreads export of several
+// thousand books is a few megabytes
+const maxImportBytes = 32 << 20
+
+type Handler struct {
+	svc    *Service
+	logger *zap.Logger
+}
+
+func NewHandler(svc *Service, l *zap.Logger) *Handler {
+	return &Handler{svc: svc, logger: l}
+}
+
+func memberID(w http.ResponseWriter, r *http.Request) (string, bool) {
+	id := strings.TrimSpace(r.Header.Get(MemberHeader))
+	if id == "" {
+		http.Error(w, MemberHeader+" header is required", http.StatusUnauthorized)
+		return "", false
+	}
+	return id, true
+}
+
+// GET /reading-list?shelf=read
+
+// GetReadingList godoc
+// @Summary Get the member's reading list
+// @Description Returns the catalog titles on the calling member's shelves, most recently changed first. The member is identified by the X-Member-ID header set by the gateway.
+// @Tags reading-list
+// @Produce json
+// @Param X-Member-ID header string true "Member ID"
+// @Param shelf query string false "Only this shelf" Enums(read, currently-reading, to-read, did-not-finish)
+// @Success 200 {array} Entry
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /reading-list [get]
+func (h *Handler) GetReadingList(w http.ResponseWriter, r *http.Request) {
+	member, ok := memberID(w, r)
+	if !ok {
+		return
+	}
+	shelfName := r.URL.Query().Get("shelf")
+	if shelfName != "" {
+		if _, problem := shelf(shelfName); problem != "" {
+			http.Error(w, problem, http.StatusBadRequest)
+			return
+		}
+	}
+	entries, err := h.svc.List(r.Context(), member, strings.ToLower(shelfName))
+	if err != nil {
+		httperr.Write(w, h.logger, "failed to list reading list", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(entries)
+}
+
+// POST /reading-list/import
+
+// ImportReadingList godoc
+// @Summary Import a Goodreads or StoryGraph export
+// @Description Reads the CSV library export of Goodreads (My Books > Import and export) or StoryGraph (Manage Account > Export) and puts each title found in the catalog on the member's shelf with its rating and read date. Rows are matched by ISBN, then by title and author surname; rows that match nothing are listed in the report. Titles already on the list are updated.
+// @Tags reading-list
+// @Accept text/csv
+// @Produce json
+// @Param X-Member-ID header string true "Member ID"
+// @Success 200 {object} ImportReport
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 413 {object} map[string]string
+// @Router /reading-list/import [post]
+func (h *Handler) ImportReadingList(w http.ResponseWriter, r *http.Request) {
+	member, ok := memberID(w, r)
+	if !ok {
+		return
+	}
+	report, err := h.svc.Import(r.Context(), member, http.MaxBytesReader(w, r.Body, maxImportBytes))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		switch {
+		case errors.As(err, &tooLarge):
+			http.Error(w, "export file too large", http.StatusRequestEntityTooLarge)
+		case errors.Is(err, ErrInvalidExport):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		default:
+			httperr.Write(w, h.logger, "reading list import failed", err)
+		}
+		return
+	}
+
+	h.logger.Info("reading list imported", zap.String("source", report.Source),
+		zap.Int("rows", report.Rows), zap.Int("imported", report.Imported), zap.Int("unmatched", len(report.Unmatched)))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,45 @@
+package readinglist
+
+import "time"
+
+// Shelves a reading list entry can be on. Goodreads exports use the first
+// three as exclusive shelves; StoryGraph adds did-not-finish.
+const (
+	ShelfRead             = "read"
+	ShelfCurrentlyReading = "currently-reading"
+	ShelfToRead           = "to-read"
+	ShelfDidNotFinish     = "did-not-finish"
+)
+
+var shelves = []string{ShelfRead, ShelfCurrentlyReading, ShelfToRead, ShelfDidNotFinish}
+
+// Entry is a catalog title on a member's reading list
+type Entry struct {
+	BookID int    `json:"book_id" example:"1"`
+	Title  string `json:"title" example:"The Great Gatsby"`
+	Author string `json:"author" example:"F. Scott Fitzgerald"`
+	Shelf  string `json:"shelf" example:"read" enums:"read,currently-reading,to-read,did-not-finish"`
+	// Rating is the member's star rating, 0.25 to 5
+	Rating    *float64  `json:"rating,omitempty" example:"4.5"`
+	DateRead  string    `json:"date_read,omitempty" example:"2024-03-17"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// UnmatchedRow is an import row that was not added to the reading list
+type UnmatchedRow struct {
+	// Line is the row's line in the CSV file; the header is line 1
+	Line   int    `json:"line" example:"14"`
+	Title  string `json:"title" example:"The Hunger Games (The Hunger Games, #1)"`
+	Author string `json:"author" example:"Suzanne Collins"`
+	ISBN   string `json:"isbn,omitempty" example:"9780439023481"`
+	Reason string `json:"reason" example:"no catalog title matches"`
+}
+
+// ImportReport is the outcome of a reading list import
+type ImportReport struct {
+	// Source is the detected export format, "goodreads" or "storygraph"
+	Source    string         `json:"source" example:"goodreads"`
+	Rows      int            `json:"rows" example:"212"`
+	Imported  int            `json:"imported" example:"187"`
+	Unmatched []UnmatchedRow `json:"unmatched"`
+}
@@ -0,0 +1,167 @@
+package readinglist
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidExport is wrapped by errors about the content of an import file
+var ErrInvalidExport = errors.New("invalid reading list export")
+
+// Export formats recognized by their header
+const (
+	SourceGoodreads  = "goodreads"
+	SourceStoryGraph = "storygraph"
+)
+
+// importRow is one book of an export, before it is matched to the catalog
+type importRow struct {
+	line     int
+	title    string
+	author   string
+	isbns    []string
+	shelf    string
+	rating   *float64
+	dateRead string
+	problem  string
+}
+
+// columns maps what an export calls each field; the first two are required
+type columns struct {
+	title, author string
+	isbns         []string
+	shelf, rating string
+	dateRead      string
+}
+
+var formats = map[string]columns{
+	SourceGoodreads: {
+		title: "title", author: "author", isbns: []string{"isbn13", "isbn"},
+		shelf: "exclusive shelf", rating: "my rating", dateRead: "date read",
+	},
+	SourceStoryGraph: {
+		title: "title", author: "authors", isbns: []string{"isbn/uid"},
+		shelf: "read status", rating: "star rating", dateRead: "last date read",
+	},
+}
+
+// parseExport reads a Goodreads or StoryGraph library export
+func parseExport(r io.Reader) (string, []importRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return "", nil, fmt.Errorf("%w: reading CSV header: %v", ErrInvalidExport, err)
+	}
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		index[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+
+	source := ""
+	switch {
+	case has(index, "exclusive shelf"):
+		source = SourceGoodreads
+	case has(index, "read status"):
+		source = SourceStoryGraph
+	default:
+		return "", nil, fmt.Errorf("%w: not a Goodreads or StoryGraph export (no Exclusive Shelf or Read Status column)", ErrInvalidExport)
+	}
+	cols := formats[source]
+	if !has(index, cols.title) || !has(index, cols.author) {
+		return "", nil, fmt.Errorf("%w: missing the %q or %q column", ErrInvalidExport, cols.title, cols.author)
+	}
+
+	var rows []importRow
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return "", nil, fmt.Errorf("%w: line %d: %v", ErrInvalidExport, line, err)
+		}
+		field := func(name string) string {
+			if i, ok := index[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		row := importRow{line: line, title: field(cols.title), author: field(cols.author)}
+		if source == SourceStoryGraph {
+			// StoryGraph lists every author in one column
+			row.author, _, _ = strings.Cut(row.author, ",")
+			row.author = strings.TrimSpace(row.author)
+		}
+		for _, c := range cols.isbns {
+			// Goodreads writes ISBNs as ="9780439023481" so spreadsheets keep them as text
+			if v := strings.Trim(field(c), `="`); v != "" {
+				row.isbns = append(row.isbns, v)
+			}
+		}
+		row.shelf, row.problem = shelf(field(cols.shelf))
+		if row.problem == "" {
+			row.rating, row.problem = rating(field(cols.rating))
+		}
+		if row.problem == "" {
+			row.dateRead = date(field(cols.dateRead))
+		}
+		if row.title == "" && len(row.isbns) == 0 {
+			row.problem = "row has neither a title nor an ISBN"
+		}
+		rows = append(rows, row)
+	}
+	return source, rows, nil
+}
+
+func has(index map[string]int, name string) bool {
+	_, ok := index[name]
+	return ok
+}
+
+func shelf(v string) (string, string) {
+	v = strings.ToLower(v)
+	if v == "" {
+		return ShelfToRead, ""
+	}
+	for _, s := range shelves {
+		if v == s {
+			return s, ""
+		}
+	}
+	return "", fmt.Sprintf("shelf %q is not supported", v)
+}
+
+// rating parses a star rating; 0 and blank mean the book was not rated
+func rating(v string) (*float64, string) {
+	if v == "" {
+		return nil, ""
+	}
+	stars, err := strconv.ParseFloat(v, 64)
+	if err != nil || stars < 0 || stars > 5 {
+		return nil, fmt.Sprintf("rating %q is not between 0 and 5 stars", v)
+	}
+	if stars == 0 {
+		return nil, ""
+	}
+	return &stars, ""
+}
+
+// date normalizes the read date to YYYY-MM-DD; dates it cannot parse are
+// dropped rather than failing the row
+func date(v string) string {
+	for _, layout := range []string{"2006/01/02", "2006-01-02", "2006/1/2", "01/02/2006"} {
+		if t, err := time.Parse(layout, v); err == nil {
+			return t.Format(time.DateOnly)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,112 @@
+package readinglist
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"public_library/internal/db"
+	"public_library/utils"
+	"strings"
+)
+
+type Repository struct {
+	db *db.DB
+}
+
+func NewRepository(conn *db.DB) *Repository {
+	return &Repository{db: conn}
+}
+
+// MatchTitle returns the IDs of up to two catalog books with exactly this
+// title (ignoring case) whose author contains authorSurname
+func (r *Repository) MatchTitle(ctx context.Context, title, authorSurname string) ([]int, error) {
+	query := fmt.Sprintf(`
+		SELECT id FROM %s
+		WHERE lower(title) = lower($1) AND author ILIKE '%%' || $2 || '%%'
+		ORDER BY id
+		LIMIT 2
+	`, utils.BooksTable)
+	pattern := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(authorSurname)
+	rows, err := r.db.QueryContext(ctx, query, title, pattern)
+	if err != nil {
+		log.Printf("Failed to match title %q: %v", title, err)
+		return nil, err
+	}
+	defer rows.Close()
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// Upsert puts each entry on the member's reading list, replacing the shelf,
+// rating and read date of titles already on it
+func (r *Repository) Upsert(ctx context.Context, memberID string, entries []Entry) error {
+	log.Println("<--------Upsert reading list starts-------->")
+	defer log.Println("<--------Upsert reading list ends-------->")
+
+	var bookIDs []int64
+	var shelves, datesRead []string
+	var ratings []*float64
+	for _, e := range entries {
+		bookIDs = append(bookIDs, int64(e.BookID))
+		shelves = append(shelves, e.Shelf)
+		ratings = append(ratings, e.Rating)
+		datesRead = append(datesRead, e.DateRead)
+	}
+
+	query := fmt.Sprintf(`INSERT INTO %s (member_id, book_id, shelf, rating, date_read)
+		SELECT $1, book_id, shelf, rating, NULLIF(date_read, '')::date
+		FROM unnest($2::int[], $3::text[], $4::numeric[], $5::text[]) AS t (book_id, shelf, rating, date_read)
+		ON CONFLICT (member_id, book_id) DO UPDATE SET
+			shelf = EXCLUDED.shelf,
+			rating = EXCLUDED.rating,
+			date_read = EXCLUDED.date_read,
+			updated_at = now()`, utils.ReadingListTable)
+	if _, err := r.db.ExecContext(ctx, query, memberID, bookIDs, shelves, ratings, datesRead); err != nil {
+		log.Printf("Failed to upsert %d reading list entries for member %q: %v", len(entries), memberID, err)
+		return err
+	}
+	return nil
+}
+
+// List returns the member's reading list, most recently changed first,
+// optionally only one shelf
+func (r *Repository) List(ctx context.Context, memberID, shelf string) ([]Entry, error) {
+	log.Println("<--------List reading list starts-------->")
+	defer log.Println("<--------List reading list ends-------->")
+
+	query := fmt.Sprintf(`
+		SELECT e.book_id, b.title, b.author, e.shelf, e.rating::float8, COALESCE(to_char(e.date_read, 'YYYY-MM-DD'), ''), e.updated_at
+		FROM %s e
+		JOIN %s b ON b.id = e.book_id
+		WHERE e.member_id = $1 AND ($2 = '' OR e.shelf = $2)
+		ORDER BY e.updated_at DESC, e.book_id
+	`, utils.ReadingListTable, utils.BooksTable)
+	rows, err := r.db.QueryContext(ctx, query, memberID, shelf)
+	if err != nil {
+		log.Printf("Failed to list reading list of member %q: %v", memberID, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []Entry{}
+	for rows.Next() {
+		var e Entry
+		if err := rows.Scan(&e.BookID, &e.Title, &e.Author, &e.Shelf, &e.Rating, &e.DateRead, &e.UpdatedAt); err != nil {
+			log.Printf("Failed to scan reading list entry: %v", err)
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("Row iteration error: %v", err)
+		return nil, err
+	}
+	return entries, nil
+}
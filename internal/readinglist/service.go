@@ -0,0 +1,118 @@
+// Package readinglist keeps members' reading lists (the shelves and ratings
+// of catalog titles) and imports them from Goodreads and StoryGraph exports.
+// Members are identified by MemberHeader, set by the authenticating gateway.
+package readinglist
+
+import (
+	"context"
+	"errors"
+	"io"
+	"public_library/internal/book"
+	"regexp"
+	"strings"
+)
+
+type Service struct {
+	repo  *Repository
+	books *book.Service
+}
+
+func NewService(repo *Repository, books *book.Service) *Service {
+	return &Service{repo: repo, books: books}
+}
+
+// List returns a member's reading list, optionally only one shelf
+func (s *Service) List(ctx context.Context, memberID, shelf string) ([]Entry, error) {
+	return s.repo.List(ctx, memberID, shelf)
+}
+
+// seriesSuffix is the series note Goodreads appends to titles, as in
+// "The Hunger Games (The Hunger Games, #1)"
+var seriesSuffix = regexp.MustCompile(`\s*\([^()]*#\s*[\d.]+\)\s*$`)
+
+// Import matches every row of an export to a catalog title, by ISBN and
+// then by title and author, and puts the matches on the member's reading
+// list. Rows that match nothing, or more than one title, are reported and
+// skipped; the rest are imported together.
+func (s *Service) Import(ctx context.Context, memberID string, body io.Reader) (*ImportReport, error) {
+	source, rows, err := parseExport(body)
+	if err != nil {
+		return nil, err
+	}
+	report := &ImportReport{Source: source, Rows: len(rows), Unmatched: []UnmatchedRow{}}
+
+	// A title listed twice keeps its last row
+	byBook := make(map[int]Entry)
+	var order []int
+	for _, row := range rows {
+		reason := row.problem
+		bookID := 0
+		if reason == "" {
+			bookID, reason, err = s.match(ctx, row)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if reason != "" {
+			report.Unmatched = append(report.Unmatched, UnmatchedRow{
+				Line: row.line, Title: row.title, Author: row.author, ISBN: strings.Join(row.isbns, ", "), Reason: reason,
+			})
+			continue
+		}
+		if _, seen := byBook[bookID]; !seen {
+			order = append(order, bookID)
+		}
+		byBook[bookID] = Entry{BookID: bookID, Shelf: row.shelf, Rating: row.rating, DateRead: row.dateRead}
+	}
+
+	if len(order) > 0 {
+		entries := make([]Entry, len(order))
+		for i, id := range order {
+			entries[i] = byBook[id]
+		}
+		if err := s.repo.Upsert(ctx, memberID, entries); err != nil {
+			return nil, err
+		}
+	}
+	report.Imported = len(order)
+	return report, nil
+}
+
+// match finds the catalog book of a row, or says why there is none
+func (s *Service) match(ctx context.Context, row importRow) (int, string, error) {
+	for _, isbn := range row.isbns {
+		idType := book.IdentifierISBN13
+		if len(strings.NewReplacer("-", "", " ", "").Replace(isbn)) == 10 {
+			idType = book.IdentifierISBN10
+		}
+		b, err := s.books.GetByIdentifier(ctx, idType, isbn)
+		switch {
+		case err == nil:
+			return b.ID, "", nil
+		case errors.Is(err, book.ErrNotFound), errors.Is(err, book.ErrValidation):
+			// StoryGraph puts its own IDs in ISBN/UID for books without one
+		default:
+			return 0, "", err
+		}
+	}
+
+	title := strings.TrimSpace(seriesSuffix.ReplaceAllString(row.title, ""))
+	if title == "" || row.author == "" {
+		return 0, "no catalog title has this ISBN", nil
+	}
+	// Catalogs and exports spell first names differently ("J.R.R." and
+	// "J. R. R."), so only the surname has to match
+	names := strings.Fields(row.author)
+	ids, err := s.repo.MatchTitle(ctx, title, names[len(names)-1])
+	if err != nil {
+		return 0, "", err
+	}
+	switch len(ids) {
+	case 0:
+		return 0, "no catalog title matches", nil
+	case 1:
+		return ids[0], "", nil
+	default:
+		return 0, "several catalog titles match; add it by hand", nil
+	}
+}
@@ -0,0 +1,112 @@
+package recommendations
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"public_library/internal/book"
+	"public_library/internal/httperr"
+	"public_library/internal/readinglist"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	svc    *Service
+	logger *zap.Logger
+}
+
+func NewHandler(svc *Service, l *zap.Logger) *Handler {
+	return &Handler{svc: svc, logger: l}
+}
+
+// GET /books/{id}/recommendations?limit=10
+
+// GetBookRecommendations godoc
+// @Summary Recommend books to the readers of a book
+// @Description Returns books for readers of the book, best first, from the configured strategy or the caller's variant in the recommendations experiment: co_borrowing (what the book's borrowers also borrowed), content (same author, series or collection) or random. Places the strategy leaves empty are filled with random books. Books outside the caller's audience limit are left out.
+// @Tags books
+// @Produce json
+// @Param id path int true "Book ID"
+// @Param limit query int false "Books to return, at most recommendations.max_limit" default(10)
+// @Success 200 {object} Recommendations
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /books/{id}/recommendations [get]
+func (h *Handler) GetBookRecommendations(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "invalid book ID", http.StatusBadRequest)
+		return
+	}
+	limit, ok := limitParam(w, r)
+	if !ok {
+		return
+	}
+	recs, err := h.svc.ForBook(r.Context(), id, limit)
+	if err != nil {
+		h.writeError(w, "failed to recommend books", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(recs)
+}
+
+// GET /me/recommendations?limit=10
+
+// GetMyRecommendations godoc
+// @Summary Recommend books to the calling member
+// @Description Returns books the member hasn't borrowed, best first, starting from the books they borrowed most recently. Strategies and the random fill are those of /books/{id}/recommendations; members with no loans, or whose loans are anonymized, get random books. The member is identified by the X-Member-ID header set by the gateway.
+// @Tags members
+// @Produce json
+// @Param X-Member-ID header string true "Member ID"
+// @Param limit query int false "Books to return, at most recommendations.max_limit" default(10)
+// @Success 200 {object} Recommendations
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /me/recommendations [get]
+func (h *Handler) GetMyRecommendations(w http.ResponseWriter, r *http.Request) {
+	member := strings.TrimSpace(r.Header.Get(readinglist.MemberHeader))
+	if member == "" {
+		http.Error(w, readinglist.MemberHeader+" header is required", http.StatusUnauthorized)
+		return
+	}
+	limit, ok := limitParam(w, r)
+	if !ok {
+		return
+	}
+	recs, err := h.svc.ForMember(r.Context(), member, limit)
+	if err != nil {
+		h.writeError(w, "failed to recommend books", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(recs)
+}
+
+func limitParam(w http.ResponseWriter, r *http.Request) (int, bool) {
+	v := r.URL.Query().Get("limit")
+	if v == "" {
+		return 0, true
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		http.Error(w, "invalid limit parameter", http.StatusBadRequest)
+		return 0, false
+	}
+	return n, true
+}
+
+func (h *Handler) writeError(w http.ResponseWriter, msg string, err error) {
+	switch {
+	case errors.Is(err, ErrInvalid):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, book.ErrNotFound):
+		http.Error(w, "book not found", http.StatusNotFound)
+	default:
+		httperr.Write(w, h.logger, msg, err)
+	}
+}
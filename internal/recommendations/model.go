@@ -0,0 +1,24 @@
+package recommendations
+
+// Recommendation is a book recommended to a reader
+type Recommendation struct {
+	BookID     int    `json:"book_id" example:"12"`
+	Title      string `json:"title" example:"The Dispossessed"`
+	Author     string `json:"author" example:"Ursula K. Le Guin"`
+	Collection string `json:"collection" example:"Adult Fiction"`
+	Audience   string `json:"audience" example:"adult" enums:"children,teen,adult"`
+	// Strategy is the recommender that picked the book; random for books
+	// filled in by the fallback
+	Strategy string `json:"strategy" example:"co_borrowing" enums:"co_borrowing,content,random"`
+	// Score ranks the books of a strategy; it is 0 for random picks
+	Score float64 `json:"score" example:"7"`
+}
+
+// Recommendations are the books recommended for a book or a member, best
+// first
+type Recommendations struct {
+	// Strategy is the recommender asked first, from recommendations.strategy
+	// or the caller's variant in the recommendations experiment
+	Strategy string           `json:"strategy" example:"co_borrowing" enums:"co_borrowing,content,random"`
+	Books    []Recommendation `json:"books"`
+}
@@ -0,0 +1,95 @@
+package recommendations
+
+import "context"
+
+// Strategies, by the name recommendations.strategy and experiment variants
+// select them by
+const (
+	StrategyCoBorrowing = "co_borrowing"
+	StrategyContent     = "content"
+	StrategyRandom      = "random"
+)
+
+// Query narrows what a Recommender may return
+type Query struct {
+	Limit int
+	// Audiences limits the books to those classifications; nil allows every
+	// book
+	Audiences []string
+	// Exclude lists books not to recommend, such as those already borrowed
+	Exclude []int
+}
+
+// Scored is a book a Recommender picked, with its score
+type Scored struct {
+	BookID int
+	Score  float64
+}
+
+// Recommender picks up to q.Limit books, best first. It may return fewer,
+// or none when it knows too little; the service fills up with the random
+// fallback.
+type Recommender interface {
+	// Name is the strategy the recommender implements
+	Name() string
+	// ForBook recommends books to the readers of the book
+	ForBook(ctx context.Context, bookID int, q Query) ([]Scored, error)
+	// ForMember recommends books to the member, who borrowed the seeds,
+	// most recent first. Members with anonymized loans have few or no seeds.
+	ForMember(ctx context.Context, seeds []int, memberID string, q Query) ([]Scored, error)
+}
+
+// coBorrowing recommends the books borrowed by the members who borrowed the
+// seeds, scored by how many seeds each of those members shares
+type coBorrowing struct {
+	repo         *Repository
+	minBorrowers int
+}
+
+func (c *coBorrowing) Name() string { return StrategyCoBorrowing }
+
+func (c *coBorrowing) ForBook(ctx context.Context, bookID int, q Query) ([]Scored, error) {
+	return c.repo.CoBorrowed(ctx, []int{bookID}, "", c.minBorrowers, q)
+}
+
+func (c *coBorrowing) ForMember(ctx context.Context, seeds []int, memberID string, q Query) ([]Scored, error) {
+	if len(seeds) == 0 {
+		return nil, nil
+	}
+	return c.repo.CoBorrowed(ctx, seeds, memberID, c.minBorrowers, q)
+}
+
+// content recommends the books sharing an author, series or collection
+// with the seeds
+type content struct {
+	repo *Repository
+}
+
+func (c *content) Name() string { return StrategyContent }
+
+func (c *content) ForBook(ctx context.Context, bookID int, q Query) ([]Scored, error) {
+	return c.repo.Similar(ctx, []int{bookID}, q)
+}
+
+func (c *content) ForMember(ctx context.Context, seeds []int, memberID string, q Query) ([]Scored, error) {
+	if len(seeds) == 0 {
+		return nil, nil
+	}
+	return c.repo.Similar(ctx, seeds, q)
+}
+
+// random recommends any books, for readers nothing is known about
+type random struct {
+	repo *Repository
+}
+
+func (r *random) Name() string { return StrategyRandom }
+
+func (r *random) ForBook(ctx context.Context, bookID int, q Query) ([]Scored, error) {
+	q.Exclude = append(q.Exclude, bookID)
+	return r.repo.Random(ctx, q)
+}
+
+func (r *random) ForMember(ctx context.Context, seeds []int, memberID string, q Query) ([]Scored, error) {
+	return r.repo.Random(ctx, q)
+}
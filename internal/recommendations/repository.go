@@ -0,0 +1,175 @@
+package recommendations
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"public_library/internal/db"
+	"public_library/utils"
+)
+
+type Repository struct {
+	db *db.DB
+}
+
+func NewRepository(conn *db.DB) *Repository {
+	return &Repository{db: conn}
+}
+
+func (r *Repository) queryScored(ctx context.Context, query string, args ...interface{}) ([]Scored, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []Scored
+	for rows.Next() {
+		var s Scored
+		if err := rows.Scan(&s.BookID, &s.Score); err != nil {
+			return nil, err
+		}
+		list = append(list, s)
+	}
+	return list, rows.Err()
+}
+
+// MemberBooks returns the books the member borrowed, most recently borrowed
+// first. Anonymized loans no longer name the member, so they are left out.
+func (r *Repository) MemberBooks(ctx context.Context, memberID string, limit int) ([]int, error) {
+	query := fmt.Sprintf(`
+		SELECT a.book_id FROM %s l
+		JOIN %s a ON a.id = l.acquisition_id
+		WHERE l.member_id = $1
+		GROUP BY a.book_id
+		ORDER BY max(l.checked_out_at) DESC, a.book_id
+		LIMIT $2
+	`, utils.LoansTable, utils.AcquisitionsTable)
+	rows, err := r.db.QueryContext(ctx, query, memberID, limit)
+	if err != nil {
+		log.Printf("Failed to list the books member id=%s borrowed: %v", memberID, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// CoBorrowed returns the books borrowed by at least minBorrowers of the
+// members who borrowed any of the seeds, other than memberID. A book scores
+// the seeds each of its borrowers shares, summed.
+func (r *Repository) CoBorrowed(ctx context.Context, seeds []int, memberID string, minBorrowers int, q Query) ([]Scored, error) {
+	query := fmt.Sprintf(`
+		WITH neighbours AS (
+			SELECT l.member_id, count(DISTINCT a.book_id) AS shared
+			FROM %[1]s l
+			JOIN %[2]s a ON a.id = l.acquisition_id
+			WHERE a.book_id = ANY($1) AND l.member_id IS NOT NULL AND l.member_id <> $2
+			GROUP BY l.member_id
+		), borrowed AS (
+			SELECT DISTINCT l.member_id, a.book_id
+			FROM %[1]s l
+			JOIN %[2]s a ON a.id = l.acquisition_id
+			JOIN neighbours n ON n.member_id = l.member_id
+		)
+		SELECT t.book_id, SUM(n.shared)::float8
+		FROM borrowed t
+		JOIN neighbours n ON n.member_id = t.member_id
+		JOIN %[3]s b ON b.id = t.book_id
+		WHERE NOT (t.book_id = ANY($1)) AND NOT (t.book_id = ANY($3))
+			AND ($4::text[] IS NULL OR b.audience = ANY($4))
+		GROUP BY t.book_id
+		HAVING count(*) >= $5
+		ORDER BY SUM(n.shared) DESC, t.book_id
+		LIMIT $6
+	`, utils.LoansTable, utils.AcquisitionsTable, utils.BooksTable)
+	list, err := r.queryScored(ctx, query, seeds, memberID, exclude(q), q.Audiences, minBorrowers, q.Limit)
+	if err != nil {
+		log.Printf("Failed to find co-borrowed books: %v", err)
+		return nil, err
+	}
+	return list, nil
+}
+
+// Similar returns the books sharing an author, series or collection with
+// the seeds, scored 3 per shared author, 2 per shared series and 1 per
+// shared collection, newest first among equals
+func (r *Repository) Similar(ctx context.Context, seeds []int, q Query) ([]Scored, error) {
+	query := fmt.Sprintf(`
+		WITH seeds AS (
+			SELECT author, series_id, collection FROM %[1]s WHERE id = ANY($1)
+		)
+		SELECT b.id, SUM(
+			CASE WHEN b.author = s.author THEN 3 ELSE 0 END +
+			CASE WHEN b.series_id = s.series_id THEN 2 ELSE 0 END +
+			CASE WHEN b.collection <> '' AND b.collection = s.collection THEN 1 ELSE 0 END)::float8
+		FROM %[1]s b
+		JOIN seeds s ON b.author = s.author OR b.series_id = s.series_id
+			OR (b.collection <> '' AND b.collection = s.collection)
+		WHERE NOT (b.id = ANY($1)) AND NOT (b.id = ANY($2))
+			AND ($3::text[] IS NULL OR b.audience = ANY($3))
+		GROUP BY b.id
+		ORDER BY 2 DESC, max(b.publication_year) DESC NULLS LAST, b.id
+		LIMIT $4
+	`, utils.BooksTable)
+	list, err := r.queryScored(ctx, query, seeds, exclude(q), q.Audiences, q.Limit)
+	if err != nil {
+		log.Printf("Failed to find similar books: %v", err)
+		return nil, err
+	}
+	return list, nil
+}
+
+// Random returns books picked at random
+func (r *Repository) Random(ctx context.Context, q Query) ([]Scored, error) {
+	query := fmt.Sprintf(`
+		SELECT b.id, 0::float8 FROM %s b
+		WHERE NOT (b.id = ANY($1)) AND ($2::text[] IS NULL OR b.audience = ANY($2))
+		ORDER BY random()
+		LIMIT $3
+	`, utils.BooksTable)
+	list, err := r.queryScored(ctx, query, exclude(q), q.Audiences, q.Limit)
+	if err != nil {
+		log.Printf("Failed to pick random books: %v", err)
+		return nil, err
+	}
+	return list, nil
+}
+
+// Books returns the recommendation details of the books with ids, by id
+func (r *Repository) Books(ctx context.Context, ids []int) (map[int]Recommendation, error) {
+	query := fmt.Sprintf(`SELECT id, title, author, collection, audience FROM %s WHERE id = ANY($1)`, utils.BooksTable)
+	rows, err := r.db.QueryContext(ctx, query, ids)
+	if err != nil {
+		log.Printf("Failed to read %d recommended books: %v", len(ids), err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	books := make(map[int]Recommendation, len(ids))
+	for rows.Next() {
+		var rec Recommendation
+		if err := rows.Scan(&rec.BookID, &rec.Title, &rec.Author, &rec.Collection, &rec.Audience); err != nil {
+			return nil, err
+		}
+		books[rec.BookID] = rec
+	}
+	return books, rows.Err()
+}
+
+// exclude returns q.Exclude, never nil, since NOT (id = ANY(NULL)) matches
+// no row
+func exclude(q Query) []int {
+	if q.Exclude == nil {
+		return []int{}
+	}
+	return q.Exclude
+}
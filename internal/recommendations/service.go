@@ -0,0 +1,150 @@
+// Package recommendations recommends books to the readers of a book and to
+// members, through a Recommender picked by recommendations.strategy: books
+// co-borrowed by other members, books sharing an author, series or
+// collection, or random books. A running experiment named by
+// recommendations.experiment overrides the strategy for the callers in a
+// variant named after one. Whatever the strategy leaves short of the limit
+// is filled with random books.
+package recommendations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"public_library/internal/book"
+	"public_library/internal/config"
+	"public_library/internal/experiments"
+	"slices"
+)
+
+// ErrInvalid is wrapped by errors about the content of a request
+var ErrInvalid = errors.New("invalid recommendations request")
+
+// maxSeeds caps the recent loans member recommendations start from
+const maxSeeds = 50
+
+// maxBorrowed caps the loans left out of member recommendations
+const maxBorrowed = 1000
+
+type Service struct {
+	repo       *Repository
+	books      *book.Service
+	cfg        config.RecommendationsConfig
+	strategies map[string]Recommender
+	fallback   Recommender
+}
+
+// NewService creates the recommendation service with the strategy of cfg
+func NewService(repo *Repository, books *book.Service, cfg config.RecommendationsConfig) (*Service, error) {
+	s := &Service{repo: repo, books: books, cfg: cfg, fallback: &random{repo: repo}, strategies: map[string]Recommender{}}
+	for _, r := range []Recommender{&coBorrowing{repo: repo, minBorrowers: cfg.MinCoBorrowers}, &content{repo: repo}, s.fallback} {
+		s.strategies[r.Name()] = r
+	}
+	if _, ok := s.strategies[cfg.Strategy]; !ok {
+		return nil, fmt.Errorf("unknown recommendations.strategy %q", cfg.Strategy)
+	}
+	return s, nil
+}
+
+// recommender returns the strategy for the request: the caller's variant in
+// the recommendations experiment when it names one, else the configured one
+func (s *Service) recommender(ctx context.Context) Recommender {
+	if s.cfg.Experiment != "" {
+		if r, ok := s.strategies[experiments.VariantOf(ctx, s.cfg.Experiment)]; ok {
+			return r
+		}
+	}
+	return s.strategies[s.cfg.Strategy]
+}
+
+// ForBook recommends up to limit books to the readers of the book, within
+// the caller's audience limit
+func (s *Service) ForBook(ctx context.Context, bookID, limit int) (*Recommendations, error) {
+	if _, err := s.books.Get(ctx, bookID); err != nil {
+		return nil, err
+	}
+	q, err := s.query(ctx, limit, []int{bookID})
+	if err != nil {
+		return nil, err
+	}
+	r := s.recommender(ctx)
+	return s.recommend(ctx, r, q, func(r Recommender, q Query) ([]Scored, error) {
+		return r.ForBook(ctx, bookID, q)
+	})
+}
+
+// ForMember recommends up to limit books to the member that they haven't
+// borrowed, within the caller's audience limit
+func (s *Service) ForMember(ctx context.Context, memberID string, limit int) (*Recommendations, error) {
+	borrowed, err := s.repo.MemberBooks(ctx, memberID, maxBorrowed)
+	if err != nil {
+		return nil, err
+	}
+	q, err := s.query(ctx, limit, borrowed)
+	if err != nil {
+		return nil, err
+	}
+	seeds := borrowed[:min(len(borrowed), maxSeeds)]
+	r := s.recommender(ctx)
+	return s.recommend(ctx, r, q, func(r Recommender, q Query) ([]Scored, error) {
+		return r.ForMember(ctx, seeds, memberID, q)
+	})
+}
+
+func (s *Service) query(ctx context.Context, limit int, exclude []int) (Query, error) {
+	if limit == 0 {
+		limit = s.cfg.DefaultLimit
+	}
+	if limit < 1 || limit > s.cfg.MaxLimit {
+		return Query{}, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalid, s.cfg.MaxLimit)
+	}
+	return Query{Limit: limit, Audiences: book.AllowedAudiences(ctx), Exclude: exclude}, nil
+}
+
+// recommend asks r, then fills what it leaves short of q.Limit with the
+// random fallback, and adds the details of the books
+func (s *Service) recommend(ctx context.Context, r Recommender, q Query, ask func(Recommender, Query) ([]Scored, error)) (*Recommendations, error) {
+	picked, err := ask(r, q)
+	if err != nil {
+		return nil, err
+	}
+	strategies := make([]string, len(picked))
+	for i := range picked {
+		strategies[i] = r.Name()
+	}
+	if len(picked) < q.Limit && r != s.fallback {
+		fill := q
+		fill.Limit = q.Limit - len(picked)
+		fill.Exclude = slices.Clone(q.Exclude)
+		for _, p := range picked {
+			fill.Exclude = append(fill.Exclude, p.BookID)
+		}
+		more, err := ask(s.fallback, fill)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range more {
+			picked = append(picked, p)
+			strategies = append(strategies, s.fallback.Name())
+		}
+	}
+
+	ids := make([]int, len(picked))
+	for i, p := range picked {
+		ids[i] = p.BookID
+	}
+	books, err := s.repo.Books(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	res := &Recommendations{Strategy: r.Name(), Books: []Recommendation{}}
+	for i, p := range picked {
+		rec, ok := books[p.BookID]
+		if !ok {
+			continue
+		}
+		rec.Strategy, rec.Score = strategies[i], p.Score
+		res.Books = append(res.Books, rec)
+	}
+	return res, nil
+}
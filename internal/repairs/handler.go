@@ -0,0 +1,197 @@
+package repairs
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"public_library/internal/httperr"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	svc    *Service
+	logger *zap.Logger
+}
+
+func NewHandler(svc *Service, l *zap.Logger) *Handler {
+	return &Handler{svc: svc, logger: l}
+}
+
+// POST /acquisitions/{id}/repairs
+
+// SendOutForRepair godoc
+// @Summary Send a damaged copy out for repair or binding
+// @Description Records the copy as out with the vendor until it is returned; it can't be checked out meanwhile. kind defaults to repair and sent_on to today. cost, with its currency, is the vendor's quote.
+// @Tags repairs
+// @Accept json
+// @Produce json
+// @Param id path int true "Acquisition ID"
+// @Param repair body Repair true "Vendor, damage, cost and expected return"
+// @Success 201 {object} Repair
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /acquisitions/{id}/repairs [post]
+func (h *Handler) SendOutForRepair(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "invalid acquisition ID")
+	if !ok {
+		return
+	}
+	var rp Repair
+	if err := json.NewDecoder(r.Body).Decode(&rp); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	rp.AcquisitionID = id
+	if err := h.svc.SendOut(r.Context(), &rp); err != nil {
+		h.writeError(w, "failed to send copy out for repair", err)
+		return
+	}
+	h.logger.Info("copy sent out for repair", zap.Int("acquisition_id", id), zap.String("vendor", rp.Vendor),
+		zap.String("expected_on", rp.ExpectedOn))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(rp)
+}
+
+// GET /acquisitions/{id}/repairs
+
+// ListCopyRepairs godoc
+// @Summary List the repairs of a copy
+// @Tags repairs
+// @Produce json
+// @Param id path int true "Acquisition ID"
+// @Success 200 {array} Repair
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /acquisitions/{id}/repairs [get]
+func (h *Handler) ListCopyRepairs(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "invalid acquisition ID")
+	if !ok {
+		return
+	}
+	list, err := h.svc.ListByCopy(r.Context(), id)
+	if err != nil {
+		h.writeError(w, "failed to list repairs", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// GET /repairs?status=out&overdue=true
+
+// ListRepairs godoc
+// @Summary List the repair queue
+// @Description Lists repairs, copies still out first by expected return date. overdue=true lists only copies out past their expected return date.
+// @Tags repairs
+// @Produce json
+// @Param status query string false "Only repairs with this status" Enums(out, returned)
+// @Param overdue query bool false "Only copies overdue from the vendor"
+// @Success 200 {array} Repair
+// @Failure 400 {object} map[string]string
+// @Router /repairs [get]
+func (h *Handler) ListRepairs(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	req := ListRequest{Status: q.Get("status")}
+	if v := q.Get("overdue"); v != "" {
+		overdue, err := strconv.ParseBool(v)
+		if err != nil {
+			http.Error(w, "invalid overdue", http.StatusBadRequest)
+			return
+		}
+		req.Overdue = overdue
+	}
+	list, err := h.svc.List(r.Context(), req)
+	if err != nil {
+		h.writeError(w, "failed to list repairs", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// GET /repairs/{id}
+
+// GetRepair godoc
+// @Summary Get a repair
+// @Tags repairs
+// @Produce json
+// @Param id path int true "Repair ID"
+// @Success 200 {object} Repair
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /repairs/{id} [get]
+func (h *Handler) GetRepair(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "invalid repair ID")
+	if !ok {
+		return
+	}
+	rp, err := h.svc.Get(r.Context(), id)
+	if err != nil {
+		h.writeError(w, "failed to get repair", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rp)
+}
+
+// POST /repairs/{id}/return
+
+// ReturnRepair godoc
+// @Summary Record a copy back from repair
+// @Description Puts the copy back in circulation. returned_on defaults to today; cost, if given, replaces the quote with the invoiced amount.
+// @Tags repairs
+// @Accept json
+// @Produce json
+// @Param id path int true "Repair ID"
+// @Param return body Return false "Return date and final cost"
+// @Success 200 {object} Repair
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /repairs/{id}/return [post]
+func (h *Handler) ReturnRepair(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "invalid repair ID")
+	if !ok {
+		return
+	}
+	var ret Return
+	if err := json.NewDecoder(r.Body).Decode(&ret); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	rp, err := h.svc.Return(r.Context(), id, ret)
+	if err != nil {
+		h.writeError(w, "failed to return repair", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rp)
+}
+
+func pathID(w http.ResponseWriter, r *http.Request, msg string) (int, bool) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, msg, http.StatusBadRequest)
+		return 0, false
+	}
+	return id, true
+}
+
+func (h *Handler) writeError(w http.ResponseWriter, msg string, err error) {
+	switch {
+	case errors.Is(err, ErrInvalid):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, ErrNotFound), errors.Is(err, ErrCopyNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, ErrWithdrawn), errors.Is(err, ErrAlreadyOut), errors.Is(err, ErrReturned):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		httperr.Write(w, h.logger, msg, err)
+	}
+}
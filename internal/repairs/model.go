@@ -0,0 +1,64 @@
+package repairs
+
+import "time"
+
+// Kinds of work a copy is sent out for
+const (
+	KindRepair  = "repair"
+	KindBinding = "binding"
+)
+
+var kinds = []string{KindRepair, KindBinding}
+
+// Repair statuses. A copy is out until it is returned, and doesn't
+// circulate while it is out.
+const (
+	StatusOut      = "out"
+	StatusReturned = "returned"
+)
+
+var statuses = []string{StatusOut, StatusReturned}
+
+// Repair is one trip of a damaged copy to a bindery or repair vendor
+type Repair struct {
+	ID            int    `json:"id" example:"5"`
+	AcquisitionID int    `json:"acquisition_id" example:"31"`
+	BookID        int    `json:"book_id" example:"1"`
+	Title         string `json:"title" example:"The Hunger Games"`
+	Branch        string `json:"branch" example:"Central"`
+	Kind          string `json:"kind" example:"binding" enums:"repair,binding"`
+	Vendor        string `json:"vendor" example:"Hertzberg Bindery"`
+	// Damage describes what is wrong with the copy
+	Damage string `json:"damage" example:"Spine split, loose pages"`
+	// Cost is the quoted cost while the copy is out and the final cost once
+	// it is returned, a decimal amount in Currency
+	Cost     string `json:"cost,omitempty" example:"12.50"`
+	Currency string `json:"currency,omitempty" example:"USD"` // ISO 4217
+	// SentOn defaults to today
+	SentOn     string `json:"sent_on" example:"2026-03-02"`
+	ExpectedOn string `json:"expected_on" example:"2026-04-15"`
+	ReturnedOn string `json:"returned_on,omitempty" example:"2026-04-10"`
+	Status     string `json:"status" example:"out" enums:"out,returned"`
+	// Overdue is set for copies still out after their expected return date
+	Overdue   bool      `json:"overdue" example:"false"`
+	Note      string    `json:"note,omitempty" example:"Rebind in library buckram"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Return records a copy coming back from repair
+type Return struct {
+	// ReturnedOn defaults to today
+	ReturnedOn string `json:"returned_on" example:"2026-04-10"`
+	// Cost replaces the quoted cost with the invoiced one when given, in
+	// Currency or the repair's currency
+	Cost     string `json:"cost" example:"14.00"`
+	Currency string `json:"currency" example:""`
+	Note     string `json:"note" example:""`
+}
+
+// ListRequest filters the repair queue
+type ListRequest struct {
+	Status        string
+	Overdue       bool
+	AcquisitionID int
+}
@@ -0,0 +1,192 @@
+package repairs
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"public_library/internal/db"
+	"public_library/utils"
+)
+
+var (
+	// ErrNotFound is returned when no repair has the requested id
+	ErrNotFound = errors.New("repair not found")
+	// ErrCopyNotFound is returned when no acquisition has the copy's id
+	ErrCopyNotFound = errors.New("copy not found")
+	// ErrWithdrawn is returned for sending out a withdrawn copy
+	ErrWithdrawn = errors.New("copy has been withdrawn")
+	// ErrAlreadyOut is returned for sending out a copy that is already out
+	ErrAlreadyOut = errors.New("copy is already out for repair")
+	// ErrReturned is returned for returning a repair a second time
+	ErrReturned = errors.New("repair has already been returned")
+)
+
+type Repository struct {
+	db *db.DB
+}
+
+func NewRepository(conn *db.DB) *Repository {
+	return &Repository{db: conn}
+}
+
+// selectRepairsSQL joins repairs ("r") to their copy and its book; dates are
+// selected as text so they read as YYYY-MM-DD
+const selectRepairsSQL = `
+	SELECT r.id, r.acquisition_id, a.book_id, b.title, a.branch, r.kind, r.vendor, r.damage,
+		COALESCE(r.cost::text, ''), r.currency, r.sent_on::text, r.expected_on::text,
+		COALESCE(r.returned_on::text, ''), r.returned_on IS NULL AND r.expected_on < CURRENT_DATE,
+		r.note, r.created_at
+	FROM %[1]s r
+	JOIN %[2]s a ON a.id = r.acquisition_id
+	JOIN %[3]s b ON b.id = a.book_id
+`
+
+func scanRepair(row interface{ Scan(...interface{}) error }) (Repair, error) {
+	var rp Repair
+	err := row.Scan(&rp.ID, &rp.AcquisitionID, &rp.BookID, &rp.Title, &rp.Branch, &rp.Kind, &rp.Vendor, &rp.Damage,
+		&rp.Cost, &rp.Currency, &rp.SentOn, &rp.ExpectedOn, &rp.ReturnedOn, &rp.Overdue, &rp.Note, &rp.CreatedAt)
+	if err != nil {
+		return rp, err
+	}
+	rp.Status = StatusOut
+	if rp.ReturnedOn != "" {
+		rp.Status = StatusReturned
+	}
+	return rp, nil
+}
+
+func selectRepairs(where string) string {
+	return fmt.Sprintf(selectRepairsSQL, utils.RepairsTable, utils.AcquisitionsTable, utils.BooksTable) + where
+}
+
+// Create sends the copy out for repair, filling in rp from the stored row.
+// The copy is locked while it is checked, so it can't be sent out twice.
+func (r *Repository) Create(ctx context.Context, rp *Repair) error {
+	log.Println("<--------Create repair starts-------->")
+	defer log.Println("<--------Create repair ends-------->")
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var withdrawn, out bool
+	check := fmt.Sprintf(`
+		SELECT a.withdrawn_at IS NOT NULL,
+			EXISTS (SELECT 1 FROM %s r WHERE r.acquisition_id = a.id AND r.returned_on IS NULL)
+		FROM %s a WHERE a.id = $1
+		FOR UPDATE
+	`, utils.RepairsTable, utils.AcquisitionsTable)
+	err = tx.QueryRowContext(ctx, check, rp.AcquisitionID).Scan(&withdrawn, &out)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return ErrCopyNotFound
+	case err != nil:
+		return err
+	case withdrawn:
+		return ErrWithdrawn
+	case out:
+		return ErrAlreadyOut
+	}
+
+	var id int
+	insert := fmt.Sprintf(`
+		INSERT INTO %s (acquisition_id, kind, vendor, damage, cost, currency, sent_on, expected_on, note)
+		VALUES ($1, $2, $3, $4, NULLIF($5, '')::numeric, $6, $7, $8, $9)
+		RETURNING id
+	`, utils.RepairsTable)
+	err = tx.QueryRowContext(ctx, insert, rp.AcquisitionID, rp.Kind, rp.Vendor, rp.Damage, rp.Cost, rp.Currency,
+		rp.SentOn, rp.ExpectedOn, rp.Note).Scan(&id)
+	if err != nil {
+		log.Printf("Failed to create repair of acquisition id=%d: %v", rp.AcquisitionID, err)
+		return err
+	}
+	stored, err := scanRepair(tx.QueryRowContext(ctx, selectRepairs(`WHERE r.id = $1`), id))
+	if err != nil {
+		return err
+	}
+	*rp = stored
+	return tx.Commit()
+}
+
+func (r *Repository) GetByID(ctx context.Context, id int) (*Repair, error) {
+	rp, err := scanRepair(r.db.QueryRowContext(ctx, selectRepairs(`WHERE r.id = $1`), id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		log.Printf("Failed to get repair id=%d: %v", id, err)
+		return nil, err
+	}
+	return &rp, nil
+}
+
+// List returns the repairs matching req, those due back soonest first
+func (r *Repository) List(ctx context.Context, req ListRequest) ([]Repair, error) {
+	query := selectRepairs(`
+		WHERE ($1 = '' OR ($1 = 'out') = (r.returned_on IS NULL))
+			AND (NOT $2 OR (r.returned_on IS NULL AND r.expected_on < CURRENT_DATE))
+			AND ($3 = 0 OR r.acquisition_id = $3)
+		ORDER BY r.returned_on IS NOT NULL, r.expected_on, r.id
+	`)
+	rows, err := r.db.QueryContext(ctx, query, req.Status, req.Overdue, req.AcquisitionID)
+	if err != nil {
+		log.Printf("Failed to list repairs: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []Repair{}
+	for rows.Next() {
+		rp, err := scanRepair(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, rp)
+	}
+	return list, rows.Err()
+}
+
+// CopyExists reports whether an acquisition has the id
+func (r *Repository) CopyExists(ctx context.Context, acquisitionID int) (bool, error) {
+	var exists bool
+	query := fmt.Sprintf(`SELECT EXISTS (SELECT 1 FROM %s WHERE id = $1)`, utils.AcquisitionsTable)
+	err := r.db.QueryRowContext(ctx, query, acquisitionID).Scan(&exists)
+	return exists, err
+}
+
+// Return records the copy back from repair, which puts it back in
+// circulation. An empty cost keeps the quoted one.
+func (r *Repository) Return(ctx context.Context, id int, ret Return) (*Repair, error) {
+	log.Println("<--------Return repair starts-------->")
+	defer log.Println("<--------Return repair ends-------->")
+
+	query := fmt.Sprintf(`
+		UPDATE %s r
+		SET returned_on = $2,
+			cost = COALESCE(NULLIF($3, '')::numeric, r.cost),
+			currency = CASE WHEN $3 = '' THEN r.currency ELSE $4 END,
+			note = CASE WHEN $5 = '' THEN r.note ELSE $5 END
+		WHERE r.id = $1 AND r.returned_on IS NULL
+	`, utils.RepairsTable)
+	result, err := r.db.ExecContext(ctx, query, id, ret.ReturnedOn, ret.Cost, ret.Currency, ret.Note)
+	if err != nil {
+		log.Printf("Failed to return repair id=%d: %v", id, err)
+		return nil, err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	rp, err := r.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if n == 0 {
+		return nil, ErrReturned
+	}
+	return rp, nil
+}
@@ -0,0 +1,137 @@
+// Package repairs tracks copies sent out to a bindery or repair vendor:
+// what was wrong, who has them, what it costs and when they are due back.
+// A copy out for repair doesn't circulate until it is returned.
+package repairs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"public_library/internal/acquisition"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ErrInvalid is wrapped by errors about the content of a request
+var ErrInvalid = errors.New("invalid repair")
+
+const maxTextLength = 500
+
+type Service struct {
+	repo *Repository
+}
+
+func NewService(repo *Repository) *Service {
+	return &Service{repo: repo}
+}
+
+// SendOut validates rp and records its copy as out for repair
+func (s *Service) SendOut(ctx context.Context, rp *Repair) error {
+	rp.Kind = strings.ToLower(strings.TrimSpace(rp.Kind))
+	if rp.Kind == "" {
+		rp.Kind = KindRepair
+	}
+	if !slices.Contains(kinds, rp.Kind) {
+		return fmt.Errorf("%w: kind must be one of %s", ErrInvalid, strings.Join(kinds, ", "))
+	}
+	rp.Vendor = strings.TrimSpace(rp.Vendor)
+	if rp.Vendor == "" || utf8.RuneCountInString(rp.Vendor) > maxTextLength {
+		return fmt.Errorf("%w: vendor is required and must be at most %d characters", ErrInvalid, maxTextLength)
+	}
+	rp.Damage = strings.TrimSpace(rp.Damage)
+	rp.Note = strings.TrimSpace(rp.Note)
+	if utf8.RuneCountInString(rp.Damage) > maxTextLength || utf8.RuneCountInString(rp.Note) > maxTextLength {
+		return fmt.Errorf("%w: damage and note must be at most %d characters", ErrInvalid, maxTextLength)
+	}
+	var err error
+	if rp.Cost, rp.Currency, err = validateCost(rp.Cost, rp.Currency); err != nil {
+		return err
+	}
+	if rp.SentOn == "" {
+		rp.SentOn = time.Now().Format(time.DateOnly)
+	}
+	sent, err := time.Parse(time.DateOnly, rp.SentOn)
+	if err != nil {
+		return fmt.Errorf("%w: sent_on must be a date (YYYY-MM-DD)", ErrInvalid)
+	}
+	expected, err := time.Parse(time.DateOnly, rp.ExpectedOn)
+	if err != nil {
+		return fmt.Errorf("%w: expected_on is required and must be a date (YYYY-MM-DD)", ErrInvalid)
+	}
+	if expected.Before(sent) {
+		return fmt.Errorf("%w: expected_on must not be before sent_on", ErrInvalid)
+	}
+	return s.repo.Create(ctx, rp)
+}
+
+// Get returns a repair
+func (s *Service) Get(ctx context.Context, id int) (*Repair, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+// List returns the repair queue, the copies due back soonest first
+func (s *Service) List(ctx context.Context, req ListRequest) ([]Repair, error) {
+	if req.Status != "" && !slices.Contains(statuses, req.Status) {
+		return nil, fmt.Errorf("%w: status must be one of %s", ErrInvalid, strings.Join(statuses, ", "))
+	}
+	return s.repo.List(ctx, req)
+}
+
+// ListByCopy returns the repair history of a copy
+func (s *Service) ListByCopy(ctx context.Context, acquisitionID int) ([]Repair, error) {
+	exists, err := s.repo.CopyExists(ctx, acquisitionID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrCopyNotFound
+	}
+	return s.repo.List(ctx, ListRequest{AcquisitionID: acquisitionID})
+}
+
+// Return records the copy of a repair back, on ret.ReturnedOn or today
+func (s *Service) Return(ctx context.Context, id int, ret Return) (*Repair, error) {
+	rp, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if ret.ReturnedOn == "" {
+		ret.ReturnedOn = time.Now().Format(time.DateOnly)
+	}
+	returned, err := time.Parse(time.DateOnly, ret.ReturnedOn)
+	if err != nil {
+		return nil, fmt.Errorf("%w: returned_on must be a date (YYYY-MM-DD)", ErrInvalid)
+	}
+	if sent, _ := time.Parse(time.DateOnly, rp.SentOn); returned.Before(sent) {
+		return nil, fmt.Errorf("%w: returned_on must not be before sent_on", ErrInvalid)
+	}
+	if strings.TrimSpace(ret.Currency) == "" {
+		ret.Currency = rp.Currency
+	}
+	if ret.Cost, ret.Currency, err = validateCost(ret.Cost, ret.Currency); err != nil {
+		return nil, err
+	}
+	ret.Note = strings.TrimSpace(ret.Note)
+	if utf8.RuneCountInString(ret.Note) > maxTextLength {
+		return nil, fmt.Errorf("%w: note must be at most %d characters", ErrInvalid, maxTextLength)
+	}
+	return s.repo.Return(ctx, id, ret)
+}
+
+// validateCost checks an optional cost, which needs a currency when given
+func validateCost(cost, currency string) (string, string, error) {
+	cost = strings.TrimSpace(cost)
+	if cost == "" {
+		return "", "", nil
+	}
+	if !acquisition.ValidPrice(cost) {
+		return "", "", fmt.Errorf("%w: cost must be a non-negative decimal amount with at most four decimals", ErrInvalid)
+	}
+	currency, ok := acquisition.NormalizeCurrency(currency)
+	if !ok {
+		return "", "", fmt.Errorf("%w: currency must be an ISO 4217 code such as USD", ErrInvalid)
+	}
+	return cost, currency, nil
+}
@@ -0,0 +1,199 @@
+package rfid
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"public_library/internal/httperr"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	svc    *Service
+	logger *zap.Logger
+}
+
+func NewHandler(svc *Service, l *zap.Logger) *Handler {
+	return &Handler{svc: svc, logger: l}
+}
+
+// GET /acquisitions/{id}/rfid-tags
+
+// ListCopyTags godoc
+// @Summary List the RFID tags of a copy
+// @Tags rfid
+// @Produce json
+// @Param id path int true "Acquisition ID"
+// @Success 200 {array} Tag
+// @Failure 400 {object} map[string]string
+// @Router /acquisitions/{id}/rfid-tags [get]
+func (h *Handler) ListCopyTags(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	list, err := h.svc.ListByCopy(r.Context(), id)
+	if err != nil {
+		h.writeError(w, "failed to list rfid tags", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// POST /acquisitions/{id}/rfid-tags
+
+// AssignTag godoc
+// @Summary Associate an RFID tag with a copy
+// @Description Associates the tag UID with the copy. The UID may be grouped with colons, dashes or spaces. Assigning a tag the copy already carries changes nothing; a tag of another copy must be removed from it first.
+// @Tags rfid
+// @Accept json
+// @Produce json
+// @Param id path int true "Acquisition ID"
+// @Param tag body TagRequest true "Tag to associate"
+// @Success 201 {object} Tag
+// @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Router /acquisitions/{id}/rfid-tags [post]
+func (h *Handler) AssignTag(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	var req TagRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	t, err := h.svc.Assign(r.Context(), id, req)
+	if err != nil {
+		h.writeError(w, "failed to assign rfid tag", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(t)
+}
+
+// POST /rfid-tags/encode
+
+// EncodeTags godoc
+// @Summary Encode a batch of RFID tags
+// @Description Associates up to 1000 tags with their copies, as a processing station encodes them. Either every tag is associated or, if any belongs to another copy, none is.
+// @Tags rfid
+// @Accept json
+// @Produce json
+// @Param batch body EncodeRequest true "Tags to associate"
+// @Success 200 {object} Encoded
+// @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Router /rfid-tags/encode [post]
+func (h *Handler) EncodeTags(w http.ResponseWriter, r *http.Request) {
+	var req EncodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	encoded, err := h.svc.Encode(r.Context(), req)
+	if err != nil {
+		h.writeError(w, "failed to encode rfid tags", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(encoded)
+}
+
+// GET /rfid-tags/{uid}
+
+// ResolveTag godoc
+// @Summary Resolve an RFID tag read to its copy
+// @Description Returns the copy carrying the tag, whether it is checked out, and whether a security gate should alarm for it.
+// @Tags rfid
+// @Produce json
+// @Param uid path string true "Tag UID" example(E004015012345678)
+// @Success 200 {object} Item
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /rfid-tags/{uid} [get]
+func (h *Handler) ResolveTag(w http.ResponseWriter, r *http.Request) {
+	it, err := h.svc.Resolve(r.Context(), mux.Vars(r)["uid"])
+	if err != nil {
+		h.writeError(w, "failed to resolve rfid tag", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(it)
+}
+
+// POST /rfid-tags/resolve
+
+// ResolveTags godoc
+// @Summary Resolve many RFID tag reads at once
+// @Description Resolves up to 500 tags a reader sees at once, such as a self-check pad or a security gate. Reads that are not a UID, and tags of no copy, are listed as unknown.
+// @Tags rfid
+// @Accept json
+// @Produce json
+// @Param reads body ResolveRequest true "Tag UIDs read"
+// @Success 200 {object} Resolved
+// @Failure 400 {object} map[string]string
+// @Router /rfid-tags/resolve [post]
+func (h *Handler) ResolveTags(w http.ResponseWriter, r *http.Request) {
+	var req ResolveRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	resolved, err := h.svc.ResolveAll(r.Context(), req)
+	if err != nil {
+		h.writeError(w, "failed to resolve rfid tags", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resolved)
+}
+
+// DELETE /rfid-tags/{uid}
+
+// RemoveTag godoc
+// @Summary Remove an RFID tag from its copy
+// @Description Unassigns the tag, e.g. when a damaged tag is replaced, so it can be encoded for another copy.
+// @Tags rfid
+// @Param uid path string true "Tag UID" example(E004015012345678)
+// @Success 204 "No Content"
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /rfid-tags/{uid} [delete]
+func (h *Handler) RemoveTag(w http.ResponseWriter, r *http.Request) {
+	if err := h.svc.Remove(r.Context(), mux.Vars(r)["uid"]); err != nil {
+		h.writeError(w, "failed to remove rfid tag", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func pathID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "invalid acquisition ID", http.StatusBadRequest)
+		return 0, false
+	}
+	return id, true
+}
+
+func (h *Handler) writeError(w http.ResponseWriter, msg string, err error) {
+	switch {
+	case errors.Is(err, ErrInvalid):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, ErrNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, ErrTagInUse):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		httperr.Write(w, h.logger, msg, err)
+	}
+}
@@ -0,0 +1,60 @@
+package rfid
+
+import "time"
+
+// Tag is an RFID tag encoded for a copy
+type Tag struct {
+	// UID is the tag's unique ID in upper-case hex, as readers report it
+	UID           string    `json:"uid" example:"E004015012345678"`
+	AcquisitionID int       `json:"acquisition_id" example:"31"`
+	EncodedAt     time.Time `json:"encoded_at"`
+}
+
+// TagRequest associates a tag with a copy
+type TagRequest struct {
+	UID string `json:"uid" example:"E0:04:01:50:12:34:56:78"`
+}
+
+// EncodeRequest associates a batch of tags with their copies, e.g. as a
+// processing station encodes them
+type EncodeRequest struct {
+	Tags []EncodeTag `json:"tags"`
+}
+
+// EncodeTag is one tag of an EncodeRequest
+type EncodeTag struct {
+	UID           string `json:"uid" example:"E004015012345678"`
+	AcquisitionID int    `json:"acquisition_id" example:"31"`
+}
+
+// Encoded reports the tags of an EncodeRequest, all of them now associated
+type Encoded struct {
+	Encoded int   `json:"encoded" example:"40"`
+	Tags    []Tag `json:"tags"`
+}
+
+// Item is the copy an RFID tag read resolves to
+type Item struct {
+	UID           string `json:"uid" example:"E004015012345678"`
+	AcquisitionID int    `json:"acquisition_id" example:"31"`
+	BookID        int    `json:"book_id" example:"1"`
+	Title         string `json:"title" example:"The Left Hand of Darkness"`
+	Branch        string `json:"branch" example:"Central"`
+	CheckedOut    bool   `json:"checked_out" example:"false"`
+	Withdrawn     bool   `json:"withdrawn" example:"false"`
+	// Alarm is set for copies that are not checked out, which a security
+	// gate should not let leave
+	Alarm bool `json:"alarm" example:"true"`
+}
+
+// ResolveRequest resolves every tag a reader sees at once, as at a gate
+type ResolveRequest struct {
+	UIDs []string `json:"uids" example:"E004015012345678,E004015087654321"`
+}
+
+// Resolved lists the copies of the tags read, and the tags that are not
+// associated with any copy
+type Resolved struct {
+	Items   []Item   `json:"items"`
+	Unknown []string `json:"unknown" example:"E0040150DEADBEEF"`
+}
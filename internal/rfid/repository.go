@@ -0,0 +1,171 @@
+package rfid
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"public_library/internal/db"
+	"public_library/utils"
+)
+
+var (
+	// ErrNotFound is returned when no copy carries the tag
+	ErrNotFound = errors.New("rfid tag not found")
+	// ErrTagInUse is returned for encoding a tag that is already associated
+	// with another copy
+	ErrTagInUse = errors.New("rfid tag is encoded for another copy")
+)
+
+type Repository struct {
+	db *db.DB
+}
+
+func NewRepository(conn *db.DB) *Repository {
+	return &Repository{db: conn}
+}
+
+// ListByCopy returns the tags of the copy, in the order they were encoded
+func (r *Repository) ListByCopy(ctx context.Context, acquisitionID int) ([]Tag, error) {
+	query := fmt.Sprintf(`
+		SELECT uid, acquisition_id, encoded_at FROM %s
+		WHERE acquisition_id = $1
+		ORDER BY encoded_at, uid
+	`, utils.RFIDTagsTable)
+	rows, err := r.db.QueryContext(ctx, query, acquisitionID)
+	if err != nil {
+		log.Printf("Failed to list the rfid tags of copy id=%d: %v", acquisitionID, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []Tag{}
+	for rows.Next() {
+		var t Tag
+		if err := rows.Scan(&t.UID, &t.AcquisitionID, &t.EncodedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, t)
+	}
+	return list, rows.Err()
+}
+
+// Assign associates the tag with the copy. Assigning a tag to the copy it
+// already belongs to keeps it as it was.
+func (r *Repository) Assign(ctx context.Context, acquisitionID int, uid string) (*Tag, error) {
+	log.Println("<--------Assign rfid tag starts-------->")
+	defer log.Println("<--------Assign rfid tag ends-------->")
+
+	t, err := assign(ctx, r.db, acquisitionID, uid)
+	if err != nil {
+		log.Printf("Failed to assign rfid tag %s to copy id=%d: %v", uid, acquisitionID, err)
+		return nil, err
+	}
+	return t, nil
+}
+
+// Encode associates every tag with its copy, or none of them if any is
+// already associated with another copy
+func (r *Repository) Encode(ctx context.Context, tags []EncodeTag) ([]Tag, error) {
+	log.Println("<--------Encode rfid tags starts-------->")
+	defer log.Println("<--------Encode rfid tags ends-------->")
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("Failed to begin encode transaction: %v", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	encoded := make([]Tag, 0, len(tags))
+	for _, et := range tags {
+		t, err := assign(ctx, tx, et.AcquisitionID, et.UID)
+		if err != nil {
+			log.Printf("Failed to encode rfid tag %s for copy id=%d: %v", et.UID, et.AcquisitionID, err)
+			return nil, err
+		}
+		encoded = append(encoded, *t)
+	}
+	if err := tx.Commit(); err != nil {
+		log.Printf("Failed to commit %d rfid tags: %v", len(tags), err)
+		return nil, err
+	}
+	return encoded, nil
+}
+
+func assign(ctx context.Context, q db.Queryer, acquisitionID int, uid string) (*Tag, error) {
+	t := &Tag{}
+	query := fmt.Sprintf(`
+		INSERT INTO %[1]s (uid, acquisition_id) VALUES ($1, $2)
+		ON CONFLICT (uid) DO UPDATE SET uid = EXCLUDED.uid
+		WHERE %[1]s.acquisition_id = EXCLUDED.acquisition_id
+		RETURNING uid, acquisition_id, encoded_at
+	`, utils.RFIDTagsTable)
+	err := q.QueryRowContext(ctx, query, uid, acquisitionID).Scan(&t.UID, &t.AcquisitionID, &t.EncodedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		// the tag belongs to another copy
+		var other int
+		query := fmt.Sprintf(`SELECT acquisition_id FROM %s WHERE uid = $1`, utils.RFIDTagsTable)
+		if err := q.QueryRowContext(ctx, query, uid).Scan(&other); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: %s belongs to copy %d", ErrTagInUse, uid, other)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Remove unassigns the tag from its copy, e.g. when the tag is replaced
+func (r *Repository) Remove(ctx context.Context, uid string) error {
+	log.Println("<--------Remove rfid tag starts-------->")
+	defer log.Println("<--------Remove rfid tag ends-------->")
+
+	query := fmt.Sprintf(`DELETE FROM %s WHERE uid = $1`, utils.RFIDTagsTable)
+	res, err := r.db.ExecContext(ctx, query, uid)
+	if err != nil {
+		log.Printf("Failed to remove rfid tag %s: %v", uid, err)
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Resolve returns the copies carrying the tags, with whether each is
+// checked out; tags of no copy are left out
+func (r *Repository) Resolve(ctx context.Context, uids []string) ([]Item, error) {
+	query := fmt.Sprintf(`
+		SELECT t.uid, a.id, a.book_id, b.title, a.branch,
+			EXISTS (SELECT 1 FROM %[4]s l WHERE l.acquisition_id = a.id AND l.returned_at IS NULL),
+			a.withdrawn_at IS NOT NULL
+		FROM %[1]s t
+		JOIN %[2]s a ON a.id = t.acquisition_id
+		JOIN %[3]s b ON b.id = a.book_id
+		WHERE t.uid = ANY($1)
+		ORDER BY t.uid
+	`, utils.RFIDTagsTable, utils.AcquisitionsTable, utils.BooksTable, utils.LoansTable)
+	rows, err := r.db.QueryContext(ctx, query, uids)
+	if err != nil {
+		log.Printf("Failed to resolve %d rfid tags: %v", len(uids), err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []Item{}
+	for rows.Next() {
+		var it Item
+		if err := rows.Scan(&it.UID, &it.AcquisitionID, &it.BookID, &it.Title, &it.Branch,
+			&it.CheckedOut, &it.Withdrawn); err != nil {
+			return nil, err
+		}
+		it.Alarm = !it.CheckedOut
+		items = append(items, it)
+	}
+	return items, rows.Err()
+}
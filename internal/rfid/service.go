@@ -0,0 +1,158 @@
+// Package rfid associates RFID tags with copies and resolves tag reads back
+// to them, for RFID self-check machines and security gates. Tags are known
+// by their UID, such as the 64-bit UID of an ISO 15693 library tag.
+package rfid
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ErrInvalid is wrapped by errors about the content of a request
+var ErrInvalid = errors.New("invalid rfid request")
+
+const (
+	// maxEncodeTags caps the tags of one encode request
+	maxEncodeTags = 1000
+	// maxResolveTags caps the tags of one resolve request; a gate reads far
+	// fewer at once
+	maxResolveTags = 500
+)
+
+// uidPattern matches a normalized UID: 4 to 32 bytes in hex
+var uidPattern = regexp.MustCompile(`^([0-9A-F]{2}){4,32}$`)
+
+// uidSeparators are left out of UIDs, which readers print grouped
+var uidSeparators = strings.NewReplacer(":", "", "-", "", " ", "")
+
+type Service struct {
+	repo *Repository
+}
+
+func NewService(repo *Repository) *Service {
+	return &Service{repo: repo}
+}
+
+// NormalizeUID returns a tag UID in the upper-case hex it is stored as,
+// without the separators readers group it with
+func NormalizeUID(uid string) (string, error) {
+	normalized := strings.ToUpper(uidSeparators.Replace(strings.TrimSpace(uid)))
+	if !uidPattern.MatchString(normalized) {
+		return "", fmt.Errorf("%w: uid must be 4 to 32 bytes in hex, such as E004015012345678", ErrInvalid)
+	}
+	return normalized, nil
+}
+
+// ListByCopy returns the tags of the copy
+func (s *Service) ListByCopy(ctx context.Context, acquisitionID int) ([]Tag, error) {
+	return s.repo.ListByCopy(ctx, acquisitionID)
+}
+
+// Assign associates the tag with the copy
+func (s *Service) Assign(ctx context.Context, acquisitionID int, req TagRequest) (*Tag, error) {
+	uid, err := NormalizeUID(req.UID)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.Assign(ctx, acquisitionID, uid)
+}
+
+// Encode associates a batch of tags with their copies, all or none
+func (s *Service) Encode(ctx context.Context, req EncodeRequest) (*Encoded, error) {
+	if len(req.Tags) == 0 {
+		return nil, fmt.Errorf("%w: tags is required", ErrInvalid)
+	}
+	if len(req.Tags) > maxEncodeTags {
+		return nil, fmt.Errorf("%w: at most %d tags can be encoded at once", ErrInvalid, maxEncodeTags)
+	}
+	copies := make(map[string]int, len(req.Tags))
+	for i := range req.Tags {
+		t := &req.Tags[i]
+		uid, err := NormalizeUID(t.UID)
+		if err != nil {
+			return nil, fmt.Errorf("tags[%d]: %w", i, err)
+		}
+		if other, ok := copies[uid]; ok && other != t.AcquisitionID {
+			return nil, fmt.Errorf("%w: tags[%d]: %s is listed for copies %d and %d", ErrInvalid, i, uid, other, t.AcquisitionID)
+		}
+		t.UID, copies[uid] = uid, t.AcquisitionID
+	}
+	tags, err := s.repo.Encode(ctx, req.Tags)
+	if err != nil {
+		return nil, err
+	}
+	return &Encoded{Encoded: len(tags), Tags: tags}, nil
+}
+
+// Remove unassigns the tag from its copy
+func (s *Service) Remove(ctx context.Context, uid string) error {
+	uid, err := NormalizeUID(uid)
+	if err != nil {
+		return err
+	}
+	return s.repo.Remove(ctx, uid)
+}
+
+// Resolve returns the copy of one tag read
+func (s *Service) Resolve(ctx context.Context, uid string) (*Item, error) {
+	uid, err := NormalizeUID(uid)
+	if err != nil {
+		return nil, err
+	}
+	items, err := s.repo.Resolve(ctx, []string{uid})
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, ErrNotFound
+	}
+	return &items[0], nil
+}
+
+// ResolveAll returns the copies of every tag read at once. Reads that are
+// not a UID, as well as tags of no copy, are reported as unknown.
+func (s *Service) ResolveAll(ctx context.Context, req ResolveRequest) (*Resolved, error) {
+	if len(req.UIDs) > maxResolveTags {
+		return nil, fmt.Errorf("%w: at most %d tags can be resolved at once", ErrInvalid, maxResolveTags)
+	}
+	resolved := &Resolved{Items: []Item{}, Unknown: []string{}}
+	seen := make(map[string]bool, len(req.UIDs))
+	var uids []string
+	for _, read := range req.UIDs {
+		uid, err := NormalizeUID(read)
+		if err != nil {
+			uid = strings.TrimSpace(read)
+		}
+		if seen[uid] {
+			continue
+		}
+		seen[uid] = true
+		if err != nil {
+			resolved.Unknown = append(resolved.Unknown, uid)
+			continue
+		}
+		uids = append(uids, uid)
+	}
+	if len(uids) == 0 {
+		return resolved, nil
+	}
+
+	items, err := s.repo.Resolve(ctx, uids)
+	if err != nil {
+		return nil, err
+	}
+	found := make(map[string]bool, len(items))
+	for _, it := range items {
+		found[it.UID] = true
+	}
+	resolved.Items = items
+	for _, uid := range uids {
+		if !found[uid] {
+			resolved.Unknown = append(resolved.Unknown, uid)
+		}
+	}
+	return resolved, nil
+}
@@ -0,0 +1,105 @@
+// Package sandbox runs the API as a public demo. The server's tables live in
+// their own schema (db.schema is set to sandbox.schema), which Reset drops,
+// recreates and fills with demo data on a schedule.
+package sandbox
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"public_library/internal/book"
+	"public_library/internal/config"
+	"public_library/internal/db"
+	"public_library/internal/series"
+	"public_library/internal/stats"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+type Sandbox struct {
+	conn       *sql.DB
+	cfg        config.SandboxConfig
+	partitions config.PartitionConfig
+	books      *book.Service
+	series     *series.Repository
+	stats      *stats.Repository
+	logger     *zap.Logger
+}
+
+func New(conn *sql.DB, cfg config.AppConfig, books *book.Service, s *series.Repository, st *stats.Repository, logger *zap.Logger) *Sandbox {
+	return &Sandbox{
+		conn:       conn,
+		cfg:        cfg.Sandbox,
+		partitions: cfg.Partitions,
+		books:      books,
+		series:     s,
+		stats:      st,
+		logger:     logger,
+	}
+}
+
+// Reset replaces the sandbox schema with a fresh copy of the demo data. The
+// schema is rebuilt in one transaction, so requests see either the old
+// tables or the new ones.
+func (s *Sandbox) Reset(ctx context.Context) error {
+	schema := pgx.Identifier{s.cfg.Schema}.Sanitize()
+	tx, err := s.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, stmt := range []string{
+		"DROP SCHEMA IF EXISTS " + schema + " CASCADE",
+		"CREATE SCHEMA " + schema,
+		"SET LOCAL search_path TO " + schema + ", public",
+	} {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("resetting sandbox schema: %w", err)
+		}
+	}
+	if err := db.ApplySchema(ctx, tx); err != nil {
+		return err
+	}
+	// Partitions must exist before the seed writes audit rows, or the rows
+	// land in the default partition and block creating them later
+	if err := db.MaintainPartitions(ctx, tx, s.partitions, time.Now()); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	s.books.InvalidateLists()
+	if err := s.seed(ctx); err != nil {
+		return fmt.Errorf("seeding sandbox: %w", err)
+	}
+	return s.stats.Refresh(ctx)
+}
+
+// StartResets resets the sandbox now and then every reset interval until
+// ctx is cancelled
+func (s *Sandbox) StartResets(ctx context.Context) {
+	reset := func() {
+		if err := s.Reset(ctx); err != nil {
+			s.logger.Error("Sandbox reset failed", zap.Error(err))
+			return
+		}
+		s.logger.Info("Sandbox reset", zap.String("schema", s.cfg.Schema))
+	}
+	go func() {
+		reset()
+		ticker := time.NewTicker(s.cfg.ResetInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				reset()
+			}
+		}
+	}()
+}
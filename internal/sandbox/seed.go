@@ -0,0 +1,91 @@
+package sandbox
+
+import (
+	"context"
+	"errors"
+	"public_library/internal/book"
+	"public_library/internal/series"
+	"public_library/pkg/fixtures"
+	"time"
+)
+
+func intPtr(n int) *int { return &n }
+
+func position(p float64) *float64 { return &p }
+
+// DemoSeries and DemoBooks are the hand-picked part of the demo catalog,
+// also served by cmd/mockserver
+var DemoSeries = []series.Series{
+	{Title: "The Lord of the Rings", Description: "Tolkien's epic in three volumes"},
+}
+
+// DemoBooks are created through the book service, so they pass the same
+// validation as visitors' books. Series IDs index DemoSeries, plus one.
+var DemoBooks = []book.Book{
+	{Title: "The Great Gatsby", Author: "F. Scott Fitzgerald", ISBN: "9780743273565", Format: "paperback", Audience: "adult",
+		CallNumber: "813.52 FIT", ShelfLocation: "2F-A12", Collection: "Adult Fiction", PublicationYear: intPtr(1925), PageCount: intPtr(180),
+		Description: "A portrait of the Jazz Age on Long Island."},
+	{Title: "Pride and Prejudice", Author: "Jane Austen", ISBN: "9780141439518", Format: "paperback", Audience: "adult",
+		CallNumber: "823.7 AUS", ShelfLocation: "2F-A03", Collection: "Adult Fiction", PublicationYear: intPtr(1813), PageCount: intPtr(480),
+		Description: "Elizabeth Bennet and Mr Darcy misjudge each other across the drawing rooms of Regency England."},
+	{Title: "Moby-Dick", Author: "Herman Melville", ISBN: "9780142437247", Format: "paperback", Audience: "adult",
+		CallNumber: "813.3 MEL", ShelfLocation: "2F-A10", Collection: "Adult Fiction", PublicationYear: intPtr(1851), PageCount: intPtr(720),
+		Description: "Captain Ahab pursues the white whale that took his leg."},
+	{Title: "Frankenstein", Author: "Mary Shelley", ISBN: "9780141439471", Format: "paperback", Audience: "adult",
+		CallNumber: "823.7 SHE", ShelfLocation: "2F-A04", Collection: "Adult Fiction", PublicationYear: intPtr(1818), PageCount: intPtr(280),
+		Description: "A young scientist creates life and recoils from what he has made."},
+	{Title: "Jane Eyre", Author: "Charlotte Brontë", ISBN: "9780141441146", Format: "paperback", Audience: "adult",
+		CallNumber: "823.8 BRO", ShelfLocation: "2F-A05", Collection: "Adult Fiction", PublicationYear: intPtr(1847), PageCount: intPtr(624),
+		Description: "An orphaned governess finds love and a secret at Thornfield Hall."},
+	{Title: "The Hobbit", Author: "J.R.R. Tolkien", ISBN: "9780547928227", Format: "paperback", Audience: "teen",
+		CallNumber: "823.912 TOL", ShelfLocation: "1F-T02", Collection: "Teen Fiction", PublicationYear: intPtr(1937), PageCount: intPtr(300),
+		Description: "Bilbo Baggins is swept into a quest for a dragon's hoard."},
+	{Title: "The Fellowship of the Ring", Author: "J.R.R. Tolkien", ISBN: "9780547928210", Format: "paperback", Audience: "teen",
+		CallNumber: "823.912 TOL", ShelfLocation: "1F-T02", Collection: "Teen Fiction", PublicationYear: intPtr(1954), PageCount: intPtr(432),
+		Series: &book.SeriesRef{ID: 1, Position: position(1)}},
+	{Title: "The Two Towers", Author: "J.R.R. Tolkien", ISBN: "9780547928203", Format: "paperback", Audience: "teen",
+		CallNumber: "823.912 TOL", ShelfLocation: "1F-T02", Collection: "Teen Fiction", PublicationYear: intPtr(1954), PageCount: intPtr(352),
+		Series: &book.SeriesRef{ID: 1, Position: position(2)}},
+	{Title: "The Return of the King", Author: "J.R.R. Tolkien", ISBN: "9780547928197", Format: "paperback", Audience: "teen",
+		CallNumber: "823.912 TOL", ShelfLocation: "1F-T02", Collection: "Teen Fiction", PublicationYear: intPtr(1955), PageCount: intPtr(432),
+		Series: &book.SeriesRef{ID: 1, Position: position(3)}},
+	{Title: "Charlotte's Web", Author: "E.B. White", ISBN: "9780064400558", Format: "paperback", Audience: "children",
+		CallNumber: "J WHI", ShelfLocation: "1F-C07", Collection: "Children's Fiction", PublicationYear: intPtr(1952), PageCount: intPtr(192),
+		Description: "A pig named Wilbur is saved by the words a spider spins in her web."},
+	{Title: "Where the Wild Things Are", Author: "Maurice Sendak", ISBN: "9780060254926", Format: "hardcover", Audience: "children",
+		CallNumber: "E SEN", ShelfLocation: "1F-C01", Collection: "Picture Books", PublicationYear: intPtr(1963), PageCount: intPtr(48)},
+}
+
+func (s *Sandbox) seed(ctx context.Context) error {
+	ids := make([]int, len(DemoSeries))
+	for i := range DemoSeries {
+		sr := DemoSeries[i]
+		if err := s.series.Create(ctx, &sr); err != nil {
+			return err
+		}
+		ids[i] = sr.ID
+	}
+	for i := range DemoBooks {
+		b := DemoBooks[i]
+		if b.Series != nil {
+			ref := *b.Series
+			ref.ID = ids[ref.ID-1]
+			b.Series = &ref
+		}
+		if err := s.books.Create(ctx, &b); err != nil {
+			return err
+		}
+	}
+
+	// A fixed seed gives visitors the same catalog after every reset
+	generated := fixtures.New(1, time.Now()).Books(s.cfg.GeneratedBooks)
+	for i := range generated {
+		if err := s.books.Create(ctx, &generated[i]); err != nil {
+			if errors.Is(err, book.ErrDuplicate) {
+				continue // a generated ISBN matched a classic above
+			}
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package savedsearch
+
+import (
+	"context"
+	"public_library/internal/eventbus"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// maxAlertBooks caps the books of one alert; a search that matches more is
+// alerted about the rest on the next run
+const maxAlertBooks = 50
+
+// SendAlerts checks every saved search against the books added since it was
+// last checked, publishes an alert for each that matches any, and returns
+// how many were sent
+func (s *Service) SendAlerts(ctx context.Context) (int, error) {
+	lastID, err := s.books.LastID(ctx)
+	if err != nil {
+		return 0, err
+	}
+	searches, err := s.repo.ListAll(ctx)
+	if err != nil {
+		return 0, err
+	}
+	sent := 0
+	for _, ss := range searches {
+		if ss.LastBookID >= lastID {
+			continue
+		}
+		books, err := s.books.ListAddedAfter(ctx, ss.Query.request(), ss.LastBookID, maxAlertBooks)
+		if err != nil {
+			return sent, err
+		}
+		upTo := lastID
+		if len(books) > 0 {
+			upTo = max(upTo, books[len(books)-1].ID)
+			if len(books) == maxAlertBooks {
+				upTo = books[len(books)-1].ID
+			}
+		}
+		if err := s.repo.Advance(ctx, ss.ID, upTo, len(books) > 0); err != nil {
+			return sent, err
+		}
+		if len(books) == 0 {
+			continue
+		}
+		s.bus.Publish(ctx, eventbus.SavedSearchMatched, ss.ID,
+			Alert{SavedSearchID: ss.ID, MemberID: ss.MemberID, Name: ss.Name, Books: books})
+		sent++
+	}
+	return sent, nil
+}
+
+// StartAlerts sends saved search alerts at start and every alert_interval
+// until ctx is cancelled. A non-positive interval disables them.
+func (s *Service) StartAlerts(ctx context.Context, logger *zap.Logger) {
+	if s.cfg.AlertInterval <= 0 {
+		return
+	}
+	send := func() {
+		sent, err := s.SendAlerts(ctx)
+		if err != nil {
+			logger.Error("Saved search alerts failed", zap.Error(err))
+			return
+		}
+		if sent > 0 {
+			logger.Info("Saved search alerts sent", zap.Int("count", sent))
+		}
+	}
+	go func() {
+		send()
+		ticker := time.NewTicker(s.cfg.AlertInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				send()
+			}
+		}
+	}()
+}
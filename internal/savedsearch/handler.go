@@ -0,0 +1,107 @@
+package savedsearch
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"public_library/internal/httperr"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	svc    *Service
+	logger *zap.Logger
+}
+
+func NewHandler(svc *Service, l *zap.Logger) *Handler {
+	return &Handler{svc: svc, logger: l}
+}
+
+// GET /members/{id}/saved-searches
+
+// ListSavedSearches godoc
+// @Summary List a member's saved searches
+// @Tags saved-searches
+// @Produce json
+// @Param id path string true "Member ID"
+// @Success 200 {array} SavedSearch
+// @Router /members/{id}/saved-searches [get]
+func (h *Handler) ListSavedSearches(w http.ResponseWriter, r *http.Request) {
+	list, err := h.svc.List(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		h.writeError(w, "failed to list saved searches", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// POST /members/{id}/saved-searches
+
+// CreateSavedSearch godoc
+// @Summary Save a search for a member
+// @Description Saves the search and filters of a book list request. Books added to the catalog from now on that match it are published as a saved_search.matched alert for the member. The name defaults to the search.
+// @Tags saved-searches
+// @Accept json
+// @Produce json
+// @Param id path string true "Member ID"
+// @Param search body SavedSearch true "Search to save; name and query are read"
+// @Success 201 {object} SavedSearch
+// @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /members/{id}/saved-searches [post]
+func (h *Handler) CreateSavedSearch(w http.ResponseWriter, r *http.Request) {
+	var ss SavedSearch
+	if err := json.NewDecoder(r.Body).Decode(&ss); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	saved := SavedSearch{Name: ss.Name, Query: ss.Query}
+	if err := h.svc.Create(r.Context(), mux.Vars(r)["id"], &saved); err != nil {
+		h.writeError(w, "failed to save search", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(saved)
+}
+
+// DELETE /members/{id}/saved-searches/{search_id}
+
+// DeleteSavedSearch godoc
+// @Summary Delete a member's saved search
+// @Tags saved-searches
+// @Param id path string true "Member ID"
+// @Param search_id path int true "Saved search ID"
+// @Success 204
+// @Failure 404 {object} map[string]string
+// @Router /members/{id}/saved-searches/{search_id} [delete]
+func (h *Handler) DeleteSavedSearch(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["search_id"])
+	if err != nil {
+		http.Error(w, "invalid saved search ID", http.StatusBadRequest)
+		return
+	}
+	if err := h.svc.Delete(r.Context(), vars["id"], id); err != nil {
+		h.writeError(w, "failed to delete saved search", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) writeError(w http.ResponseWriter, msg string, err error) {
+	switch {
+	case errors.Is(err, ErrInvalid):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, ErrNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, ErrLimit):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		httperr.Write(w, h.logger, msg, err)
+	}
+}
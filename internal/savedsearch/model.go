@@ -0,0 +1,72 @@
+package savedsearch
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"public_library/internal/book"
+	"time"
+)
+
+// SavedSearch is a member's book search that alerts them when books added
+// to the catalog match it
+type SavedSearch struct {
+	ID       int    `json:"id" example:"1"`
+	MemberID string `json:"member_id" example:"m-1001"`
+	Name     string `json:"name" example:"New books by Brandon Sanderson"`
+	Query    Query  `json:"query"`
+	// LastBookID is the last book added to the catalog when the search was
+	// saved or last checked; only books added after it alert the member
+	LastBookID    int        `json:"last_book_id" example:"1042"`
+	LastAlertedAt *time.Time `json:"last_alerted_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// Query is the search and filters of a book list request
+type Query struct {
+	Search    string   `json:"search,omitempty" example:"brandon sanderson"` // title, author, ISBN or full text
+	YearFrom  *int     `json:"year_from,omitempty" example:"2020"`           // inclusive
+	YearTo    *int     `json:"year_to,omitempty"`                            // inclusive
+	Formats   []string `json:"formats,omitempty" example:"hardcover,ebook"`
+	Audiences []string `json:"audiences,omitempty" example:"adult"`
+	// AccessibilityFeatures matches books that have every listed feature
+	AccessibilityFeatures []string `json:"accessibility_features,omitempty"`
+}
+
+// request is the book list request that runs the query
+func (q Query) request() book.PaginationRequest {
+	return book.PaginationRequest{Search: q.Search, YearFrom: q.YearFrom, YearTo: q.YearTo,
+		Formats: q.Formats, Audiences: q.Audiences, AccessibilityFeatures: q.AccessibilityFeatures}
+}
+
+func (q Query) empty() bool {
+	return q.Search == "" && q.YearFrom == nil && q.YearTo == nil &&
+		len(q.Formats) == 0 && len(q.Audiences) == 0 && len(q.AccessibilityFeatures) == 0
+}
+
+// Value stores the query as JSON
+func (q Query) Value() (driver.Value, error) {
+	b, err := json.Marshal(q)
+	return string(b), err
+}
+
+// Scan reads the query from its JSON column
+func (q *Query) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case []byte:
+		return json.Unmarshal(v, q)
+	case string:
+		return json.Unmarshal([]byte(v), q)
+	default:
+		return fmt.Errorf("cannot scan %T into a saved search query", src)
+	}
+}
+
+// Alert tells a member about books added to the catalog that match one of
+// their saved searches
+type Alert struct {
+	SavedSearchID int         `json:"saved_search_id" example:"1"`
+	MemberID      string      `json:"member_id" example:"m-1001"`
+	Name          string      `json:"name" example:"New books by Brandon Sanderson"`
+	Books         []book.Book `json:"books"`
+}
@@ -0,0 +1,99 @@
+package savedsearch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"public_library/internal/db"
+	"public_library/utils"
+)
+
+// ErrNotFound is returned when the member has no saved search with the id
+var ErrNotFound = errors.New("saved search not found")
+
+var searchMapping = db.Mapping[SavedSearch]{
+	Table:   utils.SavedSearchesTable,
+	Columns: []string{"id", "member_id", "name", "query", "last_book_id", "last_alerted_at", "created_at"},
+	Fields: func(s *SavedSearch) []interface{} {
+		return []interface{}{&s.ID, &s.MemberID, &s.Name, &s.Query, &s.LastBookID, &s.LastAlertedAt, &s.CreatedAt}
+	},
+	Writable: []string{"member_id", "name", "query", "last_book_id"},
+	Values: func(s *SavedSearch) []interface{} {
+		return []interface{}{s.MemberID, s.Name, s.Query, s.LastBookID}
+	},
+	ID: func(s *SavedSearch) int { return s.ID },
+}
+
+type Repository struct {
+	db       *db.DB
+	searches *db.Table[SavedSearch]
+}
+
+func NewRepository(conn *db.DB) *Repository {
+	return &Repository{db: conn, searches: db.NewTable(conn, searchMapping)}
+}
+
+// List returns the member's saved searches, oldest first
+func (r *Repository) List(ctx context.Context, memberID string) ([]SavedSearch, error) {
+	return r.searches.List(ctx, db.ListOptions{
+		Filters: []db.Filter{{Column: "member_id", Op: "=", Value: memberID}},
+		OrderBy: []string{"id"},
+	})
+}
+
+// ListAll returns every saved search, for checking them for new books
+func (r *Repository) ListAll(ctx context.Context) ([]SavedSearch, error) {
+	return r.searches.List(ctx, db.ListOptions{OrderBy: []string{"id"}})
+}
+
+// Count returns how many searches the member has saved
+func (r *Repository) Count(ctx context.Context, memberID string) (int64, error) {
+	return r.searches.Count(ctx, []db.Filter{{Column: "member_id", Op: "=", Value: memberID}})
+}
+
+func (r *Repository) Create(ctx context.Context, s *SavedSearch) error {
+	log.Println("<--------Create saved search starts-------->")
+	defer log.Println("<--------Create saved search ends-------->")
+
+	if err := r.searches.Insert(ctx, s); err != nil {
+		log.Printf("Failed to save search %q for member id=%s: %v", s.Name, s.MemberID, err)
+		return err
+	}
+	return nil
+}
+
+// Delete removes the member's saved search with id
+func (r *Repository) Delete(ctx context.Context, memberID string, id int) error {
+	log.Println("<--------Delete saved search starts-------->")
+	defer log.Println("<--------Delete saved search ends-------->")
+
+	query := fmt.Sprintf(`DELETE FROM %s WHERE id = $1 AND member_id = $2`, utils.SavedSearchesTable)
+	res, err := r.db.ExecContext(ctx, query, id, memberID)
+	if err != nil {
+		log.Printf("Failed to delete saved search id=%d: %v", id, err)
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Advance records that the search was checked up to the book with
+// lastBookID, and if alerted that the member was just alerted. A search
+// checked concurrently up to a later book is left as it is.
+func (r *Repository) Advance(ctx context.Context, id, lastBookID int, alerted bool) error {
+	query := fmt.Sprintf(`
+		UPDATE %s
+		SET last_book_id = $2, last_alerted_at = CASE WHEN $3 THEN now() ELSE last_alerted_at END
+		WHERE id = $1 AND last_book_id < $2
+	`, utils.SavedSearchesTable)
+	if _, err := r.db.ExecContext(ctx, query, id, lastBookID, alerted); err != nil {
+		log.Printf("Failed to advance saved search id=%d to book id=%d: %v", id, lastBookID, err)
+		return err
+	}
+	return nil
+}
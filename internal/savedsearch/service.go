@@ -0,0 +1,90 @@
+// Package savedsearch lets members save a book search, such as new books by
+// an author, and alerts them when books added to the catalog match it. A
+// scheduled job checks every saved search against the books added since it
+// was last checked and publishes the matches on the event bus, for
+// notification senders to deliver.
+package savedsearch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"public_library/internal/book"
+	"public_library/internal/config"
+	"public_library/internal/eventbus"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	// ErrInvalid is wrapped by errors about the content of a request
+	ErrInvalid = errors.New("invalid saved search")
+	// ErrLimit is returned for saving a search beyond
+	// saved_searches.max_per_member
+	ErrLimit = errors.New("saved search limit reached")
+)
+
+const maxNameLength = 200
+
+type Service struct {
+	repo  *Repository
+	books *book.Service
+	cfg   config.SavedSearchesConfig
+	bus   *eventbus.Bus
+}
+
+// NewService creates the saved search service. Alerts are published on bus
+// under eventbus.SavedSearchMatched.
+func NewService(repo *Repository, books *book.Service, cfg config.SavedSearchesConfig, bus *eventbus.Bus) *Service {
+	return &Service{repo: repo, books: books, cfg: cfg, bus: bus}
+}
+
+// List returns the member's saved searches
+func (s *Service) List(ctx context.Context, memberID string) ([]SavedSearch, error) {
+	return s.repo.List(ctx, memberID)
+}
+
+// Create validates ss and saves it for the member. Only books added from
+// now on alert them.
+func (s *Service) Create(ctx context.Context, memberID string, ss *SavedSearch) error {
+	ss.MemberID = memberID
+	ss.Name = strings.TrimSpace(ss.Name)
+	ss.Query.Search = strings.TrimSpace(ss.Query.Search)
+	if ss.Name == "" {
+		ss.Name = ss.Query.Search
+	}
+	if ss.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalid)
+	}
+	if utf8.RuneCountInString(ss.Name) > maxNameLength {
+		return fmt.Errorf("%w: name must be at most %d characters", ErrInvalid, maxNameLength)
+	}
+	if ss.Query.empty() {
+		return fmt.Errorf("%w: query must have a search or a filter", ErrInvalid)
+	}
+
+	saved, err := s.repo.Count(ctx, memberID)
+	if err != nil {
+		return err
+	}
+	if saved >= int64(s.cfg.MaxPerMember) {
+		return fmt.Errorf("%w: a member can save at most %d searches", ErrLimit, s.cfg.MaxPerMember)
+	}
+
+	if ss.LastBookID, err = s.books.LastID(ctx); err != nil {
+		return err
+	}
+	// running the query for no books checks its filters before it is saved
+	if _, err := s.books.ListAddedAfter(ctx, ss.Query.request(), ss.LastBookID, 0); err != nil {
+		if errors.Is(err, book.ErrValidation) {
+			return fmt.Errorf("%w: %w", ErrInvalid, err)
+		}
+		return err
+	}
+	return s.repo.Create(ctx, ss)
+}
+
+// Delete removes the member's saved search with id
+func (s *Service) Delete(ctx context.Context, memberID string, id int) error {
+	return s.repo.Delete(ctx, memberID, id)
+}
@@ -0,0 +1,432 @@
+package serials
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"public_library/internal/httperr"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	svc    *Service
+	logger *zap.Logger
+}
+
+func NewHandler(svc *Service, l *zap.Logger) *Handler {
+	return &Handler{svc: svc, logger: l}
+}
+
+// GET /serials
+
+// ListSerials godoc
+// @Summary List serials
+// @Tags serials
+// @Produce json
+// @Success 200 {array} Serial
+// @Router /serials [get]
+func (h *Handler) ListSerials(w http.ResponseWriter, r *http.Request) {
+	list, err := h.svc.ListSerials(r.Context())
+	if err != nil {
+		h.writeError(w, "failed to list serials", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// GET /serials/{id}
+
+// GetSerial godoc
+// @Summary Get a serial with its subscriptions
+// @Tags serials
+// @Produce json
+// @Param id path int true "Serial ID"
+// @Success 200 {object} SerialResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /serials/{id} [get]
+func (h *Handler) GetSerial(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id", "invalid serial ID")
+	if !ok {
+		return
+	}
+	serial, err := h.svc.GetSerial(r.Context(), id)
+	if err != nil {
+		h.writeError(w, "failed to get serial", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(serial)
+}
+
+// POST /serials
+
+// CreateSerial godoc
+// @Summary Create a serial
+// @Tags serials
+// @Accept json
+// @Produce json
+// @Param serial body Serial true "Serial to create"
+// @Success 201 {object} Serial
+// @Failure 400 {object} map[string]string
+// @Router /serials [post]
+func (h *Handler) CreateSerial(w http.ResponseWriter, r *http.Request) {
+	var s Serial
+	if err := json.NewDecoder(r.Body).Decode(&s); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if err := h.svc.CreateSerial(r.Context(), &s); err != nil {
+		h.writeError(w, "failed to create serial", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(s)
+}
+
+// PUT /serials/{id}
+
+// UpdateSerial godoc
+// @Summary Update a serial
+// @Description Changing the frequency affects issues predicted from now on, not those already predicted.
+// @Tags serials
+// @Accept json
+// @Produce json
+// @Param id path int true "Serial ID"
+// @Param serial body Serial true "Updated serial"
+// @Success 200 {object} Serial
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /serials/{id} [put]
+func (h *Handler) UpdateSerial(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id", "invalid serial ID")
+	if !ok {
+		return
+	}
+	var s Serial
+	if err := json.NewDecoder(r.Body).Decode(&s); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	s.ID = id
+	if err := h.svc.UpdateSerial(r.Context(), &s); err != nil {
+		h.writeError(w, "failed to update serial", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s)
+}
+
+// DELETE /serials/{id}
+
+// DeleteSerial godoc
+// @Summary Delete a serial
+// @Description Deletes the serial with its subscriptions and issues
+// @Tags serials
+// @Param id path int true "Serial ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /serials/{id} [delete]
+func (h *Handler) DeleteSerial(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id", "invalid serial ID")
+	if !ok {
+		return
+	}
+	if err := h.svc.DeleteSerial(r.Context(), id); err != nil {
+		h.writeError(w, "failed to delete serial", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// POST /serials/{id}/subscriptions
+
+// CreateSubscription godoc
+// @Summary Subscribe to a serial
+// @Description Records a subscription for a period. The volume, number and expected date of its first issue, with the serial's frequency and issues_per_volume, are used to predict the issues that follow. copies defaults to 1 and claim_after_days to 30. cost, with its currency, is what the period cost. Recording the subscription for the next period stops the renewal reminders of the current one.
+// @Tags serials
+// @Accept json
+// @Produce json
+// @Param id path int true "Serial ID"
+// @Param subscription body Subscription true "Subscription"
+// @Success 201 {object} Subscription
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /serials/{id}/subscriptions [post]
+func (h *Handler) CreateSubscription(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id", "invalid serial ID")
+	if !ok {
+		return
+	}
+	var sub Subscription
+	if err := json.NewDecoder(r.Body).Decode(&sub); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	sub.SerialID = id
+	if err := h.svc.Subscribe(r.Context(), &sub); err != nil {
+		h.writeError(w, "failed to create subscription", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(sub)
+}
+
+// POST /serials/subscriptions/{id}/predict?through=2026-06-30
+
+// PredictIssues godoc
+// @Summary Predict the expected issues of a subscription
+// @Description Adds the issues expected from the first issue through the given date, or the end of the subscription, dated by the serial's frequency and numbered by volume. Issues predicted before are kept, so predicting again only extends the list.
+// @Tags serials
+// @Produce json
+// @Param id path int true "Subscription ID"
+// @Param through query string false "Last expected date, YYYY-MM-DD"
+// @Success 200 {object} PredictResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /serials/subscriptions/{id}/predict [post]
+func (h *Handler) PredictIssues(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id", "invalid subscription ID")
+	if !ok {
+		return
+	}
+	added, err := h.svc.Predict(r.Context(), id, r.URL.Query().Get("through"))
+	if err != nil {
+		h.writeError(w, "failed to predict issues", err)
+		return
+	}
+	issues, err := h.svc.ListIssues(r.Context(), id, "")
+	if err != nil {
+		h.writeError(w, "failed to list issues", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(PredictResponse{Added: added, Issues: issues})
+}
+
+// GET /serials/subscriptions/{id}/issues?status=expected
+
+// ListIssues godoc
+// @Summary List the issues of a subscription
+// @Tags serials
+// @Produce json
+// @Param id path int true "Subscription ID"
+// @Param status query string false "Only issues with this status" Enums(expected, received, claimed)
+// @Success 200 {array} Issue
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /serials/subscriptions/{id}/issues [get]
+func (h *Handler) ListIssues(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id", "invalid subscription ID")
+	if !ok {
+		return
+	}
+	issues, err := h.svc.ListIssues(r.Context(), id, r.URL.Query().Get("status"))
+	if err != nil {
+		h.writeError(w, "failed to list issues", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(issues)
+}
+
+// POST /serials/subscriptions/{id}/issues
+
+// CheckInUnexpectedIssue godoc
+// @Summary Check in an issue that was not predicted
+// @Description Records a special issue, supplement or other issue outside the predicted run as received.
+// @Tags serials
+// @Accept json
+// @Produce json
+// @Param id path int true "Subscription ID"
+// @Param issue body UnexpectedIssue true "Volume, number and receipt"
+// @Success 201 {object} Issue
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /serials/subscriptions/{id}/issues [post]
+func (h *Handler) CheckInUnexpectedIssue(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id", "invalid subscription ID")
+	if !ok {
+		return
+	}
+	var u UnexpectedIssue
+	if err := json.NewDecoder(r.Body).Decode(&u); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	issue, err := h.svc.CheckInUnexpected(r.Context(), id, u)
+	if err != nil {
+		h.writeError(w, "failed to check in issue", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(issue)
+}
+
+// POST /serials/issues/{id}/check-in
+
+// CheckInIssue godoc
+// @Summary Check in an expected issue
+// @Description Marks the issue received, on received_on (default today) with copies (default the subscription's copies). Claimed issues can be checked in too.
+// @Tags serials
+// @Accept json
+// @Produce json
+// @Param id path int true "Issue ID"
+// @Param checkin body CheckIn false "Receipt"
+// @Success 200 {object} Issue
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /serials/issues/{id}/check-in [post]
+func (h *Handler) CheckInIssue(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id", "invalid issue ID")
+	if !ok {
+		return
+	}
+	var c CheckIn
+	if err := json.NewDecoder(r.Body).Decode(&c); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	issue, err := h.svc.CheckIn(r.Context(), id, c)
+	if err != nil {
+		h.writeError(w, "failed to check in issue", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(issue)
+}
+
+// GET /serials/claims
+
+// ListDueClaims godoc
+// @Summary List the issues due for a claim
+// @Description Lists issues not received claim_after_days after their expected date, and claimed issues whose last claim was at least claim_after_days ago, with the vendor to claim from. Most overdue first.
+// @Tags serials
+// @Produce json
+// @Success 200 {array} Claim
+// @Router /serials/claims [get]
+func (h *Handler) ListDueClaims(w http.ResponseWriter, r *http.Request) {
+	claims, err := h.svc.DueClaims(r.Context())
+	if err != nil {
+		h.writeError(w, "failed to list claims", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(claims)
+}
+
+// POST /serials/issues/{id}/claim
+
+// ClaimIssue godoc
+// @Summary Record a claim of a missing issue
+// @Description Marks an overdue issue claimed from its vendor and counts the claim. The issue is due for another claim after claim_after_days.
+// @Tags serials
+// @Produce json
+// @Param id path int true "Issue ID"
+// @Success 200 {object} Claim
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /serials/issues/{id}/claim [post]
+func (h *Handler) ClaimIssue(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id", "invalid issue ID")
+	if !ok {
+		return
+	}
+	claim, err := h.svc.Claim(r.Context(), id)
+	if err != nil {
+		h.writeError(w, "failed to claim issue", err)
+		return
+	}
+	h.logger.Info("serial issue claimed", zap.Int("issue_id", id), zap.String("vendor", claim.Vendor),
+		zap.Int("claim_count", claim.ClaimCount))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(claim)
+}
+
+// GET /serials/{id}/costs
+
+// GetCostHistory godoc
+// @Summary Get the cost history of a serial
+// @Description Lists what each subscription period cost, oldest first, with the change from the period before when both are in the same currency.
+// @Tags serials
+// @Produce json
+// @Param id path int true "Serial ID"
+// @Success 200 {object} CostHistory
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /serials/{id}/costs [get]
+func (h *Handler) GetCostHistory(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id", "invalid serial ID")
+	if !ok {
+		return
+	}
+	history, err := h.svc.CostHistory(r.Context(), id)
+	if err != nil {
+		h.writeError(w, "failed to get cost history", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(history)
+}
+
+// GET /serials/renewals?days=60
+
+// ListDueRenewals godoc
+// @Summary List the subscriptions due for renewal
+// @Description Lists subscriptions ending within days (default the longest of serials.renewal_notice_days) that no later subscription of their serial follows, soonest first, with the latest reminder sent for each.
+// @Tags serials
+// @Produce json
+// @Param days query int false "Days ahead to look"
+// @Success 200 {array} Renewal
+// @Failure 400 {object} map[string]string
+// @Router /serials/renewals [get]
+func (h *Handler) ListDueRenewals(w http.ResponseWriter, r *http.Request) {
+	days := 0
+	if v := r.URL.Query().Get("days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			http.Error(w, "invalid days", http.StatusBadRequest)
+			return
+		}
+		days = n
+	}
+	renewals, err := h.svc.DueRenewals(r.Context(), days)
+	if err != nil {
+		h.writeError(w, "failed to list renewals", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(renewals)
+}
+
+func pathID(w http.ResponseWriter, r *http.Request, name, msg string) (int, bool) {
+	id, err := strconv.Atoi(mux.Vars(r)[name])
+	if err != nil {
+		http.Error(w, msg, http.StatusBadRequest)
+		return 0, false
+	}
+	return id, true
+}
+
+func (h *Handler) writeError(w http.ResponseWriter, msg string, err error) {
+	switch {
+	case errors.Is(err, ErrInvalid):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, ErrSerialNotFound), errors.Is(err, ErrSubscriptionNotFound), errors.Is(err, ErrIssueNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, ErrNotDue):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		httperr.Write(w, h.logger, msg, err)
+	}
+}
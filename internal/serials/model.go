@@ -0,0 +1,146 @@
+package serials
+
+import "time"
+
+// Issue statuses. An expected issue that is overdue can be claimed from the
+// vendor, more than once if it still doesn't arrive.
+const (
+	StatusExpected = "expected"
+	StatusReceived = "received"
+	StatusClaimed  = "claimed"
+)
+
+var statuses = []string{StatusExpected, StatusReceived, StatusClaimed}
+
+// Serial is a journal, magazine or newspaper: a title published in issues
+type Serial struct {
+	ID        int    `json:"id" example:"4"`
+	Title     string `json:"title" example:"National Geographic"`
+	ISSN      string `json:"issn,omitempty" example:"00279358"`
+	Publisher string `json:"publisher" example:"National Geographic Society"`
+	// Frequency is how often issues are published, which drives prediction
+	Frequency string `json:"frequency" example:"monthly" enums:"weekly,biweekly,semimonthly,monthly,bimonthly,quarterly,semiannual,annual"`
+}
+
+// SerialResponse is a serial with its subscriptions
+type SerialResponse struct {
+	Serial
+	Subscriptions []Subscription `json:"subscriptions"`
+}
+
+// Subscription is the library's order of a serial from a vendor for a
+// period. The first issue's numbering and date anchor the prediction of
+// the issues that follow.
+type Subscription struct {
+	ID       int    `json:"id" example:"7"`
+	SerialID int    `json:"serial_id" example:"4"`
+	Vendor   string `json:"vendor" example:"EBSCO"`
+	Copies   int    `json:"copies" example:"1"`
+	StartsOn string `json:"starts_on" example:"2026-01-01"`
+	EndsOn   string `json:"ends_on" example:"2026-12-31"`
+	// FirstVolume and FirstNumber number the first issue of the period
+	FirstVolume int `json:"first_volume" example:"249"`
+	FirstNumber int `json:"first_number" example:"1"`
+	// FirstIssueOn is when the first issue is expected
+	FirstIssueOn string `json:"first_issue_on" example:"2026-01-05"`
+	// IssuesPerVolume restarts numbering at 1 in a new volume after this many
+	// issues; 0 numbers issues straight through one volume
+	IssuesPerVolume int `json:"issues_per_volume" example:"12"`
+	// ClaimAfterDays is how long after its expected date an issue may be
+	// claimed, and how long to wait between claims
+	ClaimAfterDays int `json:"claim_after_days" example:"30"`
+	// Cost is what the period cost, a decimal amount in Currency
+	Cost      string    `json:"cost,omitempty" example:"39.00"`
+	Currency  string    `json:"currency,omitempty" example:"USD"` // ISO 4217
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Issue is one expected or received issue of a subscription
+type Issue struct {
+	ID             int `json:"id" example:"120"`
+	SubscriptionID int `json:"subscription_id" example:"7"`
+	// Sequence is the issue's place in the prediction, from 0; it is
+	// empty for issues checked in without being predicted
+	Sequence   *int   `json:"sequence,omitempty" example:"0"`
+	Volume     int    `json:"volume" example:"249"`
+	Number     int    `json:"number" example:"1"`
+	ExpectedOn string `json:"expected_on,omitempty" example:"2026-01-05"`
+	Status     string `json:"status" example:"expected" enums:"expected,received,claimed"`
+	ReceivedOn string `json:"received_on,omitempty" example:"2026-01-07"`
+	// CopiesReceived counts the copies checked in
+	CopiesReceived int    `json:"copies_received" example:"1"`
+	ClaimCount     int    `json:"claim_count" example:"0"`
+	LastClaimedOn  string `json:"last_claimed_on,omitempty" example:"2026-02-10"`
+	Note           string `json:"note,omitempty" example:"Special issue"`
+}
+
+// CheckIn records the arrival of an issue
+type CheckIn struct {
+	// ReceivedOn defaults to today
+	ReceivedOn string `json:"received_on" example:"2026-01-07"`
+	// Copies defaults to the subscription's copies
+	Copies int    `json:"copies" example:"1"`
+	Note   string `json:"note" example:""`
+}
+
+// UnexpectedIssue is an issue checked in that was not predicted, such as a
+// special issue or supplement
+type UnexpectedIssue struct {
+	Volume int `json:"volume" example:"249"`
+	Number int `json:"number" example:"13"`
+	CheckIn
+}
+
+// Claim is an overdue issue to claim from its vendor
+type Claim struct {
+	Issue
+	SerialID    int    `json:"serial_id" example:"4"`
+	SerialTitle string `json:"serial_title" example:"National Geographic"`
+	ISSN        string `json:"issn,omitempty" example:"00279358"`
+	Vendor      string `json:"vendor" example:"EBSCO"`
+	// DaysOverdue counts from the expected date
+	DaysOverdue int `json:"days_overdue" example:"41"`
+}
+
+// PredictResponse reports a prediction with the subscription's issues
+type PredictResponse struct {
+	// Added counts the issues the prediction added
+	Added  int     `json:"added" example:"12"`
+	Issues []Issue `json:"issues"`
+}
+
+// Renewal is a subscription ending soon that has not been renewed, i.e. no
+// later subscription of its serial has been recorded
+type Renewal struct {
+	Subscription
+	SerialTitle string `json:"serial_title" example:"National Geographic"`
+	ISSN        string `json:"issn,omitempty" example:"00279358"`
+	// DaysLeft counts the days until the subscription ends
+	DaysLeft int `json:"days_left" example:"28"`
+	// RemindedDaysBefore is the notice period of the latest reminder sent,
+	// and RemindedAt when it was sent; both are empty before the first
+	RemindedDaysBefore *int       `json:"reminded_days_before,omitempty" example:"30"`
+	RemindedAt         *time.Time `json:"reminded_at,omitempty"`
+}
+
+// CostPeriod is what one subscription period of a serial cost
+type CostPeriod struct {
+	SubscriptionID int    `json:"subscription_id" example:"7"`
+	Vendor         string `json:"vendor" example:"EBSCO"`
+	StartsOn       string `json:"starts_on" example:"2026-01-01"`
+	EndsOn         string `json:"ends_on" example:"2026-12-31"`
+	Cost           string `json:"cost,omitempty" example:"39.00"`
+	Currency       string `json:"currency,omitempty" example:"USD"`
+	// Change and ChangePercent compare the cost with the previous period's;
+	// they are empty when either cost is missing or the currencies differ
+	Change        string `json:"change,omitempty" example:"3.00"`
+	ChangePercent string `json:"change_percent,omitempty" example:"8.3"`
+}
+
+// CostHistory lists the cost of every subscription period of a serial,
+// oldest first
+type CostHistory struct {
+	SerialID int          `json:"serial_id" example:"4"`
+	Title    string       `json:"title" example:"National Geographic"`
+	Periods  []CostPeriod `json:"periods"`
+}
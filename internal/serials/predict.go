@@ -0,0 +1,94 @@
+package serials
+
+import "time"
+
+// Publication frequencies
+const (
+	FrequencyWeekly      = "weekly"
+	FrequencyBiweekly    = "biweekly"
+	FrequencySemimonthly = "semimonthly"
+	FrequencyMonthly     = "monthly"
+	FrequencyBimonthly   = "bimonthly"
+	FrequencyQuarterly   = "quarterly"
+	FrequencySemiannual  = "semiannual"
+	FrequencyAnnual      = "annual"
+)
+
+// frequencies gives each frequency's interval between issues in days or in
+// months; semimonthly issues come half a month apart
+var frequencies = map[string]struct{ days, months int }{
+	FrequencyWeekly:     {days: 7},
+	FrequencyBiweekly:   {days: 14},
+	FrequencyMonthly:    {months: 1},
+	FrequencyBimonthly:  {months: 2},
+	FrequencyQuarterly:  {months: 3},
+	FrequencySemiannual: {months: 6},
+	FrequencyAnnual:     {months: 12},
+	// no fixed interval: see issueDate
+	FrequencySemimonthly: {},
+}
+
+// maxPredicted bounds one prediction, e.g. 20 years of a weekly
+const maxPredicted = 1040
+
+// predict returns the issues of s published from its first issue through
+// the given date, numbered and dated from the first issue
+func predict(s *Subscription, frequency string, through time.Time) []Issue {
+	first, err := time.Parse(time.DateOnly, s.FirstIssueOn)
+	if err != nil {
+		return nil
+	}
+	var issues []Issue
+	for k := 0; k < maxPredicted; k++ {
+		date := issueDate(first, frequency, k)
+		if date.After(through) {
+			break
+		}
+		seq := k
+		volume, number := issueNumber(s, k)
+		issues = append(issues, Issue{
+			SubscriptionID: s.ID,
+			Sequence:       &seq,
+			Volume:         volume,
+			Number:         number,
+			ExpectedOn:     date.Format(time.DateOnly),
+			Status:         StatusExpected,
+		})
+	}
+	return issues
+}
+
+// issueDate is when the k-th issue after first (k = 0) is published
+func issueDate(first time.Time, frequency string, k int) time.Time {
+	f := frequencies[frequency]
+	switch {
+	case f.days > 0:
+		return first.AddDate(0, 0, f.days*k)
+	case f.months > 0:
+		return addMonths(first, f.months*k)
+	}
+	// semimonthly: the first issue's day and 15 days later, every month
+	date := addMonths(first, k/2)
+	if k%2 == 1 {
+		date = date.AddDate(0, 0, 15)
+	}
+	return date
+}
+
+// addMonths adds n months to t, keeping the day of the month where the
+// target month has it and using its last day where it doesn't
+func addMonths(t time.Time, n int) time.Time {
+	y, m, d := t.Date()
+	firstOfTarget := time.Date(y, m+time.Month(n), 1, 0, 0, 0, 0, t.Location())
+	lastDay := firstOfTarget.AddDate(0, 1, -1).Day()
+	return firstOfTarget.AddDate(0, 0, min(d, lastDay)-1)
+}
+
+// issueNumber is the volume and number of the k-th issue of s
+func issueNumber(s *Subscription, k int) (volume, number int) {
+	if s.IssuesPerVolume <= 0 {
+		return s.FirstVolume, s.FirstNumber + k
+	}
+	position := s.FirstNumber - 1 + k
+	return s.FirstVolume + position/s.IssuesPerVolume, position%s.IssuesPerVolume + 1
+}
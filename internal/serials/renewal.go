@@ -0,0 +1,152 @@
+package serials
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"public_library/internal/eventbus"
+	"slices"
+	"sort"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// noticeDays returns the configured notice periods, longest first
+func (s *Service) noticeDays() []int {
+	days := slices.Clone(s.cfg.RenewalNoticeDays)
+	sort.Sort(sort.Reverse(sort.IntSlice(days)))
+	return slices.Compact(days)
+}
+
+// DueRenewals returns the unrenewed subscriptions ending within days, or
+// within the longest notice period if days is 0, soonest first
+func (s *Service) DueRenewals(ctx context.Context, days int) ([]Renewal, error) {
+	if days < 0 {
+		return nil, fmt.Errorf("%w: days must not be negative", ErrInvalid)
+	}
+	if days == 0 {
+		if notice := s.noticeDays(); len(notice) > 0 {
+			days = notice[0]
+		}
+	}
+	return s.repo.DueRenewals(ctx, days)
+}
+
+// SendReminders publishes a reminder for every unrenewed subscription that
+// has entered a notice period it was not yet reminded for, and returns how
+// many were sent. Only the shortest period reached is reminded, so a
+// subscription recorded 20 days before it ends gets one reminder, not one
+// per longer period it skipped.
+func (s *Service) SendReminders(ctx context.Context) (int, error) {
+	notice := s.noticeDays()
+	if len(notice) == 0 {
+		return 0, nil
+	}
+	renewals, err := s.repo.DueRenewals(ctx, notice[0])
+	if err != nil {
+		return 0, err
+	}
+	sent := 0
+	for _, rn := range renewals {
+		period := -1
+		for _, days := range notice {
+			if rn.DaysLeft <= days {
+				period = days
+			}
+		}
+		if rn.RemindedDaysBefore != nil && *rn.RemindedDaysBefore <= period {
+			continue
+		}
+		recorded, err := s.repo.RecordReminder(ctx, rn.ID, period)
+		if err != nil {
+			return sent, err
+		}
+		if !recorded {
+			continue
+		}
+		now := time.Now().UTC()
+		rn.RemindedDaysBefore, rn.RemindedAt = &period, &now
+		s.bus.Publish(ctx, eventbus.SerialRenewalDue, rn.ID, rn)
+		sent++
+	}
+	return sent, nil
+}
+
+// StartReminders sends due renewal reminders at start and every
+// reminder_interval until ctx is cancelled. A non-positive interval
+// disables them.
+func (s *Service) StartReminders(ctx context.Context, logger *zap.Logger) {
+	if s.cfg.ReminderInterval <= 0 {
+		return
+	}
+	send := func() {
+		sent, err := s.SendReminders(ctx)
+		if err != nil {
+			logger.Error("Serial renewal reminders failed", zap.Error(err))
+			return
+		}
+		if sent > 0 {
+			logger.Info("Serial renewal reminders sent", zap.Int("count", sent))
+		}
+	}
+	go func() {
+		send()
+		ticker := time.NewTicker(s.cfg.ReminderInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				send()
+			}
+		}
+	}()
+}
+
+// CostHistory lists what each subscription period of a serial cost, oldest
+// first, with the change from the period before
+func (s *Service) CostHistory(ctx context.Context, serialID int) (*CostHistory, error) {
+	serial, err := s.repo.GetSerial(ctx, serialID)
+	if err != nil {
+		return nil, err
+	}
+	subscriptions, err := s.repo.ListSubscriptions(ctx, serialID)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(subscriptions, func(i, j int) bool {
+		return subscriptions[i].StartsOn < subscriptions[j].StartsOn
+	})
+
+	history := &CostHistory{SerialID: serial.ID, Title: serial.Title, Periods: []CostPeriod{}}
+	var previous *Subscription
+	for i := range subscriptions {
+		sub := &subscriptions[i]
+		period := CostPeriod{SubscriptionID: sub.ID, Vendor: sub.Vendor, StartsOn: sub.StartsOn, EndsOn: sub.EndsOn,
+			Cost: sub.Cost, Currency: sub.Currency}
+		if previous != nil && previous.Currency == sub.Currency {
+			period.Change, period.ChangePercent = costChange(previous.Cost, sub.Cost)
+		}
+		history.Periods = append(history.Periods, period)
+		previous = sub
+	}
+	return history, nil
+}
+
+// costChange returns the difference between two decimal costs and its
+// percentage of the first, or empty strings if either is missing
+func costChange(from, to string) (change, percent string) {
+	a, okA := new(big.Rat).SetString(from)
+	b, okB := new(big.Rat).SetString(to)
+	if !okA || !okB {
+		return "", ""
+	}
+	diff := new(big.Rat).Sub(b, a)
+	change = diff.FloatString(2)
+	if a.Sign() != 0 {
+		percent = new(big.Rat).Mul(new(big.Rat).Quo(diff, a), big.NewRat(100, 1)).FloatString(1)
+	}
+	return change, percent
+}
@@ -0,0 +1,431 @@
+package serials
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"public_library/internal/db"
+	"public_library/utils"
+)
+
+var (
+	ErrSerialNotFound       = errors.New("serial not found")
+	ErrSubscriptionNotFound = errors.New("subscription not found")
+	ErrIssueNotFound        = errors.New("issue not found")
+	// ErrNotDue is returned for a claim of an issue that was received or
+	// isn't overdue yet
+	ErrNotDue = errors.New("issue is not due for a claim")
+)
+
+var serialMapping = db.Mapping[Serial]{
+	Table:   utils.SerialsTable,
+	Columns: []string{"id", "title", "issn", "publisher", "frequency"},
+	Fields: func(s *Serial) []interface{} {
+		return []interface{}{&s.ID, &s.Title, &s.ISSN, &s.Publisher, &s.Frequency}
+	},
+	Writable: []string{"title", "issn", "publisher", "frequency"},
+	Values: func(s *Serial) []interface{} {
+		return []interface{}{s.Title, s.ISSN, s.Publisher, s.Frequency}
+	},
+	ID: func(s *Serial) int { return s.ID },
+}
+
+// Dates are selected as text so they read as YYYY-MM-DD
+var subscriptionMapping = db.Mapping[Subscription]{
+	Table: utils.SerialSubscriptionsTable,
+	Columns: []string{"id", "serial_id", "vendor", "copies", "starts_on::text", "ends_on::text",
+		"first_volume", "first_number", "first_issue_on::text", "issues_per_volume", "claim_after_days",
+		"COALESCE(cost::text, '')", "currency", "created_at"},
+	Fields: subscriptionFields,
+	Writable: []string{"serial_id", "vendor", "copies", "starts_on", "ends_on",
+		"first_volume", "first_number", "first_issue_on", "issues_per_volume", "claim_after_days", "cost", "currency"},
+	Values: func(s *Subscription) []interface{} {
+		// a subscription without a cost stores NULL rather than ''
+		var cost interface{}
+		if s.Cost != "" {
+			cost = s.Cost
+		}
+		return []interface{}{s.SerialID, s.Vendor, s.Copies, s.StartsOn, s.EndsOn,
+			s.FirstVolume, s.FirstNumber, s.FirstIssueOn, s.IssuesPerVolume, s.ClaimAfterDays, cost, s.Currency}
+	},
+	ID: func(s *Subscription) int { return s.ID },
+}
+
+func subscriptionFields(s *Subscription) []interface{} {
+	return []interface{}{&s.ID, &s.SerialID, &s.Vendor, &s.Copies, &s.StartsOn, &s.EndsOn,
+		&s.FirstVolume, &s.FirstNumber, &s.FirstIssueOn, &s.IssuesPerVolume, &s.ClaimAfterDays,
+		&s.Cost, &s.Currency, &s.CreatedAt}
+}
+
+// issueColumns are the columns issueFields reads; queries alias the issues
+// table as "i"
+const issueColumns = `i.id, i.subscription_id, i.sequence, i.volume, i.number, COALESCE(i.expected_on::text, ''),
+	i.status, COALESCE(i.received_on::text, ''), i.copies_received, i.claim_count,
+	COALESCE(i.last_claimed_on::text, ''), i.note`
+
+func issueFields(i *Issue) []interface{} {
+	return []interface{}{&i.ID, &i.SubscriptionID, &i.Sequence, &i.Volume, &i.Number, &i.ExpectedOn, &i.Status,
+		&i.ReceivedOn, &i.CopiesReceived, &i.ClaimCount, &i.LastClaimedOn, &i.Note}
+}
+
+type Repository struct {
+	db            *db.DB
+	serials       *db.Table[Serial]
+	subscriptions *db.Table[Subscription]
+}
+
+func NewRepository(conn *db.DB) *Repository {
+	return &Repository{
+		db:            conn,
+		serials:       db.NewTable(conn, serialMapping),
+		subscriptions: db.NewTable(conn, subscriptionMapping),
+	}
+}
+
+func (r *Repository) ListSerials(ctx context.Context) ([]Serial, error) {
+	list, err := r.serials.List(ctx, db.ListOptions{OrderBy: []string{"title", "id"}})
+	if err != nil {
+		log.Printf("Failed to list serials: %v", err)
+		return nil, err
+	}
+	return list, nil
+}
+
+func (r *Repository) GetSerial(ctx context.Context, id int) (*Serial, error) {
+	s, err := r.serials.Get(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrSerialNotFound
+		}
+		log.Printf("Failed to get serial id=%d: %v", id, err)
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *Repository) CreateSerial(ctx context.Context, s *Serial) error {
+	log.Println("<--------Create serial starts-------->")
+	defer log.Println("<--------Create serial ends-------->")
+
+	if err := r.serials.Insert(ctx, s); err != nil {
+		log.Printf("Failed to create serial %+v: %v", s, err)
+		return err
+	}
+	return nil
+}
+
+func (r *Repository) UpdateSerial(ctx context.Context, s *Serial) error {
+	log.Println("<--------Update serial starts-------->")
+	defer log.Println("<--------Update serial ends-------->")
+
+	if err := r.serials.Update(ctx, s); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrSerialNotFound
+		}
+		log.Printf("Failed to update serial id=%d: %v", s.ID, err)
+		return err
+	}
+	return nil
+}
+
+// DeleteSerial removes the serial with its subscriptions and issues
+func (r *Repository) DeleteSerial(ctx context.Context, id int) error {
+	log.Println("<--------Delete serial starts-------->")
+	defer log.Println("<--------Delete serial ends-------->")
+
+	if err := r.serials.Delete(ctx, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrSerialNotFound
+		}
+		log.Printf("Failed to delete serial id=%d: %v", id, err)
+		return err
+	}
+	return nil
+}
+
+// ListSubscriptions returns the subscriptions of a serial, latest first
+func (r *Repository) ListSubscriptions(ctx context.Context, serialID int) ([]Subscription, error) {
+	list, err := r.subscriptions.List(ctx, db.ListOptions{
+		Filters: []db.Filter{{Column: "serial_id", Op: "=", Value: serialID}},
+		OrderBy: []string{"id DESC"},
+	})
+	if err != nil {
+		log.Printf("Failed to list subscriptions of serial id=%d: %v", serialID, err)
+		return nil, err
+	}
+	return list, nil
+}
+
+func (r *Repository) GetSubscription(ctx context.Context, id int) (*Subscription, error) {
+	s, err := r.subscriptions.Get(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrSubscriptionNotFound
+		}
+		log.Printf("Failed to get subscription id=%d: %v", id, err)
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *Repository) CreateSubscription(ctx context.Context, s *Subscription) error {
+	log.Println("<--------Create subscription starts-------->")
+	defer log.Println("<--------Create subscription ends-------->")
+
+	if err := r.subscriptions.Insert(ctx, s); err != nil {
+		log.Printf("Failed to create subscription of serial id=%d: %v", s.SerialID, err)
+		return err
+	}
+	return nil
+}
+
+// AddPredicted stores predicted issues that aren't stored yet; issues are
+// identified by their subscription and sequence, so predicting again only
+// adds issues past the previous prediction
+func (r *Repository) AddPredicted(ctx context.Context, issues []Issue) (int, error) {
+	log.Println("<--------AddPredicted issues starts-------->")
+	defer log.Println("<--------AddPredicted issues ends-------->")
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	query := fmt.Sprintf(`
+		INSERT INTO %s (subscription_id, sequence, volume, number, expected_on, status)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (subscription_id, sequence) DO NOTHING
+	`, utils.SerialIssuesTable)
+	added := 0
+	for _, i := range issues {
+		result, err := tx.ExecContext(ctx, query, i.SubscriptionID, i.Sequence, i.Volume, i.Number, i.ExpectedOn, i.Status)
+		if err != nil {
+			log.Printf("Failed to store predicted issue %d.%d of subscription id=%d: %v", i.Volume, i.Number, i.SubscriptionID, err)
+			return 0, err
+		}
+		if n, err := result.RowsAffected(); err == nil {
+			added += int(n)
+		}
+	}
+	return added, tx.Commit()
+}
+
+// AddReceived stores an unpredicted issue as received
+func (r *Repository) AddReceived(ctx context.Context, i *Issue) error {
+	log.Println("<--------AddReceived issue starts-------->")
+	defer log.Println("<--------AddReceived issue ends-------->")
+
+	query := fmt.Sprintf(`
+		INSERT INTO %s AS i (subscription_id, volume, number, status, received_on, copies_received, note)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING %s
+	`, utils.SerialIssuesTable, issueColumns)
+	err := r.db.QueryRowContext(ctx, query, i.SubscriptionID, i.Volume, i.Number, StatusReceived, i.ReceivedOn,
+		i.CopiesReceived, i.Note).Scan(issueFields(i)...)
+	if err != nil {
+		log.Printf("Failed to check in issue %d.%d of subscription id=%d: %v", i.Volume, i.Number, i.SubscriptionID, err)
+		return err
+	}
+	return nil
+}
+
+// ListIssues returns the issues of a subscription by expected date,
+// optionally only those with status
+func (r *Repository) ListIssues(ctx context.Context, subscriptionID int, status string) ([]Issue, error) {
+	query := fmt.Sprintf(`
+		SELECT %s FROM %s i
+		WHERE i.subscription_id = $1 AND ($2 = '' OR i.status = $2)
+		ORDER BY COALESCE(i.expected_on, i.received_on), i.volume, i.number, i.id
+	`, issueColumns, utils.SerialIssuesTable)
+	rows, err := r.db.QueryContext(ctx, query, subscriptionID, status)
+	if err != nil {
+		log.Printf("Failed to list issues of subscription id=%d: %v", subscriptionID, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	issues := []Issue{}
+	for rows.Next() {
+		var i Issue
+		if err := rows.Scan(issueFields(&i)...); err != nil {
+			return nil, err
+		}
+		issues = append(issues, i)
+	}
+	return issues, rows.Err()
+}
+
+// CheckIn marks an issue received. Checking in an issue again replaces its
+// receipt date and copies.
+func (r *Repository) CheckIn(ctx context.Context, id int, c CheckIn) (*Issue, error) {
+	log.Println("<--------CheckIn issue starts-------->")
+	defer log.Println("<--------CheckIn issue ends-------->")
+
+	query := fmt.Sprintf(`
+		UPDATE %s i SET status = $2, received_on = $3, copies_received = $4, note = CASE WHEN $5 = '' THEN i.note ELSE $5 END
+		WHERE i.id = $1
+		RETURNING %s
+	`, utils.SerialIssuesTable, issueColumns)
+	var i Issue
+	err := r.db.QueryRowContext(ctx, query, id, StatusReceived, c.ReceivedOn, c.Copies, c.Note).Scan(issueFields(&i)...)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrIssueNotFound
+		}
+		log.Printf("Failed to check in issue id=%d: %v", id, err)
+		return nil, err
+	}
+	return &i, nil
+}
+
+// GetIssue returns an issue
+func (r *Repository) GetIssue(ctx context.Context, id int) (*Issue, error) {
+	query := fmt.Sprintf(`SELECT %s FROM %s i WHERE i.id = $1`, issueColumns, utils.SerialIssuesTable)
+	var i Issue
+	if err := r.db.QueryRowContext(ctx, query, id).Scan(issueFields(&i)...); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrIssueNotFound
+		}
+		log.Printf("Failed to get issue id=%d: %v", id, err)
+		return nil, err
+	}
+	return &i, nil
+}
+
+// dueClaimsSQL joins the issues due for a claim to their subscription and
+// serial: not received, and at least claim_after_days past their expected
+// date and their last claim
+const dueClaimsSQL = `
+	FROM %[1]s i
+	JOIN %[2]s sub ON sub.id = i.subscription_id
+	JOIN %[3]s s ON s.id = sub.serial_id
+	WHERE i.status <> '%[4]s'
+		AND i.expected_on + sub.claim_after_days <= CURRENT_DATE
+		AND (i.last_claimed_on IS NULL OR i.last_claimed_on + sub.claim_after_days <= CURRENT_DATE)
+`
+
+func claimFields(c *Claim) []interface{} {
+	return append(issueFields(&c.Issue), &c.SerialID, &c.SerialTitle, &c.ISSN, &c.Vendor, &c.DaysOverdue)
+}
+
+// DueClaims returns the issues due for a claim, most overdue first
+func (r *Repository) DueClaims(ctx context.Context) ([]Claim, error) {
+	query := fmt.Sprintf(`SELECT %s, s.id, s.title, s.issn, sub.vendor, CURRENT_DATE - i.expected_on`, issueColumns) +
+		fmt.Sprintf(dueClaimsSQL, utils.SerialIssuesTable, utils.SerialSubscriptionsTable, utils.SerialsTable, StatusReceived) +
+		`ORDER BY i.expected_on, i.id`
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		log.Printf("Failed to list due claims: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	claims := []Claim{}
+	for rows.Next() {
+		var c Claim
+		if err := rows.Scan(claimFields(&c)...); err != nil {
+			return nil, err
+		}
+		claims = append(claims, c)
+	}
+	return claims, rows.Err()
+}
+
+// Claim records a claim of an issue due for one. It returns ErrNotDue if
+// the issue was received or claimed too recently.
+func (r *Repository) Claim(ctx context.Context, id int) (*Claim, error) {
+	log.Println("<--------Claim issue starts-------->")
+	defer log.Println("<--------Claim issue ends-------->")
+
+	due := `SELECT i.id AS issue_id, s.id AS serial_id, s.title, s.issn, sub.vendor, CURRENT_DATE - i.expected_on AS days_overdue` +
+		fmt.Sprintf(dueClaimsSQL, utils.SerialIssuesTable, utils.SerialSubscriptionsTable, utils.SerialsTable, StatusReceived) +
+		`AND i.id = $1`
+	query := fmt.Sprintf(`
+		WITH due AS (%s)
+		UPDATE %s i SET status = $2, claim_count = i.claim_count + 1, last_claimed_on = CURRENT_DATE
+		FROM due
+		WHERE i.id = due.issue_id
+		RETURNING %s, due.serial_id, due.title, due.issn, due.vendor, due.days_overdue
+	`, due, utils.SerialIssuesTable, issueColumns)
+	var c Claim
+	err := r.db.QueryRowContext(ctx, query, id, StatusClaimed).Scan(claimFields(&c)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		if _, err := r.GetIssue(ctx, id); err != nil {
+			return nil, err
+		}
+		return nil, ErrNotDue
+	}
+	if err != nil {
+		log.Printf("Failed to claim issue id=%d: %v", id, err)
+		return nil, err
+	}
+	return &c, nil
+}
+
+// dueRenewalsSQL selects the subscriptions ending within $1 days that no
+// later subscription of their serial follows, with the latest reminder
+// sent for each; it is ordered by end date
+const dueRenewalsSQL = `
+	SELECT sub.id, sub.serial_id, sub.vendor, sub.copies, sub.starts_on::text, sub.ends_on::text,
+		sub.first_volume, sub.first_number, sub.first_issue_on::text, sub.issues_per_volume, sub.claim_after_days,
+		COALESCE(sub.cost::text, ''), sub.currency, sub.created_at,
+		s.title, s.issn, sub.ends_on - CURRENT_DATE, last.days_before, last.sent_at
+	FROM %[1]s sub
+	JOIN %[2]s s ON s.id = sub.serial_id
+	LEFT JOIN LATERAL (
+		SELECT days_before, sent_at FROM %[3]s r
+		WHERE r.subscription_id = sub.id
+		ORDER BY days_before
+		LIMIT 1
+	) last ON true
+	WHERE sub.ends_on >= CURRENT_DATE AND sub.ends_on - CURRENT_DATE <= $1
+		AND NOT EXISTS (
+			SELECT 1 FROM %[1]s next
+			WHERE next.serial_id = sub.serial_id AND next.starts_on > sub.starts_on
+		)
+	ORDER BY sub.ends_on, sub.id
+`
+
+// DueRenewals returns the unrenewed subscriptions ending within days,
+// soonest first
+func (r *Repository) DueRenewals(ctx context.Context, days int) ([]Renewal, error) {
+	query := fmt.Sprintf(dueRenewalsSQL, utils.SerialSubscriptionsTable, utils.SerialsTable, utils.SerialRemindersTable)
+	rows, err := r.db.QueryContext(ctx, query, days)
+	if err != nil {
+		log.Printf("Failed to list due renewals: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	renewals := []Renewal{}
+	for rows.Next() {
+		var rn Renewal
+		fields := append(subscriptionFields(&rn.Subscription), &rn.SerialTitle, &rn.ISSN, &rn.DaysLeft,
+			&rn.RemindedDaysBefore, &rn.RemindedAt)
+		if err := rows.Scan(fields...); err != nil {
+			return nil, err
+		}
+		renewals = append(renewals, rn)
+	}
+	return renewals, rows.Err()
+}
+
+// RecordReminder stores that the reminder for a subscription's notice
+// period was sent and reports whether it was new, so the reminder is sent
+// once even when several instances find it due
+func (r *Repository) RecordReminder(ctx context.Context, subscriptionID, daysBefore int) (bool, error) {
+	query := fmt.Sprintf(`
+		INSERT INTO %s (subscription_id, days_before) VALUES ($1, $2)
+		ON CONFLICT (subscription_id, days_before) DO NOTHING
+	`, utils.SerialRemindersTable)
+	result, err := r.db.ExecContext(ctx, query, subscriptionID, daysBefore)
+	if err != nil {
+		log.Printf("Failed to record renewal reminder of subscription id=%d: %v", subscriptionID, err)
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
@@ -0,0 +1,267 @@
+// Package serials manages journals, magazines and newspapers, which arrive
+// as a stream of issues rather than being catalogued once: subscriptions
+// predict the issues due, staff check them in as they arrive, and issues
+// that don't arrive are claimed from the vendor.
+package serials
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"public_library/internal/acquisition"
+	"public_library/internal/book"
+	"public_library/internal/config"
+	"public_library/internal/eventbus"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ErrInvalid is wrapped by errors about the content of a request
+var ErrInvalid = errors.New("invalid serials request")
+
+const (
+	maxTextLength         = 500
+	defaultClaimAfterDays = 30
+)
+
+type Service struct {
+	repo *Repository
+	cfg  config.SerialsConfig
+	bus  *eventbus.Bus
+}
+
+// NewService creates the serials service. Renewal reminders are published on
+// bus under eventbus.SerialRenewalDue.
+func NewService(repo *Repository, cfg config.SerialsConfig, bus *eventbus.Bus) *Service {
+	return &Service{repo: repo, cfg: cfg, bus: bus}
+}
+
+func (s *Service) ListSerials(ctx context.Context) ([]Serial, error) {
+	return s.repo.ListSerials(ctx)
+}
+
+// GetSerial returns the serial with its subscriptions
+func (s *Service) GetSerial(ctx context.Context, id int) (*SerialResponse, error) {
+	serial, err := s.repo.GetSerial(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	subscriptions, err := s.repo.ListSubscriptions(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return &SerialResponse{Serial: *serial, Subscriptions: subscriptions}, nil
+}
+
+func (s *Service) CreateSerial(ctx context.Context, serial *Serial) error {
+	if err := serial.validate(); err != nil {
+		return err
+	}
+	return s.repo.CreateSerial(ctx, serial)
+}
+
+func (s *Service) UpdateSerial(ctx context.Context, serial *Serial) error {
+	if err := serial.validate(); err != nil {
+		return err
+	}
+	return s.repo.UpdateSerial(ctx, serial)
+}
+
+func (s *Service) DeleteSerial(ctx context.Context, id int) error {
+	return s.repo.DeleteSerial(ctx, id)
+}
+
+func (serial *Serial) validate() error {
+	serial.Title = strings.TrimSpace(serial.Title)
+	if serial.Title == "" || utf8.RuneCountInString(serial.Title) > maxTextLength {
+		return fmt.Errorf("%w: title is required and must be at most %d characters", ErrInvalid, maxTextLength)
+	}
+	serial.Publisher = strings.TrimSpace(serial.Publisher)
+	if utf8.RuneCountInString(serial.Publisher) > maxTextLength {
+		return fmt.Errorf("%w: publisher must be at most %d characters", ErrInvalid, maxTextLength)
+	}
+	if strings.TrimSpace(serial.ISSN) != "" {
+		id, err := book.NormalizeIdentifier(book.IdentifierISSN, serial.ISSN)
+		if err != nil {
+			return fmt.Errorf("%w: %q is not a valid ISSN", ErrInvalid, serial.ISSN)
+		}
+		serial.ISSN = id.Value
+	} else {
+		serial.ISSN = ""
+	}
+	serial.Frequency = strings.ToLower(strings.TrimSpace(serial.Frequency))
+	if _, ok := frequencies[serial.Frequency]; !ok {
+		known := make([]string, 0, len(frequencies))
+		for f := range frequencies {
+			known = append(known, f)
+		}
+		sort.Strings(known)
+		return fmt.Errorf("%w: frequency must be one of %s", ErrInvalid, strings.Join(known, ", "))
+	}
+	return nil
+}
+
+// Subscribe validates sub and stores it as a subscription of its serial.
+// Issues are not predicted until Predict is called.
+func (s *Service) Subscribe(ctx context.Context, sub *Subscription) error {
+	if _, err := s.repo.GetSerial(ctx, sub.SerialID); err != nil {
+		return err
+	}
+	sub.Vendor = strings.TrimSpace(sub.Vendor)
+	if utf8.RuneCountInString(sub.Vendor) > maxTextLength {
+		return fmt.Errorf("%w: vendor must be at most %d characters", ErrInvalid, maxTextLength)
+	}
+	if sub.Copies == 0 {
+		sub.Copies = 1
+	}
+	if sub.Copies < 1 {
+		return fmt.Errorf("%w: copies must be positive", ErrInvalid)
+	}
+	dates := map[string]*string{"starts_on": &sub.StartsOn, "ends_on": &sub.EndsOn, "first_issue_on": &sub.FirstIssueOn}
+	parsed := make(map[string]time.Time)
+	for name, date := range dates {
+		t, err := time.Parse(time.DateOnly, *date)
+		if err != nil {
+			return fmt.Errorf("%w: %s must be a date (YYYY-MM-DD)", ErrInvalid, name)
+		}
+		parsed[name] = t
+	}
+	if parsed["ends_on"].Before(parsed["starts_on"]) {
+		return fmt.Errorf("%w: ends_on must not be before starts_on", ErrInvalid)
+	}
+	if parsed["first_issue_on"].Before(parsed["starts_on"]) || parsed["first_issue_on"].After(parsed["ends_on"]) {
+		return fmt.Errorf("%w: first_issue_on must be within the subscription", ErrInvalid)
+	}
+	if sub.FirstVolume < 0 || sub.FirstNumber < 1 {
+		return fmt.Errorf("%w: first_volume must not be negative and first_number must be positive", ErrInvalid)
+	}
+	if sub.IssuesPerVolume < 0 || (sub.IssuesPerVolume > 0 && sub.FirstNumber > sub.IssuesPerVolume) {
+		return fmt.Errorf("%w: issues_per_volume must be 0 or at least first_number", ErrInvalid)
+	}
+	if sub.ClaimAfterDays == 0 {
+		sub.ClaimAfterDays = defaultClaimAfterDays
+	}
+	if sub.ClaimAfterDays < 1 {
+		return fmt.Errorf("%w: claim_after_days must be positive", ErrInvalid)
+	}
+	sub.Cost = strings.TrimSpace(sub.Cost)
+	if sub.Cost != "" {
+		if !acquisition.ValidPrice(sub.Cost) {
+			return fmt.Errorf("%w: cost must be a non-negative decimal amount with at most four decimals", ErrInvalid)
+		}
+		currency, ok := acquisition.NormalizeCurrency(sub.Currency)
+		if !ok {
+			return fmt.Errorf("%w: currency must be an ISO 4217 code such as USD", ErrInvalid)
+		}
+		sub.Currency = currency
+	} else {
+		sub.Currency = ""
+	}
+	return s.repo.CreateSubscription(ctx, sub)
+}
+
+// Predict stores the issues of a subscription expected through the given
+// date (YYYY-MM-DD), or through its end if through is empty, and returns how
+// many were new. Issues already predicted are kept as they are.
+func (s *Service) Predict(ctx context.Context, subscriptionID int, through string) (int, error) {
+	sub, err := s.repo.GetSubscription(ctx, subscriptionID)
+	if err != nil {
+		return 0, err
+	}
+	serial, err := s.repo.GetSerial(ctx, sub.SerialID)
+	if err != nil {
+		return 0, err
+	}
+	end, err := time.Parse(time.DateOnly, sub.EndsOn)
+	if err != nil {
+		return 0, err
+	}
+	if through != "" {
+		t, err := time.Parse(time.DateOnly, through)
+		if err != nil {
+			return 0, fmt.Errorf("%w: through must be a date (YYYY-MM-DD)", ErrInvalid)
+		}
+		if t.Before(end) {
+			end = t
+		}
+	}
+	return s.repo.AddPredicted(ctx, predict(sub, serial.Frequency, end))
+}
+
+// ListIssues returns the issues of a subscription by date, optionally only
+// those with status
+func (s *Service) ListIssues(ctx context.Context, subscriptionID int, status string) ([]Issue, error) {
+	if status != "" && !slices.Contains(statuses, status) {
+		return nil, fmt.Errorf("%w: status must be one of %s", ErrInvalid, strings.Join(statuses, ", "))
+	}
+	if _, err := s.repo.GetSubscription(ctx, subscriptionID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListIssues(ctx, subscriptionID, status)
+}
+
+// CheckIn marks an issue received, with the subscription's copies unless
+// the check-in says otherwise
+func (s *Service) CheckIn(ctx context.Context, issueID int, c CheckIn) (*Issue, error) {
+	issue, err := s.repo.GetIssue(ctx, issueID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.completeCheckIn(ctx, issue.SubscriptionID, &c); err != nil {
+		return nil, err
+	}
+	return s.repo.CheckIn(ctx, issueID, c)
+}
+
+// CheckInUnexpected records an issue that arrived without being predicted
+func (s *Service) CheckInUnexpected(ctx context.Context, subscriptionID int, u UnexpectedIssue) (*Issue, error) {
+	if u.Volume < 0 || u.Number < 1 {
+		return nil, fmt.Errorf("%w: volume must not be negative and number must be positive", ErrInvalid)
+	}
+	if err := s.completeCheckIn(ctx, subscriptionID, &u.CheckIn); err != nil {
+		return nil, err
+	}
+	issue := &Issue{SubscriptionID: subscriptionID, Volume: u.Volume, Number: u.Number,
+		ReceivedOn: u.ReceivedOn, CopiesReceived: u.Copies, Note: u.Note}
+	return issue, s.repo.AddReceived(ctx, issue)
+}
+
+// completeCheckIn validates c and fills in its defaults from the
+// subscription
+func (s *Service) completeCheckIn(ctx context.Context, subscriptionID int, c *CheckIn) error {
+	if c.ReceivedOn == "" {
+		c.ReceivedOn = time.Now().Format(time.DateOnly)
+	}
+	if _, err := time.Parse(time.DateOnly, c.ReceivedOn); err != nil {
+		return fmt.Errorf("%w: received_on must be a date (YYYY-MM-DD)", ErrInvalid)
+	}
+	c.Note = strings.TrimSpace(c.Note)
+	if utf8.RuneCountInString(c.Note) > maxTextLength {
+		return fmt.Errorf("%w: note must be at most %d characters", ErrInvalid, maxTextLength)
+	}
+	if c.Copies < 0 {
+		return fmt.Errorf("%w: copies must not be negative", ErrInvalid)
+	}
+	sub, err := s.repo.GetSubscription(ctx, subscriptionID)
+	if err != nil {
+		return err
+	}
+	if c.Copies == 0 {
+		c.Copies = sub.Copies
+	}
+	return nil
+}
+
+// DueClaims returns the issues to claim from their vendors, most overdue
+// first
+func (s *Service) DueClaims(ctx context.Context) ([]Claim, error) {
+	return s.repo.DueClaims(ctx)
+}
+
+// Claim records that an overdue issue was claimed from its vendor
+func (s *Service) Claim(ctx context.Context, issueID int) (*Claim, error) {
+	return s.repo.Claim(ctx, issueID)
+}
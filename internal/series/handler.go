@@ -0,0 +1,186 @@
+package series
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"public_library/internal/book"
+	"public_library/internal/httperr"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	repo   *Repository
+	books  *book.Service
+	logger *zap.Logger
+}
+
+func NewHandler(r *Repository, books *book.Service, l *zap.Logger) *Handler {
+	return &Handler{repo: r, books: books, logger: l}
+}
+
+// GET /series
+
+// ListSeries godoc
+// @Summary List series
+// @Tags series
+// @Produce json
+// @Success 200 {array} Series
+// @Failure 500 {object} map[string]string
+// @Router /series [get]
+func (h *Handler) ListSeries(w http.ResponseWriter, r *http.Request) {
+	list, err := h.repo.List(r.Context())
+	if err != nil {
+		h.writeError(w, "failed to list series", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// GET /series/{id}
+
+// GetSeries godoc
+// @Summary Get a series with its volumes
+// @Description Returns the series and its books in reading order
+// @Tags series
+// @Produce json
+// @Param id path int true "Series ID"
+// @Success 200 {object} SeriesResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /series/{id} [get]
+func (h *Handler) GetSeries(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.pathID(w, r)
+	if !ok {
+		return
+	}
+
+	s, err := h.repo.GetByID(r.Context(), id)
+	if err != nil {
+		h.writeError(w, "error retrieving series", err)
+		return
+	}
+	volumes, err := h.books.ListBySeries(r.Context(), id)
+	if err != nil {
+		h.writeError(w, "error retrieving series volumes", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(SeriesResponse{Series: *s, Volumes: volumes})
+}
+
+// POST /series
+
+// CreateSeries godoc
+// @Summary Create a series
+// @Tags series
+// @Accept json
+// @Produce json
+// @Param series body Series true "Series to create"
+// @Success 201 {object} Series
+// @Failure 400 {object} map[string]string
+// @Router /series [post]
+func (h *Handler) CreateSeries(w http.ResponseWriter, r *http.Request) {
+	var s Series
+	if !decodeSeries(w, r, &s) {
+		return
+	}
+	if err := h.repo.Create(r.Context(), &s); err != nil {
+		h.writeError(w, "create series failed", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(s)
+}
+
+// PUT /series/{id}
+
+// UpdateSeries godoc
+// @Summary Update a series
+// @Tags series
+// @Accept json
+// @Produce json
+// @Param id path int true "Series ID"
+// @Param series body Series true "Updated series"
+// @Success 200 {object} Series
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /series/{id} [put]
+func (h *Handler) UpdateSeries(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.pathID(w, r)
+	if !ok {
+		return
+	}
+	var s Series
+	if !decodeSeries(w, r, &s) {
+		return
+	}
+	s.ID = id
+	if err := h.repo.Update(r.Context(), &s); err != nil {
+		h.writeError(w, "update series failed", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s)
+}
+
+// DELETE /series/{id}
+
+// DeleteSeries godoc
+// @Summary Delete a series
+// @Description Deletes the series; its books remain in the catalog without a series
+// @Tags series
+// @Param id path int true "Series ID"
+// @Success 204 "No Content"
+// @Failure 404 {object} map[string]string
+// @Router /series/{id} [delete]
+func (h *Handler) DeleteSeries(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.pathID(w, r)
+	if !ok {
+		return
+	}
+	if err := h.repo.Delete(r.Context(), id); err != nil {
+		h.writeError(w, "delete series failed", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) pathID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	idStr := mux.Vars(r)["id"]
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		h.logger.Warn("invalid series ID", zap.String("id", idStr))
+		http.Error(w, "invalid series ID", http.StatusBadRequest)
+		return 0, false
+	}
+	return id, true
+}
+
+func decodeSeries(w http.ResponseWriter, r *http.Request, s *Series) bool {
+	if err := json.NewDecoder(r.Body).Decode(s); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return false
+	}
+	s.Title = strings.TrimSpace(s.Title)
+	if s.Title == "" {
+		http.Error(w, "title is required", http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+func (h *Handler) writeError(w http.ResponseWriter, msg string, err error) {
+	if errors.Is(err, ErrNotFound) {
+		http.Error(w, "series not found", http.StatusNotFound)
+		return
+	}
+	httperr.Write(w, h.logger, msg, err)
+}
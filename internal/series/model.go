@@ -0,0 +1,16 @@
+package series
+
+import "public_library/internal/book"
+
+// Series groups titles that are meant to be read in order
+type Series struct {
+	ID          int    `json:"id" example:"3"`
+	Title       string `json:"title" example:"The Lord of the Rings"`
+	Description string `json:"description" example:"Tolkien's epic in three volumes"`
+}
+
+// SeriesResponse is a series with its volumes in reading order
+type SeriesResponse struct {
+	Series
+	Volumes []book.Book `json:"volumes"`
+}
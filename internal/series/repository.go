@@ -0,0 +1,110 @@
+package series
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"public_library/internal/db"
+	"public_library/internal/eventbus"
+	"public_library/utils"
+)
+
+var ErrNotFound = errors.New("series not found")
+
+var seriesMapping = db.Mapping[Series]{
+	Table:   utils.SeriesTable,
+	Columns: []string{"id", "title", "description"},
+	Fields: func(s *Series) []interface{} {
+		return []interface{}{&s.ID, &s.Title, &s.Description}
+	},
+	Writable: []string{"title", "description"},
+	Values: func(s *Series) []interface{} {
+		return []interface{}{s.Title, s.Description}
+	},
+	ID: func(s *Series) int { return s.ID },
+}
+
+type Repository struct {
+	table *db.Table[Series]
+	bus   *eventbus.Bus
+}
+
+func NewRepository(conn *db.DB, bus *eventbus.Bus) *Repository {
+	return &Repository{table: db.NewTable(conn, seriesMapping), bus: bus}
+}
+
+func (r *Repository) List(ctx context.Context) ([]Series, error) {
+	log.Println("<--------List series starts-------->")
+	defer log.Println("<--------List series ends-------->")
+
+	list, err := r.table.List(ctx, db.ListOptions{OrderBy: []string{"title", "id"}})
+	if err != nil {
+		log.Printf("Failed to list series: %v", err)
+		return nil, err
+	}
+	return list, nil
+}
+
+func (r *Repository) GetByID(ctx context.Context, id int) (*Series, error) {
+	log.Println("<--------GetByID series starts-------->")
+	defer log.Println("<--------GetByID series ends-------->")
+
+	s, err := r.table.Get(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Printf("Series with id=%d not found", id)
+			return nil, ErrNotFound
+		}
+		log.Printf("Failed to get series by id=%d: %v", id, err)
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *Repository) Create(ctx context.Context, s *Series) error {
+	log.Println("<--------Create series starts-------->")
+	defer log.Println("<--------Create series ends-------->")
+
+	if err := r.table.Insert(ctx, s); err != nil {
+		log.Printf("Failed to create series %+v: %v", s, err)
+		return err
+	}
+	r.bus.Publish(ctx, eventbus.SeriesCreated, s.ID, *s)
+	return nil
+}
+
+func (r *Repository) Update(ctx context.Context, s *Series) error {
+	log.Println("<--------Update series starts-------->")
+	defer log.Println("<--------Update series ends-------->")
+
+	if err := notFound(r.table.Update(ctx, s), "update", s.ID); err != nil {
+		return err
+	}
+	r.bus.Publish(ctx, eventbus.SeriesUpdated, s.ID, *s)
+	return nil
+}
+
+// Delete removes a series; its books stay in the catalog without a series
+func (r *Repository) Delete(ctx context.Context, id int) error {
+	log.Println("<--------Delete series starts-------->")
+	defer log.Println("<--------Delete series ends-------->")
+
+	if err := notFound(r.table.Delete(ctx, id), "delete", id); err != nil {
+		return err
+	}
+	r.bus.Publish(ctx, eventbus.SeriesDeleted, id, nil)
+	return nil
+}
+
+// notFound logs a failed write and maps a missing row to ErrNotFound
+func notFound(err error, op string, id int) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		log.Printf("No series found with id=%d", id)
+		return ErrNotFound
+	}
+	if err != nil {
+		log.Printf("Failed to %s series id=%d: %v", op, id, err)
+	}
+	return err
+}
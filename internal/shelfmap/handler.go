@@ -0,0 +1,221 @@
+package shelfmap
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"public_library/internal/book"
+	"public_library/internal/httperr"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	svc    *Service
+	logger *zap.Logger
+}
+
+func NewHandler(svc *Service, l *zap.Logger) *Handler {
+	return &Handler{svc: svc, logger: l}
+}
+
+// GET /shelf-ranges?branch=Central
+
+// ListShelfRanges godoc
+// @Summary List shelf ranges
+// @Description Lists the shelf ranges of a branch, or of every branch, in call number order.
+// @Tags shelf-map
+// @Produce json
+// @Param branch query string false "Branch"
+// @Success 200 {array} Range
+// @Router /shelf-ranges [get]
+func (h *Handler) ListShelfRanges(w http.ResponseWriter, r *http.Request) {
+	list, err := h.svc.List(r.Context(), r.URL.Query().Get("branch"))
+	if err != nil {
+		h.writeError(w, "failed to list shelf ranges", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// GET /shelf-ranges/{id}
+
+// GetShelfRange godoc
+// @Summary Get a shelf range
+// @Tags shelf-map
+// @Produce json
+// @Param id path int true "Shelf range ID"
+// @Success 200 {object} Range
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /shelf-ranges/{id} [get]
+func (h *Handler) GetShelfRange(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	sr, err := h.svc.Get(r.Context(), id)
+	if err != nil {
+		h.writeError(w, "failed to get shelf range", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sr)
+}
+
+// POST /shelf-ranges
+
+// CreateShelfRange godoc
+// @Summary Add a shelf range to the shelf map
+// @Description Records where a branch shelves the call numbers from first_call_number to last_call_number, optionally of one collection only.
+// @Tags shelf-map
+// @Accept json
+// @Produce json
+// @Param range body Range true "Shelf range to add"
+// @Success 201 {object} Range
+// @Failure 400 {object} map[string]string
+// @Router /shelf-ranges [post]
+func (h *Handler) CreateShelfRange(w http.ResponseWriter, r *http.Request) {
+	var sr Range
+	if err := json.NewDecoder(r.Body).Decode(&sr); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if err := h.svc.Create(r.Context(), &sr); err != nil {
+		h.writeError(w, "failed to create shelf range", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(sr)
+}
+
+// PUT /shelf-ranges/{id}
+
+// UpdateShelfRange godoc
+// @Summary Update a shelf range
+// @Tags shelf-map
+// @Accept json
+// @Produce json
+// @Param id path int true "Shelf range ID"
+// @Param range body Range true "Updated shelf range"
+// @Success 200 {object} Range
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /shelf-ranges/{id} [put]
+func (h *Handler) UpdateShelfRange(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	var sr Range
+	if err := json.NewDecoder(r.Body).Decode(&sr); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	sr.ID = id
+	if err := h.svc.Update(r.Context(), &sr); err != nil {
+		h.writeError(w, "failed to update shelf range", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sr)
+}
+
+// DELETE /shelf-ranges/{id}
+
+// DeleteShelfRange godoc
+// @Summary Remove a shelf range from the shelf map
+// @Tags shelf-map
+// @Param id path int true "Shelf range ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /shelf-ranges/{id} [delete]
+func (h *Handler) DeleteShelfRange(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	if err := h.svc.Delete(r.Context(), id); err != nil {
+		h.writeError(w, "failed to delete shelf range", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GET /shelf-map/locate?call_number=813.52%20FIT&branch=Central
+
+// LocateCallNumber godoc
+// @Summary Find where a call number is shelved
+// @Description Returns the floor, aisle and side a call number is shelved on at each branch with a shelf range for it, or at the given branch only. A range of the given collection is preferred over one shelving every collection.
+// @Tags shelf-map
+// @Produce json
+// @Param call_number query string true "Call number" example(813.52 FIT)
+// @Param branch query string false "Branch"
+// @Param collection query string false "Collection of the item"
+// @Success 200 {array} Location
+// @Failure 400 {object} map[string]string
+// @Router /shelf-map/locate [get]
+func (h *Handler) LocateCallNumber(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	list, err := h.svc.Locate(r.Context(), LocateRequest{CallNumber: q.Get("call_number"),
+		Branch: q.Get("branch"), Collection: q.Get("collection")})
+	if err != nil {
+		h.writeError(w, "failed to locate call number", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// GET /books/{id}/location?branch=Central
+
+// LocateBook godoc
+// @Summary Find where a book is shelved
+// @Description Locates the book's call number in its collection on the shelf map. Books without a call number have no location.
+// @Tags shelf-map
+// @Produce json
+// @Param id path int true "Book ID"
+// @Param branch query string false "Branch"
+// @Success 200 {array} Location
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /books/{id}/location [get]
+func (h *Handler) LocateBook(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "invalid book ID", http.StatusBadRequest)
+		return
+	}
+	list, err := h.svc.LocateBook(r.Context(), id, r.URL.Query().Get("branch"))
+	if err != nil {
+		h.writeError(w, "failed to locate book", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+func pathID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "invalid shelf range ID", http.StatusBadRequest)
+		return 0, false
+	}
+	return id, true
+}
+
+func (h *Handler) writeError(w http.ResponseWriter, msg string, err error) {
+	switch {
+	case errors.Is(err, ErrInvalid):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, ErrNotFound), errors.Is(err, book.ErrNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	default:
+		httperr.Write(w, h.logger, msg, err)
+	}
+}
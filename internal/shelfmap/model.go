@@ -0,0 +1,48 @@
+package shelfmap
+
+import "time"
+
+// Range is a run of shelving at a branch, such as one side of an aisle,
+// holding the call numbers from FirstCallNumber to LastCallNumber
+type Range struct {
+	ID     int    `json:"id" example:"1"`
+	Branch string `json:"branch" example:"Central"`
+	// Collection is empty for ranges that shelve every collection
+	Collection string `json:"collection,omitempty" example:"Adult Fiction"`
+	Floor      string `json:"floor" example:"2"`
+	Aisle      string `json:"aisle" example:"12"`
+	Side       string `json:"side,omitempty" example:"left" enums:"left,right"`
+	// Unit is the label on the shelving unit
+	Unit            string `json:"unit,omitempty" example:"2F-A12"`
+	FirstCallNumber string `json:"first_call_number" example:"800"`
+	// LastCallNumber is inclusive, and call numbers starting with it are in
+	// the range, so "813" covers "813.52 FIT"
+	LastCallNumber string    `json:"last_call_number" example:"813"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// Location is where a call number is shelved at one branch
+type Location struct {
+	CallNumber string `json:"call_number" example:"813.52 FIT"`
+	Branch     string `json:"branch" example:"Central"`
+	Collection string `json:"collection,omitempty" example:"Adult Fiction"`
+	Floor      string `json:"floor" example:"2"`
+	Aisle      string `json:"aisle" example:"12"`
+	Side       string `json:"side,omitempty" example:"left" enums:"left,right"`
+	Unit       string `json:"unit,omitempty" example:"2F-A12"`
+	RangeID    int    `json:"range_id" example:"1"`
+}
+
+// LocateRequest looks up where a call number is shelved; empty Branch and
+// Collection don't filter
+type LocateRequest struct {
+	CallNumber string
+	Branch     string
+	Collection string
+}
+
+// Range sides
+const (
+	SideLeft  = "left"
+	SideRight = "right"
+)
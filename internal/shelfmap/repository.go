@@ -0,0 +1,142 @@
+package shelfmap
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"public_library/internal/book"
+	"public_library/internal/db"
+	"public_library/utils"
+)
+
+// ErrNotFound is returned when no shelf range has the requested id
+var ErrNotFound = errors.New("shelf range not found")
+
+// first_sort and last_sort are written from the call numbers so ranges
+// compare in shelf order
+var rangeMapping = db.Mapping[Range]{
+	Table: utils.ShelfRangesTable,
+	Columns: []string{"id", "branch", "collection", "floor", "aisle", "side", "unit",
+		"first_call_number", "last_call_number", "created_at"},
+	Fields: func(sr *Range) []interface{} {
+		return []interface{}{&sr.ID, &sr.Branch, &sr.Collection, &sr.Floor, &sr.Aisle, &sr.Side, &sr.Unit,
+			&sr.FirstCallNumber, &sr.LastCallNumber, &sr.CreatedAt}
+	},
+	Writable: []string{"branch", "collection", "floor", "aisle", "side", "unit",
+		"first_call_number", "last_call_number", "first_sort", "last_sort"},
+	Values: func(sr *Range) []interface{} {
+		return []interface{}{sr.Branch, sr.Collection, sr.Floor, sr.Aisle, sr.Side, sr.Unit,
+			sr.FirstCallNumber, sr.LastCallNumber,
+			book.CallNumberSortKey(sr.FirstCallNumber), book.CallNumberSortKey(sr.LastCallNumber)}
+	},
+	ID: func(sr *Range) int { return sr.ID },
+}
+
+type Repository struct {
+	db     *db.DB
+	ranges *db.Table[Range]
+}
+
+func NewRepository(conn *db.DB) *Repository {
+	return &Repository{db: conn, ranges: db.NewTable(conn, rangeMapping)}
+}
+
+// List returns the ranges of branch, or of every branch if it is empty, in
+// shelf order
+func (r *Repository) List(ctx context.Context, branch string) ([]Range, error) {
+	opts := db.ListOptions{OrderBy: []string{"branch", "collection", "first_sort", "id"}}
+	if branch != "" {
+		opts.Filters = []db.Filter{{Column: "branch", Op: "=", Value: branch}}
+	}
+	list, err := r.ranges.List(ctx, opts)
+	if err != nil {
+		log.Printf("Failed to list shelf ranges: %v", err)
+		return nil, err
+	}
+	return list, nil
+}
+
+func (r *Repository) Get(ctx context.Context, id int) (*Range, error) {
+	sr, err := r.ranges.Get(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		log.Printf("Failed to get shelf range id=%d: %v", id, err)
+		return nil, err
+	}
+	return &sr, nil
+}
+
+func (r *Repository) Create(ctx context.Context, sr *Range) error {
+	log.Println("<--------Create shelf range starts-------->")
+	defer log.Println("<--------Create shelf range ends-------->")
+
+	if err := r.ranges.Insert(ctx, sr); err != nil {
+		log.Printf("Failed to create shelf range %+v: %v", sr, err)
+		return err
+	}
+	return nil
+}
+
+func (r *Repository) Update(ctx context.Context, sr *Range) error {
+	log.Println("<--------Update shelf range starts-------->")
+	defer log.Println("<--------Update shelf range ends-------->")
+
+	if err := r.ranges.Update(ctx, sr); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		log.Printf("Failed to update shelf range id=%d: %v", sr.ID, err)
+		return err
+	}
+	return nil
+}
+
+func (r *Repository) Delete(ctx context.Context, id int) error {
+	log.Println("<--------Delete shelf range starts-------->")
+	defer log.Println("<--------Delete shelf range ends-------->")
+
+	if err := r.ranges.Delete(ctx, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		log.Printf("Failed to delete shelf range id=%d: %v", id, err)
+		return err
+	}
+	return nil
+}
+
+// Locate returns, for each branch with a range holding the call number, the
+// range it is shelved in. A range of the requested collection wins over one
+// shelving every collection, and of overlapping ranges the one starting
+// closest to the call number wins.
+func (r *Repository) Locate(ctx context.Context, req LocateRequest) ([]Location, error) {
+	query := fmt.Sprintf(`
+		SELECT DISTINCT ON (branch) id, branch, collection, floor, aisle, side, unit
+		FROM %s
+		WHERE ($2 = '' OR branch = $2)
+			AND ($3 = '' OR collection IN ('', $3))
+			AND first_sort <= $1
+			AND (last_sort >= $1 OR starts_with($1, last_sort))
+		ORDER BY branch, collection <> '' DESC, first_sort DESC, id
+	`, utils.ShelfRangesTable)
+	rows, err := r.db.QueryContext(ctx, query, book.CallNumberSortKey(req.CallNumber), req.Branch, req.Collection)
+	if err != nil {
+		log.Printf("Failed to locate call number %q: %v", req.CallNumber, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []Location{}
+	for rows.Next() {
+		l := Location{CallNumber: req.CallNumber}
+		if err := rows.Scan(&l.RangeID, &l.Branch, &l.Collection, &l.Floor, &l.Aisle, &l.Side, &l.Unit); err != nil {
+			return nil, err
+		}
+		list = append(list, l)
+	}
+	return list, rows.Err()
+}
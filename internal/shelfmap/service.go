@@ -0,0 +1,103 @@
+// Package shelfmap maps the shelving of each branch: which floor, aisle and
+// side every range of call numbers is shelved on, so the catalog can show
+// patrons where to find an item.
+package shelfmap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"public_library/internal/book"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrInvalid is wrapped by errors about the content of a request
+var ErrInvalid = errors.New("invalid shelf range")
+
+const maxTextLength = 100
+
+type Service struct {
+	repo  *Repository
+	books *book.Service
+}
+
+func NewService(repo *Repository, books *book.Service) *Service {
+	return &Service{repo: repo, books: books}
+}
+
+// List returns the ranges of branch, or of every branch, in shelf order
+func (s *Service) List(ctx context.Context, branch string) ([]Range, error) {
+	return s.repo.List(ctx, strings.TrimSpace(branch))
+}
+
+func (s *Service) Get(ctx context.Context, id int) (*Range, error) {
+	return s.repo.Get(ctx, id)
+}
+
+func (s *Service) Create(ctx context.Context, sr *Range) error {
+	if err := sr.validate(); err != nil {
+		return err
+	}
+	return s.repo.Create(ctx, sr)
+}
+
+func (s *Service) Update(ctx context.Context, sr *Range) error {
+	if err := sr.validate(); err != nil {
+		return err
+	}
+	return s.repo.Update(ctx, sr)
+}
+
+func (s *Service) Delete(ctx context.Context, id int) error {
+	return s.repo.Delete(ctx, id)
+}
+
+// Locate returns where the call number is shelved at each branch, or at
+// req.Branch only
+func (s *Service) Locate(ctx context.Context, req LocateRequest) ([]Location, error) {
+	req.CallNumber = strings.TrimSpace(req.CallNumber)
+	req.Branch = strings.TrimSpace(req.Branch)
+	req.Collection = strings.TrimSpace(req.Collection)
+	if req.CallNumber == "" {
+		return nil, fmt.Errorf("%w: call_number is required", ErrInvalid)
+	}
+	return s.repo.Locate(ctx, req)
+}
+
+// LocateBook returns where the book with id is shelved, by its call number
+// and collection
+func (s *Service) LocateBook(ctx context.Context, id int, branch string) ([]Location, error) {
+	b, err := s.books.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if b.CallNumber == "" {
+		return []Location{}, nil
+	}
+	return s.Locate(ctx, LocateRequest{CallNumber: b.CallNumber, Branch: branch, Collection: b.Collection})
+}
+
+func (sr *Range) validate() error {
+	for _, field := range []*string{&sr.Branch, &sr.Collection, &sr.Floor, &sr.Aisle, &sr.Unit,
+		&sr.FirstCallNumber, &sr.LastCallNumber} {
+		*field = strings.TrimSpace(*field)
+		if utf8.RuneCountInString(*field) > maxTextLength {
+			return fmt.Errorf("%w: text fields must be at most %d characters", ErrInvalid, maxTextLength)
+		}
+	}
+	if sr.Branch == "" {
+		return fmt.Errorf("%w: branch is required", ErrInvalid)
+	}
+	sr.Side = strings.ToLower(strings.TrimSpace(sr.Side))
+	if sr.Side != "" && sr.Side != SideLeft && sr.Side != SideRight {
+		return fmt.Errorf("%w: side must be %q or %q", ErrInvalid, SideLeft, SideRight)
+	}
+	if sr.FirstCallNumber == "" || sr.LastCallNumber == "" {
+		return fmt.Errorf("%w: first_call_number and last_call_number are required", ErrInvalid)
+	}
+	if book.CallNumberSortKey(sr.FirstCallNumber) > book.CallNumberSortKey(sr.LastCallNumber) {
+		return fmt.Errorf("%w: first_call_number must not shelve after last_call_number", ErrInvalid)
+	}
+	return nil
+}
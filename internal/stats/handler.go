@@ -0,0 +1,210 @@
+package stats
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"public_library/internal/analytics"
+	"public_library/internal/book"
+	"public_library/internal/config"
+	"public_library/internal/httperr"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	defaultTopAuthors  = 10
+	defaultTopUsage    = 10
+	defaultUsageDays   = 30
+	maxUsageDays       = 366
+	defaultZeroResults = 100
+	maxZeroResults     = 1000
+)
+
+type Handler struct {
+	repo      *Repository
+	analytics *analytics.Repository
+	books     *book.Service
+	cfg       config.AnalyticsConfig
+	logger    *zap.Logger
+}
+
+func NewHandler(r *Repository, a *analytics.Repository, books *book.Service, cfg config.AnalyticsConfig, l *zap.Logger) *Handler {
+	return &Handler{repo: r, analytics: a, books: books, cfg: cfg, logger: l}
+}
+
+// GET /stats/books?top=10
+
+// GetBookStats godoc
+// @Summary Catalog statistics
+// @Description Returns catalog totals and the most represented authors. Figures come from materialized views and may be up to one refresh interval old.
+// @Tags stats
+// @Produce json
+// @Param top query int false "Number of top authors to return" default(10)
+// @Success 200 {object} BookStatsResponse
+// @Failure 500 {object} map[string]string
+// @Router /stats/books [get]
+func (h *Handler) GetBookStats(w http.ResponseWriter, r *http.Request) {
+	top := defaultTopAuthors
+	if v := r.URL.Query().Get("top"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			http.Error(w, "invalid top parameter", http.StatusBadRequest)
+			return
+		}
+		top = n
+	}
+
+	stats, err := h.repo.BookStats(r.Context(), top)
+	if err != nil {
+		httperr.Write(w, h.logger, "failed to get book stats", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stats)
+}
+
+// GET /stats/usage?from=2024-04-01&to=2024-04-30&top=10
+
+// GetUsageStats godoc
+// @Summary Anonymous catalog usage
+// @Description Returns how many searches were made and found nothing, the queries most often searched without results and the most viewed books between from and to (UTC days, inclusive), to guide collection development. Counts are kept per day with no member or client behind them, and leave out requests sent with DNT or Sec-GPC and members who set analytics_opt_out. Queries searched fewer than analytics.min_query_count times are not reported. Counts are flushed periodically, so the last minute or so may be missing.
+// @Tags stats
+// @Produce json
+// @Param from query string false "First day (YYYY-MM-DD); defaults to 29 days before to"
+// @Param to query string false "Last day (YYYY-MM-DD); defaults to today"
+// @Param top query int false "Number of top queries and books to return" default(10)
+// @Success 200 {object} analytics.Report
+// @Failure 400 {object} map[string]string
+// @Router /stats/usage [get]
+func (h *Handler) GetUsageStats(w http.ResponseWriter, r *http.Request) {
+	from, to, ok := reportPeriod(w, r)
+	if !ok {
+		return
+	}
+	top := defaultTopUsage
+	if v := r.URL.Query().Get("top"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			http.Error(w, "invalid top parameter", http.StatusBadRequest)
+			return
+		}
+		top = n
+	}
+
+	report, err := h.analytics.Report(r.Context(), from, to, top, h.cfg.MinQueryCount, book.AllowedAudiences(r.Context()))
+	if err != nil {
+		httperr.Write(w, h.logger, "failed to get usage stats", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// GET /admin/zero-results?from=2024-04-01&to=2024-04-30&min_searches=2&format=csv
+
+// GetZeroResults godoc
+// @Summary Searches that found nothing
+// @Description Lists the queries patrons searched without results between from and to (UTC days, inclusive), most searched first, for collection managers to see what is looked for but not held. Queries are lowercased with their whitespace collapsed, and counted like GET /stats/usage, but rare queries are listed too. catalog_matches shows how many books each query finds now. format=csv downloads the list as a file.
+// @Tags admin
+// @Produce json
+// @Produce text/csv
+// @Security AdminToken
+// @Param from query string false "First day (YYYY-MM-DD); defaults to 29 days before to"
+// @Param to query string false "Last day (YYYY-MM-DD); defaults to today"
+// @Param min_searches query int false "Leave out queries searched fewer times" default(1)
+// @Param limit query int false "Queries to return, at most 1000" default(100)
+// @Param format query string false "Output format" Enums(json, csv) default(json)
+// @Success 200 {object} analytics.ZeroResultLog
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /admin/zero-results [get]
+func (h *Handler) GetZeroResults(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	format := strings.ToLower(q.Get("format"))
+	if format != "" && format != "json" && format != "csv" {
+		http.Error(w, "format must be json or csv", http.StatusBadRequest)
+		return
+	}
+	from, to, ok := reportPeriod(w, r)
+	if !ok {
+		return
+	}
+	minSearches := 1
+	if v := q.Get("min_searches"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			http.Error(w, "invalid min_searches parameter", http.StatusBadRequest)
+			return
+		}
+		minSearches = n
+	}
+	limit := defaultZeroResults
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxZeroResults {
+			http.Error(w, "limit must be between 1 and 1000", http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+
+	zl, err := h.analytics.ZeroResults(r.Context(), from, to, minSearches, limit)
+	if err == nil {
+		err = h.countMatches(r.Context(), zl)
+	}
+	if err != nil {
+		httperr.Write(w, h.logger, "failed to get zero-result log", err)
+		return
+	}
+
+	if format == "csv" {
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="zero-results-%s-%s.csv"`, zl.From, zl.To))
+		if err := writeZeroResultsCSV(w, zl); err != nil {
+			h.logger.Warn("zero-result CSV interrupted", zap.Error(err))
+		}
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(zl)
+}
+
+// reportPeriod reads the from and to days of a report, defaulting to the
+// last defaultUsageDays days. It answers 400 and returns false when they
+// are invalid.
+func reportPeriod(w http.ResponseWriter, r *http.Request) (time.Time, time.Time, bool) {
+	q := r.URL.Query()
+
+	to := time.Now().UTC().Truncate(24 * time.Hour)
+	if v := q.Get("to"); v != "" {
+		t, err := time.Parse(time.DateOnly, v)
+		if err != nil {
+			http.Error(w, "invalid to parameter, expected YYYY-MM-DD", http.StatusBadRequest)
+			return to, to, false
+		}
+		to = t
+	}
+	from := to.AddDate(0, 0, -(defaultUsageDays - 1))
+	if v := q.Get("from"); v != "" {
+		t, err := time.Parse(time.DateOnly, v)
+		if err != nil {
+			http.Error(w, "invalid from parameter, expected YYYY-MM-DD", http.StatusBadRequest)
+			return from, to, false
+		}
+		from = t
+	}
+	if from.After(to) {
+		http.Error(w, "from must not be after to", http.StatusBadRequest)
+		return from, to, false
+	}
+	if to.Sub(from) >= maxUsageDays*24*time.Hour {
+		http.Error(w, "the report covers at most 366 days", http.StatusBadRequest)
+		return from, to, false
+	}
+	return from, to, true
+}
@@ -0,0 +1,17 @@
+package stats
+
+import "time"
+
+// AuthorCount is the number of catalog titles by one author
+type AuthorCount struct {
+	Author    string `json:"author" example:"Terry Pratchett"`
+	BookCount int64  `json:"book_count" example:"41"`
+}
+
+// BookStatsResponse is the catalog summary shown on the dashboard
+type BookStatsResponse struct {
+	TotalBooks   int64         `json:"total_books" example:"12034"`
+	TotalAuthors int64         `json:"total_authors" example:"5120"`
+	TopAuthors   []AuthorCount `json:"top_authors"`
+	RefreshedAt  time.Time     `json:"refreshed_at"`
+}
@@ -0,0 +1,30 @@
+package stats
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// StartRefresher refreshes the statistics views every interval until ctx is
+// cancelled. A non-positive interval disables scheduled refreshes.
+func StartRefresher(ctx context.Context, repo *Repository, interval time.Duration, logger *zap.Logger) {
+	if interval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := repo.Refresh(ctx); err != nil {
+					logger.Error("Stats refresh failed", zap.Error(err))
+				}
+			}
+		}
+	}()
+}
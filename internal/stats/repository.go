@@ -0,0 +1,75 @@
+package stats
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"public_library/internal/db"
+	"public_library/utils"
+)
+
+type Repository struct {
+	db *db.DB
+}
+
+func NewRepository(conn *db.DB) *Repository {
+	return &Repository{db: conn}
+}
+
+// BookStats reads the catalog summary from the materialized views
+func (r *Repository) BookStats(ctx context.Context, topN int) (*BookStatsResponse, error) {
+	log.Println("<--------BookStats starts-------->")
+	defer log.Println("<--------BookStats ends-------->")
+
+	var resp BookStatsResponse
+	summaryQuery := fmt.Sprintf(`SELECT total_books, total_authors, refreshed_at FROM %s`, utils.LibraryStatsView)
+	err := r.db.QueryRowContext(ctx, summaryQuery).Scan(&resp.TotalBooks, &resp.TotalAuthors, &resp.RefreshedAt)
+	if err != nil {
+		log.Printf("Failed to read library stats: %v", err)
+		return nil, err
+	}
+
+	authorsQuery := fmt.Sprintf(`
+		SELECT author, book_count
+		FROM %s
+		ORDER BY book_count DESC, author
+		LIMIT $1
+	`, utils.AuthorStatsView)
+	rows, err := r.db.QueryContext(ctx, authorsQuery, topN)
+	if err != nil {
+		log.Printf("Failed to read author stats: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	resp.TopAuthors = []AuthorCount{}
+	for rows.Next() {
+		var a AuthorCount
+		if err := rows.Scan(&a.Author, &a.BookCount); err != nil {
+			log.Printf("Failed to scan author stats row: %v", err)
+			return nil, err
+		}
+		resp.TopAuthors = append(resp.TopAuthors, a)
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("Row iteration error: %v", err)
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+// Refresh rebuilds every statistics view without blocking readers
+func (r *Repository) Refresh(ctx context.Context) error {
+	log.Println("<--------Refresh starts-------->")
+	defer log.Println("<--------Refresh ends-------->")
+
+	for _, view := range []string{utils.LibraryStatsView, utils.AuthorStatsView} {
+		query := fmt.Sprintf(`REFRESH MATERIALIZED VIEW CONCURRENTLY %s`, view)
+		if _, err := r.db.ExecContext(ctx, query); err != nil {
+			log.Printf("Failed to refresh %s: %v", view, err)
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package stats
+
+import (
+	"context"
+	"encoding/csv"
+	"io"
+	"public_library/internal/analytics"
+	"public_library/internal/book"
+	"strconv"
+)
+
+var zeroResultColumns = []string{"query", "searches", "days", "first_searched_on", "last_searched_on",
+	"catalog_matches"}
+
+// countMatches searches the catalog for each query of the log, so queries
+// that find books now can be told from those still unanswered
+func (h *Handler) countMatches(ctx context.Context, zl *analytics.ZeroResultLog) error {
+	for i := range zl.Queries {
+		q := &zl.Queries[i]
+		_, _, total, err := h.books.List(ctx, book.PaginationRequest{Search: q.Query, PageSize: 1})
+		if err != nil {
+			return err
+		}
+		q.CatalogMatches = total
+	}
+	return nil
+}
+
+func writeZeroResultsCSV(w io.Writer, zl *analytics.ZeroResultLog) error {
+	cw := csv.NewWriter(w)
+	cw.Write(zeroResultColumns)
+	for _, q := range zl.Queries {
+		cw.Write([]string{q.Query, strconv.FormatInt(q.Searches, 10), strconv.Itoa(q.Days), q.FirstSearchedOn,
+			q.LastSearchedOn, strconv.FormatInt(q.CatalogMatches, 10)})
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,215 @@
+package suggestions
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"public_library/internal/book"
+	"public_library/internal/circulation"
+	"public_library/internal/hooks"
+	"public_library/internal/httperr"
+	"public_library/internal/purchasing"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	svc    *Service
+	logger *zap.Logger
+}
+
+func NewHandler(svc *Service, l *zap.Logger) *Handler {
+	return &Handler{svc: svc, logger: l}
+}
+
+// POST /members/{id}/suggestions
+
+// SuggestPurchase godoc
+// @Summary Suggest a title for the library to buy
+// @Description Records the member's suggestion of a title the library doesn't have, for staff to triage. A member can have 10 suggestions awaiting triage. A title the catalog already has, by ISBN or a similar title by the same author, is matched to it instead: the suggestion is recorded as matched with the book, the open purchase order it is on if any, and a hold placed for the member at pickup_branch.
+// @Tags suggestions
+// @Accept json
+// @Produce json
+// @Param id path string true "Member ID"
+// @Param suggestion body SuggestionRequest true "Title to suggest"
+// @Success 201 {object} Suggestion
+// @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 422 {object} map[string]string
+// @Router /members/{id}/suggestions [post]
+func (h *Handler) SuggestPurchase(w http.ResponseWriter, r *http.Request) {
+	var req SuggestionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	sg, err := h.svc.Suggest(r.Context(), mux.Vars(r)["id"], req)
+	if err != nil {
+		h.writeError(w, "failed to record suggestion", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(sg)
+}
+
+// GET /members/{id}/suggestions
+
+// ListMemberSuggestions godoc
+// @Summary List a member's suggestions
+// @Description Lists the member's suggestions, most recent first, with whether they were accepted or, with the reason, rejected.
+// @Tags suggestions
+// @Produce json
+// @Param id path string true "Member ID"
+// @Success 200 {array} Suggestion
+// @Router /members/{id}/suggestions [get]
+func (h *Handler) ListMemberSuggestions(w http.ResponseWriter, r *http.Request) {
+	list, err := h.svc.ListByMember(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		h.writeError(w, "failed to list suggestions", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// GET /suggestions?status=pending
+
+// ListSuggestions godoc
+// @Summary List purchase suggestions for triage
+// @Description Lists the members' suggestions, oldest first, optionally only those with a status.
+// @Tags suggestions
+// @Produce json
+// @Param status query string false "Status" Enums(pending, accepted, rejected, matched)
+// @Success 200 {array} Suggestion
+// @Failure 400 {object} map[string]string
+// @Router /suggestions [get]
+func (h *Handler) ListSuggestions(w http.ResponseWriter, r *http.Request) {
+	list, err := h.svc.List(r.Context(), r.URL.Query().Get("status"))
+	if err != nil {
+		h.writeError(w, "failed to list suggestions", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// GET /suggestions/{id}
+
+// GetSuggestion godoc
+// @Summary Get a purchase suggestion
+// @Tags suggestions
+// @Produce json
+// @Param id path int true "Suggestion ID"
+// @Success 200 {object} Suggestion
+// @Failure 404 {object} map[string]string
+// @Router /suggestions/{id} [get]
+func (h *Handler) GetSuggestion(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	sg, err := h.svc.Get(r.Context(), id)
+	if err != nil {
+		h.writeError(w, "failed to get suggestion", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sg)
+}
+
+// POST /suggestions/{id}/accept
+
+// AcceptSuggestion godoc
+// @Summary Accept a suggestion by ordering the title
+// @Description Places a purchase order for the suggested title with the vendor and marks the suggestion accepted. The title is added to the catalog from the suggestion, with the ISBN given if the suggestion has none, unless book_id names the catalog record to order. The member is notified through suggestion.decided.
+// @Tags suggestions
+// @Accept json
+// @Produce json
+// @Param id path int true "Suggestion ID"
+// @Param order body AcceptRequest true "Order to place"
+// @Success 200 {object} Suggestion
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /suggestions/{id}/accept [post]
+func (h *Handler) AcceptSuggestion(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	var req AcceptRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	sg, err := h.svc.Accept(r.Context(), id, req)
+	if err != nil {
+		h.writeError(w, "failed to accept suggestion", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sg)
+}
+
+// POST /suggestions/{id}/reject
+
+// RejectSuggestion godoc
+// @Summary Reject a suggestion
+// @Description Marks the suggestion rejected with the reason, which the member sees. The member is notified through suggestion.decided.
+// @Tags suggestions
+// @Accept json
+// @Produce json
+// @Param id path int true "Suggestion ID"
+// @Param rejection body RejectRequest true "Reason"
+// @Success 200 {object} Suggestion
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /suggestions/{id}/reject [post]
+func (h *Handler) RejectSuggestion(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	var req RejectRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	sg, err := h.svc.Reject(r.Context(), id, req)
+	if err != nil {
+		h.writeError(w, "failed to reject suggestion", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sg)
+}
+
+func pathID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "invalid suggestion ID", http.StatusBadRequest)
+		return 0, false
+	}
+	return id, true
+}
+
+func (h *Handler) writeError(w http.ResponseWriter, msg string, err error) {
+	switch {
+	case errors.Is(err, ErrInvalid), errors.Is(err, purchasing.ErrInvalid), errors.Is(err, book.ErrValidation),
+		errors.Is(err, circulation.ErrInvalid):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, ErrNotFound), errors.Is(err, circulation.ErrMemberNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, ErrDecided), errors.Is(err, ErrInCatalog), errors.Is(err, ErrTooMany),
+		errors.Is(err, book.ErrDuplicate):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, hooks.ErrVetoed):
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+	default:
+		httperr.Write(w, h.logger, msg, err)
+	}
+}
@@ -0,0 +1,72 @@
+package suggestions
+
+import "time"
+
+// Suggestion statuses. Staff accept a pending suggestion by ordering the
+// title, or reject it with a reason. A suggestion of a title the catalog
+// already has, or has on order, is matched to it as it is made and never
+// waits for triage.
+const (
+	StatusPending  = "pending"
+	StatusAccepted = "accepted"
+	StatusRejected = "rejected"
+	StatusMatched  = "matched"
+)
+
+var statuses = []string{StatusPending, StatusAccepted, StatusRejected, StatusMatched}
+
+// Suggestion is a member's request that the library buy a title it doesn't
+// have
+type Suggestion struct {
+	ID       int    `json:"id" example:"15"`
+	MemberID string `json:"member_id" example:"m-1001"`
+	Title    string `json:"title" example:"Tomorrow, and Tomorrow, and Tomorrow"`
+	Author   string `json:"author" example:"Gabrielle Zevin"`
+	ISBN     string `json:"isbn,omitempty" example:"9780593321201"`
+	// Note is the member's reason for suggesting the title
+	Note   string `json:"note,omitempty" example:"My book club is reading it in March."`
+	Status string `json:"status" example:"pending" enums:"pending,accepted,rejected,matched"`
+	// Reason explains a rejection to the member
+	Reason string `json:"reason,omitempty" example:"Out of print"`
+	// BookID and OrderID are the catalog record and the purchase order of
+	// an accepted suggestion. A matched suggestion has the catalog record it
+	// matched, the open order the record is on if any, and HoldID, the hold
+	// placed for the member on it unless they couldn't have one.
+	BookID    *int       `json:"book_id,omitempty" example:"230"`
+	OrderID   *int       `json:"order_id,omitempty" example:"12"`
+	HoldID    *int       `json:"hold_id,omitempty" example:"88"`
+	CreatedAt time.Time  `json:"created_at"`
+	DecidedAt *time.Time `json:"decided_at,omitempty"`
+}
+
+// SuggestionRequest suggests a title for purchase
+type SuggestionRequest struct {
+	Title  string `json:"title" example:"Tomorrow, and Tomorrow, and Tomorrow"`
+	Author string `json:"author" example:"Gabrielle Zevin"`
+	ISBN   string `json:"isbn" example:"9780593321201"`
+	Note   string `json:"note" example:"My book club is reading it in March."`
+	// PickupBranch is where to pick up the hold placed if the title is
+	// already in the catalog
+	PickupBranch string `json:"pickup_branch" example:"Central"`
+}
+
+// AcceptRequest accepts a suggestion by ordering the title from a vendor.
+// The title is added to the catalog from the suggestion, unless book_id
+// names a catalog record to order instead.
+type AcceptRequest struct {
+	BookID *int `json:"book_id"`
+	// ISBN is ordered by; it is required when the suggestion has none
+	ISBN          string `json:"isbn" example:"9780593321201"`
+	Vendor        string `json:"vendor" example:"Gardners Books"`
+	VendorID      string `json:"vendor_id" example:"5013546000008"`
+	Currency      string `json:"currency" example:"GBP"`
+	FundingSource string `json:"funding_source" example:"Book fund 2026"`
+	// Quantity defaults to 1
+	Quantity int    `json:"quantity" example:"2"`
+	Price    string `json:"price" example:"18.99"`
+}
+
+// RejectRequest rejects a suggestion
+type RejectRequest struct {
+	Reason string `json:"reason" example:"Out of print"`
+}
@@ -0,0 +1,240 @@
+package suggestions
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"public_library/internal/db"
+	"public_library/utils"
+	"strings"
+)
+
+var (
+	// ErrNotFound is returned when no suggestion has the requested id
+	ErrNotFound = errors.New("suggestion not found")
+	// ErrDecided is returned when a suggestion already accepted or rejected
+	// is triaged again
+	ErrDecided = errors.New("suggestion already decided")
+)
+
+type Repository struct {
+	db *db.DB
+}
+
+func NewRepository(conn *db.DB) *Repository {
+	return &Repository{db: conn}
+}
+
+const selectSuggestionsSQL = `
+	SELECT id, member_id, title, author, isbn, note, status, reason, book_id, order_id, hold_id, created_at,
+		decided_at
+	FROM %s
+`
+
+// returningSQL returns a written suggestion as scanSuggestion reads it
+const returningSQL = `id, member_id, title, author, isbn, note, status, reason, book_id, order_id, hold_id, created_at,
+	decided_at`
+
+func selectSuggestions(where string) string {
+	return fmt.Sprintf(selectSuggestionsSQL, utils.SuggestionsTable) + where
+}
+
+func scanSuggestion(row interface{ Scan(...interface{}) error }) (Suggestion, error) {
+	var s Suggestion
+	err := row.Scan(&s.ID, &s.MemberID, &s.Title, &s.Author, &s.ISBN, &s.Note, &s.Status, &s.Reason, &s.BookID,
+		&s.OrderID, &s.HoldID, &s.CreatedAt, &s.DecidedAt)
+	return s, err
+}
+
+func (r *Repository) query(ctx context.Context, query string, args ...interface{}) ([]Suggestion, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []Suggestion{}
+	for rows.Next() {
+		s, err := scanSuggestion(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, s)
+	}
+	return list, rows.Err()
+}
+
+func (r *Repository) Create(ctx context.Context, memberID string, req SuggestionRequest) (*Suggestion, error) {
+	log.Println("<--------Create suggestion starts-------->")
+	defer log.Println("<--------Create suggestion ends-------->")
+
+	query := fmt.Sprintf(`
+		INSERT INTO %s (member_id, title, author, isbn, note) VALUES ($1, $2, $3, $4, $5)
+		RETURNING %s
+	`, utils.SuggestionsTable, returningSQL)
+	s, err := scanSuggestion(r.db.QueryRowContext(ctx, query, memberID, req.Title, req.Author, req.ISBN, req.Note))
+	if err != nil {
+		log.Printf("Failed to create suggestion %+v by member id=%s: %v", req, memberID, err)
+		return nil, err
+	}
+	return &s, nil
+}
+
+// CreateMatched records the member's suggestion as matched to the catalog
+// book, with the open order it is on and the hold placed for the member
+func (r *Repository) CreateMatched(ctx context.Context, memberID string, req SuggestionRequest, bookID int,
+	orderID, holdID *int) (*Suggestion, error) {
+	log.Println("<--------Create matched suggestion starts-------->")
+	defer log.Println("<--------Create matched suggestion ends-------->")
+
+	query := fmt.Sprintf(`
+		INSERT INTO %s (member_id, title, author, isbn, note, status, book_id, order_id, hold_id, decided_at)
+		VALUES ($1, $2, $3, $4, $5, '%s', $6, $7, $8, now())
+		RETURNING %s
+	`, utils.SuggestionsTable, StatusMatched, returningSQL)
+	s, err := scanSuggestion(r.db.QueryRowContext(ctx, query, memberID, req.Title, req.Author, req.ISBN, req.Note,
+		bookID, orderID, holdID))
+	if err != nil {
+		log.Printf("Failed to create matched suggestion %+v by member id=%s: %v", req, memberID, err)
+		return nil, err
+	}
+	return &s, nil
+}
+
+// MatchTitle returns the IDs of up to two catalog books whose title is
+// similar to title, by pg_trgm similarity, and whose author contains
+// authorSurname, most similar first. With no surname the title must match
+// exactly, ignoring case.
+func (r *Repository) MatchTitle(ctx context.Context, title, authorSurname string) ([]int, error) {
+	query := fmt.Sprintf(`
+		SELECT id FROM %s
+		WHERE CASE WHEN $2 = '' THEN lower(title) = lower($1)
+			ELSE similarity(lower(title), lower($1)) >= $3 AND author ILIKE '%%' || $2 || '%%' END
+		ORDER BY similarity(lower(title), lower($1)) DESC, id
+		LIMIT 2
+	`, utils.BooksTable)
+	pattern := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(authorSurname)
+	rows, err := r.db.QueryContext(ctx, query, title, pattern, minTitleSimilarity)
+	if err != nil {
+		log.Printf("Failed to match title %q: %v", title, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// OpenOrder returns the most recent purchase order with copies of the book
+// still to be invoiced, or nil if it is on none
+func (r *Repository) OpenOrder(ctx context.Context, bookID int) (*int, error) {
+	var id int
+	query := fmt.Sprintf(`
+		SELECT o.id FROM %s o JOIN %s l ON l.order_id = o.id
+		WHERE l.book_id = $1 AND l.invoiced_quantity < l.quantity AND o.status <> 'invoiced'
+		ORDER BY o.created_at DESC, o.id DESC
+		LIMIT 1
+	`, utils.PurchaseOrdersTable, utils.PurchaseOrderLinesTable)
+	err := r.db.QueryRowContext(ctx, query, bookID).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		log.Printf("Failed to find open order of book id=%d: %v", bookID, err)
+		return nil, err
+	}
+	return &id, nil
+}
+
+func (r *Repository) GetByID(ctx context.Context, id int) (*Suggestion, error) {
+	s, err := scanSuggestion(r.db.QueryRowContext(ctx, selectSuggestions(`WHERE id = $1`), id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		log.Printf("Failed to get suggestion id=%d: %v", id, err)
+		return nil, err
+	}
+	return &s, nil
+}
+
+// List returns the suggestions with status, or all of them, oldest first so
+// staff triage them in the order they came in
+func (r *Repository) List(ctx context.Context, status string) ([]Suggestion, error) {
+	list, err := r.query(ctx, selectSuggestions(`
+		WHERE $1 = '' OR status = $1
+		ORDER BY created_at, id
+	`), status)
+	if err != nil {
+		log.Printf("Failed to list suggestions: %v", err)
+		return nil, err
+	}
+	return list, nil
+}
+
+// ListByMember returns the member's suggestions, most recent first
+func (r *Repository) ListByMember(ctx context.Context, memberID string) ([]Suggestion, error) {
+	list, err := r.query(ctx, selectSuggestions(`
+		WHERE member_id = $1
+		ORDER BY created_at DESC, id DESC
+	`), memberID)
+	if err != nil {
+		log.Printf("Failed to list suggestions of member id=%s: %v", memberID, err)
+		return nil, err
+	}
+	return list, nil
+}
+
+// CountPending counts the member's suggestions awaiting triage
+func (r *Repository) CountPending(ctx context.Context, memberID string) (int, error) {
+	var n int
+	query := fmt.Sprintf(`SELECT count(*) FROM %s WHERE member_id = $1 AND status = $2`, utils.SuggestionsTable)
+	if err := r.db.QueryRowContext(ctx, query, memberID, StatusPending).Scan(&n); err != nil {
+		log.Printf("Failed to count pending suggestions of member id=%s: %v", memberID, err)
+		return 0, err
+	}
+	return n, nil
+}
+
+// Accept marks the pending suggestion accepted with the book and order
+// bought for it
+func (r *Repository) Accept(ctx context.Context, id, bookID, orderID int) (*Suggestion, error) {
+	return r.decide(ctx, id, `status = 'accepted', book_id = $2, order_id = $3`, bookID, orderID)
+}
+
+// Reject marks the pending suggestion rejected for reason
+func (r *Repository) Reject(ctx context.Context, id int, reason string) (*Suggestion, error) {
+	return r.decide(ctx, id, `status = 'rejected', reason = $2`, reason)
+}
+
+func (r *Repository) decide(ctx context.Context, id int, set string, args ...interface{}) (*Suggestion, error) {
+	log.Println("<--------Decide suggestion starts-------->")
+	defer log.Println("<--------Decide suggestion ends-------->")
+
+	query := fmt.Sprintf(`
+		UPDATE %s SET %s, decided_at = now()
+		WHERE id = $1 AND status = 'pending'
+		RETURNING %s
+	`, utils.SuggestionsTable, set, returningSQL)
+	s, err := scanSuggestion(r.db.QueryRowContext(ctx, query, append([]interface{}{id}, args...)...))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			if _, err := r.GetByID(ctx, id); err != nil {
+				return nil, err
+			}
+			return nil, ErrDecided
+		}
+		log.Printf("Failed to decide suggestion id=%d: %v", id, err)
+		return nil, err
+	}
+	return &s, nil
+}
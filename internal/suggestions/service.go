@@ -0,0 +1,264 @@
+// Package suggestions is the suggestion box: members suggest titles the
+// library doesn't have, and staff triage them, accepting one by ordering the
+// title from a vendor or rejecting it with a reason. A suggestion of a title
+// the catalog already has, by ISBN or a similar title by the same author,
+// is matched to it instead: the member gets a hold on it, and it is never
+// ordered twice. Each outcome is published on the event bus for
+// notifications to tell the member.
+package suggestions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"public_library/internal/book"
+	"public_library/internal/circulation"
+	"public_library/internal/eventbus"
+	"public_library/internal/purchasing"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	// ErrInvalid is wrapped by errors about the content of a request
+	ErrInvalid = errors.New("invalid suggestion")
+	// ErrInCatalog is wrapped when an ISBN given to order a suggestion is
+	// already in the catalog
+	ErrInCatalog = errors.New("title already in the catalog")
+	// ErrTooMany is returned when a member already has maxPending
+	// suggestions awaiting triage
+	ErrTooMany = errors.New("too many suggestions awaiting triage")
+)
+
+const (
+	maxTextLength = 200
+	maxNoteLength = 1000
+	// maxPending caps the suggestions a member can have awaiting triage
+	maxPending = 10
+	// minTitleSimilarity is the pg_trgm similarity from which a suggested
+	// title by the same author matches a catalog title
+	minTitleSimilarity = 0.6
+)
+
+type Service struct {
+	repo     *Repository
+	books    *book.Service
+	purchase *purchasing.Service
+	holds    *circulation.Service
+	bus      *eventbus.Bus
+}
+
+// NewService creates the suggestion box. Suggestions accepted, rejected or
+// matched are published on bus under eventbus.SuggestionDecided.
+func NewService(repo *Repository, books *book.Service, purchase *purchasing.Service, holds *circulation.Service,
+	bus *eventbus.Bus) *Service {
+	return &Service{repo: repo, books: books, purchase: purchase, holds: holds, bus: bus}
+}
+
+// Suggest validates req and records the member's suggestion. A title the
+// catalog has is matched to its record, and to the open order it is on if
+// any, and a hold is placed on it for the member; members who can't hold
+// it, e.g. because they already do, just get the match.
+func (s *Service) Suggest(ctx context.Context, memberID string, req SuggestionRequest) (*Suggestion, error) {
+	req.Title = strings.TrimSpace(req.Title)
+	if req.Title == "" || utf8.RuneCountInString(req.Title) > maxTextLength {
+		return nil, fmt.Errorf("%w: title is required and must be at most %d characters", ErrInvalid, maxTextLength)
+	}
+	req.Author = strings.TrimSpace(req.Author)
+	if utf8.RuneCountInString(req.Author) > maxTextLength {
+		return nil, fmt.Errorf("%w: author must be at most %d characters", ErrInvalid, maxTextLength)
+	}
+	req.Note = strings.TrimSpace(req.Note)
+	if utf8.RuneCountInString(req.Note) > maxNoteLength {
+		return nil, fmt.Errorf("%w: note must be at most %d characters", ErrInvalid, maxNoteLength)
+	}
+	req.PickupBranch = strings.TrimSpace(req.PickupBranch)
+	if strings.TrimSpace(req.ISBN) != "" {
+		id, err := normalizeISBN(req.ISBN)
+		if err != nil {
+			return nil, err
+		}
+		req.ISBN = id.Value
+	}
+	if bookID, err := s.match(ctx, req); err != nil {
+		return nil, err
+	} else if bookID != 0 {
+		return s.matched(ctx, memberID, req, bookID)
+	}
+	if n, err := s.repo.CountPending(ctx, memberID); err != nil {
+		return nil, err
+	} else if n >= maxPending {
+		return nil, ErrTooMany
+	}
+	return s.repo.Create(ctx, memberID, req)
+}
+
+// ListByMember returns the member's suggestions and what became of them
+func (s *Service) ListByMember(ctx context.Context, memberID string) ([]Suggestion, error) {
+	return s.repo.ListByMember(ctx, memberID)
+}
+
+// List returns the suggestions with status, or all of them, oldest first
+func (s *Service) List(ctx context.Context, status string) ([]Suggestion, error) {
+	if status != "" && !slices.Contains(statuses, status) {
+		return nil, fmt.Errorf("%w: status must be one of %s", ErrInvalid, strings.Join(statuses, ", "))
+	}
+	return s.repo.List(ctx, status)
+}
+
+func (s *Service) Get(ctx context.Context, id int) (*Suggestion, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+// Accept orders the suggested title on a new purchase order and marks the
+// suggestion accepted. Unless req names a catalog record to order, the
+// title is added to the catalog first, and removed again if the order
+// can't be placed.
+func (s *Service) Accept(ctx context.Context, id int, req AcceptRequest) (*Suggestion, error) {
+	sg, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if sg.Status != StatusPending {
+		return nil, ErrDecided
+	}
+	if req.Quantity == 0 {
+		req.Quantity = 1
+	}
+
+	var created *book.Book
+	bookID := 0
+	if req.BookID != nil {
+		bookID = *req.BookID
+	} else {
+		isbn := sg.ISBN
+		if strings.TrimSpace(req.ISBN) != "" {
+			if isbn, err = s.checkISBN(ctx, req.ISBN); err != nil {
+				return nil, err
+			}
+		}
+		if isbn == "" {
+			return nil, fmt.Errorf("%w: isbn is required to order a suggestion without one", ErrInvalid)
+		}
+		created = &book.Book{Title: sg.Title, Author: sg.Author, ISBN: isbn}
+		if err := s.books.Create(ctx, created); err != nil {
+			return nil, err
+		}
+		bookID = created.ID
+	}
+
+	po := &purchasing.PurchaseOrder{
+		Vendor:        req.Vendor,
+		VendorID:      req.VendorID,
+		Currency:      req.Currency,
+		FundingSource: req.FundingSource,
+		Lines:         []purchasing.OrderLine{{BookID: bookID, Quantity: req.Quantity, Price: req.Price}},
+	}
+	if err := s.purchase.Create(ctx, po); err != nil {
+		if created != nil {
+			s.books.Delete(ctx, created.ID)
+		}
+		return nil, err
+	}
+	sg, err = s.repo.Accept(ctx, id, bookID, po.ID)
+	if err != nil {
+		return nil, err
+	}
+	s.bus.Publish(ctx, eventbus.SuggestionDecided, sg.ID, *sg)
+	return sg, nil
+}
+
+// Reject marks the suggestion rejected, telling the member why
+func (s *Service) Reject(ctx context.Context, id int, req RejectRequest) (*Suggestion, error) {
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" || utf8.RuneCountInString(reason) > maxTextLength {
+		return nil, fmt.Errorf("%w: reason is required and must be at most %d characters", ErrInvalid, maxTextLength)
+	}
+	sg, err := s.repo.Reject(ctx, id, reason)
+	if err != nil {
+		return nil, err
+	}
+	s.bus.Publish(ctx, eventbus.SuggestionDecided, sg.ID, *sg)
+	return sg, nil
+}
+
+// match returns the catalog book the suggestion is of, by its ISBN or else
+// by a similar title by the same author, or 0 when there is none or the
+// title matches more than one book
+func (s *Service) match(ctx context.Context, req SuggestionRequest) (int, error) {
+	if req.ISBN != "" {
+		id, _ := normalizeISBN(req.ISBN)
+		b, err := s.books.GetByIdentifier(ctx, id.Type, id.Value)
+		if err == nil {
+			return b.ID, nil
+		}
+		if !errors.Is(err, book.ErrNotFound) {
+			return 0, err
+		}
+	}
+	surname := ""
+	if names := strings.Fields(req.Author); len(names) > 0 {
+		surname = names[len(names)-1]
+	}
+	ids, err := s.repo.MatchTitle(ctx, req.Title, surname)
+	if err != nil || len(ids) != 1 {
+		return 0, err
+	}
+	return ids[0], nil
+}
+
+// matched records the suggestion as matched to the book, placing a hold on
+// it for the member if they can have one
+func (s *Service) matched(ctx context.Context, memberID string, req SuggestionRequest, bookID int) (*Suggestion, error) {
+	orderID, err := s.repo.OpenOrder(ctx, bookID)
+	if err != nil {
+		return nil, err
+	}
+	var holdID *int
+	h, err := s.holds.PlaceHold(ctx, memberID, bookID, req.PickupBranch)
+	switch {
+	case err == nil:
+		holdID = &h.ID
+	case errors.Is(err, circulation.ErrAlreadyHeld), errors.Is(err, circulation.ErrHoldLimit),
+		errors.Is(err, circulation.ErrSuspended), errors.Is(err, circulation.ErrExpired):
+	default:
+		return nil, err
+	}
+	sg, err := s.repo.CreateMatched(ctx, memberID, req, bookID, orderID, holdID)
+	if err != nil {
+		return nil, err
+	}
+	s.bus.Publish(ctx, eventbus.SuggestionDecided, sg.ID, *sg)
+	return sg, nil
+}
+
+// checkISBN normalizes an ISBN-10 or ISBN-13 and checks no catalog book
+// has it
+func (s *Service) checkISBN(ctx context.Context, isbn string) (string, error) {
+	id, err := normalizeISBN(isbn)
+	if err != nil {
+		return "", err
+	}
+	b, err := s.books.GetByIdentifier(ctx, id.Type, id.Value)
+	if err == nil {
+		return "", fmt.Errorf("%w: isbn %s is book %d", ErrInCatalog, id.Value, b.ID)
+	}
+	if !errors.Is(err, book.ErrNotFound) {
+		return "", err
+	}
+	return id.Value, nil
+}
+
+// normalizeISBN normalizes an ISBN-10 or ISBN-13, typed by its length
+func normalizeISBN(isbn string) (book.Identifier, error) {
+	idType := book.IdentifierISBN13
+	if len(strings.NewReplacer("-", "", " ", "").Replace(isbn)) == 10 {
+		idType = book.IdentifierISBN10
+	}
+	id, err := book.NormalizeIdentifier(idType, isbn)
+	if err != nil {
+		return id, fmt.Errorf("%w: isbn must be an ISBN-10 or ISBN-13", ErrInvalid)
+	}
+	return id, nil
+}
@@ -0,0 +1,92 @@
+package usage
+
+import (
+	"encoding/json"
+	"net/http"
+	"public_library/internal/httperr"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultTopEndpoints = 10
+	defaultReportDays   = 30
+	maxReportDays       = 366
+)
+
+type Handler struct {
+	repo   *Repository
+	logger *zap.Logger
+}
+
+func NewHandler(r *Repository, l *zap.Logger) *Handler {
+	return &Handler{repo: r, logger: l}
+}
+
+// GET /admin/api-keys/{id}/usage?from=2024-04-01&to=2024-04-30&top=10
+
+// GetKeyUsage godoc
+// @Summary Usage of an API key
+// @Description Returns request counts, error rates and the busiest endpoints of the key between from and to (UTC days, inclusive). Counts are flushed periodically, so the last minute or so may be missing.
+// @Tags admin
+// @Produce json
+// @Security AdminToken
+// @Param id path string true "API key ID"
+// @Param from query string false "First day (YYYY-MM-DD); defaults to 29 days before to"
+// @Param to query string false "Last day (YYYY-MM-DD); defaults to today"
+// @Param top query int false "Number of top endpoints to return" default(10)
+// @Success 200 {object} Report
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /admin/api-keys/{id}/usage [get]
+func (h *Handler) GetKeyUsage(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	to := time.Now().UTC().Truncate(24 * time.Hour)
+	if v := q.Get("to"); v != "" {
+		t, err := time.Parse(time.DateOnly, v)
+		if err != nil {
+			http.Error(w, "invalid to parameter, expected YYYY-MM-DD", http.StatusBadRequest)
+			return
+		}
+		to = t
+	}
+	from := to.AddDate(0, 0, -(defaultReportDays - 1))
+	if v := q.Get("from"); v != "" {
+		t, err := time.Parse(time.DateOnly, v)
+		if err != nil {
+			http.Error(w, "invalid from parameter, expected YYYY-MM-DD", http.StatusBadRequest)
+			return
+		}
+		from = t
+	}
+	if from.After(to) {
+		http.Error(w, "from must not be after to", http.StatusBadRequest)
+		return
+	}
+	if to.Sub(from) >= maxReportDays*24*time.Hour {
+		http.Error(w, "the report covers at most 366 days", http.StatusBadRequest)
+		return
+	}
+
+	top := defaultTopEndpoints
+	if v := q.Get("top"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			http.Error(w, "invalid top parameter", http.StatusBadRequest)
+			return
+		}
+		top = n
+	}
+
+	report, err := h.repo.Report(r.Context(), mux.Vars(r)["id"], from, to, top)
+	if err != nil {
+		httperr.Write(w, h.logger, "failed to get usage report", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
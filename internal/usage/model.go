@@ -0,0 +1,39 @@
+package usage
+
+// Totals are request counts over a period. Client errors are 4xx responses
+// (including 429s from the rate limiter), server errors 5xx.
+type Totals struct {
+	Requests     int64 `json:"requests" example:"15230"`
+	ClientErrors int64 `json:"client_errors" example:"312"`
+	ServerErrors int64 `json:"server_errors" example:"4"`
+	// ErrorRate is (client_errors + server_errors) / requests
+	ErrorRate float64 `json:"error_rate" example:"0.0207"`
+}
+
+// EndpointUsage is the traffic of one key on one endpoint
+type EndpointUsage struct {
+	Endpoint string `json:"endpoint" example:"POST /api/v1/books/list"`
+	Totals
+}
+
+// DailyUsage is the traffic of one key on one day (UTC)
+type DailyUsage struct {
+	Day string `json:"day" example:"2024-05-01"`
+	Totals
+}
+
+// Report is the usage of one API key between From and To, inclusive
+type Report struct {
+	APIKeyID     string          `json:"api_key_id" example:"catalog-kiosk"`
+	From         string          `json:"from" example:"2024-04-02"`
+	To           string          `json:"to" example:"2024-05-01"`
+	Totals       Totals          `json:"totals"`
+	TopEndpoints []EndpointUsage `json:"top_endpoints"`
+	Daily        []DailyUsage    `json:"daily"`
+}
+
+func (t *Totals) computeRate() {
+	if t.Requests > 0 {
+		t.ErrorRate = float64(t.ClientErrors+t.ServerErrors) / float64(t.Requests)
+	}
+}
@@ -0,0 +1,106 @@
+// Package usage counts requests per API key and endpoint and reports them
+// through GET /admin/api-keys/{id}/usage. Counts are aggregated in memory
+// and added to the api_key_usage table by a periodic flush, so recording
+// costs no query per request.
+package usage
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type counterKey struct {
+	apiKeyID string
+	day      string
+	endpoint string
+}
+
+type counts struct {
+	requests, clientErrors, serverErrors int64
+}
+
+// Recorder aggregates requests until the next flush
+type Recorder struct {
+	repo   *Repository
+	logger *zap.Logger
+
+	mu      sync.Mutex
+	pending map[counterKey]*counts
+}
+
+func NewRecorder(repo *Repository, logger *zap.Logger) *Recorder {
+	return &Recorder{repo: repo, logger: logger, pending: make(map[counterKey]*counts)}
+}
+
+// Record counts one request of apiKeyID to endpoint that answered status
+func (rec *Recorder) Record(apiKeyID, endpoint string, status int, at time.Time) {
+	k := counterKey{apiKeyID: apiKeyID, day: at.UTC().Format(time.DateOnly), endpoint: endpoint}
+
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	c, ok := rec.pending[k]
+	if !ok {
+		c = &counts{}
+		rec.pending[k] = c
+	}
+	c.requests++
+	switch {
+	case status >= 500:
+		c.serverErrors++
+	case status >= 400:
+		c.clientErrors++
+	}
+}
+
+// Flush adds the pending counts to the database. If that fails they are
+// merged back and retried on the next flush.
+func (rec *Recorder) Flush(ctx context.Context) error {
+	rec.mu.Lock()
+	batch := rec.pending
+	rec.pending = make(map[counterKey]*counts)
+	rec.mu.Unlock()
+
+	if len(batch) == 0 {
+		return nil
+	}
+	if err := rec.repo.Add(ctx, batch); err != nil {
+		rec.mu.Lock()
+		for k, c := range batch {
+			if p, ok := rec.pending[k]; ok {
+				p.requests += c.requests
+				p.clientErrors += c.clientErrors
+				p.serverErrors += c.serverErrors
+			} else {
+				rec.pending[k] = c
+			}
+		}
+		rec.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// StartFlusher flushes every interval until ctx is cancelled. Whoever
+// cancels ctx should Flush once more to keep the last counts.
+func (rec *Recorder) StartFlusher(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := rec.Flush(ctx); err != nil {
+					rec.logger.Error("Usage flush failed", zap.Error(err))
+				}
+			}
+		}
+	}()
+}
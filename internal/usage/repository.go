@@ -0,0 +1,117 @@
+package usage
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"public_library/internal/db"
+	"public_library/utils"
+	"time"
+)
+
+type Repository struct {
+	db *db.DB
+}
+
+func NewRepository(conn *db.DB) *Repository {
+	return &Repository{db: conn}
+}
+
+// Add adds a batch of counts to the stored totals in one statement
+func (r *Repository) Add(ctx context.Context, batch map[counterKey]*counts) error {
+	log.Println("<--------Add usage starts-------->")
+	defer log.Println("<--------Add usage ends-------->")
+
+	var keys, days, endpoints []string
+	var requests, clientErrors, serverErrors []int64
+	for k, c := range batch {
+		keys, days, endpoints = append(keys, k.apiKeyID), append(days, k.day), append(endpoints, k.endpoint)
+		requests = append(requests, c.requests)
+		clientErrors = append(clientErrors, c.clientErrors)
+		serverErrors = append(serverErrors, c.serverErrors)
+	}
+
+	query := fmt.Sprintf(`INSERT INTO %[1]s (api_key_id, day, endpoint, requests, client_errors, server_errors)
+		SELECT * FROM unnest($1::text[], $2::date[], $3::text[], $4::bigint[], $5::bigint[], $6::bigint[])
+		ON CONFLICT (api_key_id, day, endpoint) DO UPDATE SET
+			requests = %[1]s.requests + EXCLUDED.requests,
+			client_errors = %[1]s.client_errors + EXCLUDED.client_errors,
+			server_errors = %[1]s.server_errors + EXCLUDED.server_errors`, utils.APIKeyUsageTable)
+	if _, err := r.db.ExecContext(ctx, query, keys, days, endpoints, requests, clientErrors, serverErrors); err != nil {
+		log.Printf("Failed to add usage for %d counters: %v", len(batch), err)
+		return err
+	}
+	return nil
+}
+
+// Report sums the usage of apiKeyID from..to (inclusive days) with its topN
+// busiest endpoints
+func (r *Repository) Report(ctx context.Context, apiKeyID string, from, to time.Time, topN int) (*Report, error) {
+	log.Println("<--------Usage report starts-------->")
+	defer log.Println("<--------Usage report ends-------->")
+
+	report := &Report{
+		APIKeyID:     apiKeyID,
+		From:         from.Format(time.DateOnly),
+		To:           to.Format(time.DateOnly),
+		TopEndpoints: []EndpointUsage{},
+		Daily:        []DailyUsage{},
+	}
+	args := []interface{}{apiKeyID, report.From, report.To}
+
+	endpointsQuery := fmt.Sprintf(`
+		SELECT endpoint, SUM(requests), SUM(client_errors), SUM(server_errors)
+		FROM %s
+		WHERE api_key_id = $1 AND day BETWEEN $2 AND $3
+		GROUP BY endpoint
+		ORDER BY SUM(requests) DESC, endpoint
+	`, utils.APIKeyUsageTable)
+	rows, err := r.db.QueryContext(ctx, endpointsQuery, args...)
+	if err != nil {
+		log.Printf("Failed to read endpoint usage for %q: %v", apiKeyID, err)
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var e EndpointUsage
+		if err := rows.Scan(&e.Endpoint, &e.Requests, &e.ClientErrors, &e.ServerErrors); err != nil {
+			log.Printf("Failed to scan endpoint usage: %v", err)
+			return nil, err
+		}
+		report.Totals.Requests += e.Requests
+		report.Totals.ClientErrors += e.ClientErrors
+		report.Totals.ServerErrors += e.ServerErrors
+		if len(report.TopEndpoints) < topN {
+			e.computeRate()
+			report.TopEndpoints = append(report.TopEndpoints, e)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	report.Totals.computeRate()
+
+	dailyQuery := fmt.Sprintf(`
+		SELECT to_char(day, 'YYYY-MM-DD'), SUM(requests), SUM(client_errors), SUM(server_errors)
+		FROM %s
+		WHERE api_key_id = $1 AND day BETWEEN $2 AND $3
+		GROUP BY day
+		ORDER BY day
+	`, utils.APIKeyUsageTable)
+	dailyRows, err := r.db.QueryContext(ctx, dailyQuery, args...)
+	if err != nil {
+		log.Printf("Failed to read daily usage for %q: %v", apiKeyID, err)
+		return nil, err
+	}
+	defer dailyRows.Close()
+	for dailyRows.Next() {
+		var d DailyUsage
+		if err := dailyRows.Scan(&d.Day, &d.Requests, &d.ClientErrors, &d.ServerErrors); err != nil {
+			log.Printf("Failed to scan daily usage: %v", err)
+			return nil, err
+		}
+		d.computeRate()
+		report.Daily = append(report.Daily, d)
+	}
+	return report, dailyRows.Err()
+}
@@ -0,0 +1,269 @@
+package weeding
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"public_library/internal/httperr"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	svc    *Service
+	logger *zap.Logger
+}
+
+func NewHandler(svc *Service, l *zap.Logger) *Handler {
+	return &Handler{svc: svc, logger: l}
+}
+
+// POST /weeding/proposals
+
+// ProposeWeeding godoc
+// @Summary Propose copies for withdrawal
+// @Description Selects the copies in the collection meeting every given criterion: at most max_checkouts checkouts, no checkout in idle_days days (counting from acquisition for copies never checked out), acquired at least min_age_years ago, and optionally one branch and format. idle_days or min_age_years is required. All matches start marked for withdrawal; copies on another open proposal are left out.
+// @Tags weeding
+// @Accept json
+// @Produce json
+// @Param proposal body Proposal true "Criteria and note"
+// @Success 201 {object} Proposal
+// @Failure 400 {object} map[string]string
+// @Router /weeding/proposals [post]
+func (h *Handler) ProposeWeeding(w http.ResponseWriter, r *http.Request) {
+	var p Proposal
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if err := h.svc.Propose(r.Context(), &p); err != nil {
+		h.writeError(w, "failed to create weeding proposal", err)
+		return
+	}
+	proposal, err := h.svc.Get(r.Context(), p.ID)
+	if err != nil {
+		h.writeError(w, "failed to load weeding proposal", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(proposal)
+}
+
+// GET /weeding/proposals?status=proposed
+
+// ListWeedingProposals godoc
+// @Summary List weeding proposals
+// @Tags weeding
+// @Produce json
+// @Param status query string false "Only proposals with this status" Enums(proposed, approved, rejected)
+// @Success 200 {array} Proposal
+// @Failure 400 {object} map[string]string
+// @Router /weeding/proposals [get]
+func (h *Handler) ListWeedingProposals(w http.ResponseWriter, r *http.Request) {
+	proposals, err := h.svc.List(r.Context(), r.URL.Query().Get("status"))
+	if err != nil {
+		h.writeError(w, "failed to list weeding proposals", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(proposals)
+}
+
+// GET /weeding/proposals/{id}
+
+// GetWeedingProposal godoc
+// @Summary Get a weeding proposal with its candidates
+// @Tags weeding
+// @Produce json
+// @Param id path int true "Proposal ID"
+// @Success 200 {object} Proposal
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /weeding/proposals/{id} [get]
+func (h *Handler) GetWeedingProposal(w http.ResponseWriter, r *http.Request) {
+	id, ok := proposalID(w, r)
+	if !ok {
+		return
+	}
+	p, err := h.svc.Get(r.Context(), id)
+	if err != nil {
+		h.writeError(w, "failed to get weeding proposal", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(p)
+}
+
+// PUT /weeding/proposals/{id}/candidates/{acquisition_id}
+
+// ReviewWeedingCandidate godoc
+// @Summary Decide whether a candidate copy is withdrawn or kept
+// @Tags weeding
+// @Accept json
+// @Param id path int true "Proposal ID"
+// @Param acquisition_id path int true "Acquisition ID of the copy"
+// @Param review body Review true "Decision and note"
+// @Success 204 "No Content"
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /weeding/proposals/{id}/candidates/{acquisition_id} [put]
+func (h *Handler) ReviewWeedingCandidate(w http.ResponseWriter, r *http.Request) {
+	id, ok := proposalID(w, r)
+	if !ok {
+		return
+	}
+	acquisitionID, err := strconv.Atoi(mux.Vars(r)["acquisition_id"])
+	if err != nil {
+		http.Error(w, "invalid acquisition ID", http.StatusBadRequest)
+		return
+	}
+	var review Review
+	if err := json.NewDecoder(r.Body).Decode(&review); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	if err := h.svc.Review(r.Context(), id, acquisitionID, review); err != nil {
+		h.writeError(w, "failed to review weeding candidate", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// POST /weeding/proposals/{id}/approve
+
+// ApproveWeedingProposal godoc
+// @Summary Approve a weeding proposal
+// @Description Withdraws every candidate still marked for withdrawal and records how the copies are disposed of. Withdrawn copies no longer count in the insurance valuation or circulate.
+// @Tags weeding
+// @Accept json
+// @Produce json
+// @Param id path int true "Proposal ID"
+// @Param decision body Decision true "Disposal method"
+// @Success 200 {object} Proposal
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /weeding/proposals/{id}/approve [post]
+func (h *Handler) ApproveWeedingProposal(w http.ResponseWriter, r *http.Request) {
+	id, ok := proposalID(w, r)
+	if !ok {
+		return
+	}
+	var d Decision
+	if err := json.NewDecoder(r.Body).Decode(&d); err != nil {
+		http.Error(w, "invalid JSON", http.StatusBadRequest)
+		return
+	}
+	p, err := h.svc.Approve(r.Context(), id, d)
+	if err != nil {
+		h.writeError(w, "failed to approve weeding proposal", err)
+		return
+	}
+	h.logger.Info("weeding proposal approved", zap.Int("proposal_id", id), zap.String("disposal", p.Disposal))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(p)
+}
+
+// POST /weeding/proposals/{id}/reject
+
+// RejectWeedingProposal godoc
+// @Summary Reject a weeding proposal
+// @Description Closes the proposal without withdrawing any copy.
+// @Tags weeding
+// @Produce json
+// @Param id path int true "Proposal ID"
+// @Success 200 {object} Proposal
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /weeding/proposals/{id}/reject [post]
+func (h *Handler) RejectWeedingProposal(w http.ResponseWriter, r *http.Request) {
+	id, ok := proposalID(w, r)
+	if !ok {
+		return
+	}
+	p, err := h.svc.Reject(r.Context(), id)
+	if err != nil {
+		h.writeError(w, "failed to reject weeding proposal", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(p)
+}
+
+// GET /weeding/proposals/{id}/disposal-report?format=pdf
+
+// GetDisposalReport godoc
+// @Summary Get the disposal report of an approved proposal
+// @Description Lists the copies the proposal withdrew by branch, with their circulation and purchase details. format=csv or format=pdf downloads the report as a file.
+// @Tags weeding
+// @Produce json
+// @Produce text/csv
+// @Produce application/pdf
+// @Param id path int true "Proposal ID"
+// @Param format query string false "Output format" Enums(json, csv, pdf) default(json)
+// @Success 200 {object} DisposalReport
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /weeding/proposals/{id}/disposal-report [get]
+func (h *Handler) GetDisposalReport(w http.ResponseWriter, r *http.Request) {
+	id, ok := proposalID(w, r)
+	if !ok {
+		return
+	}
+	format := strings.ToLower(r.URL.Query().Get("format"))
+	if format != "" && format != "json" && format != "csv" && format != "pdf" {
+		http.Error(w, "format must be json, csv or pdf", http.StatusBadRequest)
+		return
+	}
+	report, err := h.svc.DisposalReport(r.Context(), id)
+	if err != nil {
+		h.writeError(w, "failed to build disposal report", err)
+		return
+	}
+
+	switch format {
+	case "csv":
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="disposal-%d.csv"`, id))
+		if err := writeReportCSV(w, report); err != nil {
+			h.logger.Warn("disposal report CSV interrupted", zap.Error(err))
+		}
+	case "pdf":
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="disposal-%d.pdf"`, id))
+		w.Write(reportPDF(report))
+	default:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(report)
+	}
+}
+
+func proposalID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "invalid proposal ID", http.StatusBadRequest)
+		return 0, false
+	}
+	return id, true
+}
+
+func (h *Handler) writeError(w http.ResponseWriter, msg string, err error) {
+	switch {
+	case errors.Is(err, ErrInvalid):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, ErrNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, ErrDecided), errors.Is(err, ErrNotApproved):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		httperr.Write(w, h.logger, msg, err)
+	}
+}
@@ -0,0 +1,87 @@
+package weeding
+
+import "time"
+
+// Proposal statuses. Candidates can be reviewed while a proposal is
+// proposed; approving it withdraws the copies still marked for withdrawal.
+const (
+	StatusProposed = "proposed"
+	StatusApproved = "approved"
+	StatusRejected = "rejected"
+)
+
+var statuses = []string{StatusProposed, StatusApproved, StatusRejected}
+
+// Candidate decisions. Every candidate starts as DecisionWithdraw.
+const (
+	DecisionWithdraw = "withdraw"
+	DecisionKeep     = "keep"
+)
+
+// Criteria select the copies a proposal suggests withdrawing. A copy must
+// meet every criterion given.
+type Criteria struct {
+	// MaxCheckouts selects copies checked out at most this many times
+	MaxCheckouts *int `json:"max_checkouts,omitempty" example:"2"`
+	// IdleDays selects copies not checked out (or, if never, not acquired)
+	// in this many days
+	IdleDays int `json:"idle_days,omitempty" example:"730"`
+	// MinAgeYears selects copies acquired at least this many years ago
+	MinAgeYears int    `json:"min_age_years,omitempty" example:"10"`
+	Branch      string `json:"branch,omitempty" example:"Central"`
+	Format      string `json:"format,omitempty" example:"paperback"`
+}
+
+// Proposal is a list of copies suggested for withdrawal
+type Proposal struct {
+	ID       int      `json:"id" example:"3"`
+	Criteria Criteria `json:"criteria"`
+	Note     string   `json:"note,omitempty" example:"Annual fiction weed"`
+	Status   string   `json:"status" example:"proposed" enums:"proposed,approved,rejected"`
+	// Disposal says what becomes of withdrawn copies, e.g. "Friends book sale"
+	Disposal       string `json:"disposal,omitempty" example:"Friends book sale"`
+	CandidateCount int    `json:"candidate_count" example:"64"`
+	// Candidates are left out of proposal lists
+	Candidates []Candidate `json:"candidates,omitempty"`
+	CreatedAt  time.Time   `json:"created_at"`
+	DecidedAt  *time.Time  `json:"decided_at,omitempty"`
+}
+
+// Candidate is one copy on a proposal, with the facts reviewers decide on
+type Candidate struct {
+	AcquisitionID  int    `json:"acquisition_id" example:"41"`
+	BookID         int    `json:"book_id" example:"1"`
+	Title          string `json:"title" example:"The Great Gatsby"`
+	Author         string `json:"author" example:"F. Scott Fitzgerald"`
+	ISBN           string `json:"isbn,omitempty" example:"9780743273565"`
+	Branch         string `json:"branch" example:"Central"`
+	Format         string `json:"format" example:"paperback"`
+	AcquiredOn     string `json:"acquired_on" example:"2012-05-02"`
+	Checkouts      int    `json:"checkouts" example:"1"`
+	LastCheckoutOn string `json:"last_checkout_on,omitempty" example:"2019-11-20"`
+	Price          string `json:"price" example:"12.99"`
+	Currency       string `json:"currency" example:"USD"`
+	Decision       string `json:"decision" example:"withdraw" enums:"withdraw,keep"`
+	Note           string `json:"note,omitempty" example:"Only copy of a local author"`
+}
+
+// Review is a decision on one candidate
+type Review struct {
+	Decision string `json:"decision" example:"keep" enums:"withdraw,keep"`
+	Note     string `json:"note" example:"Only copy of a local author"`
+}
+
+// Decision approves or rejects a proposal
+type Decision struct {
+	// Disposal is required to approve
+	Disposal string `json:"disposal" example:"Friends book sale"`
+}
+
+// DisposalReport lists the copies an approved proposal withdrew
+type DisposalReport struct {
+	ProposalID int         `json:"proposal_id" example:"3"`
+	Disposal   string      `json:"disposal" example:"Friends book sale"`
+	ApprovedAt time.Time   `json:"approved_at"`
+	Copies     int         `json:"copies" example:"57"`
+	Items      []Candidate `json:"items"`
+}
@@ -0,0 +1,58 @@
+package weeding
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"public_library/internal/pdf"
+	"strconv"
+	"strings"
+)
+
+var reportColumns = []string{"acquisition_id", "book_id", "title", "author", "isbn", "branch", "format",
+	"acquired_on", "checkouts", "last_checkout_on", "price", "currency", "note"}
+
+func writeReportCSV(w io.Writer, r *DisposalReport) error {
+	cw := csv.NewWriter(w)
+	cw.Write(reportColumns)
+	for _, c := range r.Items {
+		cw.Write([]string{strconv.Itoa(c.AcquisitionID), strconv.Itoa(c.BookID), c.Title, c.Author, c.ISBN,
+			c.Branch, c.Format, c.AcquiredOn, strconv.Itoa(c.Checkouts), c.LastCheckoutOn, c.Price, c.Currency, c.Note})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// reportPDF prints the withdrawn copies grouped by branch, for the disposal
+// paperwork
+func reportPDF(r *DisposalReport) []byte {
+	doc := pdf.New(fmt.Sprintf("Disposal report, weeding proposal %d", r.ProposalID))
+	doc.Line("DISPOSAL REPORT")
+	doc.Line(fmt.Sprintf("Weeding proposal %d, approved %s", r.ProposalID, r.ApprovedAt.Format("2 January 2006")))
+	doc.Line("Disposal: " + r.Disposal)
+	doc.Line(fmt.Sprintf("Copies withdrawn: %d", r.Copies))
+	const header = "  %-8s %-34.34s %-13s %-10s %-10s %5s"
+	branch := ""
+	for i, c := range r.Items {
+		if i == 0 || c.Branch != branch {
+			branch = c.Branch
+			name := branch
+			if name == "" {
+				name = "(no branch)"
+			}
+			doc.Line("")
+			doc.Line(name)
+			doc.Line(fmt.Sprintf(header, "Copy", "Title / author", "ISBN", "Acquired", "Last out", "Loans"))
+			doc.Line("  " + strings.Repeat("-", pdf.LineWidth-2))
+		}
+		last := c.LastCheckoutOn
+		if last == "" {
+			last = "never"
+		}
+		doc.Line(fmt.Sprintf(header, strconv.Itoa(c.AcquisitionID), c.Title, c.ISBN, c.AcquiredOn, last, strconv.Itoa(c.Checkouts)))
+		if c.Author != "" {
+			doc.Line(fmt.Sprintf("  %-8s %.34s", "", c.Author))
+		}
+	}
+	return doc.Bytes()
+}
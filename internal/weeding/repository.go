@@ -0,0 +1,264 @@
+package weeding
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"public_library/internal/db"
+	"public_library/utils"
+	"time"
+)
+
+var (
+	// ErrNotFound is returned when no proposal, or no candidate on it, has
+	// the requested id
+	ErrNotFound = errors.New("weeding proposal not found")
+	// ErrDecided is returned for changes to a proposal already approved or
+	// rejected
+	ErrDecided = errors.New("weeding proposal has already been decided")
+)
+
+type Repository struct {
+	db *db.DB
+}
+
+func NewRepository(conn *db.DB) *Repository {
+	return &Repository{db: conn}
+}
+
+const selectProposalsSQL = `
+	SELECT p.id, p.criteria, p.note, p.status, p.disposal, p.created_at, p.decided_at,
+		(SELECT COUNT(*) FROM %[2]s c WHERE c.proposal_id = p.id)
+	FROM %[1]s p
+`
+
+func scanProposal(row interface{ Scan(...interface{}) error }) (Proposal, error) {
+	var p Proposal
+	var criteria []byte
+	err := row.Scan(&p.ID, &criteria, &p.Note, &p.Status, &p.Disposal, &p.CreatedAt, &p.DecidedAt, &p.CandidateCount)
+	if err != nil {
+		return p, err
+	}
+	return p, json.Unmarshal(criteria, &p.Criteria)
+}
+
+// Create stores p with every copy meeting its criteria as a candidate.
+// Copies already withdrawn or on another open proposal are left out.
+func (r *Repository) Create(ctx context.Context, p *Proposal) error {
+	log.Println("<--------Create weeding proposal starts-------->")
+	defer log.Println("<--------Create weeding proposal ends-------->")
+
+	criteria, err := json.Marshal(p.Criteria)
+	if err != nil {
+		return err
+	}
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	insertProposal := fmt.Sprintf(`
+		INSERT INTO %s (criteria, note) VALUES ($1, $2)
+		RETURNING id, status, created_at
+	`, utils.WeedingProposalsTable)
+	if err := tx.QueryRowContext(ctx, insertProposal, criteria, p.Note).Scan(&p.ID, &p.Status, &p.CreatedAt); err != nil {
+		log.Printf("Failed to create weeding proposal: %v", err)
+		return err
+	}
+
+	c := p.Criteria
+	insertCandidates := fmt.Sprintf(`
+		INSERT INTO %[1]s (proposal_id, acquisition_id)
+		SELECT $1, a.id
+		FROM %[2]s a
+		JOIN %[3]s b ON b.id = a.book_id
+		WHERE a.withdrawn_at IS NULL
+			AND ($2::int IS NULL OR a.checkouts <= $2)
+			AND ($3 = 0 OR COALESCE(a.last_checkout_on, a.acquired_on) < CURRENT_DATE - $3::int)
+			AND ($4 = 0 OR a.acquired_on <= CURRENT_DATE - make_interval(years => $4::int))
+			AND ($5 = '' OR a.branch = $5)
+			AND ($6 = '' OR b.format = $6)
+			AND NOT EXISTS (
+				SELECT 1 FROM %[1]s oc
+				JOIN %[4]s op ON op.id = oc.proposal_id
+				WHERE oc.acquisition_id = a.id AND op.status = '%[5]s' AND op.id <> $1
+			)
+	`, utils.WeedingCandidatesTable, utils.AcquisitionsTable, utils.BooksTable, utils.WeedingProposalsTable, StatusProposed)
+	result, err := tx.ExecContext(ctx, insertCandidates, p.ID, c.MaxCheckouts, c.IdleDays, c.MinAgeYears, c.Branch, c.Format)
+	if err != nil {
+		log.Printf("Failed to select candidates for weeding proposal id=%d: %v", p.ID, err)
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil {
+		p.CandidateCount = int(n)
+	}
+	return tx.Commit()
+}
+
+// GetByID returns the proposal with its candidates
+func (r *Repository) GetByID(ctx context.Context, id int) (*Proposal, error) {
+	query := fmt.Sprintf(selectProposalsSQL+`WHERE p.id = $1`, utils.WeedingProposalsTable, utils.WeedingCandidatesTable)
+	p, err := scanProposal(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		log.Printf("Failed to get weeding proposal id=%d: %v", id, err)
+		return nil, err
+	}
+	if p.Candidates, err = r.candidates(ctx, id, ""); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// List returns proposals newest first without their candidates, optionally
+// only those with status
+func (r *Repository) List(ctx context.Context, status string) ([]Proposal, error) {
+	query := fmt.Sprintf(selectProposalsSQL+`WHERE $1 = '' OR p.status = $1 ORDER BY p.id DESC`,
+		utils.WeedingProposalsTable, utils.WeedingCandidatesTable)
+	rows, err := r.db.QueryContext(ctx, query, status)
+	if err != nil {
+		log.Printf("Failed to list weeding proposals: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	proposals := []Proposal{}
+	for rows.Next() {
+		p, err := scanProposal(rows)
+		if err != nil {
+			return nil, err
+		}
+		proposals = append(proposals, p)
+	}
+	return proposals, rows.Err()
+}
+
+// candidates returns the candidates of a proposal in branch, format and
+// title order, optionally only those with decision
+func (r *Repository) candidates(ctx context.Context, proposalID int, decision string) ([]Candidate, error) {
+	query := fmt.Sprintf(`
+		SELECT c.acquisition_id, b.id, b.title, b.author,
+			COALESCE((SELECT i.value FROM %[4]s i
+				WHERE i.book_id = b.id AND i.type IN ('isbn13', 'isbn10')
+				ORDER BY i.type DESC LIMIT 1), ''),
+			a.branch, b.format, a.acquired_on, a.checkouts, a.last_checkout_on,
+			a.price::text, a.currency, c.decision, c.note
+		FROM %[1]s c
+		JOIN %[2]s a ON a.id = c.acquisition_id
+		JOIN %[3]s b ON b.id = a.book_id
+		WHERE c.proposal_id = $1 AND ($2 = '' OR c.decision = $2)
+		ORDER BY a.branch, b.format, b.title, a.id
+	`, utils.WeedingCandidatesTable, utils.AcquisitionsTable, utils.BooksTable, utils.BookIdentifiersTable)
+	rows, err := r.db.QueryContext(ctx, query, proposalID, decision)
+	if err != nil {
+		log.Printf("Failed to list candidates of weeding proposal id=%d: %v", proposalID, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	candidates := []Candidate{}
+	for rows.Next() {
+		var c Candidate
+		var acquiredOn time.Time
+		var lastCheckout sql.NullTime
+		err := rows.Scan(&c.AcquisitionID, &c.BookID, &c.Title, &c.Author, &c.ISBN, &c.Branch, &c.Format,
+			&acquiredOn, &c.Checkouts, &lastCheckout, &c.Price, &c.Currency, &c.Decision, &c.Note)
+		if err != nil {
+			return nil, err
+		}
+		c.AcquiredOn = acquiredOn.Format(time.DateOnly)
+		if lastCheckout.Valid {
+			c.LastCheckoutOn = lastCheckout.Time.Format(time.DateOnly)
+		}
+		candidates = append(candidates, c)
+	}
+	return candidates, rows.Err()
+}
+
+// Review records the decision on one candidate of an open proposal
+func (r *Repository) Review(ctx context.Context, proposalID, acquisitionID int, review Review) error {
+	log.Println("<--------Review weeding candidate starts-------->")
+	defer log.Println("<--------Review weeding candidate ends-------->")
+
+	query := fmt.Sprintf(`
+		UPDATE %s c SET decision = $3, note = $4
+		FROM %s p
+		WHERE p.id = c.proposal_id AND c.proposal_id = $1 AND c.acquisition_id = $2 AND p.status = $5
+	`, utils.WeedingCandidatesTable, utils.WeedingProposalsTable)
+	result, err := r.db.ExecContext(ctx, query, proposalID, acquisitionID, review.Decision, review.Note, StatusProposed)
+	if err != nil {
+		log.Printf("Failed to review candidate %d of weeding proposal id=%d: %v", acquisitionID, proposalID, err)
+		return err
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n > 0 {
+		return nil
+	}
+	// Find out whether the proposal is missing, decided or lacks the copy
+	p, err := r.GetByID(ctx, proposalID)
+	if err != nil {
+		return err
+	}
+	if p.Status != StatusProposed {
+		return ErrDecided
+	}
+	return fmt.Errorf("%w: copy %d is not a candidate", ErrNotFound, acquisitionID)
+}
+
+// Decide approves or rejects an open proposal. Approving withdraws every
+// candidate still marked for withdrawal.
+func (r *Repository) Decide(ctx context.Context, id int, status, disposal string) (*Proposal, error) {
+	log.Println("<--------Decide weeding proposal starts-------->")
+	defer log.Println("<--------Decide weeding proposal ends-------->")
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var current string
+	lock := fmt.Sprintf(`SELECT status FROM %s WHERE id = $1 FOR UPDATE`, utils.WeedingProposalsTable)
+	if err := tx.QueryRowContext(ctx, lock, id).Scan(&current); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if current != StatusProposed {
+		return nil, ErrDecided
+	}
+
+	if status == StatusApproved {
+		withdraw := fmt.Sprintf(`
+			UPDATE %s a SET withdrawn_at = now()
+			FROM %s c
+			WHERE c.acquisition_id = a.id AND c.proposal_id = $1 AND c.decision = $2 AND a.withdrawn_at IS NULL
+		`, utils.AcquisitionsTable, utils.WeedingCandidatesTable)
+		if _, err := tx.ExecContext(ctx, withdraw, id, DecisionWithdraw); err != nil {
+			log.Printf("Failed to withdraw the copies of weeding proposal id=%d: %v", id, err)
+			return nil, err
+		}
+	}
+	decide := fmt.Sprintf(`UPDATE %s SET status = $2, disposal = $3, decided_at = now() WHERE id = $1`, utils.WeedingProposalsTable)
+	if _, err := tx.ExecContext(ctx, decide, id, status, disposal); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		log.Printf("Failed to decide weeding proposal id=%d: %v", id, err)
+		return nil, err
+	}
+	return r.GetByID(ctx, id)
+}
+
+// Withdrawn returns the candidates of a proposal marked for withdrawal
+func (r *Repository) Withdrawn(ctx context.Context, proposalID int) ([]Candidate, error) {
+	return r.candidates(ctx, proposalID, DecisionWithdraw)
+}
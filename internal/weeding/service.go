@@ -0,0 +1,115 @@
+// Package weeding runs the withdrawal of little-used copies: a proposal
+// selects copies by circulation and age, staff review each candidate, and
+// approving the proposal withdraws the copies and yields a disposal report.
+package weeding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"public_library/internal/book"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	// ErrInvalid is wrapped by errors about the content of a request
+	ErrInvalid = errors.New("invalid weeding request")
+	// ErrNotApproved is returned for the disposal report of a proposal that
+	// was not approved
+	ErrNotApproved = errors.New("weeding proposal has not been approved")
+)
+
+const maxTextLength = 500
+
+type Service struct {
+	repo    *Repository
+	formats *book.FormatCatalog
+}
+
+func NewService(repo *Repository, formats *book.FormatCatalog) *Service {
+	return &Service{repo: repo, formats: formats}
+}
+
+// Propose validates p's criteria and stores it with the matching copies as
+// candidates, all marked for withdrawal
+func (s *Service) Propose(ctx context.Context, p *Proposal) error {
+	c := &p.Criteria
+	if c.MaxCheckouts != nil && *c.MaxCheckouts < 0 {
+		return fmt.Errorf("%w: max_checkouts must not be negative", ErrInvalid)
+	}
+	if c.IdleDays < 0 || c.MinAgeYears < 0 {
+		return fmt.Errorf("%w: idle_days and min_age_years must not be negative", ErrInvalid)
+	}
+	// Circulation counts alone would sweep up copies bought last week
+	if c.IdleDays == 0 && c.MinAgeYears == 0 {
+		return fmt.Errorf("%w: idle_days or min_age_years is required", ErrInvalid)
+	}
+	c.Branch = strings.TrimSpace(c.Branch)
+	c.Format = strings.ToLower(strings.TrimSpace(c.Format))
+	if _, ok := s.formats.Lookup(c.Format); c.Format != "" && !ok {
+		return fmt.Errorf("%w: unknown format %q", ErrInvalid, c.Format)
+	}
+	p.Note = strings.TrimSpace(p.Note)
+	if utf8.RuneCountInString(p.Note) > maxTextLength {
+		return fmt.Errorf("%w: note must be at most %d characters", ErrInvalid, maxTextLength)
+	}
+	return s.repo.Create(ctx, p)
+}
+
+func (s *Service) Get(ctx context.Context, id int) (*Proposal, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+// List returns proposals newest first, optionally only those with status
+func (s *Service) List(ctx context.Context, status string) ([]Proposal, error) {
+	if status != "" && !slices.Contains(statuses, status) {
+		return nil, fmt.Errorf("%w: status must be one of %s", ErrInvalid, strings.Join(statuses, ", "))
+	}
+	return s.repo.List(ctx, status)
+}
+
+// Review records whether a candidate of an open proposal is withdrawn or
+// kept
+func (s *Service) Review(ctx context.Context, proposalID, acquisitionID int, review Review) error {
+	if review.Decision != DecisionWithdraw && review.Decision != DecisionKeep {
+		return fmt.Errorf("%w: decision must be withdraw or keep", ErrInvalid)
+	}
+	review.Note = strings.TrimSpace(review.Note)
+	if utf8.RuneCountInString(review.Note) > maxTextLength {
+		return fmt.Errorf("%w: note must be at most %d characters", ErrInvalid, maxTextLength)
+	}
+	return s.repo.Review(ctx, proposalID, acquisitionID, review)
+}
+
+// Approve withdraws the copies of an open proposal still marked for
+// withdrawal and records how they are disposed of
+func (s *Service) Approve(ctx context.Context, id int, d Decision) (*Proposal, error) {
+	d.Disposal = strings.TrimSpace(d.Disposal)
+	if d.Disposal == "" || utf8.RuneCountInString(d.Disposal) > maxTextLength {
+		return nil, fmt.Errorf("%w: disposal is required, e.g. \"Friends book sale\", and must be at most %d characters", ErrInvalid, maxTextLength)
+	}
+	return s.repo.Decide(ctx, id, StatusApproved, d.Disposal)
+}
+
+// Reject closes an open proposal without withdrawing anything
+func (s *Service) Reject(ctx context.Context, id int) (*Proposal, error) {
+	return s.repo.Decide(ctx, id, StatusRejected, "")
+}
+
+// DisposalReport lists the copies an approved proposal withdrew
+func (s *Service) DisposalReport(ctx context.Context, id int) (*DisposalReport, error) {
+	p, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if p.Status != StatusApproved || p.DecidedAt == nil {
+		return nil, ErrNotApproved
+	}
+	items, err := s.repo.Withdrawn(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return &DisposalReport{ProposalID: id, Disposal: p.Disposal, ApprovedAt: *p.DecidedAt, Copies: len(items), Items: items}, nil
+}
@@ -0,0 +1,91 @@
+// Package contract verifies consumer contracts against the API in-process.
+// Contracts use the Pact v2 JSON layout (consumer, provider, interactions
+// with a request, an expected response and an optional provider state).
+// Response bodies are matched by shape: every field the consumer's example
+// has must be present in the actual response with the same JSON type, and
+// extra fields are allowed. That catches renamed, removed or retyped fields
+// without pinning the data.
+package contract
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Contract is the set of interactions one consumer relies on
+type Contract struct {
+	Consumer     Participant   `json:"consumer"`
+	Provider     Participant   `json:"provider"`
+	Interactions []Interaction `json:"interactions"`
+}
+
+type Participant struct {
+	Name string `json:"name"`
+}
+
+// Interaction is one request and the response the consumer expects
+type Interaction struct {
+	Description string `json:"description"`
+	// ProviderState names the data the provider must set up first
+	ProviderState string   `json:"providerState,omitempty"`
+	Request       Request  `json:"request"`
+	Response      Response `json:"response"`
+}
+
+// Request is sent as is, after replacing {name} placeholders in Path and
+// Body with the values returned by the provider state
+type Request struct {
+	Method  string            `json:"method"`
+	Path    string            `json:"path"`
+	Query   string            `json:"query,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    json.RawMessage   `json:"body,omitempty"`
+}
+
+// Response is what the consumer expects. Headers must match exactly; Body
+// is matched by shape.
+type Response struct {
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    json.RawMessage   `json:"body,omitempty"`
+}
+
+// Load reads a contract file
+func Load(path string) (Contract, error) {
+	var c Contract
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return c, err
+	}
+	if err := json.Unmarshal(data, &c); err != nil {
+		return c, fmt.Errorf("parsing contract %s: %w", path, err)
+	}
+	for i, in := range c.Interactions {
+		if in.Request.Method == "" || in.Request.Path == "" || in.Response.Status == 0 {
+			return c, fmt.Errorf("contract %s: interaction %d (%q) needs a request method and path and a response status",
+				path, i, in.Description)
+		}
+	}
+	return c, nil
+}
+
+// LoadDir reads every *.json contract in dir, in name order
+func LoadDir(dir string) ([]Contract, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+	contracts := make([]Contract, 0, len(paths))
+	for _, p := range paths {
+		c, err := Load(p)
+		if err != nil {
+			return nil, err
+		}
+		contracts = append(contracts, c)
+	}
+	return contracts, nil
+}
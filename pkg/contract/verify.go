@@ -0,0 +1,197 @@
+package contract
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+)
+
+// StateFunc sets up the data a provider state describes and returns the
+// values its interactions refer to as {name} placeholders, such as the ID of
+// a book it created
+type StateFunc func(ctx context.Context) (map[string]string, error)
+
+// Verifier replays contracts against Handler
+type Verifier struct {
+	Handler http.Handler
+	States  map[string]StateFunc
+}
+
+// Failure lists what did not match in one interaction
+type Failure struct {
+	Consumer    string
+	Interaction string
+	Problems    []string
+}
+
+func (f Failure) String() string {
+	return fmt.Sprintf("%s: %s:\n  - %s", f.Consumer, f.Interaction, strings.Join(f.Problems, "\n  - "))
+}
+
+// Verify replays every interaction of c and returns those that failed
+func (v *Verifier) Verify(ctx context.Context, c Contract) []Failure {
+	var failures []Failure
+	for _, in := range c.Interactions {
+		if problems := v.verify(ctx, in); len(problems) > 0 {
+			failures = append(failures, Failure{Consumer: c.Consumer.Name, Interaction: in.Description, Problems: problems})
+		}
+	}
+	return failures
+}
+
+// VerifyAll verifies every contract and joins the failures into one error
+func (v *Verifier) VerifyAll(ctx context.Context, contracts []Contract) error {
+	var errs []error
+	for _, c := range contracts {
+		for _, f := range v.Verify(ctx, c) {
+			errs = append(errs, errors.New(f.String()))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (v *Verifier) verify(ctx context.Context, in Interaction) []string {
+	values := map[string]string{}
+	if in.ProviderState != "" {
+		state, ok := v.States[in.ProviderState]
+		if !ok {
+			return []string{fmt.Sprintf("unknown provider state %q", in.ProviderState)}
+		}
+		var err error
+		if values, err = state(ctx); err != nil {
+			return []string{fmt.Sprintf("setting up provider state %q: %v", in.ProviderState, err)}
+		}
+	}
+
+	target := substitute(in.Request.Path, values)
+	if in.Request.Query != "" {
+		target += "?" + substitute(in.Request.Query, values)
+	}
+	var body *bytes.Reader
+	if len(in.Request.Body) > 0 {
+		body = bytes.NewReader([]byte(substitute(string(in.Request.Body), values)))
+	} else {
+		body = bytes.NewReader(nil)
+	}
+	req := httptest.NewRequest(in.Request.Method, target, body).WithContext(ctx)
+	for k, val := range in.Request.Headers {
+		req.Header.Set(k, substitute(val, values))
+	}
+
+	rec := httptest.NewRecorder()
+	v.Handler.ServeHTTP(rec, req)
+
+	var problems []string
+	if rec.Code != in.Response.Status {
+		problems = append(problems, fmt.Sprintf("status: expected %d, got %d (%s)",
+			in.Response.Status, rec.Code, strings.TrimSpace(rec.Body.String())))
+		return problems
+	}
+	keys := make([]string, 0, len(in.Response.Headers))
+	for k := range in.Response.Headers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		want, got := in.Response.Headers[k], rec.Header().Get(k)
+		// Content-Type is compared without parameters such as charset
+		if strings.EqualFold(k, "Content-Type") {
+			got, _, _ = strings.Cut(got, ";")
+		}
+		if got != want {
+			problems = append(problems, fmt.Sprintf("header %s: expected %q, got %q", k, want, got))
+		}
+	}
+	if len(in.Response.Body) > 0 {
+		var want, got interface{}
+		if err := json.Unmarshal(in.Response.Body, &want); err != nil {
+			return append(problems, fmt.Sprintf("contract body is not JSON: %v", err))
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+			return append(problems, fmt.Sprintf("response body is not JSON: %v", err))
+		}
+		problems = append(problems, matchShape("$", want, got)...)
+	}
+	return problems
+}
+
+func substitute(s string, values map[string]string) string {
+	for k, v := range values {
+		s = strings.ReplaceAll(s, "{"+k+"}", v)
+	}
+	return s
+}
+
+// matchShape compares an expected example with an actual JSON value by
+// type. null in the example accepts any value; every element of an actual
+// array must match the example's first element.
+func matchShape(path string, want, got interface{}) []string {
+	if want == nil {
+		return nil
+	}
+	switch w := want.(type) {
+	case map[string]interface{}:
+		g, ok := got.(map[string]interface{})
+		if !ok {
+			return []string{fmt.Sprintf("%s: expected an object, got %s", path, typeName(got))}
+		}
+		keys := make([]string, 0, len(w))
+		for k := range w {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		var problems []string
+		for _, k := range keys {
+			gv, present := g[k]
+			if !present {
+				problems = append(problems, fmt.Sprintf("%s.%s: missing", path, k))
+				continue
+			}
+			problems = append(problems, matchShape(path+"."+k, w[k], gv)...)
+		}
+		return problems
+	case []interface{}:
+		g, ok := got.([]interface{})
+		if !ok {
+			return []string{fmt.Sprintf("%s: expected an array, got %s", path, typeName(got))}
+		}
+		if len(w) == 0 {
+			return nil
+		}
+		var problems []string
+		for i, gv := range g {
+			problems = append(problems, matchShape(fmt.Sprintf("%s[%d]", path, i), w[0], gv)...)
+		}
+		return problems
+	default:
+		if typeName(want) != typeName(got) {
+			return []string{fmt.Sprintf("%s: expected %s, got %s", path, typeName(want), typeName(got))}
+		}
+		return nil
+	}
+}
+
+func typeName(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
@@ -0,0 +1,193 @@
+package fixtures
+
+import (
+	"fmt"
+	"public_library/internal/book"
+	"strings"
+)
+
+// genre ties the subject of a book to its Dewey range, collection and
+// typical audience
+type genre struct {
+	name       string
+	dewey      [2]int // call number range; 0 means fiction, shelved by author
+	collection string
+	audience   string
+	// blurb is the description, formatted with the subject
+	blurb string
+	// titles are formatted with the title-cased subject; fiction without
+	// titles gets an invented one
+	titles   []string
+	subjects []string
+}
+
+var genres = []weighted[genre]{
+	{genre{"literary fiction", [2]int{0, 0}, "Adult Fiction", "adult", "A novel about %s.", nil,
+		[]string{"a family reunion", "a failing marriage", "a small coastal town", "an inheritance", "a lost brother"}}, 20},
+	{genre{"mystery", [2]int{0, 0}, "Mystery", "adult", "A mystery involving %s.", nil,
+		[]string{"a locked-room murder", "a missing heiress", "a poisoned vicar", "a stolen manuscript", "a cold case"}}, 15},
+	{genre{"science fiction", [2]int{0, 0}, "Science Fiction", "adult", "A science fiction novel about %s.", nil,
+		[]string{"a generation ship", "first contact", "a failing terraforming colony", "time-travelling historians", "an AI uprising"}}, 10},
+	{genre{"fantasy", [2]int{0, 0}, "Teen Fiction", "teen", "A fantasy adventure about %s.", nil,
+		[]string{"a reluctant heir", "a forbidden library", "dragon riders", "a cursed forest", "a school of magic"}}, 10},
+	{genre{"history", [2]int{900, 999}, "Adult Nonfiction", "adult", "A narrative history of %s.",
+		[]string{"A Short History of %s", "%s: A New History", "The Age of %s"},
+		[]string{"the Silk Road", "the fall of Constantinople", "the Hanseatic League", "the Meiji Restoration", "the Dust Bowl"}}, 10},
+	{genre{"science", [2]int{500, 599}, "Adult Nonfiction", "adult", "An accessible introduction to %s.",
+		[]string{"The Science of %s", "Understanding %s", "%s Explained"},
+		[]string{"the deep ocean", "quantum mechanics", "the human microbiome", "volcanoes", "bird migration"}}, 8},
+	{genre{"cooking", [2]int{641, 641}, "Adult Nonfiction", "adult", "Recipes and techniques for %s.",
+		[]string{"The Joy of %s", "%s at Home", "Simply %s"},
+		[]string{"sourdough", "weeknight vegetarian dinners", "Sichuan cooking", "preserving and pickling", "baking with children"}}, 6},
+	{genre{"biography", [2]int{920, 929}, "Biography", "adult", "The life of %s.",
+		[]string{"The Life of %s", "Portrait of %s"},
+		[]string{"a pioneering aviator", "a Victorian engineer", "a jazz pianist", "a Renaissance painter", "a polar explorer"}}, 6},
+	{genre{"picture book", [2]int{0, 0}, "Picture Books", "children", "A picture book about %s.", nil,
+		[]string{"a brave little fox", "a bedtime moon", "a lost mitten", "a very hungry caterpillar's cousin", "a busy fire station"}}, 10},
+	{genre{"children's fiction", [2]int{0, 0}, "Children's Fiction", "children", "A chapter book about %s.", nil,
+		[]string{"a treehouse club", "a talking cat", "a summer camp mystery", "a robot best friend", "a haunted school"}}, 5},
+}
+
+var firstNames = []string{
+	"Ada", "Amara", "Benedict", "Camille", "Chinua", "Dmitri", "Elena", "Farah", "Gabriel", "Hana",
+	"Ingrid", "Jonas", "Kenji", "Lucia", "Mateo", "Nadia", "Oscar", "Priya", "Quentin", "Rosa",
+	"Samuel", "Tove", "Umberto", "Vera", "Wei", "Ximena", "Yusuf", "Zadie",
+}
+
+var lastNames = []string{
+	"Abernathy", "Bergström", "Castellanos", "Delacroix", "Eze", "Fitzgerald", "Gallagher", "Haddad",
+	"Ishikawa", "Jovanović", "Kowalski", "Lindqvist", "Mbeki", "Nakamura", "O'Connell", "Petrov",
+	"Quinn", "Ramírez", "Sato", "Thornbury", "Underwood", "Villanueva", "Whitfield", "Yamamoto", "Zielinski",
+}
+
+var titleAdjectives = []string{"Silent", "Last", "Hidden", "Broken", "Golden", "Distant", "Forgotten", "Burning", "Paper", "Winter"}
+var titleNouns = []string{"Harbor", "Garden", "Machine", "Orchard", "Kingdom", "Lighthouse", "Archive", "River", "Mirror", "Atlas"}
+
+var formats = []weighted[string]{
+	{"paperback", 40}, {"hardcover", 25}, {"ebook", 15}, {"audiobook", 7},
+	{"large_print", 5}, {"audiobook_cd", 3}, {"dvd", 3}, {"bluray", 1}, {"magazine", 1},
+}
+
+var editions = []weighted[string]{{"1st", 70}, {"2nd", 15}, {"3rd", 5}, {"Revised", 5}, {"Anniversary", 5}}
+
+// Author returns a plausible author name
+func (g *Generator) Author() string {
+	if g.rnd.Intn(10) == 0 {
+		// initials, as on many older spines
+		return fmt.Sprintf("%c.%c. %s", pick(g, firstNames)[0], pick(g, firstNames)[0], pick(g, lastNames))
+	}
+	return pick(g, firstNames) + " " + pick(g, lastNames)
+}
+
+func (g *Generator) title(gen genre, subject string) string {
+	switch {
+	case len(gen.titles) > 0:
+		t := fmt.Sprintf(pick(g, gen.titles), titleCase(subject))
+		return strings.ToUpper(t[:1]) + t[1:]
+	case g.rnd.Intn(3) == 0:
+		return "The " + pick(g, titleNouns) + " of " + pick(g, lastNames)
+	default:
+		return "The " + pick(g, titleAdjectives) + " " + pick(g, titleNouns)
+	}
+}
+
+var smallWords = map[string]bool{"a": true, "an": true, "and": true, "of": true, "the": true, "with": true}
+
+// titleCase capitalizes every word except articles and short prepositions
+func titleCase(s string) string {
+	words := strings.Fields(s)
+	for i, w := range words {
+		if !smallWords[w] {
+			words[i] = strings.ToUpper(w[:1]) + w[1:]
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// ISBN13 returns a valid, unique (per generator) ISBN-13 with an
+// English-language group prefix
+func (g *Generator) ISBN13() string {
+	for {
+		prefix := pick(g, []string{"9780", "9781"})
+		digits := prefix + fmt.Sprintf("%08d", g.rnd.Intn(100_000_000))
+		isbn := digits + string(isbn13CheckDigit(digits))
+		if !g.isbns[isbn] {
+			g.isbns[isbn] = true
+			return isbn
+		}
+	}
+}
+
+func isbn13CheckDigit(first12 string) byte {
+	sum := 0
+	for i, c := range first12 {
+		d := int(c - '0')
+		if i%2 == 1 {
+			d *= 3
+		}
+		sum += d
+	}
+	return byte('0' + (10-sum%10)%10)
+}
+
+// Book returns one book. It has no ID or series and passes book.Validate.
+func (g *Generator) Book() book.Book {
+	gen := pickWeighted(g, genres)
+	subject := pick(g, gen.subjects)
+	author := g.Author()
+	surname := author[strings.LastIndex(author, " ")+1:]
+	cutter := strings.ToUpper(string([]rune(surname)[:3]))
+
+	b := book.Book{
+		Title:       g.title(gen, subject),
+		Author:      author,
+		ISBN:        g.ISBN13(),
+		Format:      pickWeighted(g, formats),
+		Audience:    gen.audience,
+		Collection:  gen.collection,
+		Edition:     pickWeighted(g, editions),
+		Description: fmt.Sprintf(gen.blurb, subject),
+	}
+
+	switch {
+	case gen.dewey[0] != 0:
+		class := gen.dewey[0] + g.rnd.Intn(gen.dewey[1]-gen.dewey[0]+1)
+		b.CallNumber = fmt.Sprintf("%03d.%d %s", class, g.rnd.Intn(100), cutter)
+	case gen.audience == "children" && gen.collection == "Picture Books":
+		b.CallNumber = "E " + cutter
+	case gen.audience == "children":
+		b.CallNumber = "J " + cutter
+	default:
+		b.CallNumber = "FIC " + cutter
+	}
+	floor := map[string]string{"children": "1F-C", "teen": "1F-T", "adult": "2F-A"}[gen.audience]
+	b.ShelfLocation = fmt.Sprintf("%s%02d", floor, 1+g.rnd.Intn(40))
+
+	// publication years skew recent, as in a working collection
+	year := g.now.Year() - int(g.rnd.ExpFloat64()*15)
+	if year < 1800 {
+		year = 1800 + g.rnd.Intn(100)
+	}
+	b.PublicationYear = &year
+
+	pages := 150 + int(g.rnd.NormFloat64()*80) + 150
+	if gen.collection == "Picture Books" {
+		pages = 24 + 8*g.rnd.Intn(4)
+	}
+	if pages < 16 {
+		pages = 16
+	}
+	if b.Format != "audiobook" && b.Format != "audiobook_cd" && b.Format != "dvd" && b.Format != "bluray" {
+		b.PageCount = &pages
+	}
+	return b
+}
+
+// Books returns n books
+func (g *Generator) Books(n int) []book.Book {
+	books := make([]book.Book, n)
+	for i := range books {
+		books[i] = g.Book()
+	}
+	return books
+}
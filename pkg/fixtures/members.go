@@ -0,0 +1,149 @@
+package fixtures
+
+import (
+	"fmt"
+	"public_library/internal/book"
+	"strings"
+	"time"
+)
+
+// Member is a generated library patron, with more detail than the
+// circulation service stores about its members.
+type Member struct {
+	CardNumber string    `json:"card_number"`
+	Name       string    `json:"name"`
+	Email      string    `json:"email"`
+	BirthDate  time.Time `json:"birth_date"`
+	// Audience is the catalog audience the member's age allows
+	Audience string    `json:"audience"`
+	JoinedAt time.Time `json:"joined_at"`
+}
+
+// Loan is one checkout in a member's history. ReturnedAt is nil while the
+// item is still out.
+type Loan struct {
+	MemberIndex  int        `json:"member_index"`
+	BookIndex    int        `json:"book_index"`
+	CheckedOutAt time.Time  `json:"checked_out_at"`
+	DueAt        time.Time  `json:"due_at"`
+	ReturnedAt   *time.Time `json:"returned_at,omitempty"`
+	Renewals     int        `json:"renewals"`
+}
+
+// CardNumber returns a unique 14-digit library card barcode with a Luhn check digit
+func (g *Generator) CardNumber() string {
+	for {
+		digits := "2" + fmt.Sprintf("%012d", g.rnd.Int63n(1_000_000_000_000))
+		card := digits + string(luhnCheckDigit(digits))
+		if !g.cards[card] {
+			g.cards[card] = true
+			return card
+		}
+	}
+}
+
+func luhnCheckDigit(payload string) byte {
+	sum := 0
+	for i := len(payload) - 1; i >= 0; i-- {
+		d := int(payload[i] - '0')
+		if (len(payload)-i)%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+	return byte('0' + (10-sum%10)%10)
+}
+
+// Member returns one member aged 4 to 90
+func (g *Generator) Member() Member {
+	first, last := pick(g, firstNames), pick(g, lastNames)
+	age := 4 + g.rnd.Intn(87)
+	birth := g.now.AddDate(-age, 0, -g.rnd.Intn(365)).Truncate(24 * time.Hour)
+
+	audience := "adult"
+	switch {
+	case age < 13:
+		audience = "children"
+	case age < 18:
+		audience = "teen"
+	}
+	membershipYears := g.rnd.Intn(age - 3)
+	joined := g.now.AddDate(-membershipYears, 0, -g.rnd.Intn(365))
+
+	local := strings.ToLower(first + "." + strings.ReplaceAll(last, "'", ""))
+	return Member{
+		CardNumber: g.CardNumber(),
+		Name:       first + " " + last,
+		Email:      fmt.Sprintf("%s%d@%s", local, g.rnd.Intn(100), pick(g, []string{"example.org", "example.com", "example.net"})),
+		BirthDate:  birth,
+		Audience:   audience,
+		JoinedAt:   joined,
+	}
+}
+
+// Members returns n members
+func (g *Generator) Members(n int) []Member {
+	members := make([]Member, n)
+	for i := range members {
+		members[i] = g.Member()
+	}
+	return members
+}
+
+var audienceRank = map[string]int{"children": 0, "teen": 1, "adult": 2}
+
+// LoanHistory returns about perMember loans per member, drawn from the books
+// their audience allows, in checkout order per member. Loan periods use the
+// default loan days of each book's format; most loans are returned, some
+// late, and the most recent may still be out.
+func (g *Generator) LoanHistory(books []book.Book, members []Member, perMember int) []Loan {
+	if len(books) == 0 || perMember <= 0 {
+		return nil
+	}
+	catalog, _ := book.NewFormatCatalog(nil)
+
+	var loans []Loan
+	for mi, m := range members {
+		allowed := make([]int, 0, len(books))
+		for bi, b := range books {
+			if audienceRank[b.Audience] <= audienceRank[m.Audience] {
+				allowed = append(allowed, bi)
+			}
+		}
+		if len(allowed) == 0 {
+			continue
+		}
+
+		n := perMember/2 + g.rnd.Intn(perMember+1)
+		span := g.now.Sub(m.JoinedAt)
+		at := m.JoinedAt
+		for i := 0; i < n; i++ {
+			// spread checkouts over the membership, roughly evenly
+			at = at.Add(time.Duration(g.rnd.Int63n(int64(span)/int64(n+1) + 1))).Truncate(time.Second)
+			if at.After(g.now) {
+				break
+			}
+			bi := pick(g, allowed)
+			days := 21
+			if f, ok := catalog.Lookup(books[bi].Format); ok {
+				days = f.LoanDays
+			}
+			loan := Loan{MemberIndex: mi, BookIndex: bi, CheckedOutAt: at}
+			if g.rnd.Intn(5) == 0 {
+				loan.Renewals = 1 + g.rnd.Intn(2)
+			}
+			loan.DueAt = at.AddDate(0, 0, days*(1+loan.Renewals))
+
+			// returned somewhere between a few days in and a week late
+			returned := at.Add(time.Duration(g.rnd.Int63n(int64(loan.DueAt.Sub(at) + 7*24*time.Hour)))).Truncate(time.Second)
+			if returned.Before(g.now) {
+				loan.ReturnedAt = &returned
+			}
+			loans = append(loans, loan)
+		}
+	}
+	return loans
+}
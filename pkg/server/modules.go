@@ -0,0 +1,532 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"public_library/internal/acquisition"
+	"public_library/internal/admin"
+	"public_library/internal/analytics"
+	"public_library/internal/book"
+	"public_library/internal/cache"
+	"public_library/internal/calendar"
+	"public_library/internal/challenges"
+	"public_library/internal/circulation"
+	"public_library/internal/config"
+	"public_library/internal/consent"
+	"public_library/internal/courses"
+	"public_library/internal/db"
+	"public_library/internal/devices"
+	"public_library/internal/embeddings"
+	"public_library/internal/eventbus"
+	"public_library/internal/experiments"
+	"public_library/internal/feed"
+	"public_library/internal/health"
+	"public_library/internal/ilssync"
+	"public_library/internal/media"
+	"public_library/internal/payments"
+	"public_library/internal/picks"
+	"public_library/internal/policy"
+	"public_library/internal/purchasing"
+	"public_library/internal/ratelimit"
+	"public_library/internal/readinglist"
+	"public_library/internal/recommendations"
+	"public_library/internal/repairs"
+	"public_library/internal/rfid"
+	"public_library/internal/sandbox"
+	"public_library/internal/savedsearch"
+	"public_library/internal/serials"
+	"public_library/internal/series"
+	"public_library/internal/shelfmap"
+	"public_library/internal/stats"
+	"public_library/internal/suggestions"
+	"public_library/internal/usage"
+	"public_library/internal/weeding"
+	"time"
+
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// Each module declares the constructors of one part of the API and the
+// lifecycle of its background jobs. A new module (members, loans, ...) adds
+// its own fx.Module here and to modules; nothing else is wired by hand.
+var modules = fx.Options(
+	configModule,
+	dbModule,
+	eventsModule,
+	healthModule,
+	bookModule,
+	seriesModule,
+	mediaModule,
+	statsModule,
+	adminModule,
+	rateLimitModule,
+	usageModule,
+	analyticsModule,
+	experimentsModule,
+	sandboxModule,
+	readingListModule,
+	ilsSyncModule,
+	acquisitionModule,
+	purchasingModule,
+	weedingModule,
+	repairsModule,
+	rfidModule,
+	devicesModule,
+	coursesModule,
+	calendarModule,
+	shelfMapModule,
+	policyModule,
+	circulationModule,
+	consentModule,
+	savedSearchModule,
+	serialsModule,
+	challengesModule,
+	picksModule,
+	suggestionsModule,
+	recommendationsModule,
+	feedModule,
+	embeddingsModule,
+	paymentsModule,
+	fx.Provide(newRouter),
+)
+
+// configModule splits AppConfig so constructors depend on their own section
+var configModule = fx.Module("config",
+	fx.Provide(
+		func(c config.AppConfig) config.DBConfig {
+			if c.Sandbox.Enabled {
+				c.DB.Schema = c.Sandbox.Schema
+			}
+			return c.DB
+		},
+		func(c config.AppConfig) config.RetryConfig { return c.DB.Retry },
+		func(c config.AppConfig) config.CatalogConfig { return c.Catalog },
+		func(c config.AppConfig) config.FormatsConfig { return c.Formats },
+		func(c config.AppConfig) config.MediaConfig { return c.Media },
+		func(c config.AppConfig) cache.Config { return c.Cache.BookList },
+		func(c config.AppConfig) config.HealthConfig { return c.Health },
+		func(c config.AppConfig) config.MaintenanceConfig { return c.Maintenance },
+		func(c config.AppConfig) config.RateLimitConfig { return c.RateLimit },
+		func(c config.AppConfig) config.ILSSyncConfig { return c.ILSSync },
+		func(c config.AppConfig) config.AcquisitionsConfig { return c.Acquisitions },
+		func(c config.AppConfig) config.EDIConfig { return c.EDI },
+		func(c config.AppConfig) config.SerialsConfig { return c.Serials },
+		func(c config.AppConfig) config.CirculationConfig { return c.Circulation },
+		func(c config.AppConfig) config.PolicyConfig { return c.Policy },
+		func(c config.AppConfig) config.SavedSearchesConfig { return c.SavedSearches },
+		func(c config.AppConfig) config.DevicesConfig { return c.Devices },
+		func(c config.AppConfig) config.AnalyticsConfig { return c.Analytics },
+		func(c config.AppConfig) config.RecommendationsConfig { return c.Recommendations },
+		func(c config.AppConfig) config.FeedConfig { return c.Feed },
+		func(c config.AppConfig) config.EmbeddingsConfig { return c.Embeddings },
+	),
+)
+
+var dbModule = fx.Module("db",
+	fx.Provide(
+		db.NewRetrier,
+		func(conn *sql.DB, c config.DBConfig, logger *zap.Logger) *db.DB {
+			return db.NewDB(conn, c.SlowQueryThreshold, logger)
+		},
+	),
+	fx.Invoke(func(lc fx.Lifecycle, conn *sql.DB, c config.AppConfig, logger *zap.Logger) {
+		runJob(lc, func(ctx context.Context) {
+			db.StartPartitionMaintenance(ctx, conn, c.Partitions, logger)
+		})
+	}),
+)
+
+var eventsModule = fx.Module("events",
+	fx.Provide(eventbus.New),
+	// Book responses include series titles, so cached lists go stale with series
+	fx.Invoke(func(bus *eventbus.Bus, books *book.Service) {
+		for _, topic := range []string{eventbus.SeriesUpdated, eventbus.SeriesDeleted} {
+			bus.Subscribe(topic, func(context.Context, eventbus.Event) { books.InvalidateLists() })
+		}
+	}),
+)
+
+// healthModule checks the database and every integration in health.checks
+var healthModule = fx.Module("health",
+	fx.Provide(func(c config.HealthConfig, books *book.Service) (*health.Checker, error) {
+		checker := health.NewChecker(c)
+		checker.Register("database", books.Ping)
+		return checker, checker.RegisterConfigured()
+	}),
+)
+
+var bookModule = fx.Module("book",
+	fx.Provide(
+		book.NewRepository,
+		func(c config.FormatsConfig) (*book.FormatCatalog, error) {
+			return book.NewFormatCatalog(c.LoanDays)
+		},
+		book.NewService,
+		book.NewHandler,
+	),
+)
+
+var seriesModule = fx.Module("series",
+	fx.Provide(series.NewRepository, series.NewHandler),
+)
+
+var mediaModule = fx.Module("media",
+	fx.Provide(
+		func(c config.MediaConfig) (*media.TokenSigner, error) {
+			return media.NewTokenSigner(c.TokenSecret, c.TokenTTL)
+		},
+		media.NewRepository,
+		func(r *media.Repository, signer *media.TokenSigner, loans *circulation.Repository, c config.MediaConfig,
+			o options, logger *zap.Logger) *media.Handler {
+			return media.NewHandler(r, signer, loans, c, o.pathPrefix, logger)
+		},
+	),
+)
+
+var statsModule = fx.Module("stats",
+	fx.Provide(stats.NewRepository, stats.NewHandler),
+	fx.Invoke(func(lc fx.Lifecycle, repo *stats.Repository, c config.AppConfig, logger *zap.Logger) {
+		runJob(lc, func(ctx context.Context) {
+			stats.StartRefresher(ctx, repo, c.Stats.RefreshInterval, logger)
+		})
+	}),
+)
+
+var adminModule = fx.Module("admin",
+	fx.Provide(admin.NewMaintenance, admin.NewHandler),
+)
+
+var rateLimitModule = fx.Module("ratelimit",
+	fx.Provide(ratelimit.NewRepository, ratelimit.NewLimiter, ratelimit.NewHandler),
+	fx.Invoke(func(lc fx.Lifecycle, limiter *ratelimit.Limiter, c config.RateLimitConfig) {
+		runJob(lc, func(ctx context.Context) {
+			limiter.StartReloader(ctx, c.ReloadInterval)
+		})
+	}),
+)
+
+// usageModule flushes the usage counters periodically and once more on stop,
+// before the database is closed
+var usageModule = fx.Module("usage",
+	fx.Provide(usage.NewRepository, usage.NewRecorder, usage.NewHandler),
+	fx.Invoke(func(lc fx.Lifecycle, rec *usage.Recorder, c config.AppConfig) {
+		runJob(lc, func(ctx context.Context) {
+			rec.StartFlusher(ctx, c.Usage.FlushInterval)
+		})
+		lc.Append(fx.StopHook(rec.Flush))
+	}),
+)
+
+// analyticsModule flushes the anonymous usage counts periodically and once
+// more on stop, before the database is closed
+var analyticsModule = fx.Module("analytics",
+	fx.Provide(analytics.NewRepository, analytics.NewRecorder),
+	fx.Invoke(func(lc fx.Lifecycle, rec *analytics.Recorder, c config.AnalyticsConfig) {
+		if !c.Enabled {
+			return
+		}
+		runJob(lc, func(ctx context.Context) {
+			rec.StartFlusher(ctx, c.FlushInterval)
+		})
+		lc.Append(fx.StopHook(rec.Flush))
+	}),
+)
+
+// experimentsModule reloads the experiments and flushes their counts
+// periodically, once more on stop, and records checkouts and holds as
+// conversions
+var experimentsModule = fx.Module("experiments",
+	fx.Provide(experiments.NewRepository, experiments.NewAssigner, experiments.NewService, experiments.NewHandler),
+	fx.Invoke(func(lc fx.Lifecycle, a *experiments.Assigner, bus *eventbus.Bus, c config.AppConfig) {
+		runJob(lc, func(ctx context.Context) {
+			a.StartJobs(ctx, c.Experiments.ReloadInterval, c.Experiments.FlushInterval)
+		})
+		lc.Append(fx.StopHook(a.Flush))
+		bus.Subscribe(eventbus.LoanCheckedOut, func(ctx context.Context, e eventbus.Event) {
+			if l, ok := e.Payload.(circulation.Loan); ok && l.MemberID != "" {
+				a.ConvertMember(l.MemberID, experiments.MetricCheckout, e.OccurredAt)
+			}
+		})
+		bus.Subscribe(eventbus.HoldPlaced, func(ctx context.Context, e eventbus.Event) {
+			if h, ok := e.Payload.(circulation.Hold); ok {
+				a.ConvertMember(h.MemberID, experiments.MetricHold, e.OccurredAt)
+			}
+		})
+	}),
+)
+
+// sandboxModule resets the demo data on schedule when sandbox mode is on
+var sandboxModule = fx.Module("sandbox",
+	fx.Provide(sandbox.New),
+	fx.Invoke(func(lc fx.Lifecycle, sb *sandbox.Sandbox, c config.AppConfig) {
+		if !c.Sandbox.Enabled {
+			return
+		}
+		runJob(lc, sb.StartResets)
+	}),
+)
+
+var readingListModule = fx.Module("readinglist",
+	fx.Provide(readinglist.NewRepository, readinglist.NewService, readinglist.NewHandler),
+)
+
+// ilsSyncModule pulls records from the legacy ILS on schedule when enabled
+var ilsSyncModule = fx.Module("ilssync",
+	fx.Provide(ilssync.NewRepository, ilssync.New, ilssync.NewHandler),
+	fx.Invoke(func(lc fx.Lifecycle, s *ilssync.Syncer, c config.ILSSyncConfig) {
+		if !c.Enabled {
+			return
+		}
+		runJob(lc, s.Start)
+	}),
+)
+
+var acquisitionModule = fx.Module("acquisition",
+	fx.Provide(
+		acquisition.NewRateSource,
+		acquisition.NewRepository,
+		acquisition.NewService,
+		acquisition.NewHandler,
+	),
+)
+
+var purchasingModule = fx.Module("purchasing",
+	fx.Provide(
+		purchasing.NewRepository,
+		purchasing.NewService,
+		purchasing.NewHandler,
+	),
+)
+
+var weedingModule = fx.Module("weeding",
+	fx.Provide(
+		weeding.NewRepository,
+		weeding.NewService,
+		weeding.NewHandler,
+	),
+)
+
+var repairsModule = fx.Module("repairs",
+	fx.Provide(
+		repairs.NewRepository,
+		repairs.NewService,
+		repairs.NewHandler,
+	),
+)
+
+var rfidModule = fx.Module("rfid",
+	fx.Provide(
+		rfid.NewRepository,
+		rfid.NewService,
+		rfid.NewHandler,
+	),
+)
+
+var devicesModule = fx.Module("devices",
+	fx.Provide(
+		devices.NewRepository,
+		devices.NewService,
+		devices.NewHandler,
+	),
+	fx.Invoke(func(lc fx.Lifecycle, svc *devices.Service, logger *zap.Logger) {
+		runJob(lc, func(ctx context.Context) {
+			svc.StartMonitor(ctx, logger)
+		})
+	}),
+)
+
+var coursesModule = fx.Module("courses",
+	fx.Provide(
+		courses.NewRepository,
+		courses.NewService,
+		courses.NewHandler,
+	),
+)
+
+var calendarModule = fx.Module("calendar",
+	fx.Provide(
+		calendar.NewRepository,
+		calendar.NewService,
+		calendar.NewHandler,
+	),
+)
+
+var shelfMapModule = fx.Module("shelfmap",
+	fx.Provide(
+		shelfmap.NewRepository,
+		shelfmap.NewService,
+		shelfmap.NewHandler,
+	),
+)
+
+var policyModule = fx.Module("policy",
+	fx.Provide(
+		policy.NewEngine,
+		policy.NewHandler,
+	),
+)
+
+// circulationModule brings the fines of overdue loans up to date and renews
+// the loans of members who opted in on schedule
+var circulationModule = fx.Module("circulation",
+	fx.Provide(
+		circulation.NewRepository,
+		circulation.NewService,
+		circulation.NewHandler,
+	),
+	fx.Invoke(func(lc fx.Lifecycle, svc *circulation.Service, logger *zap.Logger) {
+		runJob(lc, func(ctx context.Context) {
+			svc.StartOverdueJob(ctx, logger)
+		})
+		runJob(lc, func(ctx context.Context) {
+			svc.StartAutoRenewJob(ctx, logger)
+		})
+	}),
+)
+
+var consentModule = fx.Module("consent",
+	fx.Provide(
+		consent.NewRepository,
+		consent.NewService,
+		consent.NewHandler,
+	),
+)
+
+// savedSearchModule alerts members to new books matching their saved
+// searches on schedule
+var savedSearchModule = fx.Module("savedsearch",
+	fx.Provide(
+		savedsearch.NewRepository,
+		savedsearch.NewService,
+		savedsearch.NewHandler,
+	),
+	fx.Invoke(func(lc fx.Lifecycle, svc *savedsearch.Service, logger *zap.Logger) {
+		runJob(lc, func(ctx context.Context) {
+			svc.StartAlerts(ctx, logger)
+		})
+	}),
+)
+
+// serialsModule sends subscription renewal reminders on schedule
+var serialsModule = fx.Module("serials",
+	fx.Provide(
+		serials.NewRepository,
+		serials.NewService,
+		serials.NewHandler,
+	),
+	fx.Invoke(func(lc fx.Lifecycle, svc *serials.Service, logger *zap.Logger) {
+		runJob(lc, func(ctx context.Context) {
+			svc.StartReminders(ctx, logger)
+		})
+	}),
+)
+
+// challengesModule logs the books members return toward their reading
+// challenge for the year
+var challengesModule = fx.Module("challenges",
+	fx.Provide(
+		challenges.NewRepository,
+		challenges.NewService,
+		challenges.NewHandler,
+	),
+	fx.Invoke(func(bus *eventbus.Bus, svc *challenges.Service, logger *zap.Logger) {
+		bus.Subscribe(eventbus.LoanReturned, func(ctx context.Context, e eventbus.Event) {
+			l, ok := e.Payload.(circulation.Loan)
+			if !ok || l.MemberID == "" || l.ReturnedAt == nil {
+				return
+			}
+			if err := svc.LogReturn(ctx, l.MemberID, l.BookID, *l.ReturnedAt); err != nil {
+				logger.Warn("Logging a return toward a reading challenge failed", zap.Int("loan_id", l.ID), zap.Error(err))
+			}
+		})
+	}),
+)
+
+var picksModule = fx.Module("picks",
+	fx.Provide(
+		picks.NewRepository,
+		picks.NewService,
+		func(svc *picks.Service, o options, logger *zap.Logger) *picks.Handler {
+			return picks.NewHandler(svc, o.pathPrefix, logger)
+		},
+	),
+)
+
+var suggestionsModule = fx.Module("suggestions",
+	fx.Provide(
+		suggestions.NewRepository,
+		suggestions.NewService,
+		suggestions.NewHandler,
+	),
+)
+
+var recommendationsModule = fx.Module("recommendations",
+	fx.Provide(
+		recommendations.NewRepository,
+		recommendations.NewService,
+		recommendations.NewHandler,
+	),
+)
+
+var feedModule = fx.Module("feed",
+	fx.Provide(
+		feed.NewRepository,
+		feed.NewService,
+		feed.NewHandler,
+	),
+)
+
+// embeddingsModule embeds new and edited books on schedule when enabled
+var embeddingsModule = fx.Module("embeddings",
+	fx.Provide(
+		embeddings.NewRepository,
+		embeddings.NewService,
+		embeddings.NewHandler,
+	),
+	fx.Invoke(func(lc fx.Lifecycle, svc *embeddings.Service, logger *zap.Logger) {
+		runJob(lc, func(ctx context.Context) {
+			svc.StartIndexer(ctx, logger)
+		})
+	}),
+)
+
+var paymentsModule = fx.Module("payments",
+	fx.Provide(
+		payments.NewRepository,
+		payments.NewService,
+		payments.NewHandler,
+	),
+)
+
+// databaseModule provides the *sql.DB: either conn, migrated but owned by
+// the caller, or a connection opened from the config and closed on stop
+func databaseModule(conn *sql.DB) fx.Option {
+	if conn != nil {
+		return fx.Provide(func() (*sql.DB, error) {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			return conn, db.ApplySchema(ctx, conn)
+		})
+	}
+	return fx.Provide(func(lc fx.Lifecycle, c config.DBConfig, logger *zap.Logger) *sql.DB {
+		conn := db.InitConnection(c, logger)
+		lc.Append(fx.StopHook(conn.Close))
+		return conn
+	})
+}
+
+// runJob starts a background job with the app and cancels it on stop
+func runJob(lc fx.Lifecycle, start func(ctx context.Context)) {
+	ctx, cancel := context.WithCancel(context.Background())
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			start(ctx)
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			cancel()
+			return nil
+		},
+	})
+}
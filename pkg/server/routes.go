@@ -0,0 +1,355 @@
+package server
+
+import (
+	"net/http"
+	"public_library/internal/acquisition"
+	"public_library/internal/admin"
+	"public_library/internal/analytics"
+	"public_library/internal/book"
+	"public_library/internal/calendar"
+	"public_library/internal/challenges"
+	"public_library/internal/circulation"
+	"public_library/internal/config"
+	"public_library/internal/consent"
+	"public_library/internal/courses"
+	"public_library/internal/devices"
+	"public_library/internal/embeddings"
+	"public_library/internal/experiments"
+	"public_library/internal/feed"
+	"public_library/internal/ilssync"
+	"public_library/internal/media"
+	"public_library/internal/middleware"
+	"public_library/internal/payments"
+	"public_library/internal/picks"
+	"public_library/internal/policy"
+	"public_library/internal/purchasing"
+	"public_library/internal/ratelimit"
+	"public_library/internal/readinglist"
+	"public_library/internal/recommendations"
+	"public_library/internal/repairs"
+	"public_library/internal/rfid"
+	"public_library/internal/savedsearch"
+	"public_library/internal/serials"
+	"public_library/internal/series"
+	"public_library/internal/shelfmap"
+	"public_library/internal/stats"
+	"public_library/internal/suggestions"
+	"public_library/internal/usage"
+	"public_library/internal/weeding"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/swaggo/http-swagger"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+
+	_ "public_library/docs"
+)
+
+type routerParams struct {
+	fx.In
+
+	Config       config.AppConfig
+	Options      options
+	Logger       *zap.Logger
+	Books        *book.Handler
+	Series       *series.Handler
+	Media        *media.Handler
+	Stats        *stats.Handler
+	Admin        *admin.Handler
+	Limits       *ratelimit.Handler
+	Limiter      *ratelimit.Limiter
+	Usage        *usage.Handler
+	Recorder     *usage.Recorder
+	Analytics    *analytics.Recorder
+	Experiments  *experiments.Handler
+	Assigner     *experiments.Assigner
+	Reading      *readinglist.Handler
+	ILSSync      *ilssync.Handler
+	Acquisitions *acquisition.Handler
+	Purchasing   *purchasing.Handler
+	Weeding      *weeding.Handler
+	Repairs      *repairs.Handler
+	RFID         *rfid.Handler
+	Devices      *devices.Handler
+	Courses      *courses.Handler
+	Circulation  *circulation.Handler
+	Policy       *policy.Handler
+	Calendar     *calendar.Handler
+	ShelfMap     *shelfmap.Handler
+	Consent      *consent.Handler
+	Consents     *consent.Service
+	Saved        *savedsearch.Handler
+	Serials      *serials.Handler
+	Challenges   *challenges.Handler
+	Picks        *picks.Handler
+	Suggestions  *suggestions.Handler
+	Recommend    *recommendations.Handler
+	Feed         *feed.Handler
+	Embeddings   *embeddings.Handler
+	Payments     *payments.Handler
+	// Maintenance gates the write routes
+	Maintenance *admin.Maintenance
+}
+
+func newRouter(p routerParams) *mux.Router {
+	cfg, logger := p.Config, p.Logger
+	handler, seriesHandler, mediaHandler, statsHandler := p.Books, p.Series, p.Media, p.Stats
+
+	// Per-route timeouts: reads should be fast, writes get a bit more headroom
+	read := middleware.Timeout(cfg.Server.Timeouts.Read, logger)
+	write := middleware.Timeout(cfg.Server.Timeouts.Write, logger)
+	bulk := middleware.Timeout(cfg.Server.Timeouts.Import, logger)
+	// Writes are refused while maintenance mode is on; reads keep working
+	maintenance := middleware.Maintenance(p.Maintenance)
+	change := chain(maintenance, write)
+	bulk = chain(maintenance, bulk)
+
+	// RESTful routes
+	router := mux.NewRouter()
+	v1 := router.PathPrefix(p.Options.pathPrefix).Subrouter()
+	v1.Use(middleware.Endpoint)
+	v1.Use(middleware.AudienceLimit)
+	v1.Use(middleware.Language)
+	// Members with documents to accept can only reach the consent routes
+	v1.Use(middleware.Consent(p.Consents, logger))
+	// Usage wraps the rate limiter so refused requests are counted too
+	v1.Use(middleware.Usage(p.Recorder))
+	v1.Use(middleware.RateLimit(p.Limiter))
+	if cfg.Analytics.Enabled {
+		v1.Use(middleware.Analytics(p.Analytics))
+	}
+	v1.Use(middleware.Experiments(p.Assigner))
+	v1.Handle("/health", read(http.HandlerFunc(handler.HealthCheck))).Methods("GET")
+	v1.Handle("/formats", read(http.HandlerFunc(handler.ListFormats))).Methods("GET")
+	v1.Handle("/books", read(http.HandlerFunc(handler.ListBooks))).Methods("GET")
+	v1.Handle("/books/list", read(http.HandlerFunc(handler.GetBooks))).Methods("POST")
+	v1.Handle("/books/search/natural", read(http.HandlerFunc(p.Embeddings.SearchNatural))).Methods("POST")
+	v1.Handle("/books/availability", read(http.HandlerFunc(p.Circulation.CheckAvailability))).Methods("POST")
+	v1.Handle("/books/create", change(http.HandlerFunc(handler.CreateBook))).Methods("POST")
+	v1.Handle("/books/import", bulk(http.HandlerFunc(handler.ImportBooks))).Methods("POST")
+	v1.Handle("/books/bulk", bulk(http.HandlerFunc(handler.BulkCreateBooks))).Methods("POST")
+	v1.Handle("/books/bulk-delete", bulk(http.HandlerFunc(handler.BulkDeleteBooks))).Methods("POST")
+	// Exports stream for as long as the download takes, so no timeout wrapper
+	v1.HandleFunc("/books/export", handler.ExportBooks).Methods("GET")
+	v1.Handle("/books/shelf-report", read(http.HandlerFunc(handler.GetShelfReport))).Methods("GET")
+	v1.Handle("/books/identifiers/{type}/{value}", read(http.HandlerFunc(handler.GetBookByIdentifier))).Methods("GET")
+	v1.Handle("/books/{id}", read(http.HandlerFunc(handler.GetBookByID))).Methods("GET")
+	v1.Handle("/books/{id}/citation", read(http.HandlerFunc(handler.GetBookCitation))).Methods("GET")
+	v1.Handle("/books/{id}/cite", read(http.HandlerFunc(handler.GetFormattedCitation))).Methods("GET")
+	v1.Handle("/books/{id}/translations", read(http.HandlerFunc(handler.ListTranslations))).Methods("GET")
+	v1.Handle("/books/{id}/recommendations", read(http.HandlerFunc(p.Recommend.GetBookRecommendations))).Methods("GET")
+	v1.Handle("/books/{id}/similar", read(http.HandlerFunc(p.Embeddings.GetSimilarBooks))).Methods("GET")
+	v1.Handle("/me/recommendations", read(http.HandlerFunc(p.Recommend.GetMyRecommendations))).Methods("GET")
+	v1.Handle("/me/feed", read(http.HandlerFunc(p.Feed.GetMyFeed))).Methods("GET")
+	v1.Handle("/books/{id}/translations/{language}", change(http.HandlerFunc(handler.PutTranslation))).Methods("PUT")
+	v1.Handle("/books/{id}/translations/{language}", change(http.HandlerFunc(handler.DeleteTranslation))).Methods("DELETE")
+	v1.Handle("/books/{id}", change(http.HandlerFunc(handler.UpdateBook))).Methods("PUT")
+	v1.Handle("/books/{id}", change(http.HandlerFunc(handler.PatchBook))).Methods("PATCH")
+	v1.Handle("/books/{id}", change(http.HandlerFunc(handler.DeleteBook))).Methods("DELETE")
+	// Uploads and streams run as long as the transfer takes, so no timeout wrapper
+	v1.Handle("/books/{id}/assets", maintenance(http.HandlerFunc(mediaHandler.UploadAsset))).Methods("POST")
+	v1.Handle("/books/{id}/assets", read(http.HandlerFunc(mediaHandler.ListAssets))).Methods("GET")
+	// Issuing a stream token only signs it, so it stays available in maintenance mode
+	v1.Handle("/media/assets/{id}/stream-token", write(http.HandlerFunc(mediaHandler.IssueStreamToken))).Methods("POST")
+	v1.HandleFunc("/media/stream/{token}", mediaHandler.StreamAsset).Methods("GET")
+	v1.Handle("/media/stream/{token}/opds", read(http.HandlerFunc(mediaHandler.StreamFeed))).Methods("GET")
+	v1.Handle("/media/stream/{token}/pages/{page}", read(http.HandlerFunc(mediaHandler.StreamPage))).Methods("GET")
+	v1.Handle("/series", read(http.HandlerFunc(seriesHandler.ListSeries))).Methods("GET")
+	v1.Handle("/series", change(http.HandlerFunc(seriesHandler.CreateSeries))).Methods("POST")
+	v1.Handle("/series/{id}", read(http.HandlerFunc(seriesHandler.GetSeries))).Methods("GET")
+	v1.Handle("/series/{id}", change(http.HandlerFunc(seriesHandler.UpdateSeries))).Methods("PUT")
+	v1.Handle("/series/{id}", change(http.HandlerFunc(seriesHandler.DeleteSeries))).Methods("DELETE")
+	v1.Handle("/stats/books", read(http.HandlerFunc(statsHandler.GetBookStats))).Methods("GET")
+	v1.Handle("/stats/usage", read(http.HandlerFunc(statsHandler.GetUsageStats))).Methods("GET")
+	v1.Handle("/experiments/assignments", read(http.HandlerFunc(p.Experiments.GetAssignments))).Methods("GET")
+	// Conversions are only counted in memory, so they stay available in maintenance mode
+	v1.Handle("/experiments/{key}/conversions", write(http.HandlerFunc(p.Experiments.RecordConversion))).Methods("POST")
+	v1.Handle("/books/{id}/acquisitions", read(http.HandlerFunc(p.Acquisitions.ListAcquisitions))).Methods("GET")
+	v1.Handle("/books/{id}/acquisitions", change(http.HandlerFunc(p.Acquisitions.RecordAcquisition))).Methods("POST")
+	v1.Handle("/acquisitions/report", read(http.HandlerFunc(p.Acquisitions.GetAcquisitionReport))).Methods("GET")
+	v1.Handle("/acquisitions/valuation", read(http.HandlerFunc(p.Acquisitions.GetValuation))).Methods("GET")
+	v1.Handle("/acquisitions/{id}", change(http.HandlerFunc(p.Acquisitions.UpdateAcquisition))).Methods("PUT")
+	v1.Handle("/acquisitions/{id}", change(http.HandlerFunc(p.Acquisitions.DeleteAcquisition))).Methods("DELETE")
+	v1.Handle("/acquisitions/{id}/checkouts", change(http.HandlerFunc(p.Acquisitions.RecordCheckout))).Methods("POST")
+	v1.Handle("/copies/{id}/history", read(http.HandlerFunc(p.Circulation.GetCopyHistory))).Methods("GET")
+	v1.Handle("/acquisitions/{id}/history", read(http.HandlerFunc(p.Circulation.GetCopyHistory))).Methods("GET")
+	v1.Handle("/acquisitions/{id}/repairs", read(http.HandlerFunc(p.Repairs.ListCopyRepairs))).Methods("GET")
+	v1.Handle("/acquisitions/{id}/repairs", change(http.HandlerFunc(p.Repairs.SendOutForRepair))).Methods("POST")
+	v1.Handle("/repairs", read(http.HandlerFunc(p.Repairs.ListRepairs))).Methods("GET")
+	v1.Handle("/repairs/{id}", read(http.HandlerFunc(p.Repairs.GetRepair))).Methods("GET")
+	v1.Handle("/repairs/{id}/return", change(http.HandlerFunc(p.Repairs.ReturnRepair))).Methods("POST")
+	v1.Handle("/acquisitions/{id}/rfid-tags", read(http.HandlerFunc(p.RFID.ListCopyTags))).Methods("GET")
+	v1.Handle("/acquisitions/{id}/rfid-tags", change(http.HandlerFunc(p.RFID.AssignTag))).Methods("POST")
+	v1.Handle("/rfid-tags/encode", bulk(http.HandlerFunc(p.RFID.EncodeTags))).Methods("POST")
+	v1.Handle("/rfid-tags/resolve", read(http.HandlerFunc(p.RFID.ResolveTags))).Methods("POST")
+	v1.Handle("/rfid-tags/{uid}", read(http.HandlerFunc(p.RFID.ResolveTag))).Methods("GET")
+	v1.Handle("/rfid-tags/{uid}", change(http.HandlerFunc(p.RFID.RemoveTag))).Methods("DELETE")
+	// Devices keep reporting in maintenance mode, or they would all show as
+	// silent once it ends
+	v1.Handle("/devices", write(http.HandlerFunc(p.Devices.RegisterDevice))).Methods("POST")
+	v1.Handle("/devices/{id}/heartbeat", write(http.HandlerFunc(p.Devices.SendHeartbeat))).Methods("POST")
+	v1.Handle("/devices/{id}/errors", write(http.HandlerFunc(p.Devices.ReportDeviceError))).Methods("POST")
+	v1.Handle("/purchase-orders", read(http.HandlerFunc(p.Purchasing.ListPurchaseOrders))).Methods("GET")
+	v1.Handle("/purchase-orders", change(http.HandlerFunc(p.Purchasing.CreatePurchaseOrder))).Methods("POST")
+	v1.Handle("/purchase-orders/{id}", read(http.HandlerFunc(p.Purchasing.GetPurchaseOrder))).Methods("GET")
+	v1.Handle("/purchase-orders/{id}/edifact", change(http.HandlerFunc(p.Purchasing.SendPurchaseOrder))).Methods("POST")
+	v1.Handle("/members/{id}/suggestions", read(http.HandlerFunc(p.Suggestions.ListMemberSuggestions))).Methods("GET")
+	v1.Handle("/members/{id}/suggestions", change(http.HandlerFunc(p.Suggestions.SuggestPurchase))).Methods("POST")
+	v1.Handle("/suggestions", read(http.HandlerFunc(p.Suggestions.ListSuggestions))).Methods("GET")
+	v1.Handle("/suggestions/{id}", read(http.HandlerFunc(p.Suggestions.GetSuggestion))).Methods("GET")
+	v1.Handle("/suggestions/{id}/accept", change(http.HandlerFunc(p.Suggestions.AcceptSuggestion))).Methods("POST")
+	v1.Handle("/suggestions/{id}/reject", change(http.HandlerFunc(p.Suggestions.RejectSuggestion))).Methods("POST")
+	v1.Handle("/edi/messages", bulk(http.HandlerFunc(p.Purchasing.IngestVendorMessages))).Methods("POST")
+	v1.Handle("/weeding/proposals", read(http.HandlerFunc(p.Weeding.ListWeedingProposals))).Methods("GET")
+	v1.Handle("/weeding/proposals", change(http.HandlerFunc(p.Weeding.ProposeWeeding))).Methods("POST")
+	v1.Handle("/weeding/proposals/{id}", read(http.HandlerFunc(p.Weeding.GetWeedingProposal))).Methods("GET")
+	v1.Handle("/weeding/proposals/{id}/candidates/{acquisition_id}", change(http.HandlerFunc(p.Weeding.ReviewWeedingCandidate))).Methods("PUT")
+	v1.Handle("/weeding/proposals/{id}/approve", change(http.HandlerFunc(p.Weeding.ApproveWeedingProposal))).Methods("POST")
+	v1.Handle("/weeding/proposals/{id}/reject", change(http.HandlerFunc(p.Weeding.RejectWeedingProposal))).Methods("POST")
+	v1.Handle("/weeding/proposals/{id}/disposal-report", read(http.HandlerFunc(p.Weeding.GetDisposalReport))).Methods("GET")
+	v1.Handle("/courses", read(http.HandlerFunc(p.Courses.ListCourses))).Methods("GET")
+	v1.Handle("/courses", change(http.HandlerFunc(p.Courses.CreateCourse))).Methods("POST")
+	v1.Handle("/courses/{id}", read(http.HandlerFunc(p.Courses.GetCourse))).Methods("GET")
+	v1.Handle("/courses/{id}", change(http.HandlerFunc(p.Courses.UpdateCourse))).Methods("PUT")
+	v1.Handle("/courses/{id}", change(http.HandlerFunc(p.Courses.DeleteCourse))).Methods("DELETE")
+	v1.Handle("/courses/{id}/reserves", read(http.HandlerFunc(p.Courses.ListReserves))).Methods("GET")
+	v1.Handle("/courses/{id}/reserves", change(http.HandlerFunc(p.Courses.AddReserve))).Methods("POST")
+	v1.Handle("/courses/{id}/reserves/{reserve_id}", change(http.HandlerFunc(p.Courses.UpdateReserve))).Methods("PUT")
+	v1.Handle("/courses/{id}/reserves/{reserve_id}", change(http.HandlerFunc(p.Courses.RemoveReserve))).Methods("DELETE")
+	v1.Handle("/patron-groups", read(http.HandlerFunc(p.Circulation.ListPatronGroups))).Methods("GET")
+	v1.Handle("/closures", read(http.HandlerFunc(p.Calendar.ListClosures))).Methods("GET")
+	v1.Handle("/closures", change(http.HandlerFunc(p.Calendar.CreateClosure))).Methods("POST")
+	v1.Handle("/closures/{id}", read(http.HandlerFunc(p.Calendar.GetClosure))).Methods("GET")
+	v1.Handle("/closures/{id}", change(http.HandlerFunc(p.Calendar.UpdateClosure))).Methods("PUT")
+	v1.Handle("/closures/{id}", change(http.HandlerFunc(p.Calendar.DeleteClosure))).Methods("DELETE")
+	v1.Handle("/shelf-ranges", read(http.HandlerFunc(p.ShelfMap.ListShelfRanges))).Methods("GET")
+	v1.Handle("/shelf-ranges", change(http.HandlerFunc(p.ShelfMap.CreateShelfRange))).Methods("POST")
+	v1.Handle("/shelf-ranges/{id}", read(http.HandlerFunc(p.ShelfMap.GetShelfRange))).Methods("GET")
+	v1.Handle("/shelf-ranges/{id}", change(http.HandlerFunc(p.ShelfMap.UpdateShelfRange))).Methods("PUT")
+	v1.Handle("/shelf-ranges/{id}", change(http.HandlerFunc(p.ShelfMap.DeleteShelfRange))).Methods("DELETE")
+	v1.Handle("/shelf-map/locate", read(http.HandlerFunc(p.ShelfMap.LocateCallNumber))).Methods("GET")
+	v1.Handle("/books/{id}/location", read(http.HandlerFunc(p.ShelfMap.LocateBook))).Methods("GET")
+	v1.Handle("/loan-policy", read(http.HandlerFunc(p.Policy.GetLoanTerms))).Methods("GET")
+	v1.Handle("/members", change(http.HandlerFunc(p.Circulation.CreateMember))).Methods("POST")
+	v1.Handle("/members/guests", change(http.HandlerFunc(p.Circulation.CreateGuest))).Methods("POST")
+	v1.Handle("/members/{id}", read(http.HandlerFunc(p.Circulation.GetMember))).Methods("GET")
+	v1.Handle("/members/{id}", change(http.HandlerFunc(p.Circulation.UpdateMember))).Methods("PUT")
+	v1.Handle("/members/{id}/suspension", change(http.HandlerFunc(p.Circulation.SuspendMember))).Methods("PUT")
+	v1.Handle("/members/{id}/suspension", change(http.HandlerFunc(p.Circulation.UnsuspendMember))).Methods("DELETE")
+	v1.Handle("/members/{id}/status", read(http.HandlerFunc(p.Circulation.GetMemberStatus))).Methods("GET")
+	v1.Handle("/members/{id}/loans", read(http.HandlerFunc(p.Circulation.ListMemberLoans))).Methods("GET")
+	v1.Handle("/members/{id}/loans/anonymize", change(http.HandlerFunc(p.Circulation.AnonymizeMemberLoans))).Methods("POST")
+	v1.Handle("/members/{id}/holds", read(http.HandlerFunc(p.Circulation.ListMemberHolds))).Methods("GET")
+	v1.Handle("/members/{id}/holds", change(http.HandlerFunc(p.Circulation.PlaceHold))).Methods("POST")
+	v1.Handle("/members/{id}/links", read(http.HandlerFunc(p.Circulation.ListMemberLinks))).Methods("GET")
+	v1.Handle("/members/{id}/links", change(http.HandlerFunc(p.Circulation.LinkChild))).Methods("POST")
+	v1.Handle("/members/{id}/links/{child_id}", change(http.HandlerFunc(p.Circulation.UpdateMemberLink))).Methods("PUT")
+	v1.Handle("/members/{id}/links/{child_id}", change(http.HandlerFunc(p.Circulation.UnlinkChild))).Methods("DELETE")
+	v1.Handle("/members/{id}/family/{child_id}/loans", read(http.HandlerFunc(p.Circulation.ListFamilyLoans))).Methods("GET")
+	v1.Handle("/members/{id}/family/{child_id}/loans/{loan_id}/renew", change(http.HandlerFunc(p.Circulation.RenewFamilyLoan))).Methods("POST")
+	v1.Handle("/members/{id}/family/{child_id}/holds", read(http.HandlerFunc(p.Circulation.ListFamilyHolds))).Methods("GET")
+	v1.Handle("/members/{id}/family/{child_id}/holds", change(http.HandlerFunc(p.Circulation.PlaceFamilyHold))).Methods("POST")
+	v1.Handle("/members/{id}/family/{child_id}/holds/{hold_id}", change(http.HandlerFunc(p.Circulation.CancelFamilyHold))).Methods("DELETE")
+	v1.Handle("/consent-documents", read(http.HandlerFunc(p.Consent.ListConsentDocuments))).Methods("GET")
+	v1.Handle("/consent-documents", change(http.HandlerFunc(p.Consent.PublishConsentDocument))).Methods("POST")
+	v1.Handle("/members/{id}/consents", read(http.HandlerFunc(p.Consent.GetMemberConsents))).Methods("GET")
+	v1.Handle("/members/{id}/consents", change(http.HandlerFunc(p.Consent.AcceptConsentDocument))).Methods("POST")
+	v1.Handle("/members/{id}/saved-searches", read(http.HandlerFunc(p.Saved.ListSavedSearches))).Methods("GET")
+	v1.Handle("/members/{id}/saved-searches", change(http.HandlerFunc(p.Saved.CreateSavedSearch))).Methods("POST")
+	v1.Handle("/members/{id}/saved-searches/{search_id}", change(http.HandlerFunc(p.Saved.DeleteSavedSearch))).Methods("DELETE")
+	v1.Handle("/holds/{id}", change(http.HandlerFunc(p.Circulation.CancelHold))).Methods("DELETE")
+	v1.Handle("/loans", change(http.HandlerFunc(p.Circulation.Checkout))).Methods("POST")
+	v1.Handle("/desk/checkouts", change(http.HandlerFunc(p.Circulation.DeskCheckout))).Methods("POST")
+	v1.Handle("/circulation/checkin/batch", bulk(http.HandlerFunc(p.Circulation.BatchCheckin))).Methods("POST")
+	v1.Handle("/hold-shelf", read(http.HandlerFunc(p.Circulation.ListHoldShelf))).Methods("GET")
+	v1.Handle("/hold-shelf/slips", read(http.HandlerFunc(p.Circulation.PrintHoldSlips))).Methods("GET")
+	v1.Handle("/hold-shelf/clear-expired", change(http.HandlerFunc(p.Circulation.ClearExpiredHolds))).Methods("POST")
+	v1.Handle("/loans/{id}", read(http.HandlerFunc(p.Circulation.GetLoan))).Methods("GET")
+	v1.Handle("/loans/{id}/renew", change(http.HandlerFunc(p.Circulation.RenewLoan))).Methods("POST")
+	v1.Handle("/loans/{id}/return", change(http.HandlerFunc(p.Circulation.ReturnLoan))).Methods("POST")
+	v1.Handle("/loans/{id}/claim-returned", change(http.HandlerFunc(p.Circulation.ClaimReturned))).Methods("POST")
+	v1.Handle("/loans/{id}/claim-returned/resolve", change(http.HandlerFunc(p.Circulation.ResolveClaim))).Methods("POST")
+	v1.Handle("/shelf-checks", read(http.HandlerFunc(p.Circulation.ListShelfChecks))).Methods("GET")
+	v1.Handle("/shelf-checks/{id}/result", change(http.HandlerFunc(p.Circulation.RecordShelfCheck))).Methods("POST")
+	v1.Handle("/drawers", read(http.HandlerFunc(p.Payments.ListDrawers))).Methods("GET")
+	v1.Handle("/drawers", change(http.HandlerFunc(p.Payments.OpenDrawer))).Methods("POST")
+	v1.Handle("/drawers/{id}", read(http.HandlerFunc(p.Payments.GetDrawer))).Methods("GET")
+	v1.Handle("/drawers/{id}/close", change(http.HandlerFunc(p.Payments.CloseDrawer))).Methods("POST")
+	v1.Handle("/drawers/{id}/payments", change(http.HandlerFunc(p.Payments.TakePayment))).Methods("POST")
+	v1.Handle("/members/{id}/payments", read(http.HandlerFunc(p.Payments.ListMemberPayments))).Methods("GET")
+	v1.Handle("/serials", read(http.HandlerFunc(p.Serials.ListSerials))).Methods("GET")
+	v1.Handle("/serials", change(http.HandlerFunc(p.Serials.CreateSerial))).Methods("POST")
+	v1.Handle("/serials/claims", read(http.HandlerFunc(p.Serials.ListDueClaims))).Methods("GET")
+	v1.Handle("/serials/renewals", read(http.HandlerFunc(p.Serials.ListDueRenewals))).Methods("GET")
+	v1.Handle("/serials/subscriptions/{id}/predict", change(http.HandlerFunc(p.Serials.PredictIssues))).Methods("POST")
+	v1.Handle("/serials/subscriptions/{id}/issues", read(http.HandlerFunc(p.Serials.ListIssues))).Methods("GET")
+	v1.Handle("/serials/subscriptions/{id}/issues", change(http.HandlerFunc(p.Serials.CheckInUnexpectedIssue))).Methods("POST")
+	v1.Handle("/serials/issues/{id}/check-in", change(http.HandlerFunc(p.Serials.CheckInIssue))).Methods("POST")
+	v1.Handle("/serials/issues/{id}/claim", change(http.HandlerFunc(p.Serials.ClaimIssue))).Methods("POST")
+	v1.Handle("/serials/{id}", read(http.HandlerFunc(p.Serials.GetSerial))).Methods("GET")
+	v1.Handle("/serials/{id}", change(http.HandlerFunc(p.Serials.UpdateSerial))).Methods("PUT")
+	v1.Handle("/serials/{id}", change(http.HandlerFunc(p.Serials.DeleteSerial))).Methods("DELETE")
+	v1.Handle("/serials/{id}/costs", read(http.HandlerFunc(p.Serials.GetCostHistory))).Methods("GET")
+	v1.Handle("/serials/{id}/subscriptions", change(http.HandlerFunc(p.Serials.CreateSubscription))).Methods("POST")
+	v1.Handle("/members/{id}/challenges", read(http.HandlerFunc(p.Challenges.ListChallenges))).Methods("GET")
+	v1.Handle("/members/{id}/challenges/{year}", read(http.HandlerFunc(p.Challenges.GetChallenge))).Methods("GET")
+	v1.Handle("/members/{id}/challenges/{year}", change(http.HandlerFunc(p.Challenges.SetChallengeGoal))).Methods("PUT")
+	v1.Handle("/members/{id}/challenges/{year}", change(http.HandlerFunc(p.Challenges.DeleteChallenge))).Methods("DELETE")
+	v1.Handle("/members/{id}/challenges/{year}/books", change(http.HandlerFunc(p.Challenges.LogChallengeBook))).Methods("POST")
+	v1.Handle("/members/{id}/challenges/{year}/books/{book_id}", change(http.HandlerFunc(p.Challenges.RemoveChallengeBook))).Methods("DELETE")
+	v1.Handle("/challenges/{year}/leaderboard", read(http.HandlerFunc(p.Challenges.GetLeaderboard))).Methods("GET")
+	v1.Handle("/picks", read(http.HandlerFunc(p.Picks.ListPicks))).Methods("GET")
+	v1.Handle("/picks/{slug}", read(http.HandlerFunc(p.Picks.GetPicks))).Methods("GET")
+	v1.Handle("/picks/{slug}/feed", read(http.HandlerFunc(p.Picks.GetPicksFeed))).Methods("GET")
+	v1.Handle("/carousel", read(http.HandlerFunc(p.Picks.GetCarousel))).Methods("GET")
+	v1.Handle("/reading-list", read(http.HandlerFunc(p.Reading.GetReadingList))).Methods("GET")
+	v1.Handle("/reading-list/import", bulk(http.HandlerFunc(p.Reading.ImportReadingList))).Methods("POST")
+
+	// Operator endpoints, behind the admin token and exempt from maintenance mode
+	adminRoutes := v1.PathPrefix("/admin").Subrouter()
+	adminRoutes.Use(middleware.AdminToken(cfg.Admin.Token))
+	adminRoutes.HandleFunc("/maintenance", p.Admin.GetMaintenance).Methods("GET")
+	adminRoutes.HandleFunc("/maintenance", p.Admin.SetMaintenance).Methods("PUT")
+	adminRoutes.HandleFunc("/rate-limits", p.Limits.ListRateLimits).Methods("GET")
+	adminRoutes.HandleFunc("/rate-limits/{key}", p.Limits.GetRateLimit).Methods("GET")
+	adminRoutes.HandleFunc("/rate-limits/{key}", p.Limits.PutRateLimit).Methods("PUT")
+	adminRoutes.HandleFunc("/rate-limits/{key}", p.Limits.DeleteRateLimit).Methods("DELETE")
+	adminRoutes.Handle("/api-keys/{id}/usage", read(http.HandlerFunc(p.Usage.GetKeyUsage))).Methods("GET")
+	adminRoutes.Handle("/zero-results", read(http.HandlerFunc(statsHandler.GetZeroResults))).Methods("GET")
+	adminRoutes.Handle("/experiments", read(http.HandlerFunc(p.Experiments.ListExperiments))).Methods("GET")
+	adminRoutes.Handle("/experiments/{key}", read(http.HandlerFunc(p.Experiments.GetExperiment))).Methods("GET")
+	adminRoutes.Handle("/experiments/{key}", write(http.HandlerFunc(p.Experiments.PutExperiment))).Methods("PUT")
+	adminRoutes.Handle("/experiments/{key}", write(http.HandlerFunc(p.Experiments.DeleteExperiment))).Methods("DELETE")
+	adminRoutes.Handle("/experiments/{key}/results", read(http.HandlerFunc(p.Experiments.GetExperimentResults))).Methods("GET")
+	adminRoutes.Handle("/embeddings", read(http.HandlerFunc(p.Embeddings.GetEmbeddingsStatus))).Methods("GET")
+	adminRoutes.Handle("/ils-sync", read(http.HandlerFunc(p.ILSSync.GetSyncStatus))).Methods("GET")
+	adminRoutes.Handle("/ils-sync/run", write(http.HandlerFunc(p.ILSSync.RunSync))).Methods("POST")
+	adminRoutes.Handle("/devices", read(http.HandlerFunc(p.Devices.GetDeviceDashboard))).Methods("GET")
+	adminRoutes.Handle("/devices/{id}", read(http.HandlerFunc(p.Devices.GetDevice))).Methods("GET")
+	adminRoutes.Handle("/devices/{id}", write(http.HandlerFunc(p.Devices.DeleteDevice))).Methods("DELETE")
+	adminRoutes.Handle("/devices/{id}/errors", read(http.HandlerFunc(p.Devices.ListDeviceErrors))).Methods("GET")
+	adminRoutes.Handle("/picks", read(http.HandlerFunc(p.Picks.ListAllPicks))).Methods("GET")
+	adminRoutes.Handle("/picks", write(http.HandlerFunc(p.Picks.CreatePicks))).Methods("POST")
+	adminRoutes.Handle("/picks/{id}", read(http.HandlerFunc(p.Picks.GetAnyPicks))).Methods("GET")
+	adminRoutes.Handle("/picks/{id}", write(http.HandlerFunc(p.Picks.UpdatePicks))).Methods("PUT")
+	adminRoutes.Handle("/picks/{id}", write(http.HandlerFunc(p.Picks.DeletePicks))).Methods("DELETE")
+	adminRoutes.Handle("/picks/{id}/items", write(http.HandlerFunc(p.Picks.SetPicksItems))).Methods("PUT")
+	adminRoutes.Handle("/carousel", read(http.HandlerFunc(p.Picks.ListFeatures))).Methods("GET")
+	adminRoutes.Handle("/carousel", write(http.HandlerFunc(p.Picks.ScheduleFeature))).Methods("POST")
+	adminRoutes.Handle("/carousel/{id}", read(http.HandlerFunc(p.Picks.GetFeature))).Methods("GET")
+	adminRoutes.Handle("/carousel/{id}", write(http.HandlerFunc(p.Picks.UpdateFeature))).Methods("PUT")
+	adminRoutes.Handle("/carousel/{id}", write(http.HandlerFunc(p.Picks.DeleteFeature))).Methods("DELETE")
+
+	if p.Options.metrics {
+		router.Handle("/metrics", promhttp.Handler()).Methods("GET")
+	}
+	v1.PathPrefix("/swagger/").Handler(httpSwagger.WrapHandler)
+
+	return router
+}
+
+// chain applies outer around inner
+func chain(outer, inner func(http.Handler) http.Handler) func(http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler { return outer(inner(h)) }
+}
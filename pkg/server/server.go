@@ -0,0 +1,197 @@
+// Package server assembles the library API so it can run as its own process
+// (see cmd/main.go) or be embedded in another Go program, which can supply its
+// own logger and database and mount the API under its own mux.
+package server
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"public_library/internal/config"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxevent"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Config is the full application configuration
+type Config = config.AppConfig
+
+// DefaultConfig returns the configuration used for anything a file leaves out
+func DefaultConfig() Config {
+	return config.Default()
+}
+
+// LoadConfig reads and validates a YAML configuration file, filling defaults
+// for anything it leaves out
+func LoadConfig(path string) (Config, error) {
+	return config.Load(path)
+}
+
+type options struct {
+	logger     *zap.Logger
+	sqlDB      *sql.DB
+	pathPrefix string
+	metrics    bool
+	populate   []interface{}
+}
+
+// Option customizes New
+type Option func(*options)
+
+// WithLogger makes the server log to logger instead of a new production logger
+func WithLogger(logger *zap.Logger) Option {
+	return func(o *options) { o.logger = logger }
+}
+
+// WithDB makes the server use an already open database instead of connecting
+// with the db section of the configuration. The schema is still applied.
+// The caller keeps ownership and closes it.
+func WithDB(conn *sql.DB) Option {
+	return func(o *options) { o.sqlDB = conn }
+}
+
+// WithPathPrefix mounts the API routes under prefix instead of /api/v1
+func WithPathPrefix(prefix string) Option {
+	return func(o *options) { o.pathPrefix = prefix }
+}
+
+// WithoutMetrics leaves /metrics off the router, for embedding programs that
+// already expose the default Prometheus registry themselves
+func WithoutMetrics() Option {
+	return func(o *options) { o.metrics = false }
+}
+
+// WithPopulate fills each target, a pointer to a component type such as
+// *book.Service, from the assembled server. It lets test harnesses and tools
+// reach the services and repositories behind the router.
+func WithPopulate(targets ...interface{}) Option {
+	return func(o *options) { o.populate = append(o.populate, targets...) }
+}
+
+// Server is an assembled library API. Its parts are constructed and started
+// by an fx application built from the modules in modules.go.
+type Server struct {
+	cfg      Config
+	logger   *zap.Logger
+	app      *fx.App
+	router   *mux.Router
+	stopOnce sync.Once
+	stopErr  error
+}
+
+// New connects to the database (unless WithDB is given), applies the schema
+// and builds the router. Nothing runs in the background until Start or Run.
+func New(cfg Config, opts ...Option) (*Server, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+	o := options{pathPrefix: "/api/v1", metrics: true}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if cfg.Sandbox.Enabled && o.sqlDB != nil {
+		return nil, errors.New("sandbox mode opens its own database connection and cannot be combined with WithDB")
+	}
+	if o.logger == nil {
+		logger, err := zap.NewProduction()
+		if err != nil {
+			return nil, err
+		}
+		o.logger = logger
+	}
+
+	s := &Server{cfg: cfg, logger: o.logger}
+	s.app = fx.New(
+		fx.WithLogger(func() fxevent.Logger {
+			l := &fxevent.ZapLogger{Logger: o.logger}
+			l.UseLogLevel(zapcore.DebugLevel)
+			return l
+		}),
+		fx.Supply(cfg, o, o.logger),
+		databaseModule(o.sqlDB),
+		modules,
+		fx.Populate(&s.router),
+		fx.Populate(o.populate...),
+	)
+	if err := s.app.Err(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Handler returns the API router, for mounting under another mux. Routes
+// keep their path prefix, so mount it at "/" or strip the parent's prefix.
+func (s *Server) Handler() http.Handler {
+	return s.router
+}
+
+// Start launches the background jobs (partition maintenance and statistics
+// refresh); they stop, and a database opened by New is closed, when ctx is
+// done. Embedding programs that only mount Handler should call Start
+// themselves.
+func (s *Server) Start(ctx context.Context) error {
+	if err := s.start(ctx); err != nil {
+		return err
+	}
+	go func() {
+		<-ctx.Done()
+		s.stop()
+	}()
+	return nil
+}
+
+func (s *Server) start(ctx context.Context) error {
+	startCtx, cancel := context.WithTimeout(ctx, s.app.StartTimeout())
+	defer cancel()
+	return s.app.Start(startCtx)
+}
+
+func (s *Server) stop() error {
+	s.stopOnce.Do(func() {
+		stopCtx, cancel := context.WithTimeout(context.Background(), s.app.StopTimeout())
+		defer cancel()
+		s.stopErr = s.app.Stop(stopCtx)
+	})
+	return s.stopErr
+}
+
+// Addr is the address Run listens on, from the server section of the
+// configuration
+func (s *Server) Addr() string {
+	return fmt.Sprintf("%s:%d", s.cfg.Server.Host, s.cfg.Server.Port)
+}
+
+// Run starts the background jobs and serves the API on Addr until ctx is
+// done, then shuts down gracefully and stops everything Start started
+func (s *Server) Run(ctx context.Context) error {
+	if err := s.start(ctx); err != nil {
+		return err
+	}
+
+	srv := &http.Server{Addr: s.Addr(), Handler: s.router}
+	errCh := make(chan error, 1)
+	go func() {
+		s.logger.Info("Starting server", zap.String("addr", srv.Addr))
+		errCh <- srv.ListenAndServe()
+	}()
+
+	var err error
+	select {
+	case err = <-errCh:
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		err = srv.Shutdown(shutdownCtx)
+	}
+	if errors.Is(err, http.ErrServerClosed) {
+		err = nil
+	}
+	return errors.Join(err, s.stop())
+}
@@ -0,0 +1,109 @@
+package testutil
+
+import (
+	"context"
+	"fmt"
+	"public_library/internal/book"
+	"public_library/internal/series"
+	"public_library/pkg/contract"
+	"strconv"
+)
+
+// Provider states the contracts in contracts/ may name
+const (
+	StateEmptyCatalog = "the catalog is empty"
+	StateBookExists   = "a book exists"
+	StateSeriesExists = "a series with two volumes exists"
+)
+
+// ProviderStates sets up the data behind each provider state. Every state
+// starts from an empty catalog and returns the IDs it created as
+// placeholders: {bookId} and {isbn} for a book, {seriesId} for a series.
+func (h *Harness) ProviderStates() map[string]contract.StateFunc {
+	return map[string]contract.StateFunc{
+		StateEmptyCatalog: func(ctx context.Context) (map[string]string, error) {
+			return map[string]string{}, h.clear(ctx)
+		},
+		StateBookExists: func(ctx context.Context) (map[string]string, error) {
+			if err := h.clear(ctx); err != nil {
+				return nil, err
+			}
+			year, pages := 1925, 180
+			b := book.Book{
+				Title:           "The Great Gatsby",
+				Author:          "F. Scott Fitzgerald",
+				Description:     "A portrait of the Jazz Age on Long Island.",
+				ISBN:            "9780743273565",
+				CallNumber:      "813.52 FIT",
+				ShelfLocation:   "2F-A12",
+				Collection:      "Adult Fiction",
+				PublicationYear: &year,
+				PageCount:       &pages,
+				Format:          "hardcover",
+				Audience:        "adult",
+			}
+			if err := h.Books.Create(ctx, &b); err != nil {
+				return nil, err
+			}
+			return map[string]string{"bookId": strconv.Itoa(b.ID), "isbn": b.ISBN}, nil
+		},
+		StateSeriesExists: func(ctx context.Context) (map[string]string, error) {
+			if err := h.clear(ctx); err != nil {
+				return nil, err
+			}
+			s := series.Series{Title: "The Lord of the Rings", Description: "Tolkien's epic in three volumes"}
+			if err := h.Series.Create(ctx, &s); err != nil {
+				return nil, err
+			}
+			for i, title := range []string{"The Fellowship of the Ring", "The Two Towers"} {
+				pos := float64(i + 1)
+				b := book.Book{
+					Title:    title,
+					Author:   "J. R. R. Tolkien",
+					Format:   "paperback",
+					Audience: "adult",
+					Series:   &book.SeriesRef{ID: s.ID, Position: &pos},
+				}
+				if err := h.Books.Create(ctx, &b); err != nil {
+					return nil, err
+				}
+			}
+			return map[string]string{"seriesId": strconv.Itoa(s.ID)}, nil
+		},
+	}
+}
+
+// Verifier replays contracts against the harness's router with its provider
+// states
+func (h *Harness) Verifier() *contract.Verifier {
+	return &contract.Verifier{Handler: h.Handler, States: h.ProviderStates()}
+}
+
+// clear deletes every book and series so each state starts from nothing
+func (h *Harness) clear(ctx context.Context) error {
+	list, err := h.Series.List(ctx)
+	if err != nil {
+		return err
+	}
+	for _, s := range list {
+		if err := h.Series.Delete(ctx, s.ID); err != nil {
+			return fmt.Errorf("deleting series %d: %w", s.ID, err)
+		}
+	}
+	var ids []int
+	err = h.Books.Export(ctx, 0, func(batch []book.Book) error {
+		for _, b := range batch {
+			ids = append(ids, b.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if err := h.Books.Delete(ctx, id); err != nil {
+			return fmt.Errorf("deleting book %d: %w", id, err)
+		}
+	}
+	return nil
+}
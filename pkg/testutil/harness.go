@@ -0,0 +1,90 @@
+package testutil
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"public_library/internal/book"
+	"public_library/internal/media"
+	"public_library/internal/series"
+	"public_library/internal/stats"
+	"public_library/pkg/server"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest"
+)
+
+// Harness is the API assembled against a fresh, migrated database. Its
+// background jobs (statistics refresh, partition maintenance) are not
+// started; call Server.Start for tests that need them.
+type Harness struct {
+	Config  server.Config
+	Server  *server.Server
+	Handler http.Handler
+	// DB is the harness's own database, with the schema applied
+	DB *sql.DB
+
+	Books    *book.Service
+	BookRepo *book.Repository
+	Series   *series.Repository
+	Media    *media.Repository
+	Stats    *stats.Repository
+}
+
+// Option adjusts the configuration a Harness is built with
+type Option func(*server.Config)
+
+// WithConfig changes the configuration before the server is assembled
+func WithConfig(fn func(*server.Config)) Option {
+	return Option(fn)
+}
+
+// New builds a Harness on its own database in the shared container and
+// removes the database when the test ends. The test is skipped when no
+// container runtime is available.
+func New(t testing.TB, opts ...Option) *Harness {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	if err := dockerAvailable(ctx); err != nil {
+		t.Skipf("testutil: no container runtime available: %v", err)
+	}
+	pg, err := SharedPostgres(ctx)
+	if err != nil {
+		t.Fatalf("testutil: %v", err)
+	}
+	conn, drop, err := pg.NewDatabase(ctx)
+	if err != nil {
+		t.Fatalf("testutil: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := drop(); err != nil {
+			t.Logf("testutil: dropping database: %v", err)
+		}
+	})
+
+	cfg := server.DefaultConfig()
+	cfg.Media.StorageDir = t.TempDir()
+	cfg.Media.TokenSecret = "testutil-stream-token-secret"
+	// Stale cached lists would make write-then-read tests flaky
+	cfg.Cache.BookList.TTL = 0
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	h := &Harness{Config: cfg, DB: conn}
+	srv, err := server.New(cfg,
+		server.WithDB(conn),
+		server.WithLogger(zaptest.NewLogger(t, zaptest.Level(zap.WarnLevel))),
+		server.WithPopulate(&h.Books, &h.BookRepo, &h.Series, &h.Media, &h.Stats),
+	)
+	if err != nil {
+		t.Fatalf("testutil: assembling server: %v", err)
+	}
+	h.Server = srv
+	h.Handler = srv.Handler()
+	return h
+}
@@ -0,0 +1,131 @@
+// Package testutil runs the library API against a throwaway PostgreSQL
+// started with testcontainers-go, for our own integration tests and for
+// programs that embed the API. One container is shared per test binary;
+// every Harness gets its own database in it.
+package testutil
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/modules/postgres"
+
+	_ "github.com/jackc/pgx/v5/stdlib" // PostgreSQL driver
+)
+
+// PostgresImage is the image StartPostgres runs
+const PostgresImage = "postgres:16-alpine"
+
+// Postgres is a PostgreSQL server running in a container
+type Postgres struct {
+	container *postgres.PostgresContainer
+	dsn       string
+	admin     *sql.DB
+	databases atomic.Int64
+}
+
+// StartPostgres starts a PostgreSQL container and waits until it accepts
+// connections. Terminate removes it.
+func StartPostgres(ctx context.Context) (*Postgres, error) {
+	container, err := postgres.Run(ctx, PostgresImage,
+		postgres.WithDatabase("library"),
+		postgres.WithUsername("library"),
+		postgres.WithPassword("library"),
+		postgres.BasicWaitStrategies(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("starting postgres container: %w", err)
+	}
+	dsn, err := container.ConnectionString(ctx, "sslmode=disable")
+	if err != nil {
+		container.Terminate(ctx)
+		return nil, err
+	}
+	admin, err := sql.Open("pgx", dsn)
+	if err != nil {
+		container.Terminate(ctx)
+		return nil, err
+	}
+	return &Postgres{container: container, dsn: dsn, admin: admin}, nil
+}
+
+// Terminate stops and removes the container
+func (p *Postgres) Terminate(ctx context.Context) error {
+	p.admin.Close()
+	return p.container.Terminate(ctx)
+}
+
+// NewDatabase creates an empty database and returns a connection to it.
+// drop closes the connection and removes the database.
+func (p *Postgres) NewDatabase(ctx context.Context) (conn *sql.DB, drop func() error, err error) {
+	name := fmt.Sprintf("test_%d_%d", time.Now().UnixNano()%1_000_000, p.databases.Add(1))
+	ident := pgx.Identifier{name}.Sanitize()
+	if _, err := p.admin.ExecContext(ctx, "CREATE DATABASE "+ident); err != nil {
+		return nil, nil, fmt.Errorf("creating database %s: %w", name, err)
+	}
+
+	u, err := url.Parse(p.dsn)
+	if err != nil {
+		return nil, nil, err
+	}
+	u.Path = "/" + name
+	conn, err = sql.Open("pgx", u.String())
+	if err != nil {
+		return nil, nil, err
+	}
+	drop = func() error {
+		conn.Close()
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		_, err := p.admin.ExecContext(ctx, "DROP DATABASE IF EXISTS "+ident+" WITH (FORCE)")
+		return err
+	}
+	return conn, drop, nil
+}
+
+var shared struct {
+	mu  sync.Mutex
+	pg  *Postgres
+	err error
+}
+
+// SharedPostgres starts the container on first use and returns the same one
+// afterwards. The testcontainers reaper removes it when the test binary
+// exits; call TerminateShared from TestMain to remove it sooner.
+func SharedPostgres(ctx context.Context) (*Postgres, error) {
+	shared.mu.Lock()
+	defer shared.mu.Unlock()
+	if shared.pg == nil && shared.err == nil {
+		shared.pg, shared.err = StartPostgres(ctx)
+	}
+	return shared.pg, shared.err
+}
+
+// TerminateShared removes the shared container, if it was started
+func TerminateShared(ctx context.Context) error {
+	shared.mu.Lock()
+	defer shared.mu.Unlock()
+	if shared.pg == nil {
+		return nil
+	}
+	err := shared.pg.Terminate(ctx)
+	shared.pg = nil
+	return err
+}
+
+// dockerAvailable reports whether a container runtime is reachable
+func dockerAvailable(ctx context.Context) error {
+	provider, err := testcontainers.ProviderDocker.GetProvider()
+	if err != nil {
+		return err
+	}
+	defer provider.Close()
+	return provider.Health(ctx)
+}
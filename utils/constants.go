@@ -2,10 +2,63 @@ package utils
 
 // Table names
 const (
-	ASC            = "asc"
-	DESC           = "desc"
-	BooksTable     = "books"
-	StatusOK       = "ok"
-	StatusError    = "error"
-	StatusDegraded = "degraded"
+	ASC                        = "asc"
+	DESC                       = "desc"
+	BooksTable                 = "books"
+	BookIdentifiersTable       = "book_identifiers"
+	BookTranslationsTable      = "book_translations"
+	BookEmbeddingsTable        = "book_embeddings"
+	SeriesTable                = "series"
+	BookAssetsTable            = "book_assets"
+	APIKeyLimitsTable          = "api_key_limits"
+	APIKeyUsageTable           = "api_key_usage"
+	ReadingListTable           = "reading_list_entries"
+	ILSSyncStateTable          = "ils_sync_state"
+	AcquisitionsTable          = "acquisitions"
+	RFIDTagsTable              = "rfid_tags"
+	DevicesTable               = "devices"
+	DeviceErrorsTable          = "device_errors"
+	PurchaseOrdersTable        = "purchase_orders"
+	PurchaseOrderLinesTable    = "purchase_order_lines"
+	EDIMessagesTable           = "edi_messages"
+	WeedingProposalsTable      = "weeding_proposals"
+	WeedingCandidatesTable     = "weeding_candidates"
+	RepairsTable               = "repairs"
+	CoursesTable               = "courses"
+	CourseReservesTable        = "course_reserves"
+	ClosuresTable              = "closures"
+	ShelfRangesTable           = "shelf_ranges"
+	MembersTable               = "members"
+	LoansTable                 = "loans"
+	HoldsTable                 = "holds"
+	CopyEventsTable            = "copy_events"
+	ShelfChecksTable           = "shelf_checks"
+	DrawerSessionsTable        = "drawer_sessions"
+	PaymentsTable              = "payments"
+	MemberLinksTable           = "member_links"
+	ConsentDocumentsTable      = "consent_documents"
+	ConsentsTable              = "consents"
+	SavedSearchesTable         = "saved_searches"
+	SerialsTable               = "serials"
+	SerialSubscriptionsTable   = "serial_subscriptions"
+	SerialIssuesTable          = "serial_issues"
+	SerialRemindersTable       = "serial_renewal_reminders"
+	ReadingChallengesTable     = "reading_challenges"
+	ChallengeBooksTable        = "challenge_books"
+	PickListsTable             = "pick_lists"
+	PickItemsTable             = "pick_list_items"
+	CarouselFeaturesTable      = "carousel_features"
+	SuggestionsTable           = "suggestions"
+	AnalyticsDailyTable        = "analytics_daily"
+	AnalyticsZeroResultsTable  = "analytics_zero_results"
+	AnalyticsViewsTable        = "analytics_views"
+	ExperimentsTable           = "experiments"
+	ExperimentAssignmentsTable = "experiment_assignments"
+	ExperimentConversionsTable = "experiment_conversions"
+	// Materialized views backing the stats endpoints
+	LibraryStatsView = "library_stats"
+	AuthorStatsView  = "author_stats"
+	StatusOK         = "ok"
+	StatusError      = "error"
+	StatusDegraded   = "degraded"
 )